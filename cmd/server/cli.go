@@ -0,0 +1,320 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gcal-mcp-server/internal/auth"
+	"gcal-mcp-server/internal/calendar"
+	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+)
+
+// cliAliases are short command names for the most used tools. Every other
+// tool runs under its own name.
+var cliAliases = map[string]string{
+	"list":     "list_events",
+	"create":   "create_event",
+	"edit":     "edit_event",
+	"delete":   "delete_event",
+	"freebusy": "get_attendee_freebusy",
+	"digest":   "morning_digest",
+}
+
+// runCommand runs one command against backend and returns the exit code.
+// help works before setup is complete; other commands need the Google
+// backend to be set up, which is done through the MCP server's setup tools.
+func runCommand(backend string, budget *quota.Budget, args []string, asJSON bool) int {
+	var ct *calendar.CalendarTools
+	switch {
+	case args[0] == "help":
+		ct = calendar.NewCalendarTools(nil)
+	case backend == "google" && !auth.CheckSetup().Ready():
+		fmt.Fprintf(os.Stderr, "Google Calendar is not set up yet: start the server from your MCP client and follow setup_status, or try the demo calendar with -backend fake\n")
+		return 1
+	default:
+		var err error
+		if ct, err = newCalendarTools(backend, budget, false); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	}
+
+	code := runCLI(ct, args, asJSON, os.Stdout)
+	if err := shutdownTracing(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to flush traces: %v\n", err)
+	}
+	return code
+}
+
+// runCLI runs the tool named by args[0] once, with arguments taken from the
+// remaining flags, prints its result to out and returns the exit code. It
+// calls the same handlers the MCP server does, so
+//
+//	gcal-mcp-server list --today
+//	gcal-mcp-server create --summary Lunch --start_time 2025-03-03T12:00:00Z --end_time 2025-03-03T13:00:00Z
+//
+// give the same results as the list_events and create_event tools. With
+// asJSON the whole tool result is printed as JSON instead of its text.
+func runCLI(ct *calendar.CalendarTools, args []string, asJSON bool, out io.Writer) int {
+	tools := ct.GetTools()
+	if args[0] == "help" {
+		if len(args) > 1 {
+			if tool, ok := findCLITool(tools, args[1]); ok {
+				printToolUsage(out, tool)
+				return 0
+			}
+		}
+		printCLIUsage(out, tools)
+		return 0
+	}
+
+	tool, ok := findCLITool(tools, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q; run '%s help' for the list of commands\n", args[0], os.Args[0])
+		return 2
+	}
+	for _, arg := range args[1:] {
+		if arg == "-h" || arg == "-help" || arg == "--help" {
+			printToolUsage(out, tool)
+			return 0
+		}
+	}
+	arguments, err := cliArguments(tool, args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v; run '%s help %s' for its flags\n", args[0], err, os.Args[0], args[0])
+		return 2
+	}
+
+	result, err := ct.HandleTool(tool.Name, arguments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if asJSON {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(out, string(data))
+	} else {
+		for _, content := range result.Content {
+			if content.Type == "image" {
+				fmt.Fprintf(out, "[%s image omitted; use -json to get it base64-encoded]\n", content.MimeType)
+				continue
+			}
+			fmt.Fprintln(out, content.Text)
+		}
+	}
+	if result.IsError != nil && *result.IsError {
+		return 1
+	}
+	return 0
+}
+
+// findCLITool returns the tool a command runs: an alias, or a tool name with
+// dashes allowed for underscores.
+func findCLITool(tools []mcp.Tool, command string) (mcp.Tool, bool) {
+	name := strings.ReplaceAll(command, "-", "_")
+	if alias, ok := cliAliases[name]; ok {
+		name = alias
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return mcp.Tool{}, false
+}
+
+// cliArguments turns flags into tool arguments, typed by the tool's input
+// schema. Flags are written --name value or --name=value, with dashes
+// allowed for underscores. Booleans need no value (--dry_run), arrays take
+// comma-separated or repeated values, and objects take JSON. A flag naming
+// one of the allowed values of a string argument sets it, so list --today
+// is list --time_filter today.
+func cliArguments(tool mcp.Tool, args []string) (map[string]interface{}, error) {
+	arguments := make(map[string]interface{})
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return nil, fmt.Errorf("unexpected argument %q", arg)
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		name = strings.ReplaceAll(name, "-", "_")
+
+		property, ok := tool.InputSchema.Properties[name].(map[string]interface{})
+		if !ok {
+			enumProperty, found := enumArgument(tool, name)
+			if !found || hasValue {
+				if suggestion := mcp.SuggestArgument(tool.InputSchema, name); suggestion != "" {
+					return nil, fmt.Errorf("unknown flag --%s (did you mean --%s?)", name, suggestion)
+				}
+				return nil, fmt.Errorf("unknown flag --%s", name)
+			}
+			arguments[enumProperty] = name
+			continue
+		}
+
+		kind, _ := property["type"].(string)
+		if kind == "boolean" {
+			if !hasValue {
+				arguments[name] = true
+				continue
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("--%s: %v", name, err)
+			}
+			arguments[name] = b
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch kind {
+		case "integer", "number":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("--%s: %q is not a number", name, value)
+			}
+			arguments[name] = n
+		case "array":
+			items, _ := arguments[name].([]interface{})
+			if itemSchema, _ := property["items"].(map[string]interface{}); itemSchema["type"] == "object" {
+				var decoded interface{}
+				if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+					return nil, fmt.Errorf("--%s: invalid JSON: %v", name, err)
+				}
+				if list, ok := decoded.([]interface{}); ok {
+					items = append(items, list...)
+				} else {
+					items = append(items, decoded)
+				}
+			} else {
+				for _, item := range strings.Split(value, ",") {
+					if item = strings.TrimSpace(item); item != "" {
+						items = append(items, item)
+					}
+				}
+			}
+			arguments[name] = items
+		case "object":
+			var decoded map[string]interface{}
+			if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+				return nil, fmt.Errorf("--%s: invalid JSON object: %v", name, err)
+			}
+			arguments[name] = decoded
+		default:
+			arguments[name] = value
+		}
+	}
+
+	var missing []string
+	for _, name := range tool.InputSchema.Required {
+		if _, ok := arguments[name]; !ok {
+			missing = append(missing, "--"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return arguments, nil
+}
+
+// enumArgument returns the string argument of tool that allows value, when
+// exactly one does.
+func enumArgument(tool mcp.Tool, value string) (string, bool) {
+	var found []string
+	for name, p := range tool.InputSchema.Properties {
+		property, _ := p.(map[string]interface{})
+		if values, ok := property["enum"].([]string); ok && property["type"] == "string" {
+			for _, v := range values {
+				if v == value {
+					found = append(found, name)
+				}
+			}
+		}
+	}
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// printCLIUsage lists the commands: the aliases, then every tool.
+func printCLIUsage(out io.Writer, tools []mcp.Tool) {
+	fmt.Fprintf(out, "Usage: %s [-backend google|fake] [-json] <command> [--flag value ...]\n", os.Args[0])
+	fmt.Fprintf(out, "Without a command the MCP server runs on stdin/stdout.\n\nShort commands:\n")
+	aliases := make([]string, 0, len(cliAliases))
+	for alias := range cliAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		fmt.Fprintf(out, "  %-10s %s\n", alias, cliAliases[alias])
+	}
+	fmt.Fprintf(out, "\nCommands (run '%s help <command>' for its flags):\n", os.Args[0])
+	for _, tool := range tools {
+		fmt.Fprintf(out, "  %-28s %s\n", tool.Name, firstSentence(tool.Description))
+	}
+}
+
+// printToolUsage describes a tool and its flags.
+func printToolUsage(out io.Writer, tool mcp.Tool) {
+	fmt.Fprintf(out, "%s: %s\n\nFlags:\n", tool.Name, tool.Description)
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	required := make(map[string]bool)
+	for _, name := range tool.InputSchema.Required {
+		required[name] = true
+	}
+	for _, name := range names {
+		property, _ := tool.InputSchema.Properties[name].(map[string]interface{})
+		kind, _ := property["type"].(string)
+		description, _ := property["description"].(string)
+		fmt.Fprintf(out, "  --%s (%s", name, kind)
+		if required[name] {
+			fmt.Fprint(out, ", required")
+		}
+		if values, ok := property["enum"].([]string); ok {
+			fmt.Fprintf(out, ": %s", strings.Join(values, "|"))
+		}
+		fmt.Fprintf(out, ")\n      %s\n", description)
+	}
+}
+
+// firstSentence shortens a description to its first sentence.
+func firstSentence(s string) string {
+	if i := strings.Index(s, ". "); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
@@ -17,48 +17,279 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
+	// Embedded so time zones load on hosts without a tz database
+	_ "time/tzdata"
 
 	"gcal-mcp-server/internal/auth"
 	"gcal-mcp-server/internal/calendar"
+	"gcal-mcp-server/internal/fake"
 	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+	"gcal-mcp-server/internal/telemetry"
+
+	gcal "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
 )
 
 func main() {
-	// Setup Google Calendar service
-	calendarService, err := auth.GetCalendarService()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unable to retrieve Calendar client: %v\n", err)
-		os.Exit(1)
+	backend := flag.String("backend", "google", "calendar backend: google, or fake for an in-memory demo calendar")
+	asJSON := flag.Bool("json", false, "with a command, print the whole tool result as JSON")
+	flag.Parse()
+
+	// One request budget is shared by every tool and both Google APIs
+	budget := quota.BudgetFromEnv()
+
+	// Trace tool calls and API requests when an OTLP endpoint is configured
+	if shutdown, err := telemetry.Setup(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Tracing disabled: %v\n", err)
+	} else {
+		shutdownTracing = shutdown
+		if telemetry.Enabled() {
+			fmt.Fprintf(os.Stderr, "Tracing enabled: exporting spans over OTLP\n")
+		}
 	}
 
-	// Setup Google Drive service
-	driveService, err := auth.GetDriveService()
+	// A command runs one tool and exits instead of serving MCP
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(*backend, budget, flag.Args(), *asJSON))
+	}
+
+	// Without credentials or a token, serve the setup tools instead of
+	// exiting, so the MCP client can walk the user through setup
+	if *backend == "google" && !auth.CheckSetup().Ready() {
+		runSetupMode(*backend, budget)
+		return
+	}
+
+	calendarTools, err := newCalendarTools(*backend, budget, true)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unable to retrieve Drive client: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 
-	// Create calendar client and tools
-	calendarClient := calendar.NewClient(calendarService, driveService)
-	calendarTools := calendar.NewCalendarTools(calendarClient)
-
 	// Create MCP server
 	server := mcp.NewServer(calendarTools)
+	server.SetHealthCheck(auth.TokenHealth)
+	describeBuild(server)
+	setStrictArguments(server)
 
-	// Register all tools
-	for _, tool := range calendarTools.GetTools() {
+	// Register the tools the granted scopes allow
+	for _, tool := range scopedTools(server, calendarTools) {
 		server.RegisterTool(tool)
 	}
+	serveAgenda(server, calendarTools, budget)
 
 	// Log server startup to stderr
 	server.LogToStderr("Google Calendar MCP Server starting...")
 	server.LogToStderr("Available tools: create_event, edit_event, delete_event, search_attendees, get_attendee_freebusy, list_events, get_document")
 
-	// Run the server
-	if err := server.Run(); err != nil {
+	run(server)
+}
+
+// shutdownTracing flushes spans not yet exported
+var shutdownTracing = func(context.Context) error { return nil }
+
+// run serves MCP requests until stdin is closed.
+func run(server *mcp.Server) {
+	err := server.Run()
+	if flushErr := shutdownTracing(context.Background()); flushErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to flush traces: %v\n", flushErr)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// describeBuild reports the server build in the initialize result.
+func describeBuild(server *mcp.Server) {
+	build := buildInfo()
+	server.SetVersion(build.Version)
+	server.SetInitializeMeta(buildMetaKey, build)
+}
+
+// setStrictArguments makes tools reject misspelled or unknown arguments when
+// GCAL_MCP_STRICT_ARGUMENTS is set.
+func setStrictArguments(server *mcp.Server) {
+	if mcp.StrictArgumentsFromEnv() {
+		server.SetStrictArguments(true)
+		fmt.Fprintf(os.Stderr, "Strict arguments active: tools reject arguments their schema does not list\n")
+	}
+}
+
+// scopesMetaKey is the initialize _meta key reporting tools left out or
+// limited by the granted OAuth scopes
+const scopesMetaKey = "gcal-mcp-server/scopes"
+
+// scopedTools returns the calendar tools the signed-in user's token has the
+// scopes for, and reports any mismatch in the log and the initialize result
+// instead of letting the tools fail when called.
+func scopedTools(server *mcp.Server, calendarTools *calendar.CalendarTools) []mcp.Tool {
+	granted := auth.GrantedScopes()
+	tools, report := calendarTools.ToolsForScopes(granted)
+	if granted != nil {
+		server.SetInitializeMeta(scopesMetaKey, report)
+		calendarTools.SetScopeReport(report)
+	}
+	if summary := report.Describe(); summary != "" {
+		server.LogToStderr("%s", summary)
+	}
+	return tools
+}
+
+// serveAgenda offers today's agenda and changes to tracked events as
+// resources and keeps subscribers up to date in the background.
+func serveAgenda(server *mcp.Server, calendarTools *calendar.CalendarTools, budget *quota.Budget) {
+	server.SetResources(calendarTools)
+	calendarTools.StartAgendaWatch(server, calendar.AgendaIntervalFromEnv(), budget)
+	calendarTools.StartTrackedWatch(server, calendar.AgendaIntervalFromEnv(), budget)
+}
+
+// apiMiddleware traces every Google API request and charges it to budget.
+// Tracing is outermost so requests rejected by the budget show up as failed spans.
+// Writes also mark the warm cache, if any, stale.
+func apiMiddleware(budget *quota.Budget, warm *calendar.WarmCache) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return telemetry.Middleware(budget.Middleware(warm.Middleware(next)))
+	}
+}
+
+// newCalendarTools creates the services for backend and the configured
+// calendar tools on top of them.
+func newCalendarTools(backend string, budget *quota.Budget, serving bool) (*calendar.CalendarTools, error) {
+	// Background work only pays off in a long-running server, not for one command
+	var warm *calendar.WarmCache
+	if serving {
+		warm = calendar.WarmCacheFromEnv()
+	}
+	calendarService, driveService, err := newServices(backend, budget, warm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional subsystems, reported by server_info
+	subsystems := []calendar.Subsystem{{Name: "tracing", Enabled: telemetry.Enabled()}}
+
+	// Create calendar client and tools
+	calendarClient := calendar.NewClient(calendarService, driveService)
+	policy := calendar.PolicyFromEnv()
+	if !policy.IsEmpty() {
+		calendarClient.SetPolicy(policy)
+		fmt.Fprintf(os.Stderr, "Calendar policy active: allowed=%v denied=%v\n", policy.Allowed, policy.Denied)
+	}
+	subsystems = append(subsystems, calendar.Subsystem{Name: "calendar_policy", Enabled: !policy.IsEmpty()})
+	calendarClient.SetAttendeeTimezones(calendar.AttendeeTimezonesFromEnv())
+	calendarClient.SetDisplaySettings(calendar.DisplaySettingsFromEnv())
+	if backend == "google" {
+		// Drive access is asked for the first time a tool reads a document
+		calendarClient.SetScopeCheck(auth.RequireScopes)
+	}
+	if warm != nil {
+		calendarClient.StartWarmCache(warm, budget)
+		fmt.Fprintf(os.Stderr, "Warm cache active: prefetching today's and tomorrow's events\n")
+	}
+	subsystems = append(subsystems, calendar.Subsystem{Name: "warm_cache", Enabled: warm != nil})
+	calendarTools := calendar.NewCalendarTools(calendarClient)
+	calendarTools.SetBudget(budget)
+	calendarTools.SetColorLegend(calendar.ColorLegendFromEnv())
+	calendarTools.SetFetchLimits(calendar.FetchLimitsFromEnv())
+
+	prefs, err := loadPreferences(backend)
+	if err != nil {
+		return nil, err
+	}
+	calendarTools.SetPreferences(prefs)
+	if interval := calendar.GoalSyncIntervalFromEnv(); serving && interval > 0 {
+		calendarTools.StartGoalSync(interval, budget)
+		fmt.Fprintf(os.Stderr, "Goal sync active: rescheduling goal blocks every %s when the calendar changes\n", interval)
+		subsystems = append(subsystems, calendar.Subsystem{Name: "goal_sync", Enabled: true, Detail: "every " + interval.String()})
+	} else {
+		subsystems = append(subsystems, calendar.Subsystem{Name: "goal_sync"})
+	}
+	if interval := calendar.HoldCleanupIntervalFromEnv(); serving && interval > 0 {
+		calendarTools.StartHoldCleanup(interval, budget)
+		fmt.Fprintf(os.Stderr, "Hold cleanup active: releasing stale holds every %s\n", interval)
+		subsystems = append(subsystems, calendar.Subsystem{Name: "hold_cleanup", Enabled: true, Detail: "every " + interval.String()})
+	} else {
+		subsystems = append(subsystems, calendar.Subsystem{Name: "hold_cleanup"})
+	}
+	if serving {
+		// serveAgenda starts the watches once the MCP server exists
+		subsystems = append(subsystems,
+			calendar.Subsystem{Name: "agenda_watch", Enabled: true, Detail: "every " + calendar.AgendaIntervalFromEnv().String() + " while the agenda resource is subscribed"},
+			calendar.Subsystem{Name: "event_watch", Enabled: true, Detail: "every " + calendar.AgendaIntervalFromEnv().String() + " while " + calendar.TrackedResourceURI + " is subscribed"},
+			calendar.Subsystem{Name: "strict_arguments", Enabled: mcp.StrictArgumentsFromEnv()})
+	}
+
+	schedulingPolicy, err := loadSchedulingPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if !schedulingPolicy.IsEmpty() {
+		fmt.Fprintf(os.Stderr, "Scheduling policy active: %d rule(s)\n", len(schedulingPolicy.Rules))
+	}
+	calendarTools.SetSchedulingPolicy(schedulingPolicy)
+	subsystems = append(subsystems, calendar.Subsystem{Name: "scheduling_policy", Enabled: !schedulingPolicy.IsEmpty()})
+
+	calendarTools.SetServerInfo(buildInfo(), backend, subsystems)
+	return calendarTools, nil
+}
+
+// newServices creates the Calendar and Drive services for the selected backend.
+func newServices(backend string, budget *quota.Budget, warm *calendar.WarmCache) (*gcal.Service, *drive.Service, error) {
+	switch backend {
+	case "google":
+		auth.SetTransportMiddleware(apiMiddleware(budget, warm))
+
+		// Setup Google Calendar service
+		calendarService, err := auth.GetCalendarService()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to retrieve Calendar client: %v", err)
+		}
+
+		// Setup Google Drive service
+		driveService, err := auth.GetDriveService()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to retrieve Drive client: %v", err)
+		}
+		return calendarService, driveService, nil
+
+	case "fake":
+		fmt.Fprintf(os.Stderr, "Using in-memory fake calendar backend (signed in as %s)\n", fake.DemoOwner)
+		return fake.NewServices(fake.NewDemoStore(time.Now()), apiMiddleware(budget, warm))
+
+	default:
+		return nil, nil, fmt.Errorf("unknown backend %q (expected google or fake)", backend)
+	}
+}
+
+// loadPreferences opens the preferences of the active profile. The fake
+// backend keeps them in memory so demos never touch the real preferences file.
+func loadPreferences(backend string) (*calendar.PreferenceStore, error) {
+	profile := calendar.ProfileFromEnv()
+	if backend == "fake" {
+		return calendar.LoadPreferenceStore("", profile)
+	}
+
+	dir, err := auth.StateDir()
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate preferences: %v", err)
+	}
+	return calendar.LoadPreferenceStore(calendar.PreferencesPath(dir), profile)
+}
+
+// loadSchedulingPolicy reads the scheduling policy from
+// GCAL_MCP_SCHEDULING_POLICY_FILE or scheduling_policy.yaml in the state directory.
+func loadSchedulingPolicy() (*calendar.SchedulingPolicy, error) {
+	dir, err := auth.StateDir()
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate scheduling policy: %v", err)
+	}
+	return calendar.LoadSchedulingPolicy(calendar.SchedulingPolicyPath(dir))
+}
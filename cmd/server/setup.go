@@ -0,0 +1,175 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/auth"
+	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+)
+
+// setupTools is the tool handler used while the Google backend has no
+// credentials or token. It only offers the setup tools; once setup is
+// complete it swaps the server over to the calendar tools.
+type setupTools struct {
+	server  *mcp.Server
+	backend string
+	budget  *quota.Budget
+}
+
+// runSetupMode serves the setup tools until stdin is closed.
+func runSetupMode(backend string, budget *quota.Budget) {
+	setup := &setupTools{backend: backend, budget: budget}
+	server := mcp.NewServer(setup)
+	setup.server = server
+	server.SetHealthCheck(setup.health)
+	describeBuild(server)
+	setStrictArguments(server)
+	for _, tool := range setup.GetTools() {
+		server.RegisterTool(tool)
+	}
+
+	server.LogToStderr("Google Calendar MCP Server starting in setup mode: credentials or token missing")
+	server.LogToStderr("Available tools: setup_status, start_authentication")
+	run(server)
+}
+
+// GetTools returns the tools available before setup is complete.
+func (s *setupTools) GetTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "setup_status",
+			Description: "The calendar server is not set up yet. Check what is missing (OAuth client credentials, sign-in) and get step-by-step instructions. Once setup is complete, this enables the calendar tools.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "start_authentication",
+			Description: "Start signing in to Google: returns a URL the user must open in a browser to grant calendar access. Call setup_status after the user has approved access.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+	}
+}
+
+// HandleTool implements mcp.ToolHandler.
+func (s *setupTools) HandleTool(name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	switch name {
+	case "setup_status":
+		return s.handleSetupStatus()
+	case "start_authentication":
+		return s.handleStartAuthentication()
+	default:
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+}
+
+// health reports the server as unhealthy until setup is complete.
+func (s *setupTools) health() error {
+	if status := auth.CheckSetup(); !status.Ready() {
+		return fmt.Errorf("setup incomplete: call setup_status for instructions")
+	}
+	return auth.TokenHealth()
+}
+
+func (s *setupTools) handleSetupStatus() (*mcp.CallToolResult, error) {
+	status := auth.CheckSetup()
+
+	var result strings.Builder
+	if status.Ready() {
+		calendarTools, err := newCalendarTools(s.backend, s.budget, true)
+		if err != nil {
+			return nil, fmt.Errorf("setup is complete but the calendar tools could not start: %v", err)
+		}
+		s.server.SetTools(calendarTools, scopedTools(s.server, calendarTools))
+		serveAgenda(s.server, calendarTools, s.budget)
+		s.server.SetHealthCheck(auth.TokenHealth)
+		result.WriteString("✅ Setup complete. The calendar tools are now available; if your MCP client does not show them, reconnect the server.\n")
+	} else {
+		result.WriteString(describeSetup(status))
+	}
+
+	statusJSON, _ := json.MarshalIndent(status, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(statusJSON))
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
+
+func (s *setupTools) handleStartAuthentication() (*mcp.CallToolResult, error) {
+	status := auth.CheckSetup()
+	if status.CredentialsError != "" {
+		return nil, fmt.Errorf("OAuth client credentials are needed before signing in: %s. Call setup_status for instructions", status.CredentialsError)
+	}
+
+	authURL, err := auth.StartAuthentication()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start authentication: %v", err)
+	}
+
+	text := fmt.Sprintf("🔑 Open this URL in a browser on this machine and grant access:\n\n%s\n\nThe sign-in page redirects to a local address this server listens on for 5 minutes. After approving, call setup_status to enable the calendar tools.", authURL)
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: text}},
+	}, nil
+}
+
+// describeSetup explains what is missing and the next step.
+func describeSetup(status auth.SetupStatus) string {
+	var result strings.Builder
+	result.WriteString("⚙️ Google Calendar setup is not complete.\n\n")
+
+	if status.CredentialsError != "" {
+		fmt.Fprintf(&result, "❌ OAuth client credentials: %s\n\n", status.CredentialsError)
+		result.WriteString("To create them:\n")
+		result.WriteString("1. In the Google Cloud Console, create a project and enable the Google Calendar API and Google Drive API.\n")
+		result.WriteString("2. Under APIs & Services → Credentials, create an OAuth client ID of type \"Desktop app\".\n")
+		fmt.Fprintf(&result, "3. Download the JSON file and save it as %s (or put its contents in GCAL_MCP_CLIENT_CREDENTIALS_JSON and restart the server).\n", status.CredentialsPath)
+		result.WriteString("4. Call setup_status again, then start_authentication.\n")
+		return result.String()
+	}
+	fmt.Fprintf(&result, "✅ OAuth client credentials (%s)\n", credentialsSourceName(status))
+
+	if status.TokenError != "" {
+		fmt.Fprintf(&result, "❌ Token: %s\n", status.TokenError)
+		result.WriteString("   If the token was saved encrypted, set GCAL_MCP_TOKEN_KEY and restart; otherwise sign in again.\n")
+	}
+
+	switch status.Authentication {
+	case auth.AuthWaiting:
+		fmt.Fprintf(&result, "⏳ Waiting for you to approve access at:\n%s\nCall setup_status again once approved.\n", status.AuthURL)
+	case auth.AuthFailed:
+		fmt.Fprintf(&result, "❌ Sign-in failed: %s\nCall start_authentication to try again.\n", status.AuthError)
+	default:
+		result.WriteString("❌ Not signed in yet. Call start_authentication to get the sign-in URL.\n")
+	}
+	return result.String()
+}
+
+func credentialsSourceName(status auth.SetupStatus) string {
+	if status.CredentialsSource == "file" {
+		return status.CredentialsPath
+	}
+	return status.CredentialsSource
+}
@@ -0,0 +1,157 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// callbackAddrEnv overrides the address the OAuth callback server binds to
+	callbackAddrEnv = "GCAL_MCP_OAUTH_CALLBACK_ADDR"
+	// defaultCallbackAddr binds an ephemeral loopback port, which Google
+	// accepts as a redirect for desktop OAuth clients
+	defaultCallbackAddr = "127.0.0.1:0"
+)
+
+// callbackServer receives the OAuth authorization code on a local listener.
+// Each authentication attempt gets its own listener and ServeMux, so repeated
+// attempts never collide with each other or with other handlers.
+type callbackServer struct {
+	server      *http.Server
+	redirectURL string
+	state       string
+	codeCh      chan string
+	errCh       chan error
+}
+
+// callbackAddr returns the configured callback bind address.
+func callbackAddr() string {
+	if addr := os.Getenv(callbackAddrEnv); addr != "" {
+		return addr
+	}
+	return defaultCallbackAddr
+}
+
+// startCallbackServer listens on addr and serves the OAuth redirect. Only
+// requests to the root path carrying the expected state are accepted.
+func startCallbackServer(addr, state string) (*callbackServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start local server on %s: %v", addr, err)
+	}
+
+	cs := &callbackServer{
+		redirectURL: redirectURLFor(addr, listener.Addr()),
+		state:       state,
+		codeCh:      make(chan string, 1),
+		errCh:       make(chan error, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", cs.handleCallback)
+	cs.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := cs.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			cs.sendErr(fmt.Errorf("local server failed: %v", err))
+		}
+	}()
+	return cs, nil
+}
+
+func (cs *callbackServer) handleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// A mismatched state may be a forged request; reject it but keep waiting
+	// for the genuine redirect
+	if query.Get("state") != cs.state {
+		http.Error(w, "Invalid OAuth state parameter", http.StatusBadRequest)
+		return
+	}
+
+	if errCode := query.Get("error"); errCode != "" {
+		http.Error(w, "Authorization failed: "+errCode, http.StatusBadRequest)
+		cs.sendErr(fmt.Errorf("authorization denied: %s", errCode))
+		return
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		http.Error(w, "No authorization code received", http.StatusBadRequest)
+		cs.sendErr(fmt.Errorf("no authorization code received"))
+		return
+	}
+
+	// Send success response to browser
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = fmt.Fprintf(w, `
+		<html>
+		<head><title>Authorization Successful</title></head>
+		<body>
+			<h1>Authorization Successful!</h1>
+			<p>You can close this window and return to the terminal.</p>
+		</body>
+		</html>
+	`)
+
+	select {
+	case cs.codeCh <- code:
+	default:
+	}
+}
+
+// sendErr reports an error without blocking if one is already pending.
+func (cs *callbackServer) sendErr(err error) {
+	select {
+	case cs.errCh <- err:
+	default:
+	}
+}
+
+// shutdown stops the callback server.
+func (cs *callbackServer) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cs.server.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "server shutdown error: %v\n", err)
+	}
+}
+
+// redirectURLFor builds the redirect URL for a listener bound from addr. The
+// port always comes from the listener, so ephemeral ports work. A host name in
+// addr (e.g. "localhost:8080") is kept so it matches a registered redirect
+// exactly; unspecified hosts become the IPv4 loopback address.
+func redirectURLFor(addr string, listenerAddr net.Addr) string {
+	_, port, err := net.SplitHostPort(listenerAddr.String())
+	if err != nil {
+		return "http://" + listenerAddr.String()
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		host = "127.0.0.1"
+	} else if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// clientCredentialsEnv supplies the OAuth client configuration (the contents
+	// of credentials.json) as raw or base64-encoded JSON
+	clientCredentialsEnv = "GCAL_MCP_CLIENT_CREDENTIALS_JSON"
+	// tokenJSONEnv supplies the OAuth token (the contents of token.json) as raw
+	// or base64-encoded JSON
+	tokenJSONEnv = "GCAL_MCP_TOKEN_JSON"
+)
+
+// decodeEnvJSON returns the JSON document held in an environment variable value.
+// Values starting with '{' are taken as raw JSON; anything else is base64-decoded.
+func decodeEnvJSON(name, value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		return []byte(value), nil
+	}
+
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(value); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is neither JSON nor base64-encoded JSON", name)
+}
+
+// loadClientCredentials returns the OAuth client configuration JSON, preferring
+// GCAL_MCP_CLIENT_CREDENTIALS_JSON and falling back to the credentials file.
+func loadClientCredentials(credPath string) ([]byte, error) {
+	if value := os.Getenv(clientCredentialsEnv); value != "" {
+		return decodeEnvJSON(clientCredentialsEnv, value)
+	}
+
+	b, err := os.ReadFile(credPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client secret file from %s (or set %s): %v", credPath, clientCredentialsEnv, err)
+	}
+	return b, nil
+}
+
+// tokenFromEnv returns the token supplied through GCAL_MCP_TOKEN_JSON. It returns
+// nil with no error when the variable is unset.
+func tokenFromEnv() (*oauth2.Token, error) {
+	value := os.Getenv(tokenJSONEnv)
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := decodeEnvJSON(tokenJSONEnv, value)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := decodeToken(data, tokenPassphrase())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", tokenJSONEnv, err)
+	}
+	return tok, nil
+}
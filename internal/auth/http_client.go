@@ -0,0 +1,156 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// proxyURLEnv routes every request through this proxy; without it the
+	// standard HTTPS_PROXY, HTTP_PROXY and NO_PROXY variables apply
+	proxyURLEnv = "GCAL_MCP_PROXY_URL"
+	// caFileEnv names a PEM file of extra CA certificates to trust, e.g. a
+	// corporate TLS-inspecting proxy's, in addition to the system ones
+	caFileEnv = "GCAL_MCP_CA_FILE"
+	// httpTimeoutEnv bounds each request, as a Go duration such as "30s"
+	httpTimeoutEnv = "GCAL_MCP_HTTP_TIMEOUT"
+	// httpKeepAliveEnv sets the TCP keep-alive period; "0" turns off
+	// connection reuse for networks that drop idle connections
+	httpKeepAliveEnv = "GCAL_MCP_HTTP_KEEPALIVE"
+
+	// DefaultHTTPTimeout is the request timeout when GCAL_MCP_HTTP_TIMEOUT is unset
+	DefaultHTTPTimeout = 60 * time.Second
+	// defaultKeepAlive matches net/http's default transport
+	defaultKeepAlive = 30 * time.Second
+)
+
+// HTTPConfig configures the HTTP client used for OAuth and the Google APIs.
+type HTTPConfig struct {
+	ProxyURL  *url.URL      // nil to use the proxy environment variables
+	CAFile    string        // extra CA certificates; "" for the system ones only
+	Timeout   time.Duration // per request; 0 for none
+	KeepAlive time.Duration // TCP keep-alive period; 0 disables connection reuse
+}
+
+// HTTPConfigFromEnv reads the HTTP client configuration from the environment.
+// Invalid values are reported and ignored.
+func HTTPConfigFromEnv() HTTPConfig {
+	config := HTTPConfig{Timeout: DefaultHTTPTimeout, KeepAlive: defaultKeepAlive}
+	if value := os.Getenv(proxyURLEnv); value != "" {
+		if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+			config.ProxyURL = u
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", proxyURLEnv, value)
+		}
+	}
+	config.CAFile = os.Getenv(caFileEnv)
+	if value := os.Getenv(httpTimeoutEnv); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			config.Timeout = d
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", httpTimeoutEnv, value)
+		}
+	}
+	if value := os.Getenv(httpKeepAliveEnv); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			config.KeepAlive = d
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", httpKeepAliveEnv, value)
+		}
+	}
+	return config
+}
+
+// NewHTTPClient builds an HTTP client from config. It fails when the CA file
+// cannot be read or holds no certificates, rather than silently falling back
+// to connections that the proxy would reject.
+func NewHTTPClient(config HTTPConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.Proxy = http.ProxyFromEnvironment
+	if config.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(config.ProxyURL)
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %v", caFileEnv, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s (%s)", config.CAFile, caFileEnv)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: config.KeepAlive}
+	if config.KeepAlive == 0 {
+		dialer.KeepAlive = -1
+		transport.DisableKeepAlives = true
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Transport: transport, Timeout: config.Timeout}, nil
+}
+
+var (
+	// baseClientMu guards the HTTP client that OAuth and API requests share
+	baseClientMu sync.Mutex
+	baseClient   *http.Client
+)
+
+// baseHTTPClient returns the HTTP client built from the environment, creating
+// it on first use.
+func baseHTTPClient() (*http.Client, error) {
+	baseClientMu.Lock()
+	defer baseClientMu.Unlock()
+
+	if baseClient == nil {
+		client, err := NewHTTPClient(HTTPConfigFromEnv())
+		if err != nil {
+			return nil, err
+		}
+		baseClient = client
+	}
+	return baseClient, nil
+}
+
+// oauthContext returns a context that makes the oauth2 package send token
+// exchanges and refreshes through the configured HTTP client.
+func oauthContext() (context.Context, error) {
+	client, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(context.Background(), oauth2.HTTPClient, client), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ----- HTTPConfigFromEnv -----
+
+func TestHTTPConfigFromEnv(t *testing.T) {
+	t.Setenv(proxyURLEnv, "")
+	t.Setenv(caFileEnv, "")
+	t.Setenv(httpTimeoutEnv, "")
+	t.Setenv(httpKeepAliveEnv, "")
+	config := HTTPConfigFromEnv()
+	if config.ProxyURL != nil || config.CAFile != "" || config.Timeout != DefaultHTTPTimeout || config.KeepAlive != defaultKeepAlive {
+		t.Errorf("defaults = %+v", config)
+	}
+
+	t.Setenv(proxyURLEnv, "http://proxy.corp.example:3128")
+	t.Setenv(caFileEnv, "/etc/corp/ca.pem")
+	t.Setenv(httpTimeoutEnv, "15s")
+	t.Setenv(httpKeepAliveEnv, "0")
+	config = HTTPConfigFromEnv()
+	if config.ProxyURL == nil || config.ProxyURL.Host != "proxy.corp.example:3128" {
+		t.Errorf("proxy = %v", config.ProxyURL)
+	}
+	if config.CAFile != "/etc/corp/ca.pem" || config.Timeout != 15*time.Second || config.KeepAlive != 0 {
+		t.Errorf("config = %+v", config)
+	}
+
+	// Invalid values keep the defaults
+	t.Setenv(proxyURLEnv, "proxy.corp.example")
+	t.Setenv(httpTimeoutEnv, "soon")
+	t.Setenv(httpKeepAliveEnv, "-1s")
+	config = HTTPConfigFromEnv()
+	if config.ProxyURL != nil || config.Timeout != DefaultHTTPTimeout || config.KeepAlive != defaultKeepAlive {
+		t.Errorf("invalid values not ignored: %+v", config)
+	}
+}
+
+// ----- NewHTTPClient -----
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	config := HTTPConfigFromEnv()
+	req, _ := http.NewRequest("GET", "https://www.googleapis.com/calendar/v3/users/me/calendarList", nil)
+
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	if proxy, _ := client.Transport.(*http.Transport).Proxy(req); proxy != nil {
+		t.Errorf("proxy without configuration = %v", proxy)
+	}
+
+	t.Setenv(proxyURLEnv, "http://proxy.corp.example:3128")
+	client, err = NewHTTPClient(HTTPConfigFromEnv())
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	if proxy, _ := client.Transport.(*http.Transport).Proxy(req); proxy == nil || proxy.Host != "proxy.corp.example:3128" {
+		t.Errorf("proxy = %v, want proxy.corp.example:3128", proxy)
+	}
+}
+
+func TestNewHTTPClient_KeepAlive(t *testing.T) {
+	client, err := NewHTTPClient(HTTPConfig{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	if !client.Transport.(*http.Transport).DisableKeepAlives {
+		t.Error("keep-alives not disabled for KeepAlive 0")
+	}
+	if client.Timeout != 5*time.Second {
+		t.Errorf("timeout = %v, want 5s", client.Timeout)
+	}
+}
+
+func TestNewHTTPClient_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	// Without the server's certificate the TLS handshake fails
+	client, err := NewHTTPClient(HTTPConfig{Timeout: 5 * time.Second, KeepAlive: defaultKeepAlive})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("request to a server with an unknown CA succeeded")
+	}
+
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewHTTPClient(HTTPConfig{CAFile: caFile, Timeout: 5 * time.Second, KeepAlive: defaultKeepAlive})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with the CA file: %v", err)
+	}
+	resp.Body.Close()
+
+	// Missing or empty CA files are errors, not silent fallbacks
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing.pem"), empty} {
+		if _, err := NewHTTPClient(HTTPConfig{CAFile: path}); err == nil {
+			t.Errorf("CA file %s accepted", path)
+		}
+	}
+}
@@ -20,16 +20,15 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
@@ -56,6 +55,8 @@ const (
 	tokenExpiryBuffer = 5 * time.Minute
 	// stateTokenLength is the length in bytes of the random state token
 	stateTokenLength = 32
+	// authTimeout is how long the browser flow waits for the user
+	authTimeout = 5 * time.Minute
 )
 
 // generateStateToken generates a cryptographically secure random state token
@@ -104,46 +105,110 @@ func findRepositoryRoot() (string, error) {
 	return "", fmt.Errorf("repository root not found (no go.mod or .git found)")
 }
 
+// StateDir returns the directory holding the credentials and token files:
+// the repository root, or the current working directory if there is none.
+func StateDir() (string, error) {
+	// Try to find repository root
+	if repoRoot, err := findRepositoryRoot(); err == nil {
+		return repoRoot, nil
+	}
+
+	// Fallback to current working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("unable to get current working directory: %v", err)
+	}
+	return cwd, nil
+}
+
 // getCredentialPaths returns the full paths for credentials and token files
 // First tries repository root, then falls back to current working directory
 func getCredentialPaths() (string, string, error) {
-	var credPath, tokenPath string
-
-	// Try to find repository root
-	if repoRoot, err := findRepositoryRoot(); err == nil {
-		credPath = filepath.Join(repoRoot, credentialsFile)
-		tokenPath = filepath.Join(repoRoot, tokenFile)
-	} else {
-		// Fallback to current working directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			return "", "", fmt.Errorf("unable to get current working directory: %v", err)
-		}
-		credPath = filepath.Join(cwd, credentialsFile)
-		tokenPath = filepath.Join(cwd, tokenFile)
+	dir, err := StateDir()
+	if err != nil {
+		return "", "", err
 	}
+	return filepath.Join(dir, credentialsFile), filepath.Join(dir, tokenFile), nil
+}
+
+var (
+	// sharedClientMu guards the HTTP client and token refresher shared by the
+	// Calendar and Drive services, so both use (and refresh) the same token.
+	sharedClientMu  sync.Mutex
+	sharedClient    *http.Client
+	sharedRefresher *TokenRefresher
+	// transportMiddleware, if set, wraps the shared client's transport
+	transportMiddleware func(http.RoundTripper) http.RoundTripper
+)
 
-	return credPath, tokenPath, nil
+// SetTransportMiddleware installs a wrapper around the transport of the HTTP
+// client used for Google API calls. It must be called before the first
+// service is created.
+func SetTransportMiddleware(middleware func(http.RoundTripper) http.RoundTripper) {
+	sharedClientMu.Lock()
+	defer sharedClientMu.Unlock()
+	transportMiddleware = middleware
 }
 
-// getGoogleHTTPClient returns an authenticated HTTP client with Calendar and Drive scopes.
+// TokenHealth reports the result of the most recent background token refresh.
+// It returns nil when the token is healthy or no client has been created yet.
+func TokenHealth() error {
+	sharedClientMu.Lock()
+	refresher := sharedRefresher
+	sharedClientMu.Unlock()
+
+	if refresher == nil {
+		return nil
+	}
+	if err := refresher.Health(); err != nil {
+		return fmt.Errorf("oauth token refresh failing: %v", err)
+	}
+	return nil
+}
+
+// getGoogleHTTPClient returns an authenticated HTTP client. Its token carries
+// the scopes granted so far; RequireScopes asks for more when a tool needs them.
+// The client is created once and shared; its token is refreshed in the background.
 func getGoogleHTTPClient() (*http.Client, error) {
+	sharedClientMu.Lock()
+	defer sharedClientMu.Unlock()
+
+	if sharedClient != nil {
+		return sharedClient, nil
+	}
+
 	credPath, tokenPath, err := getCredentialPaths()
 	if err != nil {
 		return nil, fmt.Errorf("unable to determine credential paths: %v", err)
 	}
 
-	b, err := os.ReadFile(credPath)
+	config, err := oauthConfig(credPath)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read client secret file from %s: %v", credPath, err)
+		return nil, err
 	}
 
-	config, err := google.ConfigFromJSON(b, calendar.CalendarScope, drive.DriveReadonlyScope)
+	tok, err := getToken(config, tokenPath)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
+		return nil, err
 	}
 
-	return getClient(config, tokenPath)
+	base, err := baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	refresher := newTokenRefresher(config, tokenPath, tok)
+	refresher.Start(context.Background())
+
+	// API requests go through the configured transport; oauth2.NewClient
+	// keeps only its transport, so the timeout is carried over explicitly
+	sharedRefresher = refresher
+	sharedClient = oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, base), refresher)
+	sharedClient.Timeout = base.Timeout
+	if transportMiddleware != nil {
+		sharedClient.Transport = transportMiddleware(sharedClient.Transport)
+	}
+	return sharedClient, nil
 }
 
 // GetCalendarService creates and returns a new Google Calendar API service client.
@@ -177,8 +242,18 @@ func GetDriveService() (*drive.Service, error) {
 	return srv, nil
 }
 
-func getClient(config *oauth2.Config, tokenPath string) (*http.Client, error) {
-	tok, err := tokenFromFile(tokenPath)
+// getToken loads the cached token, refreshing it or running the browser flow as
+// needed, and returns a token that is valid right now. A token supplied through
+// GCAL_MCP_TOKEN_JSON takes precedence over the token file.
+func getToken(config *oauth2.Config, tokenPath string) (*oauth2.Token, error) {
+	tok, err := tokenFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	fromEnv := tok != nil
+	if !fromEnv {
+		tok, err = tokenFromFile(tokenPath)
+	}
 	if err != nil {
 		// No token file - need to authenticate
 		tok, err = getTokenFromWeb(config)
@@ -188,7 +263,7 @@ func getClient(config *oauth2.Config, tokenPath string) (*http.Client, error) {
 		if err := saveTokenSafe(tokenPath, tok); err != nil {
 			return nil, err
 		}
-		return config.Client(context.Background(), tok), nil
+		return tok, nil
 	}
 
 	// Check if token is valid (with buffer time)
@@ -203,15 +278,19 @@ func getClient(config *oauth2.Config, tokenPath string) (*http.Client, error) {
 				return nil, err
 			}
 		} else {
-			tok = newTok
+			tok = mergeTokens(tok, newTok)
 			fmt.Fprintf(os.Stderr, "Token refreshed successfully\n")
 		}
 		if err := saveTokenSafe(tokenPath, tok); err != nil {
-			return nil, err
+			// Env-supplied tokens are commonly used on read-only filesystems
+			if !fromEnv {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "Token refreshed but could not be saved: %v\n", err)
 		}
 	}
 
-	return config.Client(context.Background(), tok), nil
+	return tok, nil
 }
 
 // isTokenValid checks if the token is valid with a buffer time before expiry
@@ -233,10 +312,15 @@ func refreshToken(config *oauth2.Config, tok *oauth2.Token) (*oauth2.Token, erro
 		return nil, fmt.Errorf("no refresh token available")
 	}
 
-	// Create a token source that will refresh the token
-	tokenSource := config.TokenSource(context.Background(), tok)
+	// Create a token source from the refresh token alone so the access token is
+	// always exchanged, even if oauth2 would still consider it valid
+	ctx, err := oauthContext()
+	if err != nil {
+		return nil, err
+	}
+	tokenSource := config.TokenSource(ctx, &oauth2.Token{RefreshToken: tok.RefreshToken})
 
-	// Get a new token (this will use the refresh token if the access token is expired)
+	// Get a new token using the refresh token
 	newTok, err := tokenSource.Token()
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %v", err)
@@ -246,46 +330,33 @@ func refreshToken(config *oauth2.Config, tok *oauth2.Token) (*oauth2.Token, erro
 }
 
 func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
-	// Set up a local server to handle the OAuth callback
-	codeCh := make(chan string)
-	errCh := make(chan error)
-
-	// Create a new ServeMux to avoid conflicts with previously registered handlers
-	mux := http.NewServeMux()
-	server := &http.Server{Addr: ":8080", Handler: mux}
-
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errCh <- fmt.Errorf("no authorization code received")
-			return
-		}
+	web, err := beginWebAuth(config)
+	if err != nil {
+		return nil, err
+	}
 
-		// Send success response to browser
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = fmt.Fprintf(w, `
-			<html>
-			<head><title>Authorization Successful</title></head>
-			<body>
-				<h1>Authorization Successful!</h1>
-				<p>You can close this window and return to the terminal.</p>
-			</body>
-			</html>
-		`)
-
-		codeCh <- code
-	})
-
-	// Start the server in a goroutine
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCh <- fmt.Errorf("failed to start local server: %v", err)
-		}
-	}()
+	// Display OAuth URL prominently to stderr (visible in MCP context)
+	displayAuthURL(web.authURL)
+
+	tok, err := web.wait(authTimeout)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Authentication successful!\n")
+	return tok, nil
+}
 
-	// Update config to use localhost:8080 as redirect URI
-	config.RedirectURL = "http://localhost:8080"
+// webAuth is a browser authorization in progress: the callback server is
+// listening and the user has to visit authURL.
+type webAuth struct {
+	config   *oauth2.Config
+	callback *callbackServer
+	authURL  string
+}
 
+// beginWebAuth starts the callback server and builds the authorization URL
+// for config's scopes; opts are added to the URL.
+func beginWebAuth(config *oauth2.Config, opts ...oauth2.AuthCodeOption) (*webAuth, error) {
 	// Generate a secure random state token
 	stateToken, err := generateStateToken()
 	if err != nil {
@@ -295,60 +366,65 @@ func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
 		}
 	}
 
-	authURL := config.AuthCodeURL(stateToken, oauth2.AccessTypeOffline)
+	// Set up a local server to handle the OAuth callback
+	callback, err := startCallbackServer(callbackAddr(), stateToken)
+	if err != nil {
+		return nil, &AuthError{
+			Message:   fmt.Sprintf("OAuth error: %v", err),
+			NeedsAuth: true,
+		}
+	}
+
+	// Redirect to the address the callback server actually listens on. Copy the
+	// config so the caller's redirect URL is left untouched.
+	webConfig := *config
+	webConfig.RedirectURL = callback.redirectURL
 
-	// Display OAuth URL prominently to stderr (visible in MCP context)
-	displayAuthURL(authURL)
+	return &webAuth{
+		config:   &webConfig,
+		callback: callback,
+		authURL:  webConfig.AuthCodeURL(stateToken, append([]oauth2.AuthCodeOption{oauth2.AccessTypeOffline}, opts...)...),
+	}, nil
+}
+
+// wait blocks until the user approves or denies access, or timeout passes,
+// then exchanges the authorization code for a token and stops the callback
+// server.
+func (a *webAuth) wait(timeout time.Duration) (*oauth2.Token, error) {
+	defer a.callback.shutdown()
 
 	// Wait for either the code or an error
 	var authCode string
 	select {
-	case authCode = <-codeCh:
+	case authCode = <-a.callback.codeCh:
 		// Success - we got the code
-	case err := <-errCh:
-		// Shutdown the server before returning
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
-			fmt.Fprintf(os.Stderr, "server shutdown error: %v\n", shutdownErr)
-		}
+	case err := <-a.callback.errCh:
 		return nil, &AuthError{
 			Message:   fmt.Sprintf("OAuth error: %v", err),
-			AuthURL:   authURL,
+			AuthURL:   a.authURL,
 			NeedsAuth: true,
 		}
-	case <-time.After(5 * time.Minute):
-		// Shutdown the server before returning
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
-			fmt.Fprintf(os.Stderr, "server shutdown error: %v\n", shutdownErr)
-		}
+	case <-time.After(timeout):
 		return nil, &AuthError{
-			Message:   "Timeout waiting for authorization (5 minutes)",
-			AuthURL:   authURL,
+			Message:   fmt.Sprintf("Timeout waiting for authorization (%d minutes)", int(timeout/time.Minute)),
+			AuthURL:   a.authURL,
 			NeedsAuth: true,
 		}
 	}
 
-	// Shutdown the temporary server
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "server shutdown error: %v\n", err)
-	}
-
 	// Exchange the code for a token
-	tok, err := config.Exchange(context.TODO(), authCode)
+	ctx, err := oauthContext()
+	if err != nil {
+		return nil, err
+	}
+	tok, err := a.config.Exchange(ctx, authCode)
 	if err != nil {
 		return nil, &AuthError{
 			Message:   fmt.Sprintf("Unable to exchange authorization code for token: %v", err),
-			AuthURL:   authURL,
+			AuthURL:   a.authURL,
 			NeedsAuth: true,
 		}
 	}
-
-	fmt.Fprintf(os.Stderr, "Authentication successful!\n")
 	return tok, nil
 }
 
@@ -364,27 +440,32 @@ func displayAuthURL(authURL string) {
 }
 
 func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
+	data, err := os.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = f.Close() }()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
+	return decodeToken(data, tokenPassphrase())
 }
 
-// saveTokenSafe saves the token to a file and returns an error instead of calling log.Fatalf
+// saveTokenSafe saves the token to a file and returns an error instead of calling log.Fatalf.
+// The token is encrypted at rest when GCAL_MCP_TOKEN_KEY is set.
 func saveTokenSafe(path string, token *oauth2.Token) error {
 	fmt.Fprintf(os.Stderr, "Saving credential file to: %s\n", path)
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("unable to cache oauth token: %v", err)
+
+	var data []byte
+	var err error
+	if passphrase := tokenPassphrase(); passphrase != nil {
+		data, err = encryptToken(token, passphrase)
+	} else {
+		data, err = encodeToken(token)
 	}
-	defer func() { _ = f.Close() }()
-	if err := json.NewEncoder(f).Encode(token); err != nil {
+	if err != nil {
 		return fmt.Errorf("unable to encode oauth token: %v", err)
 	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("unable to cache oauth token: %v", err)
+	}
 	return nil
 }
 
@@ -15,12 +15,21 @@
 package auth
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
 )
 
 // ----- isTokenValid -----
@@ -132,3 +141,446 @@ func TestGenerateStateToken(t *testing.T) {
 		t.Error("consecutive state tokens should differ (random)")
 	}
 }
+
+// ----- token encryption at rest -----
+
+func TestEncryptTokenRoundTrip(t *testing.T) {
+	key := []byte("correct horse battery staple")
+	tok := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+
+	data, err := encryptToken(tok, key)
+	if err != nil {
+		t.Fatalf("encryptToken() error: %v", err)
+	}
+	if strings.Contains(string(data), "refresh") {
+		t.Error("encrypted payload should not contain the plaintext token")
+	}
+
+	got, err := decodeToken(data, key)
+	if err != nil {
+		t.Fatalf("decodeToken() error: %v", err)
+	}
+	if got.AccessToken != "access" || got.RefreshToken != "refresh" {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	if _, err := decodeToken(data, nil); err == nil {
+		t.Error("expected error decoding encrypted token without a key")
+	}
+	if _, err := decodeToken(data, []byte("wrong passphrase")); err == nil {
+		t.Error("expected error decoding encrypted token with the wrong key")
+	}
+
+	// Each save uses a fresh salt, so the same token and passphrase never
+	// produce the same key or ciphertext
+	again, err := encryptToken(tok, key)
+	if err != nil {
+		t.Fatalf("encryptToken() error: %v", err)
+	}
+	var first, second encryptedToken
+	_ = json.Unmarshal(data, &first)
+	_ = json.Unmarshal(again, &second)
+	if len(first.Salt) != tokenSaltSize || string(first.Salt) == string(second.Salt) {
+		t.Errorf("salts %x and %x, want distinct %d-byte salts", first.Salt, second.Salt, tokenSaltSize)
+	}
+}
+
+func TestDecodeToken_CorruptedNonce(t *testing.T) {
+	key := []byte("correct horse battery staple")
+	data, err := encryptToken(&oauth2.Token{AccessToken: "access"}, key)
+	if err != nil {
+		t.Fatalf("encryptToken() error: %v", err)
+	}
+	var envelope encryptedToken
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+
+	// A truncated or hand-edited file must fail to decode, not panic
+	for _, nonce := range [][]byte{nil, envelope.Nonce[:4], append(envelope.Nonce, 0)} {
+		envelope.Nonce = nonce
+		corrupted, _ := json.Marshal(envelope)
+		if _, err := decodeToken(corrupted, key); err == nil || !strings.Contains(err.Error(), "nonce") {
+			t.Errorf("nonce of %d bytes: error = %v, want a corrupted nonce error", len(nonce), err)
+		}
+	}
+}
+
+func TestDecodeToken_PlainJSONWithKey(t *testing.T) {
+	// Plain tokens written before encryption was enabled must still load
+	got, err := decodeToken([]byte(`{"access_token":"plain"}`), []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("decodeToken() error: %v", err)
+	}
+	if got.AccessToken != "plain" {
+		t.Errorf("expected access token 'plain', got %q", got.AccessToken)
+	}
+}
+
+func TestSaveTokenSafe_Encrypted(t *testing.T) {
+	t.Setenv(tokenKeyEnv, "test-passphrase")
+	path := filepath.Join(t.TempDir(), "token.json")
+
+	if err := saveTokenSafe(path, &oauth2.Token{AccessToken: "secret-access"}); err != nil {
+		t.Fatalf("saveTokenSafe() error: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading token file: %v", err)
+	}
+	if strings.Contains(string(raw), "secret-access") {
+		t.Error("access token should not appear in plaintext on disk")
+	}
+
+	tok, err := tokenFromFile(path)
+	if err != nil {
+		t.Fatalf("tokenFromFile() error: %v", err)
+	}
+	if tok.AccessToken != "secret-access" {
+		t.Errorf("expected decrypted access token, got %q", tok.AccessToken)
+	}
+}
+
+// ----- TokenRefresher -----
+
+func TestTokenRefresher_ReturnsValidTokenWithoutRefresh(t *testing.T) {
+	tok := &oauth2.Token{AccessToken: "valid", Expiry: time.Now().Add(time.Hour)}
+	r := newTokenRefresher(&oauth2.Config{}, filepath.Join(t.TempDir(), "token.json"), tok)
+
+	got, err := r.Token()
+	if err != nil {
+		t.Fatalf("Token() error: %v", err)
+	}
+	if got.AccessToken != "valid" {
+		t.Errorf("expected cached token, got %q", got.AccessToken)
+	}
+	if r.Health() != nil {
+		t.Errorf("expected healthy refresher, got %v", r.Health())
+	}
+}
+
+func TestTokenRefresher_FailedRefreshMarksUnhealthy(t *testing.T) {
+	// Expired token with no refresh token cannot be refreshed
+	tok := &oauth2.Token{AccessToken: "stale", Expiry: time.Now().Add(-time.Hour)}
+	r := newTokenRefresher(&oauth2.Config{}, filepath.Join(t.TempDir(), "token.json"), tok)
+
+	if _, err := r.Token(); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if r.Health() == nil {
+		t.Error("expected refresher to report unhealthy after failed refresh")
+	}
+	if r.nextRefreshIn() != refreshRetryInterval {
+		t.Errorf("expected retry interval after failure, got %v", r.nextRefreshIn())
+	}
+}
+
+func TestTokenRefresher_NextRefreshBeforeExpiry(t *testing.T) {
+	tok := &oauth2.Token{AccessToken: "valid", Expiry: time.Now().Add(time.Hour)}
+	r := newTokenRefresher(&oauth2.Config{}, "", tok)
+
+	wait := r.nextRefreshIn()
+	if wait > time.Hour-tokenExpiryBuffer || wait < time.Hour-tokenExpiryBuffer-time.Minute {
+		t.Errorf("expected refresh about %v from now, got %v", time.Hour-tokenExpiryBuffer, wait)
+	}
+}
+
+// ----- incremental consent -----
+
+func TestTokenScopes_SavedWithToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	tok := withScopes(&oauth2.Token{AccessToken: "a"}, baseScopes)
+	if err := saveTokenSafe(path, tok); err != nil {
+		t.Fatalf("saveTokenSafe() error: %v", err)
+	}
+	loaded, err := tokenFromFile(path)
+	if err != nil {
+		t.Fatalf("tokenFromFile() error: %v", err)
+	}
+	if got := tokenScopes(loaded); len(got) != 1 || got[0] != calendar.CalendarScope {
+		t.Errorf("expected only the base scope, got %v", got)
+	}
+
+	// Tokens saved before scopes were recorded were granted every scope
+	legacy, _ := decodeToken([]byte(`{"access_token":"old"}`), nil)
+	if missing := missingScopes(legacy, []string{drive.DriveReadonlyScope}); len(missing) != 0 {
+		t.Errorf("legacy token should have Drive access, missing %v", missing)
+	}
+}
+
+func TestMergeTokens(t *testing.T) {
+	old := withScopes(&oauth2.Token{AccessToken: "old", RefreshToken: "refresh"}, baseScopes)
+	issued := withScopes(&oauth2.Token{AccessToken: "new"}, []string{drive.DriveReadonlyScope})
+
+	merged := mergeTokens(old, issued)
+	if merged.AccessToken != "new" || merged.RefreshToken != "refresh" {
+		t.Errorf("expected the new access token and the old refresh token, got %+v", merged)
+	}
+	if missing := missingScopes(merged, legacyScopes); len(missing) != 0 {
+		t.Errorf("merged token should keep both grants, missing %v", missing)
+	}
+}
+
+func TestRequireScopes_AsksForMissingScopes(t *testing.T) {
+	t.Setenv(clientCredentialsEnv, testClientCredentials)
+	t.Setenv(callbackAddrEnv, "127.0.0.1:0")
+	config, err := oauthConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok := withScopes(&oauth2.Token{AccessToken: "a", RefreshToken: "r"}, baseScopes)
+	r := newTokenRefresher(config, filepath.Join(t.TempDir(), "token.json"), tok)
+
+	if err := r.requireScopes(baseScopes); err != nil {
+		t.Errorf("granted scopes should pass, got %v", err)
+	}
+
+	err = r.requireScopes([]string{drive.DriveReadonlyScope})
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || !authErr.NeedsAuth {
+		t.Fatalf("expected an AuthError, got %v", err)
+	}
+	parsed, err := url.Parse(authErr.AuthURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := parsed.Query(); q.Get("scope") != drive.DriveReadonlyScope || q.Get("include_granted_scopes") != "true" {
+		t.Errorf("expected an incremental request for Drive only, got %q", authErr.AuthURL)
+	}
+
+	// Asking again while waiting returns the same URL
+	authURL := authErr.AuthURL
+	err = r.requireScopes([]string{drive.DriveReadonlyScope})
+	if !errors.As(err, &authErr) || authErr.AuthURL != authURL {
+		t.Errorf("expected the pending URL again, got %v", err)
+	}
+
+	r.addGrant(withScopes(&oauth2.Token{AccessToken: "b"}, []string{drive.DriveReadonlyScope}))
+	if err := r.requireScopes(legacyScopes); err != nil {
+		t.Errorf("expected every scope after the grant, got %v", err)
+	}
+	if got, _ := r.Token(); got.RefreshToken != "r" {
+		t.Errorf("the refresh token should survive the grant, got %q", got.RefreshToken)
+	}
+}
+
+// ----- environment credentials -----
+
+func TestDecodeEnvJSON(t *testing.T) {
+	raw := `{"installed":{"client_id":"abc"}}`
+	cases := []struct {
+		name  string
+		value string
+	}{
+		{"raw JSON", raw},
+		{"raw JSON with whitespace", "  " + raw + "\n"},
+		{"base64", base64.StdEncoding.EncodeToString([]byte(raw))},
+		{"unpadded base64", base64.RawStdEncoding.EncodeToString([]byte(raw))},
+		{"url-safe base64", base64.URLEncoding.EncodeToString([]byte(raw))},
+	}
+	for _, tc := range cases {
+		got, err := decodeEnvJSON(clientCredentialsEnv, tc.value)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if string(got) != raw {
+			t.Errorf("%s: got %q, want %q", tc.name, got, raw)
+		}
+	}
+
+	if _, err := decodeEnvJSON(clientCredentialsEnv, "not json!"); err == nil {
+		t.Error("expected error for value that is neither JSON nor base64")
+	}
+}
+
+func TestLoadClientCredentials_EnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, []byte(`{"from":"file"}`), 0600); err != nil {
+		t.Fatalf("writing credentials file: %v", err)
+	}
+
+	got, err := loadClientCredentials(path)
+	if err != nil || string(got) != `{"from":"file"}` {
+		t.Errorf("expected file contents, got %q (err %v)", got, err)
+	}
+
+	t.Setenv(clientCredentialsEnv, `{"from":"env"}`)
+	got, err = loadClientCredentials(path)
+	if err != nil || string(got) != `{"from":"env"}` {
+		t.Errorf("expected env contents, got %q (err %v)", got, err)
+	}
+}
+
+func TestLoadClientCredentials_MissingFile(t *testing.T) {
+	t.Setenv(clientCredentialsEnv, "")
+	_, err := loadClientCredentials(filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil || !strings.Contains(err.Error(), clientCredentialsEnv) {
+		t.Errorf("expected error mentioning %s, got %v", clientCredentialsEnv, err)
+	}
+}
+
+func TestTokenFromEnv(t *testing.T) {
+	t.Setenv(tokenJSONEnv, "")
+	tok, err := tokenFromEnv()
+	if err != nil || tok != nil {
+		t.Fatalf("expected nil token when unset, got %+v (err %v)", tok, err)
+	}
+
+	t.Setenv(tokenJSONEnv, base64.StdEncoding.EncodeToString([]byte(`{"access_token":"env-token","refresh_token":"r"}`)))
+	tok, err = tokenFromEnv()
+	if err != nil {
+		t.Fatalf("tokenFromEnv() error: %v", err)
+	}
+	if tok.AccessToken != "env-token" || tok.RefreshToken != "r" {
+		t.Errorf("unexpected token %+v", tok)
+	}
+}
+
+// ----- callback server -----
+
+func TestCallbackServer_EphemeralPortAndState(t *testing.T) {
+	// Two servers can run at once because each gets its own port and mux
+	first, err := startCallbackServer("127.0.0.1:0", "state-1")
+	if err != nil {
+		t.Fatalf("startCallbackServer failed: %v", err)
+	}
+	defer first.shutdown()
+	cs, err := startCallbackServer("127.0.0.1:0", "state-2")
+	if err != nil {
+		t.Fatalf("second startCallbackServer failed: %v", err)
+	}
+	defer cs.shutdown()
+	if first.redirectURL == cs.redirectURL {
+		t.Fatalf("expected distinct redirect URLs, both %s", cs.redirectURL)
+	}
+
+	get := func(path string, query url.Values) int {
+		resp, err := http.Get(cs.redirectURL + path + "?" + query.Encode())
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := get("/favicon.ico", url.Values{"state": {"state-2"}, "code": {"x"}}); status != http.StatusNotFound {
+		t.Errorf("unexpected path status = %d, want 404", status)
+	}
+	if status := get("/", url.Values{"state": {"state-1"}, "code": {"forged"}}); status != http.StatusBadRequest {
+		t.Errorf("wrong state status = %d, want 400", status)
+	}
+	if status := get("/", url.Values{"state": {"state-2"}, "code": {"good"}}); status != http.StatusOK {
+		t.Errorf("valid callback status = %d, want 200", status)
+	}
+
+	select {
+	case code := <-cs.codeCh:
+		if code != "good" {
+			t.Errorf("code = %q, want %q", code, "good")
+		}
+	case err := <-cs.errCh:
+		t.Fatalf("unexpected error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for code")
+	}
+}
+
+func TestCallbackServer_AuthorizationDenied(t *testing.T) {
+	cs, err := startCallbackServer("127.0.0.1:0", "s")
+	if err != nil {
+		t.Fatalf("startCallbackServer failed: %v", err)
+	}
+	defer cs.shutdown()
+
+	resp, err := http.Get(cs.redirectURL + "/?state=s&error=access_denied")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case err := <-cs.errCh:
+		if !strings.Contains(err.Error(), "access_denied") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for error")
+	}
+}
+
+func TestRedirectURLFor(t *testing.T) {
+	listener := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 53682}
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"127.0.0.1:0", "http://127.0.0.1:53682"},
+		{":0", "http://127.0.0.1:53682"},
+		{"0.0.0.0:53682", "http://127.0.0.1:53682"},
+		{"localhost:53682", "http://localhost:53682"},
+		{"[::1]:0", "http://[::1]:53682"},
+	}
+	for _, tt := range tests {
+		if got := redirectURLFor(tt.addr, listener); got != tt.want {
+			t.Errorf("redirectURLFor(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCallbackAddr(t *testing.T) {
+	t.Setenv(callbackAddrEnv, "")
+	if got := callbackAddr(); got != defaultCallbackAddr {
+		t.Errorf("default callbackAddr = %q, want %q", got, defaultCallbackAddr)
+	}
+	t.Setenv(callbackAddrEnv, "localhost:8080")
+	if got := callbackAddr(); got != "localhost:8080" {
+		t.Errorf("callbackAddr = %q, want localhost:8080", got)
+	}
+}
+
+// ----- setup -----
+
+const testClientCredentials = `{"installed":{"client_id":"id.apps.googleusercontent.com","client_secret":"secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`
+
+func TestCheckSetup(t *testing.T) {
+	t.Setenv(clientCredentialsEnv, "{not json")
+	t.Setenv(tokenJSONEnv, "")
+	status := CheckSetup()
+	if status.Ready() || status.CredentialsError == "" {
+		t.Errorf("expected invalid credentials to block setup, got %+v", status)
+	}
+
+	t.Setenv(clientCredentialsEnv, testClientCredentials)
+	t.Setenv(tokenJSONEnv, `{"access_token":"a","refresh_token":"r"}`)
+	status = CheckSetup()
+	if !status.Ready() {
+		t.Errorf("expected ready with credentials and token from the environment, got %+v", status)
+	}
+	if status.CredentialsSource != clientCredentialsEnv || status.TokenSource != tokenJSONEnv {
+		t.Errorf("unexpected sources %q / %q", status.CredentialsSource, status.TokenSource)
+	}
+}
+
+func TestStartAuthentication(t *testing.T) {
+	t.Setenv(clientCredentialsEnv, testClientCredentials)
+	t.Setenv(callbackAddrEnv, "127.0.0.1:0")
+
+	authURL, err := StartAuthentication()
+	if err != nil {
+		t.Fatalf("StartAuthentication() error: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil || parsed.Query().Get("state") == "" || !strings.HasPrefix(parsed.Query().Get("redirect_uri"), "http://127.0.0.1:") {
+		t.Fatalf("unexpected auth URL %q", authURL)
+	}
+
+	// A second call while waiting returns the same URL
+	again, err := StartAuthentication()
+	if err != nil || again != authURL {
+		t.Errorf("second call returned %q (err %v), want %q", again, err, authURL)
+	}
+	if status := CheckSetup(); status.Authentication != AuthWaiting || status.AuthURL != authURL {
+		t.Errorf("expected waiting status with the auth URL, got %+v", status)
+	}
+}
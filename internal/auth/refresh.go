@@ -0,0 +1,145 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// refreshRetryInterval is how long the background refresher waits before
+	// retrying after a failed refresh
+	refreshRetryInterval = time.Minute
+	// minRefreshInterval keeps the background refresher from spinning when the
+	// token is already inside the expiry buffer
+	minRefreshInterval = 30 * time.Second
+	// noExpiryCheckInterval is how often a token without an expiry is re-checked
+	noExpiryCheckInterval = time.Hour
+)
+
+// TokenRefresher is an oauth2.TokenSource that refreshes the OAuth token in the
+// background before it expires and persists every refreshed token, so tool calls
+// never pay for a lazy refresh and restarts pick up the latest token.
+type TokenRefresher struct {
+	config    *oauth2.Config
+	tokenPath string
+
+	mu      sync.Mutex
+	token   *oauth2.Token
+	lastErr error
+
+	consent consentState // incremental authorization in progress, if any
+}
+
+// newTokenRefresher creates a refresher seeded with an already valid token.
+func newTokenRefresher(config *oauth2.Config, tokenPath string, tok *oauth2.Token) *TokenRefresher {
+	return &TokenRefresher{
+		config:    config,
+		tokenPath: tokenPath,
+		token:     tok,
+	}
+}
+
+// Token returns the current token, refreshing it synchronously only if the
+// background refresher has not kept it valid.
+func (r *TokenRefresher) Token() (*oauth2.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if isTokenValid(r.token) {
+		return r.token, nil
+	}
+	return r.refreshLocked()
+}
+
+// refreshLocked refreshes and persists the token. The caller must hold r.mu.
+func (r *TokenRefresher) refreshLocked() (*oauth2.Token, error) {
+	newTok, err := refreshToken(r.config, r.token)
+	if err != nil {
+		r.lastErr = err
+		return nil, err
+	}
+
+	r.token = mergeTokens(r.token, newTok)
+	r.lastErr = nil
+	if err := saveTokenSafe(r.tokenPath, r.token); err != nil {
+		fmt.Fprintf(os.Stderr, "Token refreshed but could not be saved: %v\n", err)
+	}
+	return r.token, nil
+}
+
+// Start launches the background refresh loop. It stops when ctx is cancelled.
+func (r *TokenRefresher) Start(ctx context.Context) {
+	go r.run(ctx)
+}
+
+func (r *TokenRefresher) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.nextRefreshIn()):
+		}
+
+		r.mu.Lock()
+		if r.token != nil && r.token.Expiry.IsZero() {
+			r.mu.Unlock()
+			continue
+		}
+		_, err := r.refreshLocked()
+		r.mu.Unlock()
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Background token refresh failed (retrying in %s): %v\n", refreshRetryInterval, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Token refreshed in background\n")
+		}
+	}
+}
+
+// nextRefreshIn returns how long to wait before the next background refresh.
+func (r *TokenRefresher) nextRefreshIn() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastErr != nil {
+		return refreshRetryInterval
+	}
+	if r.token == nil || r.token.Expiry.IsZero() {
+		return noExpiryCheckInterval
+	}
+
+	wait := time.Until(r.token.Expiry.Add(-tokenExpiryBuffer))
+	if wait < minRefreshInterval {
+		wait = minRefreshInterval
+	}
+	return wait
+}
+
+// Health returns the error from the most recent refresh attempt, or nil if the
+// last refresh succeeded (or none has been needed yet).
+func (r *TokenRefresher) Health() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastErr
+}
@@ -0,0 +1,224 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+)
+
+// baseScopes are requested when the user first signs in. Other scopes are
+// requested the first time a tool needs them (see RequireScopes).
+var baseScopes = []string{calendar.CalendarScope}
+
+// legacyScopes were all requested at first sign-in before scopes were
+// requested incrementally; a saved token without a scope list was granted them.
+var legacyScopes = []string{calendar.CalendarScope, drive.DriveReadonlyScope}
+
+// scopeDescriptions say what each scope lets the server do, for consent prompts.
+var scopeDescriptions = map[string]string{
+	calendar.CalendarScope:   "manage your calendars",
+	drive.DriveReadonlyScope: "read your Google Drive files, such as meeting notes",
+	drive.DriveFileScope:     "create Google Docs for event descriptions too long for an event",
+}
+
+// storedToken is the token file format: the token plus the scopes granted to
+// it, which oauth2.Token does not serialize.
+type storedToken struct {
+	oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// grantedScopes returns the scopes Google reported granting with tok, or nil
+// if they are not known.
+func grantedScopes(tok *oauth2.Token) []string {
+	if scope, ok := tok.Extra("scope").(string); ok {
+		return strings.Fields(scope)
+	}
+	return nil
+}
+
+// tokenScopes returns the scopes granted to tok, assuming legacyScopes when
+// they are not known.
+func tokenScopes(tok *oauth2.Token) []string {
+	if scopes := grantedScopes(tok); len(scopes) > 0 {
+		return scopes
+	}
+	return legacyScopes
+}
+
+// withScopes returns a copy of tok recording scopes as granted.
+func withScopes(tok *oauth2.Token, scopes []string) *oauth2.Token {
+	return tok.WithExtra(map[string]interface{}{"scope": strings.Join(scopes, " ")})
+}
+
+// missingScopes returns the scopes not granted to tok.
+func missingScopes(tok *oauth2.Token, scopes []string) []string {
+	granted := make(map[string]bool)
+	for _, s := range tokenScopes(tok) {
+		granted[s] = true
+	}
+	var missing []string
+	for _, s := range scopes {
+		if !granted[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// mergeTokens combines a newly issued token with the one it replaces. Google
+// omits the refresh token when access was granted before, so the old one is
+// kept; the granted scopes are the union of both, so a token issued for one
+// additional scope never hides the ones granted earlier.
+func mergeTokens(old, issued *oauth2.Token) *oauth2.Token {
+	merged := *issued
+	if merged.RefreshToken == "" && old != nil {
+		merged.RefreshToken = old.RefreshToken
+	}
+
+	union := make(map[string]bool)
+	for _, tok := range []*oauth2.Token{old, issued} {
+		if tok != nil {
+			for _, s := range tokenScopes(tok) {
+				union[s] = true
+			}
+		}
+	}
+	scopes := make([]string, 0, len(union))
+	for s := range union {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	return withScopes(&merged, scopes)
+}
+
+// consentState is the incremental authorization a TokenRefresher is waiting on.
+type consentState struct {
+	mu      sync.Mutex
+	pending *pendingAuth
+}
+
+// RequireScopes returns nil if the signed-in user has granted scopes. If not,
+// it starts a browser authorization for the missing scopes in the background
+// and returns an *AuthError with the URL to approve them. Once approved, the
+// new token is merged into the current one and saved, so the tool that needed
+// the scopes works when called again. Before any Google client is created
+// there is nothing to check and it returns nil.
+func RequireScopes(scopes ...string) error {
+	sharedClientMu.Lock()
+	refresher := sharedRefresher
+	sharedClientMu.Unlock()
+
+	if refresher == nil {
+		return nil
+	}
+	return refresher.requireScopes(scopes)
+}
+
+// GrantedScopes returns the scopes granted to the signed-in user's token, or
+// nil before any Google client is created.
+func GrantedScopes() []string {
+	sharedClientMu.Lock()
+	refresher := sharedRefresher
+	sharedClientMu.Unlock()
+
+	if refresher == nil {
+		return nil
+	}
+	refresher.mu.Lock()
+	defer refresher.mu.Unlock()
+	return tokenScopes(refresher.token)
+}
+
+func (r *TokenRefresher) requireScopes(scopes []string) error {
+	r.mu.Lock()
+	missing := missingScopes(r.token, scopes)
+	r.mu.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	r.consent.mu.Lock()
+	defer r.consent.mu.Unlock()
+	if pending := r.consent.pending; pending != nil && !pending.done {
+		return consentError(missing, pending.authURL)
+	}
+
+	// include_granted_scopes makes the new token cover the scopes granted
+	// before as well
+	config := *r.config
+	config.Scopes = missing
+	web, err := beginWebAuth(&config, oauth2.SetAuthURLParam("include_granted_scopes", "true"))
+	if err != nil {
+		return err
+	}
+	pending := &pendingAuth{authURL: web.authURL}
+	r.consent.pending = pending
+	displayAuthURL(web.authURL)
+
+	go func() {
+		tok, err := web.wait(authTimeout)
+		if err == nil {
+			r.addGrant(tok)
+			fmt.Fprintf(os.Stderr, "Additional access granted\n")
+		}
+
+		r.consent.mu.Lock()
+		defer r.consent.mu.Unlock()
+		pending.done = true
+		pending.err = err
+	}()
+	return consentError(missing, web.authURL)
+}
+
+// addGrant merges a token issued by an incremental authorization into the
+// current token and persists it.
+func (r *TokenRefresher) addGrant(tok *oauth2.Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.token = mergeTokens(r.token, tok)
+	r.lastErr = nil
+	if err := saveTokenSafe(r.tokenPath, r.token); err != nil {
+		fmt.Fprintf(os.Stderr, "Additional access granted but the token could not be saved: %v\n", err)
+	}
+}
+
+// consentError asks the user to approve the missing scopes at authURL.
+func consentError(missing []string, authURL string) error {
+	needs := make([]string, 0, len(missing))
+	for _, s := range missing {
+		if desc, ok := scopeDescriptions[s]; ok {
+			needs = append(needs, desc)
+		} else {
+			needs = append(needs, s)
+		}
+	}
+	return &AuthError{
+		Message:   fmt.Sprintf("This tool needs additional access to your Google account: %s. Approve it in the browser, then call the tool again.", strings.Join(needs, "; ")),
+		AuthURL:   authURL,
+		NeedsAuth: true,
+	}
+}
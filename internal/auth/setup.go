@@ -0,0 +1,179 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Authentication states reported by SetupStatus
+const (
+	AuthNotStarted = "not_started"
+	AuthWaiting    = "waiting"
+	AuthFailed     = "failed"
+	AuthComplete   = "complete"
+)
+
+// SetupStatus describes how far the server is from being able to call Google:
+// where it looks for credentials and the token, and what it found.
+type SetupStatus struct {
+	StateDir          string `json:"state_dir"`
+	CredentialsPath   string `json:"credentials_path"`
+	CredentialsSource string `json:"credentials_source,omitempty"` // "file" or the environment variable
+	CredentialsError  string `json:"credentials_error,omitempty"`
+	TokenPath         string `json:"token_path"`
+	TokenSource       string `json:"token_source,omitempty"` // "file" or the environment variable
+	TokenError        string `json:"token_error,omitempty"`
+	Authentication    string `json:"authentication"`
+	AuthURL           string `json:"auth_url,omitempty"`
+	AuthError         string `json:"auth_error,omitempty"`
+}
+
+// Ready reports whether the credentials and a token are in place, so the
+// Google services can be created without user interaction.
+func (s SetupStatus) Ready() bool {
+	return s.CredentialsSource != "" && s.CredentialsError == "" && s.TokenSource != "" && s.TokenError == ""
+}
+
+// pendingAuth is the state of the browser authorization started by
+// StartAuthentication.
+type pendingAuth struct {
+	authURL string
+	done    bool
+	err     error
+}
+
+var (
+	setupMu   sync.Mutex
+	setupAuth *pendingAuth
+)
+
+// CheckSetup inspects the credentials and token the Google backend would use.
+// It never starts the browser flow.
+func CheckSetup() SetupStatus {
+	status := SetupStatus{Authentication: AuthNotStarted}
+
+	dir, err := StateDir()
+	if err != nil {
+		status.CredentialsError = err.Error()
+		return status
+	}
+	credPath, tokenPath, _ := getCredentialPaths()
+	status.StateDir = dir
+	status.CredentialsPath = credPath
+	status.TokenPath = tokenPath
+
+	if _, err := oauthConfig(credPath); err != nil {
+		status.CredentialsError = err.Error()
+	} else if os.Getenv(clientCredentialsEnv) != "" {
+		status.CredentialsSource = clientCredentialsEnv
+	} else {
+		status.CredentialsSource = "file"
+	}
+
+	if tok, err := tokenFromEnv(); err != nil {
+		status.TokenSource = tokenJSONEnv
+		status.TokenError = err.Error()
+	} else if tok != nil {
+		status.TokenSource = tokenJSONEnv
+	} else if _, err := os.Stat(tokenPath); err == nil {
+		status.TokenSource = "file"
+		if _, err := tokenFromFile(tokenPath); err != nil {
+			status.TokenError = fmt.Sprintf("unable to read %s: %v", tokenPath, err)
+		}
+	}
+
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	if setupAuth != nil {
+		status.AuthURL = setupAuth.authURL
+		switch {
+		case !setupAuth.done:
+			status.Authentication = AuthWaiting
+		case setupAuth.err != nil:
+			status.Authentication = AuthFailed
+			status.AuthError = setupAuth.err.Error()
+		default:
+			status.Authentication = AuthComplete
+		}
+	}
+	return status
+}
+
+// oauthConfig reads the OAuth client configuration with the scopes requested
+// at first sign-in.
+func oauthConfig(credPath string) (*oauth2.Config, error) {
+	b, err := loadClientCredentials(credPath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := google.ConfigFromJSON(b, baseScopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
+	}
+	return config, nil
+}
+
+// StartAuthentication starts the browser authorization in the background and
+// returns the URL the user has to visit. The token is saved to token.json
+// once the user approves; CheckSetup reports the progress. Calling it while an
+// authorization is waiting returns the same URL.
+func StartAuthentication() (string, error) {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	if setupAuth != nil && !setupAuth.done {
+		return setupAuth.authURL, nil
+	}
+
+	credPath, tokenPath, err := getCredentialPaths()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine credential paths: %v", err)
+	}
+	config, err := oauthConfig(credPath)
+	if err != nil {
+		return "", err
+	}
+	web, err := beginWebAuth(config)
+	if err != nil {
+		return "", err
+	}
+
+	pending := &pendingAuth{authURL: web.authURL}
+	setupAuth = pending
+	displayAuthURL(web.authURL)
+
+	go func() {
+		tok, err := web.wait(authTimeout)
+		if err == nil {
+			err = saveTokenSafe(tokenPath, tok)
+		}
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Authentication successful!\n")
+		}
+
+		setupMu.Lock()
+		defer setupMu.Unlock()
+		pending.done = true
+		pending.err = err
+	}()
+	return web.authURL, nil
+}
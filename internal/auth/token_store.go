@@ -0,0 +1,167 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+// tokenKeyEnv names the environment variable holding the passphrase used to
+// encrypt token.json at rest. When it is unset the token is stored as plain JSON.
+const tokenKeyEnv = "GCAL_MCP_TOKEN_KEY"
+
+// encryptedTokenVersion identifies the on-disk format of an encrypted token
+// file: AES-256-GCM with a key derived by scrypt from the passphrase and the
+// file's salt.
+const encryptedTokenVersion = "scrypt-aes-256-gcm-v2"
+
+// scrypt cost parameters for deriving the token key, the values the scrypt
+// package recommends for interactive logins (about 32 MiB of memory). The
+// key is derived once per load or save of the token.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	tokenSaltSize = 16
+	tokenKeySize  = 32
+)
+
+// encryptedToken is the on-disk envelope for an encrypted token.
+type encryptedToken struct {
+	Encrypted string `json:"encrypted"`
+	Salt      []byte `json:"salt"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+// tokenPassphrase returns the configured passphrase, or nil if token
+// encryption is not enabled.
+func tokenPassphrase() []byte {
+	passphrase := os.Getenv(tokenKeyEnv)
+	if passphrase == "" {
+		return nil
+	}
+	return []byte(passphrase)
+}
+
+// deriveTokenKey stretches the passphrase into the AES-256 key for one token
+// file, so guessing the passphrase from a stolen file is slow and cannot be
+// precomputed across installs.
+func deriveTokenKey(passphrase, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, tokenKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive token encryption key: %v", err)
+	}
+	return key, nil
+}
+
+// encodeToken returns the JSON form of a token with its granted scopes.
+func encodeToken(tok *oauth2.Token) ([]byte, error) {
+	return json.Marshal(storedToken{Token: *tok, Scopes: grantedScopes(tok)})
+}
+
+// encryptToken seals the JSON-encoded token with AES-256-GCM under a key
+// derived from the passphrase and a fresh random salt.
+func encryptToken(tok *oauth2.Token, passphrase []byte) ([]byte, error) {
+	plain, err := encodeToken(tok)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode oauth token: %v", err)
+	}
+
+	salt := make([]byte, tokenSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+	key, err := deriveTokenKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	return json.Marshal(encryptedToken{
+		Encrypted: encryptedTokenVersion,
+		Salt:      salt,
+		Nonce:     nonce,
+		Data:      gcm.Seal(nil, nonce, plain, nil),
+	})
+}
+
+// decodeToken parses a token file's contents, decrypting it if it was written
+// encrypted. Plain JSON tokens are accepted even when a key is configured so
+// existing installs migrate transparently on the next save.
+func decodeToken(data []byte, passphrase []byte) (*oauth2.Token, error) {
+	var envelope encryptedToken
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Encrypted != "" {
+		if envelope.Encrypted != encryptedTokenVersion {
+			return nil, fmt.Errorf("unsupported encrypted token format %q", envelope.Encrypted)
+		}
+		if passphrase == nil {
+			return nil, fmt.Errorf("token file is encrypted but %s is not set", tokenKeyEnv)
+		}
+		if len(envelope.Salt) < tokenSaltSize {
+			return nil, fmt.Errorf("encrypted token file has no salt")
+		}
+		key, err := deriveTokenKey(passphrase, envelope.Salt)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(envelope.Nonce) != gcm.NonceSize() {
+			return nil, fmt.Errorf("encrypted token file is corrupted: nonce is %d bytes, want %d", len(envelope.Nonce), gcm.NonceSize())
+		}
+		plain, err := gcm.Open(nil, envelope.Nonce, envelope.Data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt token file (wrong %s?): %v", tokenKeyEnv, err)
+		}
+		data = plain
+	}
+
+	var stored storedToken
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	if len(stored.Scopes) > 0 {
+		return withScopes(&stored.Token, stored.Scopes), nil
+	}
+	return &stored.Token, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token encryption key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,211 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// calendarListFields is the field selector for calendar list entries read by
+// whoami and set_default_calendar
+const calendarListFields = "id,summary,summaryOverride,timeZone,accessRole,primary"
+
+// Identity describes the signed-in account and the calendar tools use by default.
+type Identity struct {
+	Account             string `json:"account"`
+	TimeZone            string `json:"time_zone,omitempty"`
+	Profile             string `json:"profile"`
+	DefaultCalendar     string `json:"default_calendar"`
+	DefaultCalendarName string `json:"default_calendar_name,omitempty"`
+	DefaultCalendarRole string `json:"default_calendar_access_role,omitempty"`
+	DefaultCalendarNote string `json:"default_calendar_note,omitempty"`
+	DefaultSendUpdates  string `json:"default_send_updates,omitempty"`
+}
+
+// calendarName returns the name the user sees for a calendar list entry.
+func calendarName(entry *calendar.CalendarListEntry) string {
+	if entry.SummaryOverride != "" {
+		return entry.SummaryOverride
+	}
+	return entry.Summary
+}
+
+// CalendarListEntry returns the user's calendar list entry for calendarID.
+func (c *Client) CalendarListEntry(calendarID string) (*calendar.CalendarListEntry, error) {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	entry, err := c.service.CalendarList.Get(calendarID).Fields(calendarListFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar %s: %v", calendarID, err)
+	}
+	return entry, nil
+}
+
+// ResolveCalendar finds a calendar in the user's calendar list by ID or by
+// name, ignoring case. "primary" resolves to the user's own calendar.
+func (c *Client) ResolveCalendar(idOrName string) (*calendar.CalendarListEntry, error) {
+	entries, err := c.ListCalendars()
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := matchCalendar(entries, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkCalendar(entry.Id); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// matchCalendar picks the calendar list entry identified by idOrName. IDs
+// take precedence over names; a name shared by several calendars is an error.
+func matchCalendar(entries []*calendar.CalendarListEntry, idOrName string) (*calendar.CalendarListEntry, error) {
+	var byName []*calendar.CalendarListEntry
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Id, idOrName) || (idOrName == "primary" && entry.Primary) {
+			return entry, nil
+		}
+		if strings.EqualFold(calendarName(entry), idOrName) {
+			byName = append(byName, entry)
+		}
+	}
+
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("no calendar with ID or name %q in your calendar list", idOrName)
+	case 1:
+		return byName[0], nil
+	default:
+		var ids []string
+		for _, entry := range byName {
+			ids = append(ids, entry.Id)
+		}
+		return nil, fmt.Errorf("several calendars are named %q; use one of these IDs instead: %s", idOrName, strings.Join(ids, ", "))
+	}
+}
+
+// calendarID returns the calendar_id argument, or the profile's default
+// calendar when it is omitted.
+func (ct *CalendarTools) calendarID(arguments map[string]interface{}) string {
+	return getStringOrDefault(arguments, "calendar_id", ct.defaultCalendar())
+}
+
+// defaultCalendar returns the calendar used when a tool call has no calendar_id.
+func (ct *CalendarTools) defaultCalendar() string {
+	if ct.prefs != nil {
+		if id := ct.prefs.Get().DefaultCalendar; id != "" {
+			return id
+		}
+	}
+	return "primary"
+}
+
+func (ct *CalendarTools) handleSetDefaultCalendar(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	idOrName := getStringOrDefault(arguments, "calendar", "")
+	if idOrName == "" {
+		return nil, fmt.Errorf("calendar is required")
+	}
+
+	entry, err := ct.client.ResolveCalendar(idOrName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The primary calendar is stored as "primary" so the default keeps
+	// following the signed-in account
+	defaultID := entry.Id
+	if entry.Primary {
+		defaultID = "primary"
+	}
+	if err := ct.prefs.Update(func(p *Preferences) { p.DefaultCalendar = defaultID }); err != nil {
+		return nil, err
+	}
+
+	result := fmt.Sprintf("✅ Default calendar for profile '%s' set to '%s' (%s). Tools called without calendar_id now use it.", ct.prefs.Profile(), calendarName(entry), entry.Id)
+	if entry.AccessRole == "reader" || entry.AccessRole == "freeBusyReader" {
+		result += fmt.Sprintf("\n⚠️ You only have %s access to this calendar, so creating or changing events without calendar_id will fail.", entry.AccessRole)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleWhoami(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	primary, err := ct.client.CalendarListEntry("primary")
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify the signed-in account: %v", err)
+	}
+
+	identity := Identity{
+		Account:         primary.Id,
+		TimeZone:        primary.TimeZone,
+		Profile:         defaultProfile,
+		DefaultCalendar: ct.defaultCalendar(),
+	}
+	if ct.prefs != nil {
+		identity.Profile = ct.prefs.Profile()
+		identity.DefaultSendUpdates = ct.prefs.Get().DefaultSendUpdates
+	}
+
+	if identity.DefaultCalendar == "primary" {
+		identity.DefaultCalendarName = calendarName(primary)
+		identity.DefaultCalendarRole = primary.AccessRole
+	} else if entry, err := ct.client.CalendarListEntry(identity.DefaultCalendar); err != nil {
+		identity.DefaultCalendarNote = fmt.Sprintf("default calendar is not accessible: %v", err)
+	} else {
+		identity.DefaultCalendarName = calendarName(entry)
+		identity.DefaultCalendarRole = entry.AccessRole
+	}
+
+	var result strings.Builder
+	result.WriteString("👤 Signed in:\n\n")
+	fmt.Fprintf(&result, "• Account: %s\n", identity.Account)
+	if identity.TimeZone != "" {
+		fmt.Fprintf(&result, "• Time zone: %s\n", identity.TimeZone)
+	}
+	fmt.Fprintf(&result, "• Profile: %s\n", identity.Profile)
+	if identity.DefaultCalendarName != "" {
+		fmt.Fprintf(&result, "• Default calendar: %s (%s)\n", identity.DefaultCalendarName, identity.DefaultCalendar)
+	} else {
+		fmt.Fprintf(&result, "• Default calendar: %s\n", identity.DefaultCalendar)
+	}
+	if identity.DefaultCalendarNote != "" {
+		fmt.Fprintf(&result, "• ⚠️ %s\n", identity.DefaultCalendarNote)
+	}
+	if identity.DefaultSendUpdates != "" {
+		fmt.Fprintf(&result, "• Default notifications: %s\n", identity.DefaultSendUpdates)
+	}
+
+	identityJSON, _ := json.MarshalIndent(identity, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(identityJSON))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
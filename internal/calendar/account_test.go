@@ -0,0 +1,78 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- matchCalendar -----
+
+func TestMatchCalendar(t *testing.T) {
+	entries := []*calendar.CalendarListEntry{
+		{Id: "me@example.com", Summary: "me@example.com", Primary: true},
+		{Id: "team@group.calendar.google.com", Summary: "Team"},
+		{Id: "a@group.calendar.google.com", Summary: "Shared"},
+		{Id: "b@group.calendar.google.com", Summary: "Other", SummaryOverride: "Shared"},
+	}
+
+	tests := []struct {
+		input   string
+		wantID  string
+		wantErr string
+	}{
+		{"primary", "me@example.com", ""},
+		{"TEAM@group.calendar.google.com", "team@group.calendar.google.com", ""},
+		{"team", "team@group.calendar.google.com", ""},
+		{"shared", "", "several calendars"},
+		{"Other", "", "no calendar"}, // hidden by the user's override
+		{"missing", "", "no calendar"},
+	}
+	for _, tt := range tests {
+		entry, err := matchCalendar(entries, tt.input)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("matchCalendar(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || entry.Id != tt.wantID {
+			t.Errorf("matchCalendar(%q) = %v, %v; want %s", tt.input, entry, err, tt.wantID)
+		}
+	}
+}
+
+// ----- calendarID -----
+
+func TestCalendarIDDefault(t *testing.T) {
+	ct := &CalendarTools{}
+	if got := ct.calendarID(map[string]interface{}{}); got != "primary" {
+		t.Errorf("without preferences = %q, want primary", got)
+	}
+
+	prefs, _ := LoadPreferenceStore("", "default")
+	ct.SetPreferences(prefs)
+	_ = prefs.Update(func(p *Preferences) { p.DefaultCalendar = "team@group.calendar.google.com" })
+
+	if got := ct.calendarID(map[string]interface{}{}); got != "team@group.calendar.google.com" {
+		t.Errorf("with default set = %q", got)
+	}
+	if got := ct.calendarID(map[string]interface{}{"calendar_id": "other"}); got != "other" {
+		t.Errorf("explicit calendar_id = %q, want other", got)
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// AgendaSide is one of the two agendas compare_agendas diffs: a calendar over
+// a time range.
+type AgendaSide struct {
+	CalendarID string    `json:"calendar_id"`
+	TimeMin    time.Time `json:"time_min"`
+	TimeMax    time.Time `json:"time_max"`
+}
+
+// AgendaChange is an event that differs between the base agenda and the
+// current one. Base fields describe the event in the base agenda.
+type AgendaChange struct {
+	Summary     string `json:"summary"`
+	EventID     string `json:"event_id,omitempty"`
+	Start       string `json:"start,omitempty"`
+	End         string `json:"end,omitempty"`
+	BaseEventID string `json:"base_event_id,omitempty"`
+	BaseStart   string `json:"base_start,omitempty"`
+	BaseEnd     string `json:"base_end,omitempty"`
+}
+
+// AgendaComparison lists what changed from the base agenda to the current
+// one. Moved events are at a different point of their range (or last a
+// different time); unchanged events are only counted.
+type AgendaComparison struct {
+	Base      AgendaSide     `json:"base"`
+	Current   AgendaSide     `json:"current"`
+	Added     []AgendaChange `json:"added"`
+	Removed   []AgendaChange `json:"removed"`
+	Moved     []AgendaChange `json:"moved"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// agendaEntry is an event placed within its range: offset is the wall-clock
+// time from the range's start, so a weekly meeting has the same offset in
+// consecutive weeks even across a daylight saving change.
+type agendaEntry struct {
+	event      *calendar.Event
+	start, end time.Time
+	offset     time.Duration
+	length     time.Duration
+	tokens     map[string]bool
+}
+
+// agendaKey identifies the same meeting on both sides: the series for
+// recurring instances, else the iCalendar UID, which an event keeps on every
+// calendar it is on.
+func agendaKey(e *calendar.Event) string {
+	switch {
+	case e.RecurringEventId != "":
+		return "series:" + e.RecurringEventId
+	case e.ICalUID != "":
+		return "uid:" + e.ICalUID
+	default:
+		return "id:" + e.Id
+	}
+}
+
+// wallClock returns t's local date and time as if it were UTC, so durations
+// between wall-clock times ignore daylight saving shifts. All-day dates are
+// already parsed as UTC midnight.
+func wallClock(t time.Time, allDay bool, loc *time.Location) time.Time {
+	if allDay {
+		return t
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+func agendaEntries(events []*calendar.Event, rangeStart time.Time, loc *time.Location) []agendaEntry {
+	origin := wallClock(rangeStart, false, loc)
+	var entries []agendaEntry
+	for _, e := range events {
+		if e.Status == "cancelled" || e.EventType == "workingLocation" {
+			continue
+		}
+		start, end, allDay, err := parseEventTimes(e)
+		if err != nil {
+			continue
+		}
+		wallStart := wallClock(start, allDay, loc)
+		entries = append(entries, agendaEntry{
+			event:  e,
+			start:  start,
+			end:    end,
+			offset: wallStart.Sub(origin),
+			length: wallClock(end, allDay, loc).Sub(wallStart),
+			tokens: titleTokens(e.Summary),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].start.Before(entries[j].start) })
+	return entries
+}
+
+// compareAgendas matches the events of two agendas and reports the ones added,
+// removed or moved. Events are matched by agendaKey first, in start order
+// within a key; the rest are matched by similar titles, preferring the
+// closest position, which pairs up copies synced from another system.
+func compareAgendas(base, current []*calendar.Event, baseStart, currentStart time.Time, loc *time.Location) AgendaComparison {
+	baseEntries := agendaEntries(base, baseStart, loc)
+	currentEntries := agendaEntries(current, currentStart, loc)
+
+	matched := make(map[int]int) // current index -> base index
+	baseUsed := make(map[int]bool)
+	byKey := make(map[string][]int)
+	for i, b := range baseEntries {
+		key := agendaKey(b.event)
+		byKey[key] = append(byKey[key], i)
+	}
+	for i, c := range currentEntries {
+		key := agendaKey(c.event)
+		if queue := byKey[key]; len(queue) > 0 {
+			matched[i] = queue[0]
+			baseUsed[queue[0]] = true
+			byKey[key] = queue[1:]
+		}
+	}
+	for i, c := range currentEntries {
+		if _, ok := matched[i]; ok || len(c.tokens) == 0 {
+			continue
+		}
+		best := -1
+		var bestGap time.Duration
+		for j, b := range baseEntries {
+			if baseUsed[j] || titleSimilarity(c.tokens, b.tokens) < titleSimilarityThreshold {
+				continue
+			}
+			gap := c.offset - b.offset
+			if gap < 0 {
+				gap = -gap
+			}
+			if best < 0 || gap < bestGap {
+				best, bestGap = j, gap
+			}
+		}
+		if best >= 0 {
+			matched[i] = best
+			baseUsed[best] = true
+		}
+	}
+
+	comparison := AgendaComparison{Added: []AgendaChange{}, Removed: []AgendaChange{}, Moved: []AgendaChange{}}
+	for i, c := range currentEntries {
+		j, ok := matched[i]
+		if !ok {
+			comparison.Added = append(comparison.Added, AgendaChange{
+				Summary: eventTitle(c.event),
+				EventID: c.event.Id,
+				Start:   c.start.In(loc).Format(time.RFC3339),
+				End:     c.end.In(loc).Format(time.RFC3339),
+			})
+			continue
+		}
+		b := baseEntries[j]
+		if b.offset == c.offset && b.length == c.length {
+			comparison.Unchanged++
+			continue
+		}
+		comparison.Moved = append(comparison.Moved, AgendaChange{
+			Summary:     eventTitle(c.event),
+			EventID:     c.event.Id,
+			Start:       c.start.In(loc).Format(time.RFC3339),
+			End:         c.end.In(loc).Format(time.RFC3339),
+			BaseEventID: b.event.Id,
+			BaseStart:   b.start.In(loc).Format(time.RFC3339),
+			BaseEnd:     b.end.In(loc).Format(time.RFC3339),
+		})
+	}
+	for j, b := range baseEntries {
+		if baseUsed[j] {
+			continue
+		}
+		comparison.Removed = append(comparison.Removed, AgendaChange{
+			Summary:     eventTitle(b.event),
+			BaseEventID: b.event.Id,
+			BaseStart:   b.start.In(loc).Format(time.RFC3339),
+			BaseEnd:     b.end.In(loc).Format(time.RFC3339),
+		})
+	}
+	return comparison
+}
+
+// precedingRange returns the range of the same length just before
+// [timeMin, timeMax): whole days are counted on the calendar so "last week"
+// keeps its wall-clock times across a daylight saving change.
+func precedingRange(timeMin, timeMax time.Time, loc *time.Location) time.Time {
+	length := timeMax.Sub(timeMin)
+	if length%(24*time.Hour) == 0 {
+		return timeMin.In(loc).AddDate(0, 0, -int(length/(24*time.Hour)))
+	}
+	return timeMin.Add(-length)
+}
+
+func (ct *CalendarTools) handleCompareAgendas(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	timeMinStr := getStringOrDefault(arguments, "time_min", "")
+	if timeMinStr == "" {
+		return nil, fmt.Errorf("time_min is required")
+	}
+	timeMaxStr := getStringOrDefault(arguments, "time_max", "")
+	if timeMaxStr == "" {
+		return nil, fmt.Errorf("time_max is required")
+	}
+	timeMin, err := time.Parse(time.RFC3339, timeMinStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_min format: %v", err)
+	}
+	timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_max format: %v", err)
+	}
+	if !timeMax.After(timeMin) {
+		return nil, fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	current := AgendaSide{CalendarID: ct.calendarID(arguments), TimeMin: timeMin, TimeMax: timeMax}
+	base := AgendaSide{CalendarID: getStringOrDefault(arguments, "compare_calendar_id", current.CalendarID)}
+	if v := getStringOrDefault(arguments, "compare_time_min", ""); v != "" {
+		if base.TimeMin, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid compare_time_min format: %v", err)
+		}
+	} else if base.CalendarID != current.CalendarID {
+		base.TimeMin = timeMin
+	} else {
+		base.TimeMin = precedingRange(timeMin, timeMax, loc)
+	}
+	base.TimeMax = base.TimeMin.Add(timeMax.Sub(timeMin))
+	if base.CalendarID == current.CalendarID && base.TimeMin.Equal(current.TimeMin) {
+		return nil, fmt.Errorf("nothing to compare: set compare_calendar_id or a different compare_time_min")
+	}
+
+	list := func(side AgendaSide) ([]*calendar.Event, error) {
+		events, err := ct.client.ListEvents(ListEventsParams{
+			CalendarID:   side.CalendarID,
+			TimeFilter:   "custom",
+			TimeMin:      side.TimeMin,
+			TimeMax:      side.TimeMax,
+			TimeZone:     timezone,
+			SingleEvents: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events on %s: %v", side.CalendarID, err)
+		}
+		return events.Items, nil
+	}
+	baseEvents, err := list(base)
+	if err != nil {
+		return nil, err
+	}
+	currentEvents, err := list(current)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := compareAgendas(baseEvents, currentEvents, base.TimeMin, current.TimeMin, loc)
+	comparison.Base, comparison.Current = base, current
+	return structuredResult(formatAgendaComparison(comparison, loc, ct.client.TimeFormat()), comparison), nil
+}
+
+func formatAgendaComparison(c AgendaComparison, loc *time.Location, tf TimeFormat) string {
+	side := func(s AgendaSide) string {
+		return fmt.Sprintf("%s, %s – %s", s.CalendarID, tf.MonthDay(s.TimeMin.In(loc)), tf.MonthDay(s.TimeMax.Add(-time.Second).In(loc)))
+	}
+	when := func(start, end string) string {
+		s, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return "(no start time)"
+		}
+		text := fmt.Sprintf("%s, %s", tf.ShortDate(s), tf.Clock(s))
+		if e, err := time.Parse(time.RFC3339, end); err == nil {
+			text += "–" + tf.Clock(e)
+		}
+		return text
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "🔀 %s compared with %s (%s): %d added, %d removed, %d moved, %d unchanged\n",
+		side(c.Current), side(c.Base), loc.String(), len(c.Added), len(c.Removed), len(c.Moved), c.Unchanged)
+	if len(c.Added) > 0 {
+		result.WriteString("\n➕ Added:\n")
+		for _, e := range c.Added {
+			fmt.Fprintf(&result, "• %s — %s\n", when(e.Start, e.End), e.Summary)
+		}
+	}
+	if len(c.Removed) > 0 {
+		result.WriteString("\n➖ Removed:\n")
+		for _, e := range c.Removed {
+			fmt.Fprintf(&result, "• %s — %s\n", when(e.BaseStart, e.BaseEnd), e.Summary)
+		}
+	}
+	if len(c.Moved) > 0 {
+		result.WriteString("\n↔️ Moved:\n")
+		for _, e := range c.Moved {
+			fmt.Fprintf(&result, "• %s: %s → %s\n", e.Summary, when(e.BaseStart, e.BaseEnd), when(e.Start, e.End))
+		}
+	}
+	return result.String()
+}
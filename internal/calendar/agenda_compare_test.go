@@ -0,0 +1,102 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func agendaEvent(id, uid, series, summary, start, end string) *calendar.Event {
+	return &calendar.Event{
+		Id:               id,
+		ICalUID:          uid,
+		RecurringEventId: series,
+		Summary:          summary,
+		Start:            &calendar.EventDateTime{DateTime: start},
+		End:              &calendar.EventDateTime{DateTime: end},
+	}
+}
+
+func TestCompareAgendas_Weeks(t *testing.T) {
+	lastWeek := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	thisWeek := lastWeek.AddDate(0, 0, 7)
+	base := []*calendar.Event{
+		agendaEvent("s1", "", "standup", "Standup", "2025-03-03T09:00:00Z", "2025-03-03T09:15:00Z"),
+		agendaEvent("r1", "r1@google.com", "", "Design review", "2025-03-04T14:00:00Z", "2025-03-04T15:00:00Z"),
+		agendaEvent("o1", "o1@google.com", "", "Offsite planning", "2025-03-05T10:00:00Z", "2025-03-05T11:00:00Z"),
+	}
+	current := []*calendar.Event{
+		agendaEvent("s2", "", "standup", "Standup", "2025-03-10T09:00:00Z", "2025-03-10T09:15:00Z"),
+		agendaEvent("r2", "r2@google.com", "", "Design Review", "2025-03-12T10:00:00Z", "2025-03-12T11:00:00Z"),
+		agendaEvent("h2", "h2@google.com", "", "Hiring sync", "2025-03-13T10:00:00Z", "2025-03-13T11:00:00Z"),
+	}
+
+	got := compareAgendas(base, current, lastWeek, thisWeek, time.UTC)
+	if got.Unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1 (the standup)", got.Unchanged)
+	}
+	if len(got.Added) != 1 || got.Added[0].EventID != "h2" {
+		t.Errorf("added = %+v, want the hiring sync", got.Added)
+	}
+	if len(got.Removed) != 1 || got.Removed[0].BaseEventID != "o1" {
+		t.Errorf("removed = %+v, want the offsite planning", got.Removed)
+	}
+	if len(got.Moved) != 1 || got.Moved[0].BaseEventID != "r1" || got.Moved[0].EventID != "r2" {
+		t.Errorf("moved = %+v, want the design review matched by title", got.Moved)
+	}
+}
+
+func TestCompareAgendas_Calendars(t *testing.T) {
+	week := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	// The same event has the same iCalUID on both calendars, whatever its title there
+	base := []*calendar.Event{
+		agendaEvent("a", "planning@example.com", "", "Planning", "2025-03-04T14:00:00Z", "2025-03-04T15:00:00Z"),
+		agendaEvent("b", "retro@example.com", "", "Retro", "2025-03-05T14:00:00Z", "2025-03-05T15:00:00Z"),
+	}
+	current := []*calendar.Event{
+		agendaEvent("x", "planning@example.com", "", "Sprint planning", "2025-03-04T14:00:00Z", "2025-03-04T15:30:00Z"),
+		agendaEvent("y", "retro@example.com", "", "Retro", "2025-03-05T14:00:00Z", "2025-03-05T15:00:00Z"),
+	}
+
+	got := compareAgendas(base, current, week, week, time.UTC)
+	if got.Unchanged != 1 || len(got.Added) != 0 || len(got.Removed) != 0 {
+		t.Errorf("got %+v, want only the retro unchanged besides the lengthened planning", got)
+	}
+	if len(got.Moved) != 1 || got.Moved[0].EventID != "x" || got.Moved[0].BaseEnd != "2025-03-04T15:00:00Z" {
+		t.Errorf("moved = %+v, want the lengthened planning", got.Moved)
+	}
+}
+
+func TestCompareAgendas_DaylightSaving(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	// Clocks go forward on Mar 9, 2025: 9:00 EST and 9:00 EDT are the same slot
+	thisWeek := time.Date(2025, 3, 10, 0, 0, 0, 0, ny)
+	lastWeek := precedingRange(thisWeek, thisWeek.AddDate(0, 0, 7), ny)
+	if want := time.Date(2025, 3, 3, 0, 0, 0, 0, ny); !lastWeek.Equal(want) {
+		t.Fatalf("precedingRange = %v, want %v", lastWeek, want)
+	}
+	base := []*calendar.Event{agendaEvent("s1", "", "standup", "Standup", "2025-03-03T09:00:00-05:00", "2025-03-03T09:15:00-05:00")}
+	current := []*calendar.Event{agendaEvent("s2", "", "standup", "Standup", "2025-03-10T09:00:00-04:00", "2025-03-10T09:15:00-04:00")}
+
+	if got := compareAgendas(base, current, lastWeek, thisWeek, ny); got.Unchanged != 1 {
+		t.Errorf("got %+v, want the standup unchanged", got)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// AgendaResourceURI is the resource holding today's events on the
+	// primary calendar
+	AgendaResourceURI = "calendar://primary/agenda"
+	// agendaIntervalEnv overrides how often the agenda is checked for changes
+	agendaIntervalEnv = "GCAL_MCP_AGENDA_INTERVAL"
+	// DefaultAgendaInterval is how often a subscribed agenda is checked for changes
+	DefaultAgendaInterval = time.Minute
+	// minAgendaInterval is the shortest interval between agenda checks, each
+	// one request for changes to the primary calendar
+	minAgendaInterval = 30 * time.Second
+)
+
+// ResourceNotifier tells the client about changed resources it subscribed
+// to; *mcp.Server implements it.
+type ResourceNotifier interface {
+	Subscribed(uri string) bool
+	NotifyResourceUpdated(uri string)
+}
+
+// agendaState is the version of the agenda the client last saw, by reading
+// it or being told it changed, as returned by agendaVersion.
+type agendaState struct {
+	mu   sync.Mutex
+	seen string
+}
+
+// swap records version as seen and reports whether it differs from the one
+// seen before.
+func (a *agendaState) swap(version string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	changed := a.seen != version
+	a.seen = version
+	return changed
+}
+
+// agendaVersion identifies the state of a day's agenda: the day and every
+// event with its etag, which changes with any edit, RSVP included.
+func agendaVersion(day string, events []*calendar.Event) string {
+	parts := []string{day}
+	for _, e := range events {
+		parts = append(parts, e.Id+"@"+e.Etag)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ListResources implements mcp.ResourceHandler.
+func (ct *CalendarTools) ListResources() []mcp.Resource {
+	return []mcp.Resource{{
+		URI:         AgendaResourceURI,
+		Name:        "Today's agenda",
+		Description: "Today's events on the primary calendar, in its time zone. Subscribe to be notified when they change",
+		MimeType:    "text/markdown",
+	}, {
+		URI:         TrackedResourceURI,
+		Name:        "Tracked event changes",
+		Description: "Changes to the time, attendees or status of the events followed with track_event since they were last reported. Subscribe to be notified when one changes",
+		MimeType:    "text/markdown",
+	}}
+}
+
+// ReadResource implements mcp.ResourceHandler.
+func (ct *CalendarTools) ReadResource(uri string) (*mcp.ReadResourceResult, error) {
+	if uri == TrackedResourceURI {
+		return ct.readTrackedChanges()
+	}
+	if uri != AgendaResourceURI {
+		return nil, mcp.ErrResourceNotFound
+	}
+	events, params, day, err := ct.todaysAgenda()
+	if err != nil {
+		return nil, err
+	}
+	ct.agenda.swap(agendaVersion(day, events.Items))
+
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{{
+			URI:      uri,
+			MimeType: "text/markdown",
+			Text:     ct.formatEventsResult(events, params),
+		}},
+	}, nil
+}
+
+// todaysAgenda lists today's events on the primary calendar in the
+// calendar's time zone, and returns the day they were listed for.
+func (ct *CalendarTools) todaysAgenda() (*calendar.Events, ListEventsParams, string, error) {
+	loc, err := ct.goalLocation("primary", "")
+	if err != nil {
+		return nil, ListEventsParams{}, "", err
+	}
+	params := ListEventsParams{
+		CalendarID:     "primary",
+		TimeFilter:     "today",
+		TimeZone:       loc.String(),
+		MaxResults:     250,
+		SingleEvents:   true,
+		OrderBy:        "startTime",
+		DetectOverlaps: true,
+		AttendeeLimit:  defaultAttendeeLimit,
+	}
+	events, err := ct.client.ListEvents(params)
+	if err != nil {
+		return nil, params, "", fmt.Errorf("failed to list today's events: %v", err)
+	}
+	return events, params, time.Now().In(loc).Format("2006-01-02"), nil
+}
+
+// AgendaIntervalFromEnv returns how often a subscribed agenda is checked for
+// changes (GCAL_MCP_AGENDA_INTERVAL, by default DefaultAgendaInterval).
+func AgendaIntervalFromEnv() time.Duration {
+	value := os.Getenv(agendaIntervalEnv)
+	if value == "" {
+		return DefaultAgendaInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", agendaIntervalEnv, value)
+		return DefaultAgendaInterval
+	}
+	return backgroundInterval(interval, minAgendaInterval)
+}
+
+// StartAgendaWatch keeps a subscribed agenda resource live: every interval,
+// while the client is subscribed, it checks the primary calendar for changes
+// with one request and, when today's events differ from the version the
+// client last saw (or the day turned over), sends
+// notifications/resources/updated. Nothing is checked while no one is
+// subscribed or while the request budget is low.
+func (ct *CalendarTools) StartAgendaWatch(notifier ResourceNotifier, interval time.Duration, budget *quota.Budget) {
+	go func() {
+		var synced time.Time
+		var day string
+		loc := time.UTC // the calendar's time zone as of the last listing
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if !notifier.Subscribed(AgendaResourceURI) || budgetLow(budget) {
+				synced = time.Time{}
+				continue
+			}
+			checked := time.Now()
+			if !synced.IsZero() && checked.In(loc).Format("2006-01-02") == day {
+				changed, err := ct.client.changedSince("primary", synced)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Agenda watch: %v\n", err)
+					continue
+				}
+				if !changed {
+					synced = checked
+					continue
+				}
+			}
+
+			events, params, listedDay, err := ct.todaysAgenda()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Agenda watch: %v\n", err)
+				continue
+			}
+			synced, day = checked, listedDay
+			if l, err := loadLocation(params.TimeZone); err == nil {
+				loc = l
+			}
+			if ct.agenda.swap(agendaVersion(listedDay, events.Items)) {
+				notifier.NotifyResourceUpdated(AgendaResourceURI)
+			}
+		}
+	}()
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestAgendaVersion(t *testing.T) {
+	events := []*calendar.Event{{Id: "a", Etag: "1"}, {Id: "b", Etag: "1"}}
+	version := agendaVersion("2025-03-10", events)
+
+	if agendaVersion("2025-03-10", []*calendar.Event{{Id: "a", Etag: "1"}, {Id: "b", Etag: "1"}}) != version {
+		t.Error("the same agenda should have the same version")
+	}
+	if agendaVersion("2025-03-10", []*calendar.Event{{Id: "a", Etag: "1"}, {Id: "b", Etag: "2"}}) == version {
+		t.Error("an edited event should change the version")
+	}
+	if agendaVersion("2025-03-10", events[:1]) == version {
+		t.Error("a removed event should change the version")
+	}
+	if agendaVersion("2025-03-11", events) == version {
+		t.Error("a new day should change the version")
+	}
+
+	var state agendaState
+	if !state.swap(version) {
+		t.Error("the first version should count as a change")
+	}
+	if state.swap(version) {
+		t.Error("the same version should not count as a change")
+	}
+}
+
+func TestAgendaIntervalFromEnv(t *testing.T) {
+	t.Setenv(agendaIntervalEnv, "")
+	if got := AgendaIntervalFromEnv(); got != DefaultAgendaInterval {
+		t.Errorf("unset: got %v, want %v", got, DefaultAgendaInterval)
+	}
+	t.Setenv(agendaIntervalEnv, "5s")
+	if got := AgendaIntervalFromEnv(); got != minAgendaInterval {
+		t.Errorf("5s: got %v, want it raised to %v", got, minAgendaInterval)
+	}
+	t.Setenv(agendaIntervalEnv, "often")
+	if got := AgendaIntervalFromEnv(); got != DefaultAgendaInterval {
+		t.Errorf("invalid: got %v, want %v", got, DefaultAgendaInterval)
+	}
+}
+
+// recordingNotifier is a ResourceNotifier that counts the updates sent.
+type recordingNotifier struct {
+	mu         sync.Mutex
+	subscribed bool
+	updates    int
+}
+
+func (n *recordingNotifier) Subscribed(uri string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.subscribed && uri == AgendaResourceURI
+}
+
+func (n *recordingNotifier) NotifyResourceUpdated(string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.updates++
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.updates
+}
+
+func TestAgendaResource(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC)
+	insert := func(summary string) {
+		t.Helper()
+		_, err := store.InsertEvent("primary", &calendar.Event{
+			Summary: summary,
+			Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+			End:     &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339)},
+		}, 0)
+		if err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+	insert("Standup")
+
+	if _, err := ct.ReadResource("calendar://primary/unknown"); err != mcp.ErrResourceNotFound {
+		t.Errorf("unknown URI: got %v, want ErrResourceNotFound", err)
+	}
+	result, err := ct.ReadResource(AgendaResourceURI)
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+	if len(result.Contents) != 1 || !strings.Contains(result.Contents[0].Text, "Standup") {
+		t.Fatalf("agenda does not list today's event: %+v", result.Contents)
+	}
+
+	// Nothing changed since the read, so there is nothing to tell
+	notifier := &recordingNotifier{subscribed: true}
+	ct.StartAgendaWatch(notifier, 10*time.Millisecond, nil)
+	time.Sleep(100 * time.Millisecond)
+	if n := notifier.count(); n != 0 {
+		t.Fatalf("%d update(s) sent for an unchanged agenda", n)
+	}
+
+	insert("Design review")
+	deadline := time.Now().Add(2 * time.Second)
+	for notifier.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := notifier.count(); n != 1 {
+		t.Errorf("got %d update(s) after an event was added, want 1", n)
+	}
+}
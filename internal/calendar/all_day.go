@@ -0,0 +1,110 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// dateLayout is the format of all-day event dates
+const dateLayout = "2006-01-02"
+
+// The Calendar API stores an all-day event's end as the day after its last
+// day. Callers of this server use inclusive days instead: the first and last
+// day of the event. The helpers below translate between the two.
+
+// parseEventTime parses a start_time or end_time argument: RFC3339, or a
+// plain date for all-day events. A plain end date is the event's last day, so
+// it is returned as midnight after that day.
+func parseEventTime(value string, isEnd bool) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+	day, dateErr := time.Parse(dateLayout, value)
+	if dateErr != nil {
+		return time.Time{}, err
+	}
+	if isEnd {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// allDayStartDate returns the API start date of an all-day event starting at start.
+func allDayStartDate(start time.Time) string {
+	return start.Format(dateLayout)
+}
+
+// allDayEndDate returns the API (exclusive) end date of an all-day event
+// ending at end. Any time on a day makes that day the last one, so an end on
+// the start day gives a one-day event; only an end at midnight after the
+// first day is read as already exclusive. start is nil when only the end
+// changes, in which case a midnight end is always read as exclusive.
+func allDayEndDate(start *time.Time, end time.Time) (string, error) {
+	last := midnight(end)
+	if last.Equal(end) && (start == nil || last.After(midnight(*start))) {
+		last = last.AddDate(0, 0, -1)
+	}
+	if start != nil && last.Before(midnight(*start)) {
+		return "", fmt.Errorf("end_time %s is before the first day of the all-day event (%s)", end.Format(time.RFC3339), allDayStartDate(*start))
+	}
+	return last.AddDate(0, 0, 1).Format(dateLayout), nil
+}
+
+// midnight returns the start of t's day in t's own location.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// allDayLastDate returns the last day of an all-day event (the day before
+// its API end date), or "" for timed events and unparseable dates.
+func allDayLastDate(event *calendar.Event) string {
+	if event.Start == nil || event.Start.Date == "" || event.End == nil {
+		return ""
+	}
+	end, err := time.Parse(dateLayout, event.End.Date)
+	if err != nil {
+		return ""
+	}
+	last := end.AddDate(0, 0, -1)
+	if last.Format(dateLayout) < event.Start.Date {
+		return event.Start.Date
+	}
+	return last.Format(dateLayout)
+}
+
+// describeAllDay renders the days an all-day event covers, e.g. "Jan 15" or
+// "Jan 15 – Jan 17", or "" for timed events.
+func describeAllDay(event *calendar.Event, tf TimeFormat) string {
+	last := allDayLastDate(event)
+	if last == "" {
+		return ""
+	}
+	first, err := time.Parse(dateLayout, event.Start.Date)
+	if err != nil {
+		return ""
+	}
+	if last == event.Start.Date {
+		return tf.MonthDay(first)
+	}
+	lastDay, _ := time.Parse(dateLayout, last)
+	return tf.MonthDay(first) + " – " + tf.MonthDay(lastDay)
+}
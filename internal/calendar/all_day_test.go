@@ -0,0 +1,144 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- parseEventTime -----
+
+func TestParseEventTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		isEnd    bool
+		expected string
+	}{
+		{"2025-01-15T10:00:00-08:00", false, "2025-01-15T10:00:00-08:00"},
+		{"2025-01-15T10:00:00-08:00", true, "2025-01-15T10:00:00-08:00"},
+		{"2025-01-15", false, "2025-01-15T00:00:00Z"},
+		// A plain end date is the last day, so the event runs until midnight after it
+		{"2025-01-15", true, "2025-01-16T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseEventTime(tt.value, tt.isEnd)
+		if err != nil {
+			t.Errorf("parseEventTime(%q) error: %v", tt.value, err)
+			continue
+		}
+		if got.Format(time.RFC3339) != tt.expected {
+			t.Errorf("parseEventTime(%q, %v) = %s, want %s", tt.value, tt.isEnd, got.Format(time.RFC3339), tt.expected)
+		}
+	}
+
+	if _, err := parseEventTime("tomorrow", false); err == nil {
+		t.Error("expected an error for an unparseable time")
+	}
+}
+
+// ----- allDayEndDate -----
+
+func TestAllDayEndDate(t *testing.T) {
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		end      time.Time
+		expected string
+	}{
+		{"same instant is one day", start, "2025-01-16"},
+		{"end later on the first day is one day", time.Date(2025, 1, 15, 23, 59, 59, 0, time.UTC), "2025-01-16"},
+		{"midnight after the first day is already exclusive", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), "2025-01-16"},
+		{"time on a later day includes that day", time.Date(2025, 1, 17, 12, 0, 0, 0, time.UTC), "2025-01-18"},
+		{"midnight three days on is three days", time.Date(2025, 1, 18, 0, 0, 0, 0, time.UTC), "2025-01-18"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allDayEndDate(&start, tt.end)
+			if err != nil {
+				t.Fatalf("allDayEndDate() error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("allDayEndDate() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAllDayEndDate_BeforeStart(t *testing.T) {
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	if _, err := allDayEndDate(&start, start.AddDate(0, 0, -2)); err == nil {
+		t.Error("expected an error for an end before the first day")
+	}
+}
+
+func TestAllDayEndDate_WithoutStart(t *testing.T) {
+	// Only the end changes: a midnight end is read as exclusive, any other time as the last day
+	if got, _ := allDayEndDate(nil, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)); got != "2025-01-16" {
+		t.Errorf("midnight end = %s, want 2025-01-16", got)
+	}
+	if got, _ := allDayEndDate(nil, time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)); got != "2025-01-17" {
+		t.Errorf("daytime end = %s, want 2025-01-17", got)
+	}
+}
+
+// ----- allDayLastDate / describeAllDay -----
+
+func TestAllDayLastDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     *calendar.Event
+		last      string
+		described string
+	}{
+		{
+			name:      "one day",
+			event:     &calendar.Event{Start: &calendar.EventDateTime{Date: "2025-01-15"}, End: &calendar.EventDateTime{Date: "2025-01-16"}},
+			last:      "2025-01-15",
+			described: "Jan 15",
+		},
+		{
+			name:      "three days",
+			event:     &calendar.Event{Start: &calendar.EventDateTime{Date: "2025-01-15"}, End: &calendar.EventDateTime{Date: "2025-01-18"}},
+			last:      "2025-01-17",
+			described: "Jan 15 – Jan 17",
+		},
+		{
+			name:      "zero-length event reads as one day",
+			event:     &calendar.Event{Start: &calendar.EventDateTime{Date: "2025-01-15"}, End: &calendar.EventDateTime{Date: "2025-01-15"}},
+			last:      "2025-01-15",
+			described: "Jan 15",
+		},
+		{
+			name:  "timed event",
+			event: &calendar.Event{Start: &calendar.EventDateTime{DateTime: "2025-01-15T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-01-15T11:00:00Z"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allDayLastDate(tt.event); got != tt.last {
+				t.Errorf("allDayLastDate() = %q, want %q", got, tt.last)
+			}
+			if got := describeAllDay(tt.event, TimeFormat{}); got != tt.described {
+				t.Errorf("describeAllDay() = %q, want %q", got, tt.described)
+			}
+		})
+	}
+}
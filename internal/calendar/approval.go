@@ -0,0 +1,100 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// approvalKey is the shared extended property tracking the approval of
+	// an event proposed on a team calendar. It is shared rather than private
+	// so everyone working on the calendar sees it.
+	approvalKey = "approval"
+
+	approvalPending  = "pending"
+	approvalApproved = "approved"
+)
+
+// eventApproval returns the approval state of e: approvalPending,
+// approvalApproved, or "" for events that were never proposed.
+func eventApproval(e *calendar.Event) string {
+	if e == nil || e.ExtendedProperties == nil {
+		return ""
+	}
+	return e.ExtendedProperties.Shared[approvalKey]
+}
+
+// ApproveEvent confirms an event proposed with create_event's propose
+// option: its status becomes confirmed, it is marked approved, and
+// sendUpdates decides who is told. The write is conditioned on the etag read
+// here so an event edited in the meantime is not approved unseen.
+func (c *Client) ApproveEvent(calendarID, eventID, sendUpdates string) (*calendar.Event, error) {
+	event, err := c.GetEvent(calendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	switch eventApproval(event) {
+	case approvalPending:
+	case approvalApproved:
+		return nil, fmt.Errorf("event '%s' is already approved", eventTitle(event))
+	default:
+		return nil, fmt.Errorf("event '%s' was not proposed for approval", eventTitle(event))
+	}
+
+	patch := &calendar.Event{
+		Status: "confirmed",
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Shared: map[string]string{approvalKey: approvalApproved},
+		},
+	}
+	call := c.service.Events.Patch(calendarID, eventID, patch)
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	call.Header().Set("If-Match", event.Etag)
+	approved, err := call.Do()
+	if err != nil {
+		return nil, c.conflictError(calendarID, eventID, event.Etag, err)
+	}
+	return approved, nil
+}
+
+func (ct *CalendarTools) handleApproveEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID := getStringOrDefault(arguments, "event_id", "")
+	if eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarID := ct.calendarID(arguments)
+	event, err := ct.client.ApproveEvent(calendarID, eventID, sendUpdates)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmation := newConfirmation(confirmApproved, calendarID, event, ct.client.TimeFormat())
+	note := fmt.Sprintf("The event is now confirmed; %s.", describeSendUpdates(sendUpdates))
+	return structuredResult(formatConfirmation(confirmation, confirmation, note), event), nil
+}
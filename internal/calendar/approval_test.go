@@ -0,0 +1,46 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- eventApproval -----
+
+func TestEventApproval(t *testing.T) {
+	shared := func(props map[string]string) *calendar.Event {
+		return &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{Shared: props}}
+	}
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		want  string
+	}{
+		{"no properties", &calendar.Event{}, ""},
+		{"pending", shared(map[string]string{approvalKey: approvalPending}), approvalPending},
+		{"approved", shared(map[string]string{approvalKey: approvalApproved}), approvalApproved},
+		// A private property of the same name is someone's own note, not the team's approval
+		{"private only", &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{approvalKey: approvalPending}}}, ""},
+	}
+	for _, tt := range tests {
+		if got := eventApproval(tt.event); got != tt.want {
+			t.Errorf("%s: eventApproval = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
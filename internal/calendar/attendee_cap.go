@@ -0,0 +1,50 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import "fmt"
+
+// defaultMaxAttendees is how many people create_event and edit_event invite
+// without the caller raising max_attendees, so a mistyped group or pasted
+// list does not send hundreds of invitations by accident.
+const defaultMaxAttendees = 100
+
+// maxAttendeesSchema is the schema of the max_attendees argument.
+var maxAttendeesSchema = map[string]interface{}{
+	"type":        "integer",
+	"description": fmt.Sprintf("Largest number of attendees the call may invite (defaults to %d). A larger list is refused until max_attendees is raised to at least its size, confirming the invitations are intended; for large open events consider anyone_can_add_self instead", defaultMaxAttendees),
+	"default":     defaultMaxAttendees,
+}
+
+// anyoneCanAddSelfSchema is the schema of the anyone_can_add_self argument.
+var anyoneCanAddSelfSchema = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Whether anyone who can see the event may add themselves as an attendee, e.g. for office hours or an all-hands open to the company, instead of inviting everyone (defaults to false)",
+}
+
+// checkAttendeeCap refuses to invite count people, up from previous, when that
+// is more than limit. Lists that do not grow, e.g. an RSVP that passes the
+// existing attendees back, are never refused.
+func checkAttendeeCap(title string, count, previous, limit int) error {
+	if limit < 1 {
+		return fmt.Errorf("max_attendees must be at least 1")
+	}
+	if count <= limit || count <= previous {
+		return nil
+	}
+	return fmt.Errorf("'%s' would have %d attendees, more than max_attendees (%d): check the list, then retry with max_attendees: %d to confirm. For an event open to many people, anyone_can_add_self lets them join without an invitation", title, count, limit, count)
+}
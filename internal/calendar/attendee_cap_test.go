@@ -0,0 +1,109 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- checkAttendeeCap -----
+
+func TestCheckAttendeeCap(t *testing.T) {
+	tests := []struct {
+		name            string
+		count, previous int
+		limit           int
+		wantErr         bool
+	}{
+		{"within the cap", 100, 0, 100, false},
+		{"over the cap", 101, 0, 100, true},
+		{"raised cap", 500, 0, 500, false},
+		{"list not growing", 300, 300, 100, false},
+		{"list growing past the cap", 301, 300, 100, true},
+		{"invalid cap", 1, 0, 0, true},
+	}
+	for _, tt := range tests {
+		err := checkAttendeeCap("All hands", tt.count, tt.previous, tt.limit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// ----- create_event / edit_event -----
+
+func TestAttendeeCapAndAnyoneCanAddSelf(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	arguments := map[string]interface{}{
+		"summary":             "Office hours",
+		"start_time":          start.Format(time.RFC3339),
+		"end_time":            start.Add(time.Hour).Format(time.RFC3339),
+		"attendees":           []interface{}{"ana@example.com", "bo@example.com", "cy@example.com"},
+		"anyone_can_add_self": true,
+		"max_attendees":       float64(2),
+	}
+	if _, err := ct.HandleTool("create_event", arguments); err == nil || !strings.Contains(err.Error(), "max_attendees: 3") {
+		t.Fatalf("expected the attendee cap to refuse 3 attendees, got %v", err)
+	}
+
+	arguments["max_attendees"] = float64(3)
+	result, err := ct.HandleTool("create_event", arguments)
+	if err != nil {
+		t.Fatalf("create_event: %v", err)
+	}
+	event := result.StructuredContent.(*calendar.Event)
+	if !event.AnyoneCanAddSelf {
+		t.Error("anyone_can_add_self was not set")
+	}
+
+	// Passing the same attendees back, e.g. with an RSVP, is not refused
+	if _, err := ct.HandleTool("edit_event", map[string]interface{}{
+		"event_id":            event.Id,
+		"attendees":           []interface{}{"ana@example.com", "bo@example.com", "cy@example.com"},
+		"anyone_can_add_self": false,
+		"max_attendees":       float64(2),
+	}); err != nil {
+		t.Fatalf("edit_event: %v", err)
+	}
+	edited, err := store.GetEvent("primary", event.Id)
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if edited.AnyoneCanAddSelf {
+		t.Error("anyone_can_add_self: false was not applied")
+	}
+
+	_, err = ct.HandleTool("edit_event", map[string]interface{}{
+		"event_id":      event.Id,
+		"attendees":     []interface{}{"ana@example.com", "bo@example.com", "cy@example.com", "di@example.com"},
+		"max_attendees": float64(3),
+	})
+	if err == nil || !strings.Contains(err.Error(), "4 attendees") {
+		t.Errorf("expected growing the list past the cap to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// normalizeEmail trims an attendee address and converts an internationalized
+// domain to the ASCII form the API accepts (e.g. "anna@bücher.de" becomes
+// "anna@xn--bcher-kva.de"). It returns an error saying what is wrong with
+// an address that is not valid.
+func normalizeEmail(raw string) (string, error) {
+	email := strings.TrimSpace(raw)
+	switch {
+	case email == "":
+		return "", fmt.Errorf("empty address")
+	case strings.IndexFunc(email, unicode.IsSpace) >= 0:
+		return "", fmt.Errorf("contains spaces")
+	case !strings.Contains(email, "@"):
+		return "", fmt.Errorf("missing '@'")
+	case strings.Count(email, "@") > 1:
+		return "", fmt.Errorf("more than one '@'")
+	}
+	local, domain, _ := strings.Cut(email, "@")
+	if local == "" {
+		return "", fmt.Errorf("nothing before '@'")
+	}
+	if domain == "" {
+		return "", fmt.Errorf("no domain after '@'")
+	}
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q: %v", domain, err)
+	}
+	if !strings.Contains(asciiDomain, ".") {
+		return "", fmt.Errorf("domain %q has no top-level domain", domain)
+	}
+	email = local + "@" + asciiDomain
+	if !isValidEmail(email) {
+		return "", fmt.Errorf("not a valid email address")
+	}
+	return email, nil
+}
+
+// normalizeAttendeeEmails normalizes the email of every entry of an attendee
+// list argument, given as a string or an attendee object, so malformed
+// addresses are reported before any API call instead of as an opaque 400
+// from Calendar. Every malformed entry is listed in the error.
+func normalizeAttendeeEmails(key string, values []interface{}) ([]interface{}, error) {
+	normalized := make([]interface{}, 0, len(values))
+	var problems []string
+	for i, v := range values {
+		var raw string
+		switch a := v.(type) {
+		case string:
+			raw = a
+		case map[string]interface{}:
+			raw = getStringOrDefault(a, "email", "")
+		default:
+			problems = append(problems, fmt.Sprintf("entry %d: must be an email string or an object with an email", i+1))
+			continue
+		}
+		email, err := normalizeEmail(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("entry %d %q: %v", i+1, raw, err))
+			continue
+		}
+		if entry, ok := v.(map[string]interface{}); ok {
+			copied := make(map[string]interface{}, len(entry))
+			for k, field := range entry {
+				copied[k] = field
+			}
+			copied["email"] = email
+			normalized = append(normalized, copied)
+		} else {
+			normalized = append(normalized, email)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s: %d malformed address(es): %s", key, len(problems), strings.Join(problems, "; "))
+	}
+	return normalized, nil
+}
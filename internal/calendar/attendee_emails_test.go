@@ -0,0 +1,91 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+// ----- normalizeEmail -----
+
+func TestNormalizeEmail(t *testing.T) {
+	valid := map[string]string{
+		"alice@example.com":              "alice@example.com",
+		"  bob@example.com\n":            "bob@example.com",
+		"carol@Example.COM":              "carol@example.com",
+		"anna@bücher.de":                 "anna@xn--bcher-kva.de",
+		"team@group.calendar.google.com": "team@group.calendar.google.com",
+	}
+	for in, want := range valid {
+		got, err := normalizeEmail(in)
+		if err != nil || got != want {
+			t.Errorf("normalizeEmail(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	invalid := map[string]string{
+		"":                      "empty",
+		"bob smith@example.com": "spaces",
+		"bob.example.com":       "missing '@'",
+		"bob@@example.com":      "more than one '@'",
+		"@example.com":          "nothing before",
+		"bob@":                  "no domain",
+		"bob@localhost":         "no top-level domain",
+		"bob@exa_mple.com":      "invalid domain",
+		"bob()@example.com":     "not a valid email",
+	}
+	for in, want := range invalid {
+		_, err := normalizeEmail(in)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("normalizeEmail(%q) error = %v, want it to mention %q", in, err, want)
+		}
+	}
+}
+
+// ----- normalizeAttendeeEmails -----
+
+func TestNormalizeAttendeeEmails_ListsEveryMalformedEntry(t *testing.T) {
+	values := []interface{}{
+		" alice@example.com ",
+		map[string]interface{}{"email": "bob@bücher.de", "optional": true},
+		"carol@",
+		map[string]interface{}{"email": "dave example.com"},
+	}
+	_, err := normalizeAttendeeEmails("attendees", values)
+	if err == nil {
+		t.Fatal("malformed addresses were accepted")
+	}
+	for _, want := range []string{"2 malformed", `entry 3 "carol@"`, `entry 4 "dave example.com"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	normalized, err := normalizeAttendeeEmails("attendees", values[:2])
+	if err != nil {
+		t.Fatalf("normalizeAttendeeEmails: %v", err)
+	}
+	if normalized[0] != "alice@example.com" {
+		t.Errorf("entry 1 = %v, want trimmed", normalized[0])
+	}
+	entry := normalized[1].(map[string]interface{})
+	if entry["email"] != "bob@xn--bcher-kva.de" || entry["optional"] != true {
+		t.Errorf("entry 2 = %v, want the ASCII domain with other fields kept", entry)
+	}
+	if values[1].(map[string]interface{})["email"] != "bob@bücher.de" {
+		t.Error("the caller's attendee object was modified")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+// attendeeArguments are the tool arguments that take attendee lists and so
+// accept attendee group names
+var attendeeArguments = []string{"attendees", "attendee_emails"}
+
+// AttendeeGroup is a named list of attendees saved in the profile's preferences.
+type AttendeeGroup struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// normalizeGroupName validates an attendee group name and returns it in the
+// lower case it is stored under. Names cannot contain "@", so they never
+// collide with email addresses or Google Groups.
+func normalizeGroupName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if strings.Contains(name, "@") || strings.ContainsAny(name, " \t\n") {
+		return "", fmt.Errorf("group name %q must not contain '@' or spaces", name)
+	}
+	return name, nil
+}
+
+// expandAttendeeGroups replaces every attendee group name in attendee lists
+// with the group's members. An entry without "@" is taken as a group name,
+// given either as a string or as the email of an attendee object, whose other
+// fields (such as optional) then apply to every member. Members already listed
+// are not added twice.
+func expandAttendeeGroups(values []interface{}, groups map[string][]string) ([]interface{}, error) {
+	listed := make(map[string]bool)
+	for _, v := range values {
+		if email := attendeeEntryEmail(v); strings.Contains(email, "@") {
+			listed[strings.ToLower(email)] = true
+		}
+	}
+
+	expanded := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		name := attendeeEntryEmail(v)
+		if name == "" || strings.Contains(name, "@") {
+			expanded = append(expanded, v)
+			continue
+		}
+		members, ok := groups[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("no attendee group named %q; use an email address or define the group with define_group", name)
+		}
+		for _, member := range members {
+			if listed[strings.ToLower(member)] {
+				continue
+			}
+			listed[strings.ToLower(member)] = true
+			if entry, ok := v.(map[string]interface{}); ok {
+				copied := make(map[string]interface{}, len(entry))
+				for k, field := range entry {
+					copied[k] = field
+				}
+				copied["email"] = member
+				expanded = append(expanded, copied)
+			} else {
+				expanded = append(expanded, member)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// attendeeEntryEmail returns the email of an attendee given as a string or
+// an object.
+func attendeeEntryEmail(v interface{}) string {
+	switch a := v.(type) {
+	case string:
+		return strings.TrimSpace(a)
+	case map[string]interface{}:
+		return strings.TrimSpace(getStringOrDefault(a, "email", ""))
+	}
+	return ""
+}
+
+// expandGroupArguments expands attendee group names in every attendee list
+// argument of a tool call, so groups are accepted anywhere attendees are,
+// then normalizes and validates the resulting addresses.
+func (ct *CalendarTools) expandGroupArguments(arguments map[string]interface{}) error {
+	var groups map[string][]string
+	if ct.prefs != nil {
+		groups = ct.prefs.Get().AttendeeGroups
+	}
+	for _, key := range attendeeArguments {
+		values, ok := arguments[key].([]interface{})
+		if !ok {
+			continue
+		}
+		expanded, err := expandAttendeeGroups(values, groups)
+		if err != nil {
+			return err
+		}
+		if expanded, err = normalizeAttendeeEmails(key, expanded); err != nil {
+			return err
+		}
+		arguments[key] = expanded
+	}
+	return nil
+}
+
+// attendeeGroups returns the profile's attendee groups sorted by name.
+func (ct *CalendarTools) attendeeGroups() []AttendeeGroup {
+	var groups []AttendeeGroup
+	if ct.prefs == nil {
+		return groups
+	}
+	for name, members := range ct.prefs.Get().AttendeeGroups {
+		groups = append(groups, AttendeeGroup{Name: name, Members: members})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+func (ct *CalendarTools) handleDefineGroup(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	name, err := normalizeGroupName(getStringOrDefault(arguments, "name", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := arguments["members"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("members is required")
+	}
+	for _, v := range values {
+		if _, ok := v.(string); !ok {
+			return nil, fmt.Errorf("all member emails must be strings")
+		}
+	}
+	values, err = normalizeAttendeeEmails("members", values)
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		email := v.(string)
+		if !seen[strings.ToLower(email)] {
+			seen[strings.ToLower(email)] = true
+			members = append(members, email)
+		}
+	}
+
+	_, existed := ct.prefs.Get().AttendeeGroups[name]
+	err = ct.prefs.Update(func(p *Preferences) {
+		groups := make(map[string][]string, len(p.AttendeeGroups)+1)
+		for k, v := range p.AttendeeGroups {
+			groups[k] = v
+		}
+		if len(members) == 0 {
+			delete(groups, name)
+		} else {
+			groups[name] = members
+		}
+		p.AttendeeGroups = groups
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result string
+	switch {
+	case len(members) == 0 && existed:
+		result = fmt.Sprintf("🗑️ Deleted attendee group '%s' from profile '%s'.", name, ct.prefs.Profile())
+	case len(members) == 0:
+		result = fmt.Sprintf("ℹ️ No attendee group '%s' in profile '%s'; nothing to delete.", name, ct.prefs.Profile())
+	case existed:
+		result = fmt.Sprintf("✅ Updated attendee group '%s' (%d member(s)): %s\nUse '%s' wherever attendees are accepted.", name, len(members), strings.Join(members, ", "), name)
+	default:
+		result = fmt.Sprintf("✅ Defined attendee group '%s' (%d member(s)): %s\nUse '%s' wherever attendees are accepted.", name, len(members), strings.Join(members, ", "), name)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleListGroups(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	groups := ct.attendeeGroups()
+
+	var result strings.Builder
+	profile := defaultProfile
+	if ct.prefs != nil {
+		profile = ct.prefs.Profile()
+	}
+	fmt.Fprintf(&result, "👥 Attendee groups in profile '%s':\n\n", profile)
+	if len(groups) == 0 {
+		result.WriteString("• None defined; create one with define_group\n")
+	}
+	for _, g := range groups {
+		fmt.Fprintf(&result, "• %s (%d): %s\n", g.Name, len(g.Members), strings.Join(g.Members, ", "))
+	}
+
+	groupsJSON, _ := json.MarshalIndent(groups, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(groupsJSON))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
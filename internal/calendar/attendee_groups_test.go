@@ -0,0 +1,160 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ----- normalizeGroupName -----
+
+func TestNormalizeGroupName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"platform-team", "platform-team", false},
+		{"  Platform-Team ", "platform-team", false},
+		{"", "", true},
+		{"team@example.com", "", true},
+		{"platform team", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeGroupName(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizeGroupName(%q) = %q, %v; want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// ----- expandAttendeeGroups -----
+
+func TestExpandAttendeeGroups(t *testing.T) {
+	groups := map[string][]string{
+		"platform-team": {"alice@example.com", "bob@example.com"},
+	}
+
+	tests := []struct {
+		name    string
+		values  []interface{}
+		want    []interface{}
+		wantErr string
+	}{
+		{
+			name:   "emails unchanged",
+			values: []interface{}{"carol@example.com", "eng@groups.example.com"},
+			want:   []interface{}{"carol@example.com", "eng@groups.example.com"},
+		},
+		{
+			name:   "group name expanded, case-insensitive",
+			values: []interface{}{"carol@example.com", "Platform-Team"},
+			want:   []interface{}{"carol@example.com", "alice@example.com", "bob@example.com"},
+		},
+		{
+			name:   "members listed explicitly are not repeated",
+			values: []interface{}{"platform-team", "Bob@example.com"},
+			want:   []interface{}{"alice@example.com", "Bob@example.com"},
+		},
+		{
+			name:   "object fields apply to every member",
+			values: []interface{}{map[string]interface{}{"email": "platform-team", "optional": true}},
+			want: []interface{}{
+				map[string]interface{}{"email": "alice@example.com", "optional": true},
+				map[string]interface{}{"email": "bob@example.com", "optional": true},
+			},
+		},
+		{
+			name:    "unknown group",
+			values:  []interface{}{"sales"},
+			wantErr: `no attendee group named "sales"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandAttendeeGroups(tt.values, groups)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ----- define_group / list_groups -----
+
+func TestDefineAndListGroups(t *testing.T) {
+	prefs, err := LoadPreferenceStore("", "work")
+	if err != nil {
+		t.Fatalf("LoadPreferenceStore: %v", err)
+	}
+	ct := NewCalendarTools(&Client{})
+	ct.SetPreferences(prefs)
+
+	_, err = ct.HandleTool("define_group", map[string]interface{}{
+		"name":    "Platform-Team",
+		"members": []interface{}{"alice@example.com", "bob@example.com", "alice@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("define_group: %v", err)
+	}
+	if got := prefs.Get().AttendeeGroups["platform-team"]; len(got) != 2 {
+		t.Errorf("stored members = %v, want alice and bob once each", got)
+	}
+
+	if _, err := ct.HandleTool("define_group", map[string]interface{}{
+		"name":    "platform-team",
+		"members": []interface{}{"not-an-email"},
+	}); err == nil {
+		t.Error("define_group accepted a member without an email address")
+	}
+
+	// Group names are expanded before any tool sees its attendees
+	args := map[string]interface{}{"attendee_emails": []interface{}{"platform-team"}}
+	if err := ct.expandGroupArguments(args); err != nil {
+		t.Fatalf("expandGroupArguments: %v", err)
+	}
+	if got := args["attendee_emails"].([]interface{}); len(got) != 2 {
+		t.Errorf("expanded attendee_emails = %v", got)
+	}
+
+	result, err := ct.HandleTool("list_groups", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("list_groups: %v", err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "• platform-team (2): alice@example.com, bob@example.com") {
+		t.Errorf("list_groups output:\n%s", text)
+	}
+
+	if _, err := ct.HandleTool("define_group", map[string]interface{}{
+		"name":    "platform-team",
+		"members": []interface{}{},
+	}); err != nil {
+		t.Fatalf("delete group: %v", err)
+	}
+	if _, ok := prefs.Get().AttendeeGroups["platform-team"]; ok {
+		t.Error("group still defined after defining it with no members")
+	}
+}
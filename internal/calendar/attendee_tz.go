@@ -0,0 +1,208 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// attendeeTimezonesEnv configures attendee time zones as comma-separated
+	// email=Zone pairs, e.g. "alex@example.com=Europe/London"
+	attendeeTimezonesEnv = "GCAL_MCP_ATTENDEE_TIMEZONES"
+	// timezoneLookback is how far back past events are scanned to infer an
+	// attendee's time zone from the meetings they organized
+	timezoneLookback = 90 * 24 * time.Hour
+)
+
+// AttendeeTimezonesFromEnv parses GCAL_MCP_ATTENDEE_TIMEZONES. Entries with an
+// unknown IANA zone are skipped with a warning on stderr.
+func AttendeeTimezonesFromEnv() map[string]string {
+	zones := make(map[string]string)
+	for _, entry := range strings.Split(os.Getenv(attendeeTimezonesEnv), ",") {
+		email, zone, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		email, zone = strings.ToLower(strings.TrimSpace(email)), strings.TrimSpace(zone)
+		if _, err := loadLocation(zone); err != nil {
+			fmt.Fprintf(os.Stderr, "Ignoring attendee timezone %s=%s: %v\n", email, zone, err)
+			continue
+		}
+		zones[email] = zone
+	}
+	return zones
+}
+
+// SetAttendeeTimezones sets known attendee time zones, keyed by email. These
+// take precedence over zones inferred from past events.
+func (c *Client) SetAttendeeTimezones(zones map[string]string) {
+	c.attendeeTimezones = make(map[string]string, len(zones))
+	for email, zone := range zones {
+		c.attendeeTimezones[strings.ToLower(email)] = zone
+	}
+}
+
+// AttendeeTimezones returns the time zone of each attendee that can be
+// determined, keyed by lowercased email. Configured zones are used first; the
+// rest are inferred from the start time zones of past events each attendee
+// organized. Inferred results (including misses) are cached for the session.
+func (c *Client) AttendeeTimezones(emails []string) (map[string]string, error) {
+	zones := make(map[string]string)
+	var unknown []string
+	for _, email := range emails {
+		email = strings.ToLower(email)
+		if zone, ok := c.attendeeTimezones[email]; ok {
+			zones[email] = zone
+		} else if zone, ok := c.inferredTimezones[email]; ok {
+			if zone != "" {
+				zones[email] = zone
+			}
+		} else {
+			unknown = append(unknown, email)
+		}
+	}
+	if len(unknown) == 0 {
+		return zones, nil
+	}
+
+	now := time.Now()
+	events, err := c.ListEvents(ListEventsParams{
+		TimeFilter:   "custom",
+		TimeMin:      now.Add(-timezoneLookback),
+		TimeMax:      now,
+		ShowDeclined: true,
+	})
+	if err != nil {
+		return zones, fmt.Errorf("failed to scan past events for attendee time zones: %v", err)
+	}
+
+	if c.inferredTimezones == nil {
+		c.inferredTimezones = make(map[string]string)
+	}
+	for email, zone := range inferTimezones(events.Items, unknown) {
+		c.inferredTimezones[email] = zone
+		if zone != "" {
+			zones[email] = zone
+		}
+	}
+	return zones, nil
+}
+
+// inferTimezones picks, for each email, the start time zone used most often in
+// the events that person organized. Emails with no evidence map to "".
+func inferTimezones(events []*calendar.Event, emails []string) map[string]string {
+	votes := make(map[string]map[string]int)
+	for _, email := range emails {
+		votes[email] = make(map[string]int)
+	}
+	for _, ev := range events {
+		if ev.Organizer == nil || ev.Start == nil || ev.Start.TimeZone == "" {
+			continue
+		}
+		if counts, ok := votes[strings.ToLower(ev.Organizer.Email)]; ok {
+			counts[ev.Start.TimeZone]++
+		}
+	}
+
+	zones := make(map[string]string, len(emails))
+	for email, counts := range votes {
+		best, bestCount := "", 0
+		for zone, n := range counts {
+			// Ties break alphabetically so results are stable
+			if n > bestCount || (n == bestCount && zone < best) {
+				best, bestCount = zone, n
+			}
+		}
+		zones[email] = best
+	}
+	return zones
+}
+
+// formatAttendeeLocalTimes renders the start and end of a meeting in each
+// attendee's local zone. Attendees with an unknown zone are listed separately.
+func formatAttendeeLocalTimes(start, end time.Time, attendees []string, zones map[string]string, tf TimeFormat) string {
+	if len(attendees) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("🌍 Attendee local times:\n")
+	var unknown []string
+	sorted := append([]string{}, attendees...)
+	sort.Strings(sorted)
+	for _, email := range sorted {
+		zone, ok := zones[strings.ToLower(email)]
+		if !ok {
+			unknown = append(unknown, email)
+			continue
+		}
+		loc, err := loadLocation(zone)
+		if err != nil {
+			unknown = append(unknown, email)
+			continue
+		}
+		localStart, localEnd := start.In(loc), end.In(loc)
+		endText := tf.ClockZone(localEnd)
+		if localEnd.YearDay() != localStart.YearDay() {
+			endText = tf.ShortDate(localEnd) + ", " + endText
+		}
+		fmt.Fprintf(&result, "• %s (%s): %s, %s – %s\n", email, zone,
+			tf.ShortDate(localStart), tf.Clock(localStart), endText)
+	}
+	if len(unknown) > 0 {
+		fmt.Fprintf(&result, "• Time zone unknown: %s\n", strings.Join(unknown, ", "))
+	}
+	return result.String()
+}
+
+// attendeeLocalTimesForEvent looks up attendee zones and formats the event's
+// time for each attendee other than the user. Lookup failures are reported
+// inline rather than failing the operation that created the event.
+func (c *Client) attendeeLocalTimesForEvent(event *calendar.Event) string {
+	if event == nil || event.Start == nil || event.End == nil || event.Start.DateTime == "" {
+		return ""
+	}
+	start, err1 := time.Parse(time.RFC3339, event.Start.DateTime)
+	end, err2 := time.Parse(time.RFC3339, event.End.DateTime)
+	if err1 != nil || err2 != nil {
+		return ""
+	}
+
+	var emails []string
+	for _, a := range event.Attendees {
+		if !a.Self && !a.Resource && a.Email != "" {
+			emails = append(emails, a.Email)
+		}
+	}
+	if len(emails) == 0 {
+		return ""
+	}
+
+	zones, err := c.AttendeeTimezones(emails)
+	text := formatAttendeeLocalTimes(start, end, emails, zones, c.TimeFormat())
+	if err != nil {
+		text += fmt.Sprintf("(time zone inference incomplete: %v)\n", err)
+	}
+	return text
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- AttendeeTimezonesFromEnv -----
+
+func TestAttendeeTimezonesFromEnv(t *testing.T) {
+	t.Setenv(attendeeTimezonesEnv, "Alex@Example.com=Europe/London, sam@example.com=Not/AZone, bad-entry")
+	zones := AttendeeTimezonesFromEnv()
+	if len(zones) != 1 || zones["alex@example.com"] != "Europe/London" {
+		t.Errorf("unexpected zones %v", zones)
+	}
+}
+
+// ----- inferTimezones -----
+
+func TestInferTimezones(t *testing.T) {
+	organized := func(email, zone string) *calendar.Event {
+		return &calendar.Event{
+			Organizer: &calendar.EventOrganizer{Email: email},
+			Start:     &calendar.EventDateTime{DateTime: "2025-01-15T10:00:00Z", TimeZone: zone},
+		}
+	}
+	events := []*calendar.Event{
+		organized("alex@example.com", "Europe/London"),
+		organized("Alex@example.com", "Europe/London"),
+		organized("alex@example.com", "America/New_York"),
+		organized("sam@example.com", ""),
+		organized("other@example.com", "Asia/Tokyo"),
+	}
+
+	zones := inferTimezones(events, []string{"alex@example.com", "sam@example.com"})
+	if zones["alex@example.com"] != "Europe/London" {
+		t.Errorf("expected majority zone Europe/London, got %q", zones["alex@example.com"])
+	}
+	if zone, ok := zones["sam@example.com"]; !ok || zone != "" {
+		t.Errorf("expected cached miss for sam, got %q (present %v)", zone, ok)
+	}
+	if _, ok := zones["other@example.com"]; ok {
+		t.Error("only requested emails should be inferred")
+	}
+}
+
+// ----- AttendeeTimezones -----
+
+func TestAttendeeTimezones_ConfiguredAndCachedNeedNoAPI(t *testing.T) {
+	// No service: any API call would panic
+	c := NewClient(nil, nil)
+	c.SetAttendeeTimezones(map[string]string{"Alex@example.com": "Europe/London"})
+	c.inferredTimezones = map[string]string{"sam@example.com": "Asia/Tokyo", "kim@example.com": ""}
+
+	zones, err := c.AttendeeTimezones([]string{"alex@example.com", "SAM@example.com", "kim@example.com"})
+	if err != nil {
+		t.Fatalf("AttendeeTimezones() error: %v", err)
+	}
+	if zones["alex@example.com"] != "Europe/London" || zones["sam@example.com"] != "Asia/Tokyo" {
+		t.Errorf("unexpected zones %v", zones)
+	}
+	if _, ok := zones["kim@example.com"]; ok {
+		t.Error("cached miss should not produce a zone")
+	}
+}
+
+// ----- formatAttendeeLocalTimes -----
+
+func TestFormatAttendeeLocalTimes(t *testing.T) {
+	start := time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC)
+	out := formatAttendeeLocalTimes(start, start.Add(30*time.Minute),
+		[]string{"tokyo@example.com", "london@example.com", "who@example.com"},
+		map[string]string{"london@example.com": "Europe/London", "tokyo@example.com": "Asia/Tokyo"}, TimeFormat{})
+
+	if !strings.Contains(out, "london@example.com (Europe/London): Wed, Jan 15, 3:00 PM – 3:30 PM GMT") {
+		t.Errorf("missing London line:\n%s", out)
+	}
+	// 15:00 UTC is midnight in Tokyo, so the meeting is on the next day there
+	if !strings.Contains(out, "tokyo@example.com (Asia/Tokyo): Thu, Jan 16, 12:00 AM – 12:30 AM JST") {
+		t.Errorf("missing Tokyo line:\n%s", out)
+	}
+	if !strings.Contains(out, "Time zone unknown: who@example.com") {
+		t.Errorf("missing unknown line:\n%s", out)
+	}
+}
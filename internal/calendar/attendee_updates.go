@@ -0,0 +1,92 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// responseStatuses are the RSVP states an attendee can have.
+var responseStatuses = []string{"accepted", "declined", "tentative", "needsAction"}
+
+// AttendeeUpdate changes one of an event's current attendees without
+// touching the others, for an organizer adjusting individual guests. Nil
+// fields are left as they are.
+type AttendeeUpdate struct {
+	Email          string  `json:"email"`
+	Optional       *bool   `json:"optional,omitempty"`
+	ResponseStatus *string `json:"response_status,omitempty"` // e.g. "accepted" so they are no longer asked to respond
+}
+
+// parseAttendeeUpdates parses edit_event's update_attendees argument.
+func parseAttendeeUpdates(values []interface{}) ([]AttendeeUpdate, error) {
+	updates := make([]AttendeeUpdate, 0, len(values))
+	for i, v := range values {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("update_attendees %d must be an object with an email", i+1)
+		}
+		email, err := normalizeEmail(getStringOrDefault(m, "email", ""))
+		if err != nil {
+			return nil, fmt.Errorf("update_attendees %d: %v", i+1, err)
+		}
+		update := AttendeeUpdate{Email: email}
+		if optional, ok := m["optional"].(bool); ok {
+			update.Optional = &optional
+		}
+		if status, ok := m["response_status"].(string); ok {
+			if !containsString(responseStatuses, status) {
+				return nil, fmt.Errorf("update_attendees %d: response_status must be one of %s", i+1, strings.Join(responseStatuses, ", "))
+			}
+			update.ResponseStatus = &status
+		}
+		if update.Optional == nil && update.ResponseStatus == nil {
+			return nil, fmt.Errorf("update_attendees %d (%s): give optional or response_status to change", i+1, email)
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+// mergeAttendeeUpdates returns a copy of current with updates applied to the
+// matching attendees; everyone else is copied unchanged. Every update must
+// name a current attendee.
+func mergeAttendeeUpdates(current []*calendar.EventAttendee, updates []AttendeeUpdate) ([]*calendar.EventAttendee, error) {
+	merged := make([]*calendar.EventAttendee, len(current))
+	byEmail := make(map[string]*calendar.EventAttendee, len(current))
+	for i, a := range current {
+		attendee := *a
+		merged[i] = &attendee
+		byEmail[strings.ToLower(a.Email)] = &attendee
+	}
+	for _, update := range updates {
+		attendee, ok := byEmail[strings.ToLower(update.Email)]
+		if !ok {
+			return nil, fmt.Errorf("%s is not an attendee of the event; add them with attendees instead", update.Email)
+		}
+		if update.Optional != nil {
+			attendee.Optional = *update.Optional
+		}
+		if update.ResponseStatus != nil {
+			attendee.ResponseStatus = *update.ResponseStatus
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestParseAttendeeUpdates(t *testing.T) {
+	updates, err := parseAttendeeUpdates([]interface{}{
+		map[string]interface{}{"email": " Bob@Example.com ", "optional": true},
+		map[string]interface{}{"email": "carol@example.com", "response_status": "accepted"},
+	})
+	if err != nil {
+		t.Fatalf("parseAttendeeUpdates: %v", err)
+	}
+	if updates[0].Email != "Bob@example.com" || updates[0].Optional == nil || !*updates[0].Optional || updates[0].ResponseStatus != nil {
+		t.Errorf("first update = %+v", updates[0])
+	}
+	if updates[1].ResponseStatus == nil || *updates[1].ResponseStatus != "accepted" || updates[1].Optional != nil {
+		t.Errorf("second update = %+v", updates[1])
+	}
+
+	for _, bad := range []interface{}{
+		"bob@example.com",
+		map[string]interface{}{"email": "bob@example.com"},
+		map[string]interface{}{"email": "bob@example.com", "response_status": "maybe"},
+		map[string]interface{}{"optional": true},
+	} {
+		if _, err := parseAttendeeUpdates([]interface{}{bad}); err == nil {
+			t.Errorf("expected %v to be rejected", bad)
+		}
+	}
+}
+
+func TestMergeAttendeeUpdates(t *testing.T) {
+	current := []*calendar.EventAttendee{
+		{Email: "me@example.com", Organizer: true, Self: true, ResponseStatus: "accepted"},
+		{Email: "Bob@example.com", ResponseStatus: "needsAction"},
+		{Email: "carol@example.com", ResponseStatus: "declined", Comment: "Travelling"},
+	}
+	optional, accepted := true, "accepted"
+	merged, err := mergeAttendeeUpdates(current, []AttendeeUpdate{
+		{Email: "bob@example.com", Optional: &optional, ResponseStatus: &accepted},
+	})
+	if err != nil {
+		t.Fatalf("mergeAttendeeUpdates: %v", err)
+	}
+	if len(merged) != 3 || !merged[1].Optional || merged[1].ResponseStatus != "accepted" {
+		t.Errorf("bob was not updated: %+v", merged[1])
+	}
+	if !reflect.DeepEqual(merged[0], current[0]) || !reflect.DeepEqual(merged[2], current[2]) {
+		t.Errorf("other attendees changed: %+v, %+v", merged[0], merged[2])
+	}
+	if current[1].Optional || current[1].ResponseStatus != "needsAction" {
+		t.Error("the current attendees were modified in place")
+	}
+
+	if _, err := mergeAttendeeUpdates(current, []AttendeeUpdate{{Email: "dave@example.com", Optional: &optional}}); err == nil || !strings.Contains(err.Error(), "not an attendee") {
+		t.Errorf("expected an unknown attendee to fail, got %v", err)
+	}
+}
+
+func TestEditEventUpdateAttendees(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	event, err := store.InsertEvent("primary", &calendar.Event{
+		Summary: "Design review",
+		Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+		End:     &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339)},
+		Attendees: []*calendar.EventAttendee{
+			{Email: fake.DemoOwner, Organizer: true, Self: true, ResponseStatus: "accepted"},
+			{Email: "bob@example.com", ResponseStatus: "needsAction"},
+			{Email: "carol@example.com", ResponseStatus: "tentative", Comment: "May be late"},
+		},
+	}, 0)
+	if err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	result, err := ct.HandleTool("edit_event", map[string]interface{}{
+		"event_id": event.Id,
+		"update_attendees": []interface{}{
+			map[string]interface{}{"email": "bob@example.com", "optional": true, "response_status": "accepted"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("edit_event: %v", err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "bob@example.com (accepted) optional") {
+		t.Errorf("the change was not reported:\n%s", text)
+	}
+
+	updated, err := ct.client.GetEvent("primary", event.Id)
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if len(updated.Attendees) != 3 {
+		t.Fatalf("got %d attendees, want 3", len(updated.Attendees))
+	}
+	for _, a := range updated.Attendees {
+		switch a.Email {
+		case "bob@example.com":
+			if !a.Optional || a.ResponseStatus != "accepted" {
+				t.Errorf("bob = %+v", a)
+			}
+		case "carol@example.com":
+			if a.Optional || a.ResponseStatus != "tentative" || a.Comment != "May be late" {
+				t.Errorf("carol should be untouched, got %+v", a)
+			}
+		}
+	}
+
+	if _, err := ct.HandleTool("edit_event", map[string]interface{}{
+		"event_id":         event.Id,
+		"attendees":        []interface{}{"bob@example.com"},
+		"update_attendees": []interface{}{map[string]interface{}{"email": "bob@example.com", "optional": false}},
+	}); err == nil {
+		t.Error("expected attendees and update_attendees together to fail")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// availabilityStep is the granularity free slots are rounded to
+	availabilityStep = 30 * time.Minute
+	// maxAvailabilityDays bounds how far ahead share_availability looks
+	maxAvailabilityDays = 14
+)
+
+// AvailabilityParams configures which free time share_availability reports.
+type AvailabilityParams struct {
+	Days            int            // number of days starting today
+	Location        *time.Location // zone that working hours and output use
+	WorkStart       time.Duration  // offset of the working day's start from midnight
+	WorkEnd         time.Duration  // offset of the working day's end from midnight
+	MinDuration     time.Duration  // shortest slot worth offering
+	IncludeWeekends bool
+}
+
+// TimeSlot is a free interval.
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// freeSlots returns the free working-hour slots from now over the requested
+// days, given busy periods. Slot starts are rounded up and ends rounded down
+// to availabilityStep in the local zone, so they read cleanly in an email.
+func freeSlots(busy []TimeSlot, now time.Time, params AvailabilityParams) []TimeSlot {
+	busy = append([]TimeSlot{}, busy...)
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+
+	local := now.In(params.Location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, params.Location)
+
+	var slots []TimeSlot
+	for d := 0; d < params.Days; d++ {
+		day := today.AddDate(0, 0, d)
+		if !params.IncludeWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+
+		windowStart := atClock(day, params.WorkStart)
+		windowEnd := atClock(day, params.WorkEnd)
+		if windowStart.Before(now) {
+			windowStart = now
+		}
+
+		cursor := windowStart
+		for _, b := range busy {
+			if !b.End.After(cursor) || !b.Start.Before(windowEnd) {
+				continue
+			}
+			slots = appendSlot(slots, cursor, b.Start, day, params)
+			if b.End.After(cursor) {
+				cursor = b.End
+			}
+		}
+		slots = appendSlot(slots, cursor, windowEnd, day, params)
+	}
+	return slots
+}
+
+// appendSlot rounds a free interval to availabilityStep and keeps it if it is
+// still at least MinDuration long.
+func appendSlot(slots []TimeSlot, start, end, day time.Time, params AvailabilityParams) []TimeSlot {
+	start = roundToStep(start, day, true)
+	end = roundToStep(end, day, false)
+	if end.Sub(start) < params.MinDuration || !end.After(start) {
+		return slots
+	}
+	return append(slots, TimeSlot{Start: start, End: end})
+}
+
+// roundToStep rounds t to a multiple of availabilityStep after local midnight
+// of day, up or down.
+func roundToStep(t, day time.Time, up bool) time.Time {
+	offset := t.Sub(day)
+	steps := offset / availabilityStep
+	if up && offset%availabilityStep != 0 {
+		steps++
+	}
+	return day.Add(steps * availabilityStep)
+}
+
+// atClock returns the wall-clock time offset from midnight on day.
+func atClock(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// parseClock parses "HH:MM" into an offset from midnight.
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// busySlots converts a calendar's free/busy periods to time slots.
+func busySlots(cal calendar.FreeBusyCalendar) []TimeSlot {
+	var slots []TimeSlot
+	for _, p := range cal.Busy {
+		start, err1 := time.Parse(time.RFC3339, p.Start)
+		end, err2 := time.Parse(time.RFC3339, p.End)
+		if err1 == nil && err2 == nil {
+			slots = append(slots, TimeSlot{Start: start, End: end})
+		}
+	}
+	return slots
+}
+
+// Availability is share_availability's structured result: the free slots
+// as RFC3339 times in the requested time zone.
+type Availability struct {
+	TimeZone string          `json:"time_zone"`
+	Slots    []AvailableSlot `json:"slots"`
+}
+
+// AvailableSlot is one free slot.
+type AvailableSlot struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func newAvailability(slots []TimeSlot, loc *time.Location) Availability {
+	availability := Availability{TimeZone: loc.String(), Slots: []AvailableSlot{}}
+	for _, s := range slots {
+		availability.Slots = append(availability.Slots, AvailableSlot{
+			Start: s.Start.In(loc).Format(time.RFC3339),
+			End:   s.End.In(loc).Format(time.RFC3339),
+		})
+	}
+	return availability
+}
+
+// formatAvailability renders free slots as one bullet per day, ready to paste
+// into an email.
+func formatAvailability(slots []TimeSlot, loc *time.Location, tf TimeFormat) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "I'm available at these times (%s):\n\n", loc.String())
+	if len(slots) == 0 {
+		result.WriteString("• No free time in working hours over this period\n")
+		return result.String()
+	}
+
+	var day string
+	var ranges []string
+	flush := func() {
+		if day != "" {
+			fmt.Fprintf(&result, "• %s: %s\n", day, strings.Join(ranges, ", "))
+		}
+	}
+	for _, s := range slots {
+		start, end := s.Start.In(loc), s.End.In(loc)
+		if label := tf.ShortDate(start); label != day {
+			flush()
+			day, ranges = label, nil
+		}
+		ranges = append(ranges, tf.Clock(start)+" – "+tf.Clock(end))
+	}
+	flush()
+	return result.String()
+}
+
+func (ct *CalendarTools) handleShareAvailability(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	params := AvailabilityParams{
+		Days:            getIntOrDefault(arguments, "days", 5),
+		Location:        loc,
+		MinDuration:     time.Duration(getIntOrDefault(arguments, "min_duration_minutes", 30)) * time.Minute,
+		IncludeWeekends: getBoolOrDefault(arguments, "include_weekends", false),
+	}
+	if params.Days < 1 || params.Days > maxAvailabilityDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", maxAvailabilityDays)
+	}
+	if params.MinDuration < availabilityStep {
+		params.MinDuration = availabilityStep
+	}
+	if params.WorkStart, params.WorkEnd, err = ct.workHours(arguments); err != nil {
+		return nil, err
+	}
+
+	calendarID := ct.calendarID(arguments)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	response, err := ct.client.GetFreeBusy(FreeBusyParams{
+		TimeMin:     now,
+		TimeMax:     today.AddDate(0, 0, params.Days),
+		TimeZone:    timezone,
+		CalendarIDs: []string{calendarID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get free/busy information: %v", err)
+	}
+	cal, ok := response.Calendars[calendarID]
+	if !ok {
+		return nil, fmt.Errorf("no free/busy information returned for calendar %s", calendarID)
+	}
+	if len(cal.Errors) > 0 {
+		return nil, fmt.Errorf("cannot read free/busy for calendar %s: %s", calendarID, cal.Errors[0].Reason)
+	}
+
+	protected, err := ct.protectedBusy(calendarID, now, today.AddDate(0, 0, params.Days))
+	if err != nil {
+		return nil, err
+	}
+
+	ruled, err := ct.policyBusy(calendarID, now, today.AddDate(0, 0, params.Days), loc)
+	if err != nil {
+		return nil, err
+	}
+	busy := append(busySlots(cal), protected...)
+	busy = append(busy, ruled...)
+	slots := freeSlots(busy, now, params)
+
+	return structuredResult(formatAvailability(slots, loc, ct.client.TimeFormat()), newAvailability(slots, loc)), nil
+}
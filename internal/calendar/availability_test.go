@@ -0,0 +1,118 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func availabilityParams(t *testing.T, zone string) AvailabilityParams {
+	t.Helper()
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s): %v", zone, err)
+	}
+	return AvailabilityParams{
+		Days:        3,
+		Location:    loc,
+		WorkStart:   9 * time.Hour,
+		WorkEnd:     17 * time.Hour,
+		MinDuration: 30 * time.Minute,
+	}
+}
+
+// ----- freeSlots -----
+
+func TestFreeSlots(t *testing.T) {
+	params := availabilityParams(t, "America/New_York")
+	loc := params.Location
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, loc) }
+
+	// Friday 10:07 local; the weekend is skipped and Monday is fully free
+	now := at(7, 10, 7)
+	busy := []TimeSlot{
+		{Start: at(7, 12, 0), End: at(7, 13, 10)},
+		{Start: at(7, 13, 0), End: at(7, 14, 0)},  // overlaps the previous block
+		{Start: at(7, 16, 45), End: at(7, 18, 0)}, // runs past the end of the day
+	}
+
+	got := freeSlots(busy, now, params)
+	want := []TimeSlot{
+		{Start: at(7, 10, 30), End: at(7, 12, 0)},
+		{Start: at(7, 14, 0), End: at(7, 16, 30)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d slots %v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("slot %d = %v–%v, want %v–%v", i, got[i].Start, got[i].End, want[i].Start, want[i].End)
+		}
+	}
+}
+
+func TestFreeSlots_WeekendsAndMinDuration(t *testing.T) {
+	params := availabilityParams(t, "UTC")
+	params.IncludeWeekends = true
+	params.MinDuration = time.Hour
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, time.UTC) }
+
+	now := at(8, 8, 0) // Saturday before working hours
+	busy := []TimeSlot{{Start: at(8, 9, 45), End: at(8, 17, 0)}}
+
+	got := freeSlots(busy, now, params)
+	// Saturday's 45 minutes are too short; Sunday and Monday are fully free
+	if len(got) != 2 || !got[0].Start.Equal(at(9, 9, 0)) || !got[1].End.Equal(at(10, 17, 0)) {
+		t.Errorf("unexpected slots: %v", got)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	if d, err := parseClock("08:30"); err != nil || d != 8*time.Hour+30*time.Minute {
+		t.Errorf("parseClock(08:30) = %v, %v", d, err)
+	}
+	if _, err := parseClock("8am"); err == nil {
+		t.Error("expected error for invalid clock time")
+	}
+}
+
+// ----- formatAvailability -----
+
+func TestFormatAvailability(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/London")
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, loc) }
+	slots := []TimeSlot{
+		{Start: at(3, 9), End: at(3, 11)},
+		{Start: at(3, 14), End: at(3, 17)},
+		{Start: at(4, 10), End: at(4, 12)},
+	}
+
+	text := formatAvailability(slots, loc, TimeFormat{})
+	for _, want := range []string{
+		"(Europe/London)",
+		"• Mon, Mar 3: 9:00 AM – 11:00 AM, 2:00 PM – 5:00 PM\n",
+		"• Tue, Mar 4: 10:00 AM – 12:00 PM\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+
+	if empty := formatAvailability(nil, loc, TimeFormat{}); !strings.Contains(empty, "No free time") {
+		t.Errorf("unexpected empty output: %s", empty)
+	}
+}
@@ -0,0 +1,454 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// backupVersion is the format version written by backup_calendar; restore
+// refuses backups from a newer version.
+const backupVersion = 1
+
+// CalendarBackup is a snapshot of the events of a calendar in a time range, as
+// written by backup_calendar and read by restore_calendar. Recurring series are
+// stored once, as their master event with its recurrence rules, followed by
+// their modified and cancelled instances.
+type CalendarBackup struct {
+	Version    int               `json:"version"`
+	CalendarID string            `json:"calendar_id"`
+	TimeMin    string            `json:"time_min"`
+	TimeMax    string            `json:"time_max"`
+	CreatedAt  string            `json:"created_at"`
+	Events     []*calendar.Event `json:"events"`
+}
+
+// Restore actions, one per backed-up event.
+const (
+	restoreRecreated = "recreated" // deleted since the backup and created again
+	restoreReverted  = "reverted"  // changed since the backup and overwritten
+	restoreUnchanged = "unchanged" // same as in the backup
+	restoreSkipped   = "skipped"   // changed since the backup and overwrite not set
+	restoreFailed    = "failed"
+)
+
+// RestoreResult reports what restore_calendar did, or would do on a dry run,
+// with one backed-up event.
+type RestoreResult struct {
+	EventID string `json:"event_id"`
+	Title   string `json:"title"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// BackupEvents returns every event of a calendar overlapping a time range
+// without expanding recurring series: masters carry their recurrence rules,
+// and modified or cancelled instances follow as separate events. Deleted
+// events that are not instances are left out.
+func (c *Client) BackupEvents(calendarID string, timeMin, timeMax time.Time) ([]*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	var events []*calendar.Event
+	call := c.service.Events.List(calendarID).
+		TimeMin(timeMin.Format(time.RFC3339)).
+		TimeMax(timeMax.Format(time.RFC3339)).
+		SingleEvents(false).
+		ShowDeleted(true).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events: %v", err)
+		}
+		for _, event := range page.Items {
+			if event.Status == "cancelled" && event.RecurringEventId == "" {
+				continue
+			}
+			events = append(events, event)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return orderForRestore(events), nil
+}
+
+// orderForRestore puts events that are not instances first, so a restored
+// series exists before its modified and cancelled instances are applied.
+func orderForRestore(events []*calendar.Event) []*calendar.Event {
+	ordered := make([]*calendar.Event, 0, len(events))
+	for _, event := range events {
+		if event.RecurringEventId == "" {
+			ordered = append(ordered, event)
+		}
+	}
+	for _, event := range events {
+		if event.RecurringEventId != "" {
+			ordered = append(ordered, event)
+		}
+	}
+	return ordered
+}
+
+// restoreAction decides what restoring saved does given the event's current
+// state: current is nil when the event no longer exists. Instances of a series
+// recreated by the same restore are always reapplied, since the series came
+// back without its exceptions.
+func restoreAction(saved, current *calendar.Event, overwrite, seriesRecreated bool) (string, string) {
+	savedCancelled := saved.Status == "cancelled"
+	switch {
+	case seriesRecreated:
+		return restoreReverted, ""
+	case current == nil && saved.RecurringEventId != "":
+		return restoreSkipped, "its recurring series no longer exists"
+	case current == nil:
+		return restoreRecreated, ""
+	case current.Status == "cancelled" && savedCancelled:
+		return restoreUnchanged, ""
+	case current.Status == "cancelled":
+		return restoreRecreated, ""
+	case restoreFingerprint(current) == restoreFingerprint(saved):
+		return restoreUnchanged, ""
+	case overwrite:
+		return restoreReverted, ""
+	default:
+		return restoreSkipped, "changed since the backup; pass overwrite to revert it"
+	}
+}
+
+// restoreFingerprint summarizes the parts of an event a restore puts back, so
+// an event is only reported as changed when one of them differs. Guests'
+// responses and fields the API assigns are left out.
+func restoreFingerprint(e *calendar.Event) string {
+	var attendees []string
+	for _, a := range e.Attendees {
+		attendees = append(attendees, strings.ToLower(a.Email))
+	}
+	props := e.ExtendedProperties
+	if props != nil && len(props.Private) == 0 && len(props.Shared) == 0 {
+		props = nil
+	}
+	raw, _ := json.Marshal([]interface{}{
+		e.Summary, e.Description, e.Location, e.Start, e.End, e.Recurrence,
+		attendees, e.ColorId, e.Transparency, e.Visibility, props,
+	})
+	return string(raw)
+}
+
+// restoreBody copies saved without the fields the API assigns, so it can be
+// sent as an insert or update. A stale conference create request is dropped;
+// the existing conference is kept.
+func restoreBody(saved *calendar.Event) (*calendar.Event, error) {
+	raw, err := json.Marshal(saved)
+	if err != nil {
+		return nil, err
+	}
+	body := &calendar.Event{}
+	if err := json.Unmarshal(raw, body); err != nil {
+		return nil, err
+	}
+	body.Etag = ""
+	body.Kind = ""
+	body.HtmlLink = ""
+	body.Created = ""
+	body.Updated = ""
+	body.Creator = nil
+	body.ICalUID = ""
+	body.Sequence = 0
+	body.HangoutLink = ""
+	if body.ConferenceData != nil {
+		body.ConferenceData.CreateRequest = nil
+	}
+	return body, nil
+}
+
+// RestoreEvents brings a calendar back to the events of a backup. Events
+// deleted since the backup are recreated with their original IDs where the API
+// allows it; events changed since the backup are only reverted with overwrite.
+// Events created after the backup are left alone. With dryRun nothing is
+// written and the results describe what would happen.
+func (c *Client) RestoreEvents(calendarID string, events []*calendar.Event, overwrite, dryRun bool, sendUpdates string) ([]RestoreResult, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	recreated := make(map[string]bool)
+	var results []RestoreResult
+	for _, saved := range orderForRestore(events) {
+		result := RestoreResult{EventID: saved.Id, Title: eventTitle(saved)}
+		current, err := c.GetEvent(calendarID, saved.Id)
+		if err != nil && !isNotFound(err) {
+			result.Action, result.Reason = restoreFailed, err.Error()
+			results = append(results, result)
+			continue
+		}
+		if err != nil {
+			current = nil
+		}
+
+		result.Action, result.Reason = restoreAction(saved, current, overwrite, recreated[saved.RecurringEventId])
+		if !dryRun && (result.Action == restoreRecreated || result.Action == restoreReverted) {
+			if err := c.applyRestore(calendarID, saved, current, sendUpdates); err != nil {
+				result.Action, result.Reason = restoreFailed, err.Error()
+			}
+		}
+		if result.Action == restoreRecreated && saved.Recurrence != nil {
+			recreated[saved.Id] = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// applyRestore writes saved back to the calendar: a missing event is inserted
+// (under a new ID if the API still reserves the old one), a cancelled instance
+// is deleted again, and anything else is replaced.
+func (c *Client) applyRestore(calendarID string, saved, current *calendar.Event, sendUpdates string) error {
+	if current == nil && saved.RecurringEventId != "" {
+		return fmt.Errorf("its recurring series was recreated under a new ID")
+	}
+	if saved.Status == "cancelled" {
+		return c.DeleteEvent(calendarID, saved.Id, sendUpdates)
+	}
+	body, err := restoreBody(saved)
+	if err != nil {
+		return err
+	}
+
+	if current == nil {
+		_, err := c.insertRestored(calendarID, body, sendUpdates)
+		if isDuplicateID(err) {
+			body.Id = ""
+			_, err = c.insertRestored(calendarID, body, sendUpdates)
+		}
+		return err
+	}
+
+	call := c.service.Events.Update(calendarID, saved.Id, body).ConferenceDataVersion(1)
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	_, err = call.Do()
+	return err
+}
+
+func (c *Client) insertRestored(calendarID string, body *calendar.Event, sendUpdates string) (*calendar.Event, error) {
+	call := c.service.Events.Insert(calendarID, body).ConferenceDataVersion(1)
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	return call.Do()
+}
+
+// isDuplicateID reports whether err is the 409 the API returns when an event
+// ID is already taken, as it stays for a while after the event is deleted.
+func isDuplicateID(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict
+}
+
+func (ct *CalendarTools) handleBackupCalendar(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	timeMinStr := getStringOrDefault(arguments, "time_min", "")
+	if timeMinStr == "" {
+		return nil, fmt.Errorf("time_min is required")
+	}
+	timeMaxStr := getStringOrDefault(arguments, "time_max", "")
+	if timeMaxStr == "" {
+		return nil, fmt.Errorf("time_max is required")
+	}
+	timeMin, err := time.Parse(time.RFC3339, timeMinStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_min format: %v", err)
+	}
+	timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_max format: %v", err)
+	}
+	if !timeMax.After(timeMin) {
+		return nil, fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+
+	calendarID := ct.calendarID(arguments)
+	events, err := ct.client.BackupEvents(calendarID, timeMin, timeMax)
+	if err != nil {
+		return nil, err
+	}
+	backup := CalendarBackup{
+		Version:    backupVersion,
+		CalendarID: calendarID,
+		TimeMin:    timeMin.Format(time.RFC3339),
+		TimeMax:    timeMax.Format(time.RFC3339),
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		Events:     events,
+	}
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup: %v", err)
+	}
+
+	series := 0
+	for _, event := range events {
+		if event.Recurrence != nil {
+			series++
+		}
+	}
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "💾 Backed up %d events of %s from %s to %s\n", len(events), calendarID, backup.TimeMin, backup.TimeMax)
+	fmt.Fprintf(&summary, "• Recurring series: %d (stored once with their recurrence rules)\n", series)
+
+	if file := getStringOrDefault(arguments, "file", ""); file != "" {
+		if err := os.WriteFile(file, append(data, '\n'), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write backup: %v", err)
+		}
+		fmt.Fprintf(&summary, "• File: %s\n", file)
+		summary.WriteString("\nRestore it with restore_calendar and this file.")
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{Type: "text", Text: summary.String()}},
+		}, nil
+	}
+
+	summary.WriteString("\nThe backup JSON follows as a separate content item; pass it to restore_calendar as backup.")
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{
+			{Type: "text", Text: summary.String()},
+			{Type: "text", Text: string(data)},
+		},
+	}, nil
+}
+
+// readBackup loads a backup from the file argument or the backup argument,
+// given as a JSON string or object.
+func readBackup(arguments map[string]interface{}) (*CalendarBackup, error) {
+	var data []byte
+	file := getStringOrDefault(arguments, "file", "")
+	switch value := arguments["backup"].(type) {
+	case string:
+		data = []byte(value)
+	case map[string]interface{}:
+		data, _ = json.Marshal(value)
+	}
+	switch {
+	case file != "" && data != nil:
+		return nil, fmt.Errorf("pass either file or backup, not both")
+	case file != "":
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %v", err)
+		}
+		data = raw
+	case data == nil:
+		return nil, fmt.Errorf("file or backup is required")
+	}
+
+	backup := &CalendarBackup{}
+	if err := json.Unmarshal(data, backup); err != nil {
+		return nil, fmt.Errorf("invalid backup: %v", err)
+	}
+	if backup.Version < 1 || backup.Version > backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", backup.Version)
+	}
+	return backup, nil
+}
+
+func (ct *CalendarTools) handleRestoreCalendar(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	backup, err := readBackup(arguments)
+	if err != nil {
+		return nil, err
+	}
+	calendarID := backup.CalendarID
+	if _, ok := arguments["calendar_id"].(string); ok {
+		calendarID = ct.calendarID(arguments)
+	}
+	overwrite := getBoolOrDefault(arguments, "overwrite", false)
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+	sendUpdates, err := ct.sendUpdates(arguments, false)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := ct.client.RestoreEvents(calendarID, backup.Events, overwrite, dryRun, sendUpdates)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: formatRestoreResults(backup, calendarID, results, dryRun, sendUpdates)}},
+	}, nil
+}
+
+func formatRestoreResults(backup *CalendarBackup, calendarID string, results []RestoreResult, dryRun bool, sendUpdates string) string {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Action]++
+	}
+
+	var b strings.Builder
+	if dryRun {
+		fmt.Fprintf(&b, "🔎 Dry run: restoring the backup of %s taken %s to %s\n", backup.CalendarID, backup.CreatedAt, calendarID)
+	} else {
+		fmt.Fprintf(&b, "♻️ Restored the backup of %s taken %s to %s (%s)\n", backup.CalendarID, backup.CreatedAt, calendarID, describeSendUpdates(sendUpdates))
+	}
+	fmt.Fprintf(&b, "• Recreated: %d\n• Reverted: %d\n• Unchanged: %d\n• Skipped: %d\n", counts[restoreRecreated], counts[restoreReverted], counts[restoreUnchanged], counts[restoreSkipped])
+	if counts[restoreFailed] > 0 {
+		fmt.Fprintf(&b, "• Failed: %d\n", counts[restoreFailed])
+	}
+
+	var changes []RestoreResult
+	for _, r := range results {
+		if r.Action != restoreUnchanged {
+			changes = append(changes, r)
+		}
+	}
+	if len(changes) > 0 {
+		b.WriteString("\nEvents:\n")
+		for _, r := range changes {
+			fmt.Fprintf(&b, "• %s (%s): %s", r.Title, r.EventID, r.Action)
+			if r.Reason != "" {
+				fmt.Fprintf(&b, " — %s", r.Reason)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if dryRun && counts[restoreRecreated]+counts[restoreReverted] > 0 {
+		b.WriteString("\nRun again without dry_run to apply these changes.\n")
+	}
+
+	jsonData, _ := json.MarshalIndent(results, "", "  ")
+	return b.String() + "\n" + string(jsonData)
+}
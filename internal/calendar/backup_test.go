@@ -0,0 +1,159 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- restoreAction -----
+
+func TestRestoreAction(t *testing.T) {
+	saved := &calendar.Event{Id: "e1", Summary: "Planning", Status: "confirmed", Updated: "2026-01-01T00:00:00Z"}
+	touched := &calendar.Event{Id: "e1", Summary: "Planning", Status: "confirmed", Updated: "2026-02-01T00:00:00Z"}
+	renamed := &calendar.Event{Id: "e1", Summary: "Planning (moved)", Status: "confirmed"}
+	cancelled := &calendar.Event{Id: "e1", Summary: "Planning", Status: "cancelled"}
+	savedInstance := &calendar.Event{Id: "s1_20260105T090000Z", RecurringEventId: "s1", Summary: "Standup", Status: "confirmed"}
+	savedCancelledInstance := &calendar.Event{Id: "s1_20260112T090000Z", RecurringEventId: "s1", Status: "cancelled"}
+	currentInstance := &calendar.Event{Id: "s1_20260112T090000Z", RecurringEventId: "s1", Summary: "Standup", Status: "confirmed"}
+
+	cases := []struct {
+		name            string
+		saved, current  *calendar.Event
+		overwrite       bool
+		seriesRecreated bool
+		want            string
+	}{
+		{"deleted event", saved, nil, false, false, restoreRecreated},
+		{"deleted event still visible as cancelled", saved, cancelled, false, false, restoreRecreated},
+		{"only the update time differs", saved, touched, false, false, restoreUnchanged},
+		{"changed without overwrite", saved, renamed, false, false, restoreSkipped},
+		{"changed with overwrite", saved, renamed, true, false, restoreReverted},
+		{"instance of a missing series", savedInstance, nil, false, false, restoreSkipped},
+		{"cancelled instance still cancelled", savedCancelledInstance, cancelled, false, false, restoreUnchanged},
+		{"cancelled instance of a recreated series", savedCancelledInstance, currentInstance, false, true, restoreReverted},
+		{"instance of a series recreated on a dry run", savedInstance, nil, false, true, restoreReverted},
+	}
+	for _, tc := range cases {
+		if got, _ := restoreAction(tc.saved, tc.current, tc.overwrite, tc.seriesRecreated); got != tc.want {
+			t.Errorf("%s: restoreAction = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// ----- restoreFingerprint -----
+
+func TestRestoreFingerprint_IgnoresResponses(t *testing.T) {
+	a := &calendar.Event{Summary: "Sync", Attendees: []*calendar.EventAttendee{{Email: "Ana@example.com", ResponseStatus: "needsAction"}}}
+	b := &calendar.Event{Summary: "Sync", Attendees: []*calendar.EventAttendee{{Email: "ana@example.com", ResponseStatus: "accepted"}}, ExtendedProperties: &calendar.EventExtendedProperties{}}
+	if restoreFingerprint(a) != restoreFingerprint(b) {
+		t.Error("guest responses, email case and empty extended properties should not count as changes")
+	}
+	b.Location = "Room 1"
+	if restoreFingerprint(a) == restoreFingerprint(b) {
+		t.Error("a new location should count as a change")
+	}
+}
+
+// ----- orderForRestore -----
+
+func TestOrderForRestore(t *testing.T) {
+	events := []*calendar.Event{
+		{Id: "s1_20260105T090000Z", RecurringEventId: "s1"},
+		{Id: "e1"},
+		{Id: "s1", Recurrence: []string{"RRULE:FREQ=WEEKLY"}},
+	}
+	var got []string
+	for _, e := range orderForRestore(events) {
+		got = append(got, e.Id)
+	}
+	if want := "e1,s1,s1_20260105T090000Z"; strings.Join(got, ",") != want {
+		t.Errorf("orderForRestore = %v, want %s", got, want)
+	}
+}
+
+// ----- restoreBody -----
+
+func TestRestoreBody(t *testing.T) {
+	saved := &calendar.Event{
+		Id: "e1", Etag: `"1"`, ICalUID: "e1@google.com", Sequence: 3, Updated: "2026-01-01T00:00:00Z",
+		Summary:            "Planning",
+		Recurrence:         []string{"RRULE:FREQ=WEEKLY"},
+		ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{"k": "v"}},
+		ConferenceData:     &calendar.ConferenceData{ConferenceId: "abc", CreateRequest: &calendar.CreateConferenceRequest{RequestId: "r1"}},
+	}
+	body, err := restoreBody(saved)
+	if err != nil {
+		t.Fatalf("restoreBody error: %v", err)
+	}
+	if body.Id != "e1" || body.Summary != "Planning" || len(body.Recurrence) != 1 || body.ExtendedProperties.Private["k"] != "v" {
+		t.Errorf("restoreBody lost event content: %+v", body)
+	}
+	if body.Etag != "" || body.ICalUID != "" || body.Sequence != 0 || body.Updated != "" {
+		t.Errorf("restoreBody kept server-assigned fields: %+v", body)
+	}
+	if body.ConferenceData.ConferenceId != "abc" || body.ConferenceData.CreateRequest != nil {
+		t.Errorf("restoreBody should keep the conference but drop its create request: %+v", body.ConferenceData)
+	}
+	if saved.Etag == "" || saved.ConferenceData.CreateRequest == nil {
+		t.Error("restoreBody modified the saved event")
+	}
+}
+
+// ----- readBackup -----
+
+func TestReadBackup(t *testing.T) {
+	valid := `{"version":1,"calendar_id":"primary","events":[{"id":"e1","summary":"Planning"}]}`
+	file := filepath.Join(t.TempDir(), "backup.json")
+	if err := os.WriteFile(file, []byte(valid), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		arguments map[string]interface{}
+		wantErr   string
+	}{
+		{"JSON string", map[string]interface{}{"backup": valid}, ""},
+		{"JSON object", map[string]interface{}{"backup": map[string]interface{}{"version": 1.0, "calendar_id": "primary", "events": []interface{}{map[string]interface{}{"id": "e1", "summary": "Planning"}}}}, ""},
+		{"file", map[string]interface{}{"file": file}, ""},
+		{"neither", map[string]interface{}{}, "file or backup is required"},
+		{"both", map[string]interface{}{"file": file, "backup": valid}, "not both"},
+		{"missing file", map[string]interface{}{"file": filepath.Join(t.TempDir(), "none.json")}, "failed to read backup"},
+		{"not JSON", map[string]interface{}{"backup": "nope"}, "invalid backup"},
+		{"newer version", map[string]interface{}{"backup": `{"version":2}`}, "unsupported backup version"},
+	}
+	for _, tc := range cases {
+		backup, err := readBackup(tc.arguments)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tc.name, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if backup.CalendarID != "primary" || len(backup.Events) != 1 || backup.Events[0].Summary != "Planning" {
+			t.Errorf("%s: readBackup = %+v", tc.name, backup)
+		}
+	}
+}
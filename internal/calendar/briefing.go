@@ -0,0 +1,337 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// previousOccurrenceYears is how many years before a recurring meeting
+// prepare_for_meeting looks for its previous occurrence
+const previousOccurrenceYears = 1
+
+// MeetingBriefing is everything needed to prepare for one meeting.
+type MeetingBriefing struct {
+	EventID     string              `json:"event_id"`
+	Summary     string              `json:"summary"`
+	Start       string              `json:"start"`
+	End         string              `json:"end"`
+	Location    string              `json:"location,omitempty"`
+	Organizer   string              `json:"organizer,omitempty"`
+	Description string              `json:"description,omitempty"`
+	MeetLink    string              `json:"meet_link,omitempty"`
+	DialIn      []DialIn            `json:"dial_in,omitempty"`
+	HTMLLink    string              `json:"html_link,omitempty"`
+	Documents   []BriefingDocument  `json:"documents,omitempty"`
+	Attendees   []BriefingAttendee  `json:"attendees,omitempty"`
+	Responses   map[string]int      `json:"responses,omitempty"` // responseStatus -> attendee count
+	Previous    *PreviousOccurrence `json:"previous_occurrence,omitempty"`
+}
+
+// BriefingDocument is a Drive file attached to an event.
+type BriefingDocument struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileID   string `json:"file_id,omitempty"` // pass to get_document to read it
+}
+
+// BriefingAttendee is an attendee and their RSVP.
+type BriefingAttendee struct {
+	Email     string `json:"email"`
+	Name      string `json:"name,omitempty"`
+	Response  string `json:"response"`
+	Optional  bool   `json:"optional,omitempty"`
+	Organizer bool   `json:"organizer,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// PreviousOccurrence is the last occurrence of a recurring meeting before the
+// one being prepared for.
+type PreviousOccurrence struct {
+	EventID     string             `json:"event_id"`
+	Summary     string             `json:"summary"`
+	Start       string             `json:"start"`
+	Description string             `json:"description,omitempty"`
+	Documents   []BriefingDocument `json:"documents,omitempty"`
+	Attendees   []BriefingAttendee `json:"attendees,omitempty"`
+}
+
+// newMeetingBriefing assembles the briefing for event. previous may be nil.
+func newMeetingBriefing(event, previous *calendar.Event) MeetingBriefing {
+	b := MeetingBriefing{
+		EventID:     event.Id,
+		Summary:     event.Summary,
+		Start:       formatDiffTime(event.Start),
+		End:         formatDiffTime(event.End),
+		Location:    event.Location,
+		Description: event.Description,
+		MeetLink:    meetLink(event),
+		DialIn:      dialIns(event),
+		HTMLLink:    event.HtmlLink,
+		Documents:   briefingDocuments(event),
+		Attendees:   briefingAttendees(event),
+	}
+	if event.Organizer != nil {
+		b.Organizer = formatPerson(event.Organizer.Email, event.Organizer.DisplayName, event.Organizer.Self)
+	}
+	if len(b.Attendees) > 0 {
+		b.Responses = make(map[string]int)
+		for _, a := range b.Attendees {
+			b.Responses[a.Response]++
+		}
+	}
+	if previous != nil {
+		b.Previous = &PreviousOccurrence{
+			EventID:     previous.Id,
+			Summary:     previous.Summary,
+			Start:       formatDiffTime(previous.Start),
+			Description: previous.Description,
+			Documents:   briefingDocuments(previous),
+			Attendees:   briefingAttendees(previous),
+		}
+	}
+	return b
+}
+
+func briefingDocuments(event *calendar.Event) []BriefingDocument {
+	var docs []BriefingDocument
+	for _, att := range event.Attachments {
+		docs = append(docs, BriefingDocument{
+			Title:    att.Title,
+			URL:      att.FileUrl,
+			MimeType: att.MimeType,
+			FileID:   att.FileId,
+		})
+	}
+	return docs
+}
+
+func briefingAttendees(event *calendar.Event) []BriefingAttendee {
+	var attendees []BriefingAttendee
+	for _, a := range event.Attendees {
+		if a.Resource {
+			continue
+		}
+		response := a.ResponseStatus
+		if response == "" {
+			response = "needsAction"
+		}
+		attendees = append(attendees, BriefingAttendee{
+			Email:     a.Email,
+			Name:      a.DisplayName,
+			Response:  response,
+			Optional:  a.Optional,
+			Organizer: a.Organizer,
+			Comment:   a.Comment,
+		})
+	}
+	return attendees
+}
+
+// PreviousOccurrence returns the last occurrence of event's series that
+// started before event, or nil if event is not a recurring occurrence or the
+// series has no earlier occurrence within a year. Cancelled occurrences are
+// skipped.
+func (c *Client) PreviousOccurrence(calendarID string, event *calendar.Event) (*calendar.Event, error) {
+	if event.RecurringEventId == "" || event.Start == nil {
+		return nil, nil
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	start, _, _, err := parseEventTimes(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *calendar.Event
+	call := c.service.Events.Instances(calendarID, event.RecurringEventId).
+		TimeMin(start.AddDate(-previousOccurrenceYears, 0, 0).Format(time.RFC3339)).
+		TimeMax(start.Format(time.RFC3339)).
+		MaxResults(250).
+		Fields(googleapi.Field("items(" + eventDetailFields + "),nextPageToken"))
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get previous occurrences: %v", err)
+		}
+		// Instances are in start order, so the last earlier one wins
+		for _, inst := range page.Items {
+			if inst.Id != event.Id && inst.Status != "cancelled" {
+				previous = inst
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return previous, nil
+}
+
+// formatBriefing renders a meeting briefing, followed by the structured data.
+func formatBriefing(b MeetingBriefing) string {
+	var result strings.Builder
+
+	title := b.Summary
+	if title == "" {
+		title = "(No Title)"
+	}
+	fmt.Fprintf(&result, "📋 Briefing for '%s':\n\n", title)
+	fmt.Fprintf(&result, "• When: %s – %s\n", b.Start, b.End)
+	if b.Location != "" {
+		fmt.Fprintf(&result, "• Where: %s\n", b.Location)
+	}
+	if b.Organizer != "" {
+		fmt.Fprintf(&result, "• Organizer: %s\n", b.Organizer)
+	}
+	if b.MeetLink != "" {
+		fmt.Fprintf(&result, "• Meet: %s\n", b.MeetLink)
+	}
+	for _, d := range b.DialIn {
+		fmt.Fprintf(&result, "• Dial-in: %s\n", describeDialIn(d))
+	}
+	if b.HTMLLink != "" {
+		fmt.Fprintf(&result, "• Calendar: %s\n", b.HTMLLink)
+	}
+
+	if b.Description != "" {
+		fmt.Fprintf(&result, "\n📝 Agenda / description:\n%s\n", b.Description)
+	}
+
+	if len(b.Documents) > 0 {
+		result.WriteString("\n📎 Documents:\n")
+		writeBriefingDocuments(&result, b.Documents)
+	}
+
+	if len(b.Attendees) > 0 {
+		fmt.Fprintf(&result, "\n👥 Attendees (%d accepted, %d tentative, %d declined, %d not responded):\n",
+			b.Responses["accepted"], b.Responses["tentative"], b.Responses["declined"], b.Responses["needsAction"])
+		for _, a := range b.Attendees {
+			name := a.Email
+			if a.Name != "" {
+				name = fmt.Sprintf("%s <%s>", a.Name, a.Email)
+			}
+			var notes []string
+			if a.Organizer {
+				notes = append(notes, "organizer")
+			}
+			if a.Optional {
+				notes = append(notes, "optional")
+			}
+			line := fmt.Sprintf("• %s — %s", name, a.Response)
+			if len(notes) > 0 {
+				line += " (" + strings.Join(notes, ", ") + ")"
+			}
+			if a.Comment != "" {
+				line += fmt.Sprintf(": %q", a.Comment)
+			}
+			result.WriteString(line + "\n")
+		}
+	}
+
+	if p := b.Previous; p != nil {
+		fmt.Fprintf(&result, "\n🔁 Previous occurrence (%s):\n", p.Start)
+		if p.Summary != b.Summary {
+			fmt.Fprintf(&result, "• Title: %s\n", p.Summary)
+		}
+		if p.Description != "" && p.Description != b.Description {
+			fmt.Fprintf(&result, "• Description: %s\n", p.Description)
+		}
+		var declined []string
+		for _, a := range p.Attendees {
+			if a.Response == "declined" {
+				declined = append(declined, a.Email)
+			}
+		}
+		if len(declined) > 0 {
+			fmt.Fprintf(&result, "• Declined: %s\n", strings.Join(declined, ", "))
+		}
+		if len(p.Documents) > 0 {
+			result.WriteString("• Documents:\n")
+			writeBriefingDocuments(&result, p.Documents)
+		}
+		if p.Summary == b.Summary && (p.Description == "" || p.Description == b.Description) && len(declined) == 0 && len(p.Documents) == 0 {
+			result.WriteString("• Same agenda, no documents and no declines\n")
+		}
+	}
+
+	briefingJSON, _ := json.MarshalIndent(b, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(briefingJSON))
+	return result.String()
+}
+
+func writeBriefingDocuments(result *strings.Builder, docs []BriefingDocument) {
+	for _, d := range docs {
+		title := d.Title
+		if title == "" {
+			title = "Attachment"
+		}
+		fmt.Fprintf(result, "  - %s: %s\n", title, d.URL)
+	}
+}
+
+func (ct *CalendarTools) handlePrepareForMeeting(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID, ok := arguments["event_id"].(string)
+	if !ok || eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	calendarID := ct.calendarID(arguments)
+
+	event, err := ct.client.getEventForChange(calendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A series ID means the next meeting of the series
+	if len(event.Recurrence) > 0 {
+		_, upcoming, err := ct.client.GetRecurringOccurrences(GetRecurringOccurrencesParams{
+			CalendarID:  calendarID,
+			EventID:     event.Id,
+			PastCount:   1,
+			FutureCount: 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(upcoming) == 0 {
+			return nil, fmt.Errorf("series %s has no upcoming occurrence; pass the event ID of a specific occurrence", event.Id)
+		}
+		event = upcoming[0]
+	}
+
+	previous, err := ct.client.PreviousOccurrence(calendarID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatBriefing(newMeetingBriefing(event, previous)),
+		}},
+	}, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func briefingEvent() *calendar.Event {
+	return &calendar.Event{
+		Id:               "sync_20240610T150000Z",
+		Summary:          "Weekly sync",
+		Description:      "1. Roadmap\n2. Hiring",
+		RecurringEventId: "sync",
+		HangoutLink:      "https://meet.google.com/abc-defg-hij",
+		Start:            &calendar.EventDateTime{DateTime: "2024-06-10T15:00:00Z"},
+		End:              &calendar.EventDateTime{DateTime: "2024-06-10T15:30:00Z"},
+		Organizer:        &calendar.EventOrganizer{Email: "dana@example.com", DisplayName: "Dana"},
+		Attachments: []*calendar.EventAttachment{
+			{Title: "Roadmap", FileUrl: "https://docs.google.com/document/d/roadmap", FileId: "roadmap", MimeType: "application/vnd.google-apps.document"},
+		},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "dana@example.com", ResponseStatus: "accepted", Organizer: true},
+			{Email: "sam@example.com", ResponseStatus: "declined", Comment: "on leave"},
+			{Email: "kim@example.com", Optional: true},
+			{Email: "room-1@resource.calendar.google.com", ResponseStatus: "accepted", Resource: true},
+		},
+	}
+}
+
+// ----- newMeetingBriefing -----
+
+func TestNewMeetingBriefing(t *testing.T) {
+	previous := &calendar.Event{
+		Id:          "sync_20240603T150000Z",
+		Summary:     "Weekly sync",
+		Description: "Notes from last week",
+		Start:       &calendar.EventDateTime{DateTime: "2024-06-03T15:00:00Z"},
+		Attachments: []*calendar.EventAttachment{{Title: "Notes by Gemini", FileUrl: "https://docs.google.com/document/d/notes", FileId: "notes"}},
+		Attendees:   []*calendar.EventAttendee{{Email: "kim@example.com", ResponseStatus: "declined"}},
+	}
+	b := newMeetingBriefing(briefingEvent(), previous)
+
+	if b.MeetLink != "https://meet.google.com/abc-defg-hij" {
+		t.Errorf("MeetLink = %q", b.MeetLink)
+	}
+	if b.Organizer != "Dana <dana@example.com>" {
+		t.Errorf("Organizer = %q", b.Organizer)
+	}
+	if len(b.Documents) != 1 || b.Documents[0].FileID != "roadmap" {
+		t.Errorf("Documents = %+v, want the roadmap doc", b.Documents)
+	}
+	// Resources (rooms) are not attendees to prepare for
+	if len(b.Attendees) != 3 {
+		t.Fatalf("got %d attendees, want 3", len(b.Attendees))
+	}
+	if b.Attendees[2].Response != "needsAction" {
+		t.Errorf("missing response = %q, want needsAction", b.Attendees[2].Response)
+	}
+	want := map[string]int{"accepted": 1, "declined": 1, "needsAction": 1}
+	for status, n := range want {
+		if b.Responses[status] != n {
+			t.Errorf("Responses[%s] = %d, want %d", status, b.Responses[status], n)
+		}
+	}
+	if b.Previous == nil || b.Previous.EventID != "sync_20240603T150000Z" || len(b.Previous.Documents) != 1 {
+		t.Errorf("Previous = %+v, want last week's occurrence with its notes", b.Previous)
+	}
+}
+
+func TestNewMeetingBriefing_NotRecurring(t *testing.T) {
+	b := newMeetingBriefing(&calendar.Event{
+		Id:    "one-off",
+		Start: &calendar.EventDateTime{Date: "2024-06-10"},
+		End:   &calendar.EventDateTime{Date: "2024-06-11"},
+	}, nil)
+	if b.Previous != nil || b.Attendees != nil || b.Responses != nil {
+		t.Errorf("got %+v, want no previous occurrence or attendees", b)
+	}
+	if b.Start != "2024-06-10 (all day)" {
+		t.Errorf("Start = %q", b.Start)
+	}
+}
+
+// ----- formatBriefing -----
+
+func TestFormatBriefing(t *testing.T) {
+	previous := &calendar.Event{
+		Id:        "sync_20240603T150000Z",
+		Summary:   "Weekly sync",
+		Start:     &calendar.EventDateTime{DateTime: "2024-06-03T15:00:00Z"},
+		Attendees: []*calendar.EventAttendee{{Email: "kim@example.com", ResponseStatus: "declined"}},
+	}
+	out := formatBriefing(newMeetingBriefing(briefingEvent(), previous))
+
+	for _, want := range []string{
+		"📋 Briefing for 'Weekly sync'",
+		"• Meet: https://meet.google.com/abc-defg-hij",
+		"1. Roadmap",
+		"  - Roadmap: https://docs.google.com/document/d/roadmap",
+		"(1 accepted, 0 tentative, 1 declined, 1 not responded)",
+		"• dana@example.com — accepted (organizer)",
+		`• sam@example.com — declined: "on leave"`,
+		"• kim@example.com — needsAction (optional)",
+		"🔁 Previous occurrence (2024-06-03T15:00:00Z)",
+		"• Declined: kim@example.com",
+		`"previous_occurrence"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("briefing missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+// maxCandidateSlots bounds how many options check_candidate_slots compares
+const maxCandidateSlots = 10
+
+// Candidate slot statuses of one attendee
+const (
+	candidateFree = "free"
+	candidateBusy = "busy"
+)
+
+// CandidateMatrix is whether each attendee is free for each of a fixed list
+// of candidate slots, such as the options offered in an email thread.
+type CandidateMatrix struct {
+	TimeZone    string            `json:"time_zone"`
+	Attendees   []MatrixAttendee  `json:"attendees"`
+	Slots       []CandidateResult `json:"slots"`
+	Best        []int             `json:"best"` // numbers (from 1) of the slots that suit the most attendees
+	Unavailable []string          `json:"unavailable,omitempty"`
+}
+
+// MatrixAttendee is one row of a CandidateMatrix.
+type MatrixAttendee struct {
+	Email    string `json:"email"`
+	Optional bool   `json:"optional,omitempty"`
+	Self     bool   `json:"self,omitempty"`
+}
+
+// CandidateResult is one candidate slot and who is free for it.
+type CandidateResult struct {
+	Number       int               `json:"number"`
+	Start        string            `json:"start"` // RFC3339 in the matrix time zone
+	End          string            `json:"end"`
+	Status       map[string]string `json:"status"` // attendee -> free or busy
+	Busy         []string          `json:"busy"`   // required attendees
+	OptionalBusy []string          `json:"optional_busy,omitempty"`
+	WorksForAll  bool              `json:"works_for_all"` // every required attendee is free
+	// Violations are the scheduling policy rules a meeting in the slot breaks
+	Violations      []PolicyViolation `json:"policy_violations,omitempty"`
+	BlockedByPolicy bool              `json:"blocked_by_policy,omitempty"` // a rule with block severity is broken
+
+	start, end time.Time
+}
+
+// checkCandidateSlots builds the matrix of attendees against slots. busy maps
+// each attendee to their busy periods; listed gives the order of the rows,
+// and attendees only in busy, such as members of a Google Group, follow in
+// alphabetical order. violations, when set, holds the scheduling policy
+// violations of each slot.
+func checkCandidateSlots(slots []TimeSlot, busy map[string][]TimeSlot, listed []string, optional map[string]bool, self string, loc *time.Location, violations [][]PolicyViolation) CandidateMatrix {
+	matrix := CandidateMatrix{TimeZone: loc.String(), Attendees: []MatrixAttendee{}, Slots: []CandidateResult{}, Best: []int{}}
+
+	var rows []string
+	seen := make(map[string]bool)
+	for _, id := range listed {
+		if _, ok := busy[id]; ok && !seen[id] {
+			rows = append(rows, id)
+			seen[id] = true
+		}
+	}
+	var members []string
+	for id := range busy {
+		if !seen[id] {
+			members = append(members, id)
+		}
+	}
+	sort.Strings(members)
+	rows = append(rows, members...)
+	for _, id := range rows {
+		matrix.Attendees = append(matrix.Attendees, MatrixAttendee{Email: id, Optional: optional[id], Self: id == self})
+	}
+
+	for i, slot := range slots {
+		result := CandidateResult{
+			Number: i + 1,
+			Start:  slot.Start.In(loc).Format(time.RFC3339),
+			End:    slot.End.In(loc).Format(time.RFC3339),
+			Status: make(map[string]string, len(rows)),
+			Busy:   []string{},
+			start:  slot.Start.In(loc),
+			end:    slot.End.In(loc),
+		}
+		for _, id := range rows {
+			if !overlapsAny(busy[id], slot.Start, slot.End) {
+				result.Status[id] = candidateFree
+				continue
+			}
+			result.Status[id] = candidateBusy
+			if optional[id] {
+				result.OptionalBusy = append(result.OptionalBusy, id)
+			} else {
+				result.Busy = append(result.Busy, id)
+			}
+		}
+		result.WorksForAll = len(result.Busy) == 0
+		if i < len(violations) {
+			result.Violations = violations[i]
+			result.BlockedByPolicy = len(blockingViolations(violations[i])) > 0
+		}
+		matrix.Slots = append(matrix.Slots, result)
+	}
+
+	// The best slots are allowed by the scheduling policy if any is, then
+	// have the fewest required attendees busy, then the fewest optional ones
+	rank := func(s CandidateResult) [3]int {
+		blocked := 0
+		if s.BlockedByPolicy {
+			blocked = 1
+		}
+		return [3]int{blocked, len(s.Busy), len(s.OptionalBusy)}
+	}
+	for _, s := range matrix.Slots {
+		if len(matrix.Best) == 0 {
+			matrix.Best = []int{s.Number}
+			continue
+		}
+		best, candidate := rank(matrix.Slots[matrix.Best[0]-1]), rank(s)
+		switch slices.Compare(candidate[:], best[:]) {
+		case 0:
+			matrix.Best = append(matrix.Best, s.Number)
+		case -1:
+			matrix.Best = []int{s.Number}
+		}
+	}
+	return matrix
+}
+
+// formatCandidateMatrix renders the matrix as a table with a row per
+// attendee and a column per slot, then a line per slot and the best options.
+func formatCandidateMatrix(matrix CandidateMatrix, tf TimeFormat) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🗳️ Who is free for each option (%s):\n\n", matrix.TimeZone)
+
+	result.WriteString("| Attendee |")
+	for _, s := range matrix.Slots {
+		fmt.Fprintf(&result, " %d |", s.Number)
+	}
+	result.WriteString("\n|---|")
+	result.WriteString(strings.Repeat("---|", len(matrix.Slots)))
+	result.WriteString("\n")
+	for _, a := range matrix.Attendees {
+		name := attendeeLabel(a.Email, a.Self)
+		if a.Optional {
+			name += " (optional)"
+		}
+		fmt.Fprintf(&result, "| %s |", name)
+		for _, s := range matrix.Slots {
+			if s.Status[a.Email] == candidateFree {
+				result.WriteString(" ✅ |")
+			} else {
+				result.WriteString(" ❌ |")
+			}
+		}
+		result.WriteString("\n")
+	}
+	result.WriteString("\n")
+
+	self := ""
+	for _, a := range matrix.Attendees {
+		if a.Self {
+			self = a.Email
+		}
+	}
+	names := func(ids []string) string {
+		labels := make([]string, len(ids))
+		for i, id := range ids {
+			labels[i] = attendeeLabel(id, id == self)
+		}
+		return strings.Join(labels, ", ")
+	}
+	for _, s := range matrix.Slots {
+		fmt.Fprintf(&result, "%d. %s, %s – %s — ", s.Number, tf.ShortDate(s.start), tf.Clock(s.start), describeEnd(s.start, s.end, tf))
+		switch {
+		case s.WorksForAll && len(s.OptionalBusy) == 0:
+			result.WriteString("works for everyone\n")
+		case s.WorksForAll:
+			fmt.Fprintf(&result, "works for every required attendee; optional busy: %s\n", names(s.OptionalBusy))
+		default:
+			fmt.Fprintf(&result, "%d of %d busy: %s", len(s.Busy)+len(s.OptionalBusy), len(matrix.Attendees), names(s.Busy))
+			if len(s.OptionalBusy) > 0 {
+				fmt.Fprintf(&result, "; optional: %s", names(s.OptionalBusy))
+			}
+			result.WriteString("\n")
+		}
+		for _, v := range s.Violations {
+			fmt.Fprintf(&result, "   ⚠️ [%s] %s: %s\n", v.Severity, v.Rule, v.Message)
+		}
+	}
+
+	if len(matrix.Best) > 0 {
+		best := matrix.Slots[matrix.Best[0]-1]
+		numbers := make([]string, len(matrix.Best))
+		for i, n := range matrix.Best {
+			numbers[i] = fmt.Sprintf("%d", n)
+		}
+		switch {
+		case best.BlockedByPolicy:
+			fmt.Fprintf(&result, "\n👉 Every option breaks a blocking scheduling policy rule; the closest: option %s\n", strings.Join(numbers, ", "))
+		case best.WorksForAll:
+			fmt.Fprintf(&result, "\n👉 Best: option %s\n", strings.Join(numbers, ", "))
+		default:
+			fmt.Fprintf(&result, "\n👉 No option works for every required attendee; the closest: option %s\n", strings.Join(numbers, ", "))
+		}
+	}
+	if len(matrix.Unavailable) > 0 {
+		fmt.Fprintf(&result, "\n⚠️ Free/busy not visible (left out): %s\n", strings.Join(matrix.Unavailable, "; "))
+	}
+	return result.String()
+}
+
+// attendeeLabel names an attendee in the matrix: the user's own calendar is
+// "you".
+func attendeeLabel(id string, self bool) string {
+	switch {
+	case self && id == "primary":
+		return "you"
+	case self:
+		return id + " (you)"
+	}
+	return id
+}
+
+// describeEnd renders the end of a slot: its time, with the date when it
+// ends on a later day.
+func describeEnd(start, end time.Time, tf TimeFormat) string {
+	if start.Format(dateLayout) == end.Format(dateLayout) {
+		return tf.Clock(end)
+	}
+	return tf.ShortDate(end) + ", " + tf.Clock(end)
+}
+
+// parseCandidateSlots reads the slots argument: objects with start_time and
+// end_time in RFC3339.
+func parseCandidateSlots(arguments map[string]interface{}) ([]TimeSlot, error) {
+	values, ok := arguments["slots"].([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, fmt.Errorf("slots must be a non-empty array")
+	}
+	if len(values) > maxCandidateSlots {
+		return nil, fmt.Errorf("at most %d slots can be compared at once", maxCandidateSlots)
+	}
+	slots := make([]TimeSlot, 0, len(values))
+	for i, v := range values {
+		slotMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("slot %d must be an object with start_time and end_time", i+1)
+		}
+		start, err := time.Parse(time.RFC3339, getStringOrDefault(slotMap, "start_time", ""))
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: invalid start_time format: %v", i+1, err)
+		}
+		end, err := time.Parse(time.RFC3339, getStringOrDefault(slotMap, "end_time", ""))
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: invalid end_time format: %v", i+1, err)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("slot %d: end_time must be after start_time", i+1)
+		}
+		slots = append(slots, TimeSlot{Start: start, End: end})
+	}
+	return slots, nil
+}
+
+// candidateViolations checks a meeting in each slot against the scheduling
+// policy of calendarID. It returns nil when there is no policy.
+func (ct *CalendarTools) candidateViolations(calendarID string, slots []TimeSlot, timezone string) ([][]PolicyViolation, error) {
+	if ct.schedulingPolicy.IsEmpty() {
+		return nil, nil
+	}
+	violations := make([][]PolicyViolation, len(slots))
+	for i, slot := range slots {
+		v, err := ct.checkSchedulingPolicy(calendarID, meetingSpan{Summary: fmt.Sprintf("Option %d", i+1), Start: slot.Start, End: slot.End}, timezone)
+		if err != nil {
+			return nil, err
+		}
+		violations[i] = v
+	}
+	return violations, nil
+}
+
+func (ct *CalendarTools) handleCheckCandidateSlots(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	slots, err := parseCandidateSlots(arguments)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := arguments["attendees"].([]interface{})
+	attendees, err := parseAttendees(values, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(attendees) == 0 {
+		return nil, fmt.Errorf("attendees is required")
+	}
+	var calendarIDs []string
+	optional := make(map[string]bool)
+	for _, a := range attendees {
+		calendarIDs = append(calendarIDs, a.Email)
+		if a.Optional {
+			optional[a.Email] = true
+		}
+	}
+	self := ""
+	if getBoolOrDefault(arguments, "include_self", true) {
+		self = ct.calendarID(arguments)
+		calendarIDs = append(calendarIDs, self)
+	}
+	if err := ct.fetchLimits.checkCalendars(calendarIDs); err != nil {
+		return nil, err
+	}
+
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	// One free/busy range covers every slot
+	timeMin, timeMax := slots[0].Start, slots[0].End
+	for _, s := range slots[1:] {
+		if s.Start.Before(timeMin) {
+			timeMin = s.Start
+		}
+		if s.End.After(timeMax) {
+			timeMax = s.End
+		}
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+	busy, unavailable, err := ct.recurringBusy(calendarIDs, optional, timeMin, timeMax, timezone)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := busy[self]; ok && self != "" {
+		protected, err := ct.protectedBusy(self, timeMin, timeMax)
+		if err != nil {
+			return nil, err
+		}
+		busy[self] = append(busy[self], protected...)
+	}
+
+	violations, err := ct.candidateViolations(ct.calendarID(arguments), slots, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := checkCandidateSlots(slots, busy, calendarIDs, optional, self, loc, violations)
+	matrix.Unavailable = unavailable
+	return structuredResult(formatCandidateMatrix(matrix, ct.client.TimeFormat()), matrix), nil
+}
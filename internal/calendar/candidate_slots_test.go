@@ -0,0 +1,141 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ----- checkCandidateSlots -----
+
+func TestCheckCandidateSlots(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2025, 3, 4, hour, minute, 0, 0, time.UTC) }
+	slots := []TimeSlot{
+		{Start: at(9, 0), End: at(9, 30)},
+		{Start: at(11, 0), End: at(11, 30)},
+		{Start: at(15, 0), End: at(15, 30)},
+	}
+	busy := map[string][]TimeSlot{
+		"primary":           {{Start: at(8, 30), End: at(9, 15)}},
+		"ada@example.com":   {{Start: at(15, 0), End: at(16, 0)}},
+		"bob@example.com":   {{Start: at(11, 0), End: at(12, 0)}, {Start: at(15, 0), End: at(15, 30)}},
+		"zoe@example.com":   nil, // a member of a listed Google Group
+		"carol@example.com": {{Start: at(9, 30), End: at(10, 0)}},
+	}
+	listed := []string{"ada@example.com", "bob@example.com", "carol@example.com", "team@example.com", "primary"}
+	optional := map[string]bool{"bob@example.com": true}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matrix := checkCandidateSlots(slots, busy, listed, optional, "primary", berlin, nil)
+
+	var rows []string
+	for _, a := range matrix.Attendees {
+		rows = append(rows, a.Email)
+	}
+	if got := strings.Join(rows, ","); got != "ada@example.com,bob@example.com,carol@example.com,primary,zoe@example.com" {
+		t.Errorf("rows = %s, want the listed attendees in order, then group members", got)
+	}
+	if !matrix.Attendees[1].Optional || !matrix.Attendees[3].Self {
+		t.Errorf("attendees = %+v, want bob optional and primary as self", matrix.Attendees)
+	}
+
+	first, second, third := matrix.Slots[0], matrix.Slots[1], matrix.Slots[2]
+	if first.WorksForAll || strings.Join(first.Busy, ",") != "primary" || first.Status["carol@example.com"] != candidateFree {
+		t.Errorf("slot 1 = %+v, want only primary busy (carol's meeting starts as it ends)", first)
+	}
+	if !second.WorksForAll || strings.Join(second.OptionalBusy, ",") != "bob@example.com" {
+		t.Errorf("slot 2 = %+v, want it to work with only optional bob busy", second)
+	}
+	if third.WorksForAll || strings.Join(third.Busy, ",") != "ada@example.com" || third.Status["bob@example.com"] != candidateBusy {
+		t.Errorf("slot 3 = %+v, want ada busy and optional bob busy", third)
+	}
+	if first.Start != "2025-03-04T10:00:00+01:00" {
+		t.Errorf("slot 1 start = %s, want it in Berlin time", first.Start)
+	}
+	if len(matrix.Best) != 1 || matrix.Best[0] != 2 {
+		t.Errorf("best = %v, want [2]", matrix.Best)
+	}
+
+	tf := TimeFormat{Locale: "en", Clock24: true}
+	text := formatCandidateMatrix(matrix, tf)
+	for _, want := range []string{
+		"| Attendee | 1 | 2 | 3 |",
+		"| bob@example.com (optional) | ✅ | ❌ | ❌ |",
+		"| you | ❌ | ✅ | ✅ |",
+		"1. Tue, Mar 4, 10:00 – 10:30 — 1 of 5 busy: you",
+		"2. Tue, Mar 4, 12:00 – 12:30 — works for every required attendee; optional busy: bob@example.com",
+		"3. Tue, Mar 4, 16:00 – 16:30 — 2 of 5 busy: ada@example.com; optional: bob@example.com",
+		"👉 Best: option 2",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("matrix should contain %q:\n%s", want, text)
+		}
+	}
+
+	// Without an option that suits every required attendee, the closest are offered
+	busy["zoe@example.com"] = []TimeSlot{{Start: at(11, 0), End: at(11, 30)}}
+	matrix = checkCandidateSlots(slots, busy, listed, optional, "primary", berlin, nil)
+	if fmt.Sprint(matrix.Best) != "[1]" {
+		t.Errorf("best = %v, want [1], where nobody optional is busy either", matrix.Best)
+	}
+	if text := formatCandidateMatrix(matrix, tf); !strings.Contains(text, "No option works for every required attendee; the closest: option 1") {
+		t.Errorf("the closest options should be offered:\n%s", text)
+	}
+}
+
+func TestCheckCandidateSlots_SchedulingPolicy(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 3, 4, hour, 0, 0, 0, time.UTC) }
+	slots := []TimeSlot{
+		{Start: at(17), End: at(18)},
+		{Start: at(10), End: at(11)},
+	}
+	busy := map[string][]TimeSlot{
+		"primary":         nil,
+		"ada@example.com": {{Start: at(10), End: at(11)}},
+	}
+	late := PolicyViolation{Rule: "evenings free", Severity: severityBlock, Message: "'Option 1' ends at 18:00, after 17:00"}
+	violations := [][]PolicyViolation{{late}, nil}
+
+	// A slot ruled out by the policy loses to one with an attendee busy
+	matrix := checkCandidateSlots(slots, busy, []string{"ada@example.com", "primary"}, nil, "primary", time.UTC, violations)
+	if first := matrix.Slots[0]; !first.WorksForAll || !first.BlockedByPolicy || len(first.Violations) != 1 {
+		t.Errorf("slot 1 = %+v, want it free for everyone but blocked by the policy", first)
+	}
+	if fmt.Sprint(matrix.Best) != "[2]" {
+		t.Errorf("best = %v, want [2]", matrix.Best)
+	}
+	text := formatCandidateMatrix(matrix, TimeFormat{Locale: "en", Clock24: true})
+	for _, want := range []string{
+		"⚠️ [block] evenings free: 'Option 1' ends at 18:00, after 17:00",
+		"No option works for every required attendee; the closest: option 2",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("matrix should contain %q:\n%s", want, text)
+		}
+	}
+
+	// When every slot is ruled out, the matrix says so
+	violations[1] = []PolicyViolation{late}
+	matrix = checkCandidateSlots(slots, busy, []string{"ada@example.com", "primary"}, nil, "primary", time.UTC, violations)
+	if text := formatCandidateMatrix(matrix, TimeFormat{Locale: "en", Clock24: true}); !strings.Contains(text, "Every option breaks a blocking scheduling policy rule; the closest: option 1") {
+		t.Errorf("the matrix should name the policy:\n%s", text)
+	}
+}
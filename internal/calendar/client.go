@@ -22,6 +22,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/api/calendar/v3"
@@ -33,6 +34,28 @@ type Client struct {
 	service         *calendar.Service
 	driveService    *drive.Service
 	cachedUserEmail string // cached to avoid repeated API calls
+	policy          CalendarPolicy
+
+	// cacheMu guards the values below that are filled on first use:
+	// cachedUserEmail and accessRoles, which calendars listed in parallel
+	// fill at the same time, and the display settings and journal calendar,
+	// which background loops such as the warm cache and agenda watch read
+	// alongside tool calls
+	cacheMu sync.Mutex
+
+	attendeeTimezones map[string]string // configured, keyed by lowercased email
+	inferredTimezones map[string]string // inferred from past events; "" caches a miss
+	accessRoles       map[string]string // calendar accessRole by calendar ID
+
+	displaySettings DisplaySettings // configured locale and clock
+	timeFormat      *TimeFormat     // resolved on first use
+	weekStart       *time.Weekday   // resolved on first use
+
+	journalCalendarID string // log_note's calendar, found or created on first use
+
+	warmCache *WarmCache // prefetched events; nil when disabled
+
+	scopeCheck func(scopes ...string) error // asks for scopes not granted yet; nil grants everything
 }
 
 // NewClient creates a new Calendar API client with the given Google Calendar and Drive services.
@@ -43,6 +66,21 @@ func NewClient(service *calendar.Service, driveService *drive.Service) *Client {
 	}
 }
 
+// SetScopeCheck installs the check run before calls that need OAuth scopes
+// beyond Calendar access, such as reading documents from Drive. It returns an
+// error, typically asking the user to grant the scopes, when they are missing.
+func (c *Client) SetScopeCheck(check func(scopes ...string) error) {
+	c.scopeCheck = check
+}
+
+// requireScopes runs the scope check, if any.
+func (c *Client) requireScopes(scopes ...string) error {
+	if c.scopeCheck == nil {
+		return nil
+	}
+	return c.scopeCheck(scopes...)
+}
+
 type EventParams struct {
 	CalendarID             string                   `json:"calendar_id"`
 	Summary                string                   `json:"summary"`
@@ -52,19 +90,26 @@ type EventParams struct {
 	EndTime                time.Time                `json:"end_time"`
 	TimeZone               string                   `json:"timezone,omitempty"`
 	AllDay                 bool                     `json:"all_day,omitempty"`
-	Attendees              []string                 `json:"attendees,omitempty"`
+	Attendees              []AttendeeParams         `json:"attendees,omitempty"`
 	Recurrence             []string                 `json:"recurrence,omitempty"`
 	Visibility             string                   `json:"visibility,omitempty"`
-	SendNotifications      bool                     `json:"send_notifications,omitempty"`
+	SendUpdates            string                   `json:"send_updates,omitempty"` // "all", "externalOnly" or "none"; "" leaves it to the API
 	GuestCanModify         bool                     `json:"guest_can_modify,omitempty"`
 	GuestCanInviteOthers   bool                     `json:"guest_can_invite_others,omitempty"`
 	GuestCanSeeOtherGuests bool                     `json:"guest_can_see_other_guests,omitempty"`
+	AnyoneCanAddSelf       bool                     `json:"anyone_can_add_self,omitempty"`
 	ConferenceData         *ConferenceDataParams    `json:"conference_data,omitempty"`
 	Reminders              *RemindersParams         `json:"reminders,omitempty"`
 	ColorID                string                   `json:"color_id,omitempty"`
 	EventType              string                   `json:"event_type,omitempty"`
 	WorkingLocation        *WorkingLocationParams   `json:"working_location,omitempty"`
 	FocusTimeProperties    *FocusTimeProperties     `json:"focus_time_properties,omitempty"`
+	FollowupOf             string                   `json:"followup_of,omitempty"` // ID of the event this one follows up on
+	Priority               string                   `json:"priority,omitempty"`    // "high", "normal" or "low"
+	Proposed               bool                     `json:"proposed,omitempty"`    // tentative and pending approval (see approve_event)
+	Attachments            []AttachmentParams       `json:"attachments,omitempty"`
+	Source                 *EventSourceParams       `json:"source,omitempty"` // link to the ticket, PR or doc the event came from
+	MeetSettings           *MeetSettings            `json:"meet_settings,omitempty"`
 }
 
 // WorkingLocationParams represents working location information for events
@@ -93,28 +138,51 @@ type PatchEventParams struct {
 	Attendees              []AttendeeParams      `json:"attendees,omitempty"`
 	Recurrence             []string              `json:"recurrence,omitempty"`
 	Visibility             *string               `json:"visibility,omitempty"`
-	SendNotifications      bool                  `json:"send_notifications,omitempty"`
+	SendUpdates            string                `json:"send_updates,omitempty"` // "all", "externalOnly" or "none"; "" leaves it to the API
 	GuestCanModify         *bool                 `json:"guest_can_modify,omitempty"`
 	GuestCanInviteOthers   *bool                 `json:"guest_can_invite_others,omitempty"`
 	GuestCanSeeOtherGuests *bool                 `json:"guest_can_see_other_guests,omitempty"`
+	AnyoneCanAddSelf       *bool                 `json:"anyone_can_add_self,omitempty"`
 	ConferenceData         *ConferenceDataParams `json:"conference_data,omitempty"`
 	Reminders              *RemindersParams         `json:"reminders,omitempty"`
 	ColorID                *string                  `json:"color_id,omitempty"`
 	EventType              *string                  `json:"event_type,omitempty"`
 	WorkingLocation        *WorkingLocationParams   `json:"working_location,omitempty"`
+	FollowupOf             *string                  `json:"followup_of,omitempty"` // "" removes the link
+	Priority               *string                  `json:"priority,omitempty"`    // "" removes the priority
+	Attachments            []AttachmentParams       `json:"attachments,omitempty"` // replaces all attachments; nil leaves them
+	Source                 *EventSourceParams       `json:"source,omitempty"`      // an empty URL removes the source
+	AttendeeUpdates        []AttendeeUpdate         `json:"attendee_updates,omitempty"` // changes only the named attendees
+	MeetSettings           *MeetSettings            `json:"meet_settings,omitempty"`    // empty settings remove them
+
+	// ETag, when set, makes the patch apply only if the event still has this
+	// etag, so changes made elsewhere since the event was read (e.g. in the
+	// Calendar UI) are never silently overwritten; otherwise PatchEventDirect
+	// returns a *ConflictError
+	ETag string `json:"-"`
 
 	// Track which fields have been explicitly provided
 	HasAttendees  bool `json:"-"`
 	HasRecurrence bool `json:"-"`
+
+	// RemoveConference clears the event's conference data, including its
+	// Meet link; it takes precedence over ConferenceData
+	RemoveConference bool `json:"-"`
 }
 
 type AttendeeParams struct {
 	Email          string `json:"email"`
+	DisplayName    string `json:"display_name,omitempty"`
+	Optional       bool   `json:"optional,omitempty"`
 	ResponseStatus string `json:"response_status,omitempty"`
+	// Comment is the attendee's note to the organizer with their RSVP; nil
+	// leaves the existing comment unchanged, "" clears it
+	Comment *string `json:"comment,omitempty"`
 }
 
 type ConferenceDataParams struct {
 	CreateRequest *CreateConferenceRequest `json:"create_request,omitempty"`
+	Notes         string                   `json:"notes,omitempty"` // shown with the conference in Google Calendar
 }
 
 type CreateConferenceRequest struct {
@@ -153,7 +221,10 @@ type FreeBusyParams struct {
 
 type ListEventsParams struct {
 	CalendarID      string    `json:"calendar_id"`
-	TimeFilter      string    `json:"time_filter"` // "today", "this_week", "next_week", "custom"
+	TimeFilter      string    `json:"time_filter"` // "today", "tomorrow", "this_week", "next_week", "this_weekend", "this_month", "next_month", "next_n_days", "past_n_days", "custom"
+	Days            int       `json:"days,omitempty"` // Days covered by "next_n_days" and "past_n_days"
+	WeekMode        string    `json:"week_mode,omitempty"` // "workweek" (Monday-Friday, the default) or "full" (all seven days) for "this_week" and "next_week"
+	WeekStart       time.Weekday `json:"week_start,omitempty"` // First day of the week in the user's settings (Sunday when unset)
 	TimeMin         time.Time `json:"time_min,omitempty"`
 	TimeMax         time.Time `json:"time_max,omitempty"`
 	TimeZone        string    `json:"timezone,omitempty"`
@@ -164,6 +235,13 @@ type ListEventsParams struct {
 	ShowDeclined    bool      `json:"show_declined,omitempty"`    // Include declined events in overlap detection
 	DetectOverlaps  bool      `json:"detect_overlaps,omitempty"`  // Enable overlap detection
 	Query           string    `json:"query,omitempty"`            // Free-text search query
+	AnnotateColors  bool      `json:"annotate_colors,omitempty"`  // Add the color legend category of each event
+	Organizer       string    `json:"organizer,omitempty"`        // Only events organized by this person (applied after listing)
+	MaxAttendees    int64     `json:"max_attendees,omitempty"`    // Truncate attendee lists to this many (0 = no limit)
+	FullAttendees   bool      `json:"full_attendees,omitempty"`   // Re-read events whose attendee lists were truncated
+	ExcludePast     bool      `json:"exclude_past,omitempty"`     // With "today", leave out events that have already ended
+	AttendeeLimit   int       `json:"attendee_limit,omitempty"`   // Attendees shown per event in text output (0 = all)
+	SplitMultiDay   bool      `json:"split_multi_day,omitempty"`  // Show multi-day events under each day they cover in text output
 }
 
 // EventWithOverlap wraps a calendar.Event with overlap detection information
@@ -177,6 +255,15 @@ func (c *Client) CreateEvent(params EventParams) (*calendar.Event, error) {
 	if params.CalendarID == "" {
 		params.CalendarID = "primary"
 	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, err
+	}
+
+	if len(params.Recurrence) > 0 {
+		if err := checkRecurringTimeZone(params.TimeZone); err != nil {
+			return nil, err
+		}
+	}
 
 	event := &calendar.Event{
 		Summary:     params.Summary,
@@ -184,33 +271,45 @@ func (c *Client) CreateEvent(params EventParams) (*calendar.Event, error) {
 		Location:    params.Location,
 	}
 
-	// Set start and end times
+	// Set start and end times; fixed offsets are sent as the API's names for them
+	timeZone := apiTimeZone(params.TimeZone)
 	if params.AllDay {
+		// Callers give the last day; the API wants the day after it
+		if params.EndTime.IsZero() {
+			params.EndTime = params.StartTime
+		}
+		endDate, err := allDayEndDate(&params.StartTime, params.EndTime)
+		if err != nil {
+			return nil, err
+		}
 		event.Start = &calendar.EventDateTime{
-			Date:     params.StartTime.Format("2006-01-02"),
-			TimeZone: params.TimeZone,
+			Date:     allDayStartDate(params.StartTime),
+			TimeZone: timeZone,
 		}
 		event.End = &calendar.EventDateTime{
-			Date:     params.EndTime.Format("2006-01-02"),
-			TimeZone: params.TimeZone,
+			Date:     endDate,
+			TimeZone: timeZone,
 		}
 	} else {
 		event.Start = &calendar.EventDateTime{
 			DateTime: params.StartTime.Format(time.RFC3339),
-			TimeZone: params.TimeZone,
+			TimeZone: timeZone,
 		}
 		event.End = &calendar.EventDateTime{
 			DateTime: params.EndTime.Format(time.RFC3339),
-			TimeZone: params.TimeZone,
+			TimeZone: timeZone,
 		}
 	}
 
 	// Add attendees
 	if len(params.Attendees) > 0 {
 		attendees := make([]*calendar.EventAttendee, len(params.Attendees))
-		for i, email := range params.Attendees {
+		for i, attendee := range params.Attendees {
 			attendees[i] = &calendar.EventAttendee{
-				Email: email,
+				Email:          attendee.Email,
+				DisplayName:    attendee.DisplayName,
+				Optional:       attendee.Optional,
+				ResponseStatus: attendee.ResponseStatus,
 			}
 		}
 		event.Attendees = attendees
@@ -235,10 +334,11 @@ func (c *Client) CreateEvent(params EventParams) (*calendar.Event, error) {
 	event.GuestsCanModify = params.GuestCanModify
 	event.GuestsCanInviteOthers = &params.GuestCanInviteOthers
 	event.GuestsCanSeeOtherGuests = &params.GuestCanSeeOtherGuests
+	event.AnyoneCanAddSelf = params.AnyoneCanAddSelf
 
 	// Set conference data
 	if params.ConferenceData != nil {
-		event.ConferenceData = &calendar.ConferenceData{}
+		event.ConferenceData = &calendar.ConferenceData{Notes: params.ConferenceData.Notes}
 		if params.ConferenceData.CreateRequest != nil {
 			event.ConferenceData.CreateRequest = &calendar.CreateConferenceRequest{
 				RequestId: params.ConferenceData.CreateRequest.RequestID,
@@ -295,6 +395,34 @@ func (c *Client) CreateEvent(params EventParams) (*calendar.Event, error) {
 		}
 	}
 
+	// Link the event to the one it follows up on (see list_linked_events)
+	if params.FollowupOf != "" {
+		if event.ExtendedProperties == nil {
+			event.ExtendedProperties = &calendar.EventExtendedProperties{Private: make(map[string]string)}
+		}
+		event.ExtendedProperties.Private[followupOfKey] = params.FollowupOf
+	}
+	if params.Priority != "" {
+		if event.ExtendedProperties == nil {
+			event.ExtendedProperties = &calendar.EventExtendedProperties{Private: make(map[string]string)}
+		}
+		event.ExtendedProperties.Private[priorityKey] = params.Priority
+	}
+	if params.MeetSettings != nil {
+		if event.ExtendedProperties == nil {
+			event.ExtendedProperties = &calendar.EventExtendedProperties{Private: make(map[string]string)}
+		}
+		event.ExtendedProperties.Private[meetSettingsKey] = encodeMeetSettings(*params.MeetSettings)
+	}
+	// Proposed events stay tentative until approve_event confirms them
+	if params.Proposed {
+		if event.ExtendedProperties == nil {
+			event.ExtendedProperties = &calendar.EventExtendedProperties{}
+		}
+		event.ExtendedProperties.Shared = map[string]string{approvalKey: approvalPending}
+		event.Status = "tentative"
+	}
+
 	// Set working location properties for Google Calendar API
 	if params.EventType == "workingLocation" && params.WorkingLocation != nil {
 		// Working location events MUST have transparency set to "transparent"
@@ -329,13 +457,23 @@ func (c *Client) CreateEvent(params EventParams) (*calendar.Event, error) {
 		}
 	}
 
+	if len(params.Attachments) > 0 {
+		event.Attachments = eventAttachments(params.Attachments)
+	}
+	if params.Source != nil {
+		event.Source = eventSource(params.Source)
+	}
+
 	call := c.service.Events.Insert(params.CalendarID, event)
-	if params.SendNotifications {
-		call = call.SendNotifications(true)
+	if params.SendUpdates != "" {
+		call = call.SendUpdates(params.SendUpdates)
 	}
 	if params.ConferenceData != nil {
 		call = call.ConferenceDataVersion(1)
 	}
+	if len(params.Attachments) > 0 {
+		call = call.SupportsAttachments(true)
+	}
 
 	return call.Do()
 }
@@ -345,7 +483,7 @@ func (c *Client) PatchEvent(eventID string, params EventParams) (*calendar.Event
 	// Convert EventParams to PatchEventParams for backward compatibility
 	patchParams := PatchEventParams{
 		CalendarID:        params.CalendarID,
-		SendNotifications: params.SendNotifications,
+		SendUpdates:       params.SendUpdates,
 	}
 
 	// Only set fields that are non-zero/non-empty (backward compatibility behavior)
@@ -374,12 +512,11 @@ func (c *Client) PatchEvent(eventID string, params EventParams) (*calendar.Event
 		patchParams.ColorID = &params.ColorID
 	}
 	if len(params.Attendees) > 0 {
-		// Convert []string to []AttendeeParams for backward compatibility
 		attendeeParams := make([]AttendeeParams, len(params.Attendees))
-		for i, email := range params.Attendees {
-			attendeeParams[i] = AttendeeParams{
-				Email:          email,
-				ResponseStatus: "needsAction", // Default for new attendees
+		for i, attendee := range params.Attendees {
+			attendeeParams[i] = attendee
+			if attendeeParams[i].ResponseStatus == "" {
+				attendeeParams[i].ResponseStatus = "needsAction" // Default for new attendees
 			}
 		}
 		patchParams.Attendees = attendeeParams
@@ -409,6 +546,15 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 	if params.CalendarID == "" {
 		params.CalendarID = "primary"
 	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, err
+	}
+
+	if params.HasRecurrence && len(params.Recurrence) > 0 && params.TimeZone != nil {
+		if err := checkRecurringTimeZone(*params.TimeZone); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create a patch event with only the fields that are explicitly provided
 	patchEvent := &calendar.Event{}
@@ -429,12 +575,12 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 		allDay := params.AllDay != nil && *params.AllDay
 		timezone := ""
 		if params.TimeZone != nil {
-			timezone = *params.TimeZone
+			timezone = apiTimeZone(*params.TimeZone)
 		}
 
 		if allDay {
 			patchEvent.Start = &calendar.EventDateTime{
-				Date:     params.StartTime.Format("2006-01-02"),
+				Date:     allDayStartDate(*params.StartTime),
 				TimeZone: timezone,
 			}
 		} else {
@@ -449,12 +595,16 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 		allDay := params.AllDay != nil && *params.AllDay
 		timezone := ""
 		if params.TimeZone != nil {
-			timezone = *params.TimeZone
+			timezone = apiTimeZone(*params.TimeZone)
 		}
 
 		if allDay {
+			endDate, err := allDayEndDate(params.StartTime, *params.EndTime)
+			if err != nil {
+				return nil, err
+			}
 			patchEvent.End = &calendar.EventDateTime{
-				Date:     params.EndTime.Format("2006-01-02"),
+				Date:     endDate,
 				TimeZone: timezone,
 			}
 		} else {
@@ -475,10 +625,36 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 			}
 			attendees[i] = &calendar.EventAttendee{
 				Email:          attendee.Email,
+				DisplayName:    attendee.DisplayName,
+				Optional:       attendee.Optional,
 				ResponseStatus: responseStatus,
 			}
+			if attendee.Comment != nil {
+				attendees[i].Comment = *attendee.Comment
+			}
+		}
+		patchEvent.Attendees = attendees
+	}
+
+	// Targeted attendee updates: fetch the current list, change the matching
+	// entries and write the whole list back. The write is conditioned on the
+	// version read, so an RSVP arriving in between is not overwritten
+	if len(params.AttendeeUpdates) > 0 {
+		if params.HasAttendees {
+			return nil, fmt.Errorf("attendee updates cannot be combined with a new attendee list")
+		}
+		current, err := c.service.Events.Get(params.CalendarID, eventID).Fields("etag", "attendees").Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current attendees: %v", err)
+		}
+		attendees, err := mergeAttendeeUpdates(current.Attendees, params.AttendeeUpdates)
+		if err != nil {
+			return nil, err
 		}
 		patchEvent.Attendees = attendees
+		if params.ETag == "" {
+			params.ETag = current.Etag
+		}
 	}
 
 	// Update recurrence if provided (replace entire recurrence list, even if empty)
@@ -504,10 +680,17 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 	if params.GuestCanSeeOtherGuests != nil {
 		patchEvent.GuestsCanSeeOtherGuests = params.GuestCanSeeOtherGuests
 	}
+	if params.AnyoneCanAddSelf != nil {
+		patchEvent.AnyoneCanAddSelf = *params.AnyoneCanAddSelf
+		// false is the zero value, which is otherwise left out of the patch
+		patchEvent.ForceSendFields = append(patchEvent.ForceSendFields, "AnyoneCanAddSelf")
+	}
 
-	// Handle conference data
-	if params.ConferenceData != nil {
-		patchEvent.ConferenceData = &calendar.ConferenceData{}
+	// Handle conference data; removal is sent as an explicit null
+	if params.RemoveConference {
+		patchEvent.NullFields = append(patchEvent.NullFields, "ConferenceData")
+	} else if params.ConferenceData != nil {
+		patchEvent.ConferenceData = &calendar.ConferenceData{Notes: params.ConferenceData.Notes}
 		if params.ConferenceData.CreateRequest != nil {
 			patchEvent.ConferenceData.CreateRequest = &calendar.CreateConferenceRequest{
 				RequestId: params.ConferenceData.CreateRequest.RequestID,
@@ -553,6 +736,26 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 		}
 	}
 
+	// Extended properties are merged by key, so only the link is changed
+	if params.FollowupOf != nil {
+		if patchEvent.ExtendedProperties == nil {
+			patchEvent.ExtendedProperties = &calendar.EventExtendedProperties{Private: make(map[string]string)}
+		}
+		patchEvent.ExtendedProperties.Private[followupOfKey] = *params.FollowupOf
+	}
+	if params.Priority != nil {
+		if patchEvent.ExtendedProperties == nil {
+			patchEvent.ExtendedProperties = &calendar.EventExtendedProperties{Private: make(map[string]string)}
+		}
+		patchEvent.ExtendedProperties.Private[priorityKey] = *params.Priority
+	}
+	if params.MeetSettings != nil {
+		if patchEvent.ExtendedProperties == nil {
+			patchEvent.ExtendedProperties = &calendar.EventExtendedProperties{Private: make(map[string]string)}
+		}
+		patchEvent.ExtendedProperties.Private[meetSettingsKey] = encodeMeetSettings(*params.MeetSettings)
+	}
+
 	// Handle working location properties for Google Calendar API
 	if params.EventType != nil && *params.EventType == "workingLocation" && params.WorkingLocation != nil {
 		// Working location events MUST have transparency set to "transparent"
@@ -578,27 +781,67 @@ func (c *Client) PatchEventDirect(eventID string, params PatchEventParams) (*cal
 		}
 	}
 
+	if params.Attachments != nil {
+		patchEvent.Attachments = eventAttachments(params.Attachments)
+	}
+	if params.Source != nil {
+		if params.Source.URL == "" {
+			patchEvent.NullFields = append(patchEvent.NullFields, "Source")
+		} else {
+			patchEvent.Source = eventSource(params.Source)
+		}
+	}
+
 	// Use Patch instead of Update
 	call := c.service.Events.Patch(params.CalendarID, eventID, patchEvent)
-	if params.SendNotifications {
-		call = call.SendNotifications(true)
+	if params.SendUpdates != "" {
+		call = call.SendUpdates(params.SendUpdates)
+	}
+	if params.Attachments != nil {
+		call = call.SupportsAttachments(true)
+	}
+	// The API ignores conference data changes without conferenceDataVersion=1
+	if params.ConferenceData != nil || params.RemoveConference {
+		call = call.ConferenceDataVersion(1)
+	}
+	if params.ETag != "" {
+		call.Header().Set("If-Match", params.ETag)
 	}
 
-	return call.Do()
+	event, err := call.Do()
+	if err != nil {
+		return nil, c.conflictError(params.CalendarID, eventID, params.ETag, err)
+	}
+	return event, nil
 }
 
 // DeleteEvent removes a calendar event by its ID.
-func (c *Client) DeleteEvent(calendarID, eventID string, sendNotifications bool) error {
+func (c *Client) DeleteEvent(calendarID, eventID, sendUpdates string) error {
+	return c.DeleteEventIfMatch(calendarID, eventID, "", sendUpdates)
+}
+
+// DeleteEventIfMatch removes a calendar event only if it still has etag,
+// returning a *ConflictError otherwise. An empty etag deletes unconditionally.
+func (c *Client) DeleteEventIfMatch(calendarID, eventID, etag, sendUpdates string) error {
 	if calendarID == "" {
 		calendarID = "primary"
 	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return err
+	}
 
 	call := c.service.Events.Delete(calendarID, eventID)
-	if sendNotifications {
-		call = call.SendNotifications(true)
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	if etag != "" {
+		call.Header().Set("If-Match", etag)
 	}
 
-	return call.Do()
+	if err := call.Do(); err != nil {
+		return c.conflictError(calendarID, eventID, etag, err)
+	}
+	return nil
 }
 
 // GetEvent retrieves a specific calendar event by its ID.
@@ -606,6 +849,9 @@ func (c *Client) GetEvent(calendarID, eventID string) (*calendar.Event, error) {
 	if calendarID == "" {
 		calendarID = "primary"
 	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
 
 	// Get event with complete attendee information including response status and color
 	getCall := c.service.Events.Get(calendarID, eventID).
@@ -615,7 +861,7 @@ func (c *Client) GetEvent(calendarID, eventID string) (*calendar.Event, error) {
 
 // eventDetailFields is the shared field selector used by GetEvent and GetRecurringOccurrences
 // to return a consistent, complete event detail set.
-const eventDetailFields = "id,summary,description,location,start,end,attendees(email,displayName,responseStatus),conferenceData,creator,organizer,colorId,attachments,recurringEventId,status"
+const eventDetailFields = "id,etag,updated,htmlLink,summary,description,location,start,end,attendees(email,displayName,responseStatus,comment,optional,resource,self),conferenceData,hangoutLink,creator,organizer,guestsCanModify,privateCopy,colorId,attachments,originalStartTime,recurrence,recurringEventId,reminders,status,transparency,visibility,extendedProperties"
 
 // GetRecurringOccurrencesParams holds parameters for listing instances of a recurring event.
 type GetRecurringOccurrencesParams struct {
@@ -640,6 +886,9 @@ func (c *Client) GetRecurringOccurrences(params GetRecurringOccurrencesParams) (
 	if params.CalendarID == "" {
 		params.CalendarID = "primary"
 	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, nil, err
+	}
 	if params.PastCount == 0 {
 		params.PastCount = 5
 	}
@@ -723,23 +972,24 @@ func (c *Client) GetFreeBusy(params FreeBusyParams) (*calendar.FreeBusyResponse,
 		params.CalendarIDs = []string{"primary"}
 	}
 
-	items := make([]*calendar.FreeBusyRequestItem, len(params.CalendarIDs))
-	for i, calID := range params.CalendarIDs {
-		items[i] = &calendar.FreeBusyRequestItem{
-			Id: calID,
+	for _, calID := range params.CalendarIDs {
+		// Free/busy only exposes busy blocks for attendees, so only the deny list applies
+		if err := c.checkDenied(calID); err != nil {
+			return nil, err
 		}
 	}
 
-	request := &calendar.FreeBusyRequest{
+	// Large attendee lists are split into chunks the API accepts and
+	// queried concurrently
+	request := calendar.FreeBusyRequest{
 		TimeMin:              params.TimeMin.Format(time.RFC3339),
 		TimeMax:              params.TimeMax.Format(time.RFC3339),
-		TimeZone:             params.TimeZone,
-		Items:                items,
+		TimeZone:             apiTimeZone(params.TimeZone),
 		GroupExpansionMax:    int64(params.GroupExpansionMax),
-		CalendarExpansionMax: int64(params.CalendarExpansionMax),
+		CalendarExpansionMax: int64(calendarExpansionLimit(params.CalendarExpansionMax)),
 	}
 
-	return c.service.Freebusy.Query(request).Do()
+	return c.queryFreeBusy(request, params.CalendarIDs)
 }
 
 // ListEvents retrieves calendar events based on the provided filter parameters.
@@ -747,13 +997,66 @@ func (c *Client) ListEvents(params ListEventsParams) (*calendar.Events, error) {
 	if params.CalendarID == "" {
 		params.CalendarID = "primary"
 	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, err
+	}
+
+	// Someone else's calendar shared free/busy only lists events without
+	// details, so say so rather than return blank events
+	if role, err := c.calendarAccessRole(params.CalendarID); err == nil && role == "freeBusyReader" {
+		return nil, freeBusyOnlyError(params.CalendarID)
+	}
 
 	if params.TimeZone == "" {
 		params.TimeZone = "UTC"
 	}
 
 	// Calculate time range based on filter
-	timeMin, timeMax := calculateTimeRange(params.TimeFilter, params.TimeMin, params.TimeMax, params.TimeZone)
+	timeMin, timeMax := listWindow(params, time.Now())
+
+	events, err := c.fetchEvents(params, timeMin, timeMax)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter out declined events if ShowDeclined is false
+	if !params.ShowDeclined && events.Items != nil {
+		filteredItems := make([]*calendar.Event, 0, len(events.Items))
+		for _, event := range events.Items {
+			if !selfDeclined(event) {
+				filteredItems = append(filteredItems, event)
+			}
+		}
+		events.Items = filteredItems
+	}
+
+	// The API has no organizer filter, so it is applied to the listed events
+	if params.Organizer != "" {
+		events.Items = filterByOrganizer(events.Items, params.Organizer)
+	}
+
+	if params.FullAttendees {
+		c.fillOmittedAttendees(params.CalendarID, events.Items)
+	}
+
+	return events, nil
+}
+
+// fetchEvents lists the events of params.CalendarID in [timeMin, timeMax),
+// from the warm cache when it holds them.
+func (c *Client) fetchEvents(params ListEventsParams, timeMin, timeMax time.Time) (*calendar.Events, error) {
+	if params.cacheable() {
+		if items, ok := c.warmCache.lookup(c, params.CalendarID, timeMin, timeMax); ok {
+			limit := params.MaxResults
+			if limit <= 0 {
+				limit = 250
+			}
+			if int64(len(items)) > limit {
+				items = items[:limit]
+			}
+			return &calendar.Events{Items: items}, nil
+		}
+	}
 
 	call := c.service.Events.List(params.CalendarID)
 
@@ -787,76 +1090,107 @@ func (c *Client) ListEvents(params ListEventsParams) (*calendar.Events, error) {
 		call = call.Q(params.Query)
 	}
 
-	events, err := call.Do()
-	if err != nil {
-		return nil, err
+	if params.MaxAttendees > 0 {
+		call = call.MaxAttendees(params.MaxAttendees)
 	}
 
-	// Filter out declined events if ShowDeclined is false
-	if !params.ShowDeclined && events.Items != nil {
-		filteredItems := make([]*calendar.Event, 0, len(events.Items))
-		for _, event := range events.Items {
-			if !c.isEventDeclined(event) {
-				filteredItems = append(filteredItems, event)
-			}
+	events, err := call.Do()
+	if err != nil {
+		if isNotFound(err) && params.CalendarID != "primary" {
+			return nil, notSharedError(params.CalendarID, err)
 		}
-		events.Items = filteredItems
+		return nil, err
 	}
-
 	return events, nil
 }
 
-// calculateTimeRange computes the start and end times for a given time filter and timezone.
-func calculateTimeRange(timeFilter string, customMin, customMax time.Time, timezone string) (time.Time, time.Time) {
-	loc, err := time.LoadLocation(timezone)
-	if err != nil {
-		loc = time.UTC
-	}
+// calculateTimeRange computes the start and end times of the time filter in
+// params, in its time zone.
+func calculateTimeRange(params ListEventsParams) (time.Time, time.Time) {
+	loc := locationOrUTC(params.TimeZone)
 
 	now := time.Now().In(loc)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 
-	switch timeFilter {
+	// First day of the current week, and its Monday
+	weekBegin := startOfDay.AddDate(0, 0, -int((now.Weekday()-params.WeekStart+7)%7))
+	monday := weekBegin.AddDate(0, 0, int((time.Monday-params.WeekStart+7)%7))
+	days := params.Days
+
+	switch params.TimeFilter {
 	case "today":
-		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
-		endOfDay := startOfDay.Add(24 * time.Hour)
-		return startOfDay, endOfDay
+		return startOfDay, startOfDay.Add(24 * time.Hour)
+
+	case "tomorrow":
+		startOfTomorrow := startOfDay.AddDate(0, 0, 1)
+		return startOfTomorrow, startOfTomorrow.AddDate(0, 0, 1)
 
 	case "this_week":
-		// Calculate Monday to Friday of current week
-		weekday := now.Weekday()
-		daysFromMonday := int(weekday - time.Monday)
-		if weekday == time.Sunday {
-			daysFromMonday = 6 // Sunday is 6 days from Monday
+		if params.WeekMode == "full" {
+			return weekBegin, weekBegin.AddDate(0, 0, 7)
 		}
-
-		startOfWeek := time.Date(now.Year(), now.Month(), now.Day()-daysFromMonday, 0, 0, 0, 0, loc)
-		endOfWeek := startOfWeek.Add(5 * 24 * time.Hour) // Monday to Friday
-		return startOfWeek, endOfWeek
+		// Monday to Friday of current week
+		return monday, monday.Add(5 * 24 * time.Hour)
 
 	case "next_week":
-		// Calculate Monday to Friday of next week
-		weekday := now.Weekday()
-		daysFromMonday := int(weekday - time.Monday)
-		if weekday == time.Sunday {
-			daysFromMonday = 6
+		if params.WeekMode == "full" {
+			return weekBegin.AddDate(0, 0, 7), weekBegin.AddDate(0, 0, 14)
+		}
+		// Monday to Friday of next week
+		startOfNextWeek := monday.AddDate(0, 0, 7)
+		return startOfNextWeek, startOfNextWeek.Add(5 * 24 * time.Hour)
+
+	case "this_weekend":
+		// The coming Saturday and Sunday; on a Sunday, the weekend under way
+		saturday := startOfDay.AddDate(0, 0, int(time.Saturday-now.Weekday()))
+		if now.Weekday() == time.Sunday {
+			saturday = startOfDay.AddDate(0, 0, -1)
+		}
+		return saturday, saturday.AddDate(0, 0, 2)
+
+	case "this_month":
+		startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return startOfMonth, startOfMonth.AddDate(0, 1, 0)
+
+	case "next_month":
+		startOfNextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, loc)
+		return startOfNextMonth, startOfNextMonth.AddDate(0, 1, 0)
+
+	case "next_n_days":
+		// Today and the days after it
+		if days < 1 {
+			days = 1
 		}
+		return startOfDay, startOfDay.AddDate(0, 0, days)
 
-		startOfNextWeek := time.Date(now.Year(), now.Month(), now.Day()-daysFromMonday+7, 0, 0, 0, 0, loc)
-		endOfNextWeek := startOfNextWeek.Add(5 * 24 * time.Hour)
-		return startOfNextWeek, endOfNextWeek
+	case "past_n_days":
+		// The days before today, up to and including today
+		if days < 1 {
+			days = 1
+		}
+		return startOfDay.AddDate(0, 0, 1-days), startOfDay.AddDate(0, 0, 1)
 
 	case "custom":
-		if !customMin.IsZero() && !customMax.IsZero() {
-			return customMin, customMax
+		if !params.TimeMin.IsZero() && !params.TimeMax.IsZero() {
+			return params.TimeMin, params.TimeMax
 		}
 		fallthrough
 
 	default:
 		// Default to today
-		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
-		endOfDay := startOfDay.Add(24 * time.Hour)
-		return startOfDay, endOfDay
+		return startOfDay, startOfDay.Add(24 * time.Hour)
+	}
+}
+
+// listWindow returns the range ListEvents asks the API for: the time filter's
+// range, or for the rest of today, from now on. The API lists events that end
+// after the start of the range, so a meeting in progress is still listed.
+func listWindow(params ListEventsParams, now time.Time) (time.Time, time.Time) {
+	timeMin, timeMax := calculateTimeRange(params)
+	if params.ExcludePast && params.TimeFilter == "today" && now.After(timeMin) {
+		timeMin = now
 	}
+	return timeMin, timeMax
 }
 
 // Simple email regex for validation
@@ -872,8 +1206,11 @@ func isValidEmail(email string) bool {
 
 // getUserEmail gets the authenticated user's email address (cached after first call)
 func (c *Client) getUserEmail() (string, error) {
-	if c.cachedUserEmail != "" {
-		return c.cachedUserEmail, nil
+	c.cacheMu.Lock()
+	cached := c.cachedUserEmail
+	c.cacheMu.Unlock()
+	if cached != "" {
+		return cached, nil
 	}
 
 	// Get the primary calendar to extract the user's email
@@ -886,7 +1223,9 @@ func (c *Client) getUserEmail() (string, error) {
 		return "", fmt.Errorf("unable to determine user email from primary calendar")
 	}
 
+	c.cacheMu.Lock()
 	c.cachedUserEmail = cal.Id
+	c.cacheMu.Unlock()
 	return cal.Id, nil
 }
 
@@ -911,6 +1250,9 @@ func (c *Client) SetWorkingLocation(params SetWorkingLocationParams) error {
 	if params.CalendarID == "" {
 		params.CalendarID = "primary"
 	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return err
+	}
 
 	switch params.Action {
 	case "remove":
@@ -1013,7 +1355,7 @@ func (c *Client) DetectOverlaps(events []*calendar.Event, showDeclined bool) map
 
 	for _, event := range events {
 		// Check if this event should be included in overlap detection
-		declined := c.isEventDeclined(event)
+		declined := selfDeclined(event)
 		if !showDeclined && declined {
 			continue
 		}
@@ -1058,34 +1400,6 @@ func (c *Client) DetectOverlaps(events []*calendar.Event, showDeclined bool) map
 	return overlaps
 }
 
-// isEventDeclined checks if the authenticated user has declined the event
-func (c *Client) isEventDeclined(event *calendar.Event) bool {
-	if event.Attendees == nil {
-		return false
-	}
-
-	// Get the authenticated user's email
-	userEmail, err := c.getUserEmail()
-	if err != nil {
-		// If we can't get user email, fall back to checking if any attendee declined
-		// This maintains backward compatibility but is less accurate
-		for _, attendee := range event.Attendees {
-			if attendee.ResponseStatus == "declined" {
-				return true
-			}
-		}
-		return false
-	}
-
-	// Look for the user's specific response status
-	for _, attendee := range event.Attendees {
-		if attendee.Email == userEmail && attendee.ResponseStatus == "declined" {
-			return true
-		}
-	}
-	return false
-}
-
 // parseEventTimes extracts start and end times from a calendar event
 func parseEventTimes(event *calendar.Event) (time.Time, time.Time, bool, error) {
 	var start, end time.Time
@@ -1217,6 +1531,9 @@ func (c *Client) GetDocument(params GetDocumentParams) (string, error) {
 	if params.FileID == "" {
 		return "", fmt.Errorf("file_id is required")
 	}
+	if err := c.requireScopes(drive.DriveReadonlyScope); err != nil {
+		return "", err
+	}
 	fileID := parseFileID(params.FileID)
 	resp, err := c.driveService.Files.Export(fileID, "text/markdown").Download()
 	if err != nil {
@@ -118,7 +118,7 @@ func TestEventsOverlap(t *testing.T) {
 // ----- calculateTimeRange -----
 
 func TestCalculateTimeRange_Today(t *testing.T) {
-	start, end := calculateTimeRange("today", time.Time{}, time.Time{}, "UTC")
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"})
 	now := time.Now().UTC()
 
 	if start.Day() != now.Day() {
@@ -132,8 +132,27 @@ func TestCalculateTimeRange_Today(t *testing.T) {
 	}
 }
 
+func TestListWindow_ExcludePast(t *testing.T) {
+	now := time.Now()
+	dayStart, dayEnd := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"})
+
+	start, end := listWindow(ListEventsParams{TimeFilter: "today", TimeZone: "UTC", ExcludePast: true}, now)
+	if !start.Equal(now) || !end.Equal(dayEnd) {
+		t.Errorf("rest of today = %v..%v, want %v..%v", start, end, now, dayEnd)
+	}
+	start, _ = listWindow(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"}, now)
+	if !start.Equal(dayStart) {
+		t.Errorf("whole day start = %v, want %v", start, dayStart)
+	}
+	weekStart, _ := calculateTimeRange(ListEventsParams{TimeFilter: "this_week", WeekStart: time.Monday, TimeZone: "UTC"})
+	start, _ = listWindow(ListEventsParams{TimeFilter: "this_week", TimeZone: "UTC", ExcludePast: true}, now)
+	if !start.Equal(weekStart) {
+		t.Errorf("exclude_past should only apply to today, got start %v", start)
+	}
+}
+
 func TestCalculateTimeRange_ThisWeek(t *testing.T) {
-	start, end := calculateTimeRange("this_week", time.Time{}, time.Time{}, "UTC")
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "this_week", WeekStart: time.Monday, TimeZone: "UTC"})
 	if end.Sub(start) != 5*24*time.Hour {
 		t.Errorf("this_week range should be 5 days, got %v", end.Sub(start))
 	}
@@ -144,7 +163,7 @@ func TestCalculateTimeRange_ThisWeek(t *testing.T) {
 }
 
 func TestCalculateTimeRange_NextWeek(t *testing.T) {
-	start, end := calculateTimeRange("next_week", time.Time{}, time.Time{}, "UTC")
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "next_week", WeekStart: time.Monday, TimeZone: "UTC"})
 	if end.Sub(start) != 5*24*time.Hour {
 		t.Errorf("next_week range should be 5 days, got %v", end.Sub(start))
 	}
@@ -152,17 +171,98 @@ func TestCalculateTimeRange_NextWeek(t *testing.T) {
 		t.Errorf("next_week start should be Monday, got %v", start.Weekday())
 	}
 	// next week's Monday should be after this week's Monday
-	thisStart, _ := calculateTimeRange("this_week", time.Time{}, time.Time{}, "UTC")
+	thisStart, _ := calculateTimeRange(ListEventsParams{TimeFilter: "this_week", WeekStart: time.Monday, TimeZone: "UTC"})
 	if !start.After(thisStart) {
 		t.Error("next_week start should be after this_week start")
 	}
 }
 
+func TestCalculateTimeRange_Tomorrow(t *testing.T) {
+	today, _ := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"})
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "tomorrow", TimeZone: "UTC"})
+	if !start.Equal(today.Add(24*time.Hour)) || end.Sub(start) != 24*time.Hour {
+		t.Errorf("tomorrow = %v..%v, want the day after %v", start, end, today)
+	}
+}
+
+func TestCalculateTimeRange_ThisWeekend(t *testing.T) {
+	today, _ := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"})
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "this_weekend", TimeZone: "UTC"})
+	if start.Weekday() != time.Saturday || start.Before(today.AddDate(0, 0, -1)) || start.After(today.AddDate(0, 0, 6)) {
+		t.Errorf("this_weekend start = %v, want the Saturday of this weekend (today %v)", start, today)
+	}
+	if end.Sub(start) != 48*time.Hour {
+		t.Errorf("this_weekend range should be 2 days, got %v", end.Sub(start))
+	}
+}
+
+func TestCalculateTimeRange_WeekMode(t *testing.T) {
+	today, _ := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"})
+
+	for _, weekStart := range []time.Weekday{time.Sunday, time.Monday, time.Saturday} {
+		start, end := calculateTimeRange(ListEventsParams{TimeFilter: "this_week", WeekMode: "full", WeekStart: weekStart, TimeZone: "UTC"})
+		if start.Weekday() != weekStart || end.Sub(start) != 7*24*time.Hour {
+			t.Errorf("full week starting %v = %v..%v", weekStart, start, end)
+		}
+		if start.After(today) || !end.After(today) {
+			t.Errorf("full week starting %v = %v..%v should include today", weekStart, start, end)
+		}
+		nextStart, nextEnd := calculateTimeRange(ListEventsParams{TimeFilter: "next_week", WeekMode: "full", WeekStart: weekStart, TimeZone: "UTC"})
+		if !nextStart.Equal(end) || nextEnd.Sub(nextStart) != 7*24*time.Hour {
+			t.Errorf("full next week starting %v = %v..%v, want it to start at %v", weekStart, nextStart, nextEnd, end)
+		}
+
+		// The work week is Monday-Friday of the week that holds today
+		workStart, workEnd := calculateTimeRange(ListEventsParams{TimeFilter: "this_week", WeekStart: weekStart, TimeZone: "UTC"})
+		if workStart.Weekday() != time.Monday || workEnd.Sub(workStart) != 5*24*time.Hour {
+			t.Errorf("work week starting %v = %v..%v", weekStart, workStart, workEnd)
+		}
+		if workStart.Before(start) || workEnd.After(end) {
+			t.Errorf("work week %v..%v should lie in the full week %v..%v", workStart, workEnd, start, end)
+		}
+	}
+}
+
+func TestCalculateTimeRange_Months(t *testing.T) {
+	now := time.Now().UTC()
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "this_month", TimeZone: "UTC"})
+	if start.Day() != 1 || start.Month() != now.Month() || end.Day() != 1 || end.Month() == now.Month() {
+		t.Errorf("this_month = %v..%v", start, end)
+	}
+	nextStart, nextEnd := calculateTimeRange(ListEventsParams{TimeFilter: "next_month", TimeZone: "UTC"})
+	if !nextStart.Equal(end) || !nextEnd.Equal(end.AddDate(0, 1, 0)) {
+		t.Errorf("next_month = %v..%v, want it to start at %v", nextStart, nextEnd, end)
+	}
+}
+
+func TestCalculateTimeRange_NDays(t *testing.T) {
+	today, tomorrow := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "UTC"})
+
+	tests := []struct {
+		filter    string
+		days      int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"next_n_days", 7, today, today.AddDate(0, 0, 7)},
+		{"next_n_days", 1, today, tomorrow},
+		{"next_n_days", 0, today, tomorrow},
+		{"past_n_days", 7, today.AddDate(0, 0, -6), tomorrow},
+		{"past_n_days", 1, today, tomorrow},
+	}
+	for _, tt := range tests {
+		start, end := calculateTimeRange(ListEventsParams{TimeFilter: tt.filter, Days: tt.days, TimeZone: "UTC"})
+		if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+			t.Errorf("%s(%d) = %v..%v, want %v..%v", tt.filter, tt.days, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
 func TestCalculateTimeRange_Custom(t *testing.T) {
 	min := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
 	max := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
 
-	start, end := calculateTimeRange("custom", min, max, "UTC")
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "custom", TimeMin: min, TimeMax: max, TimeZone: "UTC"})
 	if !start.Equal(min) {
 		t.Errorf("custom start = %v, want %v", start, min)
 	}
@@ -173,7 +273,7 @@ func TestCalculateTimeRange_Custom(t *testing.T) {
 
 func TestCalculateTimeRange_CustomEmpty_FallsBackToToday(t *testing.T) {
 	// Custom with zero times falls back to today
-	start, end := calculateTimeRange("custom", time.Time{}, time.Time{}, "UTC")
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "custom", TimeZone: "UTC"})
 	if end.Sub(start) != 24*time.Hour {
 		t.Errorf("empty custom should fall back to 24h today range, got %v", end.Sub(start))
 	}
@@ -181,7 +281,7 @@ func TestCalculateTimeRange_CustomEmpty_FallsBackToToday(t *testing.T) {
 
 func TestCalculateTimeRange_InvalidTimezone(t *testing.T) {
 	// Should not panic with invalid timezone — falls back to UTC
-	start, end := calculateTimeRange("today", time.Time{}, time.Time{}, "Not/A/Zone")
+	start, end := calculateTimeRange(ListEventsParams{TimeFilter: "today", TimeZone: "Not/A/Zone"})
 	if !end.After(start) {
 		t.Error("end should be after start even with invalid timezone")
 	}
@@ -346,12 +446,41 @@ func TestDetectOverlaps_Empty(t *testing.T) {
 	}
 }
 
-// isEventDeclined is only testable when attendees is nil (no API call needed)
-func TestIsEventDeclined_NoAttendees(t *testing.T) {
+func TestSelfDeclined(t *testing.T) {
+	tests := []struct {
+		name      string
+		attendees []*calendar.EventAttendee
+		want      bool
+	}{
+		{"no attendees", nil, false},
+		{"self declined", []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}, true},
+		{"self accepted", []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "accepted"}}, false},
+		{"someone else declined", []*calendar.EventAttendee{
+			{Email: "me@example.com", Self: true, ResponseStatus: "accepted"},
+			{Email: "bob@example.com", ResponseStatus: "declined"},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selfDeclined(&calendar.Event{Attendees: tt.attendees}); got != tt.want {
+				t.Errorf("selfDeclined() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectOverlaps_SkipsDeclined(t *testing.T) {
 	c := &Client{}
-	event := &calendar.Event{Attendees: nil}
-	if c.isEventDeclined(event) {
-		t.Error("event with no attendees should not be declined")
+	declined := []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+	events := []*calendar.Event{
+		{Id: "a", Start: &calendar.EventDateTime{DateTime: "2025-03-10T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-10T11:00:00Z"}},
+		{Id: "b", Attendees: declined, Start: &calendar.EventDateTime{DateTime: "2025-03-10T10:30:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-10T11:30:00Z"}},
+	}
+	if overlaps := c.DetectOverlaps(events, false); overlaps["a"] {
+		t.Errorf("declined event counted as an overlap: %v", overlaps)
+	}
+	if overlaps := c.DetectOverlaps(events, true); !overlaps["a"] || !overlaps["b"] {
+		t.Errorf("show_declined should include the declined event: %v", overlaps)
 	}
 }
 
@@ -374,3 +503,111 @@ func TestParseFileID(t *testing.T) {
 		}
 	}
 }
+
+// ----- parseAttendees -----
+
+func TestParseAttendees(t *testing.T) {
+	attendees, err := parseAttendees([]interface{}{
+		"alex@example.com",
+		map[string]interface{}{"email": "guest@partner.com", "display_name": "Pat Guest", "optional": true},
+		map[string]interface{}{"email": "kim@example.com", "response_status": "accepted"},
+	}, "needsAction")
+	if err != nil {
+		t.Fatalf("parseAttendees() error: %v", err)
+	}
+	want := []AttendeeParams{
+		{Email: "alex@example.com", ResponseStatus: "needsAction"},
+		{Email: "guest@partner.com", DisplayName: "Pat Guest", Optional: true, ResponseStatus: "needsAction"},
+		{Email: "kim@example.com", ResponseStatus: "accepted"},
+	}
+	if len(attendees) != len(want) {
+		t.Fatalf("got %d attendees, want %d", len(attendees), len(want))
+	}
+	for i := range want {
+		if attendees[i] != want[i] {
+			t.Errorf("attendee %d = %+v, want %+v", i, attendees[i], want[i])
+		}
+	}
+}
+
+func TestParseAttendees_Invalid(t *testing.T) {
+	if _, err := parseAttendees([]interface{}{map[string]interface{}{"display_name": "No Email"}}, ""); err == nil {
+		t.Error("expected error for attendee without email")
+	}
+	if _, err := parseAttendees([]interface{}{42.0}, ""); err == nil {
+		t.Error("expected error for non-string, non-object attendee")
+	}
+}
+
+func TestParseAttendees_Comment(t *testing.T) {
+	attendees, err := parseAttendees([]interface{}{
+		map[string]interface{}{"email": "me@example.com", "response_status": "declined", "comment": "On vacation"},
+		map[string]interface{}{"email": "kim@example.com", "comment": ""},
+		map[string]interface{}{"email": "alex@example.com"},
+	}, "")
+	if err != nil {
+		t.Fatalf("parseAttendees() error: %v", err)
+	}
+	if c := attendees[0].Comment; c == nil || *c != "On vacation" {
+		t.Errorf("attendee 0 comment = %v, want \"On vacation\"", c)
+	}
+	if c := attendees[1].Comment; c == nil || *c != "" {
+		t.Errorf("attendee 1 comment = %v, want empty (clear)", c)
+	}
+	if attendees[2].Comment != nil {
+		t.Errorf("attendee 2 comment = %q, want nil (keep existing)", *attendees[2].Comment)
+	}
+}
+
+// ----- keepAttendeeComments -----
+
+func TestKeepAttendeeComments(t *testing.T) {
+	event := &calendar.Event{Attendees: []*calendar.EventAttendee{
+		{Email: "Kim@example.com", ResponseStatus: "tentative", Comment: "May be late"},
+		{Email: "me@example.com", ResponseStatus: "accepted", Comment: "See you there"},
+	}}
+	cleared := ""
+	attendees := []AttendeeParams{
+		{Email: "kim@example.com", ResponseStatus: "tentative"},
+		{Email: "me@example.com", ResponseStatus: "declined", Comment: &cleared},
+		{Email: "new@example.com"},
+	}
+	keepAttendeeComments(attendees, event)
+
+	if c := attendees[0].Comment; c == nil || *c != "May be late" {
+		t.Errorf("kim comment = %v, want existing comment kept", c)
+	}
+	if c := attendees[1].Comment; c == nil || *c != "" {
+		t.Errorf("own comment = %v, want cleared", c)
+	}
+	if attendees[2].Comment != nil {
+		t.Errorf("new attendee comment = %q, want nil", *attendees[2].Comment)
+	}
+}
+
+// ----- parsePatchEventParams -----
+
+func TestParsePatchEventParams_RemoveConference(t *testing.T) {
+	ct := NewCalendarTools(&Client{})
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      bool
+	}{
+		{"remove_conference", map[string]interface{}{"remove_conference": true}, true},
+		{"conference_data null", map[string]interface{}{"conference_data": nil}, true},
+		{"not requested", map[string]interface{}{"summary": "Sync"}, false},
+		{"remove_conference false", map[string]interface{}{"remove_conference": false}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ct.parsePatchEventParams(tt.arguments)
+			if err != nil {
+				t.Fatalf("parsePatchEventParams() error: %v", err)
+			}
+			if params.RemoveConference != tt.want {
+				t.Errorf("RemoveConference = %v, want %v", params.RemoveConference, tt.want)
+			}
+		})
+	}
+}
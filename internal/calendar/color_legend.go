@@ -0,0 +1,195 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// colorLegendEnv maps event colors to meanings as comma-separated
+	// color=meaning pairs, e.g. "red=external,green=focus,default=internal"
+	colorLegendEnv = "GCAL_MCP_COLOR_LEGEND"
+	// defaultColorKey stands for events without a color of their own, which
+	// are shown in their calendar's color
+	defaultColorKey = "default"
+)
+
+// eventColorIDs lists the event color IDs in palette order.
+var eventColorIDs = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}
+
+// eventColorNames are the names the Calendar UI gives the event colors.
+var eventColorNames = map[string]string{
+	"1":  "Lavender",
+	"2":  "Sage",
+	"3":  "Grape",
+	"4":  "Flamingo",
+	"5":  "Banana",
+	"6":  "Tangerine",
+	"7":  "Peacock",
+	"8":  "Graphite",
+	"9":  "Blueberry",
+	"10": "Basil",
+	"11": "Tomato",
+}
+
+// colorAliases maps plain color words to the closest event color.
+var colorAliases = map[string]string{
+	"purple":    "3",
+	"pink":      "4",
+	"yellow":    "5",
+	"orange":    "6",
+	"cyan":      "7",
+	"turquoise": "7",
+	"gray":      "8",
+	"grey":      "8",
+	"blue":      "9",
+	"green":     "10",
+	"red":       "11",
+}
+
+// ColorLegend maps event color IDs, or "default" for uncolored events, to
+// what the user means by them.
+type ColorLegend map[string]string
+
+// ColorLegendEntry is one color of the legend as reported by get_color_legend.
+type ColorLegendEntry struct {
+	ColorID   string `json:"color_id"`
+	ColorName string `json:"color_name"`
+	Meaning   string `json:"meaning,omitempty"`
+}
+
+// ColorLegendFromEnv parses GCAL_MCP_COLOR_LEGEND. Entries naming an unknown
+// color are skipped with a warning on stderr.
+func ColorLegendFromEnv() ColorLegend {
+	legend, invalid := parseColorLegend(os.Getenv(colorLegendEnv))
+	for _, entry := range invalid {
+		fmt.Fprintf(os.Stderr, "Ignoring color legend entry %q: unknown color\n", entry)
+	}
+	return legend
+}
+
+// parseColorLegend parses color=meaning pairs. Colors may be given by ID,
+// by their Calendar name ("Tomato"), by a plain color word ("red") or as
+// "default". It returns the legend and the entries that could not be parsed.
+func parseColorLegend(spec string) (ColorLegend, []string) {
+	legend := make(ColorLegend)
+	var invalid []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		color, meaning, ok := strings.Cut(entry, "=")
+		meaning = strings.TrimSpace(meaning)
+		id, known := resolveColorID(color)
+		if !ok || !known || meaning == "" {
+			invalid = append(invalid, entry)
+			continue
+		}
+		legend[id] = meaning
+	}
+	return legend, invalid
+}
+
+// resolveColorID returns the event color ID named by color.
+func resolveColorID(color string) (string, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == defaultColorKey {
+		return defaultColorKey, true
+	}
+	if _, ok := eventColorNames[color]; ok {
+		return color, true
+	}
+	for id, name := range eventColorNames {
+		if strings.ToLower(name) == color {
+			return id, true
+		}
+	}
+	id, ok := colorAliases[color]
+	return id, ok
+}
+
+// Category returns what the event's color means, or "" if the legend does not
+// cover it.
+func (l ColorLegend) Category(event *calendar.Event) string {
+	if event.ColorId == "" {
+		return l[defaultColorKey]
+	}
+	return l[event.ColorId]
+}
+
+// entries lists every event color in palette order, followed by the default
+// color if the legend assigns it a meaning.
+func (l ColorLegend) entries() []ColorLegendEntry {
+	entries := make([]ColorLegendEntry, 0, len(eventColorIDs)+1)
+	for _, id := range eventColorIDs {
+		entries = append(entries, ColorLegendEntry{ColorID: id, ColorName: eventColorNames[id], Meaning: l[id]})
+	}
+	if meaning, ok := l[defaultColorKey]; ok {
+		entries = append(entries, ColorLegendEntry{ColorID: defaultColorKey, ColorName: "Calendar color", Meaning: meaning})
+	}
+	return entries
+}
+
+// formatColorLegend renders the colors with a meaning, then the unassigned
+// ones, followed by the structured data.
+func formatColorLegend(legend ColorLegend) string {
+	var result strings.Builder
+	result.WriteString("🎨 Event color legend:\n\n")
+	if len(legend) == 0 {
+		fmt.Fprintf(&result, "No meanings configured. Set %s, e.g. \"red=external,green=focus,default=internal\".\n", colorLegendEnv)
+	}
+
+	entries := legend.entries()
+	var unassigned []string
+	for _, e := range entries {
+		if e.Meaning == "" {
+			unassigned = append(unassigned, fmt.Sprintf("%s (%s)", e.ColorName, e.ColorID))
+			continue
+		}
+		fmt.Fprintf(&result, "• %s (%s): %s\n", e.ColorName, e.ColorID, e.Meaning)
+	}
+	if len(legend) > 0 && len(unassigned) > 0 {
+		fmt.Fprintf(&result, "\nUnassigned: %s\n", strings.Join(unassigned, ", "))
+	}
+
+	entriesJSON, _ := json.MarshalIndent(entries, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(entriesJSON))
+	return result.String()
+}
+
+// SetColorLegend sets the meanings list_events uses to annotate event colors.
+func (ct *CalendarTools) SetColorLegend(legend ColorLegend) {
+	ct.colorLegend = legend
+}
+
+func (ct *CalendarTools) handleGetColorLegend(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatColorLegend(ct.colorLegend),
+		}},
+	}, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- parseColorLegend -----
+
+func TestParseColorLegend(t *testing.T) {
+	legend, invalid := parseColorLegend(" red=external, Basil = focus ,7=1:1s,default=internal,magenta=fun,blue=,nonsense")
+	want := ColorLegend{"11": "external", "10": "focus", "7": "1:1s", "default": "internal"}
+	if len(legend) != len(want) {
+		t.Fatalf("legend = %v, want %v", legend, want)
+	}
+	for id, meaning := range want {
+		if legend[id] != meaning {
+			t.Errorf("legend[%s] = %q, want %q", id, legend[id], meaning)
+		}
+	}
+	if got := strings.Join(invalid, "|"); got != "magenta=fun|blue=|nonsense" {
+		t.Errorf("invalid entries = %q", got)
+	}
+}
+
+func TestResolveColorID(t *testing.T) {
+	tests := []struct {
+		color, want string
+		ok          bool
+	}{
+		{"11", "11", true},
+		{"Tomato", "11", true},
+		{"grey", "8", true},
+		{"DEFAULT", "default", true},
+		{"12", "", false},
+		{"magenta", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := resolveColorID(tt.color)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("resolveColorID(%q) = %q, %v; want %q, %v", tt.color, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// ----- Category -----
+
+func TestColorLegendCategory(t *testing.T) {
+	legend := ColorLegend{"11": "external", "default": "internal"}
+	tests := []struct {
+		colorID, want string
+	}{
+		{"11", "external"},
+		{"", "internal"},
+		{"5", ""},
+	}
+	for _, tt := range tests {
+		if got := legend.Category(&calendar.Event{ColorId: tt.colorID}); got != tt.want {
+			t.Errorf("Category(colorId %q) = %q, want %q", tt.colorID, got, tt.want)
+		}
+	}
+	if got := ColorLegend(nil).Category(&calendar.Event{ColorId: "11"}); got != "" {
+		t.Errorf("empty legend category = %q, want none", got)
+	}
+}
+
+// ----- formatColorLegend -----
+
+func TestFormatColorLegend(t *testing.T) {
+	out := formatColorLegend(ColorLegend{"11": "external", "default": "internal"})
+	for _, want := range []string{"• Tomato (11): external", "• Calendar color (default): internal", "Unassigned: Lavender (1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	empty := formatColorLegend(nil)
+	if !strings.Contains(empty, colorLegendEnv) || strings.Contains(empty, "Unassigned") {
+		t.Errorf("unexpected output without legend:\n%s", empty)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/calendar/v3"
+)
+
+// newConferenceRequestID returns a request ID for a conference create request.
+// The API ignores a create request whose ID it has already seen for an event,
+// so every request gets its own UUID; IDs derived from the clock collided when
+// several events were created within the same second.
+func newConferenceRequestID() string {
+	return uuid.NewString()
+}
+
+// newMeetConference asks the API to create a new Google Meet conference.
+func newMeetConference() *ConferenceDataParams {
+	return &ConferenceDataParams{
+		CreateRequest: &CreateConferenceRequest{
+			RequestID: newConferenceRequestID(),
+			ConferenceSolution: &ConferenceSolution{
+				Type: "hangoutsMeet",
+			},
+		},
+	}
+}
+
+// conferenceNote describes the outcome of a Meet link requested for event,
+// or returns "" when the link was created. Conference creation is
+// asynchronous and may fail (e.g. when the calendar does not allow Google
+// Meet) while the event itself is still saved.
+func conferenceNote(event *calendar.Event) string {
+	if event == nil {
+		return ""
+	}
+	if event.ConferenceData == nil {
+		return "⚠️ No Meet link was created: the API returned the event without conference data"
+	}
+	status := ""
+	if req := event.ConferenceData.CreateRequest; req != nil && req.Status != nil {
+		status = req.Status.StatusCode
+	}
+	switch status {
+	case "pending":
+		return "⏳ The Meet link is still being created; get_event_link shows it once it is ready"
+	case "failure":
+		return "⚠️ The Meet link could not be created (the calendar may not allow Google Meet); the event was saved without one"
+	}
+	if meetLink(event) != "" {
+		return ""
+	}
+	if status == "" {
+		return "⚠️ No Meet link was created"
+	}
+	return fmt.Sprintf("⚠️ No Meet link was created (conference status: %s)", status)
+}
+
+// DialIn is a way to join an event's conference other than its main video
+// link: a phone number with its PIN, a SIP address, a further video link, or
+// the page listing more phone numbers.
+type DialIn struct {
+	Type       string `json:"type"` // "phone", "sip", "video" or "more"
+	URI        string `json:"uri"`
+	Label      string `json:"label,omitempty"`       // e.g. "+1 555-0100"
+	RegionCode string `json:"region_code,omitempty"` // for phone numbers, e.g. "US"
+	PIN        string `json:"pin,omitempty"`
+	Passcode   string `json:"passcode,omitempty"`
+}
+
+// dialIns returns the entry points of the event's conference, except the
+// video link meetLink already returns, phone numbers first.
+func dialIns(event *calendar.Event) []DialIn {
+	if event == nil || event.ConferenceData == nil {
+		return nil
+	}
+	link := meetLink(event)
+	var phones, others []DialIn
+	for _, ep := range event.ConferenceData.EntryPoints {
+		if ep.EntryPointType == "video" && ep.Uri == link {
+			continue
+		}
+		d := newDialIn(ep)
+		if d.Type == "phone" {
+			phones = append(phones, d)
+		} else {
+			others = append(others, d)
+		}
+	}
+	return append(phones, others...)
+}
+
+// newDialIn converts a conference entry point.
+func newDialIn(ep *calendar.EntryPoint) DialIn {
+	d := DialIn{
+		Type:       ep.EntryPointType,
+		URI:        ep.Uri,
+		Label:      ep.Label,
+		RegionCode: ep.RegionCode,
+		PIN:        ep.Pin,
+		Passcode:   ep.Passcode,
+	}
+	if d.PIN == "" {
+		d.PIN = ep.AccessCode
+	}
+	if d.Passcode == "" {
+		d.Passcode = ep.Password
+	}
+	return d
+}
+
+// describeDialIn renders an entry point on one line, e.g.
+// "+1 555-0100 (US), PIN: 123 456#".
+func describeDialIn(d DialIn) string {
+	label := d.Label
+	if label == "" {
+		label = strings.TrimPrefix(strings.TrimPrefix(d.URI, "tel:"), "sip:")
+	}
+	var text string
+	switch d.Type {
+	case "phone":
+		text = label
+		if d.RegionCode != "" {
+			text += fmt.Sprintf(" (%s)", d.RegionCode)
+		}
+	case "sip":
+		text = "SIP: " + label
+	case "more":
+		text = "More phone numbers: " + d.URI
+	default:
+		text = "Video: " + d.URI
+	}
+	if d.PIN != "" {
+		text += ", PIN: " + d.PIN
+	}
+	if d.Passcode != "" {
+		text += ", passcode: " + d.Passcode
+	}
+	return text
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"gcal-mcp-server/internal/fake"
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- newMeetConference -----
+
+func TestNewMeetConference_UniqueRequestIDs(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := newMeetConference().CreateRequest.RequestID
+		if id == "" || seen[id] {
+			t.Fatalf("request ID %q is empty or repeated", id)
+		}
+		seen[id] = true
+	}
+}
+
+// ----- conferenceNote -----
+
+func TestConferenceNote(t *testing.T) {
+	withStatus := func(status string, entryPoints ...*calendar.EntryPoint) *calendar.Event {
+		return &calendar.Event{ConferenceData: &calendar.ConferenceData{
+			CreateRequest: &calendar.CreateConferenceRequest{Status: &calendar.ConferenceRequestStatus{StatusCode: status}},
+			EntryPoints:   entryPoints,
+		}}
+	}
+	video := &calendar.EntryPoint{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij"}
+
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		want  string // substring; "" means no note
+	}{
+		{"success", withStatus("success", video), ""},
+		{"pending", withStatus("pending"), "still being created"},
+		{"failure", withStatus("failure"), "could not be created"},
+		{"no conference data", &calendar.Event{}, "without conference data"},
+		{"success without link", withStatus("success"), "No Meet link was created (conference status: success)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := conferenceNote(tt.event)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("conferenceNote() = %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("conferenceNote() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ----- dialIns -----
+
+func TestDialIns(t *testing.T) {
+	event := &calendar.Event{
+		HangoutLink: "https://meet.google.com/abc-defg-hij",
+		ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+			{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij", Label: "meet.google.com/abc-defg-hij"},
+			{EntryPointType: "more", Uri: "https://tel.meet/abc-defg-hij?pin=123456"},
+			{EntryPointType: "phone", Uri: "tel:+1-555-0100", Label: "+1 555-0100", RegionCode: "US", Pin: "123456#"},
+			{EntryPointType: "sip", Uri: "sip:123456@sip.example.com", AccessCode: "123456"},
+		}},
+	}
+
+	var got []string
+	for _, d := range dialIns(event) {
+		got = append(got, describeDialIn(d))
+	}
+	want := []string{
+		"+1 555-0100 (US), PIN: 123456#",
+		"More phone numbers: https://tel.meet/abc-defg-hij?pin=123456",
+		"SIP: 123456@sip.example.com, PIN: 123456",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("dial-ins =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A second video entry point, e.g. a Zoom link with a passcode, is kept
+	zoom := &calendar.Event{ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+		{EntryPointType: "video", Uri: "https://zoom.us/j/1"},
+		{EntryPointType: "video", Uri: "https://zoom.us/j/2", Password: "secret"},
+	}}}
+	if d := dialIns(zoom); len(d) != 1 || describeDialIn(d[0]) != "Video: https://zoom.us/j/2, passcode: secret" {
+		t.Errorf("dialIns(zoom) = %+v", d)
+	}
+	if d := dialIns(&calendar.Event{}); d != nil {
+		t.Errorf("dialIns() without conference = %+v, want none", d)
+	}
+}
+
+// ----- edit_event conference_data -----
+
+// serveOnce runs an MCP server with the calendar tools in strict argument
+// mode over one tools/call request, and returns the server's reply.
+func serveOnce(t *testing.T, ct *CalendarTools, name string, arguments map[string]interface{}) string {
+	t.Helper()
+	server := mcp.NewServer(ct)
+	for _, tool := range ct.GetTools() {
+		server.RegisterTool(tool)
+	}
+	server.SetStrictArguments(true)
+
+	params, _ := json.Marshal(mcp.CallToolParams{Name: name, Arguments: arguments})
+	request, _ := json.Marshal(mcp.Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	in, inWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, outWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inWriter.Write(append(request, '\n'))
+	inWriter.Close()
+
+	oldIn, oldOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = in, outWriter
+	err = server.Run()
+	os.Stdin, os.Stdout = oldIn, oldOut
+	outWriter.Close()
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	reply, _ := io.ReadAll(out)
+	return string(reply)
+}
+
+func TestEditEvent_ConferenceDataNullStrict(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+	event, err := store.InsertEvent("primary", &calendar.Event{
+		Summary:        "Sync",
+		Start:          &calendar.EventDateTime{DateTime: "2030-03-04T10:00:00Z"},
+		End:            &calendar.EventDateTime{DateTime: "2030-03-04T11:00:00Z"},
+		ConferenceData: &calendar.ConferenceData{CreateRequest: &calendar.CreateConferenceRequest{RequestId: "sync-meet", ConferenceSolutionKey: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"}}},
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.ConferenceData == nil || len(event.ConferenceData.EntryPoints) == 0 {
+		t.Fatalf("conference = %+v, want a Meet link to remove", event.ConferenceData)
+	}
+
+	reply := serveOnce(t, ct, "edit_event", map[string]interface{}{"event_id": event.Id, "conference_data": nil})
+	if strings.Contains(reply, "unknown argument") || strings.Contains(reply, `"isError":true`) {
+		t.Fatalf("strict edit_event should accept conference_data: null, got %s", reply)
+	}
+	updated, err := store.GetEvent("primary", event.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ConferenceData != nil && len(updated.ConferenceData.EntryPoints) > 0 {
+		t.Errorf("conference = %+v, want it removed", updated.ConferenceData)
+	}
+
+	reply = serveOnce(t, ct, "edit_event", map[string]interface{}{"event_id": event.Id, "conference_data": map[string]interface{}{}})
+	if !strings.Contains(reply, "conference_data only accepts null") {
+		t.Errorf("a conference_data object should be refused, got %s", reply)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Confirmation actions.
+const (
+	confirmCreated   = "created"
+	confirmUpdated   = "updated"
+	confirmDeleted   = "deleted"
+	confirmConfirmed = "confirmed"
+	confirmMerged    = "merged"
+	confirmProposed  = "proposed"
+	confirmApproved  = "approved"
+)
+
+// Confirmation summarizes a change to one event. Mutating tools lead their
+// response with its one-line form and follow with its JSON, so chat clients
+// show a short confirmation while automation still gets the details.
+type Confirmation struct {
+	Action     string `json:"action"`
+	Title      string `json:"title"`
+	When       string `json:"when,omitempty"`  // in the event's time zone, as shown in the line
+	Start      string `json:"start,omitempty"` // RFC3339, or YYYY-MM-DD for all-day events
+	End        string `json:"end,omitempty"`
+	TimeZone   string `json:"time_zone,omitempty"`
+	Attendees  int    `json:"attendees"` // people invited, including the organizer; rooms are not counted
+	CalendarID string `json:"calendar_id,omitempty"`
+	EventID    string `json:"event_id"`
+	ETag       string `json:"etag,omitempty"`
+	HTMLLink   string `json:"html_link,omitempty"`
+	MeetLink   string `json:"meet_link,omitempty"`
+
+	// Conference is set when a Meet link was requested: its ID, entry points
+	// and settings
+	Conference *ConferenceInfo `json:"conference,omitempty"`
+}
+
+// newConfirmation summarizes action on event.
+func newConfirmation(action, calendarID string, event *calendar.Event, tf TimeFormat) Confirmation {
+	c := Confirmation{
+		Action:     action,
+		Title:      eventTitle(event),
+		When:       describeWhen(event, tf),
+		Attendees:  countAttendees(event),
+		CalendarID: calendarID,
+		EventID:    event.Id,
+		ETag:       event.Etag,
+		HTMLLink:   event.HtmlLink,
+		MeetLink:   meetLink(event),
+	}
+	if event.Start != nil {
+		c.Start = event.Start.DateTime + event.Start.Date
+		c.TimeZone = event.Start.TimeZone
+	}
+	if event.End != nil {
+		c.End = event.End.DateTime + event.End.Date
+	}
+	return c
+}
+
+// Line returns the one-line confirmation, e.g. "✅ Created 'Sync' · Tue, Oct 20,
+// 10:00 – 10:30 CEST · 3 attendees".
+func (c Confirmation) Line() string {
+	return confirmationLine(c.Action, c.Title, c.When, c.Attendees)
+}
+
+// confirmationLine formats the first line of a mutating tool's response.
+func confirmationLine(action, title, when string, attendees int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✅ %s%s '%s'", strings.ToUpper(action[:1]), action[1:], title)
+	if when != "" {
+		b.WriteString(" · " + when)
+	}
+	switch attendees {
+	case 0:
+	case 1:
+		b.WriteString(" · 1 attendee")
+	default:
+		fmt.Fprintf(&b, " · %d attendees", attendees)
+	}
+	return b.String()
+}
+
+// formatConfirmation renders the confirmation line, any non-empty notes (each
+// after a blank line) and the JSON payload.
+func formatConfirmation(c Confirmation, payload interface{}, notes ...string) string {
+	var b strings.Builder
+	b.WriteString(c.Line())
+	for _, note := range notes {
+		if note = strings.TrimRight(note, "\n"); note != "" {
+			b.WriteString("\n\n" + note)
+		}
+	}
+	data, _ := json.MarshalIndent(payload, "", "  ")
+	b.WriteString("\n\n" + string(data))
+	return b.String()
+}
+
+// describeWhen formats when an event takes place in its own time zone, e.g.
+// "Tue, Oct 20, 10:00 – 10:30 CEST", or its days for an all-day event.
+func describeWhen(event *calendar.Event, tf TimeFormat) string {
+	if days := describeAllDay(event, tf); days != "" {
+		return days + " (all day)"
+	}
+	if event.Start == nil || event.End == nil {
+		return ""
+	}
+	start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return ""
+	}
+	end, err := time.Parse(time.RFC3339, event.End.DateTime)
+	if err != nil {
+		return ""
+	}
+	if event.Start.TimeZone != "" {
+		if loc, err := loadLocation(event.Start.TimeZone); err == nil {
+			start, end = start.In(loc), end.In(loc)
+		}
+	}
+	if start.Format(dateLayout) == end.Format(dateLayout) {
+		return fmt.Sprintf("%s, %s – %s", tf.ShortDate(start), tf.Clock(start), tf.ClockZone(end))
+	}
+	return fmt.Sprintf("%s, %s – %s, %s", tf.ShortDate(start), tf.Clock(start), tf.ShortDate(end), tf.ClockZone(end))
+}
+
+// countAttendees counts the people invited to event, leaving out rooms and
+// other resources.
+func countAttendees(event *calendar.Event) int {
+	n := 0
+	for _, a := range event.Attendees {
+		if !a.Resource {
+			n++
+		}
+	}
+	return n
+}
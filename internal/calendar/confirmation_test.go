@@ -0,0 +1,111 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- confirmationLine -----
+
+func TestConfirmationLine(t *testing.T) {
+	cases := []struct {
+		action, title, when string
+		attendees           int
+		want                string
+	}{
+		{confirmCreated, "Sync", "Tue, Oct 20, 10:00 – 10:30 CEST", 3, "✅ Created 'Sync' · Tue, Oct 20, 10:00 – 10:30 CEST · 3 attendees"},
+		{confirmDeleted, "1:1", "Tue, Oct 20, 10:00 – 10:30 CEST", 1, "✅ Deleted '1:1' · Tue, Oct 20, 10:00 – 10:30 CEST · 1 attendee"},
+		{confirmUpdated, "Focus", "", 0, "✅ Updated 'Focus'"},
+	}
+	for _, tc := range cases {
+		if got := confirmationLine(tc.action, tc.title, tc.when, tc.attendees); got != tc.want {
+			t.Errorf("confirmationLine(%q) = %q, want %q", tc.title, got, tc.want)
+		}
+	}
+}
+
+// ----- describeWhen -----
+
+func TestDescribeWhen(t *testing.T) {
+	tf := TimeFormat{Clock24: true}
+	cases := []struct {
+		name  string
+		event *calendar.Event
+		want  string
+	}{
+		{
+			"in the event's time zone",
+			&calendar.Event{
+				Start: &calendar.EventDateTime{DateTime: "2026-10-20T08:00:00Z", TimeZone: "Europe/Berlin"},
+				End:   &calendar.EventDateTime{DateTime: "2026-10-20T08:30:00Z", TimeZone: "Europe/Berlin"},
+			},
+			"Tue, Oct 20, 10:00 – 10:30 CEST",
+		},
+		{
+			"past midnight",
+			&calendar.Event{
+				Start: &calendar.EventDateTime{DateTime: "2026-10-20T23:00:00Z"},
+				End:   &calendar.EventDateTime{DateTime: "2026-10-21T01:00:00Z"},
+			},
+			"Tue, Oct 20, 23:00 – Wed, Oct 21, 01:00 UTC",
+		},
+		{
+			"all day",
+			&calendar.Event{Start: &calendar.EventDateTime{Date: "2026-10-22"}, End: &calendar.EventDateTime{Date: "2026-10-24"}},
+			"Oct 22 – Oct 23 (all day)",
+		},
+		{"no times", &calendar.Event{}, ""},
+	}
+	for _, tc := range cases {
+		if got := describeWhen(tc.event, tf); got != tc.want {
+			t.Errorf("%s: describeWhen = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// ----- formatConfirmation -----
+
+func TestFormatConfirmation(t *testing.T) {
+	event := &calendar.Event{
+		Id:      "ev1",
+		Etag:    `"3"`,
+		Summary: "Sync",
+		Start:   &calendar.EventDateTime{DateTime: "2026-10-20T10:00:00Z"},
+		End:     &calendar.EventDateTime{DateTime: "2026-10-20T10:30:00Z"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "me@example.com", Self: true},
+			{Email: "ana@example.com"},
+			{Email: "room@resource.calendar.google.com", Resource: true},
+		},
+	}
+	c := newConfirmation(confirmCreated, "primary", event, TimeFormat{Clock24: true})
+	if c.Attendees != 2 {
+		t.Errorf("attendees = %d, want 2 (rooms are not counted)", c.Attendees)
+	}
+	out := formatConfirmation(c, c, "", "Note one\n")
+	want := "✅ Created 'Sync' · Tue, Oct 20, 10:00 – 10:30 UTC · 2 attendees\n\nNote one\n\n{"
+	if !strings.HasPrefix(out, want) {
+		t.Errorf("formatConfirmation = %q, want prefix %q", out, want)
+	}
+	for _, field := range []string{`"action": "created"`, `"event_id": "ev1"`, `"start": "2026-10-20T10:00:00Z"`, `"etag": "\"3\""`} {
+		if !strings.Contains(out, field) {
+			t.Errorf("expected %s in the JSON payload, got:\n%s", field, out)
+		}
+	}
+}
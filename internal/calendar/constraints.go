@@ -0,0 +1,230 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+// Constraint is a recurring time the user is never available for meetings
+// or booked work, such as Friday afternoons, saved in the profile's
+// preferences. Slot finders treat every occurrence as busy.
+type Constraint struct {
+	Name     string   `json:"name"`
+	Days     []string `json:"days"`      // weekday names
+	Start    string   `json:"start"`     // HH:MM
+	End      string   `json:"end"`       // HH:MM
+	TimeZone string   `json:"time_zone"` // zone the days and times are in
+}
+
+// constraintName normalizes a constraint name to the key it is stored under.
+func constraintName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// validate checks a constraint's days, times and time zone.
+func (c Constraint) validate() error {
+	if len(c.Days) == 0 {
+		return fmt.Errorf("days is required, e.g. ['Friday']")
+	}
+	for _, day := range c.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return fmt.Errorf("invalid day %q", day)
+		}
+	}
+	start, err := parseClock(c.Start)
+	if err != nil {
+		return fmt.Errorf("start: %v", err)
+	}
+	end, err := parseClock(c.End)
+	if err != nil {
+		return fmt.Errorf("end: %v", err)
+	}
+	if end <= start {
+		return fmt.Errorf("end must be after start")
+	}
+	if _, err := loadLocation(c.TimeZone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %v", c.TimeZone, err)
+	}
+	return nil
+}
+
+// occurrences returns the constraint's spans that overlap [timeMin, timeMax).
+func (c Constraint) occurrences(timeMin, timeMax time.Time) []TimeSlot {
+	loc, err := loadLocation(c.TimeZone)
+	if err != nil {
+		return nil
+	}
+	start, err1 := parseClock(c.Start)
+	end, err2 := parseClock(c.End)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	days := make(map[time.Weekday]bool)
+	for _, name := range c.Days {
+		if d, ok := parseWeekday(name); ok {
+			days[d] = true
+		}
+	}
+
+	var slots []TimeSlot
+	for day := midnight(timeMin.In(loc)); day.Before(timeMax); day = day.AddDate(0, 0, 1) {
+		if !days[day.Weekday()] {
+			continue
+		}
+		slot := TimeSlot{Start: atClock(day, start), End: atClock(day, end)}
+		if slot.End.After(timeMin) && slot.Start.Before(timeMax) {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// constraints returns the profile's constraints sorted by name.
+func (ct *CalendarTools) constraints() []Constraint {
+	var constraints []Constraint
+	if ct.prefs == nil {
+		return constraints
+	}
+	for _, c := range ct.prefs.Get().Constraints {
+		constraints = append(constraints, c)
+	}
+	sort.Slice(constraints, func(i, j int) bool {
+		return constraintName(constraints[i].Name) < constraintName(constraints[j].Name)
+	})
+	return constraints
+}
+
+// constraintBusy returns every occurrence of the profile's constraints in
+// [timeMin, timeMax), for slot finders to add to the user's busy time.
+func (ct *CalendarTools) constraintBusy(timeMin, timeMax time.Time) []TimeSlot {
+	var busy []TimeSlot
+	for _, c := range ct.constraints() {
+		busy = append(busy, c.occurrences(timeMin, timeMax)...)
+	}
+	return busy
+}
+
+// describeConstraint renders a constraint as "Friday 13:00–17:00 (Europe/Paris)".
+func describeConstraint(c Constraint) string {
+	return fmt.Sprintf("%s %s–%s (%s)", strings.Join(c.Days, ", "), c.Start, c.End, c.TimeZone)
+}
+
+func (ct *CalendarTools) handleAddConstraint(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	name := strings.TrimSpace(getStringOrDefault(arguments, "name", ""))
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	days, err := stringArguments(arguments, "days")
+	if err != nil {
+		return nil, err
+	}
+	loc, err := ct.goalLocation(ct.calendarID(arguments), getStringOrDefault(arguments, "timezone", ""))
+	if err != nil {
+		return nil, err
+	}
+	constraint := Constraint{
+		Name:     name,
+		Days:     days,
+		Start:    getStringOrDefault(arguments, "start", ""),
+		End:      getStringOrDefault(arguments, "end", ""),
+		TimeZone: loc.String(),
+	}
+	if err := constraint.validate(); err != nil {
+		return nil, err
+	}
+
+	key := constraintName(name)
+	_, existed := ct.prefs.Get().Constraints[key]
+	if err := ct.prefs.Update(func(p *Preferences) {
+		constraints := make(map[string]Constraint, len(p.Constraints)+1)
+		for k, v := range p.Constraints {
+			constraints[k] = v
+		}
+		constraints[key] = constraint
+		p.Constraints = constraints
+	}); err != nil {
+		return nil, err
+	}
+
+	verb := "Added"
+	if existed {
+		verb = "Updated"
+	}
+	result := fmt.Sprintf("🚫 %s constraint '%s' in profile '%s': unavailable %s.\nshare_availability, find_recurring_slot, schedule_before, schedule_followup and schedule_goals will not offer or book this time.",
+		verb, constraint.Name, ct.prefs.Profile(), describeConstraint(constraint))
+	return structuredResult(result, constraint), nil
+}
+
+func (ct *CalendarTools) handleListConstraints(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	constraints := ct.constraints()
+
+	var result strings.Builder
+	profile := defaultProfile
+	if ct.prefs != nil {
+		profile = ct.prefs.Profile()
+	}
+	fmt.Fprintf(&result, "🚫 Availability constraints in profile '%s':\n\n", profile)
+	if len(constraints) == 0 {
+		result.WriteString("• None defined; create one with add_constraint\n")
+	}
+	for _, c := range constraints {
+		fmt.Fprintf(&result, "• %s: %s\n", c.Name, describeConstraint(c))
+	}
+	if constraints == nil {
+		constraints = []Constraint{}
+	}
+	return structuredResult(result.String(), map[string]interface{}{"constraints": constraints}), nil
+}
+
+func (ct *CalendarTools) handleRemoveConstraint(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	name := strings.TrimSpace(getStringOrDefault(arguments, "name", ""))
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	key := constraintName(name)
+	existing, existed := ct.prefs.Get().Constraints[key]
+	if !existed {
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{Type: "text", Text: fmt.Sprintf("ℹ️ No constraint '%s' in profile '%s'; nothing to remove.", name, ct.prefs.Profile())}},
+		}, nil
+	}
+	if err := ct.prefs.Update(func(p *Preferences) {
+		constraints := make(map[string]Constraint, len(p.Constraints))
+		for k, v := range p.Constraints {
+			constraints[k] = v
+		}
+		delete(constraints, key)
+		p.Constraints = constraints
+	}); err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: fmt.Sprintf("🗑️ Removed constraint '%s' (%s) from profile '%s'.", existing.Name, describeConstraint(existing), ct.prefs.Profile())}},
+	}, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConstraintOccurrences(t *testing.T) {
+	c := Constraint{Name: "Friday afternoons", Days: []string{"Friday"}, Start: "13:00", End: "17:00", TimeZone: "Europe/Paris"}
+	if err := c.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	// Two weeks from a Wednesday, with the clocks changing in between
+	from := time.Date(2025, 3, 26, 0, 0, 0, 0, time.UTC)
+	slots := c.occurrences(from, from.AddDate(0, 0, 14))
+	want := []time.Time{time.Date(2025, 3, 28, 13, 0, 0, 0, paris), time.Date(2025, 4, 4, 13, 0, 0, 0, paris)}
+	if len(slots) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %d", len(slots), slots, len(want))
+	}
+	for i, start := range want {
+		if !slots[i].Start.Equal(start) || slots[i].End.Sub(slots[i].Start) != 4*time.Hour {
+			t.Errorf("occurrence %d = %v–%v, want 4h from %v", i, slots[i].Start, slots[i].End, start)
+		}
+	}
+
+	// An occurrence already under way at timeMin still counts
+	mid := time.Date(2025, 3, 28, 15, 0, 0, 0, paris)
+	if slots := c.occurrences(mid, mid.Add(time.Hour)); len(slots) != 1 {
+		t.Errorf("occurrence under way: got %v", slots)
+	}
+
+	for _, bad := range []Constraint{
+		{Days: nil, Start: "13:00", End: "17:00", TimeZone: "UTC"},
+		{Days: []string{"Funday"}, Start: "13:00", End: "17:00", TimeZone: "UTC"},
+		{Days: []string{"Friday"}, Start: "17:00", End: "13:00", TimeZone: "UTC"},
+		{Days: []string{"Friday"}, Start: "13:00", End: "17:00", TimeZone: "Mars/Olympus"},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", bad)
+		}
+	}
+}
+
+func TestConstraintTools(t *testing.T) {
+	ct := NewCalendarTools(&Client{})
+	prefs, _ := LoadPreferenceStore("", "default")
+	ct.SetPreferences(prefs)
+
+	if _, err := ct.HandleTool("add_constraint", map[string]interface{}{
+		"name": "Friday afternoons", "days": []interface{}{"Friday"}, "start": "13:00", "end": "17:00", "timezone": "UTC",
+	}); err != nil {
+		t.Fatalf("add_constraint: %v", err)
+	}
+	result, err := ct.HandleTool("list_constraints", map[string]interface{}{})
+	if err != nil || !strings.Contains(result.Content[0].Text, "Friday afternoons: Friday 13:00–17:00 (UTC)") {
+		t.Fatalf("list_constraints: %v, %v", result, err)
+	}
+
+	// Slot finders see every occurrence as busy
+	friday := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	busy, err := ct.protectedBusy("primary", friday, friday.AddDate(0, 0, 7))
+	if err != nil || len(busy) != 1 || !busy[0].Start.Equal(friday.Add(13*time.Hour)) {
+		t.Errorf("protectedBusy = %v, %v", busy, err)
+	}
+	free := freeSlots(busy, friday, AvailabilityParams{Days: 1, Location: time.UTC, WorkStart: 9 * time.Hour, WorkEnd: 17 * time.Hour, MinDuration: availabilityStep})
+	if len(free) != 1 || !free[0].End.Equal(friday.Add(13*time.Hour)) {
+		t.Errorf("free slots on Friday = %v, want only the morning", free)
+	}
+
+	if _, err := ct.HandleTool("remove_constraint", map[string]interface{}{"name": "friday AFTERNOONS"}); err != nil {
+		t.Fatalf("remove_constraint: %v", err)
+	}
+	if busy := ct.constraintBusy(friday, friday.AddDate(0, 0, 7)); len(busy) != 0 {
+		t.Errorf("removed constraint still blocks %v", busy)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// With split_multi_day, list_events shows an event that spans several days,
+// including a timed event that crosses midnight, under each day it covers
+// rather than only under its first day, with a note saying which part of the
+// event that day holds.
+
+// dayEntry is an event listed under one day, with its spanNote for that day
+type dayEntry struct {
+	event *calendar.Event
+	span  string
+}
+
+// eventDays returns the days, in loc, that event covers: from its start day
+// to the day it ends, an end at midnight not counting that day. All-day
+// events cover their dates as they are.
+func eventDays(event *calendar.Event, loc *time.Location) []time.Time {
+	var first, last time.Time
+	switch {
+	case event.Start == nil || event.End == nil:
+		return nil
+	case event.Start.Date != "":
+		start, err := time.ParseInLocation(dateLayout, event.Start.Date, loc)
+		if err != nil {
+			return nil
+		}
+		end, err := time.ParseInLocation(dateLayout, allDayLastDate(event), loc)
+		if err != nil {
+			end = start
+		}
+		first, last = start, end
+	default:
+		start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+		if err != nil {
+			return nil
+		}
+		end, err := time.Parse(time.RFC3339, event.End.DateTime)
+		if err != nil || !end.After(start) {
+			end = start
+		} else {
+			end = end.Add(-time.Nanosecond)
+		}
+		first, last = startOfDay(start.In(loc)), startOfDay(end.In(loc))
+	}
+
+	var days []time.Time
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// dayInWindow reports whether any of day falls within [from, to); a zero
+// bound is open.
+func dayInWindow(day, from, to time.Time) bool {
+	return (from.IsZero() || day.AddDate(0, 0, 1).After(from)) && (to.IsZero() || day.Before(to))
+}
+
+// startOfDay returns midnight at the start of t's day, in t's location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// spanNote describes the part of a multi-day event shown under the day-th
+// (from 0) of the days it covers. It is "" for an event within one day.
+func spanNote(day, days int) string {
+	switch {
+	case days < 2:
+		return ""
+	case day == 0:
+		return fmt.Sprintf("➡️ Continues tomorrow (day 1 of %d)", days)
+	case day == days-1:
+		return fmt.Sprintf("⬅️ Continues from yesterday (day %d of %d)", day+1, days)
+	default:
+		return fmt.Sprintf("↔️ Continues from yesterday and tomorrow (day %d of %d)", day+1, days)
+	}
+}
+
+// addEventDays lists event under each day it covers within [from, to),
+// reporting false when it covers none, so the caller lists it by its start.
+func addEventDays(byDate map[string][]dayEntry, event *calendar.Event, loc *time.Location, from, to time.Time) bool {
+	days := eventDays(event, loc)
+	added := false
+	for i, day := range days {
+		if !dayInWindow(day, from, to) {
+			continue
+		}
+		date := day.Format(dateLayout)
+		byDate[date] = append(byDate[date], dayEntry{event: event, span: spanNote(i, len(days))})
+		added = true
+	}
+	return added
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- eventDays -----
+
+func TestEventDays(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timed := func(start, end string) *calendar.Event {
+		return &calendar.Event{Start: &calendar.EventDateTime{DateTime: start}, End: &calendar.EventDateTime{DateTime: end}}
+	}
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		loc   *time.Location
+		want  []string
+	}{
+		{"same day", timed("2025-03-03T10:00:00Z", "2025-03-03T11:00:00Z"), time.UTC, []string{"2025-03-03"}},
+		{"crosses midnight", timed("2025-03-03T22:00:00Z", "2025-03-04T02:00:00Z"), time.UTC, []string{"2025-03-03", "2025-03-04"}},
+		{"ends at midnight", timed("2025-03-03T22:00:00Z", "2025-03-04T00:00:00Z"), time.UTC, []string{"2025-03-03"}},
+		{"crosses midnight in the list's zone only", timed("2025-03-03T22:30:00Z", "2025-03-03T23:30:00Z"), berlin, []string{"2025-03-03", "2025-03-04"}},
+		{"three days", timed("2025-03-03T18:00:00-08:00", "2025-03-05T09:00:00-08:00"), time.UTC, []string{"2025-03-04", "2025-03-05"}},
+		{"all day", &calendar.Event{Start: &calendar.EventDateTime{Date: "2025-03-03"}, End: &calendar.EventDateTime{Date: "2025-03-06"}}, berlin, []string{"2025-03-03", "2025-03-04", "2025-03-05"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, day := range eventDays(tt.event, tt.loc) {
+			got = append(got, day.Format(dateLayout))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: eventDays = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// ----- formatEventsResult with split_multi_day -----
+
+func TestFormatEventsResult_SplitMultiDay(t *testing.T) {
+	client := &Client{}
+	client.SetDisplaySettings(DisplaySettings{Locale: "en", Clock: "24h"})
+	ct := NewCalendarTools(client)
+
+	events := &calendar.Events{Items: []*calendar.Event{
+		{Id: "night", Summary: "Night shift", Start: &calendar.EventDateTime{DateTime: "2025-03-03T22:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-05T06:00:00Z"}},
+		{Id: "standup", Summary: "Standup", Start: &calendar.EventDateTime{DateTime: "2025-03-04T09:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-04T09:15:00Z"}},
+	}}
+	params := ListEventsParams{
+		TimeFilter: "custom",
+		TimeMin:    time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		TimeMax:    time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC),
+		TimeZone:   "UTC",
+	}
+
+	// By default an event is only listed under its start day
+	text := ct.formatEventsResult(events, params)
+	if strings.Count(text, "### Night shift") != 1 || strings.Contains(text, "Continues") {
+		t.Errorf("without split_multi_day the night shift should appear once, unannotated:\n%s", text)
+	}
+
+	params.SplitMultiDay = true
+	text = ct.formatEventsResult(events, params)
+	if strings.Contains(text, "March 3") {
+		t.Errorf("days before the listed range should be left out:\n%s", text)
+	}
+	tuesday := text[strings.Index(text, "March 4"):strings.Index(text, "March 5")]
+	if !strings.Contains(tuesday, "### Night shift\n🕐 **Mar 3, 22:00 - Mar 5, 06:00**\n↔️ Continues from yesterday and tomorrow (day 2 of 3)") || !strings.Contains(tuesday, "### Standup") {
+		t.Errorf("Tuesday should hold the middle of the night shift and the standup:\n%s", tuesday)
+	}
+	if !strings.Contains(text[strings.Index(text, "March 5"):], "⬅️ Continues from yesterday (day 3 of 3)") {
+		t.Errorf("Wednesday should hold the end of the night shift:\n%s", text)
+	}
+	if !strings.Contains(text, "📊 Total: 2 events") {
+		t.Errorf("the total should count events, not days:\n%s", text)
+	}
+}
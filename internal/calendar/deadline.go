@@ -0,0 +1,282 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+const (
+	// defaultDeadlineBlockMinutes and defaultDeadlineMaxBlockMinutes bound
+	// the blocks schedule_before books when no lengths are given
+	defaultDeadlineBlockMinutes    = 60
+	defaultDeadlineMaxBlockMinutes = 120
+	// maxDeadlineDays bounds how far ahead a deadline may be
+	maxDeadlineDays = 31
+	// deadlineBreak is left free between blocks booked in the same free slot
+	deadlineBreak = availabilityStep
+)
+
+// DeadlineBlock is one block booked, or to be booked, for a task.
+type DeadlineBlock struct {
+	EventID  string `json:"event_id,omitempty"`
+	HTMLLink string `json:"html_link,omitempty"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+
+	slot TimeSlot
+}
+
+// DeadlinePlan is how schedule_before fits a task before its deadline.
+type DeadlinePlan struct {
+	CalendarID    string          `json:"calendar_id"`
+	Summary       string          `json:"summary"`
+	Deadline      string          `json:"deadline"`
+	TimeZone      string          `json:"time_zone"`
+	NeededMinutes int             `json:"needed_minutes"`
+	BookedMinutes int             `json:"booked_minutes"`
+	FreeMinutes   int             `json:"free_minutes"` // free working time before the deadline
+	DryRun        bool            `json:"dry_run"`
+	Blocks        []DeadlineBlock `json:"blocks"`
+	Errors        []string        `json:"errors,omitempty"`
+}
+
+// planDeadlineBlocks fills the free slots, earliest first, with blocks of
+// minBlock to maxBlock until need is covered, leaving deadlineBreak between
+// blocks taken from the same slot. The last block may be shorter than
+// minBlock when that is all the task still needs. It returns the blocks and
+// the time still needed when the slots run out.
+func planDeadlineBlocks(free []TimeSlot, need, minBlock, maxBlock time.Duration) ([]TimeSlot, time.Duration) {
+	var blocks []TimeSlot
+	remaining := need
+	for _, slot := range free {
+		cursor := slot.Start
+		for remaining > 0 {
+			// Blocks are whole steps, so the last one may run slightly over
+			wanted := (remaining + availabilityStep - 1) / availabilityStep * availabilityStep
+			length := min(slot.End.Sub(cursor), maxBlock, wanted)
+			if length <= 0 || (length < minBlock && length < wanted) {
+				break
+			}
+			blocks = append(blocks, TimeSlot{Start: cursor, End: cursor.Add(length)})
+			remaining -= length
+			cursor = cursor.Add(length + deadlineBreak)
+		}
+		if remaining <= 0 {
+			return blocks, 0
+		}
+	}
+	return blocks, remaining
+}
+
+// parseDeadline parses a deadline given as RFC3339 or as a date, which means
+// the start of that day in loc: work is booked before the day begins.
+func parseDeadline(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid deadline %q: use a date (YYYY-MM-DD) or an RFC3339 time", value)
+	}
+	return day, nil
+}
+
+func (ct *CalendarTools) handleScheduleBefore(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	summary := strings.TrimSpace(getStringOrDefault(arguments, "summary", ""))
+	if summary == "" {
+		return nil, fmt.Errorf("summary is required")
+	}
+	hours, ok := arguments["hours"].(float64)
+	if !ok || hours <= 0 {
+		return nil, fmt.Errorf("hours is required and must be positive")
+	}
+	need := time.Duration(hours*60+0.5) * time.Minute
+	minBlock := time.Duration(getIntOrDefault(arguments, "min_block_minutes", defaultDeadlineBlockMinutes)) * time.Minute
+	maxBlock := time.Duration(getIntOrDefault(arguments, "max_block_minutes", defaultDeadlineMaxBlockMinutes)) * time.Minute
+	if minBlock < availabilityStep || minBlock%availabilityStep != 0 || maxBlock%availabilityStep != 0 {
+		return nil, fmt.Errorf("min_block_minutes and max_block_minutes must be multiples of %d", int(availabilityStep/time.Minute))
+	}
+	if maxBlock < minBlock {
+		return nil, fmt.Errorf("max_block_minutes must not be less than min_block_minutes")
+	}
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+
+	calendarID := ct.calendarID(arguments)
+	loc, err := ct.goalLocation(calendarID, getStringOrDefault(arguments, "timezone", ""))
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := parseDeadline(getStringOrDefault(arguments, "deadline", ""), loc)
+	if err != nil {
+		return nil, err
+	}
+	deadline = deadline.In(loc)
+	now := time.Now().In(loc)
+	if !deadline.After(now) {
+		return nil, fmt.Errorf("the deadline %s has already passed", deadline.Format(time.RFC3339))
+	}
+	if deadline.After(now.AddDate(0, 0, maxDeadlineDays)) {
+		return nil, fmt.Errorf("the deadline must be within %d days", maxDeadlineDays)
+	}
+
+	params := AvailabilityParams{
+		Location:        loc,
+		MinDuration:     availabilityStep,
+		IncludeWeekends: getBoolOrDefault(arguments, "include_weekends", false),
+	}
+	if params.WorkStart, params.WorkEnd, err = ct.workHours(arguments); err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	params.Days = int(deadline.Sub(today)/(24*time.Hour)) + 1
+
+	response, err := ct.client.GetFreeBusy(FreeBusyParams{
+		TimeMin:     now,
+		TimeMax:     deadline,
+		TimeZone:    loc.String(),
+		CalendarIDs: []string{calendarID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get free/busy information: %v", err)
+	}
+	cal, ok := response.Calendars[calendarID]
+	if !ok {
+		return nil, fmt.Errorf("no free/busy information returned for calendar %s", calendarID)
+	}
+	if len(cal.Errors) > 0 {
+		return nil, fmt.Errorf("cannot read free/busy for calendar %s: %s", calendarID, cal.Errors[0].Reason)
+	}
+	protected, err := ct.protectedBusy(calendarID, now, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	// Free working time, cut off at the deadline
+	var free []TimeSlot
+	var freeTime time.Duration
+	for _, slot := range freeSlots(append(busySlots(cal), protected...), now, params) {
+		if !slot.Start.Before(deadline) {
+			break
+		}
+		if slot.End.After(deadline) {
+			slot.End = deadline
+		}
+		free = append(free, slot)
+		freeTime += slot.End.Sub(slot.Start)
+	}
+
+	tf := ct.client.TimeFormat()
+	slots, short := planDeadlineBlocks(free, need, minBlock, maxBlock)
+	if short > 0 {
+		return nil, fmt.Errorf("%s of '%s' does not fit before %s: only %s fits in blocks of at least %s in the %s of free working time left. Try including weekends, longer working hours, shorter blocks or a later deadline",
+			hoursOf(need), summary, formatDeadline(deadline, tf), hoursOf(need-short), hoursOf(minBlock), hoursOf(freeTime))
+	}
+
+	plan := DeadlinePlan{
+		CalendarID:    calendarID,
+		Summary:       summary,
+		Deadline:      deadline.Format(time.RFC3339),
+		TimeZone:      loc.String(),
+		NeededMinutes: int(need / time.Minute),
+		FreeMinutes:   int(freeTime / time.Minute),
+		DryRun:        dryRun,
+		Blocks:        []DeadlineBlock{},
+	}
+	description := getStringOrDefault(arguments, "description", "")
+	for i, slot := range slots {
+		block := DeadlineBlock{Start: slot.Start.Format(time.RFC3339), End: slot.End.Format(time.RFC3339), slot: slot}
+		plan.BookedMinutes += int(slot.End.Sub(slot.Start) / time.Minute)
+		if !dryRun {
+			title := summary
+			if len(slots) > 1 {
+				title = fmt.Sprintf("%s (%d/%d)", summary, i+1, len(slots))
+			}
+			event, err := ct.client.CreateEvent(EventParams{
+				CalendarID:  calendarID,
+				Summary:     title,
+				Description: deadlineDescription(description, need, deadline, tf),
+				StartTime:   slot.Start,
+				EndTime:     slot.End,
+				TimeZone:    loc.String(),
+			})
+			if err != nil {
+				plan.Errors = append(plan.Errors, fmt.Sprintf("failed to book %s, %s–%s: %v", tf.ShortDate(slot.Start), tf.Clock(slot.Start), tf.Clock(slot.End), err))
+				plan.BookedMinutes -= int(slot.End.Sub(slot.Start) / time.Minute)
+				continue
+			}
+			block.EventID = event.Id
+			block.HTMLLink = event.HtmlLink
+		}
+		plan.Blocks = append(plan.Blocks, block)
+	}
+
+	return structuredResult(formatDeadlinePlan(plan, deadline, tf), plan), nil
+}
+
+// deadlineDescription is the description of each block: the caller's
+// description, followed by what the block is for.
+func deadlineDescription(description string, need time.Duration, deadline time.Time, tf TimeFormat) string {
+	note := fmt.Sprintf("Booked by schedule_before: %s of work due %s.", hoursOf(need), formatDeadline(deadline, tf))
+	if description == "" {
+		return note
+	}
+	return description + "\n\n" + note
+}
+
+// hoursOf formats d as hours and minutes, like goal targets.
+func hoursOf(d time.Duration) string {
+	return formatGoalMinutes(int(d / time.Minute))
+}
+
+// formatDeadline shows a deadline at midnight as the day it falls before.
+func formatDeadline(deadline time.Time, tf TimeFormat) string {
+	if deadline.Equal(midnight(deadline)) {
+		return tf.ShortDate(deadline)
+	}
+	return fmt.Sprintf("%s, %s", tf.ShortDate(deadline), tf.Clock(deadline))
+}
+
+// formatDeadlinePlan renders the blocks of a plan, one per line.
+func formatDeadlinePlan(plan DeadlinePlan, deadline time.Time, tf TimeFormat) string {
+	var result strings.Builder
+	verb := "Booked"
+	if plan.DryRun {
+		verb = "Would book"
+	}
+	fmt.Fprintf(&result, "⏳ %s %d block(s), %s in total, for '%s' before %s (%s)",
+		verb, len(plan.Blocks), formatGoalMinutes(plan.BookedMinutes), plan.Summary, formatDeadline(deadline, tf), plan.TimeZone)
+	if plan.DryRun {
+		result.WriteString(" — dry run, nothing changed")
+	}
+	result.WriteString(":\n")
+	for _, b := range plan.Blocks {
+		fmt.Fprintf(&result, "\n• %s, %s–%s", tf.ShortDate(b.slot.Start), tf.Clock(b.slot.Start), tf.Clock(b.slot.End))
+		if b.EventID != "" {
+			fmt.Fprintf(&result, " (ID: %s)", b.EventID)
+		}
+	}
+	for _, e := range plan.Errors {
+		fmt.Fprintf(&result, "\n❌ %s", e)
+	}
+	fmt.Fprintf(&result, "\n\n%s of the %s of free working time before the deadline remains.", formatGoalMinutes(max(plan.FreeMinutes-plan.BookedMinutes, 0)), formatGoalMinutes(plan.FreeMinutes))
+	return result.String()
+}
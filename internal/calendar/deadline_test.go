@@ -0,0 +1,76 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanDeadlineBlocks(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(d int, clock string) time.Time {
+		offset, _ := parseClock(clock)
+		return atClock(day.AddDate(0, 0, d), offset)
+	}
+	free := []TimeSlot{
+		{Start: at(0, "09:00"), End: at(0, "09:30")}, // too short for a block
+		{Start: at(0, "13:00"), End: at(0, "17:00")},
+		{Start: at(1, "09:00"), End: at(1, "12:00")},
+	}
+
+	blocks, short := planDeadlineBlocks(free, 5*time.Hour, time.Hour, 2*time.Hour)
+	if short != 0 {
+		t.Fatalf("short by %v", short)
+	}
+	want := []TimeSlot{
+		{Start: at(0, "13:00"), End: at(0, "15:00")},
+		{Start: at(0, "15:30"), End: at(0, "17:00")}, // after a break, to the end of the slot
+		{Start: at(1, "09:00"), End: at(1, "10:30")},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks %v, want %v", len(blocks), blocks, want)
+	}
+	for i := range want {
+		if !blocks[i].Start.Equal(want[i].Start) || !blocks[i].End.Equal(want[i].End) {
+			t.Errorf("block %d = %v–%v, want %v–%v", i, blocks[i].Start, blocks[i].End, want[i].Start, want[i].End)
+		}
+	}
+
+	// The last block may be shorter than the minimum when it finishes the task
+	blocks, short = planDeadlineBlocks(free, 20*time.Minute, time.Hour, 2*time.Hour)
+	if short != 0 || len(blocks) != 1 || !blocks[0].Start.Equal(at(0, "09:00")) || blocks[0].End.Sub(blocks[0].Start) != 30*time.Minute {
+		t.Errorf("short task: got %v, short %v", blocks, short)
+	}
+
+	if _, short := planDeadlineBlocks(free, 10*time.Hour, time.Hour, 2*time.Hour); short != 4*time.Hour+30*time.Minute {
+		t.Errorf("too much work: short %v, want 4h30m", short)
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	got, err := parseDeadline("2025-03-14", loc)
+	if err != nil || !got.Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, loc)) {
+		t.Errorf("date: got %v, %v", got, err)
+	}
+	got, err = parseDeadline("2025-03-14T12:00:00Z", loc)
+	if err != nil || !got.Equal(time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC3339: got %v, %v", got, err)
+	}
+	if _, err := parseDeadline("Friday", loc); err == nil {
+		t.Error("expected an invalid deadline to fail")
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// DeclineAllOptions selects which meetings decline_all leaves alone.
+type DeclineAllOptions struct {
+	SkipOneOnOnes  bool
+	SkipOrganizers []string // matched with matchesOrganizer
+}
+
+// DeclinedMeeting is a meeting decline_all declined, would decline in a dry
+// run, or failed to decline (Error).
+type DeclinedMeeting struct {
+	EventID   string `json:"event_id"`
+	Summary   string `json:"summary"`
+	Start     string `json:"start"`
+	Organizer string `json:"organizer,omitempty"`
+	Reason    string `json:"reason,omitempty"` // why it was skipped
+	Error     string `json:"error,omitempty"`
+
+	event *calendar.Event
+}
+
+// invitedAttendee returns the user's entry in the event's attendee list, or
+// nil if the user was not invited.
+func invitedAttendee(e *calendar.Event, calendarID string) *calendar.EventAttendee {
+	for _, a := range e.Attendees {
+		if a.Self || strings.EqualFold(a.Email, calendarID) {
+			return a
+		}
+	}
+	return nil
+}
+
+// isOneOnOne reports whether exactly two people are invited to e. Meeting
+// rooms do not count as people.
+func isOneOnOne(e *calendar.Event) bool {
+	people := 0
+	for _, a := range e.Attendees {
+		if !a.Resource {
+			people++
+		}
+	}
+	return people == 2
+}
+
+func newDeclinedMeeting(e *calendar.Event, loc *time.Location) DeclinedMeeting {
+	m := DeclinedMeeting{EventID: e.Id, Summary: eventTitle(e), event: e}
+	if start, _, _, err := parseEventTimes(e); err == nil {
+		m.Start = start.In(loc).Format(time.RFC3339)
+	}
+	if e.Organizer != nil {
+		m.Organizer = formatPerson(e.Organizer.Email, e.Organizer.DisplayName, false)
+	}
+	return m
+}
+
+// planDeclineAll splits events into the meetings to decline and the ones
+// skipped with a reason. Events the user organizes or was not invited to,
+// and cancelled events, are not meetings to decline and are left out of both.
+func planDeclineAll(events []*calendar.Event, calendarID string, opts DeclineAllOptions, loc *time.Location) (decline, skipped []DeclinedMeeting) {
+	decline, skipped = []DeclinedMeeting{}, []DeclinedMeeting{}
+	for _, e := range events {
+		self := invitedAttendee(e, calendarID)
+		if e.Status == "cancelled" || self == nil || self.Organizer || (e.Organizer != nil && e.Organizer.Self) {
+			continue
+		}
+		m := newDeclinedMeeting(e, loc)
+		switch {
+		case self.ResponseStatus == "declined":
+			m.Reason = "already declined"
+		case opts.SkipOneOnOnes && isOneOnOne(e):
+			m.Reason = "1:1"
+		default:
+			for _, who := range opts.SkipOrganizers {
+				if matchesOrganizer(e, who) {
+					m.Reason = fmt.Sprintf("organized by %s", who)
+					break
+				}
+			}
+		}
+		if m.Reason != "" {
+			skipped = append(skipped, m)
+			continue
+		}
+		decline = append(decline, m)
+	}
+	return decline, skipped
+}
+
+// eventsInRange lists every event instance on calendarID in [timeMin,
+// timeMax), page by page, so a long range is never cut off at the first page.
+func (c *Client) eventsInRange(calendarID string, timeMin, timeMax time.Time) ([]*calendar.Event, error) {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	var events []*calendar.Event
+	call := c.service.Events.List(calendarID).
+		TimeMin(timeMin.Format(time.RFC3339)).
+		TimeMax(timeMax.Format(time.RFC3339)).
+		SingleEvents(true).
+		OrderBy("startTime").
+		AlwaysIncludeEmail(true).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events: %v", err)
+		}
+		events = append(events, page.Items...)
+		if page.NextPageToken == "" {
+			return events, nil
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+}
+
+// DeclineEvent sets the user's response to event, as read from calendarID,
+// to declined with comment as the message to the organizer. The rest of the
+// attendee list is sent back unchanged; when the API omitted it, only the
+// user's entry is sent, which the API accepts as a response update. The write
+// is conditioned on the event's etag so a change since it was read is not
+// overwritten.
+func (c *Client) DeclineEvent(calendarID string, event *calendar.Event, comment, sendUpdates string) (*calendar.Event, error) {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	patch := &calendar.Event{AttendeesOmitted: event.AttendeesOmitted}
+	for _, a := range event.Attendees {
+		attendee := *a
+		if a.Self || strings.EqualFold(a.Email, calendarID) {
+			attendee.ResponseStatus = "declined"
+			if comment != "" {
+				attendee.Comment = comment
+			}
+		}
+		patch.Attendees = append(patch.Attendees, &attendee)
+	}
+
+	call := c.service.Events.Patch(calendarID, event.Id, patch)
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	if event.Etag != "" {
+		call.Header().Set("If-Match", event.Etag)
+	}
+	updated, err := call.Do()
+	if err != nil {
+		return nil, c.conflictError(calendarID, event.Id, event.Etag, err)
+	}
+	return updated, nil
+}
+
+func (ct *CalendarTools) handleDeclineAll(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	timeMinStr := getStringOrDefault(arguments, "time_min", "")
+	if timeMinStr == "" {
+		return nil, fmt.Errorf("time_min is required")
+	}
+	timeMaxStr := getStringOrDefault(arguments, "time_max", "")
+	if timeMaxStr == "" {
+		return nil, fmt.Errorf("time_max is required")
+	}
+	timeMin, err := time.Parse(time.RFC3339, timeMinStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_min format: %v", err)
+	}
+	timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_max format: %v", err)
+	}
+	if !timeMax.After(timeMin) {
+		return nil, fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+	skipOrganizers, err := stringArguments(arguments, "skip_organizers")
+	if err != nil {
+		return nil, err
+	}
+	opts := DeclineAllOptions{
+		SkipOneOnOnes:  getBoolOrDefault(arguments, "skip_one_on_ones", false),
+		SkipOrganizers: skipOrganizers,
+	}
+	message := strings.TrimSpace(getStringOrDefault(arguments, "message", ""))
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+	// Declining tells the organizer by default, like answering the invite in Calendar
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarID := ct.calendarID(arguments)
+	events, err := ct.client.eventsInRange(calendarID, timeMin, timeMax)
+	if err != nil {
+		return nil, err
+	}
+
+	decline, skipped := planDeclineAll(events, calendarID, opts, loc)
+	declined, failed := []DeclinedMeeting{}, []DeclinedMeeting{}
+	for _, m := range decline {
+		if dryRun {
+			declined = append(declined, m)
+			continue
+		}
+		if _, err := ct.client.DeclineEvent(calendarID, m.event, message, sendUpdates); err != nil {
+			m.Error = err.Error()
+			failed = append(failed, m)
+			continue
+		}
+		declined = append(declined, m)
+	}
+
+	tf := ct.client.TimeFormat()
+	when := func(m DeclinedMeeting) string {
+		start, err := time.Parse(time.RFC3339, m.Start)
+		if err != nil {
+			return "(no start time)"
+		}
+		return fmt.Sprintf("%s, %s", tf.ShortDate(start), tf.Clock(start))
+	}
+
+	var result strings.Builder
+	verb := "Declined"
+	if dryRun {
+		verb = "Would decline"
+	}
+	fmt.Fprintf(&result, "🙅 %s %d meeting(s) from %s to %s (%s)", verb, len(declined),
+		timeMin.In(loc).Format("2006-01-02"), timeMax.In(loc).Format("2006-01-02"), loc.String())
+	if dryRun {
+		result.WriteString(" — dry run, nothing changed")
+	} else if len(declined) > 0 {
+		fmt.Fprintf(&result, "; %s", describeSendUpdates(sendUpdates))
+	}
+	result.WriteString(":\n")
+	for _, m := range declined {
+		fmt.Fprintf(&result, "• %s — %s", when(m), m.Summary)
+		if m.Organizer != "" {
+			fmt.Fprintf(&result, " (organizer: %s)", m.Organizer)
+		}
+		result.WriteString("\n")
+	}
+	if message != "" && len(declined) > 0 {
+		fmt.Fprintf(&result, "\n💬 Message: %s\n", message)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&result, "\n⏭️ Skipped %d:\n", len(skipped))
+		for _, m := range skipped {
+			fmt.Fprintf(&result, "• %s — %s (%s)\n", when(m), m.Summary, m.Reason)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&result, "\n❌ Failed to decline %d:\n", len(failed))
+		for _, m := range failed {
+			fmt.Fprintf(&result, "• %s — %s: %s\n", when(m), m.Summary, m.Error)
+		}
+	}
+
+	return structuredResult(result.String(), map[string]interface{}{
+		"calendar_id":  calendarID,
+		"dry_run":      dryRun,
+		"send_updates": sendUpdates,
+		"declined":     declined,
+		"skipped":      skipped,
+		"failed":       failed,
+	}), nil
+}
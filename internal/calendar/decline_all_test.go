@@ -0,0 +1,143 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- planDeclineAll -----
+
+func TestPlanDeclineAll(t *testing.T) {
+	meeting := func(id, organizer, response string, others ...string) *calendar.Event {
+		e := &calendar.Event{
+			Id:        id,
+			Summary:   id,
+			Start:     &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"},
+			End:       &calendar.EventDateTime{DateTime: "2025-03-03T10:00:00Z"},
+			Organizer: &calendar.EventOrganizer{Email: organizer, Self: organizer == "me@example.com"},
+			Attendees: []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: response}},
+		}
+		for _, email := range append([]string{organizer}, others...) {
+			if email != "me@example.com" {
+				e.Attendees = append(e.Attendees, &calendar.EventAttendee{Email: email})
+			}
+		}
+		return e
+	}
+	room := meeting("room-1on1", "bob@example.com", "accepted")
+	room.Attendees = append(room.Attendees, &calendar.EventAttendee{Email: "room@resource.calendar.google.com", Resource: true})
+	cancelled := meeting("cancelled", "bob@example.com", "accepted", "carol@example.com")
+	cancelled.Status = "cancelled"
+	notInvited := meeting("not-invited", "bob@example.com", "")
+	notInvited.Attendees = notInvited.Attendees[1:]
+	allHands := meeting("all-hands", "pat@example.com", "accepted", "carol@example.com")
+	allHands.Organizer.DisplayName = "Pat Lee"
+
+	events := []*calendar.Event{
+		meeting("team", "bob@example.com", "needsAction", "carol@example.com"),
+		meeting("1on1", "bob@example.com", "accepted"),
+		room,
+		allHands,
+		meeting("declined", "bob@example.com", "declined", "carol@example.com"),
+		meeting("mine", "me@example.com", "accepted", "carol@example.com"),
+		cancelled,
+		notInvited,
+	}
+	opts := DeclineAllOptions{SkipOneOnOnes: true, SkipOrganizers: []string{"pat lee"}}
+	decline, skipped := planDeclineAll(events, "primary", opts, time.UTC)
+
+	if len(decline) != 1 || decline[0].EventID != "team" {
+		t.Fatalf("decline = %+v, want only team", decline)
+	}
+	if decline[0].Start != "2025-03-03T09:00:00Z" || decline[0].Organizer != "bob@example.com" {
+		t.Errorf("decline[0] = %+v", decline[0])
+	}
+	wantSkipped := map[string]string{
+		"1on1":      "1:1",
+		"room-1on1": "1:1",
+		"all-hands": "organized by pat lee",
+		"declined":  "already declined",
+	}
+	if len(skipped) != len(wantSkipped) {
+		t.Fatalf("skipped = %+v", skipped)
+	}
+	for _, m := range skipped {
+		if m.Reason != wantSkipped[m.EventID] {
+			t.Errorf("%s skipped for %q, want %q", m.EventID, m.Reason, wantSkipped[m.EventID])
+		}
+	}
+
+	// Without options every invitation still open is declined
+	decline, _ = planDeclineAll(events, "primary", DeclineAllOptions{}, time.UTC)
+	if len(decline) != 4 {
+		t.Errorf("declined %d meetings without options, want 4", len(decline))
+	}
+}
+
+// ----- decline_all -----
+
+func TestDeclineAll_FollowsPages(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	// More invites than fit on one page of the events list
+	const invites = 260
+	start := time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < invites; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		if _, err := store.InsertEvent("primary", &calendar.Event{
+			Summary:   fmt.Sprintf("Invite %d", i),
+			Start:     &calendar.EventDateTime{DateTime: at.Format(time.RFC3339)},
+			End:       &calendar.EventDateTime{DateTime: at.Add(30 * time.Minute).Format(time.RFC3339)},
+			Organizer: &calendar.EventOrganizer{Email: "alex@example.com"},
+			Attendees: []*calendar.EventAttendee{{Email: "alex@example.com"}, {Email: fake.DemoOwner}},
+		}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ct.handleDeclineAll(map[string]interface{}{
+		"time_min":     start.Format(time.RFC3339),
+		"time_max":     start.AddDate(0, 0, 14).Format(time.RFC3339),
+		"send_updates": "none",
+	})
+	if err != nil {
+		t.Fatalf("handleDeclineAll() error: %v", err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, fmt.Sprintf("Declined %d meeting(s)", invites)) {
+		t.Fatalf("want all %d invites declined:\n%.300s", invites, text)
+	}
+	events, err := store.ListEvents("primary", fake.EventQuery{TimeMin: start, TimeMax: start.AddDate(0, 0, 14)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range events {
+		if self := invitedAttendee(e, fake.DemoOwner); self == nil || self.ResponseStatus != "declined" {
+			t.Fatalf("'%s' was not declined", e.Summary)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// maxDescriptionLength is the longest event description, in characters,
+	// sent to the Calendar API; longer ones are rejected
+	maxDescriptionLength = 8192
+	// offloadPreviewLength is how much of an offloaded description stays on
+	// the event, ahead of the link to the full text
+	offloadPreviewLength = 1000
+
+	googleDocMimeType = "application/vnd.google-apps.document"
+)
+
+// AttachmentParams is a file attached to an event, such as a Google Doc.
+type AttachmentParams struct {
+	FileURL  string `json:"file_url"`
+	Title    string `json:"title,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+}
+
+// eventAttachments converts attachment parameters for the API.
+func eventAttachments(attachments []AttachmentParams) []*calendar.EventAttachment {
+	converted := make([]*calendar.EventAttachment, 0, len(attachments))
+	for _, a := range attachments {
+		converted = append(converted, &calendar.EventAttachment{FileUrl: a.FileURL, Title: a.Title, MimeType: a.MimeType, FileId: a.FileID})
+	}
+	return converted
+}
+
+// attachmentParams returns an event's attachments as parameters, so a patch
+// adding one keeps the others.
+func attachmentParams(event *calendar.Event) []AttachmentParams {
+	params := make([]AttachmentParams, 0, len(event.Attachments))
+	for _, a := range event.Attachments {
+		params = append(params, AttachmentParams{FileURL: a.FileUrl, Title: a.Title, MimeType: a.MimeType, FileID: a.FileId})
+	}
+	return params
+}
+
+// DescriptionOffload records a description too long for an event that was
+// moved to a Google Doc.
+type DescriptionOffload struct {
+	Characters  int      `json:"characters"`
+	DocID       string   `json:"doc_id"`
+	DocTitle    string   `json:"doc_title"`
+	DocURL      string   `json:"doc_url"`
+	SharedWith  []string `json:"shared_with,omitempty"`
+	ShareErrors []string `json:"share_errors,omitempty"`
+}
+
+// attachment returns the doc as an event attachment.
+func (o *DescriptionOffload) attachment() AttachmentParams {
+	return AttachmentParams{FileURL: o.DocURL, Title: o.DocTitle, MimeType: googleDocMimeType, FileID: o.DocID}
+}
+
+// note reports the split to the caller.
+func (o *DescriptionOffload) note() string {
+	note := fmt.Sprintf("📄 The description was %d characters, over the %d an event holds: the full text is in the Google Doc '%s' (%s), attached to the event, and the description keeps the first part with a link to it.",
+		o.Characters, maxDescriptionLength, o.DocTitle, o.DocURL)
+	if len(o.SharedWith) > 0 {
+		note += fmt.Sprintf(" Attendees who can view the doc: %s.", strings.Join(o.SharedWith, ", "))
+	}
+	if len(o.ShareErrors) > 0 {
+		note += "\n⚠️ Not shared: " + strings.Join(o.ShareErrors, "; ")
+	}
+	return note
+}
+
+// descriptionPreview returns the start of description, at most limit
+// characters, cut at the last paragraph or line break when there is one in
+// its second half.
+func descriptionPreview(description string, limit int) string {
+	if utf8.RuneCountInString(description) <= limit {
+		return description
+	}
+	preview := string([]rune(description)[:limit])
+	for _, sep := range []string{"\n\n", "\n"} {
+		if i := strings.LastIndex(preview, sep); i >= len(preview)/2 {
+			return strings.TrimRight(preview[:i], " \t\n")
+		}
+	}
+	return strings.TrimRight(preview, " \t\n") + "…"
+}
+
+// offloadedDescription is the description left on an event whose full text
+// moved to docURL.
+func offloadedDescription(description, docURL string) string {
+	return fmt.Sprintf("%s\n\n📄 Full description (%d characters): %s",
+		descriptionPreview(description, offloadPreviewLength), utf8.RuneCountInString(description), docURL)
+}
+
+// CreateDescriptionDoc saves an event description as a Google Doc in the
+// user's Drive and lets readers view it, without emailing them. Sharing
+// failures are recorded rather than returned, since the doc exists by then.
+// It needs the drive.file scope, which is asked for the first time.
+func (c *Client) CreateDescriptionDoc(title, description string, readers []string) (*DescriptionOffload, error) {
+	doc, err := c.createSharedDoc(title, description, "text/plain", "reader", readers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a Google Doc for the description: %v", err)
+	}
+	return &DescriptionOffload{
+		Characters:  utf8.RuneCountInString(description),
+		DocID:       doc.id,
+		DocTitle:    doc.title,
+		DocURL:      doc.url,
+		SharedWith:  doc.sharedWith,
+		ShareErrors: doc.shareErrors,
+	}, nil
+}
+
+// sharedDoc is a Google Doc created by createSharedDoc.
+type sharedDoc struct {
+	id, title, url string
+	sharedWith     []string
+	shareErrors    []string
+}
+
+// createSharedDoc converts content of contentType ("text/plain" or
+// "text/markdown") to a Google Doc in the user's Drive and gives each of
+// people the role ("reader" or "writer") without emailing them. Sharing
+// failures are recorded rather than returned, since the doc exists by then.
+func (c *Client) createSharedDoc(title, content, contentType, role string, people []string) (*sharedDoc, error) {
+	if err := c.requireScopes(drive.DriveFileScope); err != nil {
+		return nil, err
+	}
+	file, err := c.driveService.Files.Create(&drive.File{Name: title, MimeType: googleDocMimeType}).
+		Media(strings.NewReader(content), googleapi.ContentType(contentType)).
+		Fields("id,name,webViewLink").
+		Do()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &sharedDoc{id: file.Id, title: file.Name, url: file.WebViewLink}
+	if doc.url == "" {
+		doc.url = "https://docs.google.com/document/d/" + file.Id + "/edit"
+	}
+	for _, email := range people {
+		_, err := c.driveService.Permissions.Create(file.Id, &drive.Permission{Type: "user", Role: role, EmailAddress: email}).
+			SendNotificationEmail(false).
+			Do()
+		if err != nil {
+			doc.shareErrors = append(doc.shareErrors, fmt.Sprintf("%s: %v", email, err))
+			continue
+		}
+		doc.sharedWith = append(doc.sharedWith, email)
+	}
+	return doc, nil
+}
+
+// descriptionReaders returns the attendees to share an offloaded description
+// with: everyone but the user and meeting rooms.
+func (c *Client) descriptionReaders(emails []string) []string {
+	self, _ := c.getUserEmail()
+	var readers []string
+	for _, email := range emails {
+		if strings.EqualFold(email, self) || strings.HasSuffix(strings.ToLower(email), "@resource.calendar.google.com") {
+			continue
+		}
+		readers = append(readers, email)
+	}
+	return readers
+}
+
+// offloadDescription checks a description against maxDescriptionLength.
+// One that fits is returned unchanged. A longer one is refused unless the
+// caller passed offload_description, in which case the full text is saved as
+// a Google Doc shared with attendees, and the returned description keeps its
+// start and a link to the doc.
+func (ct *CalendarTools) offloadDescription(arguments map[string]interface{}, summary, description string, attendees []string) (string, *DescriptionOffload, error) {
+	length := utf8.RuneCountInString(description)
+	if length <= maxDescriptionLength {
+		return description, nil, nil
+	}
+	if !getBoolOrDefault(arguments, "offload_description", false) {
+		return "", nil, fmt.Errorf("description is %d characters, over the %d an event holds; shorten it, or pass offload_description: true to move the full text to a Google Doc linked from the event", length, maxDescriptionLength)
+	}
+	if summary == "" {
+		summary = "Untitled event"
+	}
+	offload, err := ct.client.CreateDescriptionDoc(summary+" — description", description, ct.client.descriptionReaders(attendees))
+	if err != nil {
+		return "", nil, err
+	}
+	return offloadedDescription(description, offload.DocURL), offload, nil
+}
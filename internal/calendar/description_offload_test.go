@@ -0,0 +1,53 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescriptionPreview(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		limit       int
+		want        string
+	}{
+		{"short text unchanged", "Agenda", 20, "Agenda"},
+		{"cut at paragraph", "First paragraph.\n\nSecond paragraph runs long.", 30, "First paragraph."},
+		{"cut at line", "Line one here\nLine two runs long", 20, "Line one here"},
+		{"ellipsis without breaks", "one long sentence with no breaks", 8, "one long…"},
+		{"early break ignored", "a\n\nvery long second paragraph", 20, "a\n\nvery long second…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := descriptionPreview(tt.description, tt.limit); got != tt.want {
+				t.Errorf("descriptionPreview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOffloadedDescription(t *testing.T) {
+	description := strings.Repeat("Notes for the review. ", 600)
+	got := offloadedDescription(description, "https://docs.google.com/document/d/doc-1/edit")
+	if !strings.HasSuffix(got, "📄 Full description (13200 characters): https://docs.google.com/document/d/doc-1/edit") {
+		t.Errorf("missing doc link: %q", got[len(got)-120:])
+	}
+	if len(got) > offloadPreviewLength+200 {
+		t.Errorf("offloaded description is %d bytes, want a short preview", len(got))
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// FieldChange is one field that differs between two versions of an event.
+// Old and New are strings, or string lists for list-valued fields.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// EventDiff records how an edit changed an event. It carries enough to revert
+// the change: the event's identity plus every changed field's previous value.
+type EventDiff struct {
+	CalendarID string        `json:"calendar_id"`
+	EventID    string        `json:"event_id"`
+	ETag       string        `json:"etag,omitempty"` // etag of the updated event, for a follow-up conditional edit
+	HTMLLink   string        `json:"html_link,omitempty"`
+	Summary    string        `json:"summary"`
+	When       string        `json:"when,omitempty"` // when the event now takes place, in its time zone
+	Attendees  int           `json:"attendees"`
+	Scope      string        `json:"scope,omitempty"` // which occurrences of a recurring event were modified
+	Changes    []FieldChange `json:"changes"`
+
+	// Conference is set when a Meet link was created or its settings changed
+	Conference *ConferenceInfo `json:"conference,omitempty"`
+}
+
+// diffFields lists the user-visible event fields compared by diffEvents, in
+// display order. Each extractor normalizes the field so that equal values
+// compare equal regardless of how the API omitted empty ones.
+var diffFields = []struct {
+	name string
+	get  func(*calendar.Event) interface{}
+}{
+	{"summary", func(e *calendar.Event) interface{} { return e.Summary }},
+	{"start", func(e *calendar.Event) interface{} { return formatDiffTime(e.Start) }},
+	{"end", func(e *calendar.Event) interface{} { return formatDiffTime(e.End) }},
+	{"location", func(e *calendar.Event) interface{} { return e.Location }},
+	{"description", func(e *calendar.Event) interface{} { return e.Description }},
+	{"attendees", diffAttendees},
+	{"recurrence", func(e *calendar.Event) interface{} { return append([]string{}, e.Recurrence...) }},
+	{"conference", diffConference},
+	{"reminders", diffReminders},
+	{"color_id", func(e *calendar.Event) interface{} { return e.ColorId }},
+	{"visibility", func(e *calendar.Event) interface{} { return e.Visibility }},
+	{"anyone_can_add_self", func(e *calendar.Event) interface{} { return e.AnyoneCanAddSelf }},
+	{"transparency", func(e *calendar.Event) interface{} { return e.Transparency }},
+	{"status", func(e *calendar.Event) interface{} { return e.Status }},
+	{"followup_of", func(e *calendar.Event) interface{} { return followupOf(e) }},
+	{"priority", func(e *calendar.Event) interface{} { return eventPriorityLevel(e) }},
+	{"source", func(e *calendar.Event) interface{} { return describeSource(e) }},
+}
+
+// diffEvents returns the fields that differ between before and after. The
+// result is never nil, so an edit that changed nothing reports "changes": [].
+func diffEvents(before, after *calendar.Event) []FieldChange {
+	changes := []FieldChange{}
+	if before == nil || after == nil {
+		return changes
+	}
+	for _, f := range diffFields {
+		oldVal, newVal := f.get(before), f.get(after)
+		if !reflect.DeepEqual(oldVal, newVal) {
+			changes = append(changes, FieldChange{Field: f.name, Old: oldVal, New: newVal})
+		}
+	}
+	return changes
+}
+
+func formatDiffTime(dt *calendar.EventDateTime) string {
+	if dt == nil {
+		return ""
+	}
+	if dt.Date != "" {
+		return dt.Date + " (all day)"
+	}
+	if dt.TimeZone != "" {
+		return dt.DateTime + " " + dt.TimeZone
+	}
+	return dt.DateTime
+}
+
+// diffAttendees returns sorted "email (responseStatus)" entries, marked
+// optional where they are, followed by the attendee's RSVP comment if any.
+func diffAttendees(e *calendar.Event) interface{} {
+	attendees := []string{}
+	for _, a := range e.Attendees {
+		entry := strings.ToLower(a.Email)
+		if a.ResponseStatus != "" {
+			entry += " (" + a.ResponseStatus + ")"
+		}
+		if a.Optional {
+			entry += " optional"
+		}
+		if a.Comment != "" {
+			entry += fmt.Sprintf(": %q", a.Comment)
+		}
+		attendees = append(attendees, entry)
+	}
+	sort.Strings(attendees)
+	return attendees
+}
+
+func diffConference(e *calendar.Event) interface{} {
+	return meetLink(e)
+}
+
+func diffReminders(e *calendar.Event) interface{} {
+	if e.Reminders == nil {
+		return ""
+	}
+	if e.Reminders.UseDefault {
+		return "default"
+	}
+	var parts []string
+	for _, r := range e.Reminders.Overrides {
+		parts = append(parts, fmt.Sprintf("%s %dm", r.Method, r.Minutes))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// formatEventDiff renders an edit result as a confirmation line and a readable
+// change list, followed by the structured diff.
+func formatEventDiff(diff EventDiff) string {
+	var result strings.Builder
+
+	title := diff.Summary
+	if title == "" {
+		title = "(No Title)"
+	}
+	result.WriteString(confirmationLine(confirmUpdated, title, diff.When, diff.Attendees) + "\n")
+	if len(diff.Changes) == 0 {
+		result.WriteString("No visible fields changed\n")
+	} else {
+		fmt.Fprintf(&result, "%d field(s) changed:\n", len(diff.Changes))
+		for _, c := range diff.Changes {
+			fmt.Fprintf(&result, "• %s: %s\n", c.Field, describeChange(c))
+		}
+	}
+	if diff.Scope != "" {
+		fmt.Fprintf(&result, "\n🔁 Modified %s\n", diff.Scope)
+	}
+	if diff.HTMLLink != "" {
+		fmt.Fprintf(&result, "\n🌐 Open in Calendar: %s\n", diff.HTMLLink)
+	}
+
+	diffJSON, _ := json.MarshalIndent(diff, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(diffJSON))
+	return result.String()
+}
+
+// describeChange summarizes one change as "old → new", or as added/removed
+// entries for list-valued fields.
+func describeChange(c FieldChange) string {
+	oldList, oldIsList := c.Old.([]string)
+	newList, newIsList := c.New.([]string)
+	if oldIsList && newIsList {
+		var parts []string
+		for _, v := range newList {
+			if !containsString(oldList, v) {
+				parts = append(parts, "+"+v)
+			}
+		}
+		for _, v := range oldList {
+			if !containsString(newList, v) {
+				parts = append(parts, "-"+v)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf("%s → %s", quoteOrEmpty(c.Old), quoteOrEmpty(c.New))
+}
+
+func quoteOrEmpty(v interface{}) string {
+	s, _ := v.(string)
+	if s == "" {
+		return "(empty)"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- diffEvents -----
+
+func TestDiffEvents(t *testing.T) {
+	before := &calendar.Event{
+		Summary: "Sync",
+		Start:   &calendar.EventDateTime{DateTime: "2025-01-15T10:00:00Z"},
+		End:     &calendar.EventDateTime{DateTime: "2025-01-15T10:30:00Z"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "a@example.com", ResponseStatus: "accepted"},
+			{Email: "b@example.com", ResponseStatus: "needsAction"},
+		},
+	}
+	after := &calendar.Event{
+		Summary: "Weekly Sync",
+		Start:   &calendar.EventDateTime{DateTime: "2025-01-15T10:00:00Z"},
+		End:     &calendar.EventDateTime{DateTime: "2025-01-15T11:00:00Z"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "a@example.com", ResponseStatus: "accepted"},
+			{Email: "c@example.com", ResponseStatus: "needsAction"},
+		},
+		Kind: "calendar#event", // not a user-visible field
+	}
+
+	changes := diffEvents(before, after)
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		fields[i] = c.Field
+	}
+	if got := strings.Join(fields, ","); got != "summary,end,attendees" {
+		t.Fatalf("changed fields = %q, want summary,end,attendees", got)
+	}
+	if changes[0].Old != "Sync" || changes[0].New != "Weekly Sync" {
+		t.Errorf("unexpected summary change %+v", changes[0])
+	}
+	if got := describeChange(changes[2]); got != "+c@example.com (needsAction), -b@example.com (needsAction)" {
+		t.Errorf("unexpected attendee description %q", got)
+	}
+}
+
+func TestDiffEvents_NoChanges(t *testing.T) {
+	ev := &calendar.Event{Summary: "Same", Start: &calendar.EventDateTime{Date: "2025-01-15"}}
+	// The patch response may include empty collections the pre-fetch omitted
+	after := &calendar.Event{Summary: "Same", Start: &calendar.EventDateTime{Date: "2025-01-15"}, Attendees: []*calendar.EventAttendee{}}
+	changes := diffEvents(ev, after)
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+	// The edit_event output schema requires changes to be an array
+	if raw, _ := json.Marshal(EventDiff{Changes: changes}); !strings.Contains(string(raw), `"changes":[]`) {
+		t.Errorf("no-op diff encodes as %s, want an empty changes array", raw)
+	}
+	if changes := diffEvents(nil, after); changes == nil {
+		t.Error("diffEvents(nil, ...) returned nil, want an empty slice")
+	}
+}
+
+func TestDiffEvents_RSVPComment(t *testing.T) {
+	before := &calendar.Event{Attendees: []*calendar.EventAttendee{{Email: "me@example.com", ResponseStatus: "needsAction"}}}
+	after := &calendar.Event{Attendees: []*calendar.EventAttendee{{Email: "me@example.com", ResponseStatus: "declined", Comment: "Out sick"}}}
+	changes := diffEvents(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("expected one change, got %+v", changes)
+	}
+	if got := describeChange(changes[0]); got != `+me@example.com (declined): "Out sick", -me@example.com (needsAction)` {
+		t.Errorf("unexpected attendee description %q", got)
+	}
+}
+
+// ----- formatEventDiff -----
+
+func TestFormatEventDiff(t *testing.T) {
+	out := formatEventDiff(EventDiff{
+		EventID: "evt1",
+		Summary: "Sync",
+		Changes: []FieldChange{{Field: "location", Old: "", New: "Room 1"}},
+	})
+	if !strings.Contains(out, `• location: (empty) → "Room 1"`) {
+		t.Errorf("expected readable change line, got:\n%s", out)
+	}
+	if !strings.HasPrefix(out, "✅ Updated 'Sync'\n1 field(s) changed:") {
+		t.Errorf("expected a confirmation line, got:\n%s", out)
+	}
+	if !strings.Contains(out, `"event_id": "evt1"`) {
+		t.Errorf("expected structured diff, got:\n%s", out)
+	}
+}
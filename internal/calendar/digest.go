@@ -0,0 +1,314 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// travelBuffer is the least free time before an in-person meeting that the
+// digest does not flag as tight
+const travelBuffer = 30 * time.Minute
+
+// MorningDigest summarizes what needs attention on one day before it starts.
+type MorningDigest struct {
+	Date         string           `json:"date"`
+	TimeZone     string           `json:"time_zone"`
+	Meetings     int              `json:"meetings"`
+	FirstMeeting *DigestEvent     `json:"first_meeting,omitempty"`
+	Unanswered   []DigestEvent    `json:"unanswered_invites"`
+	Conflicts    []DigestConflict `json:"conflicts"`
+	Travel       []DigestTravel   `json:"travel"`
+	AllDay       []string         `json:"all_day,omitempty"` // titles of all-day events, e.g. holidays or time off
+	Tracked      []TrackedChange  `json:"tracked_changes,omitempty"`
+}
+
+// DigestEvent is an event as listed in the digest, with local wall-clock times.
+type DigestEvent struct {
+	EventID   string `json:"event_id"`
+	Summary   string `json:"summary"`
+	Start     string `json:"start,omitempty"` // HH:MM; empty for all-day events
+	End       string `json:"end,omitempty"`
+	Organizer string `json:"organizer,omitempty"`
+	Location  string `json:"location,omitempty"`
+}
+
+// DigestConflict is a pair of meetings that overlap.
+type DigestConflict struct {
+	First  DigestEvent `json:"first"`
+	Second DigestEvent `json:"second"`
+}
+
+// DigestTravel is an in-person meeting and how much free time precedes it
+// since the previous meeting of the day.
+type DigestTravel struct {
+	DigestEvent
+	FreeBefore string `json:"free_before,omitempty"` // e.g. "15m"; empty for the day's first meeting
+	Tight      bool   `json:"tight"`                 // less than travelBuffer to get there
+}
+
+// isMeeting reports whether an event is a meeting the user will attend, as
+// opposed to focus time, working locations or out-of-office blocks.
+func isMeeting(e *calendar.Event) bool {
+	return blocksTime(e) && (e.EventType == "" || e.EventType == "default") && !isFocusTime(e)
+}
+
+// isPhysicalLocation reports whether a location is a place to travel to
+// rather than a video call link or dial-in.
+func isPhysicalLocation(location string) bool {
+	location = strings.ToLower(strings.TrimSpace(location))
+	if location == "" || strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return false
+	}
+	for _, virtual := range []string{"meet.google.com", "zoom.us", "teams.microsoft.com", "webex.com"} {
+		if strings.Contains(location, virtual) {
+			return false
+		}
+	}
+	return true
+}
+
+func newDigestEvent(e *calendar.Event, loc *time.Location) DigestEvent {
+	d := DigestEvent{EventID: e.Id, Summary: eventTitle(e), Location: e.Location}
+	if e.Organizer != nil && !e.Organizer.Self {
+		d.Organizer = e.Organizer.Email
+	}
+	if start, end, allDay, err := parseEventTimes(e); err == nil && !allDay {
+		d.Start = start.In(loc).Format("15:04")
+		d.End = end.In(loc).Format("15:04")
+	}
+	return d
+}
+
+// buildMorningDigest compiles the digest for day (local midnight in loc) from
+// the events listed for it, including declined ones.
+func buildMorningDigest(events []*calendar.Event, day time.Time, loc *time.Location) MorningDigest {
+	digest := MorningDigest{
+		Date:       day.Format(dateLayout),
+		TimeZone:   loc.String(),
+		Unanswered: []DigestEvent{},
+		Conflicts:  []DigestConflict{},
+		Travel:     []DigestTravel{},
+	}
+
+	type meeting struct {
+		event      *calendar.Event
+		start, end time.Time
+	}
+	var meetings []meeting
+	for _, e := range events {
+		if e.Status == "cancelled" {
+			continue
+		}
+		if selfNeedsAction(e) && (e.Organizer == nil || !e.Organizer.Self) {
+			digest.Unanswered = append(digest.Unanswered, newDigestEvent(e, loc))
+		}
+		if e.Start != nil && e.Start.Date != "" && !selfDeclined(e) {
+			digest.AllDay = append(digest.AllDay, newDigestEvent(e, loc).Summary)
+			continue
+		}
+		if !isMeeting(e) {
+			continue
+		}
+		start, end, _, err := parseEventTimes(e)
+		if err != nil {
+			continue
+		}
+		meetings = append(meetings, meeting{event: e, start: start, end: end})
+	}
+	sort.SliceStable(meetings, func(i, j int) bool { return meetings[i].start.Before(meetings[j].start) })
+
+	digest.Meetings = len(meetings)
+	if len(meetings) > 0 {
+		first := newDigestEvent(meetings[0].event, loc)
+		digest.FirstMeeting = &first
+	}
+
+	var busyUntil time.Time // end of the latest meeting so far
+	for i, m := range meetings {
+		for _, other := range meetings[i+1:] {
+			if eventsOverlap(m.start, m.end, other.start, other.end) {
+				digest.Conflicts = append(digest.Conflicts, DigestConflict{First: newDigestEvent(m.event, loc), Second: newDigestEvent(other.event, loc)})
+			}
+		}
+		if isPhysicalLocation(m.event.Location) {
+			travel := DigestTravel{DigestEvent: newDigestEvent(m.event, loc)}
+			if i > 0 {
+				free := m.start.Sub(busyUntil)
+				if free < 0 {
+					free = 0
+				}
+				travel.FreeBefore = formatDuration(free)
+				travel.Tight = free < travelBuffer
+			}
+			digest.Travel = append(digest.Travel, travel)
+		}
+		if m.end.After(busyUntil) {
+			busyUntil = m.end
+		}
+	}
+	return digest
+}
+
+// formatDuration renders a duration in hours and minutes, e.g. "1h30m" or "15m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dh%dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// formatMorningDigest renders the digest, followed by the structured data.
+func formatMorningDigest(digest MorningDigest, tf TimeFormat, day time.Time) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🌅 Morning digest for %s (%s):\n\n", tf.LongDate(day), digest.TimeZone)
+
+	if digest.FirstMeeting != nil {
+		fmt.Fprintf(&result, "• First meeting: %s at %s (%d meeting(s) today)\n", digest.FirstMeeting.Summary, digest.FirstMeeting.Start, digest.Meetings)
+	} else {
+		result.WriteString("• No meetings\n")
+	}
+	if len(digest.AllDay) > 0 {
+		fmt.Fprintf(&result, "• All day: %s\n", strings.Join(digest.AllDay, ", "))
+	}
+
+	if len(digest.Unanswered) > 0 {
+		fmt.Fprintf(&result, "\n📨 Unanswered invites (%d):\n", len(digest.Unanswered))
+		for _, e := range digest.Unanswered {
+			fmt.Fprintf(&result, "• %s%s", e.Summary, digestTime(e))
+			if e.Organizer != "" {
+				fmt.Fprintf(&result, " from %s", e.Organizer)
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	if len(digest.Conflicts) > 0 {
+		fmt.Fprintf(&result, "\n⚠️ Conflicts (%d):\n", len(digest.Conflicts))
+		for _, c := range digest.Conflicts {
+			fmt.Fprintf(&result, "• %s%s overlaps %s%s\n", c.First.Summary, digestTime(c.First), c.Second.Summary, digestTime(c.Second))
+		}
+	}
+
+	if len(digest.Travel) > 0 {
+		fmt.Fprintf(&result, "\n🚶 In person (%d):\n", len(digest.Travel))
+		for _, t := range digest.Travel {
+			fmt.Fprintf(&result, "• %s%s at %s", t.Summary, digestTime(t.DigestEvent), t.Location)
+			switch {
+			case t.Tight:
+				fmt.Fprintf(&result, " — only %s free before it", t.FreeBefore)
+			case t.FreeBefore != "":
+				fmt.Fprintf(&result, " — %s free before it", t.FreeBefore)
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	if len(digest.Tracked) > 0 {
+		fmt.Fprintf(&result, "\n🔔 Tracked events that changed (%d):\n%s", len(digest.Tracked), formatTrackedChanges(digest.Tracked))
+	}
+
+	digestJSON, _ := json.MarshalIndent(digest, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(digestJSON))
+	return result.String()
+}
+
+// digestTime renders an event's time range in parentheses, or "" for all-day events.
+func digestTime(e DigestEvent) string {
+	if e.Start == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s–%s)", e.Start, e.End)
+}
+
+// digestDay resolves the date argument of morning_digest: "today" (the
+// default), "tomorrow" or YYYY-MM-DD, as local midnight in loc.
+func digestDay(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	switch value {
+	case "", "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	day, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected 'today', 'tomorrow' or YYYY-MM-DD", value)
+	}
+	return day, nil
+}
+
+func (ct *CalendarTools) handleMorningDigest(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	calendarID := ct.calendarID(arguments)
+
+	// Default to the calendar's own time zone, so "today" is the user's day
+	timezone := getStringOrDefault(arguments, "timezone", "")
+	if timezone == "" {
+		timezone = "UTC"
+		if entry, err := ct.client.CalendarListEntry(calendarID); err == nil && entry.TimeZone != "" {
+			timezone = entry.TimeZone
+		}
+	}
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	day, err := digestDay(getStringOrDefault(arguments, "date", "today"), time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      day,
+		TimeMax:      day.AddDate(0, 0, 1),
+		TimeZone:     timezone,
+		SingleEvents: true,
+		OrderBy:      "startTime",
+		ShowDeclined: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	digest := buildMorningDigest(events.Items, day, loc)
+	// Each digest reports what changed in the tracked events since the last one
+	if digest.Tracked, _, err = ct.checkTrackedEvents(true); err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatMorningDigest(digest, ct.client.TimeFormat(), day),
+		}},
+	}, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- buildMorningDigest -----
+
+func TestBuildMorningDigest(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	standup := timedEvent("standup", "Standup", "2025-03-10T09:00:00Z", "2025-03-10T09:30:00Z")
+	review := timedEvent("review", "Design review", "2025-03-10T09:15:00Z", "2025-03-10T10:00:00Z")
+	review.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "needsAction"}}
+	review.Organizer = &calendar.EventOrganizer{Email: "lead@example.com"}
+	lunch := timedEvent("lunch", "Customer lunch", "2025-03-10T10:10:00Z", "2025-03-10T11:00:00Z")
+	lunch.Location = "Cafe Roma, 12 Main St"
+	onsite := timedEvent("onsite", "Onsite", "2025-03-10T13:00:00Z", "2025-03-10T14:00:00Z")
+	onsite.Location = "Building 4"
+	virtual := timedEvent("virtual", "Vendor call", "2025-03-10T15:00:00Z", "2025-03-10T15:30:00Z")
+	virtual.Location = "https://zoom.us/j/123"
+	declined := timedEvent("declined", "All hands", "2025-03-10T09:00:00Z", "2025-03-10T10:00:00Z")
+	declined.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+	focus := timedEvent("focus", "Focus", "2025-03-10T08:00:00Z", "2025-03-10T09:00:00Z")
+	focus.EventType = "focusTime"
+	holiday := &calendar.Event{Id: "holiday", Summary: "Team offsite week", Start: &calendar.EventDateTime{Date: "2025-03-10"}, End: &calendar.EventDateTime{Date: "2025-03-11"}}
+
+	digest := buildMorningDigest([]*calendar.Event{focus, standup, declined, review, lunch, onsite, virtual, holiday}, day, time.UTC)
+
+	if digest.Date != "2025-03-10" || digest.Meetings != 5 {
+		t.Errorf("date = %s, meetings = %d; want 2025-03-10 and 5", digest.Date, digest.Meetings)
+	}
+	if digest.FirstMeeting == nil || digest.FirstMeeting.EventID != "standup" || digest.FirstMeeting.Start != "09:00" {
+		t.Errorf("first meeting = %+v, want the 09:00 standup (focus time is not a meeting)", digest.FirstMeeting)
+	}
+	if len(digest.Unanswered) != 1 || digest.Unanswered[0].EventID != "review" || digest.Unanswered[0].Organizer != "lead@example.com" {
+		t.Errorf("unanswered = %+v, want the design review", digest.Unanswered)
+	}
+	if len(digest.Conflicts) != 1 || digest.Conflicts[0].First.EventID != "standup" || digest.Conflicts[0].Second.EventID != "review" {
+		t.Errorf("conflicts = %+v, want standup/review only (declined events do not conflict)", digest.Conflicts)
+	}
+	if len(digest.Travel) != 2 {
+		t.Fatalf("travel = %+v, want lunch and onsite", digest.Travel)
+	}
+	if digest.Travel[0].EventID != "lunch" || !digest.Travel[0].Tight || digest.Travel[0].FreeBefore != "10m" {
+		t.Errorf("lunch travel = %+v, want 10m free and tight", digest.Travel[0])
+	}
+	if digest.Travel[1].EventID != "onsite" || digest.Travel[1].Tight || digest.Travel[1].FreeBefore != "2h" {
+		t.Errorf("onsite travel = %+v, want 2h free", digest.Travel[1])
+	}
+	if len(digest.AllDay) != 1 || digest.AllDay[0] != "Team offsite week" {
+		t.Errorf("all day = %v", digest.AllDay)
+	}
+}
+
+func TestBuildMorningDigest_Empty(t *testing.T) {
+	digest := buildMorningDigest(nil, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.UTC)
+	if digest.FirstMeeting != nil || digest.Unanswered == nil || digest.Conflicts == nil || digest.Travel == nil {
+		t.Errorf("empty digest = %+v, want no first meeting and empty (not null) lists", digest)
+	}
+}
+
+// ----- isPhysicalLocation -----
+
+func TestIsPhysicalLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		expected bool
+	}{
+		{"Building 4, Room 201", true},
+		{"", false},
+		{"https://meet.google.com/abc-defg-hij", false},
+		{"Zoom: zoom.us/j/123", false},
+		{"Microsoft Teams Meeting teams.microsoft.com/l/meetup", false},
+	}
+	for _, tt := range tests {
+		if got := isPhysicalLocation(tt.location); got != tt.expected {
+			t.Errorf("isPhysicalLocation(%q) = %v, want %v", tt.location, got, tt.expected)
+		}
+	}
+}
+
+// ----- digestDay -----
+
+func TestDigestDay(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	// 02:00 UTC is still the previous evening in New York
+	now := time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", "2025-03-10"},
+		{"today", "2025-03-10"},
+		{"tomorrow", "2025-03-11"},
+		{"2025-04-01", "2025-04-01"},
+	}
+	for _, tt := range tests {
+		got, err := digestDay(tt.value, now, loc)
+		if err != nil {
+			t.Errorf("digestDay(%q) error: %v", tt.value, err)
+			continue
+		}
+		if got.Format(dateLayout) != tt.expected || got.Location() != loc {
+			t.Errorf("digestDay(%q) = %v, want %s in New York", tt.value, got, tt.expected)
+		}
+	}
+	if _, err := digestDay("next monday", now, loc); err == nil {
+		t.Error("expected an error for an unsupported date")
+	}
+}
+
+// ----- formatDuration -----
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                               "0m",
+		15 * time.Minute:                "15m",
+		2 * time.Hour:                   "2h",
+		90*time.Minute + 20*time.Second: "1h30m",
+	}
+	for d, expected := range tests {
+		if got := formatDuration(d); got != expected {
+			t.Errorf("formatDuration(%s) = %q, want %q", d, got, expected)
+		}
+	}
+}
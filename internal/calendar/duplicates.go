@@ -0,0 +1,402 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// titleSimilarityThreshold is the token overlap (Jaccard index) above which
+	// two titles are considered the same meeting
+	titleSimilarityThreshold = 0.75
+	// defaultDuplicateScanDays is the range find_duplicates scans by default
+	defaultDuplicateScanDays = 30
+)
+
+// DuplicateGroup is a set of events that appear to be copies of one meeting.
+type DuplicateGroup struct {
+	KeepEventID      string   `json:"keep_event_id"`
+	DuplicateIDs     []string `json:"duplicate_event_ids"`
+	Summary          string   `json:"summary"`
+	Start            string   `json:"start"`
+	KeepReason       string   `json:"keep_reason"`
+	DuplicateSummary []string `json:"duplicates"`
+}
+
+// findDuplicates groups events with similar titles and overlapping times.
+// Instances of the same recurring series are never grouped together.
+func findDuplicates(events []*calendar.Event) []DuplicateGroup {
+	type candidate struct {
+		event      *calendar.Event
+		start, end time.Time
+		tokens     map[string]bool
+	}
+	var candidates []candidate
+	for _, ev := range events {
+		// Private events whose details are hidden have no title to compare
+		if ev.Status == "cancelled" || (ev.EventType != "" && ev.EventType != "default") || isHiddenPrivate(ev) {
+			continue
+		}
+		start, end, _, err := parseEventTimes(ev)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{event: ev, start: start, end: end, tokens: titleTokens(ev.Summary)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].start.Before(candidates[j].start) })
+
+	// Union-find over candidate indexes
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			a, b := candidates[i], candidates[j]
+			if !b.start.Before(a.end) {
+				break // sorted by start: no later event overlaps a
+			}
+			if a.event.RecurringEventId != "" && a.event.RecurringEventId == b.event.RecurringEventId {
+				continue
+			}
+			if !eventsOverlap(a.start, a.end, b.start, b.end) || titleSimilarity(a.tokens, b.tokens) < titleSimilarityThreshold {
+				continue
+			}
+			parent[find(j)] = find(i)
+		}
+	}
+
+	members := make(map[int][]*calendar.Event)
+	var roots []int
+	for i, c := range candidates {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], c.event)
+	}
+
+	var groups []DuplicateGroup
+	for _, root := range roots {
+		evs := members[root]
+		if len(evs) < 2 {
+			continue
+		}
+		keep := pickKeeper(evs)
+		group := DuplicateGroup{
+			KeepEventID: keep.Id,
+			Summary:     keep.Summary,
+			Start:       formatDiffTime(keep.Start),
+			KeepReason:  keepReason(keep),
+		}
+		for _, ev := range evs {
+			if ev.Id == keep.Id {
+				continue
+			}
+			group.DuplicateIDs = append(group.DuplicateIDs, ev.Id)
+			group.DuplicateSummary = append(group.DuplicateSummary, fmt.Sprintf("%s (%s, %d attendee(s))", ev.Id, ev.Summary, len(ev.Attendees)))
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// titleTokens lowercases a title and splits it into words, ignoring
+// punctuation and copy markers such as "copy of".
+func titleTokens(title string) map[string]bool {
+	title = strings.ToLower(title)
+	title = strings.TrimPrefix(strings.TrimSpace(title), "copy of ")
+	tokens := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(title, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		tokens[word] = true
+	}
+	return tokens
+}
+
+// titleSimilarity returns the Jaccard index of two token sets. Two empty
+// titles are identical.
+func titleSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// pickKeeper chooses the copy to keep: the one with conference data, then the
+// most attendees, then one the user organizes, then the oldest.
+func pickKeeper(events []*calendar.Event) *calendar.Event {
+	best := events[0]
+	for _, ev := range events[1:] {
+		if keeperRank(ev) > keeperRank(best) || (keeperRank(ev) == keeperRank(best) && ev.Created != "" && ev.Created < best.Created) {
+			best = ev
+		}
+	}
+	return best
+}
+
+func keeperRank(ev *calendar.Event) int {
+	rank := len(ev.Attendees) * 2
+	if hasConference(ev) {
+		rank += 1000
+	}
+	if ev.Organizer != nil && ev.Organizer.Self {
+		rank++
+	}
+	return rank
+}
+
+func hasConference(ev *calendar.Event) bool {
+	return ev.HangoutLink != "" || (ev.ConferenceData != nil && len(ev.ConferenceData.EntryPoints) > 0)
+}
+
+func keepReason(ev *calendar.Event) string {
+	var reasons []string
+	if hasConference(ev) {
+		reasons = append(reasons, "has conference link")
+	}
+	if len(ev.Attendees) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d attendee(s)", len(ev.Attendees)))
+	}
+	if len(reasons) == 0 {
+		return "oldest copy"
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// MergeDuplicatesParams describes a merge of duplicate events into one.
+type MergeDuplicatesParams struct {
+	CalendarID   string
+	KeepEventID  string
+	DuplicateIDs []string
+	SendUpdates  string
+}
+
+// MergeDuplicates copies attendees, and a location or description the kept
+// event lacks, from the duplicates onto the kept event, then deletes the
+// duplicates. It returns the updated event and the IDs actually deleted.
+func (c *Client) MergeDuplicates(params MergeDuplicatesParams) (*calendar.Event, []string, error) {
+	if params.CalendarID == "" {
+		params.CalendarID = "primary"
+	}
+	keep, err := c.GetEvent(params.CalendarID, params.KeepEventID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get event to keep: %v", err)
+	}
+
+	var duplicates []*calendar.Event
+	for _, id := range params.DuplicateIDs {
+		if id == params.KeepEventID {
+			return nil, nil, fmt.Errorf("event %s cannot be both kept and deleted", id)
+		}
+		dup, err := c.GetEvent(params.CalendarID, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get duplicate %s: %v", id, err)
+		}
+		duplicates = append(duplicates, dup)
+	}
+
+	for _, dup := range duplicates {
+		if err := c.EventAccess(params.CalendarID, dup).checkDelete(dup.Summary); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	patch, changed := mergePatch(keep, duplicates)
+	if changed {
+		if err := c.EventAccess(params.CalendarID, keep).checkEdit(keep.Summary, patch, keep); err != nil {
+			return nil, nil, err
+		}
+		patch.CalendarID = params.CalendarID
+		patch.SendUpdates = params.SendUpdates
+		patch.ETag = keep.Etag
+		if keep, err = c.PatchEventDirect(keep.Id, patch); err != nil {
+			return nil, nil, fmt.Errorf("failed to update kept event: %v", err)
+		}
+	}
+
+	var deleted []string
+	for _, dup := range duplicates {
+		if err := c.DeleteEventIfMatch(params.CalendarID, dup.Id, dup.Etag, params.SendUpdates); err != nil {
+			return keep, deleted, fmt.Errorf("failed to delete duplicate %s: %v", dup.Id, err)
+		}
+		deleted = append(deleted, dup.Id)
+	}
+	return keep, deleted, nil
+}
+
+// mergePatch builds the patch that carries information only present on the
+// duplicates over to the kept event.
+func mergePatch(keep *calendar.Event, duplicates []*calendar.Event) (PatchEventParams, bool) {
+	var patch PatchEventParams
+	changed := false
+
+	seen := make(map[string]bool)
+	var attendees []AttendeeParams
+	for _, a := range keep.Attendees {
+		seen[strings.ToLower(a.Email)] = true
+		attendees = append(attendees, AttendeeParams{Email: a.Email, DisplayName: a.DisplayName, Optional: a.Optional, ResponseStatus: a.ResponseStatus})
+	}
+	for _, dup := range duplicates {
+		for _, a := range dup.Attendees {
+			if a.Email == "" || a.Resource || seen[strings.ToLower(a.Email)] {
+				continue
+			}
+			seen[strings.ToLower(a.Email)] = true
+			attendees = append(attendees, AttendeeParams{Email: a.Email, DisplayName: a.DisplayName, Optional: a.Optional, ResponseStatus: a.ResponseStatus})
+			changed = true
+		}
+		if keep.Location == "" && dup.Location != "" && patch.Location == nil {
+			location := dup.Location
+			patch.Location = &location
+			changed = true
+		}
+		if keep.Description == "" && dup.Description != "" && patch.Description == nil {
+			description := dup.Description
+			patch.Description = &description
+			changed = true
+		}
+	}
+	if len(attendees) > len(keep.Attendees) {
+		keepAttendeeComments(attendees, keep)
+		patch.Attendees = attendees
+		patch.HasAttendees = true
+	}
+	return patch, changed
+}
+
+func (ct *CalendarTools) handleFindDuplicates(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	now := time.Now()
+	timeMin, timeMax := now, now.AddDate(0, 0, defaultDuplicateScanDays)
+	var err error
+	if v := getStringOrDefault(arguments, "time_min", ""); v != "" {
+		if timeMin, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid time_min format: %v", err)
+		}
+	}
+	if v := getStringOrDefault(arguments, "time_max", ""); v != "" {
+		if timeMax, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid time_max format: %v", err)
+		}
+	}
+	if !timeMax.After(timeMin) {
+		return nil, fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   ct.calendarID(arguments),
+		TimeFilter:   "custom",
+		TimeMin:      timeMin,
+		TimeMax:      timeMax,
+		ShowDeclined: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	groups := findDuplicates(events.Items)
+
+	var result strings.Builder
+	if len(groups) == 0 {
+		fmt.Fprintf(&result, "✅ No duplicate events found among %d event(s) from %s to %s", len(events.Items),
+			timeMin.Format("2006-01-02"), timeMax.Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(&result, "🔁 Found %d set(s) of likely duplicate events:\n\n", len(groups))
+		for _, g := range groups {
+			fmt.Fprintf(&result, "• '%s' at %s — keep %s (%s); duplicates: %s\n", g.Summary, g.Start, g.KeepEventID, g.KeepReason, strings.Join(g.DuplicateSummary, "; "))
+		}
+		result.WriteString("\nUse merge_duplicates with keep_event_id and duplicate_event_ids to merge each set.\n")
+		groupsJSON, _ := json.MarshalIndent(groups, "", "  ")
+		fmt.Fprintf(&result, "\n%s", string(groupsJSON))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleMergeDuplicates(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	keepID := getStringOrDefault(arguments, "keep_event_id", "")
+	if keepID == "" {
+		return nil, fmt.Errorf("keep_event_id is required")
+	}
+	var duplicateIDs []string
+	if ids, ok := arguments["duplicate_event_ids"].([]interface{}); ok {
+		for _, v := range ids {
+			if id, ok := v.(string); ok && id != "" {
+				duplicateIDs = append(duplicateIDs, id)
+			}
+		}
+	}
+	if len(duplicateIDs) == 0 {
+		return nil, fmt.Errorf("duplicate_event_ids is required")
+	}
+
+	sendUpdates, err := ct.sendUpdates(arguments, false)
+	if err != nil {
+		return nil, err
+	}
+
+	keep, deleted, err := ct.client.MergeDuplicates(MergeDuplicatesParams{
+		CalendarID:   ct.calendarID(arguments),
+		KeepEventID:  keepID,
+		DuplicateIDs: duplicateIDs,
+		SendUpdates:  sendUpdates,
+	})
+	if err != nil {
+		if len(deleted) > 0 {
+			return nil, fmt.Errorf("%v (already deleted: %s)", err, strings.Join(deleted, ", "))
+		}
+		return nil, err
+	}
+
+	confirmation := newConfirmation(confirmMerged, ct.calendarID(arguments), keep, ct.client.TimeFormat())
+	payload := struct {
+		Confirmation
+		DeletedIDs []string `json:"deleted_event_ids"`
+	}{confirmation, deleted}
+	note := fmt.Sprintf("Merged %d duplicate(s) into it; deleted: %s", len(deleted), strings.Join(deleted, ", "))
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: formatConfirmation(confirmation, payload, note)}},
+	}, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func timedEvent(id, summary, start, end string) *calendar.Event {
+	return &calendar.Event{
+		Id:      id,
+		Summary: summary,
+		Start:   &calendar.EventDateTime{DateTime: start},
+		End:     &calendar.EventDateTime{DateTime: end},
+	}
+}
+
+// ----- titleSimilarity -----
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		similar bool
+	}{
+		{"Team Sync", "team sync", true},
+		{"Team Sync!", "Team - Sync", true},
+		{"Copy of Team Sync", "Team Sync", true},
+		{"Quarterly planning review", "Quarterly planning review (imported)", true},
+		{"Team Sync", "Design Review", false},
+		{"1:1 Alice", "1:1 Bob", false},
+	}
+	for _, tt := range tests {
+		got := titleSimilarity(titleTokens(tt.a), titleTokens(tt.b)) >= titleSimilarityThreshold
+		if got != tt.similar {
+			t.Errorf("similar(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.similar)
+		}
+	}
+}
+
+// ----- findDuplicates -----
+
+func TestFindDuplicates(t *testing.T) {
+	original := timedEvent("orig", "Team Sync", "2025-03-10T10:00:00Z", "2025-03-10T11:00:00Z")
+	original.Attendees = []*calendar.EventAttendee{{Email: "a@example.com"}, {Email: "b@example.com"}}
+	original.HangoutLink = "https://meet.google.com/abc"
+	imported := timedEvent("imported", "team sync", "2025-03-10T10:00:00Z", "2025-03-10T11:00:00Z")
+	imported.Created = "2020-01-01T00:00:00Z"
+
+	other := timedEvent("other", "Design Review", "2025-03-10T10:30:00Z", "2025-03-10T11:30:00Z")
+	later := timedEvent("later", "Team Sync", "2025-03-11T10:00:00Z", "2025-03-11T11:00:00Z")
+
+	inst1 := timedEvent("series_1", "Standup", "2025-03-12T09:00:00Z", "2025-03-12T09:15:00Z")
+	inst1.RecurringEventId = "series"
+	inst2 := timedEvent("series_2", "Standup", "2025-03-12T09:00:00Z", "2025-03-12T09:15:00Z")
+	inst2.RecurringEventId = "series"
+
+	cancelled := timedEvent("cancelled", "Team Sync", "2025-03-10T10:00:00Z", "2025-03-10T11:00:00Z")
+	cancelled.Status = "cancelled"
+
+	groups := findDuplicates([]*calendar.Event{imported, other, original, later, inst1, inst2, cancelled})
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups %+v, want 1", len(groups), groups)
+	}
+	g := groups[0]
+	if g.KeepEventID != "orig" {
+		t.Errorf("KeepEventID = %s, want orig (has conference and attendees)", g.KeepEventID)
+	}
+	if len(g.DuplicateIDs) != 1 || g.DuplicateIDs[0] != "imported" {
+		t.Errorf("DuplicateIDs = %v, want [imported]", g.DuplicateIDs)
+	}
+}
+
+func TestFindDuplicatesPrefersOldestWhenEqual(t *testing.T) {
+	a := timedEvent("a", "Lunch", "2025-03-10T12:00:00Z", "2025-03-10T13:00:00Z")
+	a.Created = "2025-02-02T00:00:00Z"
+	b := timedEvent("b", "Lunch", "2025-03-10T12:00:00Z", "2025-03-10T13:00:00Z")
+	b.Created = "2025-02-01T00:00:00Z"
+
+	groups := findDuplicates([]*calendar.Event{a, b})
+	if len(groups) != 1 || groups[0].KeepEventID != "b" {
+		t.Fatalf("groups = %+v, want b kept", groups)
+	}
+}
+
+// ----- mergePatch -----
+
+func TestMergePatch(t *testing.T) {
+	keep := timedEvent("keep", "Team Sync", "2025-03-10T10:00:00Z", "2025-03-10T11:00:00Z")
+	keep.Location = "Room 1"
+	keep.Attendees = []*calendar.EventAttendee{{Email: "a@example.com", ResponseStatus: "accepted"}}
+
+	dup := timedEvent("dup", "Team Sync", "2025-03-10T10:00:00Z", "2025-03-10T11:00:00Z")
+	dup.Location = "Room 2"
+	dup.Description = "Agenda"
+	dup.Attendees = []*calendar.EventAttendee{
+		{Email: "A@example.com"},
+		{Email: "c@example.com", DisplayName: "Carol", Optional: true},
+		{Email: "room@resource.calendar.google.com", Resource: true},
+	}
+
+	patch, changed := mergePatch(keep, []*calendar.Event{dup})
+	if !changed {
+		t.Fatal("changed = false, want true")
+	}
+	if patch.Location != nil {
+		t.Errorf("Location = %q, want kept event's location untouched", *patch.Location)
+	}
+	if patch.Description == nil || *patch.Description != "Agenda" {
+		t.Errorf("Description = %v, want Agenda", patch.Description)
+	}
+	if !patch.HasAttendees || len(patch.Attendees) != 2 {
+		t.Fatalf("Attendees = %+v, want a@ and c@", patch.Attendees)
+	}
+	if patch.Attendees[0].ResponseStatus != "accepted" {
+		t.Errorf("existing attendee status = %q, want accepted", patch.Attendees[0].ResponseStatus)
+	}
+	if c := patch.Attendees[1]; c.Email != "c@example.com" || c.DisplayName != "Carol" || !c.Optional {
+		t.Errorf("merged attendee = %+v", c)
+	}
+
+	if _, changed := mergePatch(keep, []*calendar.Event{timedEvent("x", "Team Sync", "", "")}); changed {
+		t.Error("changed = true for a duplicate with nothing new")
+	}
+}
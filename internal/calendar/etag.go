@@ -0,0 +1,106 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// ConflictError reports that an event changed after it was read, so a write
+// made conditional on the etag seen at read time was rejected.
+type ConflictError struct {
+	EventID string
+	ETag    string          // etag the write was conditioned on
+	Current *calendar.Event // the event as it is now; nil if it could not be read (e.g. deleted)
+}
+
+func (e *ConflictError) Error() string {
+	msg := fmt.Sprintf("event %s changed since it was read (etag %s no longer matches)", e.EventID, e.ETag)
+	if e.Current == nil {
+		return msg + "; it may have been deleted — list events again before retrying"
+	}
+	current, _ := json.MarshalIndent(newEventVersion(e.Current), "", "  ")
+	return fmt.Sprintf("%s; re-plan against the current version below and retry with etag %s:\n%s", msg, e.Current.Etag, string(current))
+}
+
+// eventVersion is the part of an event returned with a conflict, so the
+// caller can see what changed without another read.
+type eventVersion struct {
+	ID          string   `json:"id"`
+	ETag        string   `json:"etag"`
+	Updated     string   `json:"updated,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Summary     string   `json:"summary"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Location    string   `json:"location,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Attendees   []string `json:"attendees,omitempty"`
+}
+
+func newEventVersion(event *calendar.Event) eventVersion {
+	v := eventVersion{
+		ID:          event.Id,
+		ETag:        event.Etag,
+		Updated:     event.Updated,
+		Status:      event.Status,
+		Summary:     event.Summary,
+		Start:       formatDiffTime(event.Start),
+		End:         formatDiffTime(event.End),
+		Location:    event.Location,
+		Description: event.Description,
+	}
+	for _, a := range event.Attendees {
+		v.Attendees = append(v.Attendees, fmt.Sprintf("%s (%s)", a.Email, a.ResponseStatus))
+	}
+	return v
+}
+
+// checkETag returns a ConflictError if the caller expected a different
+// version of event than the one just read. An empty expected etag matches.
+func checkETag(expected string, event *calendar.Event) error {
+	if expected == "" || expected == event.Etag {
+		return nil
+	}
+	return &ConflictError{EventID: event.Id, ETag: expected, Current: event}
+}
+
+// conflictError turns a 412 response to a write conditioned on etag into a
+// ConflictError carrying the event's current version. Other errors are
+// returned unchanged.
+func (c *Client) conflictError(calendarID, eventID, etag string, err error) error {
+	if !isPreconditionFailed(err) {
+		return err
+	}
+	conflict := &ConflictError{EventID: eventID, ETag: etag}
+	if current, getErr := c.GetEvent(calendarID, eventID); getErr == nil {
+		conflict.Current = current
+	}
+	return conflict
+}
+
+// isPreconditionFailed reports whether err is a 412 response from the API.
+func isPreconditionFailed(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed
+}
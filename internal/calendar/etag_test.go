@@ -0,0 +1,88 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// ----- checkETag -----
+
+func TestCheckETag(t *testing.T) {
+	event := timedEvent("evt1", "Sync", "2025-03-10T10:00:00Z", "2025-03-10T11:00:00Z")
+	event.Etag = `"2"`
+
+	if err := checkETag("", event); err != nil {
+		t.Errorf("empty etag: %v", err)
+	}
+	if err := checkETag(`"2"`, event); err != nil {
+		t.Errorf("matching etag: %v", err)
+	}
+
+	err := checkETag(`"1"`, event)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("stale etag: got %v, want *ConflictError", err)
+	}
+	if conflict.Current != event || conflict.ETag != `"1"` {
+		t.Errorf("conflict = %+v", conflict)
+	}
+}
+
+// ----- ConflictError -----
+
+func TestConflictErrorMessage(t *testing.T) {
+	current := timedEvent("evt1", "Moved sync", "2025-03-10T14:00:00Z", "2025-03-10T15:00:00Z")
+	current.Etag = `"3"`
+	current.Attendees = []*calendar.EventAttendee{{Email: "bob@example.com", ResponseStatus: "declined"}}
+
+	msg := (&ConflictError{EventID: "evt1", ETag: `"2"`, Current: current}).Error()
+	for _, want := range []string{"changed since it was read", `retry with etag "3"`, "Moved sync", "bob@example.com (declined)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+
+	deleted := (&ConflictError{EventID: "evt1", ETag: `"2"`}).Error()
+	if !strings.Contains(deleted, "may have been deleted") {
+		t.Errorf("message for a vanished event = %q", deleted)
+	}
+}
+
+// ----- isPreconditionFailed -----
+
+func TestIsPreconditionFailed(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&googleapi.Error{Code: http.StatusPreconditionFailed}, true},
+		{fmt.Errorf("wrapped: %w", &googleapi.Error{Code: http.StatusPreconditionFailed}), true},
+		{&googleapi.Error{Code: http.StatusForbidden}, false},
+		{errors.New("network"), false},
+	}
+	for _, tt := range tests {
+		if got := isPreconditionFailed(tt.err); got != tt.want {
+			t.Errorf("isPreconditionFailed(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// eventIDArguments are the tool arguments that take event IDs and so accept
+// event handles
+var eventIDArguments = []string{"event_id", "keep_event_id", "duplicate_event_ids", "followup_of"}
+
+// minEventIDLength is the shortest event ID the Calendar API accepts. Handles
+// shorter than this (e1 to e999) can never be mistaken for an event ID.
+const minEventIDLength = 5
+
+// eventHandles gives events short handles (e1, e2, ...) that list_events
+// shows next to their IDs and that tools accept in place of the IDs, since
+// models copy long IDs badly. An event keeps its handle for the life of the
+// server; handles are never reused and never equal an event ID seen so far.
+type eventHandles struct {
+	mu       sync.Mutex
+	byID     map[string]string // event ID -> handle
+	byHandle map[string]string // handle -> event ID
+	next     int
+}
+
+// handle returns the event's handle, assigning the next free one on first use.
+func (h *eventHandles) handle(eventID string) string {
+	if eventID == "" {
+		return ""
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if handle, ok := h.byID[eventID]; ok {
+		return handle
+	}
+	if h.byID == nil {
+		h.byID = make(map[string]string)
+		h.byHandle = make(map[string]string)
+	}
+	var handle string
+	for {
+		h.next++
+		handle = "e" + strconv.Itoa(h.next)
+		if _, taken := h.byID[handle]; !taken {
+			break
+		}
+	}
+	h.byID[eventID] = handle
+	h.byHandle[handle] = eventID
+	return handle
+}
+
+// resolve returns the event ID a handle stands for. Anything that is not a
+// handle is returned unchanged; a handle too short to be an event ID that was
+// never given out is an error, e.g. one from before a restart.
+func (h *eventHandles) resolve(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !isEventHandle(value) {
+		return value, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if eventID, ok := h.byHandle[value]; ok {
+		return eventID, nil
+	}
+	if len(value) < minEventIDLength {
+		return "", fmt.Errorf("unknown event handle %q; list the events again with list_events to get current handles", value)
+	}
+	return value, nil
+}
+
+func isEventHandle(value string) bool {
+	if len(value) < 2 || value[0] != 'e' {
+		return false
+	}
+	_, err := strconv.ParseUint(value[1:], 10, 64)
+	return err == nil && value[1] != '0'
+}
+
+// resolveEventHandles replaces event handles in event ID arguments with the
+// IDs they stand for, in single values and lists alike.
+func (ct *CalendarTools) resolveEventHandles(arguments map[string]interface{}) error {
+	for _, key := range eventIDArguments {
+		switch v := arguments[key].(type) {
+		case string:
+			eventID, err := ct.handles.resolve(v)
+			if err != nil {
+				return err
+			}
+			arguments[key] = eventID
+		case []interface{}:
+			resolved := make([]interface{}, len(v))
+			for i, item := range v {
+				resolved[i] = item
+				if s, ok := item.(string); ok {
+					eventID, err := ct.handles.resolve(s)
+					if err != nil {
+						return err
+					}
+					resolved[i] = eventID
+				}
+			}
+			arguments[key] = resolved
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventHandles(t *testing.T) {
+	var h eventHandles
+	first := h.handle("abc123def456")
+	if first != "e1" || h.handle("abc123def456") != "e1" {
+		t.Errorf("first handle = %q, want e1 reused", first)
+	}
+	if second := h.handle("xyz789_20250310T090000Z"); second != "e2" {
+		t.Errorf("second handle = %q, want e2", second)
+	}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"e2", "xyz789_20250310T090000Z", false},
+		{" e1 ", "abc123def456", false},
+		{"abc123def456", "abc123def456", false},
+		{"e7", "", true},
+		{"e01", "e01", false},       // not a handle
+		{"e12345", "e12345", false}, // long enough to be an event ID
+	}
+	for _, tt := range tests {
+		got, err := h.resolve(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolve(%q) = %q, %v; want %q (error: %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestEventHandlesSkipEventIDs(t *testing.T) {
+	var h eventHandles
+	h.next = 999
+	h.handle("e1001") // an event whose ID looks like a later handle
+	if got := h.handle("other-event"); got != "e1002" {
+		t.Errorf("handle = %q, want e1002 (e1001 is an event ID)", got)
+	}
+	if got, _ := h.resolve("e1001"); got != "e1001" {
+		t.Errorf("resolve(e1001) = %q, want the event ID itself", got)
+	}
+}
+
+func TestResolveEventHandles(t *testing.T) {
+	ct := &CalendarTools{}
+	keep := ct.handles.handle("keep-event-id")
+	dup := ct.handles.handle("duplicate-event-id")
+	arguments := map[string]interface{}{
+		"keep_event_id":       keep,
+		"duplicate_event_ids": []interface{}{dup, "another-event-id"},
+		"summary":             "e1",
+	}
+	if err := ct.resolveEventHandles(arguments); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"keep_event_id":       "keep-event-id",
+		"duplicate_event_ids": []interface{}{"duplicate-event-id", "another-event-id"},
+		"summary":             "e1",
+	}
+	if !reflect.DeepEqual(arguments, want) {
+		t.Errorf("arguments = %v, want %v", arguments, want)
+	}
+	if err := ct.resolveEventHandles(map[string]interface{}{"event_id": "e9"}); err == nil {
+		t.Error("unknown handle was accepted")
+	}
+}
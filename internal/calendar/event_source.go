@@ -0,0 +1,109 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"net/url"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// EventSourceParams is the canonical link back to what an event was created
+// from, such as a ticket, pull request or doc. The Calendar API shows it on
+// the event as a clickable title.
+type EventSourceParams struct {
+	URL   string `json:"url"`             // "" removes the source when editing
+	Title string `json:"title,omitempty"` // defaults to the URL
+}
+
+// eventSourceSchema is the schema of the source argument of create_event and
+// edit_event: a URL, or an object with url and title.
+func eventSourceSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []map[string]interface{}{
+			{
+				"type":        "string",
+				"description": "http or https URL of the ticket, pull request or doc",
+			},
+			{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{
+						"type":        "string",
+						"description": "http or https URL of the ticket, pull request or doc",
+					},
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Title shown for the link, e.g. 'PROJ-123: Fix login' (defaults to the URL)",
+					},
+				},
+				"required": []string{"url"},
+			},
+		},
+		"description": description,
+	}
+}
+
+// parseEventSource reads a source argument, given either as a URL string or
+// as an object with url and title. With allowEmpty, an empty URL is accepted
+// and means the source is removed.
+func parseEventSource(value interface{}, allowEmpty bool) (*EventSourceParams, error) {
+	var source EventSourceParams
+	switch v := value.(type) {
+	case string:
+		source.URL = v
+	case map[string]interface{}:
+		source.URL = getStringOrDefault(v, "url", "")
+		source.Title = getStringOrDefault(v, "title", "")
+	default:
+		return nil, fmt.Errorf("source must be a URL or an object with url and title")
+	}
+
+	if source.URL == "" {
+		if !allowEmpty {
+			return nil, fmt.Errorf("source url is required")
+		}
+		return &EventSourceParams{}, nil
+	}
+	// The API only accepts http and https source URLs
+	u, err := url.Parse(source.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid source url '%s': it must be an absolute http or https URL", source.URL)
+	}
+	if source.Title == "" {
+		source.Title = source.URL
+	}
+	return &source, nil
+}
+
+// eventSource converts source parameters for the API.
+func eventSource(source *EventSourceParams) *calendar.EventSource {
+	return &calendar.EventSource{Url: source.URL, Title: source.Title}
+}
+
+// describeSource renders an event's source as "title (url)", or just the URL
+// when the source has no title. It returns "" for events without a source.
+func describeSource(e *calendar.Event) string {
+	if e.Source == nil || e.Source.Url == "" {
+		return ""
+	}
+	if e.Source.Title == "" || e.Source.Title == e.Source.Url {
+		return e.Source.Url
+	}
+	return fmt.Sprintf("%s (%s)", e.Source.Title, e.Source.Url)
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestParseEventSource(t *testing.T) {
+	source, err := parseEventSource("https://github.com/acme/app/pull/42", false)
+	if err != nil {
+		t.Fatalf("URL string: %v", err)
+	}
+	if source.URL != "https://github.com/acme/app/pull/42" || source.Title != source.URL {
+		t.Errorf("URL string: got %+v, want the URL as title", source)
+	}
+
+	source, err = parseEventSource(map[string]interface{}{"url": "https://jira.example.com/browse/PROJ-123", "title": "PROJ-123: Fix login"}, false)
+	if err != nil {
+		t.Fatalf("object: %v", err)
+	}
+	if source.Title != "PROJ-123: Fix login" {
+		t.Errorf("object: title = %q", source.Title)
+	}
+
+	for _, value := range []interface{}{"ftp://example.com/file", "not a url", "/relative/path", 42, map[string]interface{}{"title": "no url"}} {
+		if _, err := parseEventSource(value, false); err == nil {
+			t.Errorf("parseEventSource(%v) accepted an invalid source", value)
+		}
+	}
+
+	// An empty URL removes the source when editing
+	if _, err := parseEventSource("", false); err == nil {
+		t.Error("an empty URL was accepted when creating")
+	}
+	source, err = parseEventSource("", true)
+	if err != nil || source.URL != "" {
+		t.Errorf("empty URL when editing: got %+v, %v", source, err)
+	}
+}
+
+func TestDescribeSource(t *testing.T) {
+	tests := []struct {
+		source *calendar.EventSource
+		want   string
+	}{
+		{nil, ""},
+		{&calendar.EventSource{Url: "https://docs.example.com/d/1", Title: "Design doc"}, "Design doc (https://docs.example.com/d/1)"},
+		{&calendar.EventSource{Url: "https://docs.example.com/d/1", Title: "https://docs.example.com/d/1"}, "https://docs.example.com/d/1"},
+		{&calendar.EventSource{Url: "https://docs.example.com/d/1"}, "https://docs.example.com/d/1"},
+	}
+	for _, tt := range tests {
+		if got := describeSource(&calendar.Event{Source: tt.source}); got != tt.want {
+			t.Errorf("describeSource(%+v) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
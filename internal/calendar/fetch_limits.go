@@ -0,0 +1,192 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRangeDaysEnv overrides how many days one call may fetch events or free/busy for
+	maxRangeDaysEnv = "GCAL_MCP_MAX_RANGE_DAYS"
+	// maxResultsEnv overrides the largest max_results list_events accepts
+	maxResultsEnv = "GCAL_MCP_MAX_RESULTS"
+	// maxCalendarsEnv overrides how many calendars one free/busy call may query
+	maxCalendarsEnv = "GCAL_MCP_MAX_CALENDARS"
+	// maxFetchSuggestions bounds how many narrower calls an error suggests
+	maxFetchSuggestions = 4
+)
+
+// FetchLimits bound how much a single tool call may fetch, so a request for
+// years of events or dozens of calendars fails fast with a suggestion instead
+// of timing out. A zero limit is not enforced.
+type FetchLimits struct {
+	MaxRangeDays int // longest time range, in days
+	MaxResults   int // largest max_results for list_events
+	MaxCalendars int // most calendars or attendees in one free/busy query
+}
+
+// DefaultFetchLimits allow a quarter of events, the API's largest page, and
+// the most calendars one free/busy query accepts.
+var DefaultFetchLimits = FetchLimits{MaxRangeDays: 92, MaxResults: 2500, MaxCalendars: 50}
+
+// FetchLimitsFromEnv returns DefaultFetchLimits with any limit overridden by
+// GCAL_MCP_MAX_RANGE_DAYS, GCAL_MCP_MAX_RESULTS or GCAL_MCP_MAX_CALENDARS; 0
+// disables a limit. Invalid values are ignored with a warning on stderr.
+func FetchLimitsFromEnv() FetchLimits {
+	limits := DefaultFetchLimits
+	for _, setting := range []struct {
+		env   string
+		limit *int
+	}{
+		{maxRangeDaysEnv, &limits.MaxRangeDays},
+		{maxResultsEnv, &limits.MaxResults},
+		{maxCalendarsEnv, &limits.MaxCalendars},
+	} {
+		value := os.Getenv(setting.env)
+		if value == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			*setting.limit = n
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", setting.env, value)
+		}
+	}
+	return limits
+}
+
+// SetFetchLimits sets the limits checked before fetching events or free/busy.
+func (ct *CalendarTools) SetFetchLimits(limits FetchLimits) {
+	ct.fetchLimits = limits
+}
+
+// FetchLimitError reports a request beyond a fetch limit, with narrower
+// calls that stay within it.
+type FetchLimitError struct {
+	Limit       string            `json:"limit"` // "max_range_days", "max_results" or "max_calendars"
+	Requested   int               `json:"requested"`
+	Max         int               `json:"max"`
+	Setting     string            `json:"setting"` // environment variable that raises the limit
+	Suggestions []FetchSuggestion `json:"suggestions,omitempty"`
+}
+
+// FetchSuggestion is a narrower call: only the arguments to change are set.
+// Calendars is one batch of the calendars or attendees to query together.
+type FetchSuggestion struct {
+	TimeMin    string   `json:"time_min,omitempty"`
+	TimeMax    string   `json:"time_max,omitempty"`
+	MaxResults int      `json:"max_results,omitempty"`
+	Calendars  []string `json:"calendars,omitempty"`
+}
+
+func (e *FetchLimitError) Error() string {
+	var msg string
+	switch e.Limit {
+	case "max_range_days":
+		msg = fmt.Sprintf("the requested range of %d days exceeds the limit of %d days", e.Requested, e.Max)
+	case "max_results":
+		msg = fmt.Sprintf("max_results %d exceeds the limit of %d", e.Requested, e.Max)
+	default:
+		msg = fmt.Sprintf("%d calendars exceed the limit of %d per call", e.Requested, e.Max)
+	}
+	msg += fmt.Sprintf(" (%s)", e.Setting)
+	if len(e.Suggestions) == 0 {
+		return msg
+	}
+	details, _ := json.MarshalIndent(e, "", "  ")
+	return fmt.Sprintf("%s; narrow the request, e.g. with the suggested arguments below, and make several calls if needed:\n%s", msg, string(details))
+}
+
+// checkRange returns a FetchLimitError if [timeMin, timeMax) is longer than
+// MaxRangeDays, suggesting consecutive windows that cover it.
+func (l FetchLimits) checkRange(timeMin, timeMax time.Time) error {
+	if l.MaxRangeDays <= 0 || !timeMax.After(timeMin.AddDate(0, 0, l.MaxRangeDays)) {
+		return nil
+	}
+	err := &FetchLimitError{
+		Limit:     "max_range_days",
+		Requested: int(math.Ceil(timeMax.Sub(timeMin).Hours() / 24)),
+		Max:       l.MaxRangeDays,
+		Setting:   maxRangeDaysEnv,
+	}
+	err.Suggestions = splitRange(timeMin, timeMax, l.MaxRangeDays)
+	return err
+}
+
+// splitRange divides [timeMin, timeMax) into windows of at most days days,
+// returning the first maxFetchSuggestions of them.
+func splitRange(timeMin, timeMax time.Time, days int) []FetchSuggestion {
+	var windows []FetchSuggestion
+	for start := timeMin; start.Before(timeMax) && len(windows) < maxFetchSuggestions; start = start.AddDate(0, 0, days) {
+		end := start.AddDate(0, 0, days)
+		if end.After(timeMax) {
+			end = timeMax
+		}
+		windows = append(windows, FetchSuggestion{TimeMin: start.Format(time.RFC3339), TimeMax: end.Format(time.RFC3339)})
+	}
+	return windows
+}
+
+// checkResults returns a FetchLimitError if maxResults is above MaxResults,
+// suggesting the largest allowed value and, when the range is known, the same
+// value over the first half of the range.
+func (l FetchLimits) checkResults(maxResults int, timeMin, timeMax time.Time) error {
+	if l.MaxResults <= 0 || maxResults <= l.MaxResults {
+		return nil
+	}
+	err := &FetchLimitError{
+		Limit:       "max_results",
+		Requested:   maxResults,
+		Max:         l.MaxResults,
+		Setting:     maxResultsEnv,
+		Suggestions: []FetchSuggestion{{MaxResults: l.MaxResults}},
+	}
+	if !timeMin.IsZero() && timeMax.After(timeMin) {
+		middle := timeMin.Add(timeMax.Sub(timeMin) / 2)
+		err.Suggestions = append(err.Suggestions, FetchSuggestion{
+			TimeMin:    timeMin.Format(time.RFC3339),
+			TimeMax:    middle.Format(time.RFC3339),
+			MaxResults: l.MaxResults,
+		})
+	}
+	return err
+}
+
+// checkCalendars returns a FetchLimitError if more than MaxCalendars
+// calendars are queried at once, suggesting batches that fit.
+func (l FetchLimits) checkCalendars(calendarIDs []string) error {
+	if l.MaxCalendars <= 0 || len(calendarIDs) <= l.MaxCalendars {
+		return nil
+	}
+	err := &FetchLimitError{
+		Limit:     "max_calendars",
+		Requested: len(calendarIDs),
+		Max:       l.MaxCalendars,
+		Setting:   maxCalendarsEnv,
+	}
+	for start := 0; start < len(calendarIDs) && len(err.Suggestions) < maxFetchSuggestions; start += l.MaxCalendars {
+		end := min(start+l.MaxCalendars, len(calendarIDs))
+		err.Suggestions = append(err.Suggestions, FetchSuggestion{Calendars: calendarIDs[start:end]})
+	}
+	return err
+}
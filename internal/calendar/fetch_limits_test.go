@@ -0,0 +1,131 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ----- checkRange -----
+
+func TestCheckRange(t *testing.T) {
+	limits := FetchLimits{MaxRangeDays: 92}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := limits.checkRange(start, start.AddDate(0, 0, 92)); err != nil {
+		t.Errorf("range at the limit rejected: %v", err)
+	}
+	if err := (FetchLimits{}).checkRange(start, start.AddDate(5, 0, 0)); err != nil {
+		t.Errorf("disabled limit rejected a range: %v", err)
+	}
+
+	err := limits.checkRange(start, start.AddDate(2, 0, 0))
+	var limitErr *FetchLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("checkRange() = %v, want FetchLimitError", err)
+	}
+	if limitErr.Limit != "max_range_days" || limitErr.Requested != 730 || limitErr.Max != 92 {
+		t.Errorf("error = %+v", limitErr)
+	}
+	if len(limitErr.Suggestions) != maxFetchSuggestions {
+		t.Fatalf("got %d suggestions, want %d", len(limitErr.Suggestions), maxFetchSuggestions)
+	}
+	first, second := limitErr.Suggestions[0], limitErr.Suggestions[1]
+	if first.TimeMin != "2025-01-01T00:00:00Z" || first.TimeMax != "2025-04-03T00:00:00Z" || second.TimeMin != first.TimeMax {
+		t.Errorf("suggestions = %+v, want consecutive 92-day windows", limitErr.Suggestions)
+	}
+	if !strings.Contains(err.Error(), maxRangeDaysEnv) || !strings.Contains(err.Error(), `"time_min": "2025-01-01T00:00:00Z"`) {
+		t.Errorf("error message = %q", err.Error())
+	}
+}
+
+func TestSplitRange_LastWindowEndsAtRange(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := splitRange(start, start.AddDate(0, 0, 100), 60)
+	want := []FetchSuggestion{
+		{TimeMin: "2025-01-01T00:00:00Z", TimeMax: "2025-03-02T00:00:00Z"},
+		{TimeMin: "2025-03-02T00:00:00Z", TimeMax: "2025-04-11T00:00:00Z"},
+	}
+	if !reflect.DeepEqual(windows, want) {
+		t.Errorf("splitRange() = %+v, want %+v", windows, want)
+	}
+}
+
+// ----- checkResults -----
+
+func TestCheckResults(t *testing.T) {
+	limits := FetchLimits{MaxResults: 2500}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := limits.checkResults(2500, time.Time{}, time.Time{}); err != nil {
+		t.Errorf("max_results at the limit rejected: %v", err)
+	}
+
+	var limitErr *FetchLimitError
+	if !errors.As(limits.checkResults(10000, time.Time{}, time.Time{}), &limitErr) {
+		t.Fatal("want FetchLimitError")
+	}
+	if len(limitErr.Suggestions) != 1 || limitErr.Suggestions[0].MaxResults != 2500 {
+		t.Errorf("suggestions without a range = %+v", limitErr.Suggestions)
+	}
+
+	if !errors.As(limits.checkResults(10000, start, start.AddDate(0, 0, 30)), &limitErr) {
+		t.Fatal("want FetchLimitError")
+	}
+	if len(limitErr.Suggestions) != 2 || limitErr.Suggestions[1].TimeMax != "2025-01-16T00:00:00Z" {
+		t.Errorf("suggestions with a range = %+v, want the first half of it", limitErr.Suggestions)
+	}
+}
+
+// ----- checkCalendars -----
+
+func TestCheckCalendars(t *testing.T) {
+	limits := FetchLimits{MaxCalendars: 2}
+	ids := []string{"a@x.com", "b@x.com", "c@x.com", "d@x.com", "e@x.com"}
+
+	if err := limits.checkCalendars(ids[:2]); err != nil {
+		t.Errorf("calendars at the limit rejected: %v", err)
+	}
+
+	var limitErr *FetchLimitError
+	if !errors.As(limits.checkCalendars(ids), &limitErr) {
+		t.Fatal("want FetchLimitError")
+	}
+	want := []FetchSuggestion{
+		{Calendars: []string{"a@x.com", "b@x.com"}},
+		{Calendars: []string{"c@x.com", "d@x.com"}},
+		{Calendars: []string{"e@x.com"}},
+	}
+	if limitErr.Requested != 5 || !reflect.DeepEqual(limitErr.Suggestions, want) {
+		t.Errorf("error = %+v, want batches %+v", limitErr, want)
+	}
+}
+
+// ----- FetchLimitsFromEnv -----
+
+func TestFetchLimitsFromEnv(t *testing.T) {
+	t.Setenv(maxRangeDaysEnv, "31")
+	t.Setenv(maxResultsEnv, "0")
+	t.Setenv(maxCalendarsEnv, "many")
+
+	want := FetchLimits{MaxRangeDays: 31, MaxResults: 0, MaxCalendars: DefaultFetchLimits.MaxCalendars}
+	if got := FetchLimitsFromEnv(); got != want {
+		t.Errorf("FetchLimitsFromEnv() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// followupSearchDays is how many days from the target day
+	// schedule_followup looks for a time everyone is free
+	followupSearchDays = 5
+	// followupDescriptionLength bounds the original description carried
+	// over to a follow-up
+	followupDescriptionLength = 500
+	// meetDescriptionMarker starts the joining instructions Google appends
+	// to some descriptions; they belong to the original's conference
+	meetDescriptionMarker = "-::~:~::~:~"
+)
+
+// followupTitle is a follow-up's title: the original's, marked as a
+// follow-up once.
+func followupTitle(summary string) string {
+	if strings.HasPrefix(strings.ToLower(summary), "follow-up") {
+		return summary
+	}
+	return "Follow-up: " + summary
+}
+
+// followupDescription starts a follow-up's description with a line naming the
+// original meeting, followed by the start of the original description
+// without its joining instructions or Meet settings.
+func followupDescription(original *calendar.Event, start time.Time, tf TimeFormat) string {
+	header := fmt.Sprintf("Follow-up to '%s' on %s.", eventTitle(original), tf.ShortDate(start))
+	body := original.Description
+	if i := strings.Index(body, meetDescriptionMarker); i >= 0 {
+		body = body[:i]
+	}
+	body = strings.TrimSpace(withMeetSettings(body, MeetSettings{}))
+	if body == "" {
+		return header
+	}
+	return header + "\n\n" + descriptionPreview(body, followupDescriptionLength)
+}
+
+// followupSlot returns the first time from target on when everyone is free:
+// target itself, else the nearest free slot on its day, else on the
+// following weekdays up to followupSearchDays from target.
+func followupSlot(target TimeSlot, busy []TimeSlot, now time.Time, loc *time.Location) (TimeSlot, bool) {
+	for d := 0; d < followupSearchDays; d++ {
+		span := TimeSlot{Start: target.Start.In(loc).AddDate(0, 0, d), End: target.End.In(loc).AddDate(0, 0, d)}
+		if wd := span.Start.Weekday(); d > 0 && (wd == time.Saturday || wd == time.Sunday) {
+			continue
+		}
+		if !span.Start.Before(now) && !overlapsAny(busy, span.Start, span.End) {
+			return span, true
+		}
+		if slot, ok := nearestFreeSlot(span, busy, now, loc); ok {
+			return slot, true
+		}
+	}
+	return TimeSlot{}, false
+}
+
+func (ct *CalendarTools) handleScheduleFollowup(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID := getStringOrDefault(arguments, "event_id", "")
+	if eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	days := getIntOrDefault(arguments, "after_days", 0)
+	weeks := getIntOrDefault(arguments, "after_weeks", 0)
+	if days < 0 || weeks < 0 {
+		return nil, fmt.Errorf("after_days and after_weeks cannot be negative")
+	}
+	if days == 0 && weeks == 0 {
+		weeks = 1
+	}
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarID := ct.calendarID(arguments)
+	original, err := ct.client.GetEvent(calendarID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	start, end, allDay, err := parseEventTimes(original)
+	if err != nil {
+		return nil, fmt.Errorf("cannot schedule a follow-up to '%s': %v", eventTitle(original), err)
+	}
+	if allDay {
+		return nil, fmt.Errorf("cannot schedule a follow-up to '%s': it is an all-day event", eventTitle(original))
+	}
+	loc, err := ct.goalLocation(calendarID, original.Start.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	target := TimeSlot{
+		Start: start.In(loc).AddDate(0, 0, days+7*weeks),
+		End:   end.In(loc).AddDate(0, 0, days+7*weeks),
+	}
+
+	var attendees []AttendeeParams
+	checked := []string{calendarID}
+	for _, a := range original.Attendees {
+		if a.Self {
+			continue
+		}
+		attendees = append(attendees, AttendeeParams{Email: a.Email, DisplayName: a.DisplayName, Optional: a.Optional})
+		if !a.Optional {
+			checked = append(checked, a.Email)
+		}
+	}
+
+	// Everyone's busy time over the days searched, the user's included
+	windowStart := time.Date(target.Start.Year(), target.Start.Month(), target.Start.Day(), 0, 0, 0, 0, loc)
+	response, err := ct.client.GetFreeBusy(FreeBusyParams{
+		TimeMin:     windowStart,
+		TimeMax:     windowStart.AddDate(0, 0, followupSearchDays),
+		TimeZone:    loc.String(),
+		CalendarIDs: checked,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check availability: %v", err)
+	}
+	busy := ct.constraintBusy(windowStart, windowStart.AddDate(0, 0, followupSearchDays))
+	for _, cal := range response.Calendars {
+		busy = append(busy, busySlots(cal)...)
+	}
+
+	tf := ct.client.TimeFormat()
+	slot, ok := followupSlot(target, busy, time.Now(), loc)
+	if !ok {
+		return nil, fmt.Errorf("no time in the %d days from %s when you and the required attendees are all free for %s; try other after_days or after_weeks",
+			followupSearchDays, tf.ShortDate(target.Start), formatDuration(end.Sub(start)))
+	}
+
+	params := EventParams{
+		CalendarID:  calendarID,
+		Summary:     followupTitle(original.Summary),
+		Description: followupDescription(original, start.In(loc), tf),
+		Location:    original.Location,
+		StartTime:   slot.Start,
+		EndTime:     slot.End,
+		TimeZone:    loc.String(),
+		Attendees:   attendees,
+		Visibility:  original.Visibility,
+		ColorID:     original.ColorId,
+		FollowupOf:  original.Id,
+		SendUpdates: sendUpdates,
+	}
+	if meetLink(original) != "" {
+		params.ConferenceData = newMeetConference()
+		// The follow-up is run the same way, e.g. recorded
+		if settings := meetSettingsFromEvent(original); settings != nil {
+			params.Description = withMeetSettings(params.Description, *settings)
+			params.ConferenceData.Notes = settings.conferenceNotes()
+			params.MeetSettings = settings
+		}
+	}
+
+	var notes []string
+	if !slot.Start.Equal(target.Start) {
+		notes = append(notes, fmt.Sprintf("📆 %s, %s was not free for everyone; this is the nearest time that is", tf.ShortDate(target.Start), tf.Clock(target.Start)))
+	}
+	if unchecked := freeBusyErrors(response); len(unchecked) > 0 {
+		notes = append(notes, "⚠️ Availability not checked for: "+strings.Join(unchecked, ", "))
+	}
+
+	if dryRun {
+		var result strings.Builder
+		fmt.Fprintf(&result, "🔁 Would schedule '%s' on %s, %s–%s with %d attendee(s), linked to '%s' — dry run, nothing changed",
+			params.Summary, tf.ShortDate(slot.Start), tf.Clock(slot.Start), tf.Clock(slot.End), len(attendees), eventTitle(original))
+		for _, note := range notes {
+			fmt.Fprintf(&result, "\n%s", note)
+		}
+		return structuredResult(result.String(), map[string]interface{}{
+			"calendar_id": calendarID,
+			"followup_of": original.Id,
+			"dry_run":     true,
+			"summary":     params.Summary,
+			"start":       slot.Start.Format(time.RFC3339),
+			"end":         slot.End.Format(time.RFC3339),
+		}), nil
+	}
+
+	event, err := ct.client.CreateEvent(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the follow-up: %v", err)
+	}
+	notes = append(notes, fmt.Sprintf("🔗 Linked as a follow-up of '%s'; list_linked_events shows the chain", eventTitle(original)))
+	if params.ConferenceData != nil {
+		notes = append(notes, conferenceNote(event))
+	}
+	confirmation := newConfirmation(confirmCreated, calendarID, event, tf)
+	if params.ConferenceData != nil {
+		confirmation.Conference = conferenceInfo(event)
+	}
+	return structuredResult(formatConfirmation(confirmation, confirmation, notes...), event), nil
+}
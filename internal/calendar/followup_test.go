@@ -0,0 +1,74 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestFollowupTitle(t *testing.T) {
+	if got := followupTitle("Roadmap review"); got != "Follow-up: Roadmap review" {
+		t.Errorf("followupTitle = %q", got)
+	}
+	if got := followupTitle("Follow-up: Roadmap review"); got != "Follow-up: Roadmap review" {
+		t.Errorf("a follow-up's follow-up should not be marked twice, got %q", got)
+	}
+}
+
+func TestFollowupDescription(t *testing.T) {
+	start := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	original := &calendar.Event{
+		Summary:     "Roadmap review",
+		Description: "Agenda:\n- Q3 goals\n\n-::~:~::~:~:~:~:~::~:~::-\nJoin with Google Meet: https://meet.google.com/abc-defg-hij",
+	}
+	got := followupDescription(original, start, TimeFormat{})
+	want := "Follow-up to 'Roadmap review' on Mon, Mar 10.\n\nAgenda:\n- Q3 goals"
+	if got != want {
+		t.Errorf("followupDescription = %q, want %q", got, want)
+	}
+
+	original.Description = strings.Repeat("Long notes. ", 100)
+	if got := followupDescription(original, start, TimeFormat{}); len(got) > followupDescriptionLength+100 {
+		t.Errorf("description of %d bytes was not trimmed", len(got))
+	}
+}
+
+func TestFollowupSlot(t *testing.T) {
+	// Tuesday 10:00-11:00
+	target := TimeSlot{Start: time.Date(2025, 3, 11, 10, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 11, 11, 0, 0, 0, time.UTC)}
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	slot, ok := followupSlot(target, nil, now, time.UTC)
+	if !ok || !slot.Start.Equal(target.Start) {
+		t.Errorf("free target: got %v, %v", slot, ok)
+	}
+
+	busy := []TimeSlot{{Start: target.Start, End: target.Start.Add(30 * time.Minute)}}
+	slot, ok = followupSlot(target, busy, now, time.UTC)
+	if want := target.Start.Add(30 * time.Minute); !ok || !slot.Start.Equal(want) {
+		t.Errorf("busy target: got %v, want the nearest free slot at %v", slot, want)
+	}
+
+	// The whole working day is busy: the next weekday at the same time
+	busy = []TimeSlot{{Start: time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)}}
+	slot, ok = followupSlot(target, busy, now, time.UTC)
+	if want := target.Start.AddDate(0, 0, 1); !ok || !slot.Start.Equal(want) {
+		t.Errorf("busy day: got %v, want %v", slot, want)
+	}
+}
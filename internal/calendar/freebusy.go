@@ -0,0 +1,127 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"sync"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// maxFreeBusyCalendars is the most calendars one free/busy query may
+	// name, and the largest calendarExpansionMax the API accepts
+	maxFreeBusyCalendars = 50
+	// freeBusyConcurrency bounds how many chunks of a large query are in
+	// flight at once
+	freeBusyConcurrency = 4
+)
+
+// chunkCalendarIDs drops duplicate IDs and splits the rest into chunks of at
+// most size, preserving order.
+func chunkCalendarIDs(ids []string, size int) [][]string {
+	seen := make(map[string]bool)
+	var chunks [][]string
+	var chunk []string
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		chunk = append(chunk, id)
+		if len(chunk) == size {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// calendarExpansionLimit returns the calendarExpansionMax to send: the
+// requested value clamped to what the API accepts, or the maximum if unset.
+func calendarExpansionLimit(requested int) int {
+	if requested <= 0 || requested > maxFreeBusyCalendars {
+		return maxFreeBusyCalendars
+	}
+	return requested
+}
+
+// mergeFreeBusy combines the responses to the chunks of one query. A group
+// member named in several chunks has the same busy periods in each, so later
+// entries simply replace earlier ones.
+func mergeFreeBusy(responses []*calendar.FreeBusyResponse) *calendar.FreeBusyResponse {
+	merged := &calendar.FreeBusyResponse{Calendars: make(map[string]calendar.FreeBusyCalendar)}
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if merged.Kind == "" {
+			merged.Kind = resp.Kind
+			merged.TimeMin = resp.TimeMin
+			merged.TimeMax = resp.TimeMax
+		}
+		for id, cal := range resp.Calendars {
+			merged.Calendars[id] = cal
+		}
+		for id, group := range resp.Groups {
+			if merged.Groups == nil {
+				merged.Groups = make(map[string]calendar.FreeBusyGroup)
+			}
+			merged.Groups[id] = group
+		}
+	}
+	return merged
+}
+
+// queryFreeBusy sends request once per chunk of calendarIDs, at most
+// freeBusyConcurrency at a time, and merges the results. Any failed chunk
+// fails the whole query, since a partial answer would report busy attendees
+// as free.
+func (c *Client) queryFreeBusy(request calendar.FreeBusyRequest, calendarIDs []string) (*calendar.FreeBusyResponse, error) {
+	chunks := chunkCalendarIDs(calendarIDs, int(request.CalendarExpansionMax))
+	responses := make([]*calendar.FreeBusyResponse, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, freeBusyConcurrency)
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			req := request
+			req.Items = make([]*calendar.FreeBusyRequestItem, len(chunk))
+			for j, id := range chunk {
+				req.Items[j] = &calendar.FreeBusyRequestItem{Id: id}
+			}
+			responses[i], errs[i] = c.service.Freebusy.Query(&req).Do()
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mergeFreeBusy(responses), nil
+}
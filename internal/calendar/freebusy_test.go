@@ -0,0 +1,166 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- chunkCalendarIDs -----
+
+func TestChunkCalendarIDs(t *testing.T) {
+	var ids []string
+	for i := 0; i < 120; i++ {
+		ids = append(ids, fmt.Sprintf("user%d@example.com", i))
+	}
+
+	tests := []struct {
+		name   string
+		ids    []string
+		size   int
+		chunks []int
+	}{
+		{"single chunk", ids[:3], 50, []int{3}},
+		{"exact multiple", ids[:100], 50, []int{50, 50}},
+		{"remainder", ids, 50, []int{50, 50, 20}},
+		{"duplicates dropped", append(ids[:2:2], ids[0], ids[1], ids[2]), 2, []int{2, 1}},
+		{"empty", nil, 50, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkCalendarIDs(tt.ids, tt.size)
+			if len(chunks) != len(tt.chunks) {
+				t.Fatalf("got %d chunks, want %d", len(chunks), len(tt.chunks))
+			}
+			for i, chunk := range chunks {
+				if len(chunk) != tt.chunks[i] {
+					t.Errorf("chunk %d has %d IDs, want %d", i, len(chunk), tt.chunks[i])
+				}
+			}
+		})
+	}
+}
+
+// ----- calendarExpansionLimit -----
+
+func TestCalendarExpansionLimit(t *testing.T) {
+	tests := []struct {
+		requested, want int
+	}{
+		{0, 50},
+		{-1, 50},
+		{10, 10},
+		{50, 50},
+		{200, 50},
+	}
+	for _, tt := range tests {
+		if got := calendarExpansionLimit(tt.requested); got != tt.want {
+			t.Errorf("calendarExpansionLimit(%d) = %d, want %d", tt.requested, got, tt.want)
+		}
+	}
+}
+
+// ----- mergeFreeBusy -----
+
+func TestMergeFreeBusy(t *testing.T) {
+	busy := []*calendar.TimePeriod{{Start: "2025-03-03T09:00:00Z", End: "2025-03-03T10:00:00Z"}}
+	merged := mergeFreeBusy([]*calendar.FreeBusyResponse{
+		{
+			Kind:      "calendar#freeBusy",
+			TimeMin:   "2025-03-03T00:00:00Z",
+			Calendars: map[string]calendar.FreeBusyCalendar{"alice@example.com": {Busy: busy}},
+		},
+		{
+			Kind: "calendar#freeBusy",
+			Calendars: map[string]calendar.FreeBusyCalendar{
+				"bob@example.com":   {},
+				"carol@example.com": {Errors: []*calendar.Error{{Reason: "notFound"}}},
+			},
+			Groups: map[string]calendar.FreeBusyGroup{"team@example.com": {Calendars: []string{"bob@example.com"}}},
+		},
+	})
+
+	if len(merged.Calendars) != 3 {
+		t.Errorf("got %d calendars, want 3", len(merged.Calendars))
+	}
+	if len(merged.Calendars["alice@example.com"].Busy) != 1 {
+		t.Errorf("alice's busy periods lost: %+v", merged.Calendars["alice@example.com"])
+	}
+	if len(merged.Calendars["carol@example.com"].Errors) != 1 {
+		t.Errorf("carol's error lost: %+v", merged.Calendars["carol@example.com"])
+	}
+	if _, ok := merged.Groups["team@example.com"]; !ok {
+		t.Errorf("group lost: %+v", merged.Groups)
+	}
+	if merged.TimeMin != "2025-03-03T00:00:00Z" {
+		t.Errorf("TimeMin = %q, want the first response's", merged.TimeMin)
+	}
+}
+
+// ----- get_attendee_freebusy time filters -----
+
+func TestGetAttendeeFreeBusyTimeFilter(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	for _, d := range []int{1, 3} {
+		start := tomorrow.AddDate(0, 0, d-1).Add(10 * time.Hour)
+		if _, err := store.InsertEvent("primary", &calendar.Event{
+			Summary: fmt.Sprintf("Busy on day %d", d),
+			Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+			End:     &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339)},
+		}, 0); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	result, err := ct.HandleTool("get_attendee_freebusy", map[string]interface{}{
+		"attendee_emails": []interface{}{fake.DemoOwner},
+		"time_filter":     "tomorrow",
+	})
+	if err != nil {
+		t.Fatalf("get_attendee_freebusy: %v", err)
+	}
+	response := result.StructuredContent.(*calendar.FreeBusyResponse)
+	busy := response.Calendars[fake.DemoOwner].Busy
+	if len(busy) != 1 || busy[0].Start != tomorrow.Add(10*time.Hour).Format(time.RFC3339) {
+		t.Errorf("busy tomorrow = %+v, want only the day-1 event", busy)
+	}
+	if response.TimeMin != tomorrow.Format(time.RFC3339) {
+		t.Errorf("timeMin = %s, want the start of tomorrow", response.TimeMin)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"attendee_emails": []interface{}{fake.DemoOwner}},
+		{"attendee_emails": []interface{}{fake.DemoOwner}, "time_filter": "someday"},
+		{"attendee_emails": []interface{}{fake.DemoOwner}, "time_min": tomorrow.Format(time.RFC3339)},
+	} {
+		if _, err := ct.HandleTool("get_attendee_freebusy", args); err == nil || !strings.Contains(err.Error(), "time_") {
+			t.Errorf("%v: expected a missing or invalid range to fail, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// minGap is the shortest freed time worth suggesting a use for
+	minGap = 15 * time.Minute
+	// maxGapLookaheadDays bounds how far ahead suggest_gap_fill looks for
+	// pending invites to pull forward
+	maxGapLookaheadDays = 14
+	// maxPullForward is the most pending invites suggested for one gap
+	maxPullForward = 3
+)
+
+// Kinds of gap-filling options.
+const (
+	gapExtendFocus = "extend_focus"
+	gapPullForward = "pull_forward"
+	gapLeaveFree   = "leave_free"
+)
+
+// GapOption is one way to use freed time. Tool and Arguments, when set, are
+// the single follow-up call that carries it out. Violations are the warnings
+// of the scheduling policy about the moved meeting.
+type GapOption struct {
+	Kind        string                 `json:"kind"`
+	Description string                 `json:"description"`
+	Tool        string                 `json:"tool,omitempty"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Violations  []PolicyViolation      `json:"policy_violations,omitempty"`
+}
+
+// GapSuggestions are the options for the time a declined or cancelled event
+// frees up.
+type GapSuggestions struct {
+	EventID string        `json:"event_id,omitempty"`
+	Summary string        `json:"summary"`
+	Status  string        `json:"status"` // "cancelled", "declined" or "scheduled"
+	Free    []GapInterval `json:"free"`
+	Options []GapOption   `json:"options"`
+	Notes   []string      `json:"notes,omitempty"`
+}
+
+// GapInterval is a free stretch within the freed block.
+type GapInterval struct {
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Minutes int    `json:"minutes"`
+
+	slot TimeSlot
+}
+
+// pullCandidate is a pending invite that fits a free interval.
+type pullCandidate struct {
+	event *calendar.Event
+	slot  TimeSlot // where the invite would move to
+}
+
+// blocksTime reports whether a timed event makes the user busy.
+func blocksTime(e *calendar.Event) bool {
+	return e.Status != "cancelled" && e.Transparency != "transparent" && !selfDeclined(e) &&
+		e.Start != nil && e.Start.DateTime != ""
+}
+
+func isFocusTime(e *calendar.Event) bool {
+	if e.EventType == "focusTime" {
+		return true
+	}
+	return e.ExtendedProperties != nil && e.ExtendedProperties.Private["eventType"] == "focusTime"
+}
+
+// selfNeedsAction reports whether the user has not yet answered the invite.
+func selfNeedsAction(e *calendar.Event) bool {
+	for _, a := range e.Attendees {
+		if a.Self {
+			return a.ResponseStatus == "needsAction" || a.ResponseStatus == ""
+		}
+	}
+	return false
+}
+
+// freeIntervals returns the parts of block, at least minGap long, not covered
+// by any other event that blocks time. exclude is the freed event's ID.
+func freeIntervals(block TimeSlot, events []*calendar.Event, exclude string) []TimeSlot {
+	free := []TimeSlot{block}
+	for _, e := range events {
+		if e.Id == exclude || !blocksTime(e) {
+			continue
+		}
+		start, end, _, err := parseEventTimes(e)
+		if err != nil {
+			continue
+		}
+		var remaining []TimeSlot
+		for _, f := range free {
+			if !eventsOverlap(f.Start, f.End, start, end) {
+				remaining = append(remaining, f)
+				continue
+			}
+			if f.Start.Before(start) {
+				remaining = append(remaining, TimeSlot{Start: f.Start, End: start})
+			}
+			if end.Before(f.End) {
+				remaining = append(remaining, TimeSlot{Start: end, End: f.End})
+			}
+		}
+		free = remaining
+	}
+
+	var result []TimeSlot
+	for _, f := range free {
+		if f.End.Sub(f.Start) >= minGap {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+// focusExtensions offers to stretch focus time that ends where a free
+// interval starts, or starts where one ends, over that interval.
+func focusExtensions(free []TimeSlot, events []*calendar.Event, calendarID string, loc *time.Location, tf TimeFormat) []GapOption {
+	var options []GapOption
+	for _, e := range events {
+		if !isFocusTime(e) || !blocksTime(e) {
+			continue
+		}
+		start, end, _, err := parseEventTimes(e)
+		if err != nil {
+			continue
+		}
+		for _, f := range free {
+			newStart, newEnd := start, end
+			switch {
+			case end.Equal(f.Start):
+				newEnd = f.End
+			case start.Equal(f.End):
+				newStart = f.Start
+			default:
+				continue
+			}
+			options = append(options, GapOption{
+				Kind: gapExtendFocus,
+				Description: fmt.Sprintf("Extend '%s' (%s–%s) to %s–%s",
+					e.Summary, tf.Clock(start.In(loc)), tf.Clock(end.In(loc)), tf.Clock(newStart.In(loc)), tf.Clock(newEnd.In(loc))),
+				Tool: "edit_event",
+				Arguments: map[string]interface{}{
+					"calendar_id": calendarID,
+					"event_id":    e.Id,
+					"start_time":  newStart.In(loc).Format(time.RFC3339),
+					"end_time":    newEnd.In(loc).Format(time.RFC3339),
+				},
+			})
+		}
+	}
+	return options
+}
+
+// pendingInvites returns the unanswered invites after the gap that are short
+// enough to move into a free interval before them, soonest first. events must
+// be in start order.
+func pendingInvites(free []TimeSlot, events []*calendar.Event) []pullCandidate {
+	var candidates []pullCandidate
+	for _, e := range events {
+		if !selfNeedsAction(e) || e.Status == "cancelled" || e.Start == nil || e.Start.DateTime == "" {
+			continue
+		}
+		start, end, _, err := parseEventTimes(e)
+		if err != nil {
+			continue
+		}
+		for _, f := range free {
+			if start.Before(f.End) || end.Sub(start) > f.End.Sub(f.Start) {
+				continue
+			}
+			candidates = append(candidates, pullCandidate{event: e, slot: TimeSlot{Start: f.Start, End: f.Start.Add(end.Sub(start))}})
+			break
+		}
+	}
+	return candidates
+}
+
+// pullForwardOption checks that the user may move the invite, that its other
+// attendees are free in the slot and that the move keeps to the scheduling
+// policy, and returns the option or a note explaining why it was skipped.
+func (ct *CalendarTools) pullForwardOption(c pullCandidate, calendarID string, loc *time.Location, tf TimeFormat) (*GapOption, string) {
+	e := c.event
+	access := ct.client.EventAccess(calendarID, e)
+	if access.Level != accessOrganizer && access.Level != accessGuestEditor {
+		return nil, fmt.Sprintf("'%s' would fit but is organized by %s, who would have to move it", e.Summary, access.organizerName())
+	}
+
+	var others []string
+	for _, a := range e.Attendees {
+		if !a.Self && !a.Resource {
+			others = append(others, a.Email)
+		}
+	}
+	if len(others) > 0 {
+		response, err := ct.client.GetFreeBusy(FreeBusyParams{
+			TimeMin:     c.slot.Start,
+			TimeMax:     c.slot.End,
+			CalendarIDs: others,
+		})
+		if err != nil {
+			return nil, fmt.Sprintf("'%s' would fit but its attendees' availability could not be checked: %v", e.Summary, err)
+		}
+		var busy []string
+		for _, email := range others {
+			if cal, ok := response.Calendars[email]; ok && len(cal.Errors) == 0 && len(cal.Busy) > 0 {
+				busy = append(busy, email)
+			}
+		}
+		if len(busy) > 0 {
+			return nil, fmt.Sprintf("'%s' would fit but %s %s busy then", e.Summary, strings.Join(busy, ", "), pluralVerb(len(busy)))
+		}
+	}
+
+	violations, err := ct.checkSchedulingPolicy(calendarID, meetingSpan{ID: e.Id, Summary: e.Summary, Start: c.slot.Start, End: c.slot.End}, loc.String())
+	if err != nil {
+		return nil, fmt.Sprintf("'%s' would fit but the scheduling policy could not be checked: %v", e.Summary, err)
+	}
+	if blocking := blockingViolations(violations); len(blocking) > 0 {
+		return nil, fmt.Sprintf("'%s' would fit but moving it breaks the scheduling policy: %s", e.Summary, blocking[0].Message)
+	}
+
+	start, _, _, _ := parseEventTimes(e)
+	return &GapOption{
+		Kind: gapPullForward,
+		Description: fmt.Sprintf("Move pending invite '%s' from %s %s to %s–%s (you have not responded yet; attendees are free)",
+			e.Summary, tf.ShortDate(start.In(loc)), tf.Clock(start.In(loc)), tf.Clock(c.slot.Start.In(loc)), tf.Clock(c.slot.End.In(loc))),
+		Tool: "edit_event",
+		Arguments: map[string]interface{}{
+			"calendar_id": calendarID,
+			"event_id":    e.Id,
+			"start_time":  c.slot.Start.In(loc).Format(time.RFC3339),
+			"end_time":    c.slot.End.In(loc).Format(time.RFC3339),
+		},
+		Violations: violations,
+	}, ""
+}
+
+func pluralVerb(n int) string {
+	if n == 1 {
+		return "is"
+	}
+	return "are"
+}
+
+// formatGapSuggestions renders the options, followed by the structured data.
+func formatGapSuggestions(s GapSuggestions, loc *time.Location, tf TimeFormat) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🧩 Time freed by '%s' (%s), %s:\n\n", s.Summary, s.Status, loc.String())
+	for _, f := range s.Free {
+		fmt.Fprintf(&result, "• %s, %s–%s (%d min)\n", tf.ShortDate(f.slot.Start.In(loc)), tf.Clock(f.slot.Start.In(loc)), tf.Clock(f.slot.End.In(loc)), f.Minutes)
+	}
+	if len(s.Free) == 0 {
+		fmt.Fprintf(&result, "• Nothing: other events cover all but less than %d minutes of it\n", int(minGap/time.Minute))
+	}
+
+	result.WriteString("\nOptions:\n")
+	for i, o := range s.Options {
+		fmt.Fprintf(&result, "%d. %s\n", i+1, o.Description)
+		if o.Tool != "" {
+			args, _ := json.Marshal(o.Arguments)
+			fmt.Fprintf(&result, "   → %s %s\n", o.Tool, string(args))
+		}
+		for _, v := range o.Violations {
+			fmt.Fprintf(&result, "   ⚠️ [%s] %s: %s\n", v.Severity, v.Rule, v.Message)
+		}
+	}
+	for _, note := range s.Notes {
+		fmt.Fprintf(&result, "\nℹ️ %s", note)
+	}
+	if len(s.Notes) > 0 {
+		result.WriteString("\n")
+	}
+
+	suggestionsJSON, _ := json.MarshalIndent(s, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(suggestionsJSON))
+	return result.String()
+}
+
+// freedBlock returns the time freed by the event_id argument, or by the
+// start_time and end_time arguments when the event is already gone, with the
+// suggestions header filled in.
+func (ct *CalendarTools) freedBlock(arguments map[string]interface{}, calendarID string) (GapSuggestions, TimeSlot, error) {
+	suggestions := GapSuggestions{Status: "cancelled", Options: []GapOption{}}
+
+	eventID := getStringOrDefault(arguments, "event_id", "")
+	if eventID == "" {
+		startStr := getStringOrDefault(arguments, "start_time", "")
+		endStr := getStringOrDefault(arguments, "end_time", "")
+		if startStr == "" || endStr == "" {
+			return suggestions, TimeSlot{}, fmt.Errorf("event_id, or start_time and end_time of the freed block, is required")
+		}
+		start, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			return suggestions, TimeSlot{}, fmt.Errorf("invalid start_time format: %v", err)
+		}
+		end, err := time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			return suggestions, TimeSlot{}, fmt.Errorf("invalid end_time format: %v", err)
+		}
+		if !end.After(start) {
+			return suggestions, TimeSlot{}, fmt.Errorf("end_time must be after start_time")
+		}
+		suggestions.Summary = "(freed block)"
+		return suggestions, TimeSlot{Start: start, End: end}, nil
+	}
+
+	freed, err := ct.client.getEventForChange(calendarID, eventID)
+	if err != nil {
+		return suggestions, TimeSlot{}, fmt.Errorf("%v (if the event was deleted, pass its start_time and end_time instead)", err)
+	}
+	start, end, allDay, err := parseEventTimes(freed)
+	if err != nil {
+		return suggestions, TimeSlot{}, err
+	}
+	if allDay {
+		return suggestions, TimeSlot{}, fmt.Errorf("'%s' is an all-day event; only timed events free up a block of time", freed.Summary)
+	}
+
+	suggestions.EventID = freed.Id
+	suggestions.Summary = freed.Summary
+	switch {
+	case freed.Status == "cancelled":
+	case selfDeclined(freed):
+		suggestions.Status = "declined"
+	default:
+		suggestions.Status = "scheduled"
+		suggestions.Notes = append(suggestions.Notes, "The event is still on your calendar; these options assume you decline or cancel it first.")
+	}
+	return suggestions, TimeSlot{Start: start, End: end}, nil
+}
+
+func (ct *CalendarTools) handleSuggestGapFill(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	calendarID := ct.calendarID(arguments)
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+	lookahead := getIntOrDefault(arguments, "lookahead_days", 5)
+	if lookahead < 1 || lookahead > maxGapLookaheadDays {
+		return nil, fmt.Errorf("lookahead_days must be between 1 and %d", maxGapLookaheadDays)
+	}
+
+	suggestions, block, err := ct.freedBlock(arguments, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := block.Start, block.End
+
+	// Start the listing before the block so adjacent focus time is included
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID: calendarID,
+		TimeFilter: "custom",
+		TimeMin:    start.Add(-12 * time.Hour),
+		TimeMax:    end.AddDate(0, 0, lookahead),
+		TimeZone:   timezone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	tf := ct.client.TimeFormat()
+	free := freeIntervals(block, events.Items, suggestions.EventID)
+	for _, f := range free {
+		suggestions.Free = append(suggestions.Free, GapInterval{
+			Start:   f.Start.In(loc).Format(time.RFC3339),
+			End:     f.End.In(loc).Format(time.RFC3339),
+			Minutes: int(f.End.Sub(f.Start) / time.Minute),
+			slot:    f,
+		})
+	}
+
+	suggestions.Options = append(suggestions.Options, focusExtensions(free, events.Items, calendarID, loc, tf)...)
+	// Each candidate costs API calls, so only a few are checked
+	pulled := 0
+	for i, c := range pendingInvites(free, events.Items) {
+		if pulled == maxPullForward || i == 2*maxPullForward {
+			break
+		}
+		option, note := ct.pullForwardOption(c, calendarID, loc, tf)
+		if option == nil {
+			suggestions.Notes = append(suggestions.Notes, note)
+			continue
+		}
+		suggestions.Options = append(suggestions.Options, *option)
+		pulled++
+	}
+
+	minutes := 0
+	for _, f := range suggestions.Free {
+		minutes += f.Minutes
+	}
+	suggestions.Options = append(suggestions.Options, GapOption{
+		Kind:        gapLeaveFree,
+		Description: fmt.Sprintf("Leave the %d minutes free (no follow-up call needed)", minutes),
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatGapSuggestions(suggestions, loc, tf),
+		}},
+	}, nil
+}
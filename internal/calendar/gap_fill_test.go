@@ -0,0 +1,176 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func gapTime(t *testing.T, clock string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, "2024-06-10T"+clock+":00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func invite(id, start, end string) *calendar.Event {
+	e := timedEvent(id, id, start, end)
+	e.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "needsAction"}}
+	return e
+}
+
+// ----- freeIntervals -----
+
+func TestFreeIntervals(t *testing.T) {
+	block := TimeSlot{Start: gapTime(t, "10:00"), End: gapTime(t, "12:00")}
+
+	transparent := timedEvent("lunch", "Lunch", "2024-06-10T10:00:00Z", "2024-06-10T12:00:00Z")
+	transparent.Transparency = "transparent"
+	declined := timedEvent("skip", "Skipped", "2024-06-10T10:00:00Z", "2024-06-10T12:00:00Z")
+	declined.Attendees = []*calendar.EventAttendee{{Self: true, ResponseStatus: "declined"}}
+
+	events := []*calendar.Event{
+		timedEvent("freed", "Cancelled sync", "2024-06-10T10:00:00Z", "2024-06-10T12:00:00Z"),
+		timedEvent("a", "Overlapping call", "2024-06-10T10:30:00Z", "2024-06-10T11:00:00Z"),
+		timedEvent("b", "Short chat", "2024-06-10T11:50:00Z", "2024-06-10T12:30:00Z"),
+		transparent,
+		declined,
+	}
+
+	got := freeIntervals(block, events, "freed")
+	want := []TimeSlot{
+		{Start: gapTime(t, "10:00"), End: gapTime(t, "10:30")},
+		{Start: gapTime(t, "11:00"), End: gapTime(t, "11:50")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d intervals %v, want %v", len(got), got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("interval %d = %v–%v, want %v–%v", i, got[i].Start, got[i].End, want[i].Start, want[i].End)
+		}
+	}
+}
+
+func TestFreeIntervals_DropsShortGaps(t *testing.T) {
+	block := TimeSlot{Start: gapTime(t, "10:00"), End: gapTime(t, "11:00")}
+	events := []*calendar.Event{timedEvent("a", "Call", "2024-06-10T10:10:00Z", "2024-06-10T10:50:00Z")}
+	if got := freeIntervals(block, events, "freed"); len(got) != 0 {
+		t.Errorf("got %v, want no interval of at least %v", got, minGap)
+	}
+}
+
+// ----- focusExtensions -----
+
+func TestFocusExtensions(t *testing.T) {
+	free := []TimeSlot{{Start: gapTime(t, "10:00"), End: gapTime(t, "11:00")}}
+
+	before := timedEvent("focus1", "Deep work", "2024-06-10T08:00:00Z", "2024-06-10T10:00:00Z")
+	before.EventType = "focusTime"
+	after := timedEvent("focus2", "Writing", "2024-06-10T11:00:00Z", "2024-06-10T12:00:00Z")
+	after.ExtendedProperties = &calendar.EventExtendedProperties{Private: map[string]string{"eventType": "focusTime"}}
+	notAdjacent := timedEvent("focus3", "Later focus", "2024-06-10T14:00:00Z", "2024-06-10T15:00:00Z")
+	notAdjacent.EventType = "focusTime"
+	meeting := timedEvent("m", "Meeting", "2024-06-10T09:00:00Z", "2024-06-10T10:00:00Z")
+
+	options := focusExtensions(free, []*calendar.Event{before, after, notAdjacent, meeting}, "primary", time.UTC, TimeFormat{Clock24: true})
+	if len(options) != 2 {
+		t.Fatalf("got %d options, want 2: %+v", len(options), options)
+	}
+	if options[0].Arguments["event_id"] != "focus1" || options[0].Arguments["end_time"] != "2024-06-10T11:00:00Z" {
+		t.Errorf("first option = %+v, want focus1 extended to 11:00", options[0].Arguments)
+	}
+	if options[1].Arguments["event_id"] != "focus2" || options[1].Arguments["start_time"] != "2024-06-10T10:00:00Z" {
+		t.Errorf("second option = %+v, want focus2 starting at 10:00", options[1].Arguments)
+	}
+	if options[0].Tool != "edit_event" || options[0].Kind != gapExtendFocus {
+		t.Errorf("option = %+v, want an edit_event extend_focus option", options[0])
+	}
+}
+
+// ----- pendingInvites -----
+
+func TestPendingInvites(t *testing.T) {
+	free := []TimeSlot{{Start: gapTime(t, "10:00"), End: gapTime(t, "11:00")}}
+
+	answered := invite("answered", "2024-06-11T09:00:00Z", "2024-06-11T09:30:00Z")
+	answered.Attendees[0].ResponseStatus = "accepted"
+
+	events := []*calendar.Event{
+		invite("earlier", "2024-06-10T08:00:00Z", "2024-06-10T08:30:00Z"),
+		answered,
+		invite("too-long", "2024-06-11T13:00:00Z", "2024-06-11T14:30:00Z"),
+		invite("fits", "2024-06-12T15:00:00Z", "2024-06-12T15:45:00Z"),
+		timedEvent("no-attendees", "Solo", "2024-06-12T16:00:00Z", "2024-06-12T16:30:00Z"),
+	}
+
+	got := pendingInvites(free, events)
+	if len(got) != 1 || got[0].event.Id != "fits" {
+		t.Fatalf("got %d candidates, want only 'fits'", len(got))
+	}
+	if !got[0].slot.Start.Equal(gapTime(t, "10:00")) || !got[0].slot.End.Equal(gapTime(t, "10:45")) {
+		t.Errorf("slot = %v–%v, want 10:00–10:45", got[0].slot.Start, got[0].slot.End)
+	}
+}
+
+// ----- scheduling policy -----
+
+func TestSuggestGapFill_SchedulingPolicy(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+	if _, err := store.InsertEvent("primary", &calendar.Event{
+		Summary:   "Planning",
+		Start:     &calendar.EventDateTime{DateTime: "2030-03-05T10:00:00Z"},
+		End:       &calendar.EventDateTime{DateTime: "2030-03-05T11:00:00Z"},
+		Attendees: []*calendar.EventAttendee{{Email: fake.DemoOwner, ResponseStatus: "needsAction"}},
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+	args := map[string]interface{}{"start_time": "2030-03-04T16:00:00Z", "end_time": "2030-03-04T18:00:00Z"}
+
+	// Moved to 16:00–17:00 UTC, the invite would end at 18:00 in Berlin
+	for _, tt := range []struct {
+		severity string
+		want     string
+	}{
+		{severityWarn, "⚠️ [warn] evenings free: 'Planning' ends at 18:00, after 17:30"},
+		{severityBlock, "'Planning' would fit but moving it breaks the scheduling policy: 'Planning' ends at 18:00, after 17:30"},
+	} {
+		ct.SetSchedulingPolicy(testPolicy(t, SchedulingRule{Name: "evenings free", Type: ruleNoMeetingsAfter, Time: "17:30", Severity: tt.severity}))
+		result, err := ct.handleSuggestGapFill(args)
+		if err != nil {
+			t.Fatalf("%s: handleSuggestGapFill() error: %v", tt.severity, err)
+		}
+		text := result.Content[0].Text
+		if !strings.Contains(text, tt.want) {
+			t.Errorf("%s: result should contain %q:\n%s", tt.severity, tt.want, text)
+		}
+		if offered := strings.Contains(text, "Move pending invite 'Planning'"); offered != (tt.severity == severityWarn) {
+			t.Errorf("%s: pull forward offered = %v:\n%s", tt.severity, offered, text)
+		}
+	}
+}
@@ -0,0 +1,659 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// goalKey is the private extended property naming the goal a block was
+	// booked for
+	goalKey = "goal"
+	// goalSummaryPrefix starts the title of every goal block
+	goalSummaryPrefix = "🎯 "
+	// defaultGoalBlockMinutes is the length of a goal block when none is given
+	defaultGoalBlockMinutes = 60
+	// minGoalBlockMinutes is the shortest block booked for a goal
+	minGoalBlockMinutes = 30
+	// goalSyncIntervalEnv enables rescheduling goal blocks in the background,
+	// checking the calendar for changes this often (e.g. "10m")
+	goalSyncIntervalEnv = "GCAL_MCP_GOAL_SYNC_INTERVAL"
+	// minGoalSyncInterval is the shortest interval between goal syncs; a sync
+	// that finds changes lists the week and may move several blocks
+	minGoalSyncInterval = time.Minute
+)
+
+// Goal is a weekly amount of time to spend on something, such as "3 hours of
+// writing per week", saved in the profile's preferences. schedule_goals books
+// blocks for it in the allowed hours.
+type Goal struct {
+	Name           string   `json:"name"`
+	MinutesPerWeek int      `json:"minutes_per_week"`
+	BlockMinutes   int      `json:"block_minutes"`
+	Days           []string `json:"days,omitempty"` // weekday names; Monday to Friday when empty
+	Earliest       string   `json:"earliest"`       // HH:MM
+	Latest         string   `json:"latest"`         // HH:MM
+}
+
+// GoalBlock is one block of time booked for a goal.
+type GoalBlock struct {
+	EventID string `json:"event_id,omitempty"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	From    string `json:"from,omitempty"` // where a moved block started before
+
+	event *calendar.Event
+	slot  TimeSlot
+}
+
+// GoalPlan is what scheduling a goal for one week keeps, books, moves and
+// removes. BookedMinutes counts the blocks once the plan is carried out.
+type GoalPlan struct {
+	Goal          string      `json:"goal"`
+	TargetMinutes int         `json:"target_minutes"`
+	BookedMinutes int         `json:"booked_minutes"`
+	ShortMinutes  int         `json:"short_minutes,omitempty"` // no room was found for these
+	Kept          []GoalBlock `json:"kept"`
+	Booked        []GoalBlock `json:"booked"`
+	Moved         []GoalBlock `json:"moved"`   // blocks that now conflicted with another event
+	Removed       []GoalBlock `json:"removed"` // conflicting blocks with nowhere to go
+	Errors        []string    `json:"errors,omitempty"`
+}
+
+// goalName normalizes a goal name to the key it is stored under.
+func goalName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// goalOf returns the goal a block was booked for, or "".
+func goalOf(e *calendar.Event) string {
+	if e == nil || e.ExtendedProperties == nil {
+		return ""
+	}
+	return e.ExtendedProperties.Private[goalKey]
+}
+
+// validate checks a goal and fills in its defaults.
+func (g *Goal) validate() error {
+	if g.MinutesPerWeek < minGoalBlockMinutes || g.MinutesPerWeek > 7*24*60 {
+		return fmt.Errorf("minutes_per_week must be between %d and %d", minGoalBlockMinutes, 7*24*60)
+	}
+	if g.BlockMinutes == 0 {
+		g.BlockMinutes = defaultGoalBlockMinutes
+	}
+	if g.BlockMinutes < minGoalBlockMinutes || g.BlockMinutes%int(availabilityStep/time.Minute) != 0 {
+		return fmt.Errorf("block_minutes must be a multiple of %d", int(availabilityStep/time.Minute))
+	}
+	if g.Earliest == "" {
+		g.Earliest = "09:00"
+	}
+	if g.Latest == "" {
+		g.Latest = "17:00"
+	}
+	earliest, err := parseClock(g.Earliest)
+	if err != nil {
+		return fmt.Errorf("earliest: %v", err)
+	}
+	latest, err := parseClock(g.Latest)
+	if err != nil {
+		return fmt.Errorf("latest: %v", err)
+	}
+	if latest-earliest < time.Duration(g.BlockMinutes)*time.Minute {
+		return fmt.Errorf("a %d-minute block does not fit between %s and %s", g.BlockMinutes, g.Earliest, g.Latest)
+	}
+	for _, day := range g.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return fmt.Errorf("invalid day %q", day)
+		}
+	}
+	return nil
+}
+
+// weekdays returns the days blocks may be booked on.
+func (g Goal) weekdays() map[time.Weekday]bool {
+	days := make(map[time.Weekday]bool)
+	for _, name := range g.Days {
+		if d, ok := parseWeekday(name); ok {
+			days[d] = true
+		}
+	}
+	if len(days) == 0 {
+		for d := time.Monday; d <= time.Friday; d++ {
+			days[d] = true
+		}
+	}
+	return days
+}
+
+// goalWeekStart returns local midnight on the first day of the week holding
+// now, offset by weeks.
+func goalWeekStart(now time.Time, weekStart time.Weekday, weeks int) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	back := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return day.AddDate(0, 0, 7*weeks-back)
+}
+
+func newGoalBlock(e *calendar.Event, slot TimeSlot) GoalBlock {
+	b := GoalBlock{Start: slot.Start.Format(time.RFC3339), End: slot.End.Format(time.RFC3339), event: e, slot: slot}
+	if e != nil {
+		b.EventID = e.Id
+	}
+	return b
+}
+
+// planGoal schedules goal in the week from weekStart. Blocks already booked
+// for it are kept unless they now overlap something in busy; those are moved
+// to a free slot of the same length, or removed when there is none. Blocks
+// that already ended always count. New blocks are then booked, one per day
+// first, until the weekly minutes are reached. busy must not include the
+// goal's own blocks; the slots the plan takes are appended to it, so goals
+// planned one after another never share a slot.
+func planGoal(goal Goal, blocks []*calendar.Event, busy *[]TimeSlot, weekStart, now time.Time) GoalPlan {
+	plan := GoalPlan{Goal: goal.Name, TargetMinutes: goal.MinutesPerWeek,
+		Kept: []GoalBlock{}, Booked: []GoalBlock{}, Moved: []GoalBlock{}, Removed: []GoalBlock{}}
+	loc := weekStart.Location()
+	usedDays := make(map[string]bool)
+	take := func(slot TimeSlot) {
+		*busy = append(*busy, slot)
+		usedDays[slot.Start.In(loc).Format("2006-01-02")] = true
+		plan.BookedMinutes += int(slot.End.Sub(slot.Start) / time.Minute)
+	}
+
+	var conflicting []GoalBlock
+	for _, e := range blocks {
+		start, end, _, err := parseEventTimes(e)
+		if err != nil || e.Status == "cancelled" {
+			continue
+		}
+		block := newGoalBlock(e, TimeSlot{Start: start, End: end})
+		if end.After(now) && overlapsAny(*busy, start, end) {
+			conflicting = append(conflicting, block)
+			continue
+		}
+		plan.Kept = append(plan.Kept, block)
+	}
+	// Kept blocks are taken after checking them all, so two overlapping
+	// blocks of the same goal are not taken for a conflict
+	for _, b := range plan.Kept {
+		take(b.slot)
+	}
+
+	for _, b := range conflicting {
+		slot, ok := freeGoalSlot(goal, *busy, usedDays, weekStart, now, b.slot.End.Sub(b.slot.Start))
+		if !ok {
+			plan.Removed = append(plan.Removed, b)
+			continue
+		}
+		moved := newGoalBlock(b.event, slot)
+		moved.From = b.Start
+		plan.Moved = append(plan.Moved, moved)
+		take(slot)
+	}
+
+	for plan.BookedMinutes < goal.MinutesPerWeek {
+		minutes := goal.MinutesPerWeek - plan.BookedMinutes
+		if minutes > goal.BlockMinutes {
+			minutes = goal.BlockMinutes
+		}
+		if minutes < minGoalBlockMinutes {
+			minutes = minGoalBlockMinutes
+		}
+		slot, ok := freeGoalSlot(goal, *busy, usedDays, weekStart, now, time.Duration(minutes)*time.Minute)
+		if !ok {
+			plan.ShortMinutes = goal.MinutesPerWeek - plan.BookedMinutes
+			break
+		}
+		plan.Booked = append(plan.Booked, newGoalBlock(nil, slot))
+		take(slot)
+	}
+	return plan
+}
+
+// freeGoalSlot returns the earliest free slot of length d in the goal's hours
+// of the week that starts after now, preferring days without a block yet.
+func freeGoalSlot(goal Goal, busy []TimeSlot, usedDays map[string]bool, weekStart, now time.Time, d time.Duration) (TimeSlot, bool) {
+	earliest, _ := parseClock(goal.Earliest)
+	latest, _ := parseClock(goal.Latest)
+	days := goal.weekdays()
+	for _, spread := range []bool{true, false} {
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if !days[day.Weekday()] || (spread && usedDays[day.Format("2006-01-02")]) {
+				continue
+			}
+			for offset := earliest; offset+d <= latest; offset += availabilityStep {
+				start := atClock(day, offset)
+				if start.Before(now) {
+					continue
+				}
+				if end := start.Add(d); !overlapsAny(busy, start, end) {
+					return TimeSlot{Start: start, End: end}, true
+				}
+			}
+		}
+	}
+	return TimeSlot{}, false
+}
+
+// goals returns the profile's goals sorted by name.
+func (ct *CalendarTools) goals() []Goal {
+	var goals []Goal
+	if ct.prefs == nil {
+		return goals
+	}
+	for _, g := range ct.prefs.Get().Goals {
+		goals = append(goals, g)
+	}
+	sort.Slice(goals, func(i, j int) bool { return goalName(goals[i].Name) < goalName(goals[j].Name) })
+	return goals
+}
+
+// scheduleGoals plans every goal for the week from weekStart on calendarID
+// and, unless dryRun, books, moves and removes the blocks. Failed changes are
+// recorded in the plan's Errors.
+func (ct *CalendarTools) scheduleGoals(calendarID string, weekStart time.Time, dryRun bool) ([]GoalPlan, error) {
+	goals := ct.goals()
+	if len(goals) == 0 {
+		return nil, nil
+	}
+	loc := weekStart.Location()
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      weekStart,
+		TimeMax:      weekStart.AddDate(0, 0, 7),
+		SingleEvents: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the week's events: %v", err)
+	}
+
+	// busy holds other events, availability constraints and the blocks of
+	// goals already planned; blocks of goals not planned yet are added for
+	// each goal
+	busy := ct.constraintBusy(weekStart, weekStart.AddDate(0, 0, 7))
+	blocks := make(map[string][]*calendar.Event)
+	for _, e := range events.Items {
+		if key := goalOf(e); key != "" {
+			blocks[key] = append(blocks[key], e)
+		} else if start, end, _, err := parseEventTimes(e); err == nil && blocksTime(e) {
+			busy = append(busy, TimeSlot{Start: start, End: end})
+		}
+	}
+
+	now := time.Now()
+	planned := make(map[string]bool)
+	plans := make([]GoalPlan, 0, len(goals))
+	for _, goal := range goals {
+		key := goalName(goal.Name)
+		planned[key] = true
+		taken := append([]TimeSlot{}, busy...)
+		for other, list := range blocks {
+			if planned[other] {
+				continue
+			}
+			for _, e := range list {
+				if start, end, _, err := parseEventTimes(e); err == nil && e.Status != "cancelled" {
+					taken = append(taken, TimeSlot{Start: start, End: end})
+				}
+			}
+		}
+
+		plan := planGoal(goal, blocks[key], &taken, weekStart, now)
+		for _, list := range [][]GoalBlock{plan.Kept, plan.Moved, plan.Booked} {
+			for _, b := range list {
+				busy = append(busy, b.slot)
+			}
+		}
+		if !dryRun {
+			ct.applyGoalPlan(calendarID, goal, &plan, loc)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// applyGoalPlan carries out a plan, filling in the IDs of booked blocks.
+func (ct *CalendarTools) applyGoalPlan(calendarID string, goal Goal, plan *GoalPlan, loc *time.Location) {
+	timeZone := loc.String()
+	for i, b := range plan.Booked {
+		created, err := ct.client.BookGoalBlock(calendarID, goal, b.slot, timeZone)
+		if err != nil {
+			plan.Errors = append(plan.Errors, err.Error())
+			continue
+		}
+		plan.Booked[i].EventID = created.Id
+	}
+	for _, b := range plan.Moved {
+		start, end := b.slot.Start, b.slot.End
+		if _, err := ct.client.PatchEventDirect(b.EventID, PatchEventParams{
+			CalendarID: calendarID,
+			StartTime:  &start,
+			EndTime:    &end,
+			TimeZone:   &timeZone,
+		}); err != nil {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("failed to move block %s: %v", b.EventID, err))
+		}
+	}
+	for _, b := range plan.Removed {
+		if err := ct.client.DeleteEvent(calendarID, b.EventID, "none"); err != nil {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("failed to remove block %s: %v", b.EventID, err))
+		}
+	}
+}
+
+// BookGoalBlock creates a block of time for goal. It is private and busy,
+// and marked with the goal's name so later runs find it.
+func (c *Client) BookGoalBlock(calendarID string, goal Goal, slot TimeSlot, timeZone string) (*calendar.Event, error) {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	event := &calendar.Event{
+		Summary:     goalSummaryPrefix + goal.Name,
+		Description: fmt.Sprintf("Booked by schedule_goals toward %d minutes of '%s' per week.", goal.MinutesPerWeek, goal.Name),
+		Visibility:  "private",
+		Start:       &calendar.EventDateTime{DateTime: slot.Start.Format(time.RFC3339), TimeZone: apiTimeZone(timeZone)},
+		End:         &calendar.EventDateTime{DateTime: slot.End.Format(time.RFC3339), TimeZone: apiTimeZone(timeZone)},
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{goalKey: goalName(goal.Name)},
+		},
+	}
+	created, err := c.service.Events.Insert(calendarID, event).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to book a block for '%s': %v", goal.Name, err)
+	}
+	return created, nil
+}
+
+// goalLocation returns the time zone goals are scheduled in: timezone, else
+// the calendar's own time zone, else UTC.
+func (ct *CalendarTools) goalLocation(calendarID, timezone string) (*time.Location, error) {
+	if timezone == "" {
+		timezone = "UTC"
+		if entry, err := ct.client.CalendarListEntry(calendarID); err == nil && entry.TimeZone != "" {
+			timezone = entry.TimeZone
+		}
+	}
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+	return loc, nil
+}
+
+// GoalSyncIntervalFromEnv returns how often goal blocks are rescheduled in
+// the background (GCAL_MCP_GOAL_SYNC_INTERVAL), or 0 when it is disabled.
+func GoalSyncIntervalFromEnv() time.Duration {
+	value := os.Getenv(goalSyncIntervalEnv)
+	if value == "" {
+		return 0
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", goalSyncIntervalEnv, value)
+		return 0
+	}
+	return backgroundInterval(interval, minGoalSyncInterval)
+}
+
+// StartGoalSync keeps this week's goal blocks on the default calendar up to
+// date in the background: every interval it checks the calendar for changes
+// with one request and, when something changed (or the week turned over),
+// runs schedule_goals, moving blocks that now conflict and booking what is
+// missing. Checks are skipped while the request budget is low.
+func (ct *CalendarTools) StartGoalSync(interval time.Duration, budget *quota.Budget) {
+	go func() {
+		var synced, week time.Time
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if budgetLow(budget) || len(ct.goals()) == 0 {
+				continue
+			}
+			calendarID := ct.calendarID(nil)
+			loc, err := ct.goalLocation(calendarID, "")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Goal sync: %v\n", err)
+				continue
+			}
+			checked := time.Now()
+			start := goalWeekStart(checked.In(loc), ct.client.WeekStart(), 0)
+			if !synced.IsZero() && start.Equal(week) {
+				changed, err := ct.client.changedSince(calendarID, synced)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Goal sync: %v\n", err)
+					continue
+				}
+				if !changed {
+					synced = checked
+					continue
+				}
+			}
+
+			plans, err := ct.scheduleGoals(calendarID, start, false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Goal sync: %v\n", err)
+				continue
+			}
+			synced, week = checked, start
+			for _, p := range plans {
+				if len(p.Booked)+len(p.Moved)+len(p.Removed)+len(p.Errors) > 0 {
+					fmt.Fprintf(os.Stderr, "Goal sync: '%s': booked %d, moved %d, removed %d block(s); %d error(s)\n",
+						p.Goal, len(p.Booked), len(p.Moved), len(p.Removed), len(p.Errors))
+				}
+			}
+		}
+	}()
+}
+
+func (ct *CalendarTools) handleDefineGoal(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	name := strings.TrimSpace(getStringOrDefault(arguments, "name", ""))
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	hours, ok := arguments["hours_per_week"].(float64)
+	if !ok || hours < 0 {
+		return nil, fmt.Errorf("hours_per_week is required and must not be negative")
+	}
+	key := goalName(name)
+	existing, existed := ct.prefs.Get().Goals[key]
+
+	if hours == 0 {
+		if err := ct.prefs.Update(func(p *Preferences) {
+			goals := make(map[string]Goal, len(p.Goals))
+			for k, v := range p.Goals {
+				goals[k] = v
+			}
+			delete(goals, key)
+			p.Goals = goals
+		}); err != nil {
+			return nil, err
+		}
+		result := fmt.Sprintf("ℹ️ No goal '%s' in profile '%s'; nothing to delete.", name, ct.prefs.Profile())
+		if existed {
+			result = fmt.Sprintf("🗑️ Deleted goal '%s' from profile '%s'. Blocks already booked stay on the calendar.", existing.Name, ct.prefs.Profile())
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{Type: "text", Text: result}},
+		}, nil
+	}
+
+	days, err := stringArguments(arguments, "days")
+	if err != nil {
+		return nil, err
+	}
+	goal := Goal{
+		Name:           name,
+		MinutesPerWeek: int(hours*60 + 0.5),
+		BlockMinutes:   getIntOrDefault(arguments, "block_minutes", defaultGoalBlockMinutes),
+		Days:           days,
+		Earliest:       getStringOrDefault(arguments, "earliest", "09:00"),
+		Latest:         getStringOrDefault(arguments, "latest", "17:00"),
+	}
+	if err := goal.validate(); err != nil {
+		return nil, err
+	}
+	if err := ct.prefs.Update(func(p *Preferences) {
+		goals := make(map[string]Goal, len(p.Goals)+1)
+		for k, v := range p.Goals {
+			goals[k] = v
+		}
+		goals[key] = goal
+		p.Goals = goals
+	}); err != nil {
+		return nil, err
+	}
+
+	verb := "Defined"
+	if existed {
+		verb = "Updated"
+	}
+	result := fmt.Sprintf("✅ %s goal '%s': %s per week in %d-minute blocks, %s–%s on %s.\nRun schedule_goals to book this week's blocks.",
+		verb, goal.Name, formatGoalMinutes(goal.MinutesPerWeek), goal.BlockMinutes, goal.Earliest, goal.Latest, describeGoalDays(goal))
+	goalJSON, _ := json.MarshalIndent(goal, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result + "\n\n" + string(goalJSON)}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleListGoals(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	goals := ct.goals()
+
+	var result strings.Builder
+	profile := defaultProfile
+	if ct.prefs != nil {
+		profile = ct.prefs.Profile()
+	}
+	fmt.Fprintf(&result, "🎯 Goals in profile '%s':\n\n", profile)
+	if len(goals) == 0 {
+		result.WriteString("• None defined; create one with define_goal\n")
+	}
+	for _, g := range goals {
+		fmt.Fprintf(&result, "• %s: %s per week in %d-minute blocks, %s–%s on %s\n",
+			g.Name, formatGoalMinutes(g.MinutesPerWeek), g.BlockMinutes, g.Earliest, g.Latest, describeGoalDays(g))
+	}
+	if goals == nil {
+		goals = []Goal{}
+	}
+	return structuredResult(result.String(), map[string]interface{}{"goals": goals}), nil
+}
+
+func (ct *CalendarTools) handleScheduleGoals(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if len(ct.goals()) == 0 {
+		return nil, fmt.Errorf("no goals defined; create one with define_goal")
+	}
+	calendarID := ct.calendarID(arguments)
+	loc, err := ct.goalLocation(calendarID, getStringOrDefault(arguments, "timezone", ""))
+	if err != nil {
+		return nil, err
+	}
+	weeks := 0
+	switch week := getStringOrDefault(arguments, "week", "this"); week {
+	case "this":
+	case "next":
+		weeks = 1
+	default:
+		return nil, fmt.Errorf("invalid week %q: must be 'this' or 'next'", week)
+	}
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+
+	weekStart := goalWeekStart(time.Now().In(loc), ct.client.WeekStart(), weeks)
+	plans, err := ct.scheduleGoals(calendarID, weekStart, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	text := formatGoalPlans(plans, weekStart, dryRun, ct.client.TimeFormat())
+	return structuredResult(text, map[string]interface{}{
+		"calendar_id": calendarID,
+		"week_start":  weekStart.Format("2006-01-02"),
+		"dry_run":     dryRun,
+		"goals":       plans,
+	}), nil
+}
+
+// formatGoalPlans renders the blocks each goal kept, booked, moved and removed.
+func formatGoalPlans(plans []GoalPlan, weekStart time.Time, dryRun bool, tf TimeFormat) string {
+	loc := weekStart.Location()
+	span := func(b GoalBlock) string {
+		return fmt.Sprintf("%s, %s–%s", tf.ShortDate(b.slot.Start.In(loc)), tf.Clock(b.slot.Start.In(loc)), tf.Clock(b.slot.End.In(loc)))
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "🎯 Goals for the week of %s (%s)", tf.ShortDate(weekStart), loc.String())
+	if dryRun {
+		result.WriteString(" — dry run, nothing changed")
+	}
+	result.WriteString(":\n")
+	for _, p := range plans {
+		fmt.Fprintf(&result, "\n• %s: %s of %s booked\n", p.Goal, formatGoalMinutes(p.BookedMinutes), formatGoalMinutes(p.TargetMinutes))
+		for _, b := range p.Kept {
+			fmt.Fprintf(&result, "  ✔️ %s\n", span(b))
+		}
+		for _, b := range p.Booked {
+			fmt.Fprintf(&result, "  ➕ Booked %s\n", span(b))
+		}
+		for _, b := range p.Moved {
+			from, _ := time.Parse(time.RFC3339, b.From)
+			fmt.Fprintf(&result, "  ↪️ Moved from %s, %s to %s (it overlapped another event)\n", tf.ShortDate(from.In(loc)), tf.Clock(from.In(loc)), span(b))
+		}
+		for _, b := range p.Removed {
+			fmt.Fprintf(&result, "  🗑️ Removed %s: it overlapped another event and no other slot was free\n", span(b))
+		}
+		if p.ShortMinutes > 0 {
+			fmt.Fprintf(&result, "  ⚠️ %s short: no free time left in the goal's hours this week\n", formatGoalMinutes(p.ShortMinutes))
+		}
+		for _, e := range p.Errors {
+			fmt.Fprintf(&result, "  ❌ %s\n", e)
+		}
+	}
+	return result.String()
+}
+
+// formatGoalMinutes formats minutes as hours and minutes, e.g. "2h 30m".
+func formatGoalMinutes(minutes int) string {
+	switch {
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
+
+// describeGoalDays lists the days a goal is booked on.
+func describeGoalDays(g Goal) string {
+	if len(g.Days) == 0 {
+		return "weekdays"
+	}
+	return strings.Join(g.Days, ", ")
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func goalBlockEvent(id string, start, end time.Time) *calendar.Event {
+	return &calendar.Event{
+		Id:    id,
+		Start: &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+		End:   &calendar.EventDateTime{DateTime: end.Format(time.RFC3339)},
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{goalKey: "writing"},
+		},
+	}
+}
+
+// ----- goalWeekStart -----
+
+func TestGoalWeekStart(t *testing.T) {
+	now := time.Date(2025, 3, 5, 15, 30, 0, 0, time.UTC) // a Wednesday
+	if got, want := goalWeekStart(now, time.Monday, 0), time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("this week from Monday = %v, want %v", got, want)
+	}
+	if got, want := goalWeekStart(now, time.Sunday, 1), time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("next week from Sunday = %v, want %v", got, want)
+	}
+	if got, want := goalWeekStart(now, time.Wednesday, 0), time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("week starting today = %v, want %v", got, want)
+	}
+}
+
+// ----- Goal.validate -----
+
+func TestGoalValidate(t *testing.T) {
+	g := Goal{Name: "Writing", MinutesPerWeek: 180}
+	if err := g.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if g.BlockMinutes != defaultGoalBlockMinutes || g.Earliest != "09:00" || g.Latest != "17:00" {
+		t.Errorf("defaults not filled in: %+v", g)
+	}
+
+	for _, bad := range []Goal{
+		{Name: "a", MinutesPerWeek: 10},
+		{Name: "a", MinutesPerWeek: 120, BlockMinutes: 45},
+		{Name: "a", MinutesPerWeek: 120, Earliest: "16:30", Latest: "17:00"},
+		{Name: "a", MinutesPerWeek: 120, Days: []string{"Someday"}},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("validate accepted %+v", bad)
+		}
+	}
+}
+
+// ----- planGoal -----
+
+func TestPlanGoal_BooksOnePerDayFirst(t *testing.T) {
+	weekStart := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC) // Monday
+	goal := Goal{Name: "Writing", MinutesPerWeek: 180}
+	if err := goal.validate(); err != nil {
+		t.Fatal(err)
+	}
+	busy := []TimeSlot{{Start: weekStart.Add(9 * time.Hour), End: weekStart.Add(11 * time.Hour)}}
+
+	plan := planGoal(goal, nil, &busy, weekStart, weekStart)
+	if len(plan.Booked) != 3 || plan.BookedMinutes != 180 || plan.ShortMinutes != 0 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	wantStarts := []time.Time{
+		weekStart.Add(11 * time.Hour), // Monday, after the meeting
+		weekStart.Add(24*time.Hour + 9*time.Hour),
+		weekStart.Add(48*time.Hour + 9*time.Hour),
+	}
+	for i, b := range plan.Booked {
+		if !b.slot.Start.Equal(wantStarts[i]) {
+			t.Errorf("block %d starts %v, want %v", i, b.slot.Start, wantStarts[i])
+		}
+	}
+	if len(busy) != 4 {
+		t.Errorf("booked blocks not added to busy: %d slots", len(busy))
+	}
+}
+
+func TestPlanGoal_KeepsMovesAndRemoves(t *testing.T) {
+	weekStart := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	goal := Goal{Name: "Writing", MinutesPerWeek: 120, Days: []string{"Monday"}, Earliest: "09:00", Latest: "11:00"}
+	if err := goal.validate(); err != nil {
+		t.Fatal(err)
+	}
+	nine, ten := weekStart.Add(9*time.Hour), weekStart.Add(10*time.Hour)
+	blocks := []*calendar.Event{goalBlockEvent("keep", nine, ten), goalBlockEvent("clash", ten, ten.Add(time.Hour))}
+
+	// A meeting now overlaps the 10:00 block and the day is otherwise full
+	busy := []TimeSlot{{Start: ten, End: ten.Add(time.Hour)}}
+	plan := planGoal(goal, blocks, &busy, weekStart, weekStart)
+	if len(plan.Kept) != 1 || plan.Kept[0].EventID != "keep" {
+		t.Errorf("kept = %+v", plan.Kept)
+	}
+	if len(plan.Removed) != 1 || plan.Removed[0].EventID != "clash" || len(plan.Moved) != 0 {
+		t.Errorf("removed = %+v, moved = %+v", plan.Removed, plan.Moved)
+	}
+	if plan.BookedMinutes != 60 || plan.ShortMinutes != 60 {
+		t.Errorf("booked %d, short %d; want 60 and 60", plan.BookedMinutes, plan.ShortMinutes)
+	}
+
+	// With Tuesday allowed too, the block moves there instead
+	goal.Days = []string{"Monday", "Tuesday"}
+	busy = []TimeSlot{{Start: ten, End: ten.Add(time.Hour)}}
+	plan = planGoal(goal, blocks, &busy, weekStart, weekStart)
+	if len(plan.Moved) != 1 || plan.Moved[0].EventID != "clash" || plan.Moved[0].From != ten.Format(time.RFC3339) {
+		t.Fatalf("moved = %+v", plan.Moved)
+	}
+	if want := nine.AddDate(0, 0, 1); !plan.Moved[0].slot.Start.Equal(want) {
+		t.Errorf("moved to %v, want %v", plan.Moved[0].slot.Start, want)
+	}
+	if len(plan.Booked) != 0 || plan.BookedMinutes != 120 {
+		t.Errorf("booked %+v (%d minutes), want nothing new", plan.Booked, plan.BookedMinutes)
+	}
+}
+
+func TestPlanGoal_PastBlocksCount(t *testing.T) {
+	weekStart := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	goal := Goal{Name: "Writing", MinutesPerWeek: 60}
+	if err := goal.validate(); err != nil {
+		t.Fatal(err)
+	}
+	nine := weekStart.Add(9 * time.Hour)
+	// The block was held even though a meeting was added over it afterwards
+	busy := []TimeSlot{{Start: nine, End: nine.Add(time.Hour)}}
+	plan := planGoal(goal, []*calendar.Event{goalBlockEvent("done", nine, nine.Add(time.Hour))}, &busy, weekStart, weekStart.Add(24*time.Hour))
+	if len(plan.Kept) != 1 || len(plan.Booked) != 0 || len(plan.Removed) != 0 {
+		t.Errorf("plan = %+v", plan)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// maxGroupExpansion is the largest groupExpansionMax the free/busy API accepts.
+const maxGroupExpansion = 100
+
+// GroupAvailability is the free/busy result for one Google Group.
+type GroupAvailability struct {
+	Group      string               `json:"group"`
+	Members    []MemberAvailability `json:"members,omitempty"`
+	NotVisible []string             `json:"not_visible,omitempty"` // members whose calendars could not be read
+	Error      string               `json:"error,omitempty"`
+}
+
+// MemberAvailability is the busy time of one expanded group member.
+type MemberAvailability struct {
+	Email string                 `json:"email"`
+	Busy  []*calendar.TimePeriod `json:"busy"`
+}
+
+// groupAvailability collects the expanded members of each group in a
+// free/busy response, sorted by group. expansionMax is the limit the query
+// used, for explaining groups that were too large to expand.
+func groupAvailability(resp *calendar.FreeBusyResponse, expansionMax int) []GroupAvailability {
+	if resp == nil || len(resp.Groups) == 0 {
+		return nil
+	}
+
+	var groups []GroupAvailability
+	for email, group := range resp.Groups {
+		ga := GroupAvailability{Group: email}
+		if len(group.Errors) > 0 {
+			ga.Error = describeGroupError(group.Errors[0], expansionMax)
+		}
+		for _, member := range group.Calendars {
+			cal, ok := resp.Calendars[member]
+			if !ok || len(cal.Errors) > 0 {
+				ga.NotVisible = append(ga.NotVisible, member)
+				continue
+			}
+			ga.Members = append(ga.Members, MemberAvailability{Email: member, Busy: cal.Busy})
+		}
+		sort.Slice(ga.Members, func(i, j int) bool { return ga.Members[i].Email < ga.Members[j].Email })
+		sort.Strings(ga.NotVisible)
+		groups = append(groups, ga)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Group < groups[j].Group })
+	return groups
+}
+
+func describeGroupError(e *calendar.Error, expansionMax int) string {
+	switch e.Reason {
+	case "groupTooBig":
+		if expansionMax < maxGroupExpansion {
+			return fmt.Sprintf("group has more than %d members; raise group_expansion_max (up to %d) or query members individually", expansionMax, maxGroupExpansion)
+		}
+		return fmt.Sprintf("group has more than %d members, the most Google expands; query members individually", maxGroupExpansion)
+	case "notFound":
+		return "group not found or its membership is not visible to you"
+	default:
+		return e.Reason
+	}
+}
+
+// formatGroupAvailability summarizes group expansions: who was expanded, how
+// busy each member is, and whose calendars were not visible.
+func formatGroupAvailability(groups []GroupAvailability) string {
+	var result strings.Builder
+	result.WriteString("👥 Group availability:\n")
+	for _, g := range groups {
+		fmt.Fprintf(&result, "\n%s", g.Group)
+		if g.Error != "" {
+			fmt.Fprintf(&result, " — ⚠️ %s\n", g.Error)
+			continue
+		}
+		fmt.Fprintf(&result, " (%d member(s) visible, %d not visible)\n", len(g.Members), len(g.NotVisible))
+		for _, m := range g.Members {
+			if len(m.Busy) == 0 {
+				fmt.Fprintf(&result, "• %s: free\n", m.Email)
+			} else {
+				fmt.Fprintf(&result, "• %s: %d busy block(s)\n", m.Email, len(m.Busy))
+			}
+		}
+		if len(g.NotVisible) > 0 {
+			fmt.Fprintf(&result, "• Calendar not visible: %s\n", strings.Join(g.NotVisible, ", "))
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- groupAvailability -----
+
+func TestGroupAvailability(t *testing.T) {
+	resp := &calendar.FreeBusyResponse{
+		Groups: map[string]calendar.FreeBusyGroup{
+			"team@example.com": {Calendars: []string{"sam@example.com", "alex@example.com", "kim@example.com"}},
+			"all@example.com":  {Errors: []*calendar.Error{{Reason: "groupTooBig"}}},
+		},
+		Calendars: map[string]calendar.FreeBusyCalendar{
+			"alex@example.com": {Busy: []*calendar.TimePeriod{{Start: "2025-03-03T09:00:00Z", End: "2025-03-03T10:00:00Z"}}},
+			"kim@example.com":  {Busy: []*calendar.TimePeriod{}},
+			"sam@example.com":  {Errors: []*calendar.Error{{Reason: "notFound"}}},
+		},
+	}
+
+	groups := groupAvailability(resp, 50)
+	if len(groups) != 2 || groups[0].Group != "all@example.com" || groups[1].Group != "team@example.com" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+
+	if !strings.Contains(groups[0].Error, "more than 50 members") {
+		t.Errorf("groupTooBig error = %q", groups[0].Error)
+	}
+
+	team := groups[1]
+	if len(team.Members) != 2 || team.Members[0].Email != "alex@example.com" || team.Members[1].Email != "kim@example.com" {
+		t.Errorf("unexpected members: %+v", team.Members)
+	}
+	if len(team.NotVisible) != 1 || team.NotVisible[0] != "sam@example.com" {
+		t.Errorf("unexpected not visible: %v", team.NotVisible)
+	}
+
+	text := formatGroupAvailability(groups)
+	for _, want := range []string{"2 member(s) visible, 1 not visible", "alex@example.com: 1 busy block(s)", "kim@example.com: free", "Calendar not visible: sam@example.com"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatted output missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestGroupAvailability_NoGroups(t *testing.T) {
+	resp := &calendar.FreeBusyResponse{Calendars: map[string]calendar.FreeBusyCalendar{"a@example.com": {}}}
+	if groups := groupAvailability(resp, maxGroupExpansion); groups != nil {
+		t.Errorf("expected nil for response without groups, got %+v", groups)
+	}
+}
+
+func TestDescribeGroupError_AtMaximum(t *testing.T) {
+	got := describeGroupError(&calendar.Error{Reason: "groupTooBig"}, maxGroupExpansion)
+	if !strings.Contains(got, "query members individually") || strings.Contains(got, "raise") {
+		t.Errorf("unexpected message at maximum: %q", got)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+// maxHeatmapDays bounds the horizon of availability_heatmap
+const maxHeatmapDays = 56
+
+// HeatmapParams configures the availability heatmap.
+type HeatmapParams struct {
+	FirstDay        time.Time     // local midnight of the first day considered
+	Days            int           // horizon in days
+	WorkStart       time.Duration // offset of the first hour from midnight
+	WorkEnd         time.Duration // offset of the end of the last hour from midnight
+	IncludeWeekends bool
+}
+
+// HeatmapCell is how available the group is in one weekday hour, across
+// every occurrence of that weekday in the horizon.
+type HeatmapCell struct {
+	Weekday    string  `json:"weekday"`
+	Hour       string  `json:"hour"` // local start time, HH:MM
+	AvgFree    float64 `json:"avg_free"`
+	MinFree    int     `json:"min_free"`
+	AllFree    int     `json:"all_free_days"` // days on which everyone was free
+	Days       int     `json:"days"`          // occurrences of the weekday in the horizon
+	weekdayIdx int     // Monday first
+	offset     time.Duration
+}
+
+// availabilityHeatmap counts, for each weekday and working hour, how many of
+// the participants in busy are free for the whole hour, averaged over every
+// occurrence of that weekday in the horizon. Hours are local wall-clock
+// times, so a cell stays at the same local hour across DST changes.
+func availabilityHeatmap(busy map[string][]TimeSlot, params HeatmapParams) []HeatmapCell {
+	cells := make(map[string]*HeatmapCell)
+	var order []string
+	total := make(map[string]int)
+
+	for d := 0; d < params.Days; d++ {
+		day := params.FirstDay.AddDate(0, 0, d)
+		if !params.IncludeWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		for offset := params.WorkStart; offset+time.Hour <= params.WorkEnd; offset += time.Hour {
+			key := fmt.Sprintf("%d-%d", day.Weekday(), offset)
+			cell, ok := cells[key]
+			if !ok {
+				cell = &HeatmapCell{
+					Weekday:    day.Weekday().String(),
+					Hour:       formatClock(offset),
+					MinFree:    len(busy),
+					weekdayIdx: (int(day.Weekday()) + 6) % 7,
+					offset:     offset,
+				}
+				cells[key] = cell
+				order = append(order, key)
+			}
+
+			start := atClock(day, offset)
+			end := start.Add(time.Hour)
+			free := 0
+			for _, periods := range busy {
+				if !overlapsAny(periods, start, end) {
+					free++
+				}
+			}
+			cell.Days++
+			total[key] += free
+			if free < cell.MinFree {
+				cell.MinFree = free
+			}
+			if free == len(busy) {
+				cell.AllFree++
+			}
+		}
+	}
+
+	result := make([]HeatmapCell, 0, len(order))
+	for _, key := range order {
+		cell := cells[key]
+		cell.AvgFree = float64(total[key]) / float64(cell.Days)
+		result = append(result, *cell)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].weekdayIdx != result[j].weekdayIdx {
+			return result[i].weekdayIdx < result[j].weekdayIdx
+		}
+		return result[i].offset < result[j].offset
+	})
+	return result
+}
+
+// bestHeatmapCells returns up to n cells, most available first.
+func bestHeatmapCells(cells []HeatmapCell, n int) []HeatmapCell {
+	best := append([]HeatmapCell{}, cells...)
+	sort.SliceStable(best, func(i, j int) bool {
+		if best[i].AvgFree != best[j].AvgFree {
+			return best[i].AvgFree > best[j].AvgFree
+		}
+		return best[i].AllFree > best[j].AllFree
+	})
+	if len(best) > n {
+		best = best[:n]
+	}
+	return best
+}
+
+// formatHeatmap renders the cells as an hour × weekday grid of the average
+// number of free participants, followed by the best hours and the data.
+func formatHeatmap(cells []HeatmapCell, participants int, days int, loc *time.Location, unavailable []string) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🗺️ Availability heatmap: average free out of %d over the next %d days (%s)\n\n", participants, days, loc.String())
+	if len(cells) == 0 {
+		result.WriteString("• No working hours in this range\n")
+		return result.String()
+	}
+
+	var weekdays []string
+	var hours []string
+	grid := make(map[string]HeatmapCell)
+	for _, c := range cells {
+		if !containsString(weekdays, c.Weekday) {
+			weekdays = append(weekdays, c.Weekday)
+		}
+		if !containsString(hours, c.Hour) {
+			hours = append(hours, c.Hour)
+		}
+		grid[c.Weekday+" "+c.Hour] = c
+	}
+	sort.Strings(hours)
+
+	result.WriteString("```\n")
+	result.WriteString("      ")
+	for _, w := range weekdays {
+		fmt.Fprintf(&result, " %5s", w[:3])
+	}
+	result.WriteString("\n")
+	for _, h := range hours {
+		result.WriteString(h + " ")
+		for _, w := range weekdays {
+			if c, ok := grid[w+" "+h]; ok {
+				fmt.Fprintf(&result, " %5.1f", c.AvgFree)
+			} else {
+				fmt.Fprintf(&result, " %5s", "")
+			}
+		}
+		result.WriteString("\n")
+	}
+	result.WriteString("```\n\nBest hours:\n")
+	for _, c := range bestHeatmapCells(cells, 5) {
+		fmt.Fprintf(&result, "• %s %s — %.1f of %d free on average; everyone free on %d of %d %ss\n",
+			c.Weekday, c.Hour, c.AvgFree, participants, c.AllFree, c.Days, c.Weekday)
+	}
+	if len(unavailable) > 0 {
+		fmt.Fprintf(&result, "\n⚠️ Free/busy not visible (ignored): %s\n", strings.Join(unavailable, "; "))
+	}
+
+	cellsJSON, _ := json.MarshalIndent(cells, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(cellsJSON))
+	return result.String()
+}
+
+func (ct *CalendarTools) handleAvailabilityHeatmap(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	var calendarIDs []string
+	if values, ok := arguments["attendees"].([]interface{}); ok {
+		for _, v := range values {
+			email, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("all attendee emails must be strings")
+			}
+			calendarIDs = append(calendarIDs, email)
+		}
+	}
+	if len(calendarIDs) == 0 {
+		return nil, fmt.Errorf("attendees is required")
+	}
+	if getBoolOrDefault(arguments, "include_self", true) {
+		calendarIDs = append(calendarIDs, ct.calendarID(arguments))
+	}
+	if err := ct.fetchLimits.checkCalendars(calendarIDs); err != nil {
+		return nil, err
+	}
+
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	days := getIntOrDefault(arguments, "days", 14)
+	if days < 1 || days > maxHeatmapDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", maxHeatmapDays)
+	}
+
+	// Start tomorrow so every day in the horizon is a whole day
+	now := time.Now().In(loc)
+	params := HeatmapParams{
+		FirstDay:        time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc),
+		Days:            days,
+		IncludeWeekends: getBoolOrDefault(arguments, "include_weekends", false),
+	}
+	if params.WorkStart, err = parseClock(getStringOrDefault(arguments, "work_start", "08:00")); err != nil {
+		return nil, fmt.Errorf("invalid work_start: %v", err)
+	}
+	if params.WorkEnd, err = parseClock(getStringOrDefault(arguments, "work_end", "18:00")); err != nil {
+		return nil, fmt.Errorf("invalid work_end: %v", err)
+	}
+	if params.WorkEnd < params.WorkStart+time.Hour {
+		return nil, fmt.Errorf("work_end must be at least an hour after work_start")
+	}
+
+	busy, unavailable, err := ct.recurringBusy(calendarIDs, nil, params.FirstDay, params.FirstDay.AddDate(0, 0, days), timezone)
+	if err != nil {
+		return nil, err
+	}
+	if len(busy) == 0 {
+		return nil, fmt.Errorf("free/busy is not visible for any attendee: %s", strings.Join(unavailable, "; "))
+	}
+
+	cells := availabilityHeatmap(busy, params)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatHeatmap(cells, len(busy), days, loc, unavailable),
+		}},
+	}, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ----- availabilityHeatmap -----
+
+func TestAvailabilityHeatmap(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 3, day, hour, minute, 0, 0, loc)
+	}
+	params := HeatmapParams{
+		FirstDay:  at(6, 0, 0), // a Thursday
+		Days:      14,
+		WorkStart: 9 * time.Hour,
+		WorkEnd:   11 * time.Hour,
+	}
+
+	busy := map[string][]TimeSlot{
+		// Alice is busy every Thursday 9:30-10:00
+		"alice@example.com": {
+			{Start: at(6, 9, 30), End: at(6, 10, 0)},
+			{Start: at(13, 9, 30), End: at(13, 10, 0)},
+		},
+		// Bob is busy on one Friday morning
+		"bob@example.com": {
+			{Start: at(7, 9, 0), End: at(7, 11, 0)},
+		},
+		// Carol has no meetings
+		"carol@example.com": nil,
+	}
+
+	cells := availabilityHeatmap(busy, params)
+	if len(cells) != 10 { // 5 weekdays x 2 hours
+		t.Fatalf("got %d cells, want 10", len(cells))
+	}
+	if cells[0].Weekday != "Monday" || cells[0].Hour != "09:00" {
+		t.Errorf("first cell = %s %s, want Monday 09:00", cells[0].Weekday, cells[0].Hour)
+	}
+
+	byKey := make(map[string]HeatmapCell)
+	for _, c := range cells {
+		byKey[c.Weekday+" "+c.Hour] = c
+	}
+	tests := []struct {
+		key     string
+		avgFree float64
+		minFree int
+		allFree int
+	}{
+		{"Thursday 09:00", 2, 2, 0},
+		{"Thursday 10:00", 3, 3, 2},
+		{"Friday 09:00", 2.5, 2, 1},
+		{"Monday 10:00", 3, 3, 2},
+	}
+	for _, tt := range tests {
+		c, ok := byKey[tt.key]
+		if !ok {
+			t.Errorf("no cell for %s", tt.key)
+			continue
+		}
+		if c.Days != 2 || c.AvgFree != tt.avgFree || c.MinFree != tt.minFree || c.AllFree != tt.allFree {
+			t.Errorf("%s = %+v, want avg %.1f, min %d, all free %d over 2 days", tt.key, c, tt.avgFree, tt.minFree, tt.allFree)
+		}
+	}
+}
+
+func TestAvailabilityHeatmapWeekends(t *testing.T) {
+	params := HeatmapParams{
+		FirstDay:        time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), // a Saturday
+		Days:            2,
+		WorkStart:       9 * time.Hour,
+		WorkEnd:         10 * time.Hour,
+		IncludeWeekends: false,
+	}
+	busy := map[string][]TimeSlot{"alice@example.com": nil}
+
+	if cells := availabilityHeatmap(busy, params); len(cells) != 0 {
+		t.Errorf("got %d cells without weekends, want 0", len(cells))
+	}
+	params.IncludeWeekends = true
+	if cells := availabilityHeatmap(busy, params); len(cells) != 2 {
+		t.Errorf("got %d cells with weekends, want 2", len(cells))
+	}
+}
+
+// ----- formatHeatmap -----
+
+func TestFormatHeatmap(t *testing.T) {
+	cells := []HeatmapCell{
+		{Weekday: "Monday", Hour: "09:00", AvgFree: 1.5, MinFree: 1, AllFree: 1, Days: 2},
+		{Weekday: "Monday", Hour: "10:00", AvgFree: 2, MinFree: 2, AllFree: 2, Days: 2},
+		{Weekday: "Tuesday", Hour: "09:00", AvgFree: 0.5, MinFree: 0, AllFree: 0, Days: 2},
+	}
+	text := formatHeatmap(cells, 2, 7, time.UTC, []string{"dave@example.com (notFound)"})
+
+	for _, want := range []string{
+		"Mon   Tue",
+		"09:00    1.5   0.5",
+		"• Monday 10:00 — 2.0 of 2 free on average; everyone free on 2 of 2 Mondays",
+		"Free/busy not visible (ignored): dave@example.com (notFound)",
+		`"avg_free": 1.5`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("heatmap output missing %q:\n%s", want, text)
+		}
+	}
+	// The best hour is listed first
+	if strings.Index(text, "• Monday 10:00") > strings.Index(text, "• Monday 09:00") {
+		t.Errorf("best hours not ordered by availability:\n%s", text)
+	}
+}
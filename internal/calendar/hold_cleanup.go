@@ -0,0 +1,262 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// holdCleanupIntervalEnv enables releasing stale holds in the background,
+	// e.g. "15m"
+	holdCleanupIntervalEnv = "GCAL_MCP_HOLD_CLEANUP_INTERVAL"
+	// minHoldCleanupInterval is the shortest interval between cleanups; each
+	// run lists the pending holds and confirmed meetings even when nothing
+	// changed, since deadlines pass on their own
+	minHoldCleanupInterval = time.Minute
+)
+
+// Why cleanup_holds releases a hold.
+const (
+	staleConfirmed = "confirmed" // another hold of its group became the meeting
+	staleCancelled = "cancelled" // the meeting confirmed from its group was cancelled
+	staleExpired   = "expired"   // its group's decide_by deadline passed
+	stalePast      = "past"      // its slot has started
+)
+
+// StaleHold is a pending hold cleanup_holds releases.
+type StaleHold struct {
+	EventID  string `json:"event_id"`
+	GroupID  string `json:"group_id"`
+	Summary  string `json:"summary"`
+	Start    string `json:"start"`
+	Reason   string `json:"reason"` // "confirmed", "cancelled", "expired" or "past"
+	Released bool   `json:"released"`
+	Error    string `json:"error,omitempty"`
+}
+
+// staleHolds picks the pending holds to release. confirmed are the events
+// confirmed from a hold group, including cancelled ones.
+func staleHolds(pending, confirmed []*calendar.Event, now time.Time) []StaleHold {
+	meetings := make(map[string]*calendar.Event)
+	for _, e := range confirmed {
+		group := e.ExtendedProperties.Private[holdGroupKey]
+		// A meeting still on the calendar wins over a cancelled one
+		if current, ok := meetings[group]; !ok || current.Status == "cancelled" {
+			meetings[group] = e
+		}
+	}
+
+	var stale []StaleHold
+	for _, hold := range pending {
+		props := hold.ExtendedProperties.Private
+		reason := ""
+		if meeting, ok := meetings[props[holdGroupKey]]; ok {
+			reason = staleConfirmed
+			if meeting.Status == "cancelled" {
+				reason = staleCancelled
+			}
+		} else if decideBy, err := time.Parse(time.RFC3339, props[holdDecideByKey]); err == nil && now.After(decideBy) {
+			reason = staleExpired
+		} else if start, _, _, err := parseEventTimes(hold); err == nil && start.Before(now) {
+			reason = stalePast
+		}
+		if reason == "" {
+			continue
+		}
+		stale = append(stale, StaleHold{
+			EventID: hold.Id,
+			GroupID: props[holdGroupKey],
+			Summary: hold.Summary,
+			Start:   hold.Start.DateTime + hold.Start.Date,
+			Reason:  reason,
+		})
+	}
+	return stale
+}
+
+// listHoldEvents returns the events of calendarID created by create_holds with
+// the given hold status.
+func (c *Client) listHoldEvents(calendarID, status string, showDeleted bool) ([]*calendar.Event, error) {
+	var events []*calendar.Event
+	call := c.service.Events.List(calendarID).
+		PrivateExtendedProperty(holdStatusKey + "=" + status).
+		SingleEvents(true).
+		ShowDeleted(showDeleted).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list holds: %v", err)
+		}
+		for _, e := range page.Items {
+			if e.ExtendedProperties != nil && e.ExtendedProperties.Private[holdGroupKey] != "" {
+				events = append(events, e)
+			}
+		}
+		if page.NextPageToken == "" {
+			return events, nil
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+}
+
+// CleanupHolds finds the stale holds on calendarID (see staleHolds) and,
+// unless dryRun, deletes them. A hold that cannot be deleted is reported with
+// its error.
+func (c *Client) CleanupHolds(calendarID string, now time.Time, dryRun bool) ([]StaleHold, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	pending, err := c.listHoldEvents(calendarID, holdStatusPending, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	confirmed, err := c.listHoldEvents(calendarID, holdStatusConfirmed, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := staleHolds(pending, confirmed, now)
+	if dryRun {
+		return stale, nil
+	}
+	for i := range stale {
+		if err := c.service.Events.Delete(calendarID, stale[i].EventID).Do(); err != nil {
+			stale[i].Error = err.Error()
+			continue
+		}
+		stale[i].Released = true
+	}
+	return stale, nil
+}
+
+func (ct *CalendarTools) handleCleanupHolds(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	calendarID := ct.calendarID(arguments)
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+	stale, err := ct.client.CleanupHolds(calendarID, time.Now(), dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if stale == nil {
+		stale = []StaleHold{}
+	}
+	return structuredResult(formatStaleHolds(stale, dryRun), map[string]interface{}{
+		"calendar_id": calendarID,
+		"dry_run":     dryRun,
+		"holds":       stale,
+	}), nil
+}
+
+// describeStaleReason explains why a hold is released.
+func describeStaleReason(reason string) string {
+	switch reason {
+	case staleConfirmed:
+		return "the meeting was already confirmed from another hold"
+	case staleCancelled:
+		return "the meeting confirmed from these holds was cancelled"
+	case staleExpired:
+		return "the decision deadline passed"
+	default:
+		return "its time has started"
+	}
+}
+
+// formatStaleHolds renders the holds cleanup_holds released or, in a dry
+// run, would release.
+func formatStaleHolds(stale []StaleHold, dryRun bool) string {
+	if len(stale) == 0 {
+		return "✅ No stale holds: every pending hold is still waiting for a decision."
+	}
+	var result strings.Builder
+	if dryRun {
+		fmt.Fprintf(&result, "🔍 %d stale hold(s) would be released (dry run):\n", len(stale))
+	} else {
+		fmt.Fprintf(&result, "🧹 Released %d stale hold(s):\n", countReleased(stale))
+	}
+	for _, h := range stale {
+		status := ""
+		if h.Error != "" {
+			status = fmt.Sprintf(" ⚠️ could not be deleted: %s", h.Error)
+		}
+		fmt.Fprintf(&result, "• %s at %s (hold ID: %s): %s%s\n", h.Summary, h.Start, h.EventID, describeStaleReason(h.Reason), status)
+	}
+	return result.String()
+}
+
+func countReleased(stale []StaleHold) int {
+	n := 0
+	for _, h := range stale {
+		if h.Released {
+			n++
+		}
+	}
+	return n
+}
+
+// HoldCleanupIntervalFromEnv returns how often stale holds are released in
+// the background (GCAL_MCP_HOLD_CLEANUP_INTERVAL), or 0 when it is disabled.
+func HoldCleanupIntervalFromEnv() time.Duration {
+	value := os.Getenv(holdCleanupIntervalEnv)
+	if value == "" {
+		return 0
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", holdCleanupIntervalEnv, value)
+		return 0
+	}
+	return backgroundInterval(interval, minHoldCleanupInterval)
+}
+
+// StartHoldCleanup releases stale holds on the default calendar in the
+// background every interval, as cleanup_holds does. Deadlines pass without
+// the calendar changing, so every run lists the holds; runs are skipped while
+// the request budget is low.
+func (ct *CalendarTools) StartHoldCleanup(interval time.Duration, budget *quota.Budget) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if budgetLow(budget) {
+				continue
+			}
+			stale, err := ct.client.CleanupHolds(ct.calendarID(nil), time.Now(), false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Hold cleanup: %v\n", err)
+				continue
+			}
+			if len(stale) > 0 {
+				fmt.Fprintf(os.Stderr, "Hold cleanup: released %d of %d stale hold(s)\n", countReleased(stale), len(stale))
+			}
+		}
+	}()
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func holdEvent(id, group, status string, start time.Time, props map[string]string) *calendar.Event {
+	private := map[string]string{holdGroupKey: group, holdStatusKey: status}
+	for k, v := range props {
+		private[k] = v
+	}
+	return &calendar.Event{
+		Id:                 id,
+		Summary:            holdPrefix + "Planning",
+		Start:              &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+		End:                &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339)},
+		ExtendedProperties: &calendar.EventExtendedProperties{Private: private},
+	}
+}
+
+// ----- staleHolds -----
+
+func TestStaleHolds(t *testing.T) {
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	later := now.Add(48 * time.Hour)
+
+	cancelled := holdEvent("m2", "g2", holdStatusConfirmed, later, nil)
+	cancelled.Status = "cancelled"
+	confirmed := []*calendar.Event{holdEvent("m1", "g1", holdStatusConfirmed, later, nil), cancelled}
+	pending := []*calendar.Event{
+		holdEvent("orphan", "g1", holdStatusPending, later, nil),
+		holdEvent("after-cancel", "g2", holdStatusPending, later, nil),
+		holdEvent("expired", "g3", holdStatusPending, later, map[string]string{holdDecideByKey: now.Add(-time.Hour).Format(time.RFC3339)}),
+		holdEvent("past", "g4", holdStatusPending, now.Add(-time.Hour), nil),
+		holdEvent("waiting", "g5", holdStatusPending, later, map[string]string{holdDecideByKey: now.Add(time.Hour).Format(time.RFC3339)}),
+	}
+
+	stale := staleHolds(pending, confirmed, now)
+	want := map[string]string{"orphan": staleConfirmed, "after-cancel": staleCancelled, "expired": staleExpired, "past": stalePast}
+	if len(stale) != len(want) {
+		t.Fatalf("got %d stale holds, want %d: %+v", len(stale), len(want), stale)
+	}
+	for _, h := range stale {
+		if want[h.EventID] != h.Reason {
+			t.Errorf("%s: reason %q, want %q", h.EventID, h.Reason, want[h.EventID])
+		}
+	}
+}
+
+// ----- cleanup_holds -----
+
+func TestCleanupHolds(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	later := time.Now().UTC().Truncate(time.Hour).Add(72 * time.Hour)
+	for _, e := range []*calendar.Event{
+		holdEvent("", "g1", holdStatusConfirmed, later, nil),
+		holdEvent("", "g1", holdStatusPending, later.Add(time.Hour), nil),
+		holdEvent("", "g2", holdStatusPending, later, nil),
+	} {
+		if _, err := store.InsertEvent("primary", e, 0); err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+	}
+
+	result, err := ct.HandleTool("cleanup_holds", map[string]interface{}{"dry_run": true})
+	if err != nil {
+		t.Fatalf("cleanup_holds: %v", err)
+	}
+	stale := result.StructuredContent.(map[string]interface{})["holds"].([]StaleHold)
+	if len(stale) != 1 || stale[0].GroupID != "g1" || stale[0].Released {
+		t.Fatalf("dry run = %+v, want the g1 orphan, not released", stale)
+	}
+
+	if _, err := ct.HandleTool("cleanup_holds", nil); err != nil {
+		t.Fatalf("cleanup_holds: %v", err)
+	}
+	pending, err := ct.client.listHoldEvents("primary", holdStatusPending, false)
+	if err != nil {
+		t.Fatalf("listHoldEvents: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ExtendedProperties.Private[holdGroupKey] != "g2" {
+		t.Errorf("pending holds after cleanup = %d, want only the g2 hold", len(pending))
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Extended property keys used to track scheduling holds. All holds created by a
+// single create_holds call share the same holdGroupId so confirm_hold can find
+// and remove the siblings of the chosen slot.
+const (
+	holdPrefix           = "HOLD: "
+	holdGroupKey         = "holdGroupId"
+	holdStatusKey        = "holdStatus"
+	holdSummaryKey       = "holdSummary"
+	holdAttendeesKey     = "holdAttendees"
+	holdDecideByKey      = "holdDecideBy" // RFC3339; cleanup_holds releases the group after it
+	holdStatusPending    = "pending"
+	holdStatusConfirmed  = "confirmed"
+	holdAttendeeSplitter = ","
+)
+
+// HoldSlot is a single candidate time slot for a hold.
+type HoldSlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// CreateHoldsParams holds parameters for placing tentative holds on candidate slots.
+type CreateHoldsParams struct {
+	CalendarID  string     `json:"calendar_id"`
+	Summary     string     `json:"summary"`
+	Description string     `json:"description,omitempty"`
+	TimeZone    string     `json:"timezone,omitempty"`
+	Attendees   []string   `json:"attendees,omitempty"` // invited only when the hold is confirmed
+	Slots       []HoldSlot `json:"slots"`
+	DecideBy    time.Time  `json:"decide_by,omitempty"` // zero when the holds last until their slots start
+}
+
+// ConfirmHoldParams holds parameters for converting a hold into the real meeting.
+type ConfirmHoldParams struct {
+	CalendarID     string `json:"calendar_id"`
+	EventID        string `json:"event_id"` // the hold to keep
+	SendUpdates    string `json:"send_updates,omitempty"`
+	CreateMeetLink bool   `json:"create_meet_link,omitempty"`
+}
+
+// ConfirmHoldResult describes the outcome of confirm_hold.
+type ConfirmHoldResult struct {
+	Event          *calendar.Event `json:"event"`
+	ReleasedHolds  []string        `json:"released_hold_ids"`
+	FailedReleases []string        `json:"failed_release_ids,omitempty"`
+}
+
+// newHoldGroupID returns a random identifier shared by all holds in one request.
+func newHoldGroupID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate hold group id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// isHold reports whether the event is a pending hold created by create_holds.
+func isHold(event *calendar.Event) bool {
+	if event == nil || event.ExtendedProperties == nil || event.ExtendedProperties.Private == nil {
+		return false
+	}
+	props := event.ExtendedProperties.Private
+	return props[holdGroupKey] != "" && props[holdStatusKey] == holdStatusPending
+}
+
+// CreateHolds places a tentative "HOLD:" event on every candidate slot. The holds
+// block the time on the user's calendar without inviting anyone; attendees are
+// remembered in extended properties and invited when a hold is confirmed.
+// Every slot is checked before the first insert, and holds already placed are
+// deleted again when a later insert fails; on error the returned holds are the
+// ones that could not be removed.
+func (c *Client) CreateHolds(params CreateHoldsParams) (string, []*calendar.Event, error) {
+	if params.CalendarID == "" {
+		params.CalendarID = "primary"
+	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return "", nil, err
+	}
+	if len(params.Slots) == 0 {
+		return "", nil, fmt.Errorf("at least one slot is required")
+	}
+
+	for i, slot := range params.Slots {
+		if !slot.EndTime.After(slot.StartTime) {
+			return "", nil, fmt.Errorf("slot %d: end_time must be after start_time", i+1)
+		}
+	}
+
+	groupID, err := newHoldGroupID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	created := make([]*calendar.Event, 0, len(params.Slots))
+	for i, slot := range params.Slots {
+		event := &calendar.Event{
+			Summary:     holdPrefix + params.Summary,
+			Description: params.Description,
+			Status:      "tentative",
+			Start: &calendar.EventDateTime{
+				DateTime: slot.StartTime.Format(time.RFC3339),
+				TimeZone: apiTimeZone(params.TimeZone),
+			},
+			End: &calendar.EventDateTime{
+				DateTime: slot.EndTime.Format(time.RFC3339),
+				TimeZone: apiTimeZone(params.TimeZone),
+			},
+			ExtendedProperties: &calendar.EventExtendedProperties{
+				Private: map[string]string{
+					holdGroupKey:     groupID,
+					holdStatusKey:    holdStatusPending,
+					holdSummaryKey:   params.Summary,
+					holdAttendeesKey: strings.Join(params.Attendees, holdAttendeeSplitter),
+				},
+			},
+		}
+		if !params.DecideBy.IsZero() {
+			event.ExtendedProperties.Private[holdDecideByKey] = params.DecideBy.Format(time.RFC3339)
+		}
+
+		inserted, err := c.service.Events.Insert(params.CalendarID, event).Do()
+		if err != nil {
+			return groupID, c.removeHolds(params.CalendarID, created), fmt.Errorf("failed to create hold for slot %d: %v", i+1, err)
+		}
+		created = append(created, inserted)
+	}
+
+	return groupID, created, nil
+}
+
+// removeHolds deletes holds placed by a CreateHolds call that failed part way,
+// so a group is placed whole or not at all. It returns the holds it could not
+// delete.
+func (c *Client) removeHolds(calendarID string, holds []*calendar.Event) []*calendar.Event {
+	var left []*calendar.Event
+	for _, hold := range holds {
+		if err := c.service.Events.Delete(calendarID, hold.Id).Do(); err != nil {
+			left = append(left, hold)
+		}
+	}
+	return left
+}
+
+// ListHolds returns all pending holds belonging to a hold group.
+func (c *Client) ListHolds(calendarID, groupID string) ([]*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	var holds []*calendar.Event
+	call := c.service.Events.List(calendarID).
+		PrivateExtendedProperty(holdGroupKey + "=" + groupID).
+		SingleEvents(true).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list holds: %v", err)
+		}
+		for _, event := range page.Items {
+			if isHold(event) {
+				holds = append(holds, event)
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return holds, nil
+}
+
+// ConfirmHold turns the chosen hold into the real meeting (restoring its title,
+// confirming it and inviting the remembered attendees) and deletes every other
+// hold from the same group.
+func (c *Client) ConfirmHold(params ConfirmHoldParams) (*ConfirmHoldResult, error) {
+	if params.CalendarID == "" {
+		params.CalendarID = "primary"
+	}
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, err
+	}
+
+	hold, err := c.service.Events.Get(params.CalendarID, params.EventID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %v", err)
+	}
+	if !isHold(hold) {
+		return nil, fmt.Errorf("event %s is not a pending hold", params.EventID)
+	}
+
+	props := hold.ExtendedProperties.Private
+	groupID := props[holdGroupKey]
+
+	summary := props[holdSummaryKey]
+	if summary == "" {
+		summary = strings.TrimPrefix(hold.Summary, holdPrefix)
+	}
+
+	patch := &calendar.Event{
+		Summary: summary,
+		Status:  "confirmed",
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{
+				holdStatusKey: holdStatusConfirmed,
+			},
+		},
+	}
+	if emails := props[holdAttendeesKey]; emails != "" {
+		for _, email := range strings.Split(emails, holdAttendeeSplitter) {
+			patch.Attendees = append(patch.Attendees, &calendar.EventAttendee{Email: email})
+		}
+	}
+	if params.CreateMeetLink {
+		patch.ConferenceData = &calendar.ConferenceData{
+			CreateRequest: &calendar.CreateConferenceRequest{
+				RequestId: newConferenceRequestID(),
+				ConferenceSolutionKey: &calendar.ConferenceSolutionKey{
+					Type: "hangoutsMeet",
+				},
+			},
+		}
+	}
+
+	call := c.service.Events.Patch(params.CalendarID, params.EventID, patch)
+	if params.SendUpdates != "" {
+		call = call.SendUpdates(params.SendUpdates)
+	}
+	if params.CreateMeetLink {
+		call = call.ConferenceDataVersion(1)
+	}
+	confirmed, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm hold: %v", err)
+	}
+
+	result := &ConfirmHoldResult{Event: confirmed, ReleasedHolds: []string{}}
+
+	siblings, err := c.ListHolds(params.CalendarID, groupID)
+	if err != nil {
+		return result, fmt.Errorf("hold confirmed but failed to release other holds: %v", err)
+	}
+	for _, sibling := range siblings {
+		if sibling.Id == confirmed.Id {
+			continue
+		}
+		if err := c.service.Events.Delete(params.CalendarID, sibling.Id).Do(); err != nil {
+			result.FailedReleases = append(result.FailedReleases, sibling.Id)
+			continue
+		}
+		result.ReleasedHolds = append(result.ReleasedHolds, sibling.Id)
+	}
+
+	return result, nil
+}
+
+func (ct *CalendarTools) handleCreateHolds(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	summary := getStringOrDefault(arguments, "summary", "")
+	if summary == "" {
+		return nil, fmt.Errorf("summary is required")
+	}
+
+	slotsSlice, ok := arguments["slots"].([]interface{})
+	if !ok || len(slotsSlice) == 0 {
+		return nil, fmt.Errorf("slots must be a non-empty array")
+	}
+
+	params := CreateHoldsParams{
+		CalendarID:  ct.calendarID(arguments),
+		Summary:     summary,
+		Description: getStringOrDefault(arguments, "description", ""),
+		TimeZone:    getStringOrDefault(arguments, "timezone", ""),
+	}
+
+	if value := getStringOrDefault(arguments, "decide_by", ""); value != "" {
+		decideBy, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decide_by format: %v", err)
+		}
+		if !decideBy.After(time.Now()) {
+			return nil, fmt.Errorf("decide_by must be in the future")
+		}
+		params.DecideBy = decideBy
+	}
+
+	for i, v := range slotsSlice {
+		slotMap, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("slot %d must be an object with start_time and end_time", i+1)
+		}
+		start, err := time.Parse(time.RFC3339, getStringOrDefault(slotMap, "start_time", ""))
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: invalid start_time format: %v", i+1, err)
+		}
+		end, err := time.Parse(time.RFC3339, getStringOrDefault(slotMap, "end_time", ""))
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: invalid end_time format: %v", i+1, err)
+		}
+		params.Slots = append(params.Slots, HoldSlot{StartTime: start, EndTime: end})
+	}
+
+	if attendeesSlice, ok := arguments["attendees"].([]interface{}); ok {
+		for _, v := range attendeesSlice {
+			if email, ok := v.(string); ok && email != "" {
+				params.Attendees = append(params.Attendees, email)
+			}
+		}
+	}
+
+	// Holds propose meeting times, so they are checked against the scheduling policy
+	var warnings []PolicyViolation
+	for i, slot := range params.Slots {
+		violations, err := ct.checkSchedulingPolicy(params.CalendarID, meetingSpan{Summary: summary, Start: slot.StartTime, End: slot.EndTime}, params.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		if blocking := blockingViolations(violations); len(blocking) > 0 {
+			return nil, fmt.Errorf("slot %d breaks the scheduling policy; no holds were placed:\n%s", i+1, describeViolations(blocking))
+		}
+		warnings = append(warnings, violations...)
+	}
+
+	groupID, holds, err := ct.client.CreateHolds(params)
+	if err != nil {
+		if len(holds) > 0 {
+			return nil, fmt.Errorf("%v (%d hold(s) in group %s could not be removed again)", err, len(holds), groupID)
+		}
+		return nil, fmt.Errorf("failed to create holds; no holds were placed: %v", err)
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "✅ Placed %d hold(s) for '%s' (hold group %s):\n\n", len(holds), summary, groupID)
+	for i, hold := range holds {
+		fmt.Fprintf(&result, "%d. %s → %s (hold ID: %s)\n", i+1, hold.Start.DateTime, hold.End.DateTime, hold.Id)
+	}
+	if len(warnings) > 0 {
+		result.WriteString("\n⚠️ Scheduling policy warnings:\n" + describeViolations(warnings))
+	}
+	if !params.DecideBy.IsZero() {
+		fmt.Fprintf(&result, "\n⏳ If no hold is confirmed by %s, cleanup_holds releases them all.", params.DecideBy.Format(time.RFC3339))
+	}
+	result.WriteString("\nUse confirm_hold with the chosen hold ID to book the meeting and release the other holds.")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: result.String(),
+		}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleConfirmHold(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID, ok := arguments["event_id"].(string)
+	if !ok || eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ct.client.ConfirmHold(ConfirmHoldParams{
+		CalendarID:     ct.calendarID(arguments),
+		EventID:        eventID,
+		SendUpdates:    sendUpdates,
+		CreateMeetLink: getBoolOrDefault(arguments, "create_meet_link", false),
+	})
+	if err != nil && result == nil {
+		return nil, err
+	}
+
+	released := fmt.Sprintf("Released %d other hold(s)", len(result.ReleasedHolds))
+	if len(result.FailedReleases) > 0 {
+		released += fmt.Sprintf(" (%d could not be deleted)", len(result.FailedReleases))
+	}
+	notes := []string{released}
+	if err != nil {
+		notes = []string{fmt.Sprintf("⚠️ %v", err)}
+	}
+	notes = append(notes, ct.client.attendeeLocalTimesForEvent(result.Event))
+	if getBoolOrDefault(arguments, "create_meet_link", false) {
+		notes = append(notes, conferenceNote(result.Event))
+	}
+
+	confirmation := newConfirmation(confirmConfirmed, ct.calendarID(arguments), result.Event, ct.client.TimeFormat())
+	if getBoolOrDefault(arguments, "create_meet_link", false) {
+		confirmation.Conference = conferenceInfo(result.Event)
+	}
+	payload := struct {
+		Confirmation
+		ReleasedHolds  []string `json:"released_hold_ids"`
+		FailedReleases []string `json:"failed_release_ids,omitempty"`
+	}{confirmation, result.ReleasedHolds, result.FailedReleases}
+	text := formatConfirmation(confirmation, payload, notes...)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: text,
+		}},
+	}, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- isHold -----
+
+func TestIsHold(t *testing.T) {
+	withProps := func(props map[string]string) *calendar.Event {
+		return &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{Private: props}}
+	}
+	cases := []struct {
+		name  string
+		event *calendar.Event
+		want  bool
+	}{
+		{"nil event", nil, false},
+		{"no extended properties", &calendar.Event{}, false},
+		{"pending hold", withProps(map[string]string{holdGroupKey: "g1", holdStatusKey: holdStatusPending}), true},
+		{"confirmed hold", withProps(map[string]string{holdGroupKey: "g1", holdStatusKey: holdStatusConfirmed}), false},
+		{"missing group", withProps(map[string]string{holdStatusKey: holdStatusPending}), false},
+	}
+	for _, tc := range cases {
+		if got := isHold(tc.event); got != tc.want {
+			t.Errorf("%s: isHold = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// ----- newHoldGroupID -----
+
+func TestNewHoldGroupID(t *testing.T) {
+	id1, err := newHoldGroupID()
+	if err != nil {
+		t.Fatalf("newHoldGroupID() error: %v", err)
+	}
+	id2, _ := newHoldGroupID()
+	if len(id1) != 16 {
+		t.Errorf("expected 16 hex chars, got %q", id1)
+	}
+	if id1 == id2 {
+		t.Error("consecutive hold group IDs should differ")
+	}
+}
+
+// ----- CreateHolds -----
+
+func TestCreateHolds_AllOrNothing(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	inserts := 0
+	failSecond := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				if inserts++; inserts == 2 {
+					return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody, Header: http.Header{}, Request: req}, nil
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+	svc, drv, err := fake.NewServices(store, failSecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(svc, drv)
+	at := func(day, hour int) time.Time { return time.Date(2030, 3, day, hour, 0, 0, 0, time.UTC) }
+	countHolds := func() int {
+		events, _ := store.ListEvents("primary", fake.EventQuery{TimeMin: at(1, 0), TimeMax: at(10, 0)})
+		return len(events)
+	}
+
+	// An invalid slot is refused before anything is inserted
+	_, _, err = client.CreateHolds(CreateHoldsParams{Summary: "Sync", Slots: []HoldSlot{
+		{StartTime: at(4, 10), EndTime: at(4, 11)},
+		{StartTime: at(5, 11), EndTime: at(5, 10)},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "slot 2") || inserts != 0 || countHolds() != 0 {
+		t.Fatalf("err = %v, %d inserts, %d holds; want slot 2 refused with nothing placed", err, inserts, countHolds())
+	}
+
+	// A failed insert removes the holds placed before it
+	_, left, err := client.CreateHolds(CreateHoldsParams{Summary: "Sync", Slots: []HoldSlot{
+		{StartTime: at(4, 10), EndTime: at(4, 11)},
+		{StartTime: at(5, 10), EndTime: at(5, 11)},
+		{StartTime: at(6, 10), EndTime: at(6, 11)},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "slot 2") {
+		t.Fatalf("err = %v, want the second insert to fail", err)
+	}
+	if len(left) != 0 || countHolds() != 0 {
+		t.Errorf("%d holds left over (%d reported), want the first one removed", countHolds(), len(left))
+	}
+}
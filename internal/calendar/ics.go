@@ -0,0 +1,358 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// icsLine is one unfolded content line of an iCalendar file:
+// NAME;PARAM=value:value.
+type icsLine struct {
+	name   string
+	params map[string]string
+	value  string
+	raw    string
+}
+
+// icsDurationPattern matches the RFC 5545 durations used for DURATION, such
+// as PT1H30M, P1D or P2W.
+var icsDurationPattern = regexp.MustCompile(`^([+-]?)P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICS reads the VEVENTs of an iCalendar file as events for
+// Events.Import. Times without a zone ("floating" times) are taken in
+// timeZone. Modified instances of a recurring event (RECURRENCE-ID) share
+// their series' iCalUID and carry originalStartTime. Alarms and VTIMEZONE
+// definitions are ignored, so TZID parameters must be IANA names.
+func parseICS(data, timeZone string) ([]*calendar.Event, error) {
+	loc, err := loadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timeZone, err)
+	}
+
+	var events []*calendar.Event
+	var current *calendar.Event
+	var duration time.Duration
+	var hasDuration bool
+	var depth int // components nested inside the current VEVENT, such as VALARM
+	for i, line := range unfoldICS(data) {
+		parsed, ok := parseICSLine(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d is not an iCalendar property: %q", i+1, line)
+		}
+		switch {
+		case parsed.name == "BEGIN" && strings.EqualFold(parsed.value, "VEVENT") && current == nil:
+			current = &calendar.Event{}
+			duration, hasDuration = 0, false
+			continue
+		case current == nil:
+			continue
+		case parsed.name == "BEGIN":
+			depth++
+			continue
+		case parsed.name == "END" && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		case parsed.name == "END":
+			if err := finishICSEvent(current, duration, hasDuration); err != nil {
+				return nil, err
+			}
+			events = append(events, current)
+			current = nil
+			continue
+		}
+
+		if err := setICSProperty(current, parsed, loc, timeZone); err != nil {
+			return nil, fmt.Errorf("event %s: %v", icsEventName(current), err)
+		}
+		if parsed.name == "DURATION" {
+			d, err := parseICSDuration(parsed.value)
+			if err != nil {
+				return nil, fmt.Errorf("event %s: %v", icsEventName(current), err)
+			}
+			duration, hasDuration = d, true
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("event %s: missing END:VEVENT", icsEventName(current))
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no VEVENT found in the iCalendar data")
+	}
+	return events, nil
+}
+
+// unfoldICS splits iCalendar data into content lines, joining folded lines
+// (continuations start with a space or tab) and dropping blank ones.
+func unfoldICS(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseICSLine splits a content line into its name, parameters and value.
+// Parameter values may be quoted, so the value starts at the first colon
+// outside quotes.
+func parseICSLine(line string) (icsLine, bool) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return icsLine{}, false
+	}
+
+	parsed := icsLine{params: make(map[string]string), value: line[colon+1:], raw: line}
+	parts := splitOutsideQuotes(line[:colon], ';')
+	parsed.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		parsed.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return parsed, true
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// setICSProperty copies one VEVENT property to the event. Properties the
+// Calendar API has no field for are ignored.
+func setICSProperty(event *calendar.Event, line icsLine, loc *time.Location, timeZone string) error {
+	switch line.name {
+	case "UID":
+		event.ICalUID = line.value
+	case "SUMMARY":
+		event.Summary = unescapeICSText(line.value)
+	case "DESCRIPTION":
+		event.Description = unescapeICSText(line.value)
+	case "LOCATION":
+		event.Location = unescapeICSText(line.value)
+	case "DTSTART", "DTEND", "RECURRENCE-ID":
+		dt, err := parseICSTime(line, loc, timeZone)
+		if err != nil {
+			return err
+		}
+		switch line.name {
+		case "DTSTART":
+			event.Start = dt
+		case "DTEND":
+			event.End = dt
+		default:
+			event.OriginalStartTime = dt
+		}
+	case "RRULE", "EXRULE", "RDATE", "EXDATE":
+		event.Recurrence = append(event.Recurrence, line.raw)
+	case "STATUS":
+		event.Status = map[string]string{"CONFIRMED": "confirmed", "TENTATIVE": "tentative", "CANCELLED": "cancelled"}[strings.ToUpper(line.value)]
+	case "SEQUENCE":
+		sequence, err := strconv.ParseInt(line.value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid SEQUENCE %q", line.value)
+		}
+		event.Sequence = sequence
+	case "TRANSP":
+		event.Transparency = strings.ToLower(line.value)
+	case "CLASS":
+		event.Visibility = map[string]string{"PUBLIC": "public", "PRIVATE": "private", "CONFIDENTIAL": "confidential"}[strings.ToUpper(line.value)]
+	case "ORGANIZER":
+		event.Organizer = &calendar.EventOrganizer{Email: icsAddress(line.value), DisplayName: line.params["CN"]}
+	case "ATTENDEE":
+		attendee := &calendar.EventAttendee{
+			Email:          icsAddress(line.value),
+			DisplayName:    line.params["CN"],
+			ResponseStatus: icsResponseStatus(line.params["PARTSTAT"]),
+			Optional:       strings.EqualFold(line.params["ROLE"], "OPT-PARTICIPANT"),
+		}
+		switch strings.ToUpper(line.params["CUTYPE"]) {
+		case "RESOURCE", "ROOM":
+			attendee.Resource = true
+		}
+		event.Attendees = append(event.Attendees, attendee)
+	}
+	return nil
+}
+
+// finishICSEvent fills in the end of an event from its DURATION, or with
+// RFC 5545's defaults when it has neither DTEND nor DURATION: one day for
+// all-day events, the start time otherwise.
+func finishICSEvent(event *calendar.Event, duration time.Duration, hasDuration bool) error {
+	if event.ICalUID == "" {
+		return fmt.Errorf("event %s has no UID", icsEventName(event))
+	}
+	if event.Start == nil {
+		return fmt.Errorf("event %s has no DTSTART", icsEventName(event))
+	}
+	if event.End != nil {
+		return nil
+	}
+	if event.Start.Date != "" {
+		day, _ := time.Parse("2006-01-02", event.Start.Date)
+		if !hasDuration || duration < 24*time.Hour {
+			duration = 24 * time.Hour
+		}
+		event.End = &calendar.EventDateTime{Date: day.Add(duration).Format("2006-01-02")}
+		return nil
+	}
+	start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return err
+	}
+	event.End = &calendar.EventDateTime{DateTime: start.Add(duration).Format(time.RFC3339), TimeZone: event.Start.TimeZone}
+	return nil
+}
+
+// parseICSTime reads a DATE or DATE-TIME value: a date, a UTC time ending in
+// Z, a time in the zone named by TZID, or a floating time taken in loc.
+func parseICSTime(line icsLine, loc *time.Location, timeZone string) (*calendar.EventDateTime, error) {
+	value := strings.TrimSpace(line.value)
+	if strings.EqualFold(line.params["VALUE"], "DATE") || len(value) == 8 {
+		day, err := time.Parse("20060102", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s date %q", line.name, value)
+		}
+		return &calendar.EventDateTime{Date: day.Format("2006-01-02")}, nil
+	}
+
+	zone := apiTimeZone(timeZone)
+	if strings.HasSuffix(value, "Z") {
+		loc, zone = time.UTC, "UTC"
+		value = strings.TrimSuffix(value, "Z")
+	} else if tzid := line.params["TZID"]; tzid != "" {
+		tzLoc, err := loadLocation(tzid)
+		if err != nil {
+			return nil, fmt.Errorf("%s time zone %q: %v", line.name, tzid, err)
+		}
+		loc, zone = tzLoc, apiTimeZone(tzid)
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s time %q", line.name, line.value)
+	}
+	return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339), TimeZone: zone}, nil
+}
+
+// parseICSDuration reads a DURATION value such as PT1H30M.
+func parseICSDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	m := icsDurationPattern.FindStringSubmatch(value)
+	if m == nil || strings.HasSuffix(value, "P") || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+	if m[1] == "-" {
+		return 0, fmt.Errorf("negative DURATION %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if n, err := strconv.Atoi(m[i+2]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	return d, nil
+}
+
+// unescapeICSText undoes the escaping of TEXT values.
+func unescapeICSText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// icsAddress strips the mailto: scheme from a CAL-ADDRESS value.
+func icsAddress(value string) string {
+	if len(value) >= 7 && strings.EqualFold(value[:7], "mailto:") {
+		return value[7:]
+	}
+	return value
+}
+
+func icsResponseStatus(partstat string) string {
+	switch strings.ToUpper(partstat) {
+	case "ACCEPTED":
+		return "accepted"
+	case "DECLINED":
+		return "declined"
+	case "TENTATIVE":
+		return "tentative"
+	default:
+		return "needsAction"
+	}
+}
+
+// icsEventName names an event in parse errors by its UID or summary.
+func icsEventName(event *calendar.Event) string {
+	switch {
+	case event.ICalUID != "":
+		return event.ICalUID
+	case event.Summary != "":
+		return fmt.Sprintf("%q", event.Summary)
+	default:
+		return "(untitled)"
+	}
+}
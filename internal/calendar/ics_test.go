@@ -0,0 +1,159 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+// ----- parseICS -----
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:weekly-1@example.org\r\n" +
+	"SUMMARY:Weekly sync\\, team\r\n" +
+	"DESCRIPTION:Agenda:\\nstatus updates\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250303T100000\r\n" +
+	"DURATION:PT45M\r\n" +
+	"RRULE:FREQ=WEEKLY;COUNT=4\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20250317T100000\r\n" +
+	"ORGANIZER;CN=Alice:mailto:alice@example.org\r\n" +
+	"ATTENDEE;CN=\"Bob; B.\";PARTSTAT=ACCEPTED:mailto:bob@example.com\r\n" +
+	"ATTENDEE;ROLE=OPT-PARTICIPANT;PARTSTAT=TENTATIVE:MAILTO:carol@examp\r\n" +
+	" le.com\r\n" +
+	"SEQUENCE:2\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:weekly-1@example.org\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20250310T100000\r\n" +
+	"SUMMARY:Weekly sync (moved)\r\n" +
+	"DTSTART:20250311T090000Z\r\n" +
+	"DTEND:20250311T094500Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:offsite@example.org\r\n" +
+	"SUMMARY:Offsite\r\n" +
+	"DTSTART;VALUE=DATE:20250320\r\n" +
+	"STATUS:TENTATIVE\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:lunch@example.org\r\n" +
+	"SUMMARY:Lunch\r\n" +
+	"DTSTART:20250321T123000\r\n" +
+	"DTEND:20250321T133000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	events, err := parseICS(sampleICS, "America/New_York")
+	if err != nil {
+		t.Fatalf("parseICS: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4", len(events))
+	}
+
+	series := events[0]
+	if series.ICalUID != "weekly-1@example.org" || series.Summary != "Weekly sync, team" || series.Description != "Agenda:\nstatus updates" {
+		t.Errorf("text fields = %q / %q / %q", series.ICalUID, series.Summary, series.Description)
+	}
+	if series.Start.DateTime != "2025-03-03T10:00:00+01:00" || series.Start.TimeZone != "Europe/Berlin" {
+		t.Errorf("start = %+v, want 10:00 Berlin", series.Start)
+	}
+	if series.End.DateTime != "2025-03-03T10:45:00+01:00" {
+		t.Errorf("end = %+v, want DTSTART plus DURATION", series.End)
+	}
+	if strings.Join(series.Recurrence, "|") != "RRULE:FREQ=WEEKLY;COUNT=4|EXDATE;TZID=Europe/Berlin:20250317T100000" {
+		t.Errorf("recurrence = %v", series.Recurrence)
+	}
+	if series.Organizer.Email != "alice@example.org" || series.Organizer.DisplayName != "Alice" || series.Sequence != 2 {
+		t.Errorf("organizer = %+v, sequence = %d", series.Organizer, series.Sequence)
+	}
+	if len(series.Attendees) != 2 {
+		t.Fatalf("attendees = %+v, want 2", series.Attendees)
+	}
+	bob, carol := series.Attendees[0], series.Attendees[1]
+	if bob.Email != "bob@example.com" || bob.DisplayName != "Bob; B." || bob.ResponseStatus != "accepted" {
+		t.Errorf("bob = %+v", bob)
+	}
+	if carol.Email != "carol@example.com" || !carol.Optional || carol.ResponseStatus != "tentative" {
+		t.Errorf("carol = %+v, want the folded address, optional and tentative", carol)
+	}
+
+	moved := events[1]
+	if moved.ICalUID != series.ICalUID || moved.OriginalStartTime == nil || moved.OriginalStartTime.DateTime != "2025-03-10T10:00:00+01:00" {
+		t.Errorf("exception = %+v, want the series' UID and originalStartTime", moved)
+	}
+	if moved.Start.DateTime != "2025-03-11T09:00:00Z" || moved.Start.TimeZone != "UTC" {
+		t.Errorf("exception start = %+v", moved.Start)
+	}
+
+	offsite := events[2]
+	if offsite.Start.Date != "2025-03-20" || offsite.End.Date != "2025-03-21" || offsite.Status != "tentative" || offsite.Transparency != "transparent" {
+		t.Errorf("all-day event = %+v / %+v, status %q, transparency %q", offsite.Start, offsite.End, offsite.Status, offsite.Transparency)
+	}
+
+	lunch := events[3]
+	if lunch.Start.DateTime != "2025-03-21T12:30:00-04:00" || lunch.Start.TimeZone != "America/New_York" {
+		t.Errorf("floating start = %+v, want it in the timezone argument", lunch.Start)
+	}
+}
+
+func TestParseICS_Errors(t *testing.T) {
+	wrap := func(body string) string {
+		return "BEGIN:VCALENDAR\nBEGIN:VEVENT\n" + body + "END:VEVENT\nEND:VCALENDAR\n"
+	}
+	cases := []struct {
+		name, data, want string
+	}{
+		{"no events", "BEGIN:VCALENDAR\nEND:VCALENDAR\n", "no VEVENT"},
+		{"no UID", wrap("SUMMARY:Lunch\nDTSTART:20250321T123000Z\n"), `"Lunch" has no UID`},
+		{"no start", wrap("UID:a@example.org\n"), "no DTSTART"},
+		{"unknown TZID", wrap("UID:a@example.org\nDTSTART;TZID=Eastern Standard Time:20250321T123000\n"), `time zone "Eastern Standard Time"`},
+		{"bad duration", wrap("UID:a@example.org\nDTSTART:20250321T123000Z\nDURATION:1 hour\n"), "invalid DURATION"},
+		{"unterminated", "BEGIN:VEVENT\nUID:a@example.org\n", "missing END:VEVENT"},
+		{"not a property", wrap("UID:a@example.org\nnonsense\n"), "line 4"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseICS(tc.data, "UTC")
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	cases := map[string]string{"PT1H30M": "1h30m0s", "P1D": "24h0m0s", "P1W": "168h0m0s", "P1DT2H": "26h0m0s", "PT15S": "15s"}
+	for value, want := range cases {
+		d, err := parseICSDuration(value)
+		if err != nil || d.String() != want {
+			t.Errorf("parseICSDuration(%q) = %v, %v; want %s", value, d, err, want)
+		}
+	}
+	for _, value := range []string{"P", "PT", "-PT1H", "1H"} {
+		if _, err := parseICSDuration(value); err == nil {
+			t.Errorf("parseICSDuration(%q) succeeded, want an error", value)
+		}
+	}
+}
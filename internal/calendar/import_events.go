@@ -0,0 +1,275 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Import actions, one per source event.
+const (
+	importCreated = "imported" // new in the target calendar
+	importUpdated = "updated"  // an event with the same iCalUID was already there
+	importSkipped = "skipped"
+	importFailed  = "failed"
+)
+
+// ImportResult reports what import_events did, or would do on a dry run,
+// with one source event.
+type ImportResult struct {
+	ICalUID string `json:"ical_uid"`
+	Title   string `json:"title"`
+	Action  string `json:"action"`
+	EventID string `json:"event_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// importBody copies source for Events.Import. Unlike restoreBody it keeps the
+// iCalUID, sequence, organizer and attendees' responses, which is what makes
+// the copy the same meeting rather than a new invitation. Instances are
+// matched to their series by iCalUID and originalStartTime, so the source
+// calendar's recurringEventId is dropped.
+func importBody(source *calendar.Event) (*calendar.Event, error) {
+	body, err := restoreBody(source)
+	if err != nil {
+		return nil, err
+	}
+	body.Id = ""
+	body.RecurringEventId = ""
+	body.ICalUID = source.ICalUID
+	body.Sequence = source.Sequence
+	return body, nil
+}
+
+// orderForImport puts series and single events before modified instances, so
+// each series exists before its exceptions are imported.
+func orderForImport(events []*calendar.Event) []*calendar.Event {
+	ordered := append([]*calendar.Event(nil), events...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].OriginalStartTime == nil && ordered[j].OriginalStartTime != nil
+	})
+	return ordered
+}
+
+// findByICalUID returns the event of a calendar with the given iCalUID, or
+// nil when there is none. Modified instances share their series' iCalUID and
+// are left out.
+func (c *Client) findByICalUID(calendarID, iCalUID string) (*calendar.Event, error) {
+	page, err := c.service.Events.List(calendarID).ICalUID(iCalUID).Do()
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range page.Items {
+		if event.RecurringEventId == "" && event.Status != "cancelled" {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+// ImportEvents adds private copies of events to a calendar with
+// Events.Import instead of Events.Insert: each copy keeps its iCalUID and
+// organizer, and attendees are not invited again. An event whose iCalUID is
+// already in the calendar is updated in place, as are the modified instances
+// of such a series, so running a migration twice does not duplicate it. With
+// dryRun nothing is written.
+func (c *Client) ImportEvents(calendarID string, events []*calendar.Event, dryRun bool) ([]ImportResult, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	existingSeries := make(map[string]bool)
+	var results []ImportResult
+	for _, source := range orderForImport(events) {
+		result := ImportResult{ICalUID: source.ICalUID, Title: eventTitle(source), Action: importCreated}
+		switch {
+		case source.ICalUID == "":
+			result.Action, result.Reason = importSkipped, "no iCalUID"
+		case source.EventType != "" && source.EventType != "default":
+			result.Action, result.Reason = importSkipped, fmt.Sprintf("%s events cannot be imported", source.EventType)
+		}
+		if result.Action == importSkipped {
+			results = append(results, result)
+			continue
+		}
+
+		switch {
+		case source.OriginalStartTime != nil && existingSeries[source.ICalUID]:
+			result.Action = importUpdated
+		case source.OriginalStartTime == nil:
+			existing, err := c.findByICalUID(calendarID, source.ICalUID)
+			if err != nil {
+				result.Action, result.Reason = importFailed, err.Error()
+				results = append(results, result)
+				continue
+			}
+			if existing != nil {
+				result.Action, result.EventID = importUpdated, existing.Id
+				existingSeries[source.ICalUID] = true
+			}
+		}
+		if !dryRun {
+			imported, err := c.importEvent(calendarID, source)
+			if err != nil {
+				result.Action, result.Reason = importFailed, err.Error()
+			} else {
+				result.EventID = imported.Id
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (c *Client) importEvent(calendarID string, source *calendar.Event) (*calendar.Event, error) {
+	body, err := importBody(source)
+	if err != nil {
+		return nil, err
+	}
+	return c.service.Events.Import(calendarID, body).ConferenceDataVersion(1).SupportsAttachments(true).Do()
+}
+
+// readImportSource loads the events to import from exactly one of
+// source_calendar_id (with time_min and time_max), ics or file, and
+// describes where they came from.
+func (ct *CalendarTools) readImportSource(arguments map[string]interface{}, calendarID string) ([]*calendar.Event, string, error) {
+	sourceCalendar := getStringOrDefault(arguments, "source_calendar_id", "")
+	ics := getStringOrDefault(arguments, "ics", "")
+	file := getStringOrDefault(arguments, "file", "")
+	sources := 0
+	for _, s := range []string{sourceCalendar, ics, file} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, "", fmt.Errorf("pass exactly one of source_calendar_id, ics or file")
+	}
+
+	if sourceCalendar == "" {
+		timeZone := getStringOrDefault(arguments, "timezone", "UTC")
+		if err := validateTimeZone(timeZone); err != nil {
+			return nil, "", err
+		}
+		data, description := ics, "the iCalendar data"
+		if file != "" {
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read %s: %v", file, err)
+			}
+			data, description = string(raw), file
+		}
+		events, err := parseICS(data, timeZone)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid iCalendar data: %v", err)
+		}
+		return events, description, nil
+	}
+
+	if sourceCalendar == calendarID {
+		return nil, "", fmt.Errorf("source_calendar_id and calendar_id are the same calendar")
+	}
+	timeMinStr := getStringOrDefault(arguments, "time_min", "")
+	timeMaxStr := getStringOrDefault(arguments, "time_max", "")
+	if timeMinStr == "" || timeMaxStr == "" {
+		return nil, "", fmt.Errorf("time_min and time_max are required with source_calendar_id")
+	}
+	timeMin, err := time.Parse(time.RFC3339, timeMinStr)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid time_min format: %v", err)
+	}
+	timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid time_max format: %v", err)
+	}
+	if !timeMax.After(timeMin) {
+		return nil, "", fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, "", err
+	}
+	events, err := ct.client.BackupEvents(sourceCalendar, timeMin, timeMax)
+	if err != nil {
+		return nil, "", err
+	}
+	return events, sourceCalendar, nil
+}
+
+func (ct *CalendarTools) handleImportEvents(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	calendarID := ct.calendarID(arguments)
+	events, source, err := ct.readImportSource(arguments, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+
+	results, err := ct.client.ImportEvents(calendarID, events, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: formatImportResults(source, calendarID, results, dryRun)}},
+	}, nil
+}
+
+func formatImportResults(source, calendarID string, results []ImportResult, dryRun bool) string {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Action]++
+	}
+
+	var b strings.Builder
+	if dryRun {
+		fmt.Fprintf(&b, "🔎 Dry run: importing %d events from %s into %s\n", len(results), source, calendarID)
+	} else {
+		fmt.Fprintf(&b, "📥 Imported events from %s into %s (iCalUIDs and organizers kept, no invitations sent)\n", source, calendarID)
+	}
+	fmt.Fprintf(&b, "• New: %d\n• Updated: %d\n", counts[importCreated], counts[importUpdated])
+	if counts[importSkipped] > 0 {
+		fmt.Fprintf(&b, "• Skipped: %d\n", counts[importSkipped])
+	}
+	if counts[importFailed] > 0 {
+		fmt.Fprintf(&b, "• Failed: %d\n", counts[importFailed])
+	}
+
+	b.WriteString("\nEvents:\n")
+	for _, r := range results {
+		fmt.Fprintf(&b, "• %s (%s): %s", r.Title, r.ICalUID, r.Action)
+		if r.Reason != "" {
+			fmt.Fprintf(&b, " — %s", r.Reason)
+		}
+		b.WriteString("\n")
+	}
+	if dryRun && counts[importCreated]+counts[importUpdated] > 0 {
+		b.WriteString("\nRun again without dry_run to import them.\n")
+	}
+
+	jsonData, _ := json.MarshalIndent(results, "", "  ")
+	return b.String() + "\n" + string(jsonData)
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- importBody -----
+
+func TestImportBody(t *testing.T) {
+	source := &calendar.Event{
+		Id:               "s1_20260105T090000Z",
+		RecurringEventId: "s1",
+		ICalUID:          "s1@google.com",
+		Sequence:         3,
+		Etag:             `"1"`,
+		Summary:          "Standup",
+		Organizer:        &calendar.EventOrganizer{Email: "alice@example.org"},
+		Attendees:        []*calendar.EventAttendee{{Email: "bob@example.com", ResponseStatus: "accepted"}},
+	}
+	body, err := importBody(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.Id != "" || body.RecurringEventId != "" || body.Etag != "" {
+		t.Errorf("source calendar fields kept: %+v", body)
+	}
+	if body.ICalUID != "s1@google.com" || body.Sequence != 3 || body.Organizer.Email != "alice@example.org" || body.Attendees[0].ResponseStatus != "accepted" {
+		t.Errorf("import fields lost: %+v", body)
+	}
+}
+
+// ----- import_events -----
+
+func TestImportEvents(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	store.AddCalendar("old@group.calendar.google.com", "Old team calendar", "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	series, err := store.InsertEvent("old@group.calendar.google.com", &calendar.Event{
+		Summary:    "Planning",
+		Start:      &calendar.EventDateTime{DateTime: "2030-03-04T10:00:00Z", TimeZone: "UTC"},
+		End:        &calendar.EventDateTime{DateTime: "2030-03-04T11:00:00Z", TimeZone: "UTC"},
+		Recurrence: []string{"RRULE:FREQ=WEEKLY;COUNT=3"},
+		Organizer:  &calendar.EventOrganizer{Email: "alice@example.org"},
+		Attendees:  []*calendar.EventAttendee{{Email: "alice@example.org"}, {Email: fake.DemoOwner, ResponseStatus: "accepted"}},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.PatchEvent("old@group.calendar.google.com", series.Id+"_20300311T100000Z", "", map[string]interface{}{"summary": "Planning (moved)"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	args := map[string]interface{}{
+		"source_calendar_id": "old@group.calendar.google.com",
+		"time_min":           "2030-03-01T00:00:00Z",
+		"time_max":           "2030-03-31T00:00:00Z",
+		"dry_run":            true,
+	}
+	result, err := ct.HandleTool("import_events", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "Dry run: importing 2 events from old@group.calendar.google.com into primary") {
+		t.Errorf("dry run = %s", text)
+	}
+	if events, _ := store.ListEvents("primary", fake.EventQuery{ICalUID: series.ICalUID}); len(events) != 0 {
+		t.Fatalf("dry run imported %d events", len(events))
+	}
+
+	delete(args, "dry_run")
+	result, err = ct.HandleTool("import_events", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "• New: 2\n• Updated: 0") {
+		t.Errorf("import = %s", text)
+	}
+	events, err := store.ListEvents("primary", fake.EventQuery{ICalUID: series.ICalUID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("imported %d events, want the series and its exception", len(events))
+	}
+	master, exception := events[0], events[1]
+	if master.Organizer.Email != "alice@example.org" || master.Attendees[1].ResponseStatus != "accepted" {
+		t.Errorf("organizer %+v or responses not kept", master.Organizer)
+	}
+	if exception.RecurringEventId != master.Id || exception.Summary != "Planning (moved)" {
+		t.Errorf("exception = %+v, want it attached to the imported series", exception)
+	}
+
+	// A second run updates the copies instead of duplicating them
+	result, err = ct.HandleTool("import_events", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "• New: 0\n• Updated: 2") {
+		t.Errorf("re-import = %s", text)
+	}
+	if events, _ := store.ListEvents("primary", fake.EventQuery{ICalUID: series.ICalUID}); len(events) != 2 {
+		t.Errorf("re-import left %d events, want 2", len(events))
+	}
+
+	result, err = ct.HandleTool("import_events", map[string]interface{}{"ics": sampleICS, "timezone": "Europe/Berlin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "from the iCalendar data into primary") || !strings.Contains(text, "• New: 4") {
+		t.Errorf("ics import = %s", text)
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{},
+		{"ics": sampleICS, "source_calendar_id": "old@group.calendar.google.com"},
+		{"source_calendar_id": "primary", "time_min": "2030-03-01T00:00:00Z", "time_max": "2030-03-31T00:00:00Z"},
+		{"source_calendar_id": "old@group.calendar.google.com"},
+	} {
+		if _, err := ct.HandleTool("import_events", bad); err == nil {
+			t.Errorf("import_events(%v) succeeded, want an error", bad)
+		}
+	}
+}
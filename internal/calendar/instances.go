@@ -0,0 +1,130 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Target kinds reported by resolveEventTarget.
+const (
+	targetSingle   = "single"   // a non-recurring event
+	targetInstance = "instance" // one occurrence of a recurring series
+	targetSeries   = "series"   // every occurrence of a recurring series
+)
+
+// Scopes accepted by edit_event and delete_event.
+const (
+	scopeInstance = "instance"
+	scopeSeries   = "series"
+)
+
+// EventTarget is the event an edit or delete actually applies to.
+type EventTarget struct {
+	EventID  string // ID to pass to the API
+	Kind     string // targetSingle, targetInstance or targetSeries
+	SeriesID string // recurring series ID for instances and series
+}
+
+// isInstanceID reports whether id has the _YYYYMMDD or _YYYYMMDDTHHMMSSZ
+// suffix Google uses for recurring instance IDs.
+func isInstanceID(id string) bool {
+	return recurringInstanceSuffixRe.MatchString(id)
+}
+
+// resolveEventTarget decides whether an operation on eventID applies to a
+// single event, one occurrence or a whole series. event is the result of
+// GetEvent(eventID). scope is "", "instance" or "series"; when empty, the ID
+// decides: instance IDs target that occurrence and series IDs the series.
+func resolveEventTarget(eventID string, event *calendar.Event, scope string) (EventTarget, error) {
+	switch scope {
+	case "", scopeInstance, scopeSeries:
+	default:
+		return EventTarget{}, fmt.Errorf("invalid scope %q (expected 'instance' or 'series')", scope)
+	}
+
+	isSeries := len(event.Recurrence) > 0
+	// An instance ID only counts if the API confirms the event belongs to a
+	// series; imported events may carry similar-looking IDs.
+	isInstance := event.RecurringEventId != ""
+
+	switch {
+	case isInstance:
+		if scope == scopeSeries {
+			return EventTarget{EventID: event.RecurringEventId, Kind: targetSeries, SeriesID: event.RecurringEventId}, nil
+		}
+		return EventTarget{EventID: eventID, Kind: targetInstance, SeriesID: event.RecurringEventId}, nil
+
+	case isSeries:
+		if scope == scopeInstance {
+			return EventTarget{}, fmt.Errorf("event_id %s is a recurring series; pass the ID of one occurrence (see list_event_occurrences) to change only that occurrence", eventID)
+		}
+		return EventTarget{EventID: eventID, Kind: targetSeries, SeriesID: eventID}, nil
+
+	default:
+		if scope == scopeSeries {
+			return EventTarget{}, fmt.Errorf("event_id %s is not part of a recurring series", eventID)
+		}
+		return EventTarget{EventID: eventID, Kind: targetSingle}, nil
+	}
+}
+
+// getEventForChange fetches the event an edit or delete refers to, explaining
+// a missing recurring occurrence rather than returning a bare 404.
+func (c *Client) getEventForChange(calendarID, eventID string) (*calendar.Event, error) {
+	event, err := c.GetEvent(calendarID, eventID)
+	if err == nil {
+		return event, nil
+	}
+	if isInstanceID(eventID) {
+		return nil, fmt.Errorf("failed to get event details: %v (event_id looks like a recurring occurrence; it may have been cancelled or not match an occurrence time — use list_event_occurrences to find valid occurrence IDs)", err)
+	}
+	return nil, fmt.Errorf("failed to get event details: %v", err)
+}
+
+// describeTarget explains which events an operation touched.
+func describeTarget(target EventTarget, event *calendar.Event, tf TimeFormat) string {
+	switch target.Kind {
+	case targetInstance:
+		return fmt.Sprintf("only the occurrence on %s (series %s unchanged)", occurrenceDate(event, tf), target.SeriesID)
+	case targetSeries:
+		return "every occurrence of the recurring series"
+	default:
+		return ""
+	}
+}
+
+// occurrenceDate returns the original start date of a recurring instance.
+func occurrenceDate(event *calendar.Event, tf TimeFormat) string {
+	dt := event.OriginalStartTime
+	if dt == nil {
+		dt = event.Start
+	}
+	if dt == nil {
+		return "(unknown date)"
+	}
+	if dt.Date != "" {
+		return dt.Date
+	}
+	if t, err := time.Parse(time.RFC3339, dt.DateTime); err == nil {
+		return tf.LongDate(t) + " " + tf.ClockZone(t)
+	}
+	return dt.DateTime
+}
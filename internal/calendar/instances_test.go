@@ -0,0 +1,86 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- resolveEventTarget -----
+
+func TestResolveEventTarget(t *testing.T) {
+	series := &calendar.Event{Id: "abc", Recurrence: []string{"RRULE:FREQ=WEEKLY"}}
+	instance := &calendar.Event{Id: "abc_20250115T100000Z", RecurringEventId: "abc"}
+	single := &calendar.Event{Id: "xyz"}
+	// Imported events can have IDs that merely look like instance IDs
+	lookalike := &calendar.Event{Id: "imported_20250115"}
+
+	cases := []struct {
+		name     string
+		eventID  string
+		event    *calendar.Event
+		scope    string
+		wantID   string
+		wantKind string
+		wantErr  bool
+	}{
+		{"instance id defaults to instance", instance.Id, instance, "", instance.Id, targetInstance, false},
+		{"instance id with series scope", instance.Id, instance, "series", "abc", targetSeries, false},
+		{"series id defaults to series", "abc", series, "", "abc", targetSeries, false},
+		{"series id with instance scope", "abc", series, "instance", "", "", true},
+		{"single event", "xyz", single, "", "xyz", targetSingle, false},
+		{"single event with series scope", "xyz", single, "series", "", "", true},
+		{"lookalike id is single", lookalike.Id, lookalike, "", lookalike.Id, targetSingle, false},
+		{"invalid scope", "xyz", single, "everything", "", "", true},
+	}
+	for _, tc := range cases {
+		got, err := resolveEventTarget(tc.eventID, tc.event, tc.scope)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if got.EventID != tc.wantID || got.Kind != tc.wantKind {
+			t.Errorf("%s: got %+v, want ID %q kind %q", tc.name, got, tc.wantID, tc.wantKind)
+		}
+	}
+}
+
+// ----- describeTarget -----
+
+func TestDescribeTarget(t *testing.T) {
+	instance := &calendar.Event{
+		RecurringEventId:  "abc",
+		OriginalStartTime: &calendar.EventDateTime{Date: "2025-01-15"},
+	}
+	got := describeTarget(EventTarget{Kind: targetInstance, SeriesID: "abc"}, instance, TimeFormat{})
+	if !strings.Contains(got, "2025-01-15") || !strings.Contains(got, "series abc unchanged") {
+		t.Errorf("unexpected instance description %q", got)
+	}
+	if got := describeTarget(EventTarget{Kind: targetSingle}, &calendar.Event{}, TimeFormat{}); got != "" {
+		t.Errorf("single events need no scope note, got %q", got)
+	}
+}
+
+func TestIsInstanceID(t *testing.T) {
+	if !isInstanceID("abc_20250115T100000Z") || !isInstanceID("abc_20250115") {
+		t.Error("expected instance-style IDs to be detected")
+	}
+	if isInstanceID("abc") {
+		t.Error("plain ID should not be an instance ID")
+	}
+}
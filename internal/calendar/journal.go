@@ -0,0 +1,181 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// journalCalendarName is the secondary calendar log_note writes to; it is
+	// created on first use
+	journalCalendarName = "Journal"
+	// journalNoteKey is the private extended property marking log_note events
+	journalNoteKey = "journalNote"
+	// defaultNoteMinutes is how much time a note covers when none is given
+	defaultNoteMinutes = 15
+)
+
+// LogNoteParams describes a note to log in the journal calendar. The note
+// covers the Minutes up to At; with zero Minutes it marks the moment At,
+// stored as one minute from At since Calendar rejects empty time ranges.
+type LogNoteParams struct {
+	Note     string
+	Details  string
+	At       time.Time
+	Minutes  int
+	TimeZone string
+}
+
+// journalCalendar returns the ID of the user's journal calendar: an owned
+// calendar named journalCalendarName, created when there is none. created
+// reports whether it was created by this call. The ID is cached per session.
+func (c *Client) journalCalendar(timeZone string) (id string, created bool, err error) {
+	c.cacheMu.Lock()
+	cached := c.journalCalendarID
+	c.cacheMu.Unlock()
+	if cached != "" {
+		return cached, false, nil
+	}
+
+	entries, err := c.ListCalendars()
+	if err != nil {
+		return "", false, err
+	}
+	for _, entry := range entries {
+		if !entry.Primary && entry.AccessRole == "owner" && strings.EqualFold(entry.Summary, journalCalendarName) {
+			if err := c.checkCalendar(entry.Id); err != nil {
+				return "", false, err
+			}
+			c.setJournalCalendar(entry.Id)
+			return entry.Id, false, nil
+		}
+	}
+
+	// A new calendar gets an ID nobody could have allowed in advance
+	if len(c.policy.Allowed) > 0 {
+		return "", false, fmt.Errorf("there is no '%s' calendar and the calendar policy only allows listed calendars; create one in Google Calendar and add its ID to %s", journalCalendarName, allowedCalendarsEnv)
+	}
+	cal, err := c.service.Calendars.Insert(&calendar.Calendar{
+		Summary:     journalCalendarName,
+		Description: "Notes and time logged with log_note",
+		TimeZone:    apiTimeZone(timeZone),
+	}).Do()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create the %s calendar: %v", journalCalendarName, err)
+	}
+	if err := c.checkCalendar(cal.Id); err != nil {
+		return "", false, err
+	}
+	c.setAccessRole(cal.Id, "owner")
+	c.setJournalCalendar(cal.Id)
+	return cal.Id, true, nil
+}
+
+func (c *Client) setJournalCalendar(id string) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.journalCalendarID = id
+}
+
+// noteSpan returns the times a note covers: the minutes up to at, or the
+// minute from at for a moment.
+func noteSpan(at time.Time, minutes int) (time.Time, time.Time) {
+	if minutes == 0 {
+		return at, at.Add(time.Minute)
+	}
+	return at.Add(-time.Duration(minutes) * time.Minute), at
+}
+
+// LogNote adds a note to the journal calendar as a private event that does
+// not block time, returning the calendar it went to.
+func (c *Client) LogNote(params LogNoteParams) (*calendar.Event, string, bool, error) {
+	calendarID, created, err := c.journalCalendar(params.TimeZone)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	start, end := noteSpan(params.At, params.Minutes)
+	event := &calendar.Event{
+		Summary:      params.Note,
+		Description:  params.Details,
+		Visibility:   "private",
+		Transparency: "transparent",
+		Start: &calendar.EventDateTime{
+			DateTime: start.Format(time.RFC3339),
+			TimeZone: apiTimeZone(params.TimeZone),
+		},
+		End: &calendar.EventDateTime{
+			DateTime: end.Format(time.RFC3339),
+			TimeZone: apiTimeZone(params.TimeZone),
+		},
+		ExtendedProperties: &calendar.EventExtendedProperties{
+			Private: map[string]string{journalNoteKey: "true"},
+		},
+	}
+	inserted, err := c.service.Events.Insert(calendarID, event).Do()
+	if err != nil {
+		return nil, calendarID, created, fmt.Errorf("failed to log note: %v", err)
+	}
+	return inserted, calendarID, created, nil
+}
+
+func (ct *CalendarTools) handleLogNote(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	params := LogNoteParams{
+		Note:     strings.TrimSpace(getStringOrDefault(arguments, "note", "")),
+		Details:  getStringOrDefault(arguments, "details", ""),
+		At:       time.Now(),
+		Minutes:  getIntOrDefault(arguments, "duration_minutes", defaultNoteMinutes),
+		TimeZone: getStringOrDefault(arguments, "timezone", "UTC"),
+	}
+	if params.Note == "" {
+		return nil, fmt.Errorf("note is required")
+	}
+	if params.Minutes < 0 || params.Minutes > 24*60 {
+		return nil, fmt.Errorf("duration_minutes must be between 0 and 1440")
+	}
+	if at := getStringOrDefault(arguments, "at", ""); at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid at: %v", err)
+		}
+		params.At = t
+	}
+	loc, err := loadLocation(params.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", params.TimeZone, err)
+	}
+	params.At = params.At.In(loc).Truncate(time.Minute)
+
+	event, calendarID, created, err := ct.client.LogNote(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	if created {
+		notes = append(notes, fmt.Sprintf("Created your '%s' calendar (%s) for notes", journalCalendarName, calendarID))
+	}
+	confirmation := newConfirmation(confirmCreated, calendarID, event, ct.client.TimeFormat())
+	return structuredResult(formatConfirmation(confirmation, confirmation, notes...), confirmation), nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNoteSpan(t *testing.T) {
+	at := time.Date(2025, 3, 3, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		minutes    int
+		start, end string
+	}{
+		{120, "15:00", "17:00"},
+		{defaultNoteMinutes, "16:45", "17:00"},
+		{0, "17:00", "17:01"},
+	}
+	for _, tt := range tests {
+		start, end := noteSpan(at, tt.minutes)
+		if start.Format("15:04") != tt.start || end.Format("15:04") != tt.end {
+			t.Errorf("noteSpan(%d) = %s–%s, want %s–%s", tt.minutes, start.Format("15:04"), end.Format("15:04"), tt.start, tt.end)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// followupOfKey is the private extended property holding the ID of the
+	// event a follow-up was scheduled after
+	followupOfKey = "followupOf"
+	// maxLinkDepth bounds how many links are followed in either direction
+	maxLinkDepth = 20
+)
+
+// LinkedEvent is one event in a chain of follow-ups.
+type LinkedEvent struct {
+	EventID    string `json:"event_id"`
+	Summary    string `json:"summary,omitempty"`
+	Start      string `json:"start,omitempty"`
+	Status     string `json:"status,omitempty"`
+	FollowupOf string `json:"followup_of,omitempty"`
+	Depth      int    `json:"depth"`             // links from the event asked about; negative for earlier events
+	Missing    bool   `json:"missing,omitempty"` // the linked event could not be read (e.g. deleted)
+}
+
+// EventChain is an event with the events it follows up on and its follow-ups.
+type EventChain struct {
+	Event     LinkedEvent   `json:"event"`
+	Earlier   []LinkedEvent `json:"earlier"`    // oldest first
+	FollowUps []LinkedEvent `json:"follow_ups"` // depth first, each after the event it follows up on
+}
+
+// followupOf returns the ID of the event e follows up on, or "".
+func followupOf(e *calendar.Event) string {
+	if e == nil || e.ExtendedProperties == nil {
+		return ""
+	}
+	return e.ExtendedProperties.Private[followupOfKey]
+}
+
+func newLinkedEvent(e *calendar.Event, depth int) LinkedEvent {
+	linked := LinkedEvent{
+		EventID:    e.Id,
+		Summary:    e.Summary,
+		Status:     e.Status,
+		FollowupOf: followupOf(e),
+		Depth:      depth,
+	}
+	if e.Start != nil {
+		linked.Start = e.Start.DateTime
+		if linked.Start == "" {
+			linked.Start = e.Start.Date
+		}
+	}
+	return linked
+}
+
+// FollowUps returns the events linked as follow-ups of eventID.
+func (c *Client) FollowUps(calendarID, eventID string) ([]*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	var followUps []*calendar.Event
+	call := c.service.Events.List(calendarID).
+		PrivateExtendedProperty(followupOfKey + "=" + eventID).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list follow-ups of %s: %v", eventID, err)
+		}
+		followUps = append(followUps, page.Items...)
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return followUps, nil
+}
+
+// EventChain collects the chain of follow-up links through event: the events
+// it follows up on, oldest first, and all of its follow-ups, each level
+// listed by the API. Links are followed at most maxLinkDepth deep, and an
+// event already seen ends a branch, so a cycle cannot loop.
+func (c *Client) EventChain(calendarID string, event *calendar.Event) (*EventChain, error) {
+	chain := &EventChain{Event: newLinkedEvent(event, 0), Earlier: []LinkedEvent{}, FollowUps: []LinkedEvent{}}
+	seen := map[string]bool{event.Id: true}
+
+	for id, depth := followupOf(event), -1; id != "" && depth >= -maxLinkDepth && !seen[id]; depth-- {
+		seen[id] = true
+		earlier, err := c.GetEvent(calendarID, id)
+		if err != nil {
+			if !isNotFound(err) {
+				return nil, fmt.Errorf("failed to get event %s: %v", id, err)
+			}
+			chain.Earlier = append([]LinkedEvent{{EventID: id, Depth: depth, Missing: true}}, chain.Earlier...)
+			break
+		}
+		chain.Earlier = append([]LinkedEvent{newLinkedEvent(earlier, depth)}, chain.Earlier...)
+		id = followupOf(earlier)
+	}
+
+	var walk func(id string, depth int) error
+	walk = func(id string, depth int) error {
+		if depth > maxLinkDepth {
+			return nil
+		}
+		followUps, err := c.FollowUps(calendarID, id)
+		if err != nil {
+			return err
+		}
+		for _, f := range followUps {
+			if seen[f.Id] || f.Status == "cancelled" {
+				continue
+			}
+			seen[f.Id] = true
+			chain.FollowUps = append(chain.FollowUps, newLinkedEvent(f, depth))
+			if err := walk(f.Id, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(event.Id, 1); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// checkFollowupLink verifies that eventID may be linked as a follow-up of
+// originalID: the original must exist and must not itself follow up on
+// eventID, directly or through other events. eventID is empty for an event
+// not created yet, which cannot be part of a cycle.
+func (c *Client) checkFollowupLink(calendarID, eventID, originalID string) error {
+	if originalID == eventID {
+		return fmt.Errorf("followup_of: an event cannot follow up on itself")
+	}
+	original, err := c.GetEvent(calendarID, originalID)
+	if err != nil {
+		return fmt.Errorf("followup_of: cannot read event %s: %v", originalID, err)
+	}
+	if eventID == "" {
+		return nil
+	}
+
+	for depth := 0; depth < maxLinkDepth; depth++ {
+		id := followupOf(original)
+		if id == "" {
+			return nil
+		}
+		if id == eventID {
+			return fmt.Errorf("followup_of: %s already follows up on this event, so linking it back would create a cycle", originalID)
+		}
+		// A broken link further up cannot lead back to this event
+		if original, err = c.GetEvent(calendarID, id); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// formatEventChain renders the chain as an indented list, followed by the
+// structured data.
+func formatEventChain(chain *EventChain) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🔗 Linked events for '%s':\n\n", linkedTitle(chain.Event))
+
+	if len(chain.Earlier) > 0 {
+		result.WriteString("Follows up on:\n")
+		for _, e := range chain.Earlier {
+			fmt.Fprintf(&result, "• %s\n", describeLinkedEvent(e))
+		}
+		result.WriteString("\n")
+	}
+	fmt.Fprintf(&result, "➡️ %s (this event)\n", describeLinkedEvent(chain.Event))
+
+	if len(chain.FollowUps) > 0 {
+		result.WriteString("\nFollow-ups:\n")
+		for _, e := range chain.FollowUps {
+			fmt.Fprintf(&result, "%s• %s\n", strings.Repeat("  ", e.Depth-1), describeLinkedEvent(e))
+		}
+	} else {
+		result.WriteString("\nNo follow-ups are linked to this event yet; create one with create_event and followup_of.\n")
+	}
+
+	chainJSON, _ := json.MarshalIndent(chain, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(chainJSON))
+	return result.String()
+}
+
+func linkedTitle(e LinkedEvent) string {
+	if e.Summary == "" {
+		return "(No Title)"
+	}
+	return e.Summary
+}
+
+func describeLinkedEvent(e LinkedEvent) string {
+	if e.Missing {
+		return fmt.Sprintf("%s (not found; it may have been deleted)", e.EventID)
+	}
+	description := fmt.Sprintf("%s — %s (ID: %s)", linkedTitle(e), e.Start, e.EventID)
+	if e.Status == "cancelled" {
+		description += " [cancelled]"
+	}
+	return description
+}
+
+func (ct *CalendarTools) handleListLinkedEvents(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID := getStringOrDefault(arguments, "event_id", "")
+	if eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	calendarID := ct.calendarID(arguments)
+
+	event, err := ct.client.GetEvent(calendarID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	chain, err := ct.client.EventChain(calendarID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatEventChain(chain),
+		}},
+	}, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- followupOf -----
+
+func TestFollowupOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		want  string
+	}{
+		{"nil event", nil, ""},
+		{"no extended properties", &calendar.Event{Id: "e1"}, ""},
+		{"other private properties", &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{"tag": "x"}}}, ""},
+		{"shared property is ignored", &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{Shared: map[string]string{followupOfKey: "k1"}}}, ""},
+		{"linked", &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{followupOfKey: "k1"}}}, "k1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := followupOf(tt.event); got != tt.want {
+				t.Errorf("followupOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ----- newLinkedEvent -----
+
+func TestNewLinkedEvent(t *testing.T) {
+	timed := &calendar.Event{
+		Id:                 "review",
+		Summary:            "Review",
+		Status:             "confirmed",
+		Start:              &calendar.EventDateTime{DateTime: "2025-03-10T09:00:00Z"},
+		ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{followupOfKey: "kickoff"}},
+	}
+	got := newLinkedEvent(timed, 1)
+	want := LinkedEvent{EventID: "review", Summary: "Review", Start: "2025-03-10T09:00:00Z", Status: "confirmed", FollowupOf: "kickoff", Depth: 1}
+	if got != want {
+		t.Errorf("newLinkedEvent() = %+v, want %+v", got, want)
+	}
+
+	allDay := &calendar.Event{Id: "retro", Start: &calendar.EventDateTime{Date: "2025-03-14"}}
+	if got := newLinkedEvent(allDay, 2).Start; got != "2025-03-14" {
+		t.Errorf("all-day start = %q, want the date", got)
+	}
+}
+
+// ----- formatEventChain -----
+
+func TestFormatEventChain(t *testing.T) {
+	chain := &EventChain{
+		Event: LinkedEvent{EventID: "review", Summary: "Review", Start: "2025-03-10", FollowupOf: "kickoff"},
+		Earlier: []LinkedEvent{
+			{EventID: "gone", Depth: -2, Missing: true},
+			{EventID: "kickoff", Summary: "Kickoff", Start: "2025-03-03", FollowupOf: "gone", Depth: -1},
+		},
+		FollowUps: []LinkedEvent{
+			{EventID: "retro", Summary: "Retro", Start: "2025-03-14", FollowupOf: "review", Depth: 1},
+			{EventID: "actions", Start: "2025-03-17", FollowupOf: "retro", Depth: 2, Status: "cancelled"},
+		},
+	}
+	out := formatEventChain(chain)
+
+	for _, want := range []string{
+		"🔗 Linked events for 'Review'",
+		"• gone (not found; it may have been deleted)",
+		"• Kickoff — 2025-03-03 (ID: kickoff)",
+		"➡️ Review — 2025-03-10 (ID: review) (this event)",
+		"\n• Retro — 2025-03-14 (ID: retro)",
+		"\n  • (No Title) — 2025-03-17 (ID: actions) [cancelled]",
+		`"follow_ups"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "gone (not found") > strings.Index(out, "Kickoff —") {
+		t.Errorf("earlier events should be listed oldest first:\n%s", out)
+	}
+
+	out = formatEventChain(&EventChain{Event: LinkedEvent{EventID: "solo", Summary: "Solo"}})
+	if !strings.Contains(out, "No follow-ups are linked") || strings.Contains(out, "Follows up on") {
+		t.Errorf("unexpected output for an unlinked event:\n%s", out)
+	}
+}
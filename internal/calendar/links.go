@@ -0,0 +1,107 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// EventLinks are the URLs for opening or joining an event.
+type EventLinks struct {
+	EventID  string   `json:"event_id"`
+	Summary  string   `json:"summary"`
+	HTMLLink string   `json:"html_link"`           // the event in the Calendar web UI
+	MeetLink string   `json:"meet_link,omitempty"` // video conference, if any
+	DialIn   []DialIn `json:"dial_in,omitempty"`   // phone numbers and other entry points
+	Source   string   `json:"source,omitempty"`    // the ticket, PR or doc the event came from
+}
+
+// meetLink returns the event's video conference URL, or "" if it has none.
+func meetLink(e *calendar.Event) string {
+	if e.HangoutLink != "" {
+		return e.HangoutLink
+	}
+	if e.ConferenceData != nil {
+		for _, ep := range e.ConferenceData.EntryPoints {
+			if ep.EntryPointType == "video" {
+				return ep.Uri
+			}
+		}
+	}
+	return ""
+}
+
+// formatEventLinks renders the links of an event for sharing.
+func formatEventLinks(links EventLinks) string {
+	var result strings.Builder
+
+	title := links.Summary
+	if title == "" {
+		title = "(No Title)"
+	}
+	fmt.Fprintf(&result, "🔗 Links for '%s':\n\n", title)
+	fmt.Fprintf(&result, "• Calendar: %s\n", links.HTMLLink)
+	if links.MeetLink != "" {
+		fmt.Fprintf(&result, "• Meet: %s\n", links.MeetLink)
+	} else {
+		result.WriteString("• Meet: none (the event has no video conference)\n")
+	}
+	for _, d := range links.DialIn {
+		fmt.Fprintf(&result, "• Dial-in: %s\n", describeDialIn(d))
+	}
+	if links.Source != "" {
+		fmt.Fprintf(&result, "• Source: %s\n", links.Source)
+	}
+
+	linksJSON, _ := json.MarshalIndent(links, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(linksJSON))
+	return result.String()
+}
+
+func (ct *CalendarTools) handleGetEventLink(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID, ok := arguments["event_id"].(string)
+	if !ok || eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+
+	event, err := ct.client.getEventForChange(ct.calendarID(arguments), eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	links := EventLinks{
+		EventID:  event.Id,
+		Summary:  event.Summary,
+		HTMLLink: event.HtmlLink,
+		MeetLink: meetLink(event),
+		DialIn:   dialIns(event),
+		Source:   describeSource(event),
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatEventLinks(links),
+		}},
+	}, nil
+}
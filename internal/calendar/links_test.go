@@ -0,0 +1,94 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- meetLink -----
+
+func TestMeetLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		want  string
+	}{
+		{"none", &calendar.Event{}, ""},
+		{"hangout link", &calendar.Event{HangoutLink: "https://meet.google.com/abc-defg-hij"}, "https://meet.google.com/abc-defg-hij"},
+		{
+			name: "conference video entry point",
+			event: &calendar.Event{ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+				{EntryPointType: "phone", Uri: "tel:+1-555-0100"},
+				{EntryPointType: "video", Uri: "https://meet.google.com/xyz-abcd-efg"},
+			}}},
+			want: "https://meet.google.com/xyz-abcd-efg",
+		},
+		{
+			name: "phone only",
+			event: &calendar.Event{ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+				{EntryPointType: "phone", Uri: "tel:+1-555-0100"},
+			}}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meetLink(tt.event); got != tt.want {
+				t.Errorf("meetLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ----- formatEventLinks -----
+
+func TestFormatEventLinks(t *testing.T) {
+	withMeet := formatEventLinks(EventLinks{
+		EventID:  "ev1",
+		Summary:  "Standup",
+		HTMLLink: "https://calendar.google.com/calendar/event?eid=ev1",
+		MeetLink: "https://meet.google.com/abc-defg-hij",
+	})
+	for _, want := range []string{"'Standup'", "• Calendar: https://calendar.google.com/calendar/event?eid=ev1", "• Meet: https://meet.google.com/abc-defg-hij", `"meet_link"`} {
+		if !strings.Contains(withMeet, want) {
+			t.Errorf("output missing %q:\n%s", want, withMeet)
+		}
+	}
+
+	withPhone := formatEventLinks(EventLinks{
+		EventID:  "ev3",
+		MeetLink: "https://meet.google.com/abc-defg-hij",
+		DialIn:   []DialIn{{Type: "phone", URI: "tel:+1-555-0100", RegionCode: "US", PIN: "123456#"}},
+	})
+	for _, want := range []string{"• Dial-in: +1-555-0100 (US), PIN: 123456#", `"dial_in"`} {
+		if !strings.Contains(withPhone, want) {
+			t.Errorf("output missing %q:\n%s", want, withPhone)
+		}
+	}
+
+	noMeet := formatEventLinks(EventLinks{EventID: "ev2", HTMLLink: "https://calendar.google.com/calendar/event?eid=ev2"})
+	for _, want := range []string{"(No Title)", "• Meet: none"} {
+		if !strings.Contains(noMeet, want) {
+			t.Errorf("output missing %q:\n%s", want, noMeet)
+		}
+	}
+	if strings.Contains(noMeet, `"meet_link"`) {
+		t.Errorf("meet_link should be omitted from JSON:\n%s", noMeet)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// localeEnv overrides the locale used to format dates, e.g. "de" or "en_GB"
+	localeEnv = "GCAL_MCP_LOCALE"
+	// clockEnv overrides the clock style: "12h" or "24h"
+	clockEnv = "GCAL_MCP_CLOCK"
+	// weekStartEnv overrides the first day of the week, e.g. "sunday"
+	weekStartEnv = "GCAL_MCP_WEEK_START"
+)
+
+// DisplaySettings are configured formatting preferences. Empty values are
+// read from the user's Calendar settings instead.
+type DisplaySettings struct {
+	Locale    string // e.g. "en", "en_GB", "de"
+	Clock     string // "12h", "24h" or ""
+	WeekStart string // first day of the week, e.g. "Sunday", or ""
+}
+
+// DisplaySettingsFromEnv reads GCAL_MCP_LOCALE, GCAL_MCP_CLOCK and
+// GCAL_MCP_WEEK_START. Unrecognized clock and week start values are ignored
+// with a warning on stderr.
+func DisplaySettingsFromEnv() DisplaySettings {
+	settings := DisplaySettings{Locale: strings.TrimSpace(os.Getenv(localeEnv))}
+	switch clock := strings.ToLower(strings.TrimSpace(os.Getenv(clockEnv))); clock {
+	case "", "12h", "24h":
+		settings.Clock = clock
+	default:
+		fmt.Fprintf(os.Stderr, "Ignoring %s=%s: expected 12h or 24h\n", clockEnv, clock)
+	}
+	if value := strings.TrimSpace(os.Getenv(weekStartEnv)); value != "" {
+		if day, ok := parseWeekStart(value); ok {
+			settings.WeekStart = day.String()
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring %s=%s: expected a day such as sunday, monday or saturday\n", weekStartEnv, value)
+		}
+	}
+	return settings
+}
+
+// parseWeekStart reads a first day of the week given as a day name, or as a
+// number like the "weekStart" setting ("0" Sunday to "6" Saturday).
+func parseWeekStart(value string) (time.Weekday, bool) {
+	if day, err := strconv.Atoi(value); err == nil {
+		return time.Weekday(day), day >= 0 && day <= 6
+	}
+	return parseWeekday(value)
+}
+
+// SetDisplaySettings sets configured formatting preferences. They take
+// precedence over the user's Calendar settings.
+func (c *Client) SetDisplaySettings(settings DisplaySettings) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.displaySettings = settings
+	c.timeFormat = nil
+	c.weekStart = nil
+}
+
+// TimeFormat returns how dates and times are formatted for the user: the
+// configured locale and clock, else the "locale" and "format24HourTime"
+// Calendar settings, else US English with a 12-hour clock. The settings are
+// read once per session; a failed read falls back to the defaults.
+func (c *Client) TimeFormat() TimeFormat {
+	c.cacheMu.Lock()
+	cached, settings := c.timeFormat, c.displaySettings
+	c.cacheMu.Unlock()
+	if cached != nil {
+		return *cached
+	}
+
+	locale, clock := settings.Locale, settings.Clock
+	if locale == "" || clock == "" {
+		settings, err := c.userSettings()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Using default date format: %v\n", err)
+		}
+		if locale == "" {
+			locale = settings["locale"]
+		}
+		if clock == "" {
+			if clock24, err := strconv.ParseBool(settings["format24HourTime"]); err == nil {
+				clock = "12h"
+				if clock24 {
+					clock = "24h"
+				}
+			}
+		}
+	}
+
+	format := TimeFormat{Locale: locale, Clock24: clock == "24h"}
+	if clock == "" {
+		format.Clock24 = !format.names().monthFirst
+	}
+	c.cacheMu.Lock()
+	c.timeFormat = &format
+	c.cacheMu.Unlock()
+	return format
+}
+
+// WeekStart returns the first day of the week: the configured one, else the
+// user's "weekStart" Calendar setting ("0" Sunday, "1" Monday, "6" Saturday).
+// It is read once per session; Monday is used when the setting cannot be
+// read.
+func (c *Client) WeekStart() time.Weekday {
+	c.cacheMu.Lock()
+	cached, configured := c.weekStart, c.displaySettings.WeekStart
+	c.cacheMu.Unlock()
+	if cached != nil {
+		return *cached
+	}
+	if day, ok := parseWeekStart(configured); ok {
+		c.setWeekStart(day)
+		return day
+	}
+
+	weekStart := time.Monday
+	settings, err := c.userSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Weeks start on Monday: %v\n", err)
+	}
+	if day, err := strconv.Atoi(settings["weekStart"]); err == nil && day >= 0 && day <= 6 {
+		weekStart = time.Weekday(day)
+	}
+	c.setWeekStart(weekStart)
+	return weekStart
+}
+
+func (c *Client) setWeekStart(day time.Weekday) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.weekStart = &day
+}
+
+// userSettings returns the user's Calendar settings by ID.
+func (c *Client) userSettings() (map[string]string, error) {
+	settings := make(map[string]string)
+	call := c.service.Settings.List()
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return settings, fmt.Errorf("failed to read calendar settings: %v", err)
+		}
+		for _, s := range page.Items {
+			settings[s.Id] = s.Value
+		}
+		if page.NextPageToken == "" {
+			return settings, nil
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+}
+
+// TimeFormat formats dates and times for a locale. The zero value formats
+// like US English with a 12-hour clock.
+type TimeFormat struct {
+	Locale  string // Calendar locale, e.g. "en", "en_GB", "pt_BR"
+	Clock24 bool
+}
+
+// Clock formats the time of day, e.g. "3:04 PM" or "15:04".
+func (f TimeFormat) Clock(t time.Time) string {
+	if f.Clock24 {
+		return t.Format("15:04")
+	}
+	return t.Format("3:04 PM")
+}
+
+// ClockZone formats the time of day followed by the zone abbreviation.
+func (f TimeFormat) ClockZone(t time.Time) string {
+	return f.Clock(t) + " " + t.Format("MST")
+}
+
+// ShortDate formats an abbreviated weekday and date, e.g. "Mon, Jan 2".
+func (f TimeFormat) ShortDate(t time.Time) string {
+	n := f.names()
+	return fmt.Sprintf(n.short, n.shortDays[t.Weekday()], t.Day(), n.shortMonths[t.Month()-1])
+}
+
+// MonthDay formats an abbreviated date without weekday, e.g. "Jan 2".
+func (f TimeFormat) MonthDay(t time.Time) string {
+	n := f.names()
+	return fmt.Sprintf(n.monthDay, n.shortDays[t.Weekday()], t.Day(), n.shortMonths[t.Month()-1])
+}
+
+// LongDate formats the full date, e.g. "Monday, January 2, 2006".
+func (f TimeFormat) LongDate(t time.Time) string {
+	n := f.names()
+	return fmt.Sprintf(n.long, n.days[t.Weekday()], t.Day(), n.months[t.Month()-1], t.Year())
+}
+
+// localeNames holds a language's day and month names and date patterns. The
+// patterns take the weekday, day of month, month and year as arguments 1-4.
+type localeNames struct {
+	days, shortDays       [7]string // indexed by time.Weekday
+	months, shortMonths   [12]string
+	long, short, monthDay string
+	monthFirst            bool // month before day, as in US English
+}
+
+var englishDays = [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+var englishShortDays = [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+var englishMonths = [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+var englishShortMonths = [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+var (
+	usEnglish = localeNames{
+		days: englishDays, shortDays: englishShortDays, months: englishMonths, shortMonths: englishShortMonths,
+		long: "%[1]s, %[3]s %[2]d, %[4]d", short: "%[1]s, %[3]s %[2]d", monthDay: "%[3]s %[2]d",
+		monthFirst: true,
+	}
+	// dayFirstEnglish is used for English outside North America and for
+	// languages without their own names
+	dayFirstEnglish = localeNames{
+		days: englishDays, shortDays: englishShortDays, months: englishMonths, shortMonths: englishShortMonths,
+		long: "%[1]s %[2]d %[3]s %[4]d", short: "%[1]s %[2]d %[3]s", monthDay: "%[2]d %[3]s",
+	}
+)
+
+// languageNames maps a language code to its names.
+var languageNames = map[string]localeNames{
+	"de": {
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		long:        "%[1]s, %[2]d. %[3]s %[4]d", short: "%[1]s, %[2]d. %[3]s", monthDay: "%[2]d. %[3]s",
+	},
+	"es": {
+		days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		long:        "%[1]s, %[2]d de %[3]s de %[4]d", short: "%[1]s, %[2]d %[3]s", monthDay: "%[2]d %[3]s",
+	},
+	"fr": {
+		days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		long:        "%[1]s %[2]d %[3]s %[4]d", short: "%[1]s %[2]d %[3]s", monthDay: "%[2]d %[3]s",
+	},
+	"it": {
+		days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		shortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		long:        "%[1]s %[2]d %[3]s %[4]d", short: "%[1]s %[2]d %[3]s", monthDay: "%[2]d %[3]s",
+	},
+	"nl": {
+		days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortDays:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		long:        "%[1]s %[2]d %[3]s %[4]d", short: "%[1]s %[2]d %[3]s", monthDay: "%[2]d %[3]s",
+	},
+	"pt": {
+		days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortDays:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+		months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		shortMonths: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		long:        "%[1]s, %[2]d de %[3]s de %[4]d", short: "%[1]s, %[2]d %[3]s", monthDay: "%[2]d %[3]s",
+	},
+}
+
+// names returns the names for the format's locale. English uses month-first
+// dates only in North America and the Philippines; languages without names
+// of their own fall back to English in day-first order.
+func (f TimeFormat) names() localeNames {
+	language, region, _ := strings.Cut(strings.ReplaceAll(f.Locale, "-", "_"), "_")
+	language, region = strings.ToLower(language), strings.ToUpper(region)
+	if names, ok := languageNames[language]; ok {
+		return names
+	}
+	if language == "" || language == "en" {
+		switch region {
+		case "", "US", "CA", "PH":
+			return usEnglish
+		}
+	}
+	return dayFirstEnglish
+}
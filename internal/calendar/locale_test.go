@@ -0,0 +1,139 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// ----- TimeFormat -----
+
+func TestTimeFormat(t *testing.T) {
+	// Wednesday
+	at := time.Date(2025, 1, 15, 15, 4, 0, 0, time.UTC)
+	tests := []struct {
+		format                               TimeFormat
+		clock, shortDate, monthDay, longDate string
+	}{
+		{TimeFormat{}, "3:04 PM", "Wed, Jan 15", "Jan 15", "Wednesday, January 15, 2025"},
+		{TimeFormat{Locale: "en_US", Clock24: true}, "15:04", "Wed, Jan 15", "Jan 15", "Wednesday, January 15, 2025"},
+		{TimeFormat{Locale: "en-GB", Clock24: true}, "15:04", "Wed 15 Jan", "15 Jan", "Wednesday 15 January 2025"},
+		{TimeFormat{Locale: "de", Clock24: true}, "15:04", "Mi., 15. Jan.", "15. Jan.", "Mittwoch, 15. Januar 2025"},
+		{TimeFormat{Locale: "fr_FR", Clock24: true}, "15:04", "mer. 15 janv.", "15 janv.", "mercredi 15 janvier 2025"},
+		{TimeFormat{Locale: "es", Clock24: true}, "15:04", "mié, 15 ene", "15 ene", "miércoles, 15 de enero de 2025"},
+		{TimeFormat{Locale: "pt_BR", Clock24: true}, "15:04", "qua, 15 jan", "15 jan", "quarta-feira, 15 de janeiro de 2025"},
+		// Languages without names of their own use English, day first
+		{TimeFormat{Locale: "ja", Clock24: true}, "15:04", "Wed 15 Jan", "15 Jan", "Wednesday 15 January 2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.Locale, func(t *testing.T) {
+			if got := tt.format.Clock(at); got != tt.clock {
+				t.Errorf("Clock() = %q, want %q", got, tt.clock)
+			}
+			if got := tt.format.ShortDate(at); got != tt.shortDate {
+				t.Errorf("ShortDate() = %q, want %q", got, tt.shortDate)
+			}
+			if got := tt.format.MonthDay(at); got != tt.monthDay {
+				t.Errorf("MonthDay() = %q, want %q", got, tt.monthDay)
+			}
+			if got := tt.format.LongDate(at); got != tt.longDate {
+				t.Errorf("LongDate() = %q, want %q", got, tt.longDate)
+			}
+		})
+	}
+}
+
+func TestTimeFormat_ClockZone(t *testing.T) {
+	at := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+	if got := (TimeFormat{}).ClockZone(at); got != "9:30 AM UTC" {
+		t.Errorf("ClockZone() = %q, want \"9:30 AM UTC\"", got)
+	}
+	if got := (TimeFormat{Clock24: true}).ClockZone(at); got != "09:30 UTC" {
+		t.Errorf("ClockZone() = %q, want \"09:30 UTC\"", got)
+	}
+}
+
+// ----- Client.TimeFormat -----
+
+func TestClientTimeFormat_Configured(t *testing.T) {
+	// Both values configured: the Calendar settings are never read
+	c := &Client{}
+	c.SetDisplaySettings(DisplaySettings{Locale: "de", Clock: "12h"})
+	if got := c.TimeFormat(); got != (TimeFormat{Locale: "de", Clock24: false}) {
+		t.Errorf("TimeFormat() = %+v, want German with a 12-hour clock", got)
+	}
+}
+
+// ----- DisplaySettingsFromEnv -----
+
+func TestDisplaySettingsFromEnv(t *testing.T) {
+	t.Setenv(localeEnv, " en_GB ")
+	t.Setenv(clockEnv, "24H")
+	if got := DisplaySettingsFromEnv(); got != (DisplaySettings{Locale: "en_GB", Clock: "24h"}) {
+		t.Errorf("DisplaySettingsFromEnv() = %+v", got)
+	}
+
+	t.Setenv(clockEnv, "military")
+	if got := DisplaySettingsFromEnv(); got.Clock != "" {
+		t.Errorf("invalid clock should be ignored, got %q", got.Clock)
+	}
+
+	for value, want := range map[string]string{"sun": "Sunday", " Saturday ": "Saturday", "1": "Monday", "someday": "", "7": ""} {
+		t.Setenv(weekStartEnv, value)
+		if got := DisplaySettingsFromEnv().WeekStart; got != want {
+			t.Errorf("%s=%q: WeekStart = %q, want %q", weekStartEnv, value, got, want)
+		}
+	}
+}
+
+// ----- Client.WeekStart -----
+
+func TestClientWeekStart_Configured(t *testing.T) {
+	// A configured first day is used without reading the Calendar settings
+	c := &Client{}
+	c.SetDisplaySettings(DisplaySettings{WeekStart: "Sunday"})
+	if got := c.WeekStart(); got != time.Sunday {
+		t.Errorf("WeekStart() = %v, want Sunday", got)
+	}
+	c.SetDisplaySettings(DisplaySettings{WeekStart: "Saturday"})
+	if got := c.WeekStart(); got != time.Saturday {
+		t.Errorf("WeekStart() after reconfiguring = %v, want Saturday", got)
+	}
+}
+
+func TestClientDisplaySettings_Concurrent(t *testing.T) {
+	// Background loops format times while tool calls may reconfigure the
+	// client; run with -race to check the cached settings are guarded
+	c := &Client{}
+	c.SetDisplaySettings(DisplaySettings{Locale: "en", Clock: "24h", WeekStart: "Monday"})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = c.TimeFormat()
+				_ = c.WeekStart()
+				c.SetDisplaySettings(DisplaySettings{Locale: "en", Clock: "24h", WeekStart: "Sunday"})
+			}
+		}()
+	}
+	wg.Wait()
+	if got := c.WeekStart(); got != time.Sunday {
+		t.Errorf("WeekStart() = %v, want Sunday", got)
+	}
+}
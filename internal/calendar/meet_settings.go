@@ -0,0 +1,236 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// meetSettingsKey is the private extended property holding an event's
+	// MeetSettings as JSON
+	meetSettingsKey = "meetSettings"
+	// meetSettingsHeader starts the block of the description that lists the
+	// settings for attendees and the host
+	meetSettingsHeader = "Meet settings (applied by the host in Google Meet):"
+	// maxConferenceNotes is the longest conference note the API accepts
+	maxConferenceNotes = 2048
+	maxBreakoutRooms   = 100
+)
+
+// guestAccessModes describes Google Meet's meeting access settings.
+var guestAccessModes = map[string]string{
+	"open":       "anyone with the link joins without asking",
+	"trusted":    "people in the organization and invited guests join without asking; others ask to join",
+	"restricted": "only invited guests join without asking; everyone else asks to join",
+}
+
+// MeetSettings are how the organizer wants a Google Meet conference run. The
+// Calendar API cannot switch on recording or breakout rooms, so they are
+// written into the description and the conference notes for the host, and
+// kept on the event so they can be read back.
+type MeetSettings struct {
+	Record        bool   `json:"record,omitempty"`
+	Transcribe    bool   `json:"transcribe,omitempty"`
+	BreakoutRooms int    `json:"breakout_rooms,omitempty"`
+	GuestAccess   string `json:"guest_access,omitempty"` // "open", "trusted" or "restricted"
+	Notes         string `json:"notes,omitempty"`
+}
+
+// meetSettingsSchema is the schema of the meet_settings argument of
+// create_event and edit_event.
+func meetSettingsSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"record": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Ask the host to record the meeting",
+			},
+			"transcribe": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Ask the host to turn on transcripts",
+			},
+			"breakout_rooms": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of breakout rooms to set up (at most %d)", maxBreakoutRooms),
+			},
+			"guest_access": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"open", "trusted", "restricted"},
+				"description": "Who joins without asking: 'open' (anyone with the link), 'trusted' (the organization and invited guests) or 'restricted' (invited guests only)",
+			},
+			"notes": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Instructions for the host and guests, e.g. 'Recording is shared with the team afterwards' (at most %d characters)", maxConferenceNotes),
+			},
+		},
+		"description": description,
+	}
+}
+
+// parseMeetSettings reads a meet_settings argument.
+func parseMeetSettings(value interface{}) (*MeetSettings, error) {
+	v, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("meet_settings must be an object")
+	}
+	settings := &MeetSettings{
+		Record:        getBoolOrDefault(v, "record", false),
+		Transcribe:    getBoolOrDefault(v, "transcribe", false),
+		BreakoutRooms: getIntOrDefault(v, "breakout_rooms", 0),
+		GuestAccess:   getStringOrDefault(v, "guest_access", ""),
+		Notes:         strings.TrimSpace(getStringOrDefault(v, "notes", "")),
+	}
+	if settings.BreakoutRooms < 0 || settings.BreakoutRooms > maxBreakoutRooms {
+		return nil, fmt.Errorf("breakout_rooms must be between 0 and %d", maxBreakoutRooms)
+	}
+	if _, ok := guestAccessModes[settings.GuestAccess]; settings.GuestAccess != "" && !ok {
+		return nil, fmt.Errorf("invalid guest_access '%s' (expected 'open', 'trusted' or 'restricted')", settings.GuestAccess)
+	}
+	if len(settings.Notes) > maxConferenceNotes {
+		return nil, fmt.Errorf("meet_settings notes must be at most %d characters", maxConferenceNotes)
+	}
+	return settings, nil
+}
+
+// lines renders the settings as the items of the description block, or nil
+// when nothing is set.
+func (s MeetSettings) lines() []string {
+	var lines []string
+	if s.Record {
+		lines = append(lines, "Record the meeting")
+	}
+	if s.Transcribe {
+		lines = append(lines, "Turn on transcripts")
+	}
+	if s.BreakoutRooms > 0 {
+		lines = append(lines, fmt.Sprintf("Breakout rooms: %d", s.BreakoutRooms))
+	}
+	if s.GuestAccess != "" {
+		lines = append(lines, fmt.Sprintf("Guest access: %s (%s)", s.GuestAccess, guestAccessModes[s.GuestAccess]))
+	}
+	if s.Notes != "" {
+		lines = append(lines, s.Notes)
+	}
+	return lines
+}
+
+// conferenceNotes returns the settings as conference notes, which Google
+// Calendar shows next to the Meet link.
+func (s MeetSettings) conferenceNotes() string {
+	notes := strings.Join(s.lines(), "\n")
+	if len(notes) > maxConferenceNotes {
+		notes = s.Notes
+	}
+	return notes
+}
+
+// withMeetSettings replaces the settings block of description with one for
+// settings, or removes it when settings are empty. Text around the block is
+// kept.
+func withMeetSettings(description string, settings MeetSettings) string {
+	var kept []string
+	inBlock := false
+	for _, line := range strings.Split(description, "\n") {
+		switch {
+		case line == meetSettingsHeader:
+			inBlock = true
+			continue
+		case inBlock && strings.HasPrefix(line, "- "):
+			continue
+		case inBlock && line == "" && len(kept) > 0 && kept[len(kept)-1] == "":
+			// Keep a single blank line where the block was
+			continue
+		}
+		inBlock = false
+		kept = append(kept, line)
+	}
+	result := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+
+	lines := settings.lines()
+	if len(lines) == 0 {
+		return result
+	}
+	block := meetSettingsHeader + "\n- " + strings.Join(lines, "\n- ")
+	if result == "" {
+		return block
+	}
+	return result + "\n\n" + block
+}
+
+// meetSettingsFromEvent returns the settings saved on event, or nil.
+func meetSettingsFromEvent(event *calendar.Event) *MeetSettings {
+	if event.ExtendedProperties == nil || event.ExtendedProperties.Private[meetSettingsKey] == "" {
+		return nil
+	}
+	var settings MeetSettings
+	if err := json.Unmarshal([]byte(event.ExtendedProperties.Private[meetSettingsKey]), &settings); err != nil {
+		return nil
+	}
+	return &settings
+}
+
+// encodeMeetSettings returns the value of meetSettingsKey for settings; empty
+// settings clear it.
+func encodeMeetSettings(settings MeetSettings) string {
+	if settings == (MeetSettings{}) {
+		return ""
+	}
+	data, _ := json.Marshal(settings)
+	return string(data)
+}
+
+// ConferenceInfo is an event's conference as structured data: its ID, how
+// to join it and the settings the organizer asked for.
+type ConferenceInfo struct {
+	ConferenceID string        `json:"conference_id,omitempty"`
+	Solution     string        `json:"solution,omitempty"` // e.g. "hangoutsMeet"
+	Status       string        `json:"status,omitempty"`   // of the create request: "success", "pending" or "failure"
+	MeetLink     string        `json:"meet_link,omitempty"`
+	EntryPoints  []DialIn      `json:"entry_points,omitempty"`
+	Notes        string        `json:"notes,omitempty"`
+	Settings     *MeetSettings `json:"settings,omitempty"`
+}
+
+// conferenceInfo returns the event's conference, or nil when it has none.
+func conferenceInfo(event *calendar.Event) *ConferenceInfo {
+	if event == nil || event.ConferenceData == nil {
+		return nil
+	}
+	data := event.ConferenceData
+	info := &ConferenceInfo{
+		ConferenceID: data.ConferenceId,
+		MeetLink:     meetLink(event),
+		Notes:        data.Notes,
+		Settings:     meetSettingsFromEvent(event),
+	}
+	if data.ConferenceSolution != nil && data.ConferenceSolution.Key != nil {
+		info.Solution = data.ConferenceSolution.Key.Type
+	}
+	if data.CreateRequest != nil && data.CreateRequest.Status != nil {
+		info.Status = data.CreateRequest.Status.StatusCode
+	}
+	for _, ep := range data.EntryPoints {
+		info.EntryPoints = append(info.EntryPoints, newDialIn(ep))
+	}
+	return info
+}
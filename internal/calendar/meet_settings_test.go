@@ -0,0 +1,140 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- parseMeetSettings -----
+
+func TestParseMeetSettings(t *testing.T) {
+	settings, err := parseMeetSettings(map[string]interface{}{
+		"record":         true,
+		"breakout_rooms": float64(3),
+		"guest_access":   "trusted",
+		"notes":          "  Share the recording afterwards ",
+	})
+	if err != nil {
+		t.Fatalf("parseMeetSettings: %v", err)
+	}
+	want := MeetSettings{Record: true, BreakoutRooms: 3, GuestAccess: "trusted", Notes: "Share the recording afterwards"}
+	if *settings != want {
+		t.Errorf("got %+v, want %+v", *settings, want)
+	}
+
+	for _, value := range []interface{}{
+		"record",
+		map[string]interface{}{"guest_access": "everyone"},
+		map[string]interface{}{"breakout_rooms": float64(101)},
+		map[string]interface{}{"notes": strings.Repeat("x", maxConferenceNotes+1)},
+	} {
+		if _, err := parseMeetSettings(value); err == nil {
+			t.Errorf("%v: expected an error", value)
+		}
+	}
+}
+
+// ----- withMeetSettings -----
+
+func TestWithMeetSettings(t *testing.T) {
+	description := withMeetSettings("Agenda: roadmap", MeetSettings{Record: true, BreakoutRooms: 2})
+	want := "Agenda: roadmap\n\n" + meetSettingsHeader + "\n- Record the meeting\n- Breakout rooms: 2"
+	if description != want {
+		t.Fatalf("got %q, want %q", description, want)
+	}
+
+	// The block is replaced, keeping the text after it
+	description = withMeetSettings(description+"\n\nBring questions", MeetSettings{Transcribe: true})
+	want = "Agenda: roadmap\n\nBring questions\n\n" + meetSettingsHeader + "\n- Turn on transcripts"
+	if description != want {
+		t.Errorf("got %q, want %q", description, want)
+	}
+
+	if got := withMeetSettings(description, MeetSettings{}); got != "Agenda: roadmap\n\nBring questions" {
+		t.Errorf("empty settings should remove the block, got %q", got)
+	}
+	if got := withMeetSettings("", MeetSettings{GuestAccess: "open"}); !strings.HasPrefix(got, meetSettingsHeader+"\n- Guest access: open") {
+		t.Errorf("got %q", got)
+	}
+}
+
+// ----- create_event / edit_event meet_settings -----
+
+func TestMeetSettingsRoundTrip(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	arguments := map[string]interface{}{
+		"summary":       "All hands",
+		"description":   "Quarterly update",
+		"start_time":    start.Format(time.RFC3339),
+		"end_time":      start.Add(time.Hour).Format(time.RFC3339),
+		"meet_settings": map[string]interface{}{"record": true, "guest_access": "restricted"},
+	}
+	if _, err := ct.HandleTool("create_event", arguments); err == nil || !strings.Contains(err.Error(), "create_meet_link") {
+		t.Errorf("meet_settings without a Meet link: got %v", err)
+	}
+
+	arguments["create_meet_link"] = true
+	result, err := ct.HandleTool("create_event", arguments)
+	if err != nil {
+		t.Fatalf("create_event: %v", err)
+	}
+	event := result.StructuredContent.(*calendar.Event)
+	if event.ConferenceData == nil || !strings.Contains(event.ConferenceData.Notes, "Record the meeting") {
+		t.Errorf("conference notes = %+v", event.ConferenceData)
+	}
+	if settings := meetSettingsFromEvent(event); settings == nil || !settings.Record || settings.GuestAccess != "restricted" {
+		t.Errorf("saved settings = %+v", settings)
+	}
+	if !strings.HasPrefix(event.Description, "Quarterly update\n\n"+meetSettingsHeader) {
+		t.Errorf("description = %q", event.Description)
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, `"conference_id": "`+event.ConferenceData.ConferenceId+`"`) || !strings.Contains(text, `"entry_points"`) {
+		t.Errorf("confirmation lacks the conference:\n%s", text)
+	}
+
+	result, err = ct.HandleTool("edit_event", map[string]interface{}{
+		"event_id":      event.Id,
+		"meet_settings": map[string]interface{}{"breakout_rooms": float64(4)},
+	})
+	if err != nil {
+		t.Fatalf("edit_event: %v", err)
+	}
+	diff := result.StructuredContent.(EventDiff)
+	if diff.Conference == nil || diff.Conference.Settings == nil || diff.Conference.Settings.BreakoutRooms != 4 || diff.Conference.Settings.Record {
+		t.Errorf("conference = %+v", diff.Conference)
+	}
+	edited, err := store.GetEvent("primary", event.Id)
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if want := "Quarterly update\n\n" + meetSettingsHeader + "\n- Breakout rooms: 4"; edited.Description != want {
+		t.Errorf("description = %q, want %q", edited.Description, want)
+	}
+}
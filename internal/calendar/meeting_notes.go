@@ -0,0 +1,161 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultNotesTemplate lays out the meeting notes doc when create_event gets
+// no notes_template, like the doc of Calendar's "Take meeting notes" button.
+// Templates are Markdown with {{title}}, {{date}}, {{time}} and
+// {{attendees}} placeholders.
+const defaultNotesTemplate = `# {{title}}
+
+{{date}}, {{time}}
+
+## Attendees
+
+{{attendees}}
+
+## Notes
+
+## Action items
+
+- [ ]
+`
+
+// MeetingNotes records the Google Doc created for an event's notes.
+type MeetingNotes struct {
+	DocID       string   `json:"doc_id"`
+	DocTitle    string   `json:"doc_title"`
+	DocURL      string   `json:"doc_url"`
+	Template    string   `json:"template,omitempty"` // the template doc, when one was given
+	SharedWith  []string `json:"shared_with,omitempty"`
+	ShareErrors []string `json:"share_errors,omitempty"`
+}
+
+// attachment returns the doc as an event attachment.
+func (n *MeetingNotes) attachment() AttachmentParams {
+	return AttachmentParams{FileURL: n.DocURL, Title: n.DocTitle, MimeType: googleDocMimeType, FileID: n.DocID}
+}
+
+// note reports the doc to the caller.
+func (n *MeetingNotes) note() string {
+	note := fmt.Sprintf("📝 Meeting notes: the Google Doc '%s' (%s) is attached to the event.", n.DocTitle, n.DocURL)
+	if len(n.SharedWith) > 0 {
+		note += fmt.Sprintf(" Attendees who can edit it: %s.", strings.Join(n.SharedWith, ", "))
+	}
+	if len(n.ShareErrors) > 0 {
+		note += "\n⚠️ Not shared: " + strings.Join(n.ShareErrors, "; ")
+	}
+	return note
+}
+
+// meetingNotesTitle names the notes doc after the event and its day, e.g.
+// "Notes – Design review – 2025-03-03".
+func meetingNotesTitle(summary string, start time.Time) string {
+	if summary == "" {
+		summary = "Untitled event"
+	}
+	return fmt.Sprintf("Notes – %s – %s", summary, start.Format("2006-01-02"))
+}
+
+// fillNotesTemplate replaces the placeholders of a notes template.
+func fillNotesTemplate(template, title, date, clock string, attendees []string) string {
+	list := "- (none)"
+	if len(attendees) > 0 {
+		list = "- " + strings.Join(attendees, "\n- ")
+	}
+	return strings.NewReplacer(
+		"{{title}}", title,
+		"{{date}}", date,
+		"{{time}}", clock,
+		"{{attendees}}", list,
+	).Replace(template)
+}
+
+// notesAttendees lists the people on the event for the notes doc: the user
+// first, then the attendees, by name and email.
+func (c *Client) notesAttendees(attendees []AttendeeParams) []string {
+	var people []string
+	self, _ := c.getUserEmail()
+	if self != "" {
+		people = append(people, self)
+	}
+	for _, a := range attendees {
+		if strings.EqualFold(a.Email, self) || strings.HasSuffix(strings.ToLower(a.Email), "@resource.calendar.google.com") {
+			continue
+		}
+		if a.DisplayName != "" {
+			people = append(people, fmt.Sprintf("%s <%s>", a.DisplayName, a.Email))
+		} else {
+			people = append(people, a.Email)
+		}
+	}
+	return people
+}
+
+// createMeetingNotes creates the notes doc for an event about to be created,
+// from the notes_template doc when one is given, and lets the attendees edit
+// it. Reading a template needs drive.readonly and creating the doc
+// drive.file; both are asked for the first time they are needed.
+func (ct *CalendarTools) createMeetingNotes(arguments map[string]interface{}, params EventParams) (*MeetingNotes, error) {
+	template := defaultNotesTemplate
+	templateID := strings.TrimSpace(getStringOrDefault(arguments, "notes_template", ""))
+	if templateID != "" {
+		content, err := ct.client.GetDocument(GetDocumentParams{FileID: templateID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the notes template %s: %v", templateID, err)
+		}
+		template = content
+	}
+
+	start := params.StartTime
+	if loc, err := loadLocation(params.TimeZone); err == nil && params.TimeZone != "" {
+		start = start.In(loc)
+	}
+	tf := ct.client.TimeFormat()
+	clock := tf.ClockZone(start)
+	if params.AllDay {
+		clock = "all day"
+	}
+	title := meetingNotesTitle(params.Summary, start)
+	content := fillNotesTemplate(template, params.Summary, tf.LongDate(start), clock, ct.client.notesAttendees(params.Attendees))
+
+	var emails []string
+	for _, a := range params.Attendees {
+		emails = append(emails, a.Email)
+	}
+	doc, err := ct.client.createSharedDoc(title, content, "text/markdown", "writer", ct.client.descriptionReaders(emails))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the meeting notes doc: %v", err)
+	}
+	notes := &MeetingNotes{
+		DocID:       doc.id,
+		DocTitle:    doc.title,
+		DocURL:      doc.url,
+		SharedWith:  doc.sharedWith,
+		ShareErrors: doc.shareErrors,
+	}
+	if templateID != "" {
+		notes.Template = parseFileID(templateID)
+	}
+	return notes, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestFillNotesTemplate(t *testing.T) {
+	got := fillNotesTemplate(defaultNotesTemplate, "Design review", "Monday, March 3, 2025", "10:00 UTC", []string{"me@example.com", "Ada <ada@example.com>"})
+	for _, want := range []string{"# Design review", "Monday, March 3, 2025, 10:00 UTC", "- me@example.com\n- Ada <ada@example.com>", "## Action items"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("notes missing %q:\n%s", want, got)
+		}
+	}
+	if got := fillNotesTemplate("{{attendees}}", "", "", "", nil); got != "- (none)" {
+		t.Errorf("no attendees = %q, want a placeholder", got)
+	}
+}
+
+func TestCreateEvent_MeetingNotes(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	template, err := store.CreateFile(&drive.File{Name: "Team template", MimeType: googleDocMimeType}, "# {{title}} ({{date}})\n\nWho: {{attendees}}\n\n## Decisions\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arguments := map[string]interface{}{
+		"summary":              "Design review",
+		"start_time":           "2025-03-03T10:00:00Z",
+		"end_time":             "2025-03-03T11:00:00Z",
+		"attendees":            []interface{}{"ada@example.com", "room-1@resource.calendar.google.com"},
+		"create_meeting_notes": true,
+		"notes_template":       template.WebViewLink,
+	}
+	result, err := ct.HandleTool("create_event", arguments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, "Meeting notes") || !strings.Contains(text, "ada@example.com") {
+		t.Errorf("confirmation should report the notes doc and who can edit it:\n%s", text)
+	}
+
+	event := result.StructuredContent.(*calendar.Event)
+	if len(event.Attachments) != 1 || event.Attachments[0].Title != "Notes – Design review – 2025-03-03" {
+		t.Fatalf("attachments = %+v, want the notes doc", event.Attachments)
+	}
+	doc, content, err := store.File(event.Attachments[0].FileId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "# Design review (") || !strings.Contains(content, "## Decisions") || !strings.Contains(content, "- ada@example.com") {
+		t.Errorf("notes should be filled from the template, got:\n%s", content)
+	}
+	if len(doc.Permissions) != 1 || doc.Permissions[0].EmailAddress != "ada@example.com" || doc.Permissions[0].Role != "writer" {
+		t.Errorf("permissions = %+v, want ada as an editor and no room", doc.Permissions)
+	}
+
+	delete(arguments, "create_meeting_notes")
+	if _, err := ct.HandleTool("create_event", arguments); err == nil || !strings.Contains(err.Error(), "needs create_meeting_notes") {
+		t.Errorf("notes_template alone should be refused, got %v", err)
+	}
+}
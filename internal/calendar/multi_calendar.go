@@ -0,0 +1,179 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// calendarListConcurrency bounds how many calendars a multi-calendar
+	// listing fetches at once
+	calendarListConcurrency = 4
+	// calendarListTimeout bounds each calendar's listing, so one slow shared
+	// calendar does not hold up the others
+	calendarListTimeout = 20 * time.Second
+)
+
+// CalendarListError is a calendar a multi-calendar listing could not read.
+type CalendarListError struct {
+	CalendarID string `json:"calendar_id"`
+	Error      string `json:"error"`
+}
+
+// calendarListing is one calendar's share of a multi-calendar listing:
+// its events, or the error that kept them from being listed.
+type calendarListing struct {
+	calendarID string
+	events     *calendar.Events
+	err        error
+}
+
+// listCalendars lists params' range on each calendar concurrently, at most
+// calendarListConcurrency at a time, in the order of calendarIDs. A calendar
+// that fails or does not answer within timeout gets an error in its listing
+// while the others still return; the late call is left to finish unused.
+func (c *Client) listCalendars(calendarIDs []string, params ListEventsParams, timeout time.Duration) []calendarListing {
+	listings := make([]calendarListing, len(calendarIDs))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, calendarListConcurrency)
+	for i, id := range calendarIDs {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			p := params
+			p.CalendarID = id
+			done := make(chan calendarListing, 1)
+			go func() {
+				events, err := c.ListEvents(p)
+				done <- calendarListing{calendarID: id, events: events, err: err}
+			}()
+			select {
+			case listings[i] = <-done:
+			case <-time.After(timeout):
+				listings[i] = calendarListing{calendarID: id, err: fmt.Errorf("no response within %s", timeout)}
+			}
+		}(i, id)
+	}
+	wg.Wait()
+	return listings
+}
+
+// calendarListErrors returns the listings that failed.
+func calendarListErrors(listings []calendarListing) []CalendarListError {
+	errs := []CalendarListError{}
+	for _, l := range listings {
+		if l.err != nil {
+			errs = append(errs, CalendarListError{CalendarID: l.calendarID, Error: l.err.Error()})
+		}
+	}
+	return errs
+}
+
+// calendarListFailure is the error of a multi-calendar listing in which no
+// calendar could be listed.
+func calendarListFailure(errs []CalendarListError) error {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.CalendarID + ": " + e.Error
+	}
+	return fmt.Errorf("failed to list events on any calendar: %s", strings.Join(parts, "; "))
+}
+
+// formatCalendarListErrors renders the calendars that could not be listed,
+// or nothing when all were.
+func formatCalendarListErrors(errs []CalendarListError) string {
+	if len(errs) == 0 {
+		return ""
+	}
+	var result strings.Builder
+	fmt.Fprintf(&result, "⚠️ Could not list %d calendar(s); the results above are partial:\n", len(errs))
+	for _, e := range errs {
+		fmt.Fprintf(&result, "• %s: %s\n", e.CalendarID, e.Error)
+	}
+	return result.String()
+}
+
+// listEventsOnCalendars is list_events over several calendars. Each
+// calendar's events are listed, checked for overlaps and formatted as in a
+// single-calendar listing, so conflict resolutions edit the right calendar;
+// events are tagged with their calendar_id in the JSON. Calendars that cannot
+// be listed are reported next to the others' events instead of failing the
+// call, unless none could be listed.
+func (ct *CalendarTools) listEventsOnCalendars(calendarIDs []string, params ListEventsParams, outputFormat string) (*mcp.CallToolResult, error) {
+	listings := ct.client.listCalendars(calendarIDs, params, calendarListTimeout)
+	errs := calendarListErrors(listings)
+	if len(errs) == len(listings) {
+		return nil, calendarListFailure(errs)
+	}
+
+	jsonResult := ct.formatEventsJSON(&calendar.Events{}, params)
+	eventsJSON := []map[string]interface{}{}
+	conflicts := []OverlapConflict{}
+	var text strings.Builder
+	for _, l := range listings {
+		if l.err != nil {
+			continue
+		}
+		p := params
+		p.CalendarID = l.calendarID
+		listed := ct.formatEventsJSON(l.events, p)
+		for _, event := range listed["events"].([]map[string]interface{}) {
+			event["calendar_id"] = l.calendarID
+			eventsJSON = append(eventsJSON, event)
+		}
+		var calendarConflicts []OverlapConflict
+		if p.DetectOverlaps {
+			calendarConflicts = ct.overlapConflicts(l.events.Items, p)
+			conflicts = append(conflicts, calendarConflicts...)
+		}
+		if outputFormat != "json" {
+			fmt.Fprintf(&text, "# 📚 %s\n\n%s\n\n", l.calendarID, ct.formatEventsResult(l.events, p))
+			if len(calendarConflicts) > 0 {
+				fmt.Fprintf(&text, "%s\n\n", formatOverlapConflicts(calendarConflicts, locationOrUTC(p.TimeZone), ct.client.TimeFormat()))
+			}
+		}
+	}
+	jsonResult["events"] = eventsJSON
+	jsonResult["total_count"] = len(eventsJSON)
+	jsonResult["calendar_ids"] = calendarIDs
+	jsonResult["errors"] = errs
+	if len(conflicts) > 0 {
+		jsonResult["conflicts"] = conflicts
+	}
+
+	if outputFormat == "json" {
+		jsonBytes, err := json.Marshal(jsonResult)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal events to JSON: %v", err)
+		}
+		return structuredResult(string(jsonBytes), jsonResult), nil
+	}
+	text.WriteString(formatCalendarListErrors(errs))
+	return structuredResult(strings.TrimRight(text.String(), "\n"), jsonResult), nil
+}
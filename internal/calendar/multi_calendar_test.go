@@ -0,0 +1,72 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+func TestListCalendarsIsolatesFailures(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/calendars/slow/"):
+			<-release
+		case strings.Contains(r.URL.Path, "/calendars/forbidden/"):
+			http.Error(rw, `{"error":{"code":403,"message":"Forbidden"}}`, http.StatusForbidden)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(`{"items":[{"id":"ev1","summary":"Planning","start":{"dateTime":"2025-03-10T10:00:00Z"},"end":{"dateTime":"2025-03-10T11:00:00Z"}}]}`))
+	}))
+	defer server.Close()
+	defer close(release)
+	service, err := calendar.NewService(context.Background(), option.WithHTTPClient(server.Client()), option.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(service, nil)
+
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	listings := c.listCalendars([]string{"primary", "slow", "forbidden", "team"}, ListEventsParams{
+		TimeFilter: "custom",
+		TimeMin:    start,
+		TimeMax:    start.AddDate(0, 0, 1),
+	}, 200*time.Millisecond)
+
+	if len(listings) != 4 {
+		t.Fatalf("got %d listings, want 4", len(listings))
+	}
+	for _, i := range []int{0, 3} {
+		if l := listings[i]; l.err != nil || len(l.events.Items) != 1 {
+			t.Errorf("%s: got %v, %v; want its event", l.calendarID, l.events, l.err)
+		}
+	}
+	errs := calendarListErrors(listings)
+	if len(errs) != 2 || errs[0].CalendarID != "slow" || errs[1].CalendarID != "forbidden" {
+		t.Fatalf("errors = %+v, want slow and forbidden", errs)
+	}
+	if !strings.Contains(errs[0].Error, "no response within") {
+		t.Errorf("slow calendar error = %q, want a timeout", errs[0].Error)
+	}
+}
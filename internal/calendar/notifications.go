@@ -0,0 +1,109 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+// sendUpdatesValues are the sendUpdates values the Calendar API accepts:
+// notify all guests, only guests outside the user's domain, or no one.
+var sendUpdatesValues = []string{"all", "externalOnly", "none"}
+
+// sendUpdatesDescription documents the send_updates argument of tools that
+// notify guests.
+const sendUpdatesDescription = "Who gets email notifications: 'all' guests, 'externalOnly' (guests outside your organization), or 'none'. Overrides send_notifications; defaults to the profile's default_send_updates (see set_default_send_updates)"
+
+func checkSendUpdates(value string) error {
+	if !containsString(sendUpdatesValues, value) {
+		return fmt.Errorf("invalid send_updates %q: use %s", value, strings.Join(sendUpdatesValues, ", "))
+	}
+	return nil
+}
+
+// sendUpdates resolves who is notified of a change: the send_updates
+// argument, else send_notifications (true means all, false none), else the
+// profile's default_send_updates, else the tool's own default.
+func (ct *CalendarTools) sendUpdates(arguments map[string]interface{}, notifyByDefault bool) (string, error) {
+	if value, ok := arguments["send_updates"].(string); ok && value != "" {
+		if err := checkSendUpdates(value); err != nil {
+			return "", err
+		}
+		return value, nil
+	}
+	if notify, ok := arguments["send_notifications"].(bool); ok {
+		if notify {
+			return "all", nil
+		}
+		return "none", nil
+	}
+	if ct.prefs != nil {
+		if value := ct.prefs.Get().DefaultSendUpdates; value != "" {
+			return value, nil
+		}
+	}
+	if notifyByDefault {
+		return "all", nil
+	}
+	return "none", nil
+}
+
+// describeSendUpdates says who was notified, for tool responses.
+func describeSendUpdates(value string) string {
+	switch value {
+	case "all":
+		return "notifications sent to attendees"
+	case "externalOnly":
+		return "notifications sent to attendees outside your organization only"
+	default:
+		return "attendees not notified"
+	}
+}
+
+func (ct *CalendarTools) handleSetDefaultSendUpdates(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	value := getStringOrDefault(arguments, "send_updates", "")
+	if value == "" {
+		return nil, fmt.Errorf("send_updates is required")
+	}
+	if value != "reset" {
+		if err := checkSendUpdates(value); err != nil {
+			return nil, err
+		}
+	}
+
+	stored := value
+	if value == "reset" {
+		stored = ""
+	}
+	if err := ct.prefs.Update(func(p *Preferences) { p.DefaultSendUpdates = stored }); err != nil {
+		return nil, err
+	}
+
+	result := fmt.Sprintf("✅ Default notifications for profile '%s' set to '%s'. Tools called without send_updates or send_notifications now use it; either argument still overrides it per call.", ct.prefs.Profile(), value)
+	if value == "reset" {
+		result = fmt.Sprintf("✅ Default notifications for profile '%s' reset. Each tool uses its own default again (most notify all attendees).", ct.prefs.Profile())
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result}},
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+// ----- sendUpdates -----
+
+func TestSendUpdates(t *testing.T) {
+	withDefault := &CalendarTools{}
+	prefs, _ := LoadPreferenceStore("", "default")
+	withDefault.SetPreferences(prefs)
+	_ = prefs.Update(func(p *Preferences) { p.DefaultSendUpdates = "externalOnly" })
+
+	tests := []struct {
+		name            string
+		ct              *CalendarTools
+		args            map[string]interface{}
+		notifyByDefault bool
+		want            string
+	}{
+		{"tool default notifies", &CalendarTools{}, map[string]interface{}{}, true, "all"},
+		{"tool default quiet", &CalendarTools{}, map[string]interface{}{}, false, "none"},
+		{"profile default", withDefault, map[string]interface{}{}, true, "externalOnly"},
+		{"profile default overrides quiet tool", withDefault, map[string]interface{}{}, false, "externalOnly"},
+		{"send_notifications true", withDefault, map[string]interface{}{"send_notifications": true}, false, "all"},
+		{"send_notifications false", withDefault, map[string]interface{}{"send_notifications": false}, true, "none"},
+		{"send_updates wins", withDefault, map[string]interface{}{"send_updates": "none", "send_notifications": true}, true, "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ct.sendUpdates(tt.args, tt.notifyByDefault)
+			if err != nil {
+				t.Fatalf("sendUpdates: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("sendUpdates = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := withDefault.sendUpdates(map[string]interface{}{"send_updates": "everyone"}, true); err == nil {
+		t.Error("invalid send_updates should fail")
+	}
+}
+
+// ----- set_default_send_updates -----
+
+func TestSetDefaultSendUpdates(t *testing.T) {
+	prefs, _ := LoadPreferenceStore("", "work")
+	ct := NewCalendarTools(&Client{})
+	ct.SetPreferences(prefs)
+
+	result, err := ct.HandleTool("set_default_send_updates", map[string]interface{}{"send_updates": "none"})
+	if err != nil {
+		t.Fatalf("set_default_send_updates: %v", err)
+	}
+	if got := prefs.Get().DefaultSendUpdates; got != "none" {
+		t.Errorf("DefaultSendUpdates = %q, want none", got)
+	}
+	if !strings.Contains(result.Content[0].Text, "profile 'work'") {
+		t.Errorf("unexpected response %q", result.Content[0].Text)
+	}
+
+	if _, err := ct.HandleTool("set_default_send_updates", map[string]interface{}{"send_updates": "sometimes"}); err == nil {
+		t.Error("invalid value should fail")
+	}
+
+	if _, err := ct.HandleTool("set_default_send_updates", map[string]interface{}{"send_updates": "reset"}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if got := prefs.Get().DefaultSendUpdates; got != "" {
+		t.Errorf("DefaultSendUpdates after reset = %q, want empty", got)
+	}
+}
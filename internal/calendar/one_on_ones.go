@@ -0,0 +1,624 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// defaultRebalanceWeeks is how many upcoming weeks a new 1:1 time must be free
+// for both people
+const defaultRebalanceWeeks = 4
+
+// rebalanceDays are the days 1:1s are spread across.
+var rebalanceDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// rruleDays maps weekdays to their RRULE BYDAY codes.
+var rruleDays = map[time.Weekday]string{
+	time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+	time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+}
+
+// OneOnOne is a weekly recurring meeting between the calendar's owner and
+// one other person.
+type OneOnOne struct {
+	SeriesID string `json:"series_id"`
+	Title    string `json:"title"`
+	With     string `json:"with"`
+	Weekday  string `json:"weekday"`
+	Start    string `json:"start"` // local wall-clock time, HH:MM
+	End      string `json:"end"`
+	Movable  bool   `json:"movable"`
+	Reason   string `json:"reason,omitempty"` // why it cannot be moved
+
+	master   *calendar.Event
+	weekday  time.Weekday
+	offset   time.Duration // start offset from local midnight
+	duration time.Duration
+}
+
+// OneOnOneMove is a proposed new weekly time for a 1:1.
+type OneOnOneMove struct {
+	SeriesID    string `json:"series_id"`
+	Title       string `json:"title"`
+	With        string `json:"with"`
+	From        string `json:"from"` // e.g. "Tuesday 10:00"
+	To          string `json:"to"`
+	Applied     bool   `json:"applied"`
+	NewSeriesID string `json:"new_series_id,omitempty"` // set when the series was split
+	Error       string `json:"error,omitempty"`
+
+	meeting *OneOnOne
+	weekday time.Weekday
+	offset  time.Duration
+}
+
+// DayLoad is the number of 1:1s on a weekday before and after rebalancing.
+type DayLoad struct {
+	Weekday string `json:"weekday"`
+	Before  int    `json:"before"`
+	After   int    `json:"after"`
+}
+
+// RebalanceParams configures how 1:1s are spread across the week.
+type RebalanceParams struct {
+	FirstDay  time.Time     // local midnight of the first day checked
+	Weeks     int           // weeks a new time must be free for both people
+	WorkStart time.Duration // offset of the working day's start from midnight
+	WorkEnd   time.Duration // offset of the working day's end from midnight
+	MaxPerDay int           // 0 spreads the 1:1s as evenly as possible
+}
+
+// RecurringMasters returns the recurring series of a calendar that still have
+// occurrences from timeMin on, as their master events.
+func (c *Client) RecurringMasters(calendarID string, timeMin time.Time) ([]*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	var masters []*calendar.Event
+	call := c.service.Events.List(calendarID).
+		TimeMin(timeMin.Format(time.RFC3339)).
+		SingleEvents(false).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list recurring events: %v", err)
+		}
+		for _, event := range page.Items {
+			if len(event.Recurrence) > 0 && event.Status != "cancelled" {
+				masters = append(masters, event)
+			}
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return masters, nil
+}
+
+// weeklyRule reads the RRULE of a series that recurs every week on a single
+// day, returning its UNTIL (zero if none) and whether it has a COUNT. ok is
+// false for any other rule.
+func weeklyRule(recurrence []string) (until time.Time, hasCount, ok bool) {
+	rules := 0
+	for _, line := range recurrence {
+		if !strings.HasPrefix(line, "RRULE:") {
+			continue
+		}
+		rules++
+		for _, part := range strings.Split(strings.TrimPrefix(line, "RRULE:"), ";") {
+			key, value, _ := strings.Cut(part, "=")
+			switch strings.ToUpper(key) {
+			case "FREQ":
+				if !strings.EqualFold(value, "WEEKLY") {
+					return time.Time{}, false, false
+				}
+			case "INTERVAL":
+				if value != "1" {
+					return time.Time{}, false, false
+				}
+			case "BYDAY":
+				if strings.Contains(value, ",") || len(value) != 2 {
+					return time.Time{}, false, false
+				}
+			case "COUNT":
+				hasCount = true
+			case "UNTIL":
+				var err error
+				if until, err = time.Parse("20060102T150405Z", value); err != nil {
+					until, _ = time.Parse("20060102", value)
+				}
+			}
+		}
+		if !strings.Contains(strings.ToUpper(line), "FREQ=WEEKLY") {
+			return time.Time{}, false, false
+		}
+	}
+	return until, hasCount, rules == 1
+}
+
+// oneOnOneFromEvent returns master as a 1:1 if it is a timed weekly series
+// with exactly two people, one of them the owner of calendarID, that still
+// occurs on or after from. Meeting rooms do not count as people.
+func oneOnOneFromEvent(master *calendar.Event, calendarID string, loc *time.Location, from time.Time) (*OneOnOne, bool) {
+	if master.Start == nil || master.Start.DateTime == "" {
+		return nil, false
+	}
+	until, hasCount, ok := weeklyRule(master.Recurrence)
+	if !ok {
+		return nil, false
+	}
+
+	var people []*calendar.EventAttendee
+	for _, a := range master.Attendees {
+		if !a.Resource {
+			people = append(people, a)
+		}
+	}
+	if len(people) != 2 {
+		return nil, false
+	}
+	isOwner := func(a *calendar.EventAttendee) bool {
+		return a.Self || strings.EqualFold(a.Email, calendarID)
+	}
+	var other string
+	switch {
+	case isOwner(people[0]) && !isOwner(people[1]):
+		other = people[1].Email
+	case isOwner(people[1]) && !isOwner(people[0]):
+		other = people[0].Email
+	default:
+		return nil, false
+	}
+
+	start, _, err := eventStart(master.Start)
+	if err != nil {
+		return nil, false
+	}
+	end, _, err := eventStart(master.End)
+	if err != nil || !end.After(start) {
+		return nil, false
+	}
+	start = start.In(loc)
+	midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	next := atClock(nextWeekday(from, start.Weekday()), start.Sub(midnight))
+	if !until.IsZero() && until.Before(next) {
+		return nil, false
+	}
+	m := &OneOnOne{
+		SeriesID: master.Id,
+		Title:    eventTitle(master),
+		With:     other,
+		Weekday:  start.Weekday().String(),
+		Start:    start.Format("15:04"),
+		End:      start.Add(end.Sub(start)).Format("15:04"),
+		Movable:  true,
+		master:   master,
+		weekday:  start.Weekday(),
+		offset:   start.Sub(midnight),
+		duration: end.Sub(start),
+	}
+	switch {
+	case master.Organizer != nil && !master.Organizer.Self && !strings.EqualFold(master.Organizer.Email, calendarID):
+		m.Movable, m.Reason = false, "organized by "+master.Organizer.Email
+	case hasCount:
+		m.Movable, m.Reason = false, "ends after a set number of occurrences"
+	}
+	return m, true
+}
+
+// nextWeekday returns the first day on or after day that falls on weekday.
+func nextWeekday(day time.Time, weekday time.Weekday) time.Time {
+	return day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7)
+}
+
+// planRebalance proposes new weekly times that bring every weekday down to at
+// most params.MaxPerDay 1:1s (by default the even share, rounded up). 1:1s
+// on overloaded days move to the least loaded days with room, at the same
+// time of day if possible, else the closest time within working hours, and
+// only to times free for both people in every one of params.Weeks weeks.
+// busy maps calendarID and each other person to their busy periods; people
+// missing from it are not moved. Notes explain 1:1s that could not be moved.
+func planRebalance(meetings []*OneOnOne, busy map[string][]TimeSlot, calendarID string, params RebalanceParams) ([]OneOnOneMove, []string) {
+	counts := make(map[time.Weekday]int)
+	for _, m := range meetings {
+		counts[m.weekday]++
+	}
+	limit := params.MaxPerDay
+	if limit <= 0 {
+		limit = int(math.Ceil(float64(len(meetings)) / float64(len(rebalanceDays))))
+	}
+
+	// Copy the busy periods, since planned moves are added to them
+	planned := make(map[string][]TimeSlot, len(busy))
+	for id, slots := range busy {
+		planned[id] = append([]TimeSlot{}, slots...)
+	}
+
+	var moves []OneOnOneMove
+	var notes []string
+	for _, day := range rebalanceDays {
+		if counts[day] <= limit {
+			continue
+		}
+		// Move the latest 1:1s of the day first
+		var candidates []*OneOnOne
+		for _, m := range meetings {
+			if m.weekday == day {
+				candidates = append(candidates, m)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].offset > candidates[j].offset })
+
+		for _, m := range candidates {
+			if counts[day] <= limit {
+				break
+			}
+			if !m.Movable {
+				notes = append(notes, fmt.Sprintf("%s: not moved, %s", m.Title, m.Reason))
+				continue
+			}
+			if _, ok := planned[m.With]; !ok {
+				notes = append(notes, fmt.Sprintf("%s: %s's free/busy is not visible, so it was not moved", m.Title, m.With))
+				continue
+			}
+			weekday, offset, ok := rebalanceSlot(m, counts, limit, planned[calendarID], planned[m.With], params)
+			if !ok {
+				notes = append(notes, fmt.Sprintf("%s: no time on a lighter day is free for you and %s in each of the next %d weeks", m.Title, m.With, params.Weeks))
+				continue
+			}
+			counts[day]--
+			counts[weekday]++
+			for week := 0; week < params.Weeks; week++ {
+				start := atClock(nextWeekday(params.FirstDay, weekday).AddDate(0, 0, 7*week), offset)
+				slot := TimeSlot{Start: start, End: start.Add(m.duration)}
+				planned[calendarID] = append(planned[calendarID], slot)
+				planned[m.With] = append(planned[m.With], slot)
+			}
+			moves = append(moves, OneOnOneMove{
+				SeriesID: m.SeriesID,
+				Title:    m.Title,
+				With:     m.With,
+				From:     m.Weekday + " " + m.Start,
+				To:       weekday.String() + " " + formatClock(offset),
+				meeting:  m,
+				weekday:  weekday,
+				offset:   offset,
+			})
+		}
+		if counts[day] > limit {
+			notes = append(notes, fmt.Sprintf("%s keeps %d 1:1s (target %d)", day, counts[day], limit))
+		}
+	}
+	return moves, notes
+}
+
+// rebalanceSlot finds the weekly time for m on the least loaded day below
+// limit that is free for both people in every week checked.
+func rebalanceSlot(m *OneOnOne, counts map[time.Weekday]int, limit int, ownBusy, otherBusy []TimeSlot, params RebalanceParams) (time.Weekday, time.Duration, bool) {
+	var days []time.Weekday
+	for _, day := range rebalanceDays {
+		if day != m.weekday && counts[day] < limit {
+			days = append(days, day)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool { return counts[days[i]] < counts[days[j]] })
+
+	// The same time of day first, then the closest other times
+	var offsets []time.Duration
+	for offset := params.WorkStart; offset+m.duration <= params.WorkEnd; offset += availabilityStep {
+		offsets = append(offsets, offset)
+	}
+	distance := func(offset time.Duration) time.Duration {
+		if offset > m.offset {
+			return offset - m.offset
+		}
+		return m.offset - offset
+	}
+	sort.SliceStable(offsets, func(i, j int) bool { return distance(offsets[i]) < distance(offsets[j]) })
+	if m.offset < params.WorkStart || m.offset+m.duration > params.WorkEnd || m.offset%availabilityStep != 0 {
+		offsets = append([]time.Duration{m.offset}, offsets...)
+	}
+
+	for _, day := range days {
+		first := nextWeekday(params.FirstDay, day)
+		for _, offset := range offsets {
+			free := true
+			for week := 0; week < params.Weeks && free; week++ {
+				start := atClock(first.AddDate(0, 0, 7*week), offset)
+				end := start.Add(m.duration)
+				free = !overlapsAny(ownBusy, start, end) && !overlapsAny(otherBusy, start, end)
+			}
+			if free {
+				return day, offset, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// moveRecurrenceDay rewrites a weekly rule to recur on day. EXDATE and RDATE
+// lines are dropped, since they name times on the old day.
+func moveRecurrenceDay(recurrence []string, day time.Weekday) []string {
+	var result []string
+	for _, line := range recurrence {
+		if !strings.HasPrefix(line, "RRULE:") {
+			continue
+		}
+		var parts []string
+		for _, part := range strings.Split(strings.TrimPrefix(line, "RRULE:"), ";") {
+			key, _, _ := strings.Cut(part, "=")
+			if !strings.EqualFold(key, "BYDAY") {
+				parts = append(parts, part)
+			}
+		}
+		parts = append(parts, "BYDAY="+rruleDays[day])
+		result = append(result, "RRULE:"+strings.Join(parts, ";"))
+	}
+	return result
+}
+
+// applyMove moves a 1:1 to its new weekly time from the first day checked on.
+// A series with earlier occurrences is split, so its history keeps the old
+// time; one that has not started yet is simply moved.
+func (ct *CalendarTools) applyMove(calendarID string, move *OneOnOneMove, params RebalanceParams, sendUpdates string) error {
+	m := move.meeting
+	master := m.master
+	splitStart := atClock(nextWeekday(params.FirstDay, m.weekday), m.offset)
+	newStart := atClock(nextWeekday(params.FirstDay, move.weekday), move.offset)
+	recurrence := moveRecurrenceDay(master.Recurrence, move.weekday)
+
+	masterStart, _, err := eventStart(master.Start)
+	if err != nil {
+		return err
+	}
+	if !masterStart.Before(splitStart) {
+		newEnd := newStart.Add(m.duration)
+		_, err := ct.client.PatchEventDirect(master.Id, PatchEventParams{
+			CalendarID:    calendarID,
+			StartTime:     &newStart,
+			EndTime:       &newEnd,
+			TimeZone:      &master.Start.TimeZone,
+			Recurrence:    recurrence,
+			HasRecurrence: true,
+			SendUpdates:   sendUpdates,
+			ETag:          master.Etag,
+		})
+		return err
+	}
+
+	ended, err := endRecurrence(master.Recurrence, untilBefore(splitStart, false))
+	if err != nil {
+		return err
+	}
+	// End the listed series just before its first moved occurrence
+	if _, err := ct.client.PatchEventDirect(master.Id, PatchEventParams{
+		CalendarID:    calendarID,
+		Recurrence:    ended,
+		HasRecurrence: true,
+		SendUpdates:   sendUpdates,
+		ETag:          master.Etag,
+	}); err != nil {
+		return err
+	}
+	created, err := ct.client.StartSeries(calendarID, master, newStart, recurrence, sendUpdates)
+	if err != nil {
+		// Put the old rule back so the 1:1 is not lost
+		if _, restoreErr := ct.client.PatchEventDirect(master.Id, PatchEventParams{
+			CalendarID:    calendarID,
+			Recurrence:    master.Recurrence,
+			HasRecurrence: true,
+			SendUpdates:   sendUpdates,
+		}); restoreErr != nil {
+			return fmt.Errorf("failed to start the new series: %v; the old series now ends before %s and could not be restored (%v)", err, splitStart.Format("2006-01-02"), restoreErr)
+		}
+		return fmt.Errorf("failed to start the new series: %v; the 1:1 was left unchanged", err)
+	}
+	move.NewSeriesID = created.Id
+	return nil
+}
+
+func (ct *CalendarTools) handleRebalanceOneOnOnes(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	calendarID := ct.calendarID(arguments)
+
+	timezone := getStringOrDefault(arguments, "timezone", "")
+	if timezone == "" {
+		timezone = "UTC"
+		if entry, err := ct.client.CalendarListEntry(calendarID); err == nil && entry.TimeZone != "" {
+			timezone = entry.TimeZone
+		}
+	}
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	weeks := getIntOrDefault(arguments, "weeks", defaultRebalanceWeeks)
+	if weeks < 1 || weeks > maxRecurringSlotWeeks {
+		return nil, fmt.Errorf("weeks must be between 1 and %d", maxRecurringSlotWeeks)
+	}
+	maxPerDay := getIntOrDefault(arguments, "max_per_day", 0)
+	if maxPerDay < 0 {
+		return nil, fmt.Errorf("max_per_day must not be negative")
+	}
+	apply := getBoolOrDefault(arguments, "apply", false)
+	only, err := stringArguments(arguments, "series_ids")
+	if err != nil {
+		return nil, err
+	}
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start tomorrow so every moved occurrence is in the future
+	now := time.Now().In(loc)
+	params := RebalanceParams{
+		FirstDay:  time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc),
+		Weeks:     weeks,
+		MaxPerDay: maxPerDay,
+	}
+	if params.WorkStart, params.WorkEnd, err = ct.workHours(arguments); err != nil {
+		return nil, err
+	}
+
+	masters, err := ct.client.RecurringMasters(calendarID, params.FirstDay)
+	if err != nil {
+		return nil, err
+	}
+	var meetings []*OneOnOne
+	calendarIDs := []string{calendarID}
+	for _, master := range masters {
+		m, ok := oneOnOneFromEvent(master, calendarID, loc, params.FirstDay)
+		if !ok {
+			continue
+		}
+		meetings = append(meetings, m)
+		if !containsString(calendarIDs, m.With) {
+			calendarIDs = append(calendarIDs, m.With)
+		}
+	}
+	sort.SliceStable(meetings, func(i, j int) bool {
+		if meetings[i].weekday != meetings[j].weekday {
+			return (meetings[i].weekday+6)%7 < (meetings[j].weekday+6)%7
+		}
+		return meetings[i].offset < meetings[j].offset
+	})
+	if len(meetings) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{Type: "text", Text: "No weekly recurring 1:1s found on " + calendarID + "."}},
+		}, nil
+	}
+	if err := ct.fetchLimits.checkCalendars(calendarIDs); err != nil {
+		return nil, err
+	}
+
+	busy, unavailable, err := ct.recurringBusy(calendarIDs, map[string]bool{}, params.FirstDay, params.FirstDay.AddDate(0, 0, 7*params.Weeks), timezone)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := busy[calendarID]; !ok {
+		return nil, fmt.Errorf("free/busy of %s is not visible: %s", calendarID, strings.Join(unavailable, ", "))
+	}
+	moves, notes := planRebalance(meetings, busy, calendarID, params)
+
+	if apply {
+		for i := range moves {
+			if len(only) > 0 && !containsString(only, moves[i].SeriesID) {
+				continue
+			}
+			if err := ct.applyMove(calendarID, &moves[i], params, sendUpdates); err != nil {
+				moves[i].Error = err.Error()
+				continue
+			}
+			moves[i].Applied = true
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatRebalance(meetings, moves, notes, apply, sendUpdates, loc),
+		}},
+	}, nil
+}
+
+func formatRebalance(meetings []*OneOnOne, moves []OneOnOneMove, notes []string, apply bool, sendUpdates string, loc *time.Location) string {
+	var loads []DayLoad
+	index := make(map[string]int)
+	for _, day := range rebalanceDays {
+		index[day.String()] = len(loads)
+		loads = append(loads, DayLoad{Weekday: day.String()})
+	}
+	for _, m := range meetings {
+		i, ok := index[m.Weekday]
+		if !ok {
+			continue
+		}
+		loads[i].Before++
+		loads[i].After++
+	}
+	for _, move := range moves {
+		if apply && !move.Applied {
+			continue
+		}
+		loads[index[move.meeting.Weekday]].After--
+		loads[index[move.weekday.String()]].After++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🗓️ %d weekly 1:1s (%s)\n", len(meetings), loc.String())
+	for _, load := range loads {
+		if load.After != load.Before {
+			fmt.Fprintf(&b, "• %s: %d → %d\n", load.Weekday, load.Before, load.After)
+		} else {
+			fmt.Fprintf(&b, "• %s: %d\n", load.Weekday, load.Before)
+		}
+	}
+
+	if len(moves) == 0 {
+		b.WriteString("\nNo moves needed: the 1:1s are already spread across the week, or none can be moved.\n")
+	} else {
+		if apply {
+			fmt.Fprintf(&b, "\n✅ Moves (%s):\n", describeSendUpdates(sendUpdates))
+		} else {
+			b.WriteString("\n💡 Proposed moves:\n")
+		}
+		for _, move := range moves {
+			fmt.Fprintf(&b, "• %s with %s: %s → %s", move.Title, move.With, move.From, move.To)
+			switch {
+			case move.Error != "":
+				fmt.Fprintf(&b, " — failed: %s", move.Error)
+			case apply && !move.Applied:
+				b.WriteString(" — not applied")
+			}
+			b.WriteString("\n")
+		}
+		if !apply {
+			b.WriteString("\nRun again with apply: true to move them (series_ids limits which ones). Occurrences from tomorrow on move; earlier ones keep their time.\n")
+		}
+	}
+
+	if len(notes) > 0 {
+		b.WriteString("\nNotes:\n")
+		for _, note := range notes {
+			fmt.Fprintf(&b, "• %s\n", note)
+		}
+	}
+
+	jsonData, _ := json.MarshalIndent(map[string]interface{}{
+		"one_on_ones": meetings,
+		"days":        loads,
+		"moves":       moves,
+	}, "", "  ")
+	return b.String() + "\n" + string(jsonData)
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- weeklyRule -----
+
+func TestWeeklyRule(t *testing.T) {
+	cases := []struct {
+		name       string
+		recurrence []string
+		wantOK     bool
+		wantCount  bool
+		wantUntil  string
+	}{
+		{"weekly", []string{"RRULE:FREQ=WEEKLY;BYDAY=TU"}, true, false, ""},
+		{"weekly without BYDAY", []string{"RRULE:FREQ=WEEKLY"}, true, false, ""},
+		{"with until and exdate", []string{"RRULE:FREQ=WEEKLY;BYDAY=TU;UNTIL=20261231T235959Z", "EXDATE:20261103T100000Z"}, true, false, "2026-12-31"},
+		{"with count", []string{"RRULE:FREQ=WEEKLY;COUNT=10"}, true, true, ""},
+		{"every other week", []string{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU"}, false, false, ""},
+		{"several days", []string{"RRULE:FREQ=WEEKLY;BYDAY=TU,TH"}, false, false, ""},
+		{"daily", []string{"RRULE:FREQ=DAILY"}, false, false, ""},
+		{"no rule", []string{"RDATE:20261103T100000Z"}, false, false, ""},
+	}
+	for _, tc := range cases {
+		until, hasCount, ok := weeklyRule(tc.recurrence)
+		if ok != tc.wantOK || hasCount != tc.wantCount {
+			t.Errorf("%s: weeklyRule = (count %v, ok %v), want (%v, %v)", tc.name, hasCount, ok, tc.wantCount, tc.wantOK)
+		}
+		if got := ""; !until.IsZero() {
+			got = until.Format("2006-01-02")
+			if got != tc.wantUntil {
+				t.Errorf("%s: until = %s, want %s", tc.name, got, tc.wantUntil)
+			}
+		} else if tc.wantUntil != "" {
+			t.Errorf("%s: until not set, want %s", tc.name, tc.wantUntil)
+		}
+	}
+}
+
+// ----- oneOnOneFromEvent -----
+
+func TestOneOnOneFromEvent(t *testing.T) {
+	from := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	series := func(rule string, organizer string, emails ...string) *calendar.Event {
+		e := &calendar.Event{
+			Id:         "s1",
+			Summary:    "1:1",
+			Recurrence: []string{rule},
+			Start:      &calendar.EventDateTime{DateTime: "2026-09-01T10:00:00Z"},
+			End:        &calendar.EventDateTime{DateTime: "2026-09-01T10:30:00Z"},
+			Organizer:  &calendar.EventOrganizer{Email: organizer, Self: organizer == "me@example.com"},
+		}
+		for _, email := range emails {
+			e.Attendees = append(e.Attendees, &calendar.EventAttendee{Email: email, Self: email == "me@example.com"})
+		}
+		return e
+	}
+	withRoom := series("RRULE:FREQ=WEEKLY", "me@example.com", "me@example.com", "ana@example.com")
+	withRoom.Attendees = append(withRoom.Attendees, &calendar.EventAttendee{Email: "room@resource.calendar.google.com", Resource: true})
+
+	cases := []struct {
+		name        string
+		event       *calendar.Event
+		wantOK      bool
+		wantMovable bool
+	}{
+		{"organized 1:1", series("RRULE:FREQ=WEEKLY", "me@example.com", "me@example.com", "ana@example.com"), true, true},
+		{"rooms do not count", withRoom, true, true},
+		{"organized by the other person", series("RRULE:FREQ=WEEKLY", "ana@example.com", "me@example.com", "ana@example.com"), true, false},
+		{"fixed number of occurrences", series("RRULE:FREQ=WEEKLY;COUNT=20", "me@example.com", "me@example.com", "ana@example.com"), true, false},
+		{"three people", series("RRULE:FREQ=WEEKLY", "me@example.com", "me@example.com", "ana@example.com", "bo@example.com"), false, false},
+		{"without the user", series("RRULE:FREQ=WEEKLY", "me@example.com", "ana@example.com", "bo@example.com"), false, false},
+		{"ended before its next occurrence", series("RRULE:FREQ=WEEKLY;UNTIL=20261020T095959Z", "me@example.com", "me@example.com", "ana@example.com"), false, false},
+		{"monthly", series("RRULE:FREQ=MONTHLY", "me@example.com", "me@example.com", "ana@example.com"), false, false},
+	}
+	for _, tc := range cases {
+		m, ok := oneOnOneFromEvent(tc.event, "primary", time.UTC, from)
+		if ok != tc.wantOK {
+			t.Errorf("%s: ok = %v, want %v", tc.name, ok, tc.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if m.Movable != tc.wantMovable {
+			t.Errorf("%s: movable = %v (%s), want %v", tc.name, m.Movable, m.Reason, tc.wantMovable)
+		}
+		if m.With != "ana@example.com" || m.Weekday != "Tuesday" || m.Start != "10:00" || m.End != "10:30" {
+			t.Errorf("%s: got %+v", tc.name, m)
+		}
+	}
+}
+
+// ----- planRebalance -----
+
+func TestPlanRebalance(t *testing.T) {
+	firstDay := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) // a Monday
+	meeting := func(with string, weekday time.Weekday, hour int, movable bool) *OneOnOne {
+		return &OneOnOne{SeriesID: with, Title: "1:1 " + with, With: with, Weekday: weekday.String(), Start: formatClock(time.Duration(hour) * time.Hour), Movable: movable, Reason: "organized by " + with, weekday: weekday, offset: time.Duration(hour) * time.Hour, duration: 30 * time.Minute}
+	}
+	params := RebalanceParams{FirstDay: firstDay, Weeks: 2, WorkStart: 9 * time.Hour, WorkEnd: 17 * time.Hour}
+	// Bo is busy all day on Mondays and Wednesdays
+	var boBusy []TimeSlot
+	for week := 0; week < 2; week++ {
+		for _, day := range []int{0, 2} {
+			start := firstDay.AddDate(0, 0, 7*week+day)
+			boBusy = append(boBusy, TimeSlot{Start: start, End: start.Add(24 * time.Hour)})
+		}
+	}
+
+	meetings := []*OneOnOne{
+		meeting("ana", time.Tuesday, 10, true),
+		meeting("bo", time.Tuesday, 11, true),
+		meeting("cy", time.Tuesday, 12, false),
+		meeting("di", time.Tuesday, 13, true),
+		meeting("ed", time.Thursday, 10, true),
+	}
+	busy := map[string][]TimeSlot{"primary": nil, "ana": nil, "bo": boBusy, "di": nil, "ed": nil}
+
+	moves, notes := planRebalance(meetings, busy, "primary", params)
+	got := make(map[string]string)
+	for _, m := range moves {
+		got[m.With] = m.To
+	}
+	// Even share is 1 per day and cy cannot move, so the others leave
+	// Tuesday, latest first; bo avoids Monday and Wednesday
+	want := map[string]string{"di": "Monday 13:00", "bo": "Friday 11:00", "ana": "Wednesday 10:00"}
+	if len(got) != len(want) {
+		t.Fatalf("moves = %v, want %v", got, want)
+	}
+	for with, to := range want {
+		if got[with] != to {
+			t.Errorf("%s moved to %q, want %q (all moves: %v)", with, got[with], to, got)
+		}
+	}
+	if joined := strings.Join(notes, "\n"); !strings.Contains(joined, "1:1 cy: not moved, organized by cy") {
+		t.Errorf("notes = %q", joined)
+	}
+
+	// With no day left below the target, Tuesday keeps its extra 1:1s
+	params.MaxPerDay = 1
+	_, notes = planRebalance(append(meetings, meeting("fy", time.Monday, 9, true), meeting("gu", time.Wednesday, 9, true), meeting("hal", time.Friday, 9, true)), busy, "primary", params)
+	if joined := strings.Join(notes, "\n"); !strings.Contains(joined, "Tuesday keeps 4 1:1s (target 1)") {
+		t.Errorf("notes = %q", joined)
+	}
+	if len(busy["primary"]) != 0 {
+		t.Error("planRebalance modified the busy periods passed in")
+	}
+}
+
+func TestPlanRebalance_ClosestFreeTime(t *testing.T) {
+	firstDay := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	m := &OneOnOne{SeriesID: "s1", Title: "1:1", With: "ana", Weekday: "Tuesday", Start: "10:00", Movable: true, weekday: time.Tuesday, offset: 10 * time.Hour, duration: 30 * time.Minute}
+	other := &OneOnOne{SeriesID: "s2", Title: "1:1 bo", With: "bo", Weekday: "Tuesday", Start: "09:00", Movable: false, weekday: time.Tuesday, offset: 9 * time.Hour, duration: 30 * time.Minute}
+	params := RebalanceParams{FirstDay: firstDay, Weeks: 1, WorkStart: 9 * time.Hour, WorkEnd: 17 * time.Hour, MaxPerDay: 1}
+	monday := firstDay
+	// Ana is busy Monday 10:00-10:30, so 10:30 is the closest free start
+	busy := map[string][]TimeSlot{
+		"primary": {{Start: monday.Add(9*time.Hour + 30*time.Minute), End: monday.Add(10 * time.Hour)}},
+		"ana":     {{Start: monday.Add(10 * time.Hour), End: monday.Add(10*time.Hour + 30*time.Minute)}},
+	}
+
+	moves, _ := planRebalance([]*OneOnOne{other, m}, busy, "primary", params)
+	if len(moves) != 1 || moves[0].To != "Monday 10:30" {
+		t.Errorf("moves = %+v, want one move to Monday 10:30", moves)
+	}
+}
+
+// ----- moveRecurrenceDay -----
+
+func TestMoveRecurrenceDay(t *testing.T) {
+	got := moveRecurrenceDay([]string{"RRULE:FREQ=WEEKLY;BYDAY=TU;UNTIL=20261231T235959Z", "EXDATE:20261103T100000Z"}, time.Thursday)
+	want := "RRULE:FREQ=WEEKLY;UNTIL=20261231T235959Z;BYDAY=TH"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("moveRecurrenceDay = %v, want [%s]", got, want)
+	}
+	if got := moveRecurrenceDay([]string{"RRULE:FREQ=WEEKLY"}, time.Monday); got[0] != "RRULE:FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("moveRecurrenceDay without BYDAY = %v", got)
+	}
+}
+
+// ----- nextWeekday -----
+
+func TestNextWeekday(t *testing.T) {
+	saturday := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	if got := nextWeekday(saturday, time.Saturday); !got.Equal(saturday) {
+		t.Errorf("same day: got %v", got)
+	}
+	if got := nextWeekday(saturday, time.Monday).Format("2006-01-02"); got != "2026-10-19" {
+		t.Errorf("next Monday = %s, want 2026-10-19", got)
+	}
+}
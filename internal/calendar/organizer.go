@@ -0,0 +1,81 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// matchesOrganizer reports whether event was organized by who. An email
+// address must match exactly; anything else matches part of the organizer's
+// name or email, ignoring case. "me" matches events the user organizes.
+func matchesOrganizer(event *calendar.Event, who string) bool {
+	organizer := event.Organizer
+	if organizer == nil {
+		return false
+	}
+	who = strings.ToLower(strings.TrimSpace(who))
+	if who == "me" {
+		return organizer.Self
+	}
+	if strings.Contains(who, "@") {
+		return strings.EqualFold(organizer.Email, who)
+	}
+	return strings.Contains(strings.ToLower(organizer.DisplayName), who) ||
+		strings.Contains(strings.ToLower(organizer.Email), who)
+}
+
+// filterByOrganizer keeps the events organized by who.
+func filterByOrganizer(events []*calendar.Event, who string) []*calendar.Event {
+	filtered := make([]*calendar.Event, 0, len(events))
+	for _, event := range events {
+		if matchesOrganizer(event, who) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// formatPerson renders an organizer or creator as "Name <email>", marking the
+// signed-in user.
+func formatPerson(email, displayName string, self bool) string {
+	person := email
+	if displayName != "" && email != "" {
+		person = fmt.Sprintf("%s <%s>", displayName, email)
+	} else if displayName != "" {
+		person = displayName
+	}
+	if self {
+		person += " (you)"
+	}
+	return person
+}
+
+// personJSON is the JSON form of an organizer or creator.
+func personJSON(email, displayName string, self bool) map[string]interface{} {
+	person := map[string]interface{}{"email": email}
+	if displayName != "" {
+		person["displayName"] = displayName
+	}
+	if self {
+		person["self"] = true
+	}
+	return person
+}
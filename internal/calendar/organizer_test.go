@@ -0,0 +1,88 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- matchesOrganizer -----
+
+func TestMatchesOrganizer(t *testing.T) {
+	event := &calendar.Event{Organizer: &calendar.EventOrganizer{Email: "dana.lee@example.com", DisplayName: "Dana Lee"}}
+	mine := &calendar.Event{Organizer: &calendar.EventOrganizer{Email: "me@example.com", Self: true}}
+
+	tests := []struct {
+		name  string
+		event *calendar.Event
+		who   string
+		want  bool
+	}{
+		{"exact email", event, "dana.lee@example.com", true},
+		{"email ignores case", event, "Dana.Lee@Example.com", true},
+		{"email must match fully", event, "lee@example.com", false},
+		{"part of name", event, "dana", true},
+		{"full name ignores case", event, "dana lee", true},
+		{"part of email", event, "dana.lee", true},
+		{"other person", event, "sam", false},
+		{"me", mine, "me", true},
+		{"me is not someone else", event, "me", false},
+		{"no organizer", &calendar.Event{}, "dana", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesOrganizer(tt.event, tt.who); got != tt.want {
+				t.Errorf("matchesOrganizer(%q) = %v, want %v", tt.who, got, tt.want)
+			}
+		})
+	}
+}
+
+// ----- filterByOrganizer -----
+
+func TestFilterByOrganizer(t *testing.T) {
+	events := []*calendar.Event{
+		{Id: "1", Organizer: &calendar.EventOrganizer{Email: "dana@example.com"}},
+		{Id: "2", Organizer: &calendar.EventOrganizer{Email: "sam@example.com"}},
+		{Id: "3"},
+		{Id: "4", Organizer: &calendar.EventOrganizer{Email: "dana@example.com"}},
+	}
+	got := filterByOrganizer(events, "dana@example.com")
+	if len(got) != 2 || got[0].Id != "1" || got[1].Id != "4" {
+		t.Errorf("filterByOrganizer kept %d events, want events 1 and 4", len(got))
+	}
+}
+
+// ----- formatPerson -----
+
+func TestFormatPerson(t *testing.T) {
+	tests := []struct {
+		email, name string
+		self        bool
+		want        string
+	}{
+		{"dana@example.com", "Dana Lee", false, "Dana Lee <dana@example.com>"},
+		{"dana@example.com", "", false, "dana@example.com"},
+		{"", "Team calendar", false, "Team calendar"},
+		{"me@example.com", "", true, "me@example.com (you)"},
+	}
+	for _, tt := range tests {
+		if got := formatPerson(tt.email, tt.name, tt.self); got != tt.want {
+			t.Errorf("formatPerson(%q, %q, %v) = %q, want %q", tt.email, tt.name, tt.self, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// maxOverlapMoves bounds how many conflicts get a move suggestion, each
+	// of which reads the day's events and the attendees' free/busy
+	maxOverlapMoves = 5
+	// overlapWorkStart and overlapWorkEnd bound where a conflicting event may
+	// be moved to on its day
+	overlapWorkStart = 9 * time.Hour
+	overlapWorkEnd   = 17 * time.Hour
+)
+
+// Kinds of overlap resolutions.
+const (
+	resolveDecline = "decline"
+	resolveShorten = "shorten"
+	resolveMove    = "move"
+	resolveSkip    = "skip"
+)
+
+// OverlapConflict is two overlapping events, the one that looks more
+// important first, and the ways to resolve the conflict.
+type OverlapConflict struct {
+	EventIDs    []string            `json:"event_ids"`
+	Summaries   []string            `json:"summaries"`
+	Start       string              `json:"overlap_start"`
+	End         string              `json:"overlap_end"`
+	Minutes     int                 `json:"overlap_minutes"`
+	Reason      string              `json:"reason"` // why the second event looks less important
+	Resolutions []OverlapResolution `json:"resolutions"`
+	Notes       []string            `json:"notes,omitempty"`
+	events      [2]*calendar.Event  // higher priority first
+	spans       [2]TimeSlot
+}
+
+// OverlapResolution is one way to resolve a conflict, carried out by a single
+// call of Tool with Arguments.
+type OverlapResolution struct {
+	Kind        string                 `json:"kind"` // "decline", "shorten", "move" or "skip"
+	EventID     string                 `json:"event_id"`
+	Description string                 `json:"description"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments"`
+}
+
+// eventPriority scores how much the user is committed to an event: their
+// response (organizing counts as accepting), whether they are only optional,
+// and whether other people attend. A priority set on the event outweighs all
+// of these. A higher score is more important.
+func eventPriority(e *calendar.Event) (int, string) {
+	score, reason := 0, "you have not responded"
+	if e.Organizer != nil && e.Organizer.Self {
+		score, reason = 3, "you organize it"
+	}
+	others := 0
+	for _, a := range e.Attendees {
+		if a.Resource {
+			continue
+		}
+		if !a.Self {
+			others++
+			continue
+		}
+		switch {
+		case e.Organizer != nil && e.Organizer.Self:
+		case a.ResponseStatus == "accepted":
+			score, reason = 3, "you accepted it"
+		case a.ResponseStatus == "tentative":
+			score, reason = 1, "you answered maybe"
+		}
+		if a.Optional {
+			score -= 2
+			reason += " as an optional guest"
+		}
+	}
+	if len(e.Attendees) == 0 {
+		score, reason = 2, "it has no other attendees"
+	}
+	switch {
+	case others >= 5:
+		score += 2
+	case others > 0:
+		score++
+	}
+	switch eventPriorityLevel(e) {
+	case priorityHigh:
+		score, reason = score+10, "it is marked high priority"
+	case priorityLow:
+		score, reason = score-10, "it is marked low priority"
+	}
+	return score, reason
+}
+
+// findOverlapConflicts pairs up the timed events that overlap, skipping free
+// and cancelled events, and declined ones unless showDeclined. Each pair is
+// ordered by eventPriority; on a tie, the event starting later comes second.
+func findOverlapConflicts(events []*calendar.Event, showDeclined bool) []OverlapConflict {
+	type timed struct {
+		event *calendar.Event
+		span  TimeSlot
+	}
+	var candidates []timed
+	for _, e := range events {
+		if e.Status == "cancelled" || e.Transparency == "transparent" || (!showDeclined && selfDeclined(e)) {
+			continue
+		}
+		start, end, allDay, err := parseEventTimes(e)
+		if err != nil || allDay {
+			continue
+		}
+		candidates = append(candidates, timed{e, TimeSlot{Start: start, End: end}})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].span.Start.Before(candidates[j].span.Start) })
+
+	var conflicts []OverlapConflict
+	for i, a := range candidates {
+		for _, b := range candidates[i+1:] {
+			if !eventsOverlap(a.span.Start, a.span.End, b.span.Start, b.span.End) {
+				continue
+			}
+			first, second := a, b
+			firstScore, _ := eventPriority(a.event)
+			secondScore, _ := eventPriority(b.event)
+			if secondScore > firstScore {
+				first, second = b, a
+			}
+			_, reason := eventPriority(second.event)
+			if eventPriorityLevel(first.event) == priorityHigh && eventPriorityLevel(second.event) != priorityHigh {
+				reason = "the other event is marked high priority"
+			}
+
+			start, end := a.span.Start, a.span.End
+			if b.span.Start.After(start) {
+				start = b.span.Start
+			}
+			if b.span.End.Before(end) {
+				end = b.span.End
+			}
+			conflicts = append(conflicts, OverlapConflict{
+				EventIDs:    []string{first.event.Id, second.event.Id},
+				Summaries:   []string{eventTitle(first.event), eventTitle(second.event)},
+				Start:       start.Format(time.RFC3339),
+				End:         end.Format(time.RFC3339),
+				Minutes:     int(end.Sub(start) / time.Minute),
+				Reason:      reason,
+				Resolutions: []OverlapResolution{},
+				events:      [2]*calendar.Event{first.event, second.event},
+				spans:       [2]TimeSlot{first.span, second.span},
+			})
+		}
+	}
+	return conflicts
+}
+
+// declineResolution declines the event for the user through edit_event,
+// which replaces the attendee list, so every other attendee is passed on
+// unchanged. Events the user organizes, or whose attendee list is truncated,
+// cannot be declined this way.
+func declineResolution(e *calendar.Event, calendarID string) (OverlapResolution, bool) {
+	if e.AttendeesOmitted || (e.Organizer != nil && e.Organizer.Self) {
+		return OverlapResolution{}, false
+	}
+	var attendees []interface{}
+	invited := false
+	for _, a := range e.Attendees {
+		attendee := map[string]interface{}{"email": a.Email, "response_status": a.ResponseStatus}
+		if a.DisplayName != "" {
+			attendee["display_name"] = a.DisplayName
+		}
+		if a.Optional {
+			attendee["optional"] = true
+		}
+		if a.Self {
+			attendee["response_status"] = "declined"
+			invited = true
+		}
+		attendees = append(attendees, attendee)
+	}
+	if !invited {
+		return OverlapResolution{}, false
+	}
+	return OverlapResolution{
+		Kind:        resolveDecline,
+		EventID:     e.Id,
+		Description: fmt.Sprintf("Decline '%s'", eventTitle(e)),
+		Tool:        "edit_event",
+		Arguments: map[string]interface{}{
+			"calendar_id": calendarID,
+			"event_id":    e.Id,
+			"attendees":   attendees,
+		},
+	}, true
+}
+
+// shortenResolution trims span so it ends when other starts, or starts when
+// other ends, keeping at least minGap of it.
+func shortenResolution(e *calendar.Event, span, other TimeSlot, calendarID string, loc *time.Location, tf TimeFormat) (OverlapResolution, bool) {
+	newStart, newEnd := span.Start, span.End
+	switch {
+	case span.Start.Before(other.Start) && other.Start.Sub(span.Start) >= minGap:
+		newEnd = other.Start
+	case span.End.After(other.End) && span.End.Sub(other.End) >= minGap:
+		newStart = other.End
+	default:
+		return OverlapResolution{}, false
+	}
+	return OverlapResolution{
+		Kind:    resolveShorten,
+		EventID: e.Id,
+		Description: fmt.Sprintf("Shorten '%s' from %s–%s to %s–%s", eventTitle(e),
+			tf.Clock(span.Start.In(loc)), tf.Clock(span.End.In(loc)), tf.Clock(newStart.In(loc)), tf.Clock(newEnd.In(loc))),
+		Tool: "edit_event",
+		Arguments: map[string]interface{}{
+			"calendar_id": calendarID,
+			"event_id":    e.Id,
+			"start_time":  newStart.In(loc).Format(time.RFC3339),
+			"end_time":    newEnd.In(loc).Format(time.RFC3339),
+		},
+	}, true
+}
+
+// nearestFreeSlot returns the free slot closest to span on its day, within
+// overlapWorkStart–overlapWorkEnd and not before now, stepping by
+// availabilityStep. busy must not include the event being moved.
+func nearestFreeSlot(span TimeSlot, busy []TimeSlot, now time.Time, loc *time.Location) (TimeSlot, bool) {
+	start := span.Start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	windowStart, windowEnd := atClock(day, overlapWorkStart), atClock(day, overlapWorkEnd)
+	duration := span.End.Sub(span.Start)
+
+	fits := func(s time.Time) bool {
+		return !s.Before(windowStart) && !s.Before(now) && !s.Add(duration).After(windowEnd) && !overlapsAny(busy, s, s.Add(duration))
+	}
+	for step := availabilityStep; step <= overlapWorkEnd-overlapWorkStart; step += availabilityStep {
+		for _, s := range []time.Time{span.Start.Add(step), span.Start.Add(-step)} {
+			if fits(s) {
+				return TimeSlot{Start: s, End: s.Add(duration)}, true
+			}
+		}
+	}
+	return TimeSlot{}, false
+}
+
+// moveResolution looks for the nearest slot on the event's day where the user
+// and the event's other attendees are all free. It returns a note instead
+// when there is none or it could not be checked.
+func (ct *CalendarTools) moveResolution(e *calendar.Event, span TimeSlot, calendarID string, loc *time.Location, tf TimeFormat) (*OverlapResolution, string) {
+	start := span.Start.In(loc)
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	day, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      dayStart,
+		TimeMax:      dayEnd,
+		SingleEvents: true,
+	})
+	if err != nil {
+		return nil, fmt.Sprintf("'%s' could not be moved: %v", eventTitle(e), err)
+	}
+	var busy []TimeSlot
+	for _, other := range day.Items {
+		if other.Id == e.Id || !blocksTime(other) {
+			continue
+		}
+		if s, end, _, err := parseEventTimes(other); err == nil {
+			busy = append(busy, TimeSlot{Start: s, End: end})
+		}
+	}
+
+	var others []string
+	for _, a := range e.Attendees {
+		if !a.Self && !a.Resource {
+			others = append(others, a.Email)
+		}
+	}
+	if len(others) > 0 {
+		response, err := ct.client.GetFreeBusy(FreeBusyParams{TimeMin: dayStart, TimeMax: dayEnd, CalendarIDs: others})
+		if err != nil {
+			return nil, fmt.Sprintf("'%s' could not be moved: its attendees' availability could not be checked: %v", eventTitle(e), err)
+		}
+		for _, email := range others {
+			if cal, ok := response.Calendars[email]; ok && len(cal.Errors) == 0 {
+				for _, b := range busySlots(cal) {
+					// The event itself shows as busy for its attendees
+					if !(b.Start.Equal(span.Start) && b.End.Equal(span.End)) {
+						busy = append(busy, b)
+					}
+				}
+			}
+		}
+	}
+
+	slot, ok := nearestFreeSlot(span, busy, time.Now(), loc)
+	if !ok {
+		return nil, fmt.Sprintf("'%s' has no free slot of the same length on %s between %s and %s", eventTitle(e),
+			tf.ShortDate(start), formatClock(overlapWorkStart), formatClock(overlapWorkEnd))
+	}
+	description := fmt.Sprintf("Move '%s' from %s to %s–%s", eventTitle(e), tf.Clock(start), tf.Clock(slot.Start.In(loc)), tf.Clock(slot.End.In(loc)))
+	if len(others) > 0 {
+		description += " (attendees are free)"
+	}
+	return &OverlapResolution{
+		Kind:        resolveMove,
+		EventID:     e.Id,
+		Description: description,
+		Tool:        "edit_event",
+		Arguments: map[string]interface{}{
+			"calendar_id": calendarID,
+			"event_id":    e.Id,
+			"start_time":  slot.Start.In(loc).Format(time.RFC3339),
+			"end_time":    slot.End.In(loc).Format(time.RFC3339),
+		},
+	}, ""
+}
+
+// overlapConflicts finds the conflicts among events and their resolutions:
+// declining the less important event, shortening an event the user may edit
+// (the less important one first), and moving it to the nearest free slot.
+// Only the first maxOverlapMoves conflicts get a move.
+func (ct *CalendarTools) overlapConflicts(events []*calendar.Event, params ListEventsParams) []OverlapConflict {
+	conflicts := findOverlapConflicts(events, params.ShowDeclined)
+	if len(conflicts) == 0 {
+		return conflicts
+	}
+	loc := locationOrUTC(params.TimeZone)
+	tf := ct.client.TimeFormat()
+
+	moves := 0
+	for i := range conflicts {
+		c := &conflicts[i]
+		lower := c.events[1]
+		if r, ok := declineResolution(lower, params.CalendarID); ok {
+			c.Resolutions = append(c.Resolutions, r)
+		}
+
+		// Shorten or move the less important event, else the other one;
+		// high-priority events stay where they are
+		var editable []int
+		pinned := false
+		for _, k := range []int{1, 0} {
+			if eventPriorityLevel(c.events[k]) == priorityHigh {
+				pinned = true
+				continue
+			}
+			access := ct.client.EventAccess(params.CalendarID, c.events[k])
+			if access.Level == accessOrganizer || access.Level == accessGuestEditor {
+				editable = append(editable, k)
+			}
+		}
+		for _, k := range editable {
+			if r, ok := shortenResolution(c.events[k], c.spans[k], c.spans[1-k], params.CalendarID, loc, tf); ok {
+				c.Resolutions = append(c.Resolutions, r)
+				break
+			}
+		}
+		if len(editable) > 0 && moves < maxOverlapMoves {
+			moves++
+			k := editable[0]
+			r, note := ct.moveResolution(c.events[k], c.spans[k], params.CalendarID, loc, tf)
+			if r != nil {
+				c.Resolutions = append(c.Resolutions, *r)
+			} else {
+				c.Notes = append(c.Notes, note)
+			}
+		}
+		if len(editable) == 0 && pinned {
+			c.Notes = append(c.Notes, "Neither event can be moved or shortened: high-priority events stay where they are")
+		} else if len(editable) == 0 {
+			c.Notes = append(c.Notes, fmt.Sprintf("Neither event can be moved or shortened: %s organizes '%s'",
+				ct.client.EventAccess(params.CalendarID, lower).organizerName(), eventTitle(lower)))
+		}
+	}
+	return conflicts
+}
+
+// formatOverlapConflicts renders the conflicts with their resolutions and the
+// call that carries out each one.
+func formatOverlapConflicts(conflicts []OverlapConflict, loc *time.Location, tf TimeFormat) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "⚠️ %d conflict(s):\n", len(conflicts))
+	for _, c := range conflicts {
+		start, _ := time.Parse(time.RFC3339, c.Start)
+		end, _ := time.Parse(time.RFC3339, c.End)
+		fmt.Fprintf(&result, "\n• '%s' and '%s' overlap %s, %s–%s (%d min); '%s' looks less important: %s\n",
+			c.Summaries[0], c.Summaries[1], tf.ShortDate(start.In(loc)), tf.Clock(start.In(loc)), tf.Clock(end.In(loc)), c.Minutes, c.Summaries[1], c.Reason)
+		for i, r := range c.Resolutions {
+			args, _ := json.Marshal(r.Arguments)
+			fmt.Fprintf(&result, "  %d. %s\n     → %s %s\n", i+1, r.Description, r.Tool, string(args))
+		}
+		for _, note := range c.Notes {
+			fmt.Fprintf(&result, "  ℹ️ %s\n", note)
+		}
+	}
+	if repeatedSeriesConflicts(conflicts) {
+		result.WriteString("\n💡 Some of these conflicts repeat between the same recurring series; find_series_conflicts shows the pattern and fixes the whole series at once.\n")
+	}
+	return result.String()
+}
+
+// repeatedSeriesConflicts reports whether two or more conflicts are between
+// occurrences of the same two series.
+func repeatedSeriesConflicts(conflicts []OverlapConflict) bool {
+	seen := make(map[[2]string]bool)
+	for _, c := range conflicts {
+		pair, ok := seriesPair(c)
+		if !ok {
+			continue
+		}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		if seen[pair] {
+			return true
+		}
+		seen[pair] = true
+	}
+	return false
+}
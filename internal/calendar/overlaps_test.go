@@ -0,0 +1,168 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func overlapEvent(id, start, end string, attendees ...*calendar.EventAttendee) *calendar.Event {
+	return &calendar.Event{
+		Id:        id,
+		Summary:   id,
+		Organizer: &calendar.EventOrganizer{Email: "me@example.com", Self: true},
+		Start:     &calendar.EventDateTime{DateTime: "2025-03-03T" + start + ":00Z"},
+		End:       &calendar.EventDateTime{DateTime: "2025-03-03T" + end + ":00Z"},
+		Attendees: attendees,
+	}
+}
+
+// ----- findOverlapConflicts -----
+
+func TestFindOverlapConflicts(t *testing.T) {
+	invite := overlapEvent("invite", "10:30", "11:30",
+		&calendar.EventAttendee{Email: "boss@example.com", Organizer: true, ResponseStatus: "accepted"},
+		&calendar.EventAttendee{Email: "me@example.com", Self: true, ResponseStatus: "needsAction"})
+	invite.Organizer = &calendar.EventOrganizer{Email: "boss@example.com"}
+	sync := overlapEvent("sync", "10:00", "11:00",
+		&calendar.EventAttendee{Email: "me@example.com", Self: true, ResponseStatus: "accepted"},
+		&calendar.EventAttendee{Email: "ana@example.com"})
+	free := overlapEvent("free", "10:00", "12:00")
+	free.Transparency = "transparent"
+	solo := overlapEvent("solo", "14:00", "15:00")
+	later := overlapEvent("later", "14:30", "15:30")
+
+	conflicts := findOverlapConflicts([]*calendar.Event{invite, sync, free, solo, later}, false)
+	if len(conflicts) != 2 {
+		t.Fatalf("got %d conflicts, want 2: %+v", len(conflicts), conflicts)
+	}
+	c := conflicts[0]
+	if c.EventIDs[0] != "sync" || c.EventIDs[1] != "invite" || c.Minutes != 30 || c.Start != "2025-03-03T10:30:00Z" || c.Reason != "you have not responded" {
+		t.Errorf("unexpected conflict %+v", c)
+	}
+	// On a tie the event starting later is the less important one
+	if c := conflicts[1]; c.EventIDs[0] != "solo" || c.EventIDs[1] != "later" {
+		t.Errorf("tie order = %v, want [solo later]", c.EventIDs)
+	}
+}
+
+func TestFindOverlapConflicts_Priority(t *testing.T) {
+	sync := overlapEvent("sync", "10:00", "11:00",
+		&calendar.EventAttendee{Email: "me@example.com", Self: true, ResponseStatus: "accepted"},
+		&calendar.EventAttendee{Email: "ana@example.com"})
+	sync.ExtendedProperties = &calendar.EventExtendedProperties{Private: map[string]string{priorityKey: priorityLow}}
+	solo := overlapEvent("solo", "10:30", "11:30")
+	solo.ExtendedProperties = &calendar.EventExtendedProperties{Private: map[string]string{priorityKey: priorityNormal}}
+
+	conflicts := findOverlapConflicts([]*calendar.Event{sync, solo}, false)
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want 1", len(conflicts))
+	}
+	if c := conflicts[0]; c.EventIDs[0] != "solo" || c.EventIDs[1] != "sync" || c.Reason != "it is marked low priority" {
+		t.Errorf("low priority should lose: %+v", c)
+	}
+}
+
+func TestValidatePriority(t *testing.T) {
+	for _, p := range []string{"", "high", "normal", "low"} {
+		if err := validatePriority(p); err != nil {
+			t.Errorf("validatePriority(%q) = %v", p, err)
+		}
+	}
+	if err := validatePriority("urgent"); err == nil {
+		t.Error("expected an error for 'urgent'")
+	}
+}
+
+// ----- declineResolution -----
+
+func TestDeclineResolution(t *testing.T) {
+	invite := overlapEvent("invite", "10:00", "11:00",
+		&calendar.EventAttendee{Email: "boss@example.com", DisplayName: "Boss", ResponseStatus: "accepted"},
+		&calendar.EventAttendee{Email: "me@example.com", Self: true, Optional: true, ResponseStatus: "tentative"})
+	invite.Organizer = &calendar.EventOrganizer{Email: "boss@example.com"}
+
+	r, ok := declineResolution(invite, "primary")
+	if !ok || r.Tool != "edit_event" || r.Arguments["event_id"] != "invite" {
+		t.Fatalf("declineResolution() = %+v, %v", r, ok)
+	}
+	attendees := r.Arguments["attendees"].([]interface{})
+	boss := attendees[0].(map[string]interface{})
+	me := attendees[1].(map[string]interface{})
+	if boss["response_status"] != "accepted" || boss["display_name"] != "Boss" || me["response_status"] != "declined" || me["optional"] != true {
+		t.Errorf("attendees = %v", attendees)
+	}
+
+	if _, ok := declineResolution(overlapEvent("mine", "10:00", "11:00"), "primary"); ok {
+		t.Error("an event the user organizes should not be declined")
+	}
+	invite.AttendeesOmitted = true
+	if _, ok := declineResolution(invite, "primary"); ok {
+		t.Error("an event with a truncated attendee list should not be declined")
+	}
+}
+
+// ----- shortenResolution -----
+
+func TestShortenResolution(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 3, h, m, 0, 0, time.UTC) }
+	e := overlapEvent("e", "10:00", "11:00")
+
+	tests := []struct {
+		name      string
+		span      TimeSlot
+		other     TimeSlot
+		wantStart string
+		wantEnd   string
+	}{
+		{"ends early", TimeSlot{at(10, 0), at(11, 0)}, TimeSlot{at(10, 30), at(12, 0)}, "2025-03-03T10:00:00Z", "2025-03-03T10:30:00Z"},
+		{"starts late", TimeSlot{at(10, 0), at(11, 0)}, TimeSlot{at(9, 0), at(10, 45)}, "2025-03-03T10:45:00Z", "2025-03-03T11:00:00Z"},
+		{"too little left", TimeSlot{at(10, 0), at(11, 0)}, TimeSlot{at(10, 5), at(11, 0)}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := shortenResolution(e, tt.span, tt.other, "primary", time.UTC, TimeFormat{})
+			if tt.wantStart == "" {
+				if ok {
+					t.Errorf("shortenResolution() = %+v, want none", r)
+				}
+				return
+			}
+			if !ok || r.Arguments["start_time"] != tt.wantStart || r.Arguments["end_time"] != tt.wantEnd {
+				t.Errorf("shortenResolution() = %+v, want %s–%s", r.Arguments, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// ----- nearestFreeSlot -----
+
+func TestNearestFreeSlot(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 3, h, m, 0, 0, time.UTC) }
+	span := TimeSlot{at(10, 0), at(11, 0)}
+	busy := []TimeSlot{{at(9, 0), at(10, 30)}, {at(11, 0), at(12, 0)}}
+
+	slot, ok := nearestFreeSlot(span, busy, at(8, 0), time.UTC)
+	if !ok || !slot.Start.Equal(at(12, 0)) {
+		t.Errorf("nearestFreeSlot() = %v, %v; want 12:00", slot.Start, ok)
+	}
+	// Slots before now are not offered
+	if _, ok := nearestFreeSlot(span, busy, at(16, 30), time.UTC); ok {
+		t.Error("no slot should fit after 16:30")
+	}
+}
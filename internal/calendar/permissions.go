@@ -0,0 +1,267 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// Access levels reported by eventAccess.
+const (
+	accessOrganizer   = "organizer"    // may change or delete the event
+	accessGuestEditor = "guest_editor" // a guest the organizer allowed to modify the event
+	accessGuest       = "guest"        // may RSVP, or remove the event from their own calendar
+	accessReadOnly    = "read_only"    // may only view the event
+)
+
+// EventAccess describes what the user may do with an event.
+type EventAccess struct {
+	Level        string
+	CalendarID   string
+	CalendarRole string // accessRole of the calendar holding the event; "" if unknown
+	Organizer    string // organizer email or display name
+}
+
+// calendarAccessRole returns the user's accessRole on a calendar ("owner",
+// "writer", "reader" or "freeBusyReader"). Roles are cached for the session.
+func (c *Client) calendarAccessRole(calendarID string) (string, error) {
+	if calendarID == "" || calendarID == "primary" {
+		return "owner", nil
+	}
+	c.cacheMu.Lock()
+	role, ok := c.accessRoles[calendarID]
+	c.cacheMu.Unlock()
+	if ok {
+		return role, nil
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return "", err
+	}
+
+	entry, err := c.service.CalendarList.Get(calendarID).Fields("accessRole").Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get access role for calendar %s: %v", calendarID, err)
+	}
+	c.setAccessRole(calendarID, entry.AccessRole)
+	return entry.AccessRole, nil
+}
+
+// setAccessRole caches the user's role on a calendar; "" forgets it.
+func (c *Client) setAccessRole(calendarID, role string) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	if role == "" {
+		delete(c.accessRoles, calendarID)
+		return
+	}
+	if c.accessRoles == nil {
+		c.accessRoles = make(map[string]string)
+	}
+	c.accessRoles[calendarID] = role
+}
+
+// EventAccess determines what the user may do with event on calendarID. If
+// the calendar's access role cannot be read (e.g. it is not in the user's
+// calendar list), the decision is based on the event alone.
+func (c *Client) EventAccess(calendarID string, event *calendar.Event) EventAccess {
+	role, err := c.calendarAccessRole(calendarID)
+	if err != nil {
+		role = ""
+	}
+	return eventAccess(calendarID, role, event)
+}
+
+// eventAccess classifies the user's rights on an event from the calendar's
+// access role and the event's organizer and guest settings.
+func eventAccess(calendarID, calendarRole string, event *calendar.Event) EventAccess {
+	access := EventAccess{CalendarID: calendarID, CalendarRole: calendarRole}
+	if event.Organizer != nil {
+		access.Organizer = event.Organizer.Email
+		if access.Organizer == "" {
+			access.Organizer = event.Organizer.DisplayName
+		}
+	}
+
+	switch {
+	case calendarRole == "reader" || calendarRole == "freeBusyReader":
+		access.Level = accessReadOnly
+	case event.Organizer == nil || event.Organizer.Self:
+		access.Level = accessOrganizer
+	case strings.EqualFold(event.Organizer.Email, calendarID) && (calendarRole == "owner" || calendarRole == "writer"):
+		// The event belongs to a calendar the user can write to
+		access.Level = accessOrganizer
+	case event.GuestsCanModify:
+		access.Level = accessGuestEditor
+	default:
+		access.Level = accessGuest
+	}
+	return access
+}
+
+// checkEdit explains why the user cannot make the edit described by params to
+// event, or returns nil. Guests may still RSVP and change their own reminders
+// and event color.
+func (a EventAccess) checkEdit(title string, params PatchEventParams, event *calendar.Event) error {
+	switch a.Level {
+	case accessReadOnly:
+		return fmt.Errorf("cannot change '%s': calendar %s is shared with you read-only (%s), so you can view its events but not change them", title, a.CalendarID, a.CalendarRole)
+	case accessGuest:
+		if !isGuestEdit(params, event) {
+			return fmt.Errorf("cannot change '%s': it is organized by %s and you are a guest. You can RSVP but not change the time, title, attendees or other details — ask the organizer to make the change", title, a.organizerName())
+		}
+	}
+	return nil
+}
+
+// isGuestEdit reports whether params only touches what a guest controls: RSVP
+// status of existing attendees, reminders and the event color.
+func isGuestEdit(params PatchEventParams, event *calendar.Event) bool {
+	if params.Summary != nil || params.Description != nil || params.Location != nil ||
+		params.StartTime != nil || params.EndTime != nil || params.TimeZone != nil || params.AllDay != nil ||
+		params.HasRecurrence || params.Visibility != nil || params.GuestCanModify != nil ||
+		params.GuestCanInviteOthers != nil || params.GuestCanSeeOtherGuests != nil || params.AnyoneCanAddSelf != nil ||
+		params.ConferenceData != nil || params.RemoveConference || params.EventType != nil || params.WorkingLocation != nil ||
+		len(params.AttendeeUpdates) > 0 {
+		return false
+	}
+	for _, attendee := range params.Attendees {
+		if !hasAttendee(event, attendee.Email) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAttendee(event *calendar.Event, email string) bool {
+	for _, a := range event.Attendees {
+		if strings.EqualFold(a.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDelete explains why the user cannot delete the event, or returns nil.
+// Guests may delete, which only removes the event from their own calendar.
+func (a EventAccess) checkDelete(title string) error {
+	if a.Level == accessReadOnly {
+		return fmt.Errorf("cannot delete '%s': calendar %s is shared with you read-only (%s), so you can view its events but not delete them", title, a.CalendarID, a.CalendarRole)
+	}
+	return nil
+}
+
+// Effects of deleting an event, as named by delete_event's mode argument.
+const (
+	deleteRemoveFromMine    = "remove_from_my_calendar" // only the user's copy goes; the event stands for everyone else
+	deleteCancelForEveryone = "cancel_for_everyone"     // the event is cancelled for every guest
+)
+
+// deleteMode returns what deleting event does: an organizer cancels it for
+// everyone, while a guest, or anyone holding a private copy, only removes
+// their own copy.
+func (a EventAccess) deleteMode(event *calendar.Event) string {
+	if event.PrivateCopy || a.Level == accessGuest || a.Level == accessGuestEditor {
+		return deleteRemoveFromMine
+	}
+	return deleteCancelForEveryone
+}
+
+// checkDeleteMode explains why deleting event would not have the requested
+// effect, or returns nil. An empty mode accepts whatever deleting does.
+func (a EventAccess) checkDeleteMode(mode, title string, event *calendar.Event) error {
+	if mode != "" && mode != deleteRemoveFromMine && mode != deleteCancelForEveryone {
+		return fmt.Errorf("invalid mode %q: use %s or %s", mode, deleteRemoveFromMine, deleteCancelForEveryone)
+	}
+	if mode == "" || mode == a.deleteMode(event) {
+		return nil
+	}
+	if mode == deleteCancelForEveryone {
+		if event.PrivateCopy {
+			return fmt.Errorf("cannot cancel '%s' for everyone: it is a private copy of an event organized by %s, so deleting it only removes your copy. Ask the organizer to cancel the original", title, a.organizerName())
+		}
+		return fmt.Errorf("cannot cancel '%s' for everyone: it is organized by %s and you are a guest, so deleting it only removes it from your calendar (mode %s). Ask the organizer to cancel it, or decline it with edit_event", title, a.organizerName(), deleteRemoveFromMine)
+	}
+	return fmt.Errorf("cannot remove '%s' from your calendar only: you organize it, so deleting it cancels it for %s. Use mode %s to cancel it", title, describeGuests(event), deleteCancelForEveryone)
+}
+
+// deleteNote describes the effect of a delete for users who are not the organizer.
+func (a EventAccess) deleteNote(event *calendar.Event) string {
+	if a.deleteMode(event) != deleteRemoveFromMine {
+		return ""
+	}
+	if event.PrivateCopy {
+		return fmt.Sprintf("removed your private copy only; the original event organized by %s is unchanged", a.organizerName())
+	}
+	return fmt.Sprintf("removed from your calendar only; the event organized by %s is unchanged for other guests", a.organizerName())
+}
+
+// describeGuests names how many other people an organizer's delete affects.
+func describeGuests(event *calendar.Event) string {
+	guests := 0
+	for _, a := range event.Attendees {
+		if !a.Self && !a.Resource {
+			guests++
+		}
+	}
+	switch guests {
+	case 0:
+		return "everyone (it has no other guests)"
+	case 1:
+		return "its 1 guest"
+	default:
+		return fmt.Sprintf("all %d guests", guests)
+	}
+}
+
+// explainForbidden replaces a 403 from the API with a capability-specific
+// message. Other errors are returned unchanged.
+func (a EventAccess) explainForbidden(err error, action, title string) error {
+	if !isForbidden(err) {
+		return err
+	}
+	switch a.Level {
+	case accessGuest, accessGuestEditor:
+		return fmt.Errorf("cannot %s '%s': Google denied the change. You are a guest of this event organized by %s, so you can RSVP but the organizer must make other changes", action, title, a.organizerName())
+	case accessReadOnly:
+		return fmt.Errorf("cannot %s '%s': calendar %s is shared with you read-only", action, title, a.CalendarID)
+	default:
+		role := a.CalendarRole
+		if role == "" {
+			role = "unknown"
+		}
+		return fmt.Errorf("cannot %s '%s': Google denied the change (your access role on calendar %s: %s). The calendar owner must grant you \"Make changes to events\" access", action, title, a.CalendarID, role)
+	}
+}
+
+func (a EventAccess) organizerName() string {
+	if a.Organizer == "" {
+		return "someone else"
+	}
+	return a.Organizer
+}
+
+// isForbidden reports whether err is a 403 response from the API.
+func isForbidden(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
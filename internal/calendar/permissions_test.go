@@ -0,0 +1,175 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// ----- eventAccess -----
+
+func TestEventAccess(t *testing.T) {
+	mine := &calendar.Event{Organizer: &calendar.EventOrganizer{Email: "me@example.com", Self: true}}
+	theirs := &calendar.Event{Organizer: &calendar.EventOrganizer{Email: "boss@example.com"}}
+	theirsModifiable := &calendar.Event{Organizer: &calendar.EventOrganizer{Email: "boss@example.com"}, GuestsCanModify: true}
+	teamEvent := &calendar.Event{Organizer: &calendar.EventOrganizer{Email: "team@group.calendar.google.com"}}
+
+	tests := []struct {
+		name       string
+		calendarID string
+		role       string
+		event      *calendar.Event
+		want       string
+	}{
+		{"own event", "primary", "owner", mine, accessOrganizer},
+		{"no organizer", "primary", "owner", &calendar.Event{}, accessOrganizer},
+		{"invited guest", "primary", "owner", theirs, accessGuest},
+		{"guest allowed to modify", "primary", "owner", theirsModifiable, accessGuestEditor},
+		{"read-only calendar", "team@group.calendar.google.com", "reader", teamEvent, accessReadOnly},
+		{"free/busy only calendar", "team@group.calendar.google.com", "freeBusyReader", mine, accessReadOnly},
+		{"writable shared calendar", "team@group.calendar.google.com", "writer", teamEvent, accessOrganizer},
+		{"unknown role", "team@group.calendar.google.com", "", teamEvent, accessGuest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventAccess(tt.calendarID, tt.role, tt.event)
+			if got.Level != tt.want {
+				t.Errorf("Level = %q, want %q", got.Level, tt.want)
+			}
+		})
+	}
+}
+
+// ----- checkEdit / checkDelete -----
+
+func TestEventAccessChecks(t *testing.T) {
+	event := &calendar.Event{Attendees: []*calendar.EventAttendee{{Email: "me@example.com", Self: true}, {Email: "boss@example.com"}}}
+	newTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	colorID := "5"
+
+	guest := EventAccess{Level: accessGuest, CalendarID: "primary", Organizer: "boss@example.com"}
+	if err := guest.checkEdit("Standup", PatchEventParams{StartTime: &newTime}, event); err == nil || !strings.Contains(err.Error(), "can RSVP but not change the time") {
+		t.Errorf("guest reschedule = %v, want RSVP message", err)
+	}
+	rsvp := PatchEventParams{HasAttendees: true, Attendees: []AttendeeParams{{Email: "ME@example.com", ResponseStatus: "accepted"}}}
+	if err := guest.checkEdit("Standup", rsvp, event); err != nil {
+		t.Errorf("guest RSVP = %v, want nil", err)
+	}
+	if err := guest.checkEdit("Standup", PatchEventParams{ColorID: &colorID}, event); err != nil {
+		t.Errorf("guest color change = %v, want nil", err)
+	}
+	invite := PatchEventParams{HasAttendees: true, Attendees: []AttendeeParams{{Email: "new@example.com"}}}
+	if err := guest.checkEdit("Standup", invite, event); err == nil {
+		t.Error("guest adding an attendee should fail")
+	}
+	if err := guest.checkDelete("Standup"); err != nil {
+		t.Errorf("guest checkDelete = %v, want nil", err)
+	}
+	if note := guest.deleteNote(event); !strings.Contains(note, "your calendar only") {
+		t.Errorf("guest deleteNote = %q", note)
+	}
+
+	readOnly := EventAccess{Level: accessReadOnly, CalendarID: "team@group.calendar.google.com", CalendarRole: "reader"}
+	if err := readOnly.checkEdit("Offsite", PatchEventParams{ColorID: &colorID}, event); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("read-only checkEdit = %v, want read-only message", err)
+	}
+	if err := readOnly.checkDelete("Offsite"); err == nil {
+		t.Error("read-only checkDelete should fail")
+	}
+
+	for _, level := range []string{accessOrganizer, accessGuestEditor} {
+		a := EventAccess{Level: level}
+		if err := a.checkEdit("x", PatchEventParams{StartTime: &newTime}, event); err != nil {
+			t.Errorf("%s checkEdit = %v, want nil", level, err)
+		}
+	}
+	if note := (EventAccess{Level: accessOrganizer}).deleteNote(event); note != "" {
+		t.Errorf("organizer deleteNote = %q, want empty", note)
+	}
+}
+
+// ----- deleteMode / checkDeleteMode -----
+
+func TestDeleteMode(t *testing.T) {
+	event := &calendar.Event{Attendees: []*calendar.EventAttendee{
+		{Email: "me@example.com", Self: true},
+		{Email: "boss@example.com"},
+		{Email: "dana@example.com"},
+		{Email: "room@resource.calendar.google.com", Resource: true},
+	}}
+	privateCopy := &calendar.Event{PrivateCopy: true}
+	organizer := EventAccess{Level: accessOrganizer}
+	guest := EventAccess{Level: accessGuest, Organizer: "boss@example.com"}
+
+	tests := []struct {
+		name   string
+		access EventAccess
+		event  *calendar.Event
+		mode   string
+		want   string // deleteMode
+		errMsg string // substring of the checkDeleteMode error, "" for none
+	}{
+		{"organizer default", organizer, event, "", deleteCancelForEveryone, ""},
+		{"organizer cancels", organizer, event, deleteCancelForEveryone, deleteCancelForEveryone, ""},
+		{"organizer cannot only remove", organizer, event, deleteRemoveFromMine, deleteCancelForEveryone, "cancels it for all 2 guests"},
+		{"guest default", guest, event, "", deleteRemoveFromMine, ""},
+		{"guest removes", guest, event, deleteRemoveFromMine, deleteRemoveFromMine, ""},
+		{"guest cannot cancel", guest, event, deleteCancelForEveryone, deleteRemoveFromMine, "organized by boss@example.com and you are a guest"},
+		{"guest editor cannot cancel", EventAccess{Level: accessGuestEditor}, event, deleteCancelForEveryone, deleteRemoveFromMine, "you are a guest"},
+		{"private copy", organizer, privateCopy, deleteCancelForEveryone, deleteRemoveFromMine, "private copy"},
+		{"invalid mode", organizer, event, "archive", deleteCancelForEveryone, "invalid mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.access.deleteMode(tt.event); got != tt.want {
+				t.Errorf("deleteMode = %q, want %q", got, tt.want)
+			}
+			err := tt.access.checkDeleteMode(tt.mode, "Standup", tt.event)
+			if tt.errMsg == "" && err != nil {
+				t.Errorf("checkDeleteMode = %v, want nil", err)
+			}
+			if tt.errMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.errMsg)) {
+				t.Errorf("checkDeleteMode = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+
+	if note := organizer.deleteNote(privateCopy); !strings.Contains(note, "private copy only") {
+		t.Errorf("private copy deleteNote = %q", note)
+	}
+}
+
+// ----- explainForbidden -----
+
+func TestExplainForbidden(t *testing.T) {
+	forbidden := &googleapi.Error{Code: 403, Message: "Forbidden"}
+	access := EventAccess{Level: accessOrganizer, CalendarID: "shared@example.com", CalendarRole: "writer"}
+
+	err := access.explainForbidden(fmt.Errorf("wrapped: %w", forbidden), "change", "Review")
+	if err == nil || !strings.Contains(err.Error(), "access role on calendar shared@example.com: writer") {
+		t.Errorf("explainForbidden = %v", err)
+	}
+
+	other := &googleapi.Error{Code: 404, Message: "Not Found"}
+	if got := access.explainForbidden(other, "change", "Review"); got != other {
+		t.Errorf("non-403 error should pass through, got %v", got)
+	}
+}
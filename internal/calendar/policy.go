@@ -0,0 +1,167 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// allowedCalendarsEnv is a comma-separated list of the only calendar IDs tools may access
+	allowedCalendarsEnv = "GCAL_MCP_ALLOWED_CALENDARS"
+	// deniedCalendarsEnv is a comma-separated list of calendar IDs tools may never access
+	deniedCalendarsEnv = "GCAL_MCP_DENIED_CALENDARS"
+)
+
+// CalendarPolicy restricts which calendars the client may touch. An empty
+// Allowed list permits every calendar not in Denied; Denied always wins.
+// Calendar IDs are compared case-insensitively. The Client treats "primary"
+// and the user's own calendar ID as the same calendar.
+type CalendarPolicy struct {
+	Allowed []string
+	Denied  []string
+}
+
+// PolicyFromEnv builds a CalendarPolicy from GCAL_MCP_ALLOWED_CALENDARS and
+// GCAL_MCP_DENIED_CALENDARS.
+func PolicyFromEnv() CalendarPolicy {
+	return CalendarPolicy{
+		Allowed: splitCalendarList(os.Getenv(allowedCalendarsEnv)),
+		Denied:  splitCalendarList(os.Getenv(deniedCalendarsEnv)),
+	}
+}
+
+// IsEmpty reports whether the policy places no restrictions.
+func (p CalendarPolicy) IsEmpty() bool {
+	return len(p.Allowed) == 0 && len(p.Denied) == 0
+}
+
+// Check returns an error if the policy does not permit access to calendarID.
+// aliases are other IDs of the same calendar; the calendar is denied if any
+// of its IDs is, and allowed if any is.
+func (p CalendarPolicy) Check(calendarID string, aliases ...string) error {
+	if err := p.checkDenied(calendarID, aliases...); err != nil {
+		return err
+	}
+	if len(p.Allowed) == 0 {
+		return nil
+	}
+	for _, id := range append([]string{calendarID}, aliases...) {
+		if containsCalendar(p.Allowed, id) {
+			return nil
+		}
+	}
+	return fmt.Errorf("access to calendar %q is not permitted by policy (allowed: %s)", calendarID, strings.Join(p.Allowed, ", "))
+}
+
+// checkDenied returns an error only if calendarID, or one of its aliases, is
+// explicitly denied.
+func (p CalendarPolicy) checkDenied(calendarID string, aliases ...string) error {
+	for _, id := range append([]string{calendarID}, aliases...) {
+		if containsCalendar(p.Denied, id) {
+			return fmt.Errorf("access to calendar %q is blocked by policy", calendarID)
+		}
+	}
+	return nil
+}
+
+func containsCalendar(list []string, calendarID string) bool {
+	for _, id := range list {
+		if strings.EqualFold(id, calendarID) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitCalendarList(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SetPolicy restricts the calendars this client may access.
+func (c *Client) SetPolicy(policy CalendarPolicy) {
+	c.policy = policy
+}
+
+// checkCalendar enforces the client's calendar policy. Every Client method that
+// reads or writes a calendar's events or settings calls it before making an
+// API request.
+func (c *Client) checkCalendar(calendarID string) error {
+	if c.policy.IsEmpty() {
+		return nil
+	}
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.policy.checkDenied(calendarID); err != nil {
+		return err
+	}
+	aliases, err := c.primaryAliases(calendarID)
+	if err != nil {
+		return err
+	}
+	return c.policy.Check(calendarID, aliases...)
+}
+
+// checkDenied applies only the deny list of the client's calendar policy,
+// with "primary" and the user's own calendar ID treated alike.
+func (c *Client) checkDenied(calendarID string) error {
+	if len(c.policy.Denied) == 0 {
+		return nil
+	}
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.policy.checkDenied(calendarID); err != nil {
+		return err
+	}
+	aliases, err := c.primaryAliases(calendarID)
+	if err != nil {
+		return err
+	}
+	return c.policy.checkDenied(calendarID, aliases...)
+}
+
+// primaryAliases returns the other ID of the user's primary calendar when
+// calendarID is one of them: its calendar ID for "primary", and "primary" for
+// that ID. The primary calendar is only looked up when calendarID is
+// "primary" or the policy lists "primary".
+func (c *Client) primaryAliases(calendarID string) ([]string, error) {
+	isPrimary := strings.EqualFold(calendarID, "primary")
+	if !isPrimary && !containsCalendar(c.policy.Allowed, "primary") && !containsCalendar(c.policy.Denied, "primary") {
+		return nil, nil
+	}
+	primaryID, err := c.getUserEmail()
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply the calendar policy: %v", err)
+	}
+	switch {
+	case isPrimary:
+		return []string{primaryID}, nil
+	case strings.EqualFold(calendarID, primaryID):
+		return []string{"primary"}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- CalendarPolicy.Check -----
+
+func TestCalendarPolicyCheck(t *testing.T) {
+	cases := []struct {
+		name       string
+		policy     CalendarPolicy
+		calendarID string
+		wantErr    bool
+	}{
+		{"empty policy allows all", CalendarPolicy{}, "team@group.calendar.google.com", false},
+		{"allowlisted", CalendarPolicy{Allowed: []string{"primary"}}, "primary", false},
+		{"not allowlisted", CalendarPolicy{Allowed: []string{"primary"}}, "team@group.calendar.google.com", true},
+		{"allowlist case-insensitive", CalendarPolicy{Allowed: []string{"Me@Example.com"}}, "me@example.com", false},
+		{"denied", CalendarPolicy{Denied: []string{"team@group.calendar.google.com"}}, "team@group.calendar.google.com", true},
+		{"not denied", CalendarPolicy{Denied: []string{"team@group.calendar.google.com"}}, "primary", false},
+		{"deny wins over allow", CalendarPolicy{Allowed: []string{"primary"}, Denied: []string{"primary"}}, "primary", true},
+	}
+	for _, tc := range cases {
+		err := tc.policy.Check(tc.calendarID)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: Check(%q) error = %v, wantErr %v", tc.name, tc.calendarID, err, tc.wantErr)
+		}
+	}
+}
+
+// ----- PolicyFromEnv -----
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv(allowedCalendarsEnv, " primary, work@example.com ,,")
+	t.Setenv(deniedCalendarsEnv, "")
+
+	p := PolicyFromEnv()
+	if len(p.Allowed) != 2 || p.Allowed[0] != "primary" || p.Allowed[1] != "work@example.com" {
+		t.Errorf("unexpected allowed list %v", p.Allowed)
+	}
+	if len(p.Denied) != 0 {
+		t.Errorf("expected empty denied list, got %v", p.Denied)
+	}
+	if p.IsEmpty() {
+		t.Error("policy with an allowlist should not be empty")
+	}
+}
+
+// ----- Client enforcement -----
+
+func TestClientEnforcesPolicyBeforeAPICall(t *testing.T) {
+	// No services are configured, so any call that reaches the API would panic
+	c := NewClient(nil, nil)
+	c.SetPolicy(CalendarPolicy{Denied: []string{"primary"}})
+
+	if _, err := c.GetEvent("", "evt1"); err == nil {
+		t.Error("GetEvent on a denied calendar should fail")
+	}
+	if err := c.DeleteEvent("primary", "evt1", "none"); err == nil {
+		t.Error("DeleteEvent on a denied calendar should fail")
+	}
+	if _, err := c.ListEvents(ListEventsParams{}); err == nil {
+		t.Error("ListEvents on a denied calendar should fail")
+	}
+	if _, err := c.GetFreeBusy(FreeBusyParams{TimeMin: time.Now(), TimeMax: time.Now().Add(time.Hour)}); err == nil {
+		t.Error("GetFreeBusy on a denied calendar should fail")
+	}
+	if _, err := c.CalendarListEntry("primary"); err == nil {
+		t.Error("CalendarListEntry on a denied calendar should fail")
+	}
+	instance := &calendar.Event{RecurringEventId: "series", Start: &calendar.EventDateTime{DateTime: "2025-03-04T10:00:00Z"}}
+	if _, err := c.PreviousOccurrence("primary", instance); err == nil {
+		t.Error("PreviousOccurrence on a denied calendar should fail")
+	}
+	if _, err := c.changedSince("primary", time.Now()); err == nil {
+		t.Error("changedSince on a denied calendar should fail")
+	}
+}
+
+func TestClientPolicy_PrimaryAlias(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, err := store.InsertCalendar(&calendar.Calendar{Summary: "Team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := func(c *Client, calendarID string) error {
+		_, err := c.ListEvents(ListEventsParams{CalendarID: calendarID, TimeFilter: "today"})
+		return err
+	}
+
+	cases := []struct {
+		name       string
+		policy     CalendarPolicy
+		calendarID string
+		wantErr    bool
+	}{
+		{"primary denied by address", CalendarPolicy{Denied: []string{fake.DemoOwner}}, "primary", true},
+		{"default calendar denied by address", CalendarPolicy{Denied: []string{fake.DemoOwner}}, "", true},
+		{"address denied as primary", CalendarPolicy{Denied: []string{"primary"}}, fake.DemoOwner, true},
+		{"primary allowed by address", CalendarPolicy{Allowed: []string{fake.DemoOwner}}, "primary", false},
+		{"address allowed as primary", CalendarPolicy{Allowed: []string{"primary"}}, fake.DemoOwner, false},
+		{"other calendar not allowed", CalendarPolicy{Allowed: []string{fake.DemoOwner}}, team.Id, true},
+		{"other calendar not denied", CalendarPolicy{Denied: []string{"primary"}}, team.Id, false},
+	}
+	for _, tc := range cases {
+		c := NewClient(svc, drv)
+		c.SetPolicy(tc.policy)
+		if err := list(c, tc.calendarID); (err != nil) != tc.wantErr {
+			t.Errorf("%s: ListEvents(%q) error = %v, wantErr %v", tc.name, tc.calendarID, err, tc.wantErr)
+		}
+	}
+
+	// Free/busy applies the deny list to the user's address too
+	c := NewClient(svc, drv)
+	c.SetPolicy(CalendarPolicy{Denied: []string{fake.DemoOwner}})
+	if _, err := c.GetFreeBusy(FreeBusyParams{TimeMin: time.Now(), TimeMax: time.Now().Add(time.Hour)}); err == nil {
+		t.Error("GetFreeBusy on primary should fail when the user's address is denied")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// profileEnv selects the profile whose preferences are used
+	profileEnv = "GCAL_MCP_PROFILE"
+	// preferencesFileEnv overrides where preferences are stored
+	preferencesFileEnv = "GCAL_MCP_PREFERENCES_FILE"
+	// preferencesFile is the default preferences file name, stored next to token.json
+	preferencesFile = "preferences.json"
+	// defaultProfile is used when GCAL_MCP_PROFILE is unset
+	defaultProfile = "default"
+)
+
+// Preferences are settings chosen through tools that persist across sessions.
+type Preferences struct {
+	DefaultCalendar    string                `json:"default_calendar,omitempty"`
+	AttendeeGroups     map[string][]string   `json:"attendee_groups,omitempty"`      // group name -> member emails
+	DefaultSendUpdates string                `json:"default_send_updates,omitempty"` // "all", "externalOnly" or "none"
+	Goals              map[string]Goal       `json:"goals,omitempty"`                // lowercased goal name -> goal
+	WorkingHours       *WorkingHours         `json:"working_hours,omitempty"`        // see infer_working_hours
+	Constraints        map[string]Constraint `json:"constraints,omitempty"`          // lowercased constraint name -> constraint
+	TrackedEvents      []TrackedEvent        `json:"tracked_events,omitempty"`       // see track_event
+}
+
+// PreferenceStore holds the preferences of every profile in one JSON file,
+// keyed by profile name. Only the active profile's preferences are read or
+// changed; the others are preserved on save. A store without a path keeps
+// preferences in memory only.
+type PreferenceStore struct {
+	mu       sync.Mutex
+	path     string
+	profile  string
+	profiles map[string]Preferences
+}
+
+// ProfileFromEnv returns the profile named by GCAL_MCP_PROFILE, or "default".
+func ProfileFromEnv() string {
+	if profile := os.Getenv(profileEnv); profile != "" {
+		return profile
+	}
+	return defaultProfile
+}
+
+// PreferencesPath returns GCAL_MCP_PREFERENCES_FILE, or preferences.json in dir.
+func PreferencesPath(dir string) string {
+	if path := os.Getenv(preferencesFileEnv); path != "" {
+		return path
+	}
+	return filepath.Join(dir, preferencesFile)
+}
+
+// LoadPreferenceStore reads the preferences file at path for profile. A
+// missing file is not an error. An empty path creates an in-memory store.
+func LoadPreferenceStore(path, profile string) (*PreferenceStore, error) {
+	store := &PreferenceStore{path: path, profile: profile, profiles: make(map[string]Preferences)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read preferences: %v", err)
+	}
+	if err := json.Unmarshal(data, &store.profiles); err != nil {
+		return nil, fmt.Errorf("unable to parse preferences file %s: %v", path, err)
+	}
+	return store, nil
+}
+
+// Profile returns the name of the active profile.
+func (s *PreferenceStore) Profile() string {
+	return s.profile
+}
+
+// Get returns the active profile's preferences.
+func (s *PreferenceStore) Get() Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.profiles[s.profile]
+}
+
+// Update changes the active profile's preferences and saves the file.
+func (s *PreferenceStore) Update(change func(*Preferences)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefs := s.profiles[s.profile]
+	change(&prefs)
+	s.profiles[s.profile] = prefs
+
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode preferences: %v", err)
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("unable to save preferences: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ----- PreferenceStore -----
+
+func TestPreferenceStorePersistsPerProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+
+	work, err := LoadPreferenceStore(path, "work")
+	if err != nil {
+		t.Fatalf("LoadPreferenceStore: %v", err)
+	}
+	if got := work.Get().DefaultCalendar; got != "" {
+		t.Errorf("fresh store default = %q, want empty", got)
+	}
+	if err := work.Update(func(p *Preferences) { p.DefaultCalendar = "team@group.calendar.google.com" }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	home, _ := LoadPreferenceStore(path, "home")
+	if err := home.Update(func(p *Preferences) { p.DefaultCalendar = "family@group.calendar.google.com" }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// Saving one profile keeps the other
+	reloaded, err := LoadPreferenceStore(path, "work")
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := reloaded.Get().DefaultCalendar; got != "team@group.calendar.google.com" {
+		t.Errorf("work default = %q after reload", got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("preferences file mode = %o, want 600", perm)
+	}
+}
+
+func TestPreferenceStoreInMemory(t *testing.T) {
+	store, err := LoadPreferenceStore("", "default")
+	if err != nil {
+		t.Fatalf("LoadPreferenceStore: %v", err)
+	}
+	if err := store.Update(func(p *Preferences) { p.DefaultCalendar = "x" }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := store.Get().DefaultCalendar; got != "x" {
+		t.Errorf("default = %q, want x", got)
+	}
+}
+
+func TestLoadPreferenceStoreInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPreferenceStore(path, "default"); err == nil {
+		t.Error("expected an error for a corrupt preferences file")
+	}
+}
+
+// ----- PreferencesPath -----
+
+func TestPreferencesPath(t *testing.T) {
+	t.Setenv(preferencesFileEnv, "")
+	if got := PreferencesPath("/repo"); got != filepath.Join("/repo", "preferences.json") {
+		t.Errorf("PreferencesPath = %q", got)
+	}
+	t.Setenv(preferencesFileEnv, "/etc/gcal/prefs.json")
+	if got := PreferencesPath("/repo"); got != "/etc/gcal/prefs.json" {
+		t.Errorf("PreferencesPath with override = %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// priorityKey is the private extended property holding an event's
+	// priority, set through create_event and edit_event
+	priorityKey = "priority"
+
+	priorityHigh   = "high"
+	priorityNormal = "normal"
+	priorityLow    = "low"
+)
+
+// eventPriorityLevel returns the priority stored on e, or "" when none is set.
+func eventPriorityLevel(e *calendar.Event) string {
+	if e == nil || e.ExtendedProperties == nil {
+		return ""
+	}
+	return e.ExtendedProperties.Private[priorityKey]
+}
+
+// validatePriority checks a priority argument; "" is allowed and means none
+// (edit_event uses it to clear the priority).
+func validatePriority(priority string) error {
+	switch priority {
+	case "", priorityHigh, priorityNormal, priorityLow:
+		return nil
+	}
+	return fmt.Errorf("invalid priority %q: must be 'high', 'normal' or 'low'", priority)
+}
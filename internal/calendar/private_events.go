@@ -0,0 +1,48 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import "google.golang.org/api/calendar/v3"
+
+// privateBusyTitle is shown for private events whose details are hidden
+const privateBusyTitle = "Private — busy"
+
+// isHiddenPrivate reports whether e is a private event on someone else's
+// calendar. Google returns such events to readers with only their times, so
+// they have no title, description, location or attendees.
+func isHiddenPrivate(e *calendar.Event) bool {
+	if e.Visibility != "private" && e.Visibility != "confidential" {
+		return false
+	}
+	if e.Organizer != nil && e.Organizer.Self {
+		return false
+	}
+	return e.Summary == "" && e.Description == "" && e.Location == "" && len(e.Attendees) == 0
+}
+
+// eventTitle returns the title to show for e: "Private — busy" for a private
+// event whose details are hidden, otherwise its summary or "(No Title)".
+func eventTitle(e *calendar.Event) string {
+	switch {
+	case isHiddenPrivate(e):
+		return privateBusyTitle
+	case e.Summary == "":
+		return "(No Title)"
+	default:
+		return e.Summary
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- isHiddenPrivate / eventTitle -----
+
+func TestEventTitle(t *testing.T) {
+	hidden := func(visibility string) *calendar.Event {
+		return &calendar.Event{Id: "p1", Visibility: visibility, Start: &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"}}
+	}
+	ownPrivate := hidden("private")
+	ownPrivate.Organizer = &calendar.EventOrganizer{Email: "me@example.com", Self: true}
+	visiblePrivate := hidden("private")
+	visiblePrivate.Summary = "Doctor"
+	privateWithGuests := hidden("private")
+	privateWithGuests.Attendees = []*calendar.EventAttendee{{Email: "kim@example.com"}}
+
+	tests := []struct {
+		name       string
+		event      *calendar.Event
+		wantHidden bool
+		wantTitle  string
+	}{
+		{"private on a shared calendar", hidden("private"), true, privateBusyTitle},
+		{"confidential on a shared calendar", hidden("confidential"), true, privateBusyTitle},
+		{"untitled default visibility", hidden(""), false, "(No Title)"},
+		{"own untitled private event", ownPrivate, false, "(No Title)"},
+		{"private with details shown", visiblePrivate, false, "Doctor"},
+		{"private with attendees", privateWithGuests, false, "(No Title)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isHiddenPrivate(tt.event); got != tt.wantHidden {
+				t.Errorf("isHiddenPrivate() = %v, want %v", got, tt.wantHidden)
+			}
+			if got := eventTitle(tt.event); got != tt.wantTitle {
+				t.Errorf("eventTitle() = %q, want %q", got, tt.wantTitle)
+			}
+		})
+	}
+}
@@ -0,0 +1,502 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+const (
+	// maxRecurringSlotWeeks bounds how many weeks find_recurring_slot checks
+	maxRecurringSlotWeeks = 26
+	// freeBusyChunkDays is the span of each free/busy query; long ranges are
+	// split so no single query exceeds what the API accepts
+	freeBusyChunkDays = 28
+	// optionalConflictWeight is the share of a week's score lost when every
+	// optional attendee is busy; a required attendee's conflict loses all of it
+	optionalConflictWeight = 0.5
+	// maxExplainedBlocks caps the busy blocks listed per attendee when no
+	// slot is free every week
+	maxExplainedBlocks = 5
+)
+
+// RecurringSlotParams configures the weekly slot search.
+type RecurringSlotParams struct {
+	FirstDay        time.Time     // local midnight of the first day considered
+	Weeks           int           // number of weekly occurrences that must fit
+	Duration        time.Duration // meeting length
+	WorkStart       time.Duration // offset of the working day's start from midnight
+	WorkEnd         time.Duration // offset of the working day's end from midnight
+	IncludeWeekends bool
+	Optional        map[string]bool // participants whose conflicts lower a slot's score instead of ruling out the week
+}
+
+// RecurringSlot is a weekly time and how well it fits over the weeks checked.
+type RecurringSlot struct {
+	Weekday   string         `json:"weekday"`
+	Start     string         `json:"start"` // local wall-clock time, HH:MM
+	End       string         `json:"end"`
+	FreeWeeks int            `json:"free_weeks"` // weeks in which every required participant is free
+	Score     float64        `json:"score"`      // 1 when everyone is free every week
+	Conflicts []SlotConflict `json:"conflicts,omitempty"`
+
+	day    int           // days after FirstDay of the first occurrence
+	offset time.Duration // start offset from local midnight
+}
+
+// SlotConflict is one week in which a recurring slot is not free for everyone.
+type SlotConflict struct {
+	Date         string   `json:"date"`
+	Busy         []string `json:"busy"` // required participants
+	OptionalBusy []string `json:"optional_busy,omitempty"`
+}
+
+// SlotExplanation explains why no weekly slot is free every week: which
+// required participants ruled out the candidate slots, and with which busy
+// blocks.
+type SlotExplanation struct {
+	CandidateSlots int           `json:"candidate_slots"`
+	BestFreeWeeks  int           `json:"best_free_weeks"` // most weeks any slot is free for every required participant
+	Attendees      []SlotBlocker `json:"attendees"`
+}
+
+// SlotBlocker is one required participant's part in ruling out slots.
+type SlotBlocker struct {
+	Attendee     string      `json:"attendee"`
+	BlockedSlots int         `json:"blocked_slots"`      // slots they are busy for in at least one week
+	OnlyBlocker  int         `json:"only_blocker_slots"` // slots that would be free every week without them
+	BusyBlocks   []BusyBlock `json:"busy_blocks"`        // most slot occurrences first, at most maxExplainedBlocks
+}
+
+// BusyBlock is a busy period and how many slot occurrences it overlaps.
+type BusyBlock struct {
+	Start       string `json:"start"` // RFC3339 in the search time zone
+	End         string `json:"end"`
+	Occurrences int    `json:"occurrences"`
+
+	start, end time.Time
+}
+
+// findRecurringSlots returns every weekly slot within working hours, ordered
+// by score (best first), then by time in the week. busy maps each participant
+// to their busy periods. A week in which a required participant is busy scores
+// 0; otherwise it scores 1, less optionalConflictWeight times the share of
+// optional participants who are busy, so optional conflicts rank a slot lower
+// without ruling it out. Start times step by availabilityStep and are
+// evaluated as local wall-clock times, so a slot stays at the same local time
+// across a daylight saving change.
+func findRecurringSlots(busy map[string][]TimeSlot, params RecurringSlotParams) []RecurringSlot {
+	var participants []string
+	optionalCount := 0
+	for p := range busy {
+		participants = append(participants, p)
+		if params.Optional[p] {
+			optionalCount++
+		}
+	}
+	sort.Strings(participants)
+
+	var slots []RecurringSlot
+	for day := 0; day < 7; day++ {
+		first := params.FirstDay.AddDate(0, 0, day)
+		if !params.IncludeWeekends && (first.Weekday() == time.Saturday || first.Weekday() == time.Sunday) {
+			continue
+		}
+		for offset := params.WorkStart; offset+params.Duration <= params.WorkEnd; offset += availabilityStep {
+			slot := RecurringSlot{
+				Weekday: first.Weekday().String(),
+				Start:   formatClock(offset),
+				End:     formatClock(offset + params.Duration),
+				day:     day,
+				offset:  offset,
+			}
+			score := 0.0
+			for week := 0; week < params.Weeks; week++ {
+				date := first.AddDate(0, 0, 7*week)
+				start := atClock(date, offset)
+				end := start.Add(params.Duration)
+
+				conflict := SlotConflict{Date: date.Format("2006-01-02"), Busy: []string{}}
+				for _, p := range participants {
+					if !overlapsAny(busy[p], start, end) {
+						continue
+					}
+					if params.Optional[p] {
+						conflict.OptionalBusy = append(conflict.OptionalBusy, p)
+					} else {
+						conflict.Busy = append(conflict.Busy, p)
+					}
+				}
+				if len(conflict.Busy) == 0 {
+					slot.FreeWeeks++
+					score++
+					if len(conflict.OptionalBusy) > 0 {
+						score -= optionalConflictWeight * float64(len(conflict.OptionalBusy)) / float64(optionalCount)
+					}
+				}
+				if len(conflict.Busy) > 0 || len(conflict.OptionalBusy) > 0 {
+					slot.Conflicts = append(slot.Conflicts, conflict)
+				}
+			}
+			if params.Weeks > 0 {
+				slot.Score = roundTo(score/float64(params.Weeks), 2)
+			}
+			slots = append(slots, slot)
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].Score != slots[j].Score {
+			return slots[i].Score > slots[j].Score
+		}
+		if slots[i].day != slots[j].day {
+			return weekOrder(params.FirstDay, slots[i].day) < weekOrder(params.FirstDay, slots[j].day)
+		}
+		return slots[i].offset < slots[j].offset
+	})
+	return slots
+}
+
+// explainNoRecurringSlot explains, per required participant, why none of
+// slots (every candidate, as returned by findRecurringSlots) is free every
+// week. It returns nil when some slot is, or when there are no candidates.
+// Participants are ordered by how many slots only they block, so the first
+// one is the best to make optional.
+func explainNoRecurringSlot(busy map[string][]TimeSlot, params RecurringSlotParams, slots []RecurringSlot) *SlotExplanation {
+	if len(slots) == 0 {
+		return nil
+	}
+	explanation := &SlotExplanation{CandidateSlots: len(slots), Attendees: []SlotBlocker{}}
+	for _, slot := range slots {
+		if slot.FreeWeeks == params.Weeks {
+			return nil
+		}
+		if slot.FreeWeeks > explanation.BestFreeWeeks {
+			explanation.BestFreeWeeks = slot.FreeWeeks
+		}
+	}
+
+	blockers := make(map[string]*SlotBlocker)
+	blocks := make(map[string]map[int]int) // participant -> index in busy -> occurrences
+	for _, slot := range slots {
+		// The required participants busy in some week; when there is only
+		// one, the slot would be free every week without them
+		blocking := make(map[string]bool)
+		for _, c := range slot.Conflicts {
+			for _, p := range c.Busy {
+				blocking[p] = true
+			}
+		}
+		for p := range blocking {
+			if blockers[p] == nil {
+				blockers[p] = &SlotBlocker{Attendee: p, BusyBlocks: []BusyBlock{}}
+				blocks[p] = make(map[int]int)
+			}
+			blockers[p].BlockedSlots++
+			if len(blocking) == 1 {
+				blockers[p].OnlyBlocker++
+			}
+			for week := 0; week < params.Weeks; week++ {
+				start := atClock(params.FirstDay.AddDate(0, 0, slot.day+7*week), slot.offset)
+				end := start.Add(params.Duration)
+				for i, b := range busy[p] {
+					if b.Start.Before(end) && b.End.After(start) {
+						blocks[p][i]++
+					}
+				}
+			}
+		}
+	}
+
+	loc := params.FirstDay.Location()
+	for p, blocker := range blockers {
+		for i, n := range blocks[p] {
+			b := busy[p][i]
+			blocker.BusyBlocks = append(blocker.BusyBlocks, BusyBlock{
+				Start:       b.Start.In(loc).Format(time.RFC3339),
+				End:         b.End.In(loc).Format(time.RFC3339),
+				Occurrences: n,
+				start:       b.Start.In(loc),
+				end:         b.End.In(loc),
+			})
+		}
+		sort.Slice(blocker.BusyBlocks, func(i, j int) bool {
+			bi, bj := blocker.BusyBlocks[i], blocker.BusyBlocks[j]
+			if bi.Occurrences != bj.Occurrences {
+				return bi.Occurrences > bj.Occurrences
+			}
+			return bi.start.Before(bj.start)
+		})
+		if len(blocker.BusyBlocks) > maxExplainedBlocks {
+			blocker.BusyBlocks = blocker.BusyBlocks[:maxExplainedBlocks]
+		}
+		explanation.Attendees = append(explanation.Attendees, *blocker)
+	}
+	sort.Slice(explanation.Attendees, func(i, j int) bool {
+		ai, aj := explanation.Attendees[i], explanation.Attendees[j]
+		if ai.OnlyBlocker != aj.OnlyBlocker {
+			return ai.OnlyBlocker > aj.OnlyBlocker
+		}
+		if ai.BlockedSlots != aj.BlockedSlots {
+			return ai.BlockedSlots > aj.BlockedSlots
+		}
+		return ai.Attendee < aj.Attendee
+	})
+	return explanation
+}
+
+// formatBusyBlock renders a busy block as local times, e.g. "Mon 2025-03-10 09:00–09:30".
+func formatBusyBlock(b BusyBlock) string {
+	if b.start.Format("2006-01-02") == b.end.Format("2006-01-02") {
+		return fmt.Sprintf("%s–%s", b.start.Format("Mon 2006-01-02 15:04"), b.end.Format("15:04"))
+	}
+	return fmt.Sprintf("%s–%s", b.start.Format("Mon 2006-01-02 15:04"), b.end.Format("Mon 2006-01-02 15:04"))
+}
+
+// weekOrder ranks a day offset from firstDay by weekday, Monday first, so
+// results read in calendar-week order whatever day the search starts on.
+func weekOrder(firstDay time.Time, day int) int {
+	return (int(firstDay.AddDate(0, 0, day).Weekday()) + 6) % 7
+}
+
+func overlapsAny(busy []TimeSlot, start, end time.Time) bool {
+	for _, b := range busy {
+		if b.Start.Before(end) && b.End.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatClock formats an offset from midnight as HH:MM.
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
+
+// formatRecurringSlots renders the best slots, followed by the structured data.
+func formatRecurringSlots(slots []RecurringSlot, weeks int, loc *time.Location, unavailable []string, explanation *SlotExplanation) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🔁 Weekly slots over the next %d weeks (%s):\n\n", weeks, loc.String())
+	if len(slots) == 0 {
+		result.WriteString("• No slot of that length fits in working hours\n")
+	}
+	for _, s := range slots {
+		if len(s.Conflicts) == 0 {
+			fmt.Fprintf(&result, "• %ss %s–%s — free for everyone every week\n", s.Weekday, s.Start, s.End)
+			continue
+		}
+		var dates []string
+		for _, c := range s.Conflicts {
+			busy := c.Busy
+			if len(c.OptionalBusy) > 0 {
+				busy = append(append([]string{}, busy...), "optional: "+strings.Join(c.OptionalBusy, ", "))
+			}
+			dates = append(dates, fmt.Sprintf("%s (%s)", c.Date, strings.Join(busy, ", ")))
+		}
+		if s.FreeWeeks == weeks {
+			fmt.Fprintf(&result, "• %ss %s–%s — free for required attendees every week (score %.2f); optional conflicts: %s\n", s.Weekday, s.Start, s.End, s.Score, strings.Join(dates, "; "))
+			continue
+		}
+		fmt.Fprintf(&result, "• %ss %s–%s — free %d of %d weeks (score %.2f); conflicts: %s\n", s.Weekday, s.Start, s.End, s.FreeWeeks, weeks, s.Score, strings.Join(dates, "; "))
+	}
+	if len(slots) > 0 && slots[0].FreeWeeks < weeks {
+		result.WriteString("\nNo slot is free every week; the closest options are listed. Consider skipping the conflicting weeks or making those attendees optional.\n")
+	}
+	if explanation != nil {
+		fmt.Fprintf(&result, "\n🔍 Why no slot is free every week (%d candidate slots; the best is free %d of %d weeks):\n", explanation.CandidateSlots, explanation.BestFreeWeeks, weeks)
+		for _, a := range explanation.Attendees {
+			fmt.Fprintf(&result, "• %s: busy in %d of %d slots", a.Attendee, a.BlockedSlots, explanation.CandidateSlots)
+			if a.OnlyBlocker > 0 {
+				fmt.Fprintf(&result, "; %d would be free every week without them", a.OnlyBlocker)
+			}
+			var busy []string
+			for _, b := range a.BusyBlocks {
+				busy = append(busy, formatBusyBlock(b))
+			}
+			if len(busy) > 0 {
+				fmt.Fprintf(&result, "; e.g. %s", strings.Join(busy, ", "))
+			}
+			result.WriteString("\n")
+		}
+		if len(explanation.Attendees) > 0 && explanation.Attendees[0].OnlyBlocker > 0 {
+			first := explanation.Attendees[0]
+			fmt.Fprintf(&result, "Making %s optional would leave %d slot(s) free every week.\n", first.Attendee, first.OnlyBlocker)
+		} else {
+			result.WriteString("Every slot is blocked by more than one attendee; try fewer weeks, longer working hours or a shorter meeting.\n")
+		}
+		explanationJSON, _ := json.MarshalIndent(explanation, "", "  ")
+		fmt.Fprintf(&result, "\n%s\n", string(explanationJSON))
+	}
+	if len(unavailable) > 0 {
+		fmt.Fprintf(&result, "\n⚠️ Free/busy not visible (ignored): %s\n", strings.Join(unavailable, "; "))
+	}
+
+	slotsJSON, _ := json.MarshalIndent(slots, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(slotsJSON))
+	return result.String()
+}
+
+// recurringBusy queries free/busy over [timeMin, timeMax) in chunks and
+// collects busy periods per calendar. Calendars whose free/busy is not visible
+// are returned separately with the reason. Members of a Google Group marked in
+// optional are marked optional too, unless they are listed themselves.
+func (ct *CalendarTools) recurringBusy(calendarIDs []string, optional map[string]bool, timeMin, timeMax time.Time, timezone string) (map[string][]TimeSlot, []string, error) {
+	listed := make(map[string]bool, len(calendarIDs))
+	for _, id := range calendarIDs {
+		listed[id] = true
+	}
+	busy := make(map[string][]TimeSlot)
+	unavailable := make(map[string]string)
+
+	for chunkStart := timeMin; chunkStart.Before(timeMax); chunkStart = chunkStart.AddDate(0, 0, freeBusyChunkDays) {
+		chunkEnd := chunkStart.AddDate(0, 0, freeBusyChunkDays)
+		if chunkEnd.After(timeMax) {
+			chunkEnd = timeMax
+		}
+		response, err := ct.client.GetFreeBusy(FreeBusyParams{
+			TimeMin:           chunkStart,
+			TimeMax:           chunkEnd,
+			TimeZone:          timezone,
+			CalendarIDs:       calendarIDs,
+			GroupExpansionMax: maxGroupExpansion,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get free/busy information: %v", err)
+		}
+		// Group members appear as calendars of their own, so a busy member
+		// blocks the slot like any other attendee
+		for id, cal := range response.Calendars {
+			if len(cal.Errors) > 0 {
+				if _, isGroup := response.Groups[id]; !isGroup {
+					unavailable[id] = cal.Errors[0].Reason
+				}
+				continue
+			}
+			busy[id] = append(busy[id], busySlots(cal)...)
+		}
+		for id, group := range response.Groups {
+			if len(group.Errors) > 0 {
+				unavailable[id] = describeGroupError(group.Errors[0], maxGroupExpansion)
+			}
+			if optional[id] {
+				for _, member := range group.Calendars {
+					if !listed[member] {
+						optional[member] = true
+					}
+				}
+			}
+		}
+	}
+
+	var notes []string
+	for id, reason := range unavailable {
+		delete(busy, id)
+		notes = append(notes, fmt.Sprintf("%s (%s)", id, reason))
+	}
+	sort.Strings(notes)
+	return busy, notes, nil
+}
+
+func (ct *CalendarTools) handleFindRecurringSlot(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	values, _ := arguments["attendees"].([]interface{})
+	attendees, err := parseAttendees(values, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(attendees) == 0 {
+		return nil, fmt.Errorf("attendees is required")
+	}
+	var calendarIDs []string
+	optional := make(map[string]bool)
+	for _, a := range attendees {
+		calendarIDs = append(calendarIDs, a.Email)
+		if a.Optional {
+			optional[a.Email] = true
+		}
+	}
+	if getBoolOrDefault(arguments, "include_self", true) {
+		calendarIDs = append(calendarIDs, ct.calendarID(arguments))
+	}
+	if err := ct.fetchLimits.checkCalendars(calendarIDs); err != nil {
+		return nil, err
+	}
+
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	weeks := getIntOrDefault(arguments, "weeks", 8)
+	if weeks < 1 || weeks > maxRecurringSlotWeeks {
+		return nil, fmt.Errorf("weeks must be between 1 and %d", maxRecurringSlotWeeks)
+	}
+	duration := time.Duration(getIntOrDefault(arguments, "duration_minutes", 30)) * time.Minute
+	if duration < availabilityStep/2 {
+		return nil, fmt.Errorf("duration_minutes must be at least %d", int(availabilityStep/2/time.Minute))
+	}
+	maxResults := getIntOrDefault(arguments, "max_results", 5)
+	if maxResults < 1 {
+		return nil, fmt.Errorf("max_results must be at least 1")
+	}
+
+	// Start tomorrow so every occurrence, including the first, is in the future
+	now := time.Now().In(loc)
+	params := RecurringSlotParams{
+		FirstDay:        time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc),
+		Weeks:           weeks,
+		Duration:        duration,
+		IncludeWeekends: getBoolOrDefault(arguments, "include_weekends", false),
+		Optional:        optional,
+	}
+	if params.WorkStart, params.WorkEnd, err = ct.workHours(arguments); err != nil {
+		return nil, err
+	}
+
+	busy, unavailable, err := ct.recurringBusy(calendarIDs, optional, params.FirstDay, params.FirstDay.AddDate(0, 0, 7*weeks), timezone)
+	if err != nil {
+		return nil, err
+	}
+	if self := ct.calendarID(arguments); getBoolOrDefault(arguments, "include_self", true) {
+		if _, ok := busy[self]; ok {
+			protected, err := ct.protectedBusy(self, params.FirstDay, params.FirstDay.AddDate(0, 0, 7*weeks))
+			if err != nil {
+				return nil, err
+			}
+			busy[self] = append(busy[self], protected...)
+		}
+	}
+
+	slots := findRecurringSlots(busy, params)
+	explanation := explainNoRecurringSlot(busy, params, slots)
+	if len(slots) > maxResults {
+		slots = slots[:maxResults]
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatRecurringSlots(slots, weeks, loc, unavailable, explanation),
+		}},
+	}, nil
+}
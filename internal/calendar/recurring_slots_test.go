@@ -0,0 +1,248 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func recurringParams(t *testing.T) RecurringSlotParams {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	return RecurringSlotParams{
+		FirstDay:  time.Date(2025, 3, 5, 0, 0, 0, 0, loc), // a Wednesday; DST starts Sunday March 9
+		Weeks:     3,
+		Duration:  30 * time.Minute,
+		WorkStart: 9 * time.Hour,
+		WorkEnd:   10 * time.Hour,
+	}
+}
+
+// ----- findRecurringSlots -----
+
+func TestFindRecurringSlots(t *testing.T) {
+	params := recurringParams(t)
+	loc := params.FirstDay.Location()
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, loc)
+	}
+
+	busy := map[string][]TimeSlot{
+		// Alice is busy every Monday 9:00-9:30 and on one Wednesday at 9:30
+		"alice@example.com": {
+			{Start: at(3, 10, 9, 0), End: at(3, 10, 9, 30)},
+			{Start: at(3, 17, 9, 0), End: at(3, 17, 9, 30)},
+			{Start: at(3, 24, 9, 0), End: at(3, 24, 9, 30)},
+			{Start: at(3, 12, 9, 30), End: at(3, 12, 10, 0)},
+		},
+		// Bob is busy on all Fridays, Tuesdays and Thursdays in the window
+		"bob@example.com": {
+			{Start: at(3, 6, 0, 0), End: at(3, 8, 0, 0)},
+			{Start: at(3, 11, 0, 0), End: at(3, 12, 0, 0)},
+			{Start: at(3, 13, 0, 0), End: at(3, 15, 0, 0)},
+			{Start: at(3, 18, 0, 0), End: at(3, 19, 0, 0)},
+			{Start: at(3, 20, 0, 0), End: at(3, 22, 0, 0)},
+		},
+	}
+
+	slots := findRecurringSlots(busy, params)
+	if len(slots) != 10 { // 5 weekdays x 2 half-hour starts
+		t.Fatalf("got %d slots, want 10", len(slots))
+	}
+
+	// Fully free slots come first, Monday first within the week
+	var free []string
+	for _, s := range slots {
+		if s.FreeWeeks == params.Weeks {
+			free = append(free, s.Weekday+" "+s.Start)
+		}
+	}
+	want := []string{"Monday 09:30", "Wednesday 09:00"}
+	if strings.Join(free, ",") != strings.Join(want, ",") {
+		t.Errorf("fully free slots = %v, want %v", free, want)
+	}
+
+	// Wednesday 9:30 conflicts in one week only, ahead of slots blocked every week
+	third := slots[2]
+	if third.Weekday != "Wednesday" || third.Start != "09:30" || third.FreeWeeks != 2 {
+		t.Fatalf("third slot = %+v, want Wednesday 09:30 free 2 weeks", third)
+	}
+	if len(third.Conflicts) != 1 || third.Conflicts[0].Date != "2025-03-12" || third.Conflicts[0].Busy[0] != "alice@example.com" {
+		t.Errorf("conflicts = %+v", third.Conflicts)
+	}
+}
+
+func TestFindRecurringSlotsKeepsLocalTimeAcrossDST(t *testing.T) {
+	params := recurringParams(t)
+	params.Weeks = 2
+	loc := params.FirstDay.Location()
+
+	// 14:00 UTC is 9:00 EST before the change but 10:00 EDT after it, so a
+	// block at 13:00 UTC on the second Wednesday is 9:00 local
+	busy := map[string][]TimeSlot{
+		"alice@example.com": {{
+			Start: time.Date(2025, 3, 12, 13, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, 3, 12, 13, 30, 0, 0, time.UTC),
+		}},
+	}
+	for _, s := range findRecurringSlots(busy, params) {
+		if s.Weekday == "Wednesday" && s.Start == "09:00" {
+			if s.FreeWeeks != 1 {
+				t.Errorf("Wednesday 09:00 free %d weeks, want 1 (the block is 9:00 EDT)", s.FreeWeeks)
+			}
+			if got := atClock(params.FirstDay.AddDate(0, 0, 7), 9*time.Hour); got.In(loc).Hour() != 9 {
+				t.Errorf("atClock after DST = %v", got)
+			}
+			return
+		}
+	}
+	t.Fatal("Wednesday 09:00 slot missing")
+}
+
+func TestFindRecurringSlotsOptionalAttendees(t *testing.T) {
+	params := recurringParams(t)
+	params.Weeks = 2
+	params.Optional = map[string]bool{"carol@example.com": true, "dave@example.com": true}
+	loc := params.FirstDay.Location()
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 3, day, hour, minute, 0, 0, loc)
+	}
+
+	// Everyone but optional Carol is free on Wednesdays at 9:00; required
+	// Alice is busy on the first Thursday at 9:00
+	busy := map[string][]TimeSlot{
+		"alice@example.com": {{Start: at(6, 9, 0), End: at(6, 9, 30)}},
+		"carol@example.com": {{Start: at(5, 9, 0), End: at(5, 9, 30)}, {Start: at(12, 9, 0), End: at(12, 9, 30)}},
+		"dave@example.com":  {},
+	}
+	slots := findRecurringSlots(busy, params)
+	byTime := make(map[string]RecurringSlot)
+	for _, s := range slots {
+		byTime[s.Weekday+" "+s.Start] = s
+	}
+
+	wednesday := byTime["Wednesday 09:00"]
+	if wednesday.FreeWeeks != 2 || wednesday.Score != 0.75 {
+		t.Errorf("Wednesday 09:00 = %+v, want free both weeks with score 0.75", wednesday)
+	}
+	if len(wednesday.Conflicts) != 2 || len(wednesday.Conflicts[0].Busy) != 0 || wednesday.Conflicts[0].OptionalBusy[0] != "carol@example.com" {
+		t.Errorf("Wednesday 09:00 conflicts = %+v, want Carol as an optional conflict", wednesday.Conflicts)
+	}
+	if thursday := byTime["Thursday 09:00"]; thursday.FreeWeeks != 1 || thursday.Score != 0.5 {
+		t.Errorf("Thursday 09:00 = %+v, want free one week with score 0.5", thursday)
+	}
+
+	// Fully free slots rank first, then optional conflicts, then required ones
+	if slots[0].Score != 1 || slots[len(slots)-1].Score != 0.5 {
+		t.Errorf("slots not ordered by score: first %+v, last %+v", slots[0], slots[len(slots)-1])
+	}
+}
+
+func TestFindRecurringSlotsTooLong(t *testing.T) {
+	params := recurringParams(t)
+	params.Duration = 2 * time.Hour
+	if slots := findRecurringSlots(nil, params); len(slots) != 0 {
+		t.Errorf("got %d slots for a meeting longer than the working day, want 0", len(slots))
+	}
+}
+
+// ----- explainNoRecurringSlot -----
+
+func TestExplainNoRecurringSlot(t *testing.T) {
+	params := recurringParams(t)
+	params.WorkStart = 9 * time.Hour
+	params.WorkEnd = 9*time.Hour + 30*time.Minute // one slot per weekday
+	loc := params.FirstDay.Location()
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 3, day, hour, 0, 0, 0, loc)
+	}
+
+	// Bob is busy for the whole search; Alice has one 9:00 meeting on a
+	// Wednesday, a Monday and a Thursday, so only Tuesdays and Fridays are
+	// blocked by Bob alone. Carol is always free and blocks nothing.
+	busy := map[string][]TimeSlot{
+		"alice@example.com": {
+			{Start: at(5, 9), End: at(5, 10)}, {Start: at(10, 9), End: at(10, 10)},
+			{Start: at(13, 9), End: at(13, 10)},
+		},
+		"bob@example.com": {
+			{Start: at(4, 0), End: at(27, 0)}, // the whole search
+		},
+		"carol@example.com": {},
+	}
+	if got := explainNoRecurringSlot(busy, params, findRecurringSlots(map[string][]TimeSlot{"carol@example.com": {}}, params)); got != nil {
+		t.Errorf("explanation with a free slot = %+v, want nil", got)
+	}
+
+	got := explainNoRecurringSlot(busy, params, findRecurringSlots(busy, params))
+	if got == nil {
+		t.Fatal("no explanation although no slot is free every week")
+	}
+	if got.CandidateSlots != 5 || got.BestFreeWeeks != 0 {
+		t.Errorf("candidates %d, best %d weeks; want 5 and 0", got.CandidateSlots, got.BestFreeWeeks)
+	}
+	if len(got.Attendees) != 2 {
+		t.Fatalf("attendees = %+v, want Alice and Bob only", got.Attendees)
+	}
+	bob, alice := got.Attendees[0], got.Attendees[1]
+	if bob.Attendee != "bob@example.com" || bob.BlockedSlots != 5 || bob.OnlyBlocker != 2 {
+		t.Errorf("first blocker = %+v, want Bob blocking 5 slots, 2 only by him", bob)
+	}
+	if len(bob.BusyBlocks) != 1 || bob.BusyBlocks[0].Occurrences != 15 {
+		t.Errorf("Bob's blocks = %+v, want one block over all 15 occurrences", bob.BusyBlocks)
+	}
+	if alice.Attendee != "alice@example.com" || alice.BlockedSlots != 3 || alice.OnlyBlocker != 0 {
+		t.Errorf("second blocker = %+v, want Alice blocking 3 slots, none alone", alice)
+	}
+	if len(alice.BusyBlocks) != 3 || alice.BusyBlocks[0].Start != "2025-03-05T09:00:00-05:00" {
+		t.Errorf("Alice's blocks = %+v, want 3 starting with Wednesday March 5", alice.BusyBlocks)
+	}
+
+	out := formatRecurringSlots(nil, params.Weeks, loc, nil, got)
+	for _, want := range []string{"Why no slot is free every week (5 candidate slots; the best is free 0 of 3 weeks)", "• bob@example.com: busy in 5 of 5 slots; 2 would be free every week without them", "Wed 2025-03-05 09:00–10:00", "Making bob@example.com optional would leave 2 slot(s) free every week", `"only_blocker_slots": 2`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// ----- formatRecurringSlots -----
+
+func TestFormatRecurringSlots(t *testing.T) {
+	slots := []RecurringSlot{
+		{Weekday: "Tuesday", Start: "10:00", End: "10:30", FreeWeeks: 7, Conflicts: []SlotConflict{{Date: "2025-03-18", Busy: []string{"bob@example.com"}}}},
+	}
+	out := formatRecurringSlots(slots, 8, time.UTC, []string{"carol@example.com (notFound)"}, nil)
+	for _, want := range []string{"Tuesdays 10:00–10:30 — free 7 of 8 weeks", "2025-03-18 (bob@example.com)", "No slot is free every week", "carol@example.com (notFound)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	optional := []RecurringSlot{
+		{Weekday: "Monday", Start: "09:00", End: "09:30", FreeWeeks: 8, Score: 0.94, Conflicts: []SlotConflict{{Date: "2025-03-17", Busy: []string{}, OptionalBusy: []string{"dave@example.com"}}}},
+	}
+	out = formatRecurringSlots(optional, 8, time.UTC, nil, nil)
+	for _, want := range []string{"Mondays 09:00–09:30 — free for required attendees every week (score 0.94)", "2025-03-17 (optional: dave@example.com)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
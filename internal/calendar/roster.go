@@ -0,0 +1,118 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// defaultAttendeeLimit is how many attendees list_events shows per event
+// before summarizing the rest
+const defaultAttendeeLimit = 15
+
+// maxRosterFetches bounds how many events one listing re-reads to fill in
+// truncated attendee lists
+const maxRosterFetches = 25
+
+// fillOmittedAttendees replaces the truncated attendee lists of events
+// (attendeesOmitted) with the full roster from Events.Get, which returns every
+// attendee. At most maxRosterFetches events are re-read; the rest, and any
+// that fail to read, stay marked as truncated.
+func (c *Client) fillOmittedAttendees(calendarID string, events []*calendar.Event) {
+	fetched := 0
+	for _, event := range events {
+		if !event.AttendeesOmitted {
+			continue
+		}
+		if fetched == maxRosterFetches {
+			return
+		}
+		fetched++
+		full, err := c.GetEvent(calendarID, event.Id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Keeping truncated attendee list of %s: %v\n", event.Id, err)
+			continue
+		}
+		event.Attendees = full.Attendees
+		event.AttendeesOmitted = false
+	}
+}
+
+// omittedCount returns how many events still have truncated attendee lists.
+func omittedCount(events []*calendar.Event) int {
+	n := 0
+	for _, event := range events {
+		if event.AttendeesOmitted {
+			n++
+		}
+	}
+	return n
+}
+
+// shownAttendees picks the attendees listed for an event with more than limit
+// of them: the first limit in the event's order, keeping the signed-in user
+// among them as the API does for max_attendees. It also returns how many are
+// left out. A limit of 0 shows everyone.
+func shownAttendees(attendees []*calendar.EventAttendee, limit int) ([]*calendar.EventAttendee, int) {
+	if limit <= 0 || len(attendees) <= limit {
+		return attendees, 0
+	}
+	shown := append([]*calendar.EventAttendee(nil), attendees[:limit]...)
+	for _, a := range attendees[limit:] {
+		if a.Self {
+			shown[limit-1] = a
+			break
+		}
+	}
+	return shown, len(attendees) - limit
+}
+
+// rsvpSummary counts attendees by response, such as "80 accepted, 3 declined,
+// 17 awaiting reply".
+func rsvpSummary(attendees []*calendar.EventAttendee) string {
+	counts := make(map[string]int)
+	for _, a := range attendees {
+		counts[a.ResponseStatus]++
+	}
+	var parts []string
+	for _, status := range []struct{ key, label string }{
+		{"accepted", "accepted"},
+		{"tentative", "tentative"},
+		{"declined", "declined"},
+		{"needsAction", "awaiting reply"},
+	} {
+		if n := counts[status.key]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// truncatedCount returns how many events have more attendees than limit.
+func truncatedCount(events []*calendar.Event, limit int) int {
+	n := 0
+	for _, event := range events {
+		if limit > 0 && len(event.Attendees) > limit {
+			n++
+		}
+	}
+	return n
+}
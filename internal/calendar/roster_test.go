@@ -0,0 +1,112 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- omittedCount -----
+
+func TestOmittedCount(t *testing.T) {
+	events := []*calendar.Event{
+		{Id: "all-hands", AttendeesOmitted: true},
+		{Id: "standup"},
+		{Id: "town-hall", AttendeesOmitted: true},
+	}
+	if got := omittedCount(events); got != 2 {
+		t.Errorf("omittedCount = %d, want 2", got)
+	}
+	if got := omittedCount(nil); got != 0 {
+		t.Errorf("omittedCount(nil) = %d, want 0", got)
+	}
+}
+
+// ----- shownAttendees / rsvpSummary -----
+
+// crowd returns n attendees, every fourth one declined and the rest accepted.
+func crowd(n int) []*calendar.EventAttendee {
+	attendees := make([]*calendar.EventAttendee, n)
+	for i := range attendees {
+		status := "accepted"
+		if i%4 == 3 {
+			status = "declined"
+		}
+		attendees[i] = &calendar.EventAttendee{Email: fmt.Sprintf("guest%d@example.com", i), ResponseStatus: status}
+	}
+	return attendees
+}
+
+func TestShownAttendees(t *testing.T) {
+	attendees := crowd(40)
+	attendees[30].Self = true
+
+	shown, hidden := shownAttendees(attendees, 10)
+	if len(shown) != 10 || hidden != 30 {
+		t.Fatalf("shown %d, hidden %d; want 10 and 30", len(shown), hidden)
+	}
+	if shown[0] != attendees[0] || !shown[9].Self {
+		t.Errorf("want the first attendees with you kept last, got %s ... %s", shown[0].Email, shown[9].Email)
+	}
+	if attendees[9].Self || attendees[9].Email != "guest9@example.com" {
+		t.Error("shownAttendees must not modify the event's attendees")
+	}
+
+	for _, limit := range []int{0, 40, 100} {
+		if shown, hidden := shownAttendees(attendees, limit); len(shown) != 40 || hidden != 0 {
+			t.Errorf("limit %d: shown %d, hidden %d; want everyone", limit, len(shown), hidden)
+		}
+	}
+}
+
+func TestRSVPSummary(t *testing.T) {
+	attendees := append(crowd(8), &calendar.EventAttendee{ResponseStatus: "needsAction"})
+	if got, want := rsvpSummary(attendees), "6 accepted, 2 declined, 1 awaiting reply"; got != want {
+		t.Errorf("rsvpSummary = %q, want %q", got, want)
+	}
+}
+
+func TestFormatEventsResult_AttendeeLimit(t *testing.T) {
+	event := &calendar.Event{
+		Id:        "all-hands",
+		Summary:   "All hands",
+		Start:     &calendar.EventDateTime{DateTime: "2025-03-03T10:00:00Z"},
+		End:       &calendar.EventDateTime{DateTime: "2025-03-03T11:00:00Z"},
+		Attendees: crowd(120),
+	}
+	events := &calendar.Events{Items: []*calendar.Event{event}}
+	client := &Client{}
+	client.SetDisplaySettings(DisplaySettings{Locale: "en", Clock: "24h"})
+	ct := NewCalendarTools(client)
+
+	text := ct.formatEventsResult(events, ListEventsParams{TimeFilter: "today", AttendeeLimit: defaultAttendeeLimit})
+	for _, want := range []string{"Attendees (120: 90 accepted, 30 declined)", "guest14@example.com", "and 105 more", "attendee_limit: 0"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("listing missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "guest15@example.com") {
+		t.Error("attendees past the limit should not be listed")
+	}
+
+	text = ct.formatEventsResult(events, ListEventsParams{TimeFilter: "today"})
+	if !strings.Contains(text, "guest119@example.com") || strings.Contains(text, "more") {
+		t.Errorf("with no limit every attendee should be listed:\n%s", text)
+	}
+}
@@ -0,0 +1,726 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// schedulingPolicyEnv overrides where the scheduling policy is read from
+	schedulingPolicyEnv = "GCAL_MCP_SCHEDULING_POLICY_FILE"
+	// schedulingPolicyFile is the default policy file name, stored next to token.json
+	schedulingPolicyFile = "scheduling_policy.yaml"
+
+	severityWarn  = "warn"
+	severityBlock = "block"
+
+	ruleNoMeetingsAfter  = "no_meetings_after"
+	ruleNoMeetingsBefore = "no_meetings_before"
+	ruleNoMeetingDays    = "no_meeting_days"
+	ruleMaxMeetingHours  = "max_meeting_hours_per_day"
+	ruleProtectedTime    = "protected_time"
+
+	eventTypeFocusTime   = "focusTime"
+	eventTypeOutOfOffice = "outOfOffice"
+
+	// defaultPolicyAuditDays is the range list_policy_violations checks by default
+	defaultPolicyAuditDays = 7
+)
+
+// SchedulingRule is one rule of the scheduling policy, e.g. "no meetings
+// after 17:00" or "at most 5 hours of meetings a day".
+type SchedulingRule struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"`
+	Severity string   `yaml:"severity"` // "warn" (default) or "block"
+	Time     string   `yaml:"time"`     // HH:MM, for no_meetings_after and no_meetings_before
+	Days     []string `yaml:"days"`     // weekday names, for no_meeting_days
+	Hours    float64  `yaml:"hours"`    // for max_meeting_hours_per_day
+	// EventTypes are the blocks protected_time keeps meetings out of:
+	// "focusTime" and/or "outOfOffice" (default both)
+	EventTypes []string `yaml:"event_types"`
+
+	clock      time.Duration
+	weekdays   map[time.Weekday]bool
+	eventTypes map[string]bool
+}
+
+// SchedulingPolicy is the set of rules meetings are checked against. Rules
+// are evaluated in TimeZone, or in the zone of the meeting being checked when
+// it is unset.
+type SchedulingPolicy struct {
+	TimeZone string           `yaml:"timezone"`
+	Rules    []SchedulingRule `yaml:"rules"`
+
+	loc *time.Location
+}
+
+// PolicyViolation is a meeting, or a day of meetings, that breaks a rule.
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Date     string `json:"date"`
+	EventID  string `json:"event_id,omitempty"`
+	Summary  string `json:"summary,omitempty"`
+	Message  string `json:"message"`
+}
+
+// meetingSpan is a timed meeting being checked against the policy.
+type meetingSpan struct {
+	ID      string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// protectedSpan is a focus time or out-of-office block that protected_time
+// rules keep meetings out of.
+type protectedSpan struct {
+	EventType string
+	Summary   string
+	Start     time.Time
+	End       time.Time
+}
+
+// SchedulingPolicyPath returns GCAL_MCP_SCHEDULING_POLICY_FILE, or
+// scheduling_policy.yaml in dir.
+func SchedulingPolicyPath(dir string) string {
+	if path := os.Getenv(schedulingPolicyEnv); path != "" {
+		return path
+	}
+	return filepath.Join(dir, schedulingPolicyFile)
+}
+
+// LoadSchedulingPolicy reads and validates the policy file at path. A missing
+// file is not an error and yields an empty policy. The file is YAML; a JSON
+// policy is read the same way.
+func LoadSchedulingPolicy(path string) (*SchedulingPolicy, error) {
+	policy := &SchedulingPolicy{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return policy, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read scheduling policy: %v", err)
+	}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("unable to parse scheduling policy %s: %v", path, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scheduling policy %s: %v", path, err)
+	}
+	return policy, nil
+}
+
+// validate checks every rule and prepares it for evaluation.
+func (p *SchedulingPolicy) validate() error {
+	if p.TimeZone != "" {
+		loc, err := loadLocation(p.TimeZone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %v", p.TimeZone, err)
+		}
+		p.loc = loc
+	}
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			r.Name = r.Type
+		}
+		switch r.Severity {
+		case "":
+			r.Severity = severityWarn
+		case severityWarn, severityBlock:
+		default:
+			return fmt.Errorf("rule %q: severity must be %q or %q", r.Name, severityWarn, severityBlock)
+		}
+
+		switch r.Type {
+		case ruleNoMeetingsAfter, ruleNoMeetingsBefore:
+			clock, err := parseClock(r.Time)
+			if err != nil {
+				return fmt.Errorf("rule %q: %v", r.Name, err)
+			}
+			r.clock = clock
+		case ruleNoMeetingDays:
+			if len(r.Days) == 0 {
+				return fmt.Errorf("rule %q: days is required", r.Name)
+			}
+			r.weekdays = make(map[time.Weekday]bool)
+			for _, day := range r.Days {
+				weekday, ok := parseWeekday(day)
+				if !ok {
+					return fmt.Errorf("rule %q: unknown day %q", r.Name, day)
+				}
+				r.weekdays[weekday] = true
+			}
+		case ruleMaxMeetingHours:
+			if r.Hours <= 0 {
+				return fmt.Errorf("rule %q: hours must be positive", r.Name)
+			}
+		case ruleProtectedTime:
+			if len(r.EventTypes) == 0 {
+				r.EventTypes = []string{eventTypeFocusTime, eventTypeOutOfOffice}
+			}
+			r.eventTypes = make(map[string]bool)
+			for _, eventType := range r.EventTypes {
+				if eventType != eventTypeFocusTime && eventType != eventTypeOutOfOffice {
+					return fmt.Errorf("rule %q: unknown event type %q (expected %s or %s)", r.Name, eventType, eventTypeFocusTime, eventTypeOutOfOffice)
+				}
+				r.eventTypes[eventType] = true
+			}
+		default:
+			return fmt.Errorf("rule %q: unknown type %q (expected %s, %s, %s, %s or %s)", r.Name, r.Type,
+				ruleNoMeetingsAfter, ruleNoMeetingsBefore, ruleNoMeetingDays, ruleMaxMeetingHours, ruleProtectedTime)
+		}
+	}
+	return nil
+}
+
+// parseWeekday parses a weekday name such as "Friday" or "fri".
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// IsEmpty reports whether the policy has no rules.
+func (p *SchedulingPolicy) IsEmpty() bool {
+	return p == nil || len(p.Rules) == 0
+}
+
+// location returns the zone rules are evaluated in: the policy's own, else
+// fallback.
+func (p *SchedulingPolicy) location(fallback *time.Location) *time.Location {
+	if p.loc != nil {
+		return p.loc
+	}
+	return fallback
+}
+
+// needsDay reports whether checking a meeting requires the other meetings
+// of its day.
+func (p *SchedulingPolicy) needsDay() bool {
+	for _, r := range p.Rules {
+		if r.Type == ruleMaxMeetingHours {
+			return true
+		}
+	}
+	return false
+}
+
+// needsProtected reports whether checking a meeting requires the focus time
+// and out-of-office blocks around it.
+func (p *SchedulingPolicy) needsProtected() bool {
+	for _, r := range p.Rules {
+		if r.Type == ruleProtectedTime {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedEventTypes returns the event types a protected_time rule with block
+// severity covers. Slot suggestions treat such blocks as busy.
+func (p *SchedulingPolicy) blockedEventTypes() map[string]bool {
+	types := make(map[string]bool)
+	if p == nil {
+		return types
+	}
+	for _, r := range p.Rules {
+		if r.Type == ruleProtectedTime && r.Severity == severityBlock {
+			for eventType := range r.eventTypes {
+				types[eventType] = true
+			}
+		}
+	}
+	return types
+}
+
+// checkMeeting returns the rules broken by adding m to a day already holding
+// the meetings in day, next to the focus time and out-of-office blocks in
+// protected.
+func (p *SchedulingPolicy) checkMeeting(m meetingSpan, day []meetingSpan, protected []protectedSpan, loc *time.Location) []PolicyViolation {
+	violations := p.meetingViolations(m, loc)
+	violations = append(violations, p.protectedViolations(m, protected, loc)...)
+	date := dateKey(m.Start, loc)
+	total := m.End.Sub(m.Start)
+	for _, other := range day {
+		if other.ID != m.ID && dateKey(other.Start, loc) == date {
+			total += other.End.Sub(other.Start)
+		}
+	}
+	for _, r := range p.Rules {
+		if v, ok := r.dailyViolation(date, total); ok {
+			v.EventID, v.Summary = m.ID, m.Summary
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// audit returns every rule broken by the meetings, per meeting and per day,
+// ordered by date.
+func (p *SchedulingPolicy) audit(meetings []meetingSpan, protected []protectedSpan, loc *time.Location) []PolicyViolation {
+	var violations []PolicyViolation
+	totals := make(map[string]time.Duration)
+	for _, m := range meetings {
+		violations = append(violations, p.meetingViolations(m, loc)...)
+		violations = append(violations, p.protectedViolations(m, protected, loc)...)
+		totals[dateKey(m.Start, loc)] += m.End.Sub(m.Start)
+	}
+	for date, total := range totals {
+		for _, r := range p.Rules {
+			if v, ok := r.dailyViolation(date, total); ok {
+				violations = append(violations, v)
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Date < violations[j].Date })
+	return violations
+}
+
+// meetingViolations checks the rules that apply to a single meeting.
+func (p *SchedulingPolicy) meetingViolations(m meetingSpan, loc *time.Location) []PolicyViolation {
+	start, end := m.Start.In(loc), m.End.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+
+	var violations []PolicyViolation
+	for _, r := range p.Rules {
+		var message string
+		switch r.Type {
+		case ruleNoMeetingsAfter:
+			if end.After(atClock(day, r.clock)) {
+				message = fmt.Sprintf("ends at %s, after %s", end.Format("15:04"), r.Time)
+			}
+		case ruleNoMeetingsBefore:
+			if start.Before(atClock(day, r.clock)) {
+				message = fmt.Sprintf("starts at %s, before %s", start.Format("15:04"), r.Time)
+			}
+		case ruleNoMeetingDays:
+			if r.weekdays[start.Weekday()] {
+				message = fmt.Sprintf("is on a %s, a no-meeting day", start.Weekday())
+			}
+		}
+		if message != "" {
+			violations = append(violations, PolicyViolation{
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Date:     start.Format("2006-01-02"),
+				EventID:  m.ID,
+				Summary:  m.Summary,
+				Message:  fmt.Sprintf("'%s' %s", meetingTitle(m), message),
+			})
+		}
+	}
+	return violations
+}
+
+// protectedViolations checks the protected_time rules: a meeting must not
+// overlap the focus time or out-of-office blocks they cover.
+func (p *SchedulingPolicy) protectedViolations(m meetingSpan, protected []protectedSpan, loc *time.Location) []PolicyViolation {
+	var violations []PolicyViolation
+	for _, r := range p.Rules {
+		if r.Type != ruleProtectedTime {
+			continue
+		}
+		for _, b := range protected {
+			if !r.eventTypes[b.EventType] || !b.Start.Before(m.End) || !b.End.After(m.Start) {
+				continue
+			}
+			violations = append(violations, PolicyViolation{
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Date:     dateKey(m.Start, loc),
+				EventID:  m.ID,
+				Summary:  m.Summary,
+				Message:  fmt.Sprintf("'%s' overlaps %s", meetingTitle(m), describeProtected(b, m.Start, loc)),
+			})
+		}
+	}
+	return violations
+}
+
+// describeProtected names a protected block and its times, e.g. "focus time
+// 'Deep work' (09:00–11:00)". Times on another day than day carry the date.
+func describeProtected(b protectedSpan, day time.Time, loc *time.Location) string {
+	kind := "focus time"
+	if b.EventType == eventTypeOutOfOffice {
+		kind = "out of office"
+	}
+	clock := func(t time.Time) string {
+		if dateKey(t, loc) != dateKey(day, loc) {
+			return t.In(loc).Format("2006-01-02 15:04")
+		}
+		return t.In(loc).Format("15:04")
+	}
+	if b.Summary == "" {
+		return fmt.Sprintf("%s (%s–%s)", kind, clock(b.Start), clock(b.End))
+	}
+	return fmt.Sprintf("%s '%s' (%s–%s)", kind, b.Summary, clock(b.Start), clock(b.End))
+}
+
+// dailyViolation checks a daily limit rule against the total meeting time of
+// a day.
+func (r SchedulingRule) dailyViolation(date string, total time.Duration) (PolicyViolation, bool) {
+	if r.Type != ruleMaxMeetingHours || total.Hours() <= r.Hours {
+		return PolicyViolation{}, false
+	}
+	return PolicyViolation{
+		Rule:     r.Name,
+		Severity: r.Severity,
+		Date:     date,
+		Message:  fmt.Sprintf("%s has %.1f hours of meetings, over the limit of %g", date, total.Hours(), r.Hours),
+	}, true
+}
+
+func dateKey(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
+
+func meetingTitle(m meetingSpan) string {
+	if m.Summary == "" {
+		return "(No Title)"
+	}
+	return m.Summary
+}
+
+// blockingViolations returns the violations of rules with block severity.
+func blockingViolations(violations []PolicyViolation) []PolicyViolation {
+	var blocking []PolicyViolation
+	for _, v := range violations {
+		if v.Severity == severityBlock {
+			blocking = append(blocking, v)
+		}
+	}
+	return blocking
+}
+
+// describeViolations renders violations as bullets.
+func describeViolations(violations []PolicyViolation) string {
+	var result strings.Builder
+	for _, v := range violations {
+		fmt.Fprintf(&result, "• [%s] %s: %s\n", v.Severity, v.Rule, v.Message)
+	}
+	return result.String()
+}
+
+// meetingSpans returns the timed meetings among events: all-day, cancelled,
+// free (transparent), declined and non-meeting events such as focus time or
+// working location are skipped, as are pending holds, which stand in for a
+// meeting that is not booked yet.
+func meetingSpans(events []*calendar.Event) []meetingSpan {
+	var spans []meetingSpan
+	for _, e := range events {
+		if e.Status == "cancelled" || e.Transparency == "transparent" || e.Start == nil || e.End == nil || e.Start.DateTime == "" {
+			continue
+		}
+		if e.EventType != "" && e.EventType != "default" {
+			continue
+		}
+		if selfDeclined(e) || isHold(e) {
+			continue
+		}
+		start, err1 := time.Parse(time.RFC3339, e.Start.DateTime)
+		end, err2 := time.Parse(time.RFC3339, e.End.DateTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		spans = append(spans, meetingSpan{ID: e.Id, Summary: e.Summary, Start: start, End: end})
+	}
+	return spans
+}
+
+// protectedSpans returns the timed focus time and out-of-office blocks among
+// events, including focus time created by this server, which records its type
+// in a private extended property. Cancelled and declined blocks are skipped.
+func protectedSpans(events []*calendar.Event) []protectedSpan {
+	var spans []protectedSpan
+	for _, e := range events {
+		if e.Status == "cancelled" || e.Start == nil || e.End == nil || e.Start.DateTime == "" || selfDeclined(e) {
+			continue
+		}
+		eventType := e.EventType
+		if isFocusTime(e) {
+			eventType = eventTypeFocusTime
+		}
+		if eventType != eventTypeFocusTime && eventType != eventTypeOutOfOffice {
+			continue
+		}
+		start, err1 := time.Parse(time.RFC3339, e.Start.DateTime)
+		end, err2 := time.Parse(time.RFC3339, e.End.DateTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		spans = append(spans, protectedSpan{EventType: eventType, Summary: e.Summary, Start: start, End: end})
+	}
+	return spans
+}
+
+// selfDeclined reports whether the calendar's owner declined e. The API marks
+// their attendee entry as self, whichever of their addresses was invited.
+func selfDeclined(e *calendar.Event) bool {
+	for _, a := range e.Attendees {
+		if a.Self && a.ResponseStatus == "declined" {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSchedulingPolicy sets the rules proposed meetings are checked against,
+// by create_event and create_holds before writing and by the tools that
+// suggest times.
+func (ct *CalendarTools) SetSchedulingPolicy(policy *SchedulingPolicy) {
+	ct.schedulingPolicy = policy
+}
+
+// checkSchedulingPolicy evaluates a proposed meeting on calendarID. zone is
+// the meeting's time zone, used when the policy does not set one. The other
+// events of the day are only read when a daily limit or a protected_time rule
+// needs them.
+func (ct *CalendarTools) checkSchedulingPolicy(calendarID string, m meetingSpan, zone string) ([]PolicyViolation, error) {
+	policy := ct.schedulingPolicy
+	if policy.IsEmpty() {
+		return nil, nil
+	}
+	fallback := m.Start.Location()
+	if zone != "" {
+		if l, err := loadLocation(zone); err == nil {
+			fallback = l
+		}
+	}
+	loc := policy.location(fallback)
+
+	var day []meetingSpan
+	var protected []protectedSpan
+	if policy.needsDay() || policy.needsProtected() {
+		start := m.Start.In(loc)
+		dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		if m.End.After(dayEnd) {
+			dayEnd = m.End
+		}
+		events, err := ct.client.ListEvents(ListEventsParams{
+			CalendarID:   calendarID,
+			TimeFilter:   "custom",
+			TimeMin:      dayStart,
+			TimeMax:      dayEnd,
+			SingleEvents: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the day's meetings for the scheduling policy: %v", err)
+		}
+		day = meetingSpans(events.Items)
+		protected = protectedSpans(events.Items)
+	}
+	return policy.checkMeeting(m, day, protected, loc), nil
+}
+
+// protectedBusy returns the focus time and out-of-office blocks on calendarID
+// in [timeMin, timeMax) that a protected_time rule with block severity covers,
+// so slot suggestions never offer them, even when a block is marked free,
+// along with the occurrences of the profile's availability constraints.
+// Without such a rule no events are read.
+func (ct *CalendarTools) protectedBusy(calendarID string, timeMin, timeMax time.Time) ([]TimeSlot, error) {
+	busy := ct.constraintBusy(timeMin, timeMax)
+	blocked := ct.schedulingPolicy.blockedEventTypes()
+	if len(blocked) == 0 {
+		return busy, nil
+	}
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      timeMin,
+		TimeMax:      timeMax,
+		SingleEvents: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protected time for the scheduling policy: %v", err)
+	}
+	for _, b := range protectedSpans(events.Items) {
+		if blocked[b.EventType] {
+			busy = append(busy, TimeSlot{Start: b.Start, End: b.End})
+		}
+	}
+	return busy, nil
+}
+
+// blockedTimes returns the times in [timeMin, timeMax) that the clock and
+// day rules with block severity keep meetings out of, day by day in the
+// policy's zone, or fallback.
+func (p *SchedulingPolicy) blockedTimes(timeMin, timeMax time.Time, fallback *time.Location) []TimeSlot {
+	if p == nil {
+		return nil
+	}
+	loc := p.location(fallback)
+	first := timeMin.In(loc)
+	var blocked []TimeSlot
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); day.Before(timeMax); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		for _, r := range p.Rules {
+			if r.Severity != severityBlock {
+				continue
+			}
+			switch {
+			case r.Type == ruleNoMeetingsBefore:
+				blocked = append(blocked, TimeSlot{Start: day, End: atClock(day, r.clock)})
+			case r.Type == ruleNoMeetingsAfter:
+				blocked = append(blocked, TimeSlot{Start: atClock(day, r.clock), End: next})
+			case r.Type == ruleNoMeetingDays && r.weekdays[day.Weekday()]:
+				blocked = append(blocked, TimeSlot{Start: day, End: next})
+			}
+		}
+	}
+	return blocked
+}
+
+// policyBusy returns the times on calendarID in [timeMin, timeMax) that rules
+// with block severity rule out for a new meeting: blockedTimes, plus the
+// whole of every day whose meetings already reach a max_meeting_hours_per_day
+// limit. share_availability treats them as busy. The day's events are only
+// read when there is such a limit.
+func (ct *CalendarTools) policyBusy(calendarID string, timeMin, timeMax time.Time, fallback *time.Location) ([]TimeSlot, error) {
+	policy := ct.schedulingPolicy
+	if policy.IsEmpty() {
+		return nil, nil
+	}
+	busy := policy.blockedTimes(timeMin, timeMax, fallback)
+
+	var limits []SchedulingRule
+	for _, r := range policy.Rules {
+		if r.Type == ruleMaxMeetingHours && r.Severity == severityBlock {
+			limits = append(limits, r)
+		}
+	}
+	if len(limits) == 0 {
+		return busy, nil
+	}
+	loc := policy.location(fallback)
+	first := timeMin.In(loc)
+	dayMin := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      dayMin,
+		TimeMax:      timeMax,
+		SingleEvents: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meetings for the scheduling policy: %v", err)
+	}
+	totals := make(map[string]time.Duration)
+	for _, m := range meetingSpans(events.Items) {
+		totals[dateKey(m.Start, loc)] += m.End.Sub(m.Start)
+	}
+	for day := dayMin; day.Before(timeMax); day = day.AddDate(0, 0, 1) {
+		for _, r := range limits {
+			if totals[dateKey(day, loc)].Hours() >= r.Hours {
+				busy = append(busy, TimeSlot{Start: day, End: day.AddDate(0, 0, 1)})
+				break
+			}
+		}
+	}
+	return busy, nil
+}
+
+func (ct *CalendarTools) handleListPolicyViolations(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	policy := ct.schedulingPolicy
+	if policy.IsEmpty() {
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{
+				Type: "text",
+				Text: fmt.Sprintf("No scheduling policy is configured. Add rules to %s (or the file named by %s).", schedulingPolicyFile, schedulingPolicyEnv),
+			}},
+		}, nil
+	}
+
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+	loc = policy.location(loc)
+
+	now := time.Now()
+	timeMin, timeMax := now, now.AddDate(0, 0, defaultPolicyAuditDays)
+	if v := getStringOrDefault(arguments, "time_min", ""); v != "" {
+		if timeMin, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid time_min format: %v", err)
+		}
+	}
+	if v := getStringOrDefault(arguments, "time_max", ""); v != "" {
+		if timeMax, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid time_max format: %v", err)
+		}
+	}
+	if !timeMax.After(timeMin) {
+		return nil, fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   ct.calendarID(arguments),
+		TimeFilter:   "custom",
+		TimeMin:      timeMin,
+		TimeMax:      timeMax,
+		SingleEvents: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	violations := policy.audit(meetingSpans(events.Items), protectedSpans(events.Items), loc)
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "📏 Scheduling policy audit, %s to %s (%s):\n\n", timeMin.In(loc).Format("2006-01-02"), timeMax.In(loc).Format("2006-01-02"), loc.String())
+	if len(violations) == 0 {
+		fmt.Fprintf(&result, "• No violations of the %d rule(s)\n", len(policy.Rules))
+	} else {
+		result.WriteString(describeViolations(violations))
+	}
+
+	if violations == nil {
+		violations = []PolicyViolation{}
+	}
+	violationsJSON, _ := json.MarshalIndent(violations, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(violationsJSON))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
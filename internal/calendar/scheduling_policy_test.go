@@ -0,0 +1,355 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// testPolicy builds a validated policy evaluated in Europe/Berlin.
+func testPolicy(t *testing.T, rules ...SchedulingRule) *SchedulingPolicy {
+	t.Helper()
+	p := &SchedulingPolicy{TimeZone: "Europe/Berlin", Rules: rules}
+	if err := p.validate(); err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+	return p
+}
+
+// ----- LoadSchedulingPolicy -----
+
+func TestLoadSchedulingPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if p, err := LoadSchedulingPolicy(filepath.Join(dir, "missing.yaml")); err != nil || !p.IsEmpty() {
+		t.Fatalf("missing file = %+v, %v; want empty policy", p, err)
+	}
+
+	files := map[string]string{
+		"policy.yaml": `timezone: Europe/Berlin
+rules:
+  - name: no late meetings
+    type: no_meetings_after
+    time: "17:00"
+    severity: block
+  - type: no_meeting_days
+    days: [Fri]
+  - type: max_meeting_hours_per_day
+    hours: 5
+  - type: protected_time
+    event_types: [focusTime]
+`,
+		"policy.json": `{"timezone": "Europe/Berlin", "rules": [
+			{"name": "no late meetings", "type": "no_meetings_after", "time": "17:00", "severity": "block"},
+			{"type": "no_meeting_days", "days": ["Fri"]},
+			{"type": "max_meeting_hours_per_day", "hours": 5},
+			{"type": "protected_time", "event_types": ["focusTime"]}
+		]}`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		p, err := LoadSchedulingPolicy(path)
+		if err != nil {
+			t.Fatalf("%s: LoadSchedulingPolicy() error: %v", name, err)
+		}
+		if len(p.Rules) != 4 || p.loc == nil || p.Rules[0].Severity != severityBlock || p.Rules[0].clock != 17*time.Hour {
+			t.Fatalf("%s: unexpected rules %+v", name, p.Rules)
+		}
+		if p.Rules[1].Name != ruleNoMeetingDays || p.Rules[1].Severity != severityWarn || !p.Rules[1].weekdays[time.Friday] {
+			t.Errorf("%s: unexpected no_meeting_days rule %+v", name, p.Rules[1])
+		}
+		if p.Rules[2].Hours != 5 || !p.Rules[3].eventTypes[eventTypeFocusTime] || p.Rules[3].eventTypes[eventTypeOutOfOffice] {
+			t.Errorf("%s: unexpected rules %+v", name, p.Rules[2:])
+		}
+	}
+
+	path := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(path, []byte("rules: [type: no_meetings_after"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSchedulingPolicy(path); err == nil || !strings.Contains(err.Error(), "unable to parse") {
+		t.Errorf("broken file error = %v, want a parse error", err)
+	}
+}
+
+func TestSchedulingPolicyValidate_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy SchedulingPolicy
+		want   string
+	}{
+		{"bad zone", SchedulingPolicy{TimeZone: "Mars/Base"}, "invalid timezone"},
+		{"unknown type", SchedulingPolicy{Rules: []SchedulingRule{{Type: "no_fun"}}}, "unknown type"},
+		{"bad severity", SchedulingPolicy{Rules: []SchedulingRule{{Type: ruleNoMeetingsAfter, Time: "17:00", Severity: "fatal"}}}, "severity"},
+		{"bad time", SchedulingPolicy{Rules: []SchedulingRule{{Type: ruleNoMeetingsBefore, Time: "9am"}}}, "expected HH:MM"},
+		{"no days", SchedulingPolicy{Rules: []SchedulingRule{{Type: ruleNoMeetingDays}}}, "days is required"},
+		{"bad day", SchedulingPolicy{Rules: []SchedulingRule{{Type: ruleNoMeetingDays, Days: []string{"Caturday"}}}}, "unknown day"},
+		{"no hours", SchedulingPolicy{Rules: []SchedulingRule{{Type: ruleMaxMeetingHours}}}, "hours must be positive"},
+		{"bad event type", SchedulingPolicy{Rules: []SchedulingRule{{Type: ruleProtectedTime, EventTypes: []string{"workingLocation"}}}}, "unknown event type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validate() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// ----- checkMeeting -----
+
+func TestCheckMeeting(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 3, day, hour, min, 0, 0, berlin) }
+	p := testPolicy(t,
+		SchedulingRule{Name: "evenings free", Type: ruleNoMeetingsAfter, Time: "17:00", Severity: severityBlock},
+		SchedulingRule{Name: "mornings free", Type: ruleNoMeetingsBefore, Time: "09:00"},
+		SchedulingRule{Name: "focus fridays", Type: ruleNoMeetingDays, Days: []string{"friday"}},
+		SchedulingRule{Name: "meeting cap", Type: ruleMaxMeetingHours, Hours: 3},
+	)
+	// Monday 2025-03-03 already has 2.5 hours of meetings
+	day := []meetingSpan{
+		{ID: "a", Start: at(3, 9, 0), End: at(3, 11, 0)},
+		{ID: "b", Start: at(3, 13, 0), End: at(3, 13, 30)},
+	}
+
+	tests := []struct {
+		name  string
+		m     meetingSpan
+		day   []meetingSpan
+		rules []string
+	}{
+		{"fits", meetingSpan{Start: at(4, 10, 0), End: at(4, 11, 0)}, nil, nil},
+		{"ends exactly at the limit", meetingSpan{Start: at(4, 16, 0), End: at(4, 17, 0)}, nil, nil},
+		{"runs late", meetingSpan{Start: at(4, 16, 30), End: at(4, 17, 30)}, nil, []string{"evenings free"}},
+		{"too early", meetingSpan{Start: at(4, 8, 30), End: at(4, 9, 30)}, nil, []string{"mornings free"}},
+		{"friday", meetingSpan{Start: at(7, 10, 0), End: at(7, 10, 30)}, nil, []string{"focus fridays"}},
+		{"over the daily cap", meetingSpan{Start: at(3, 14, 0), End: at(3, 15, 0)}, day, []string{"meeting cap"}},
+		{"under the daily cap", meetingSpan{Start: at(3, 14, 0), End: at(3, 14, 30)}, day, nil},
+		// 16:30 UTC is 17:30 in Berlin, where the rules are evaluated
+		{"evaluated in policy zone", meetingSpan{Start: time.Date(2025, 3, 4, 15, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 4, 16, 30, 0, 0, time.UTC)}, nil, []string{"evenings free"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, v := range p.checkMeeting(tt.m, tt.day, nil, p.location(time.UTC)) {
+				rules = append(rules, v.Rule)
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.rules, ",") {
+				t.Errorf("violated rules = %v, want %v", rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestCheckMeeting_ProtectedTime(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 3, day, hour, min, 0, 0, berlin) }
+	p := testPolicy(t,
+		SchedulingRule{Name: "focus", Type: ruleProtectedTime, EventTypes: []string{eventTypeFocusTime}},
+		SchedulingRule{Name: "away", Type: ruleProtectedTime, EventTypes: []string{eventTypeOutOfOffice}, Severity: severityBlock},
+	)
+	protected := []protectedSpan{
+		{EventType: eventTypeFocusTime, Summary: "Deep work", Start: at(3, 9, 0), End: at(3, 11, 0)},
+		{EventType: eventTypeOutOfOffice, Start: at(3, 15, 0), End: at(4, 12, 0)},
+	}
+
+	tests := []struct {
+		name    string
+		m       meetingSpan
+		rules   []string
+		message string
+	}{
+		{"between blocks", meetingSpan{Start: at(3, 11, 0), End: at(3, 12, 0)}, nil, ""},
+		{"in focus time", meetingSpan{Summary: "Sync", Start: at(3, 10, 30), End: at(3, 11, 30)}, []string{"focus"}, "'Sync' overlaps focus time 'Deep work' (09:00–11:00)"},
+		{"out of office next morning", meetingSpan{Summary: "Standup", Start: at(4, 9, 0), End: at(4, 9, 15)}, []string{"away"}, "'Standup' overlaps out of office (2025-03-03 15:00–12:00)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := p.checkMeeting(tt.m, nil, protected, p.location(time.UTC))
+			var rules []string
+			for _, v := range violations {
+				rules = append(rules, v.Rule)
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.rules, ",") {
+				t.Errorf("violated rules = %v, want %v", rules, tt.rules)
+			}
+			if tt.message != "" && violations[0].Message != tt.message {
+				t.Errorf("message = %q, want %q", violations[0].Message, tt.message)
+			}
+		})
+	}
+
+	if blocked := p.blockedEventTypes(); len(blocked) != 1 || !blocked[eventTypeOutOfOffice] {
+		t.Errorf("blockedEventTypes() = %v, want only outOfOffice", blocked)
+	}
+}
+
+// ----- audit -----
+
+func TestSchedulingPolicyAudit(t *testing.T) {
+	p := testPolicy(t,
+		SchedulingRule{Name: "evenings free", Type: ruleNoMeetingsAfter, Time: "17:00", Severity: severityBlock},
+		SchedulingRule{Name: "meeting cap", Type: ruleMaxMeetingHours, Hours: 2},
+	)
+	berlin := p.location(time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, berlin) }
+	violations := p.audit([]meetingSpan{
+		{ID: "late", Summary: "Late sync", Start: at(4, 17), End: at(4, 18)},
+		{ID: "x", Start: at(3, 9), End: at(3, 11)},
+		{ID: "y", Start: at(3, 13), End: at(3, 14)},
+	}, nil, berlin)
+
+	if len(violations) != 2 {
+		t.Fatalf("got %d violations, want 2: %+v", len(violations), violations)
+	}
+	if v := violations[0]; v.Date != "2025-03-03" || v.Rule != "meeting cap" || !strings.Contains(v.Message, "3.0 hours") {
+		t.Errorf("unexpected daily violation %+v", v)
+	}
+	if v := violations[1]; v.EventID != "late" || v.Severity != severityBlock || !strings.Contains(v.Message, "'Late sync' ends at 18:00") {
+		t.Errorf("unexpected meeting violation %+v", v)
+	}
+	if blocking := blockingViolations(violations); len(blocking) != 1 || blocking[0].EventID != "late" {
+		t.Errorf("blockingViolations() = %+v", blocking)
+	}
+}
+
+// ----- blockedTimes and policyBusy -----
+
+func TestBlockedTimes(t *testing.T) {
+	p := testPolicy(t,
+		SchedulingRule{Name: "evenings free", Type: ruleNoMeetingsAfter, Time: "17:00", Severity: severityBlock},
+		SchedulingRule{Name: "early starts", Type: ruleNoMeetingsBefore, Time: "09:00"},
+		SchedulingRule{Name: "no-meeting Fridays", Type: ruleNoMeetingDays, Days: []string{"friday"}, Severity: severityBlock},
+	)
+	berlin := p.location(time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, berlin) }
+
+	// Thursday 6 and Friday 7 March; warn rules rule nothing out
+	blocked := p.blockedTimes(at(6, 12), at(8, 0), time.UTC)
+	want := []TimeSlot{
+		{Start: at(6, 17), End: at(7, 0)},
+		{Start: at(7, 17), End: at(8, 0)},
+		{Start: at(7, 0), End: at(8, 0)},
+	}
+	if len(blocked) != len(want) {
+		t.Fatalf("blockedTimes() = %+v, want %+v", blocked, want)
+	}
+	for i := range want {
+		if !blocked[i].Start.Equal(want[i].Start) || !blocked[i].End.Equal(want[i].End) {
+			t.Errorf("blocked[%d] = %v–%v, want %v–%v", i, blocked[i].Start, blocked[i].End, want[i].Start, want[i].End)
+		}
+	}
+}
+
+func TestPolicyBusy_MeetingCap(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+	ct.SetSchedulingPolicy(testPolicy(t, SchedulingRule{Name: "meeting cap", Type: ruleMaxMeetingHours, Hours: 2, Severity: severityBlock}))
+	berlin := ct.schedulingPolicy.location(time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2030, 3, day, hour, 0, 0, 0, berlin) }
+	for _, hour := range []int{9, 11} {
+		if _, err := store.InsertEvent("primary", &calendar.Event{
+			Summary: "Review",
+			Start:   &calendar.EventDateTime{DateTime: at(4, hour).Format(time.RFC3339)},
+			End:     &calendar.EventDateTime{DateTime: at(4, hour+1).Format(time.RFC3339)},
+		}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 4 March already has 2 hours of meetings; 5 March has none
+	busy, err := ct.policyBusy("primary", at(4, 8), at(6, 0), time.UTC)
+	if err != nil {
+		t.Fatalf("policyBusy() error: %v", err)
+	}
+	if len(busy) != 1 || !busy[0].Start.Equal(at(4, 0)) || !busy[0].End.Equal(at(5, 0)) {
+		t.Errorf("policyBusy() = %+v, want all of 4 March", busy)
+	}
+}
+
+// ----- meetingSpans -----
+
+func TestMeetingSpans(t *testing.T) {
+	timed := func(id string) *calendar.Event {
+		return &calendar.Event{
+			Id:    id,
+			Start: &calendar.EventDateTime{DateTime: "2025-03-03T10:00:00Z"},
+			End:   &calendar.EventDateTime{DateTime: "2025-03-03T11:00:00Z"},
+		}
+	}
+	allDay := &calendar.Event{Id: "allday", Start: &calendar.EventDateTime{Date: "2025-03-03"}, End: &calendar.EventDateTime{Date: "2025-03-04"}}
+	free := timed("free")
+	free.Transparency = "transparent"
+	focus := timed("focus")
+	focus.EventType = "focusTime"
+	declined := timed("declined")
+	declined.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+	hold := timed("hold")
+	hold.ExtendedProperties = &calendar.EventExtendedProperties{Private: map[string]string{holdGroupKey: "g1", holdStatusKey: holdStatusPending}}
+
+	spans := meetingSpans([]*calendar.Event{timed("meeting"), allDay, free, focus, declined, hold})
+	if len(spans) != 1 || spans[0].ID != "meeting" {
+		t.Errorf("meetingSpans() = %+v, want only the meeting", spans)
+	}
+}
+
+// ----- protectedSpans -----
+
+func TestProtectedSpans(t *testing.T) {
+	timed := func(summary, eventType string) *calendar.Event {
+		return &calendar.Event{
+			Summary:   summary,
+			EventType: eventType,
+			Start:     &calendar.EventDateTime{DateTime: "2025-03-03T10:00:00Z"},
+			End:       &calendar.EventDateTime{DateTime: "2025-03-03T11:00:00Z"},
+		}
+	}
+	// Focus time created by this server carries its type in a private property
+	created := timed("created focus", "")
+	created.ExtendedProperties = &calendar.EventExtendedProperties{Private: map[string]string{"eventType": eventTypeFocusTime}}
+	free := timed("free focus", eventTypeFocusTime)
+	free.Transparency = "transparent"
+	cancelled := timed("cancelled", eventTypeOutOfOffice)
+	cancelled.Status = "cancelled"
+
+	spans := protectedSpans([]*calendar.Event{
+		timed("meeting", ""), timed("focus", eventTypeFocusTime), timed("away", eventTypeOutOfOffice),
+		timed("office", "workingLocation"), created, free, cancelled,
+	})
+	var got []string
+	for _, s := range spans {
+		got = append(got, s.Summary+"="+s.EventType)
+	}
+	want := "focus=focusTime,away=outOfOffice,created focus=focusTime,free focus=focusTime"
+	if strings.Join(got, ",") != want {
+		t.Errorf("protectedSpans() = %v, want %s", got, want)
+	}
+}
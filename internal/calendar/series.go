@@ -0,0 +1,293 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// defaultSeriesLookbackDays is how far back analyze_series looks by default
+	defaultSeriesLookbackDays = 90
+	// maxSeriesLookbackDays bounds the history scanned by analyze_series
+	maxSeriesLookbackDays = 730
+
+	// Thresholds beyond which analyze_series suggests changing the series
+	lowAttendanceRate  = 0.5
+	highCancelRate     = 0.25
+	highRescheduleRate = 0.25
+
+	// seriesInstanceFields is the field selector for occurrences read by GetSeriesHistory
+	seriesInstanceFields = "items(id,status,start,originalStartTime,attendees(email,responseStatus,self,resource)),nextPageToken"
+)
+
+// SeriesStats summarizes the history of a recurring event.
+type SeriesStats struct {
+	SeriesID        string          `json:"series_id"`
+	Summary         string          `json:"summary"`
+	Since           string          `json:"since"`
+	Occurrences     int             `json:"occurrences"` // past occurrences, including cancelled ones
+	Held            int             `json:"held"`
+	Cancelled       int             `json:"cancelled"`
+	Rescheduled     int             `json:"rescheduled"`
+	DetailsHidden   int             `json:"details_hidden,omitempty"` // held occurrences that are private on someone else's calendar
+	AvgInvited      float64         `json:"avg_invited"`
+	AvgAccepted     float64         `json:"avg_accepted"`
+	AttendanceRate  float64         `json:"attendance_rate"` // accepted / invited over held occurrences
+	CancelRate      float64         `json:"cancel_rate"`
+	RescheduleRate  float64         `json:"reschedule_rate"`
+	Attendees       []AttendeeStats `json:"attendees,omitempty"`
+	Recommendations []string        `json:"recommendations"`
+}
+
+// AttendeeStats is one guest's responses across the held occurrences.
+type AttendeeStats struct {
+	Email    string `json:"email"`
+	Invited  int    `json:"invited"`
+	Accepted int    `json:"accepted"`
+	Declined int    `json:"declined"`
+}
+
+// GetSeriesHistory returns the recurring series and its occurrences that
+// started in the lookback window, including cancelled ones.
+func (c *Client) GetSeriesHistory(calendarID, eventID string, lookback time.Duration) (*calendar.Event, []*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, nil, err
+	}
+
+	seriesID := stripRecurringInstanceSuffix(eventID)
+	series, err := c.GetEvent(calendarID, seriesID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get recurring series: %v", err)
+	}
+	if len(series.Recurrence) == 0 {
+		return nil, nil, fmt.Errorf("event %s is not a recurring series", seriesID)
+	}
+
+	now := time.Now()
+	call := c.service.Events.Instances(calendarID, seriesID).
+		TimeMin(now.Add(-lookback).Format(time.RFC3339)).
+		TimeMax(now.Format(time.RFC3339)).
+		ShowDeleted(true).
+		MaxResults(250).
+		Fields(googleapi.Field(seriesInstanceFields))
+
+	var instances []*calendar.Event
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get past occurrences: %v", err)
+		}
+		instances = append(instances, page.Items...)
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return series, instances, nil
+}
+
+// analyzeSeries computes attendance, cancellation and reschedule statistics
+// over past occurrences of a series. The user and resources are not counted
+// as attendees. Private occurrences whose details are hidden have no attendee
+// list, so they are left out of the attendance figures.
+func analyzeSeries(series *calendar.Event, instances []*calendar.Event, since time.Time) SeriesStats {
+	stats := SeriesStats{
+		SeriesID:    series.Id,
+		Summary:     series.Summary,
+		Since:       since.Format("2006-01-02"),
+		Occurrences: len(instances),
+	}
+
+	byEmail := make(map[string]*AttendeeStats)
+	invited, accepted, withAttendees := 0, 0, 0
+	for _, inst := range instances {
+		if inst.Status == "cancelled" {
+			stats.Cancelled++
+			continue
+		}
+		stats.Held++
+		if wasRescheduled(inst) {
+			stats.Rescheduled++
+		}
+		if isHiddenPrivate(inst) {
+			stats.DetailsHidden++
+			continue
+		}
+		withAttendees++
+
+		for _, a := range inst.Attendees {
+			if a.Self || a.Resource || a.Email == "" {
+				continue
+			}
+			email := strings.ToLower(a.Email)
+			as, ok := byEmail[email]
+			if !ok {
+				as = &AttendeeStats{Email: email}
+				byEmail[email] = as
+			}
+			as.Invited++
+			invited++
+			switch a.ResponseStatus {
+			case "accepted":
+				as.Accepted++
+				accepted++
+			case "declined":
+				as.Declined++
+			}
+		}
+	}
+
+	if withAttendees > 0 {
+		stats.AvgInvited = roundTo(float64(invited)/float64(withAttendees), 1)
+		stats.AvgAccepted = roundTo(float64(accepted)/float64(withAttendees), 1)
+	}
+	if stats.Held > 0 {
+		stats.RescheduleRate = roundTo(float64(stats.Rescheduled)/float64(stats.Held), 2)
+	}
+	if invited > 0 {
+		stats.AttendanceRate = roundTo(float64(accepted)/float64(invited), 2)
+	}
+	if stats.Occurrences > 0 {
+		stats.CancelRate = roundTo(float64(stats.Cancelled)/float64(stats.Occurrences), 2)
+	}
+
+	for _, as := range byEmail {
+		stats.Attendees = append(stats.Attendees, *as)
+	}
+	// Least engaged attendees first
+	sort.Slice(stats.Attendees, func(i, j int) bool {
+		ai, aj := stats.Attendees[i], stats.Attendees[j]
+		ri, rj := float64(ai.Accepted)/float64(ai.Invited), float64(aj.Accepted)/float64(aj.Invited)
+		if ri != rj {
+			return ri < rj
+		}
+		return ai.Email < aj.Email
+	})
+
+	stats.Recommendations = seriesRecommendations(stats)
+	return stats
+}
+
+// wasRescheduled reports whether an occurrence was moved from its original time.
+func wasRescheduled(inst *calendar.Event) bool {
+	if inst.OriginalStartTime == nil || inst.Start == nil {
+		return false
+	}
+	if inst.Start.Date != "" || inst.OriginalStartTime.Date != "" {
+		return inst.Start.Date != inst.OriginalStartTime.Date
+	}
+	start, err1 := time.Parse(time.RFC3339, inst.Start.DateTime)
+	original, err2 := time.Parse(time.RFC3339, inst.OriginalStartTime.DateTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return !start.Equal(original)
+}
+
+func seriesRecommendations(stats SeriesStats) []string {
+	if stats.Occurrences == 0 {
+		return []string{"No past occurrences in the period; nothing to analyze yet."}
+	}
+
+	var recs []string
+	if stats.Held > 0 && stats.AvgInvited > 0 && stats.AttendanceRate < lowAttendanceRate {
+		recs = append(recs, fmt.Sprintf("Low attendance: only %.0f%% of invitations were accepted. Consider meeting less often, trimming the guest list, or making attendance optional.", stats.AttendanceRate*100))
+	}
+	if stats.CancelRate >= highCancelRate {
+		recs = append(recs, fmt.Sprintf("Often cancelled: %.0f%% of occurrences were cancelled. The series may recur more often than needed.", stats.CancelRate*100))
+	}
+	if stats.RescheduleRate >= highRescheduleRate {
+		recs = append(recs, fmt.Sprintf("Often moved: %.0f%% of held occurrences were rescheduled. The regular time slot may not suit attendees.", stats.RescheduleRate*100))
+	}
+	if len(recs) == 0 {
+		recs = append(recs, "The series looks healthy: attendance is good and it rarely moves or gets cancelled.")
+	}
+	return recs
+}
+
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow10(places)
+	return math.Round(v*scale) / scale
+}
+
+// formatSeriesStats renders the statistics as a short report followed by the
+// structured data.
+func formatSeriesStats(stats SeriesStats) string {
+	var result strings.Builder
+
+	title := stats.Summary
+	if title == "" {
+		title = "(No Title)"
+	}
+	fmt.Fprintf(&result, "📈 Series analysis for '%s' since %s:\n\n", title, stats.Since)
+	fmt.Fprintf(&result, "• Occurrences: %d (%d held, %d cancelled, %d rescheduled)\n", stats.Occurrences, stats.Held, stats.Cancelled, stats.Rescheduled)
+	if stats.Held > stats.DetailsHidden {
+		fmt.Fprintf(&result, "• Average attendees: %.1f invited, %.1f accepted (%.0f%% attendance)\n", stats.AvgInvited, stats.AvgAccepted, stats.AttendanceRate*100)
+	}
+	if stats.DetailsHidden > 0 {
+		fmt.Fprintf(&result, "• %d held occurrence(s) are private (%s) and not counted in attendance\n", stats.DetailsHidden, privateBusyTitle)
+	}
+	result.WriteString("\n💡 Recommendations:\n")
+	for _, rec := range stats.Recommendations {
+		fmt.Fprintf(&result, "• %s\n", rec)
+	}
+
+	statsJSON, _ := json.MarshalIndent(stats, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(statsJSON))
+	return result.String()
+}
+
+func (ct *CalendarTools) handleAnalyzeSeries(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID, ok := arguments["event_id"].(string)
+	if !ok || eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+
+	lookbackDays := getIntOrDefault(arguments, "lookback_days", defaultSeriesLookbackDays)
+	if lookbackDays < 1 || lookbackDays > maxSeriesLookbackDays {
+		return nil, fmt.Errorf("lookback_days must be between 1 and %d", maxSeriesLookbackDays)
+	}
+	lookback := time.Duration(lookbackDays) * 24 * time.Hour
+
+	series, instances, err := ct.client.GetSeriesHistory(ct.calendarID(arguments), eventID, lookback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze series: %v", err)
+	}
+
+	stats := analyzeSeries(series, instances, time.Now().Add(-lookback))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatSeriesStats(stats),
+		}},
+	}, nil
+}
@@ -0,0 +1,374 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// defaultSeriesConflictWeeks and maxSeriesConflictWeeks bound how far
+	// ahead find_series_conflicts looks
+	defaultSeriesConflictWeeks = 8
+	maxSeriesConflictWeeks     = 13
+	// maxSeriesConflictDates bounds the dates listed per conflict in text
+	maxSeriesConflictDates = 6
+)
+
+// SeriesConflict is two recurring series whose occurrences overlap, the one
+// that looks more important first, with the pattern of their collisions and
+// fixes that apply to the whole series.
+type SeriesConflict struct {
+	SeriesIDs   []string            `json:"series_ids"`
+	Summaries   []string            `json:"summaries"`
+	Pattern     string              `json:"pattern"`         // e.g. "every other Tuesday, 10:00 AM–10:30 AM"
+	Dates       []string            `json:"dates"`           // YYYY-MM-DD of each collision
+	Occurrences int                 `json:"occurrences"`     // occurrences of the second series in the range
+	Minutes     int                 `json:"overlap_minutes"` // longest overlap
+	Reason      string              `json:"reason"`          // why the second series looks less important
+	Resolutions []OverlapResolution `json:"resolutions"`
+	Notes       []string            `json:"notes,omitempty"`
+
+	events [2]*calendar.Event // an occurrence of each series, higher priority first
+	spans  []TimeSlot         // the overlaps, in order
+}
+
+// seriesPair returns the two series a conflict is between, in order, or
+// false when it is not between occurrences of two different series.
+func seriesPair(c OverlapConflict) ([2]string, bool) {
+	a, b := c.events[0].RecurringEventId, c.events[1].RecurringEventId
+	if a == "" || b == "" || a == b {
+		return [2]string{}, false
+	}
+	return [2]string{a, b}, true
+}
+
+// groupSeriesConflicts merges the conflicts between occurrences of the same
+// two series into one SeriesConflict each, ordered by the first collision.
+// Conflicts involving single events are left out.
+func groupSeriesConflicts(conflicts []OverlapConflict, events []*calendar.Event, loc *time.Location) []SeriesConflict {
+	occurrences := make(map[string]int)
+	for _, e := range events {
+		if e.RecurringEventId != "" && blocksTime(e) {
+			occurrences[e.RecurringEventId]++
+		}
+	}
+
+	byPair := make(map[[2]string]*SeriesConflict)
+	var order [][2]string
+	for _, c := range conflicts {
+		pair, ok := seriesPair(c)
+		if !ok {
+			continue
+		}
+		key := pair
+		if key[0] > key[1] {
+			key[0], key[1] = key[1], key[0]
+		}
+		sc, ok := byPair[key]
+		if !ok {
+			// The first collision decides which series looks less important
+			sc = &SeriesConflict{
+				SeriesIDs:   []string{pair[0], pair[1]},
+				Summaries:   c.Summaries,
+				Occurrences: occurrences[pair[1]],
+				Reason:      c.Reason,
+				Resolutions: []OverlapResolution{},
+				events:      c.events,
+			}
+			byPair[key] = sc
+			order = append(order, key)
+		}
+		start, _ := time.Parse(time.RFC3339, c.Start)
+		end, _ := time.Parse(time.RFC3339, c.End)
+		sc.spans = append(sc.spans, TimeSlot{Start: start.In(loc), End: end.In(loc)})
+		sc.Dates = append(sc.Dates, start.In(loc).Format(dateLayout))
+		sc.Minutes = max(sc.Minutes, c.Minutes)
+	}
+
+	result := make([]SeriesConflict, 0, len(order))
+	for _, key := range order {
+		result = append(result, *byPair[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].spans[0].Start.Before(result[j].spans[0].Start) })
+	return result
+}
+
+// conflictPattern describes when collisions recur: "every Tuesday", "every
+// other Tuesday" or "every 3 weeks on Tuesday" when they are evenly spaced
+// whole weeks apart, else how many there are, followed by the overlap's time
+// of day when it is always the same.
+func conflictPattern(spans []TimeSlot, tf TimeFormat) string {
+	first := spans[0].Start
+	sameDay, sameTime := true, true
+	for _, s := range spans[1:] {
+		sameDay = sameDay && s.Start.Weekday() == first.Weekday()
+		sameTime = sameTime && tf.Clock(s.Start) == tf.Clock(first) && tf.Clock(s.End) == tf.Clock(spans[0].End)
+	}
+
+	var pattern string
+	weekday := first.Weekday().String()
+	switch gap, even := evenGapDays(spans); {
+	case len(spans) == 1:
+		pattern = "once, on " + tf.ShortDate(first)
+	case even && gap == 7:
+		pattern = "every " + weekday
+	case even && gap == 14:
+		pattern = "every other " + weekday
+	case even && gap%7 == 0:
+		pattern = fmt.Sprintf("every %d weeks on %s", gap/7, weekday)
+	case sameDay:
+		pattern = fmt.Sprintf("on %d %ss", len(spans), weekday)
+	default:
+		pattern = fmt.Sprintf("on %d days", len(spans))
+	}
+	if sameTime {
+		pattern += fmt.Sprintf(", %s–%s", tf.Clock(first), tf.Clock(spans[0].End))
+	}
+	return pattern
+}
+
+// evenGapDays returns the number of calendar days between consecutive spans
+// and whether it is always the same.
+func evenGapDays(spans []TimeSlot) (int, bool) {
+	if len(spans) < 2 {
+		return 0, false
+	}
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	gap := int(day(spans[1].Start).Sub(day(spans[0].Start)).Hours() / 24)
+	for i := 2; i < len(spans); i++ {
+		if int(day(spans[i].Start).Sub(day(spans[i-1].Start)).Hours()/24) != gap {
+			return gap, false
+		}
+	}
+	return gap, true
+}
+
+// seriesShift returns the smallest shift, in availabilityStep steps, that
+// moves every occurrence off busy time while keeping it within
+// overlapWorkStart–overlapWorkEnd on its day. busy must not include the
+// series' own occurrences.
+func seriesShift(occurrences, busy []TimeSlot, loc *time.Location) (time.Duration, bool) {
+	fits := func(delta time.Duration) bool {
+		for _, o := range occurrences {
+			start, end := o.Start.Add(delta).In(loc), o.End.Add(delta).In(loc)
+			day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+			if start.Before(atClock(day, overlapWorkStart)) || end.After(atClock(day, overlapWorkEnd)) || overlapsAny(busy, start, end) {
+				return false
+			}
+		}
+		return true
+	}
+	for step := availabilityStep; step <= overlapWorkEnd-overlapWorkStart; step += availabilityStep {
+		for _, delta := range []time.Duration{step, -step} {
+			if fits(delta) {
+				return delta, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// seriesResolutions fills in the series-level fixes of a conflict: moving the
+// less important series (else the other one) to the nearest time none of its
+// occurrences collide, skipping only the colliding occurrences, or declining
+// the whole series when the user is a guest. events are all the events in
+// the range checked.
+func (ct *CalendarTools) seriesResolutions(sc *SeriesConflict, events []*calendar.Event, calendarID string, loc *time.Location, tf TimeFormat) {
+	lower := sc.events[1]
+	seriesID := sc.SeriesIDs[1]
+	if r, ok := declineResolution(lower, calendarID); ok {
+		r.EventID = seriesID
+		r.Description = fmt.Sprintf("Decline every occurrence of '%s'", sc.Summaries[1])
+		r.Arguments["event_id"] = seriesID
+		r.Arguments["scope"] = scopeSeries
+		sc.Resolutions = append(sc.Resolutions, r)
+	}
+
+	moved := false
+	for _, k := range []int{1, 0} {
+		if eventPriorityLevel(sc.events[k]) == priorityHigh {
+			continue
+		}
+		master, err := ct.client.GetEvent(calendarID, sc.SeriesIDs[k])
+		if err != nil {
+			sc.Notes = append(sc.Notes, fmt.Sprintf("'%s' could not be read: %v", sc.Summaries[k], err))
+			continue
+		}
+		access := ct.client.EventAccess(calendarID, master)
+		if access.Level != accessOrganizer && access.Level != accessGuestEditor {
+			continue
+		}
+
+		var own, busy []TimeSlot
+		for _, e := range events {
+			start, end, _, err := parseEventTimes(e)
+			if err != nil || !blocksTime(e) {
+				continue
+			}
+			if e.RecurringEventId == sc.SeriesIDs[k] {
+				own = append(own, TimeSlot{Start: start, End: end})
+			} else {
+				busy = append(busy, TimeSlot{Start: start, End: end})
+			}
+		}
+
+		if !moved {
+			if r, ok := seriesMoveResolution(master, sc.Summaries[k], own, busy, calendarID, loc, tf); ok {
+				sc.Resolutions = append(sc.Resolutions, r)
+				moved = true
+			}
+		}
+		// Skipping makes sense only while other occurrences remain
+		if k == 1 && access.Level == accessOrganizer && len(sc.Dates) < len(own) {
+			sc.Resolutions = append(sc.Resolutions, OverlapResolution{
+				Kind:        resolveSkip,
+				EventID:     sc.SeriesIDs[k],
+				Description: fmt.Sprintf("Skip the %d colliding occurrence(s) of '%s'; the other %d stay", len(sc.Dates), sc.Summaries[k], len(own)-len(sc.Dates)),
+				Tool:        "series_modify",
+				Arguments: map[string]interface{}{
+					"calendar_id": calendarID,
+					"event_id":    sc.SeriesIDs[k],
+					"action":      "skip",
+					"dates":       sc.Dates,
+				},
+			})
+		}
+	}
+	if !moved {
+		sc.Notes = append(sc.Notes, "Neither series can be moved to a time that is free every week: you may not edit it, it is high priority, or no such time exists between "+
+			formatClock(overlapWorkStart)+" and "+formatClock(overlapWorkEnd))
+	}
+}
+
+// seriesMoveResolution moves a whole series by the smallest shift that frees
+// all of its occurrences in the range.
+func seriesMoveResolution(master *calendar.Event, summary string, own, busy []TimeSlot, calendarID string, loc *time.Location, tf TimeFormat) (OverlapResolution, bool) {
+	start, end, allDay, err := parseEventTimes(master)
+	if err != nil || allDay || len(own) == 0 {
+		return OverlapResolution{}, false
+	}
+	delta, ok := seriesShift(own, busy, loc)
+	if !ok {
+		return OverlapResolution{}, false
+	}
+	seriesLoc := seriesLocation(master)
+	arguments := map[string]interface{}{
+		"calendar_id": calendarID,
+		"event_id":    master.Id,
+		"scope":       scopeSeries,
+		"start_time":  start.Add(delta).In(seriesLoc).Format(time.RFC3339),
+		"end_time":    end.Add(delta).In(seriesLoc).Format(time.RFC3339),
+	}
+	if master.Start.TimeZone != "" {
+		arguments["timezone"] = master.Start.TimeZone
+	}
+	first := own[0]
+	return OverlapResolution{
+		Kind:    resolveMove,
+		EventID: master.Id,
+		Description: fmt.Sprintf("Move every occurrence of '%s' from %s–%s to %s–%s, free of conflicts on your calendar for all %d occurrence(s) checked",
+			summary, tf.Clock(first.Start.In(loc)), tf.Clock(first.End.In(loc)), tf.Clock(first.Start.Add(delta).In(loc)), tf.Clock(first.End.Add(delta).In(loc)), len(own)),
+		Tool:      "edit_event",
+		Arguments: arguments,
+	}, true
+}
+
+func (ct *CalendarTools) handleFindSeriesConflicts(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	weeks := getIntOrDefault(arguments, "weeks", defaultSeriesConflictWeeks)
+	if weeks < 1 || weeks > maxSeriesConflictWeeks {
+		return nil, fmt.Errorf("weeks must be between 1 and %d", maxSeriesConflictWeeks)
+	}
+	calendarID := ct.calendarID(arguments)
+	loc, err := ct.goalLocation(calendarID, getStringOrDefault(arguments, "timezone", ""))
+	if err != nil {
+		return nil, err
+	}
+	from := time.Now().In(loc)
+	to := from.AddDate(0, 0, 7*weeks)
+	if err := ct.fetchLimits.checkRange(from, to); err != nil {
+		return nil, err
+	}
+
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      from,
+		TimeMax:      to,
+		TimeZone:     loc.String(),
+		MaxResults:   2500,
+		SingleEvents: true,
+		OrderBy:      "startTime",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	tf := ct.client.TimeFormat()
+	conflicts := groupSeriesConflicts(findOverlapConflicts(events.Items, false), events.Items, loc)
+	for i := range conflicts {
+		conflicts[i].Pattern = conflictPattern(conflicts[i].spans, tf)
+		ct.seriesResolutions(&conflicts[i], events.Items, calendarID, loc, tf)
+	}
+
+	return structuredResult(formatSeriesConflicts(conflicts, weeks, loc, tf), map[string]interface{}{
+		"calendar_id": calendarID,
+		"weeks":       weeks,
+		"time_zone":   loc.String(),
+		"conflicts":   conflicts,
+	}), nil
+}
+
+// formatSeriesConflicts renders each pair of colliding series once, with the
+// pattern of the collisions and the calls that fix the whole series.
+func formatSeriesConflicts(conflicts []SeriesConflict, weeks int, loc *time.Location, tf TimeFormat) string {
+	var result strings.Builder
+	if len(conflicts) == 0 {
+		fmt.Fprintf(&result, "✅ No recurring series collide in the next %d weeks (%s).", weeks, loc.String())
+		return result.String()
+	}
+	fmt.Fprintf(&result, "🔁 %d pair(s) of recurring series collide in the next %d weeks (%s):\n", len(conflicts), weeks, loc.String())
+	for _, c := range conflicts {
+		fmt.Fprintf(&result, "\n• '%s' and '%s' collide %s (up to %d min): %d of the %d occurrence(s) of '%s'",
+			c.Summaries[0], c.Summaries[1], c.Pattern, c.Minutes, len(c.Dates), c.Occurrences, c.Summaries[1])
+		dates := make([]string, 0, maxSeriesConflictDates)
+		for i, s := range c.spans {
+			if i == maxSeriesConflictDates {
+				dates = append(dates, fmt.Sprintf("and %d more", len(c.spans)-i))
+				break
+			}
+			dates = append(dates, tf.MonthDay(s.Start))
+		}
+		fmt.Fprintf(&result, " (%s). '%s' looks less important: %s\n", strings.Join(dates, ", "), c.Summaries[1], c.Reason)
+		for i, r := range c.Resolutions {
+			args, _ := json.Marshal(r.Arguments)
+			fmt.Fprintf(&result, "  %d. %s\n     → %s %s\n", i+1, r.Description, r.Tool, string(args))
+		}
+		for _, note := range c.Notes {
+			fmt.Fprintf(&result, "  ℹ️ %s\n", note)
+		}
+	}
+	return result.String()
+}
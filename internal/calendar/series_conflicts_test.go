@@ -0,0 +1,168 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- conflictPattern -----
+
+func TestConflictPattern(t *testing.T) {
+	tuesday := time.Date(2025, 3, 4, 10, 30, 0, 0, time.UTC)
+	spans := func(days ...int) []TimeSlot {
+		var s []TimeSlot
+		for _, d := range days {
+			start := tuesday.AddDate(0, 0, d)
+			s = append(s, TimeSlot{Start: start, End: start.Add(30 * time.Minute)})
+		}
+		return s
+	}
+	tf := TimeFormat{}
+
+	tests := []struct {
+		name  string
+		spans []TimeSlot
+		want  string
+	}{
+		{"weekly", spans(0, 7, 14), "every Tuesday, "},
+		{"biweekly", spans(0, 14, 28), "every other Tuesday, "},
+		{"every three weeks", spans(0, 21), "every 3 weeks on Tuesday, "},
+		{"uneven Tuesdays", spans(0, 7, 21), "on 3 Tuesdays, "},
+		{"different days", spans(0, 1, 7), "on 3 days, "},
+		{"once", spans(0), "once, on "},
+	}
+	for _, tt := range tests {
+		if got := conflictPattern(tt.spans, tf); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s: got %q, want prefix %q", tt.name, got, tt.want)
+		}
+	}
+
+	moved := spans(0, 14)
+	moved[1].End = moved[1].End.Add(time.Hour)
+	if got := conflictPattern(moved, tf); got != "every other Tuesday" {
+		t.Errorf("varying times: got %q, want no time of day", got)
+	}
+}
+
+// ----- groupSeriesConflicts -----
+
+func TestGroupSeriesConflicts(t *testing.T) {
+	occurrence := func(series string, day int, start, end string) *calendar.Event {
+		e := overlapEvent(fmt.Sprintf("%s_%d", series, day), start, end)
+		e.RecurringEventId = series
+		e.Start.DateTime = fmt.Sprintf("2025-03-%02dT%s:00Z", 3+day, start)
+		e.End.DateTime = fmt.Sprintf("2025-03-%02dT%s:00Z", 3+day, end)
+		return e
+	}
+	events := []*calendar.Event{
+		occurrence("staff", 0, "10:00", "11:00"),
+		occurrence("review", 0, "10:30", "11:30"),
+		occurrence("single", 1, "10:00", "11:00"),
+		occurrence("staff", 1, "10:00", "11:00"),
+		occurrence("review", 2, "10:30", "11:30"),
+		occurrence("staff", 2, "10:00", "11:00"),
+	}
+	events[2].RecurringEventId = ""
+
+	grouped := groupSeriesConflicts(findOverlapConflicts(events, false), events, time.UTC)
+	if len(grouped) != 1 {
+		t.Fatalf("got %d series conflicts, want 1: %+v", len(grouped), grouped)
+	}
+	g := grouped[0]
+	if g.SeriesIDs[0] != "staff" || g.SeriesIDs[1] != "review" || g.Occurrences != 2 || g.Minutes != 30 {
+		t.Errorf("unexpected series conflict %+v", g)
+	}
+	if len(g.Dates) != 2 || g.Dates[0] != "2025-03-03" || g.Dates[1] != "2025-03-05" {
+		t.Errorf("dates = %v", g.Dates)
+	}
+}
+
+// ----- seriesShift -----
+
+func TestSeriesShift(t *testing.T) {
+	at := func(day, h, m int) time.Time { return time.Date(2025, 3, day, h, m, 0, 0, time.UTC) }
+	own := []TimeSlot{{at(4, 10, 0), at(4, 11, 0)}, {at(11, 10, 0), at(11, 11, 0)}}
+
+	busy := []TimeSlot{{at(4, 10, 30), at(4, 11, 30)}, {at(11, 9, 0), at(11, 10, 30)}}
+	if delta, ok := seriesShift(own, busy, time.UTC); !ok || delta != 90*time.Minute {
+		t.Errorf("got %v, %v; want 1h30m, the first shift free on both days", delta, ok)
+	}
+
+	busy = []TimeSlot{{at(4, 9, 0), at(4, 17, 0)}}
+	if _, ok := seriesShift(own, busy, time.UTC); ok {
+		t.Error("expected no shift when one day is fully booked")
+	}
+}
+
+// ----- find_series_conflicts -----
+
+func TestFindSeriesConflicts(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	tuesday := today.AddDate(0, 0, (int(time.Tuesday)-int(today.Weekday())+7)%7+7)
+	insert := func(summary string, start time.Time, rule string) *calendar.Event {
+		ev, err := store.InsertEvent("primary", &calendar.Event{
+			Summary:    summary,
+			Start:      &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: "UTC"},
+			End:        &calendar.EventDateTime{DateTime: start.Add(time.Hour).Format(time.RFC3339), TimeZone: "UTC"},
+			Recurrence: []string{rule},
+		}, 0)
+		if err != nil {
+			t.Fatalf("InsertEvent: %v", err)
+		}
+		return ev
+	}
+	insert("Staff", tuesday.Add(10*time.Hour), "RRULE:FREQ=WEEKLY;BYDAY=TU")
+	review := insert("Review", tuesday.Add(10*time.Hour+30*time.Minute), "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU")
+
+	result, err := ct.HandleTool("find_series_conflicts", map[string]interface{}{"weeks": 8})
+	if err != nil {
+		t.Fatalf("find_series_conflicts: %v", err)
+	}
+	data := result.StructuredContent.(map[string]interface{})
+	conflicts := data["conflicts"].([]SeriesConflict)
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d series conflicts, want 1: %+v", len(conflicts), conflicts)
+	}
+	c := conflicts[0]
+	if c.SeriesIDs[1] != review.Id || !strings.HasPrefix(c.Pattern, "every other Tuesday") || len(c.Dates) != c.Occurrences {
+		t.Errorf("unexpected series conflict %+v", c)
+	}
+	if len(c.Resolutions) != 1 || c.Resolutions[0].Kind != resolveMove {
+		t.Fatalf("resolutions = %+v, want one move", c.Resolutions)
+	}
+	move := c.Resolutions[0].Arguments
+	if move["event_id"] != review.Id || move["scope"] != scopeSeries || move["start_time"] != tuesday.Add(11*time.Hour).Format(time.RFC3339) {
+		t.Errorf("move = %v, want the whole series at 11:00", move)
+	}
+
+	if _, err := ct.HandleTool("find_series_conflicts", map[string]interface{}{"weeks": 20}); err == nil {
+		t.Error("expected an error for more than 13 weeks")
+	}
+}
@@ -0,0 +1,699 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// Actions accepted by series_modify.
+const (
+	seriesEnd   = "end"   // stop the series after a date (sets UNTIL)
+	seriesSkip  = "skip"  // drop upcoming occurrences (adds EXDATEs)
+	seriesSplit = "split" // change the rule from a date on (ends the series, starts a new one)
+)
+
+const (
+	// seriesHorizonYears bounds how far ahead series_modify looks for
+	// occurrences and modified exceptions
+	seriesHorizonYears = 2
+	// maxSkipOccurrences bounds how many occurrences one skip removes
+	maxSkipOccurrences = 52
+)
+
+// SeriesChange reports what series_modify did to a recurring series.
+type SeriesChange struct {
+	Action        string            `json:"action"`
+	SeriesID      string            `json:"series_id"`
+	Summary       string            `json:"summary"`
+	Recurrence    []string          `json:"recurrence"`
+	NewSeriesID   string            `json:"new_series_id,omitempty"`
+	NewStart      string            `json:"new_start,omitempty"`
+	NewRecurrence []string          `json:"new_recurrence,omitempty"`
+	Skipped       []string          `json:"skipped,omitempty"`   // dates of the occurrences removed
+	Cancelled     []string          `json:"cancelled,omitempty"` // modified occurrences cancelled one by one
+	Dropped       []SeriesException `json:"dropped_exceptions,omitempty"`
+	Notes         []string          `json:"notes,omitempty"`
+}
+
+// SeriesException is an occurrence changed on its own, apart from the series.
+type SeriesException struct {
+	EventID string `json:"event_id"`
+	Date    string `json:"date"`   // original date of the occurrence
+	Change  string `json:"change"` // "moved", "edited" or "cancelled"
+}
+
+// SeriesOccurrences returns the occurrences of a series whose time falls in
+// [from, to), including cancelled ones, in order.
+func (c *Client) SeriesOccurrences(calendarID, seriesID string, from, to time.Time) ([]*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	call := c.service.Events.Instances(calendarID, seriesID).
+		TimeMin(from.Format(time.RFC3339)).
+		TimeMax(to.Format(time.RFC3339)).
+		ShowDeleted(true).
+		MaxResults(250).
+		Fields(googleapi.Field("items(" + eventDetailFields + "),nextPageToken"))
+
+	var instances []*calendar.Event
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get occurrences: %v", err)
+		}
+		instances = append(instances, page.Items...)
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return instances, nil
+}
+
+// StartSeries creates a copy of master that starts at start and recurs by
+// recurrence. Guests are invited afresh, keeping the user's own response,
+// and an existing conference is carried over so the meeting link stays.
+func (c *Client) StartSeries(calendarID string, master *calendar.Event, start time.Time, recurrence []string, sendUpdates string) (*calendar.Event, error) {
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	masterStart, allDay, err := eventStart(master.Start)
+	if err != nil {
+		return nil, err
+	}
+	masterEnd, _, err := eventStart(master.End)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &calendar.Event{
+		Summary:         master.Summary,
+		Description:     master.Description,
+		Location:        master.Location,
+		Visibility:      master.Visibility,
+		Transparency:    master.Transparency,
+		ColorId:         master.ColorId,
+		GuestsCanModify: master.GuestsCanModify,
+		Reminders:       master.Reminders,
+		ConferenceData:  master.ConferenceData,
+		Recurrence:      recurrence,
+	}
+	end := start.Add(masterEnd.Sub(masterStart))
+	if allDay {
+		event.Start = &calendar.EventDateTime{Date: start.Format("2006-01-02")}
+		event.End = &calendar.EventDateTime{Date: end.Format("2006-01-02")}
+	} else {
+		event.Start = &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: master.Start.TimeZone}
+		event.End = &calendar.EventDateTime{DateTime: end.Format(time.RFC3339), TimeZone: master.End.TimeZone}
+	}
+	for _, a := range master.Attendees {
+		attendee := &calendar.EventAttendee{
+			Email:          a.Email,
+			DisplayName:    a.DisplayName,
+			Optional:       a.Optional,
+			Resource:       a.Resource,
+			ResponseStatus: "needsAction",
+		}
+		if a.Self {
+			attendee.ResponseStatus = a.ResponseStatus
+		}
+		event.Attendees = append(event.Attendees, attendee)
+	}
+
+	call := c.service.Events.Insert(calendarID, event)
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	if event.ConferenceData != nil {
+		call = call.ConferenceDataVersion(1)
+	}
+	return call.Do()
+}
+
+// eventStart parses an event start or end. All-day dates are UTC midnight.
+func eventStart(dt *calendar.EventDateTime) (time.Time, bool, error) {
+	if dt == nil {
+		return time.Time{}, false, fmt.Errorf("event has no start or end time")
+	}
+	if dt.Date != "" {
+		t, err := time.Parse("2006-01-02", dt.Date)
+		return t, true, err
+	}
+	t, err := time.Parse(time.RFC3339, dt.DateTime)
+	return t, false, err
+}
+
+// seriesLocation returns the time zone a series recurs in: its own, else the
+// offset of its start time.
+func seriesLocation(master *calendar.Event) *time.Location {
+	if master.Start != nil && master.Start.TimeZone != "" {
+		if loc, err := loadLocation(master.Start.TimeZone); err == nil {
+			return loc
+		}
+	}
+	if start, _, err := eventStart(master.Start); err == nil {
+		return start.Location()
+	}
+	return time.UTC
+}
+
+// originalStart returns when an occurrence was scheduled by the series rule,
+// before any move, and whether it is an all-day occurrence.
+func originalStart(inst *calendar.Event) (time.Time, bool, error) {
+	if inst.OriginalStartTime != nil {
+		return eventStart(inst.OriginalStartTime)
+	}
+	return eventStart(inst.Start)
+}
+
+// occurrenceDay returns the original date of an occurrence in loc, as YYYY-MM-DD.
+func occurrenceDay(inst *calendar.Event, loc *time.Location) string {
+	start, allDay, err := originalStart(inst)
+	if err != nil {
+		return ""
+	}
+	if allDay {
+		return start.Format("2006-01-02")
+	}
+	return start.In(loc).Format("2006-01-02")
+}
+
+// exceptionKind says how an occurrence differs from its series, or returns
+// "" if it is unmodified.
+func exceptionKind(inst, master *calendar.Event) string {
+	switch {
+	case inst.Status == "cancelled":
+		return "cancelled"
+	case wasRescheduled(inst):
+		return "moved"
+	case inst.Summary != master.Summary || inst.Location != master.Location || inst.Description != master.Description:
+		return "edited"
+	default:
+		return ""
+	}
+}
+
+// checkRecurrence validates the recurrence lines of a new series rule.
+func checkRecurrence(lines []string) error {
+	hasRule := false
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "RRULE:"):
+			hasRule = true
+		case strings.HasPrefix(line, "EXDATE"), strings.HasPrefix(line, "RDATE"):
+		default:
+			return fmt.Errorf("invalid recurrence line %q (expected RRULE:, EXDATE or RDATE)", line)
+		}
+	}
+	if !hasRule {
+		return fmt.Errorf("recurrence must include an RRULE line, e.g. 'RRULE:FREQ=WEEKLY;INTERVAL=2'")
+	}
+	return nil
+}
+
+// endRecurrence rewrites the RRULE in recurrence to stop at until, dropping
+// any COUNT or earlier UNTIL. Other lines are kept.
+func endRecurrence(recurrence []string, until string) ([]string, error) {
+	var result []string
+	found := false
+	for _, line := range recurrence {
+		if !strings.HasPrefix(line, "RRULE:") {
+			result = append(result, line)
+			continue
+		}
+		found = true
+		var parts []string
+		for _, part := range strings.Split(strings.TrimPrefix(line, "RRULE:"), ";") {
+			key, _, _ := strings.Cut(part, "=")
+			if strings.EqualFold(key, "COUNT") || strings.EqualFold(key, "UNTIL") {
+				continue
+			}
+			parts = append(parts, part)
+		}
+		parts = append(parts, "UNTIL="+until)
+		result = append(result, "RRULE:"+strings.Join(parts, ";"))
+	}
+	if !found {
+		return nil, fmt.Errorf("the series has no RRULE to end")
+	}
+	return result, nil
+}
+
+// untilBefore returns the RRULE UNTIL value that keeps every occurrence
+// before cut and none from it on. For all-day series cut is a UTC midnight.
+func untilBefore(cut time.Time, allDay bool) string {
+	if allDay {
+		return cut.AddDate(0, 0, -1).Format("20060102")
+	}
+	return cut.Add(-time.Second).UTC().Format("20060102T150405Z")
+}
+
+// exdateLine returns the EXDATE line removing the occurrence that starts at
+// start, in the series' time zone when it has one.
+func exdateLine(start time.Time, allDay bool, timeZone string) string {
+	switch {
+	case allDay:
+		return "EXDATE;VALUE=DATE:" + start.Format("20060102")
+	case timeZone != "":
+		if loc, err := loadLocation(timeZone); err == nil {
+			return "EXDATE;TZID=" + timeZone + ":" + start.In(loc).Format("20060102T150405")
+		}
+	}
+	return "EXDATE:" + start.UTC().Format("20060102T150405Z")
+}
+
+// addExdates appends the EXDATE lines not already in recurrence.
+func addExdates(recurrence, lines []string) []string {
+	result := append([]string{}, recurrence...)
+	for _, line := range lines {
+		if !containsString(result, line) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// exdatesFrom returns the EXDATE lines of recurrence that exclude any date
+// on or after from. A day of slack keeps exclusions written in another time
+// zone; an extra one before the new series starts has no effect.
+func exdatesFrom(recurrence []string, from time.Time) []string {
+	since := from.AddDate(0, 0, -1).Format("20060102")
+	var lines []string
+	for _, line := range recurrence {
+		_, values, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(line, "EXDATE") {
+			continue
+		}
+		for _, value := range strings.Split(values, ",") {
+			if len(value) >= 8 && value[:8] >= since {
+				lines = append(lines, line)
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// droppedExceptions returns the modified occurrences on or after day that no
+// longer belong to the series once it ends before day. Cancelled occurrences
+// are left out: they stay cancelled either way.
+func droppedExceptions(master *calendar.Event, instances []*calendar.Event, day string, loc *time.Location) []SeriesException {
+	var dropped []SeriesException
+	for _, inst := range instances {
+		d := occurrenceDay(inst, loc)
+		kind := exceptionKind(inst, master)
+		if d < day || kind == "" || kind == "cancelled" {
+			continue
+		}
+		dropped = append(dropped, SeriesException{EventID: inst.Id, Date: d, Change: kind})
+	}
+	return dropped
+}
+
+// pickSkipped chooses the occurrences a skip removes: those on dates, or the
+// next count occurrences after now. Cancelled occurrences are never picked;
+// dates with no occurrence, or only a cancelled one, are returned as notes.
+func pickSkipped(instances []*calendar.Event, dates []string, count int, now time.Time, loc *time.Location) ([]*calendar.Event, []string, error) {
+	var upcoming []*calendar.Event
+	for _, inst := range instances {
+		if start, _, err := originalStart(inst); err == nil && start.After(now) {
+			upcoming = append(upcoming, inst)
+		}
+	}
+
+	var picked []*calendar.Event
+	var notes []string
+	if len(dates) == 0 {
+		for _, inst := range upcoming {
+			if len(picked) == count {
+				break
+			}
+			if inst.Status != "cancelled" {
+				picked = append(picked, inst)
+			}
+		}
+		if len(picked) < count {
+			notes = append(notes, fmt.Sprintf("only %d upcoming occurrences were found to skip", len(picked)))
+		}
+		return picked, notes, nil
+	}
+
+	var missing []string
+	for _, date := range dates {
+		found := false
+		for _, inst := range upcoming {
+			if occurrenceDay(inst, loc) != date {
+				continue
+			}
+			found = true
+			if inst.Status == "cancelled" {
+				notes = append(notes, fmt.Sprintf("the occurrence on %s is already cancelled", date))
+			} else {
+				picked = append(picked, inst)
+			}
+		}
+		if !found {
+			missing = append(missing, date)
+		}
+	}
+	if len(missing) > 0 {
+		var known []string
+		for _, inst := range upcoming {
+			if inst.Status != "cancelled" && len(known) < 5 {
+				known = append(known, occurrenceDay(inst, loc))
+			}
+		}
+		return nil, nil, fmt.Errorf("the series has no upcoming occurrence on %s (next occurrences: %s)", strings.Join(missing, ", "), strings.Join(known, ", "))
+	}
+	return picked, notes, nil
+}
+
+// stringArguments returns the string array argument key, or nil if it is absent.
+func stringArguments(arguments map[string]interface{}, key string) ([]string, error) {
+	values, ok := arguments[key].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	var result []string
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("all %s values must be strings", key)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// parseDay parses a YYYY-MM-DD argument as local midnight in loc.
+func parseDay(name, value string, loc *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q (expected YYYY-MM-DD)", name, value)
+	}
+	return day, nil
+}
+
+// formatSeriesChange renders the outcome, followed by the structured data.
+func formatSeriesChange(change SeriesChange, sendUpdates string) string {
+	var result strings.Builder
+	title := change.Summary
+	if title == "" {
+		title = "(No Title)"
+	}
+	fmt.Fprintf(&result, "🔁 Updated recurring series '%s' (%s):\n\n", title, describeSendUpdates(sendUpdates))
+	fmt.Fprintf(&result, "• Series %s: %s\n", change.SeriesID, strings.Join(change.Recurrence, " "))
+	if len(change.Skipped) > 0 {
+		fmt.Fprintf(&result, "• Skipped: %s\n", strings.Join(change.Skipped, ", "))
+	}
+	if len(change.Cancelled) > 0 {
+		fmt.Fprintf(&result, "• Cancelled individually (modified occurrences): %s\n", strings.Join(change.Cancelled, ", "))
+	}
+	if change.NewSeriesID != "" {
+		fmt.Fprintf(&result, "• New series %s from %s: %s\n", change.NewSeriesID, change.NewStart, strings.Join(change.NewRecurrence, " "))
+	}
+	if len(change.Dropped) > 0 {
+		var items []string
+		for _, d := range change.Dropped {
+			items = append(items, fmt.Sprintf("%s (%s, %s)", d.Date, d.Change, d.EventID))
+		}
+		fmt.Fprintf(&result, "\n⚠️ Modified occurrences no longer part of the series: %s. Recreate or re-apply these changes if they still matter.\n", strings.Join(items, "; "))
+	}
+	for _, note := range change.Notes {
+		fmt.Fprintf(&result, "\n⚠️ %s\n", note)
+	}
+
+	changeJSON, _ := json.MarshalIndent(change, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(changeJSON))
+	return result.String()
+}
+
+func (ct *CalendarTools) handleSeriesModify(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID, ok := arguments["event_id"].(string)
+	if !ok || eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	calendarID := ct.calendarID(arguments)
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := ct.client.getEventForChange(calendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	master := event
+	if event.RecurringEventId != "" {
+		if master, err = ct.client.GetEvent(calendarID, event.RecurringEventId); err != nil {
+			return nil, fmt.Errorf("failed to get recurring series: %v", err)
+		}
+	}
+	if len(master.Recurrence) == 0 {
+		return nil, fmt.Errorf("event %s is not part of a recurring series", eventID)
+	}
+	if err := checkETag(getStringOrDefault(arguments, "etag", ""), master); err != nil {
+		return nil, err
+	}
+
+	title := master.Summary
+	if title == "" {
+		title = "(No Title)"
+	}
+	access := ct.client.EventAccess(calendarID, master)
+	if err := access.checkEdit(title, PatchEventParams{HasRecurrence: true}, master); err != nil {
+		return nil, err
+	}
+
+	loc := seriesLocation(master)
+	masterStart, allDay, err := eventStart(master.Start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read series start: %v", err)
+	}
+
+	change := SeriesChange{
+		Action:   getStringOrDefault(arguments, "action", ""),
+		SeriesID: master.Id,
+		Summary:  master.Summary,
+	}
+	var recurrence []string
+	var cancel []*calendar.Event
+	var splitStart time.Time
+
+	switch change.Action {
+	case seriesEnd:
+		lastDay, err := parseDay("last_date", getStringOrDefault(arguments, "last_date", ""), loc)
+		if err != nil {
+			return nil, err
+		}
+		cut := lastDay.AddDate(0, 0, 1)
+		if allDay {
+			cut = time.Date(cut.Year(), cut.Month(), cut.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		if !masterStart.Before(cut) {
+			return nil, fmt.Errorf("'%s' starts after %s, so ending it then would leave no occurrences; delete it with delete_event instead", title, lastDay.Format("2006-01-02"))
+		}
+		instances, err := ct.client.SeriesOccurrences(calendarID, master.Id, lastDay, lastDay.AddDate(seriesHorizonYears, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+		change.Dropped = droppedExceptions(master, instances, cut.Format("2006-01-02"), loc)
+		if recurrence, err = endRecurrence(master.Recurrence, untilBefore(cut, allDay)); err != nil {
+			return nil, err
+		}
+
+	case seriesSkip:
+		dates, err := stringArguments(arguments, "dates")
+		if err != nil {
+			return nil, err
+		}
+		count := getIntOrDefault(arguments, "count", 0)
+		switch {
+		case len(dates) == 0 && count == 0:
+			return nil, fmt.Errorf("skip needs dates or count")
+		case len(dates) > 0 && count != 0:
+			return nil, fmt.Errorf("pass either dates or count, not both")
+		case count < 0 || count > maxSkipOccurrences || len(dates) > maxSkipOccurrences:
+			return nil, fmt.Errorf("at most %d occurrences can be skipped at once", maxSkipOccurrences)
+		}
+		for _, date := range dates {
+			if _, err := parseDay("date", date, loc); err != nil {
+				return nil, err
+			}
+		}
+
+		now := time.Now()
+		instances, err := ct.client.SeriesOccurrences(calendarID, master.Id, now, now.AddDate(seriesHorizonYears, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+		picked, notes, err := pickSkipped(instances, dates, count, now, loc)
+		if err != nil {
+			return nil, err
+		}
+		change.Notes = notes
+		if len(picked) == 0 {
+			return nil, fmt.Errorf("no occurrences to skip: %s", strings.Join(notes, "; "))
+		}
+
+		// Unmodified occurrences are excluded from the rule; modified ones are
+		// cancelled directly, since an EXDATE only matches the rule's own times
+		var exdates []string
+		for _, inst := range picked {
+			change.Skipped = append(change.Skipped, occurrenceDay(inst, loc))
+			if exceptionKind(inst, master) != "" {
+				cancel = append(cancel, inst)
+				continue
+			}
+			start, instAllDay, err := originalStart(inst)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read occurrence %s: %v", inst.Id, err)
+			}
+			exdates = append(exdates, exdateLine(start, instAllDay, master.Start.TimeZone))
+		}
+		recurrence = addExdates(master.Recurrence, exdates)
+
+	case seriesSplit:
+		fromDay, err := parseDay("from_date", getStringOrDefault(arguments, "from_date", ""), loc)
+		if err != nil {
+			return nil, err
+		}
+		newRule, err := stringArguments(arguments, "recurrence")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRecurrence(newRule); err != nil {
+			return nil, err
+		}
+
+		// Look from the day before so all-day occurrences are seen in any time zone
+		instances, err := ct.client.SeriesOccurrences(calendarID, master.Id, fromDay.AddDate(0, 0, -1), fromDay.AddDate(seriesHorizonYears, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+		day := fromDay.Format("2006-01-02")
+		var first *calendar.Event
+		var cancelled []string
+		for _, inst := range instances {
+			if occurrenceDay(inst, loc) < day {
+				continue
+			}
+			if first == nil {
+				first = inst
+			}
+			// Occurrences already cancelled stay cancelled under the new rule
+			if inst.Status == "cancelled" {
+				start, instAllDay, err := originalStart(inst)
+				if err == nil {
+					cancelled = append(cancelled, exdateLine(start, instAllDay, master.Start.TimeZone))
+				}
+			}
+		}
+		if first == nil {
+			return nil, fmt.Errorf("'%s' has no occurrences on or after %s", title, day)
+		}
+		splitStart, _, err = originalStart(first)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read occurrence %s: %v", first.Id, err)
+		}
+		change.Dropped = droppedExceptions(master, instances, day, loc)
+		newRecurrence := addExdates(newRule, append(exdatesFrom(master.Recurrence, fromDay), cancelled...))
+
+		if !masterStart.Before(splitStart) {
+			// Nothing precedes the split, so the series simply takes the new rule
+			recurrence = newRecurrence
+			break
+		}
+		if recurrence, err = endRecurrence(master.Recurrence, untilBefore(splitStart, allDay)); err != nil {
+			return nil, err
+		}
+		change.NewRecurrence = newRecurrence
+		change.NewStart = occurrenceDay(first, loc)
+
+	default:
+		return nil, fmt.Errorf("invalid action %q (expected 'end', 'skip' or 'split')", change.Action)
+	}
+
+	change.Recurrence = master.Recurrence
+	// Skipping only modified occurrences leaves the rule as it is
+	if strings.Join(recurrence, "\n") != strings.Join(master.Recurrence, "\n") {
+		// Patch the rule of the master read above, not a later version
+		updated, err := ct.client.PatchEventDirect(master.Id, PatchEventParams{
+			CalendarID:    calendarID,
+			Recurrence:    recurrence,
+			HasRecurrence: true,
+			SendUpdates:   sendUpdates,
+			ETag:          master.Etag,
+		})
+		if err != nil {
+			if isForbidden(err) {
+				return nil, access.explainForbidden(err, "change", title)
+			}
+			return nil, fmt.Errorf("failed to update series '%s': %v", title, err)
+		}
+		change.Recurrence = updated.Recurrence
+	}
+
+	for _, inst := range cancel {
+		if err := ct.client.DeleteEvent(calendarID, inst.Id, sendUpdates); err != nil {
+			change.Notes = append(change.Notes, fmt.Sprintf("failed to cancel the modified occurrence %s: %v", inst.Id, err))
+			continue
+		}
+		change.Cancelled = append(change.Cancelled, inst.Id)
+	}
+
+	if change.NewRecurrence != nil {
+		created, err := ct.client.StartSeries(calendarID, master, splitStart, change.NewRecurrence, sendUpdates)
+		if err != nil {
+			// Put the old rule back so the occurrences from the split on are not lost
+			_, restoreErr := ct.client.PatchEventDirect(master.Id, PatchEventParams{
+				CalendarID:    calendarID,
+				Recurrence:    master.Recurrence,
+				HasRecurrence: true,
+				SendUpdates:   sendUpdates,
+			})
+			if restoreErr != nil {
+				return nil, fmt.Errorf("failed to start the new series: %v; '%s' now ends before %s and could not be restored (%v) — restore its recurrence to %s with edit_event", err, title, change.NewStart, restoreErr, strings.Join(master.Recurrence, " "))
+			}
+			return nil, fmt.Errorf("failed to start the new series: %v; '%s' was left unchanged", err, title)
+		}
+		change.NewSeriesID = created.Id
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: formatSeriesChange(change, sendUpdates),
+		}},
+	}, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// occurrence builds an instance of series originally starting at original.
+func occurrence(series, original string) *calendar.Event {
+	return &calendar.Event{
+		Id:                series + "_" + original,
+		Summary:           "Sync",
+		RecurringEventId:  series,
+		Start:             &calendar.EventDateTime{DateTime: original},
+		OriginalStartTime: &calendar.EventDateTime{DateTime: original},
+		Status:            "confirmed",
+	}
+}
+
+// ----- endRecurrence -----
+
+func TestEndRecurrence(t *testing.T) {
+	cases := []struct {
+		name       string
+		recurrence []string
+		want       []string
+	}{
+		{"open ended", []string{"RRULE:FREQ=WEEKLY;BYDAY=MO"}, []string{"RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20250301T000000Z"}},
+		{"replaces count", []string{"RRULE:FREQ=DAILY;COUNT=10"}, []string{"RRULE:FREQ=DAILY;UNTIL=20250301T000000Z"}},
+		{"replaces until", []string{"RRULE:FREQ=DAILY;UNTIL=20260101T000000Z;INTERVAL=2"}, []string{"RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20250301T000000Z"}},
+		{"keeps exdates", []string{"EXDATE:20250106T090000Z", "RRULE:FREQ=WEEKLY"}, []string{"EXDATE:20250106T090000Z", "RRULE:FREQ=WEEKLY;UNTIL=20250301T000000Z"}},
+	}
+	for _, tc := range cases {
+		got, err := endRecurrence(tc.recurrence, "20250301T000000Z")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := endRecurrence([]string{"RDATE:20250106T090000Z"}, "20250301T000000Z"); err == nil {
+		t.Error("expected error for a recurrence without RRULE")
+	}
+}
+
+// ----- untilBefore / exdateLine -----
+
+func TestUntilBefore(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	if got := untilBefore(time.Date(2025, 3, 3, 10, 0, 0, 0, paris), false); got != "20250303T085959Z" {
+		t.Errorf("timed: got %s", got)
+	}
+	if got := untilBefore(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), true); got != "20250302" {
+		t.Errorf("all-day: got %s", got)
+	}
+}
+
+func TestExdateLine(t *testing.T) {
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		allDay   bool
+		timeZone string
+		want     string
+	}{
+		{"utc", false, "", "EXDATE:20250303T090000Z"},
+		{"series time zone", false, "Europe/Paris", "EXDATE;TZID=Europe/Paris:20250303T100000"},
+		{"unknown time zone", false, "Nowhere/Else", "EXDATE:20250303T090000Z"},
+		{"all day", true, "Europe/Paris", "EXDATE;VALUE=DATE:20250303"},
+	}
+	for _, tc := range cases {
+		if got := exdateLine(start, tc.allDay, tc.timeZone); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// ----- addExdates / exdatesFrom -----
+
+func TestAddExdates(t *testing.T) {
+	recurrence := []string{"RRULE:FREQ=WEEKLY", "EXDATE:20250303T090000Z"}
+	got := addExdates(recurrence, []string{"EXDATE:20250303T090000Z", "EXDATE:20250310T090000Z"})
+	want := []string{"RRULE:FREQ=WEEKLY", "EXDATE:20250303T090000Z", "EXDATE:20250310T090000Z"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if len(recurrence) != 2 {
+		t.Error("addExdates modified its input")
+	}
+}
+
+func TestExdatesFrom(t *testing.T) {
+	recurrence := []string{
+		"RRULE:FREQ=WEEKLY",
+		"EXDATE:20250106T090000Z",
+		"EXDATE;TZID=Europe/Paris:20250203T100000",
+		"EXDATE;VALUE=DATE:20250120,20250310",
+	}
+	got := exdatesFrom(recurrence, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	want := []string{"EXDATE;TZID=Europe/Paris:20250203T100000", "EXDATE;VALUE=DATE:20250120,20250310"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// ----- checkRecurrence -----
+
+func TestCheckRecurrence(t *testing.T) {
+	if err := checkRecurrence([]string{"RRULE:FREQ=WEEKLY;INTERVAL=2", "EXDATE:20250106T090000Z"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, lines := range [][]string{nil, {"EXDATE:20250106T090000Z"}, {"FREQ=WEEKLY"}} {
+		if err := checkRecurrence(lines); err == nil {
+			t.Errorf("checkRecurrence(%v) expected error", lines)
+		}
+	}
+}
+
+// ----- exceptionKind / droppedExceptions -----
+
+func TestExceptionKind(t *testing.T) {
+	master := &calendar.Event{Id: "s", Summary: "Sync"}
+
+	moved := occurrence("s", "2025-03-03T09:00:00Z")
+	moved.Start = &calendar.EventDateTime{DateTime: "2025-03-03T11:00:00Z"}
+	edited := occurrence("s", "2025-03-10T09:00:00Z")
+	edited.Location = "Room 4"
+	cancelled := occurrence("s", "2025-03-17T09:00:00Z")
+	cancelled.Status = "cancelled"
+
+	cases := []struct {
+		inst *calendar.Event
+		want string
+	}{
+		{occurrence("s", "2025-02-24T09:00:00Z"), ""},
+		{moved, "moved"},
+		{edited, "edited"},
+		{cancelled, "cancelled"},
+	}
+	for _, tc := range cases {
+		if got := exceptionKind(tc.inst, master); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.inst.Id, got, tc.want)
+		}
+	}
+
+	dropped := droppedExceptions(master, []*calendar.Event{cases[0].inst, moved, edited, cancelled}, "2025-03-04", time.UTC)
+	want := []SeriesException{{EventID: edited.Id, Date: "2025-03-10", Change: "edited"}}
+	if !reflect.DeepEqual(dropped, want) {
+		t.Errorf("droppedExceptions = %+v, want %+v", dropped, want)
+	}
+}
+
+// ----- pickSkipped -----
+
+func TestPickSkipped(t *testing.T) {
+	now := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)
+	cancelled := occurrence("s", "2025-03-17T09:00:00Z")
+	cancelled.Status = "cancelled"
+	instances := []*calendar.Event{
+		occurrence("s", "2025-03-03T09:00:00Z"), // past
+		occurrence("s", "2025-03-10T09:00:00Z"),
+		cancelled,
+		occurrence("s", "2025-03-24T09:00:00Z"),
+	}
+	ids := func(events []*calendar.Event) []string {
+		var result []string
+		for _, e := range events {
+			result = append(result, e.Id)
+		}
+		return result
+	}
+
+	picked, notes, err := pickSkipped(instances, nil, 2, now, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"s_2025-03-10T09:00:00Z", "s_2025-03-24T09:00:00Z"}; !reflect.DeepEqual(ids(picked), want) || len(notes) != 0 {
+		t.Errorf("count: got %v (notes %v), want %v", ids(picked), notes, want)
+	}
+
+	picked, notes, _ = pickSkipped(instances, nil, 5, now, time.UTC)
+	if len(picked) != 2 || len(notes) != 1 {
+		t.Errorf("count beyond series: got %v, notes %v", ids(picked), notes)
+	}
+
+	picked, notes, err = pickSkipped(instances, []string{"2025-03-24", "2025-03-17"}, 0, now, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"s_2025-03-24T09:00:00Z"}; !reflect.DeepEqual(ids(picked), want) {
+		t.Errorf("dates: got %v, want %v", ids(picked), want)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "already cancelled") {
+		t.Errorf("dates: expected an already-cancelled note, got %v", notes)
+	}
+
+	if _, _, err := pickSkipped(instances, []string{"2025-03-03"}, 0, now, time.UTC); err == nil || !strings.Contains(err.Error(), "2025-03-10") {
+		t.Errorf("expected error listing upcoming dates for a past date, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func seriesInstance(status, original, start string, responses ...string) *calendar.Event {
+	ev := &calendar.Event{
+		Status:            status,
+		OriginalStartTime: &calendar.EventDateTime{DateTime: original},
+		Start:             &calendar.EventDateTime{DateTime: start},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "me@example.com", Self: true, ResponseStatus: "accepted"},
+			{Email: "room@resource.calendar.google.com", Resource: true, ResponseStatus: "accepted"},
+		},
+	}
+	emails := []string{"alex@example.com", "kim@example.com"}
+	for i, r := range responses {
+		ev.Attendees = append(ev.Attendees, &calendar.EventAttendee{Email: emails[i], ResponseStatus: r})
+	}
+	return ev
+}
+
+// ----- analyzeSeries -----
+
+func TestAnalyzeSeries(t *testing.T) {
+	series := &calendar.Event{Id: "weekly", Summary: "Weekly sync"}
+	instances := []*calendar.Event{
+		seriesInstance("confirmed", "2025-03-03T09:00:00Z", "2025-03-03T09:00:00Z", "accepted", "declined"),
+		seriesInstance("confirmed", "2025-03-10T09:00:00Z", "2025-03-10T11:00:00Z", "accepted", "needsAction"),
+		seriesInstance("cancelled", "2025-03-17T09:00:00Z", "2025-03-17T09:00:00Z"),
+		seriesInstance("confirmed", "2025-03-24T09:00:00Z", "2025-03-24T09:00:00Z", "accepted", "declined"),
+	}
+
+	stats := analyzeSeries(series, instances, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if stats.Occurrences != 4 || stats.Held != 3 || stats.Cancelled != 1 || stats.Rescheduled != 1 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if stats.AvgInvited != 2 || stats.AvgAccepted != 1 || stats.AttendanceRate != 0.5 {
+		t.Errorf("unexpected attendance: invited=%v accepted=%v rate=%v", stats.AvgInvited, stats.AvgAccepted, stats.AttendanceRate)
+	}
+	if stats.CancelRate != 0.25 || stats.RescheduleRate != 0.33 {
+		t.Errorf("unexpected rates: cancel=%v reschedule=%v", stats.CancelRate, stats.RescheduleRate)
+	}
+	if len(stats.Attendees) != 2 || stats.Attendees[0].Email != "kim@example.com" || stats.Attendees[0].Declined != 2 {
+		t.Errorf("expected kim (least engaged) first, got %+v", stats.Attendees)
+	}
+
+	recs := strings.Join(stats.Recommendations, "\n")
+	if !strings.Contains(recs, "Often cancelled") || !strings.Contains(recs, "Often moved") || strings.Contains(recs, "Low attendance") {
+		t.Errorf("unexpected recommendations:\n%s", recs)
+	}
+}
+
+func TestAnalyzeSeries_Healthy(t *testing.T) {
+	series := &calendar.Event{Id: "weekly"}
+	instances := []*calendar.Event{
+		seriesInstance("confirmed", "2025-03-03T09:00:00Z", "2025-03-03T09:00:00Z", "accepted", "accepted"),
+		seriesInstance("confirmed", "2025-03-10T09:00:00Z", "2025-03-10T09:00:00Z", "accepted", "tentative"),
+	}
+	stats := analyzeSeries(series, instances, time.Now())
+	if len(stats.Recommendations) != 1 || !strings.Contains(stats.Recommendations[0], "healthy") {
+		t.Errorf("expected healthy verdict, got %v", stats.Recommendations)
+	}
+}
+
+func TestAnalyzeSeries_PrivateOccurrences(t *testing.T) {
+	series := &calendar.Event{Id: "weekly"}
+	hidden := &calendar.Event{
+		Status:     "confirmed",
+		Visibility: "private",
+		Start:      &calendar.EventDateTime{DateTime: "2025-03-10T09:00:00Z"},
+	}
+	instances := []*calendar.Event{
+		seriesInstance("confirmed", "2025-03-03T09:00:00Z", "2025-03-03T09:00:00Z", "accepted", "accepted"),
+		hidden,
+	}
+	stats := analyzeSeries(series, instances, time.Now())
+	if stats.Held != 2 || stats.DetailsHidden != 1 || stats.AvgInvited != 2 || stats.AttendanceRate != 1 {
+		t.Errorf("hidden occurrence should count as held but not in attendance: %+v", stats)
+	}
+}
+
+func TestAnalyzeSeries_NoOccurrences(t *testing.T) {
+	stats := analyzeSeries(&calendar.Event{Id: "new"}, nil, time.Now())
+	if stats.Occurrences != 0 || len(stats.Recommendations) != 1 || !strings.Contains(stats.Recommendations[0], "nothing to analyze") {
+		t.Errorf("unexpected stats for empty history: %+v", stats)
+	}
+}
+
+// ----- wasRescheduled -----
+
+func TestWasRescheduled(t *testing.T) {
+	tests := []struct {
+		name     string
+		original *calendar.EventDateTime
+		start    *calendar.EventDateTime
+		want     bool
+	}{
+		{"same time", &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"}, &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"}, false},
+		{"same instant other offset", &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"}, &calendar.EventDateTime{DateTime: "2025-03-03T10:00:00+01:00"}, false},
+		{"moved", &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"}, &calendar.EventDateTime{DateTime: "2025-03-04T09:00:00Z"}, true},
+		{"all-day moved", &calendar.EventDateTime{Date: "2025-03-03"}, &calendar.EventDateTime{Date: "2025-03-04"}, true},
+		{"not an instance", nil, &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev := &calendar.Event{OriginalStartTime: tt.original, Start: tt.start}
+			if got := wasRescheduled(ev); got != tt.want {
+				t.Errorf("wasRescheduled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
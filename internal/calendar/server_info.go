@@ -0,0 +1,137 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+// BuildInfo identifies the running server build.
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commit_time,omitempty"`
+	Modified   bool   `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	GoVersion  string `json:"go_version,omitempty"`
+}
+
+// Subsystem is an optional part of the server, such as the warm cache, and
+// whether this server runs it.
+type Subsystem struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// ServerInfo is reported by server_info to help debug what a client sees,
+// such as tools missing for lack of a scope.
+type ServerInfo struct {
+	Build      BuildInfo    `json:"build"`
+	Backend    string       `json:"backend"`
+	Profile    string       `json:"profile"`
+	Subsystems []Subsystem  `json:"subsystems"`
+	Tools      int          `json:"tools"`            // tools offered to the client
+	Scopes     *ScopeReport `json:"scopes,omitempty"` // nil for a backend without OAuth
+}
+
+// SetServerInfo records the build, backend and subsystems reported by
+// server_info.
+func (ct *CalendarTools) SetServerInfo(build BuildInfo, backend string, subsystems []Subsystem) {
+	ct.server = ServerInfo{Build: build, Backend: backend, Subsystems: subsystems}
+}
+
+// SetScopeReport records which tools the granted OAuth scopes leave out, as
+// returned by ToolsForScopes, for server_info.
+func (ct *CalendarTools) SetScopeReport(report ScopeReport) {
+	ct.scopes = &report
+}
+
+func (ct *CalendarTools) handleServerInfo(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	info := ct.server
+	if info.Build.Version == "" {
+		info.Build.Version = "unknown"
+	}
+	info.Profile = defaultProfile
+	if ct.prefs != nil {
+		info.Profile = ct.prefs.Profile()
+	}
+	info.Tools = len(ct.GetTools())
+	if ct.scopes != nil && ct.scopes.Granted != nil {
+		info.Scopes = ct.scopes
+		info.Tools -= len(ct.scopes.Unavailable)
+	}
+
+	return structuredResult(formatServerInfo(info), info), nil
+}
+
+func formatServerInfo(info ServerInfo) string {
+	var result strings.Builder
+	result.WriteString("🛠️ Server info:\n\n")
+	fmt.Fprintf(&result, "• Version: %s", info.Build.Version)
+	if info.Build.Commit != "" {
+		commit := info.Build.Commit
+		if info.Build.Modified {
+			commit += ", modified"
+		}
+		fmt.Fprintf(&result, " (%s)", commit)
+	}
+	result.WriteString("\n")
+	if info.Build.CommitTime != "" {
+		fmt.Fprintf(&result, "• Committed: %s\n", info.Build.CommitTime)
+	}
+	if info.Build.GoVersion != "" {
+		fmt.Fprintf(&result, "• Go: %s\n", info.Build.GoVersion)
+	}
+	if info.Backend != "" {
+		fmt.Fprintf(&result, "• Backend: %s\n", info.Backend)
+	}
+	fmt.Fprintf(&result, "• Profile: %s\n", info.Profile)
+	fmt.Fprintf(&result, "• Tools offered: %d\n", info.Tools)
+
+	if len(info.Subsystems) > 0 {
+		result.WriteString("\nSubsystems:\n")
+		for _, s := range info.Subsystems {
+			mark := "⚪"
+			if s.Enabled {
+				mark = "🟢"
+			}
+			fmt.Fprintf(&result, "%s %s", mark, s.Name)
+			if s.Detail != "" {
+				fmt.Fprintf(&result, ": %s", s.Detail)
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	if info.Scopes != nil {
+		result.WriteString("\nGranted scopes:\n")
+		for _, scope := range info.Scopes.Granted {
+			fmt.Fprintf(&result, "• %s\n", scope)
+		}
+		if summary := info.Scopes.Describe(); summary != "" {
+			fmt.Fprintf(&result, "⚠️ %s\n", strings.ReplaceAll(summary, "\n", "\n⚠️ "))
+		}
+	}
+
+	infoJSON, _ := json.MarshalIndent(info, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(infoJSON))
+	return result.String()
+}
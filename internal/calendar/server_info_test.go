@@ -0,0 +1,66 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServerInfo(t *testing.T) {
+	ct := NewCalendarTools(&Client{})
+	ct.SetServerInfo(BuildInfo{Version: "1.4.2", Commit: "abc1234", Modified: true}, "google", []Subsystem{
+		{Name: "warm_cache"},
+		{Name: "hold_cleanup", Enabled: true, Detail: "every 15m0s"},
+	})
+	ct.SetScopeReport(ScopeReport{
+		Granted:     []string{"https://www.googleapis.com/auth/calendar.readonly"},
+		Unavailable: map[string][]string{"create_event": {"https://www.googleapis.com/auth/calendar.events"}},
+	})
+
+	result, err := ct.HandleTool("server_info", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := result.StructuredContent.(ServerInfo)
+	if info.Build.Version != "1.4.2" || info.Backend != "google" || info.Profile != defaultProfile {
+		t.Errorf("info = %+v, want version 1.4.2 on google with the default profile", info)
+	}
+	if want := len(ct.GetTools()) - 1; info.Tools != want {
+		t.Errorf("tools = %d, want %d without the unavailable one", info.Tools, want)
+	}
+
+	text := result.Content[0].Text
+	for _, want := range []string{"1.4.2 (abc1234, modified)", "⚪ warm_cache", "🟢 hold_cleanup: every 15m0s", "calendar.readonly", "create_event"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result text missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestServerInfo_WithoutOAuth(t *testing.T) {
+	// The fake backend has no granted scopes, so every tool is offered
+	ct := NewCalendarTools(&Client{})
+	ct.SetScopeReport(ScopeReport{})
+
+	result, err := ct.HandleTool("server_info", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := result.StructuredContent.(ServerInfo)
+	if info.Scopes != nil || info.Build.Version != "unknown" || info.Tools != len(ct.GetTools()) {
+		t.Errorf("info = %+v, want no scopes, an unknown version and every tool", info)
+	}
+}
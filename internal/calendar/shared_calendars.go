@@ -0,0 +1,175 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// SharedCalendar is a calendar in the user's calendar list that someone else
+// owns, such as a manager's or a direct report's calendar.
+type SharedCalendar struct {
+	CalendarID    string `json:"calendar_id"`
+	Name          string `json:"name"`
+	AccessRole    string `json:"access_role"`
+	TimeZone      string `json:"time_zone,omitempty"`
+	Person        bool   `json:"person"`         // a user's calendar rather than a group or resource calendar
+	EventsVisible bool   `json:"events_visible"` // false when only free/busy is shared
+	CanEdit       bool   `json:"can_edit"`
+}
+
+// ListCalendars returns every entry of the user's calendar list.
+func (c *Client) ListCalendars() ([]*calendar.CalendarListEntry, error) {
+	var entries []*calendar.CalendarListEntry
+	call := c.service.CalendarList.List().Fields("items(" + calendarListFields + "),nextPageToken")
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list calendars: %v", err)
+		}
+		entries = append(entries, page.Items...)
+		if page.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+	return entries, nil
+}
+
+// sharedCalendars keeps the calendar list entries the user does not own,
+// people's calendars first, each group sorted by name. Calendars blocked by
+// the calendar policy are left out.
+func sharedCalendars(entries []*calendar.CalendarListEntry, policy CalendarPolicy) []SharedCalendar {
+	var shared []SharedCalendar
+	for _, entry := range entries {
+		if entry.Primary || entry.AccessRole == "owner" || policy.Check(entry.Id) != nil {
+			continue
+		}
+		shared = append(shared, SharedCalendar{
+			CalendarID:    entry.Id,
+			Name:          calendarName(entry),
+			AccessRole:    entry.AccessRole,
+			TimeZone:      entry.TimeZone,
+			Person:        isPersonCalendar(entry.Id),
+			EventsVisible: entry.AccessRole != "freeBusyReader",
+			CanEdit:       entry.AccessRole == "writer",
+		})
+	}
+	sort.SliceStable(shared, func(i, j int) bool {
+		if shared[i].Person != shared[j].Person {
+			return shared[i].Person
+		}
+		return strings.ToLower(shared[i].Name) < strings.ToLower(shared[j].Name)
+	})
+	return shared
+}
+
+// isPersonCalendar reports whether a calendar ID is a user's email address.
+// Secondary, group, holiday and resource calendars have IDs under
+// calendar.google.com or resource.calendar.google.com.
+func isPersonCalendar(id string) bool {
+	return strings.Contains(id, "@") && !strings.HasSuffix(strings.ToLower(id), "calendar.google.com")
+}
+
+// freeBusyOnlyError explains that a calendar's events cannot be read because
+// only its free/busy information is shared.
+func freeBusyOnlyError(calendarID string) error {
+	return fmt.Errorf("calendar %s only shares free/busy information with you, so its events cannot be listed; use get_attendee_freebusy with %s to see when it is busy, or ask its owner for \"See all event details\" access", calendarID, calendarID)
+}
+
+// notSharedError explains a 404 from a calendar that is not the user's own.
+func notSharedError(calendarID string, err error) error {
+	return fmt.Errorf("calendar %s was not found or is not shared with you (list_shared_calendars shows the calendars you can read): %v", calendarID, err)
+}
+
+// describeCalendarError explains why a calendar's free/busy is not visible.
+func describeCalendarError(e *calendar.Error) string {
+	switch e.Reason {
+	case "notFound":
+		return "not shared with you, or no such calendar"
+	default:
+		return e.Reason
+	}
+}
+
+// freeBusyErrors lists the calendars in a free/busy response whose busy
+// times are not visible, with the reason. Group errors are reported by
+// groupAvailability instead.
+func freeBusyErrors(resp *calendar.FreeBusyResponse) []string {
+	var notes []string
+	for id, cal := range resp.Calendars {
+		if len(cal.Errors) == 0 {
+			continue
+		}
+		if _, isGroup := resp.Groups[id]; isGroup {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("%s (%s)", id, describeCalendarError(cal.Errors[0])))
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+func (ct *CalendarTools) handleListSharedCalendars(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	entries, err := ct.client.ListCalendars()
+	if err != nil {
+		return nil, err
+	}
+	shared := sharedCalendars(entries, ct.client.policy)
+	if getBoolOrDefault(arguments, "people_only", false) {
+		var people []SharedCalendar
+		for _, s := range shared {
+			if s.Person {
+				people = append(people, s)
+			}
+		}
+		shared = people
+	}
+
+	var result strings.Builder
+	result.WriteString("🤝 Calendars shared with you:\n\n")
+	if len(shared) == 0 {
+		result.WriteString("• None. Calendars others share with you appear here once they are in your calendar list (see subscribe_calendar).\n")
+	}
+	for _, s := range shared {
+		access := "can see event details"
+		switch {
+		case s.CanEdit:
+			access = "can see and edit events"
+		case !s.EventsVisible:
+			access = "free/busy only"
+		}
+		fmt.Fprintf(&result, "• %s (%s) — %s\n", s.Name, s.CalendarID, access)
+	}
+	if len(shared) > 0 {
+		result.WriteString("\nPass a calendar_id to list_events, or the ID to get_attendee_freebusy. Free/busy-only calendars work with get_attendee_freebusy alone.\n")
+	}
+
+	sharedJSON, _ := json.MarshalIndent(shared, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(sharedJSON))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- sharedCalendars -----
+
+func TestSharedCalendars(t *testing.T) {
+	entries := []*calendar.CalendarListEntry{
+		{Id: "me@example.com", Summary: "Me", AccessRole: "owner", Primary: true},
+		{Id: "side@group.calendar.google.com", Summary: "My project", AccessRole: "owner"},
+		{Id: "team@group.calendar.google.com", Summary: "Team", AccessRole: "reader"},
+		{Id: "zoe@example.com", Summary: "Zoe", AccessRole: "writer"},
+		{Id: "boss@example.com", Summary: "Boss", SummaryOverride: "Alex (manager)", AccessRole: "freeBusyReader"},
+		{Id: "secret@example.com", Summary: "Secret", AccessRole: "reader"},
+	}
+	got := sharedCalendars(entries, CalendarPolicy{Denied: []string{"secret@example.com"}})
+	want := []SharedCalendar{
+		{CalendarID: "boss@example.com", Name: "Alex (manager)", AccessRole: "freeBusyReader", Person: true},
+		{CalendarID: "zoe@example.com", Name: "Zoe", AccessRole: "writer", Person: true, EventsVisible: true, CanEdit: true},
+		{CalendarID: "team@group.calendar.google.com", Name: "Team", AccessRole: "reader", EventsVisible: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestIsPersonCalendar(t *testing.T) {
+	cases := map[string]bool{
+		"alex@example.com":                           true,
+		"team@group.calendar.google.com":             false,
+		"en.usa#holiday@group.v.calendar.google.com": false,
+		"c_123@resource.calendar.google.com":         false,
+		"primary":                                    false,
+	}
+	for id, want := range cases {
+		if got := isPersonCalendar(id); got != want {
+			t.Errorf("isPersonCalendar(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+// ----- freeBusyErrors -----
+
+func TestFreeBusyErrors(t *testing.T) {
+	resp := &calendar.FreeBusyResponse{
+		Calendars: map[string]calendar.FreeBusyCalendar{
+			"alex@example.com":   {},
+			"stranger@other.com": {Errors: []*calendar.Error{{Reason: "notFound"}}},
+			"team@example.com":   {Errors: []*calendar.Error{{Reason: "groupTooBig"}}},
+			"broken@example.com": {Errors: []*calendar.Error{{Reason: "internalError"}}},
+		},
+		Groups: map[string]calendar.FreeBusyGroup{"team@example.com": {}},
+	}
+	got := freeBusyErrors(resp)
+	want := []string{"broken@example.com (internalError)", "stranger@other.com (not shared with you, or no such calendar)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
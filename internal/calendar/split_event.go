@@ -0,0 +1,224 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// minSplitPartMinutes is the shortest piece split_event creates; a shorter
+// remainder at the end of the block is left free.
+const minSplitPartMinutes = 5
+
+// SplitPart is one of the events a block was split into.
+type SplitPart struct {
+	EventID string `json:"event_id,omitempty"` // empty in a dry run, except for the original
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// splitSlots divides [start, end) into pieces with breakLength between them:
+// parts pieces of equal length, or pieces of partLength from the start (the
+// last one shorter if the block does not divide evenly). Exactly one of parts
+// and partLength is set. At least two pieces must result.
+func splitSlots(start, end time.Time, parts int, partLength, breakLength time.Duration) ([]TimeSlot, error) {
+	minPart := minSplitPartMinutes * time.Minute
+	total := end.Sub(start)
+	if parts > 0 {
+		if parts < 2 {
+			return nil, fmt.Errorf("parts must be at least 2")
+		}
+		partLength = ((total - time.Duration(parts-1)*breakLength) / time.Duration(parts)).Truncate(time.Minute)
+		if partLength < minPart {
+			return nil, fmt.Errorf("%d parts with %s breaks leave under %d minutes each", parts, breakLength, minSplitPartMinutes)
+		}
+	}
+	if partLength < minPart {
+		return nil, fmt.Errorf("part_minutes must be at least %d", minSplitPartMinutes)
+	}
+
+	var slots []TimeSlot
+	for s := start; end.Sub(s) >= minPart; s = s.Add(partLength + breakLength) {
+		e := s.Add(partLength)
+		if e.After(end) {
+			e = end
+		}
+		slots = append(slots, TimeSlot{Start: s, End: e})
+		if parts > 0 && len(slots) == parts {
+			break
+		}
+	}
+	if len(slots) < 2 {
+		return nil, fmt.Errorf("a %s block cannot be split into pieces of %s with %s breaks", total, partLength, breakLength)
+	}
+	if parts > 0 {
+		// Equal parts are rounded down to the minute; the last one takes the rest
+		slots[len(slots)-1].End = end
+	}
+	return slots, nil
+}
+
+// SplitEvent splits event into one event per slot. The original event
+// becomes the first piece, keeping its ID, attendees and conference; the
+// others are copies of it, with the same title, description, color and
+// attendees. The copies are created first and removed again if the original
+// cannot be shortened, e.g. because it changed since it was read.
+func (c *Client) SplitEvent(calendarID string, event *calendar.Event, slots []TimeSlot, sendUpdates string) ([]*calendar.Event, error) {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return nil, err
+	}
+	at := func(t time.Time) *calendar.EventDateTime {
+		return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339), TimeZone: event.Start.TimeZone}
+	}
+
+	var copies []*calendar.Event
+	rollback := func() {
+		for _, e := range copies {
+			c.DeleteEvent(calendarID, e.Id, sendUpdates)
+		}
+	}
+	for _, slot := range slots[1:] {
+		body, err := restoreBody(event)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		body.Id = ""
+		body.Start, body.End = at(slot.Start), at(slot.End)
+		created, err := c.insertRestored(calendarID, body, sendUpdates)
+		if err != nil {
+			rollback()
+			return nil, fmt.Errorf("failed to create the piece at %s: %v", slot.Start.Format(time.RFC3339), err)
+		}
+		copies = append(copies, created)
+	}
+
+	call := c.service.Events.Patch(calendarID, event.Id, &calendar.Event{Start: at(slots[0].Start), End: at(slots[0].End)})
+	if sendUpdates != "" {
+		call = call.SendUpdates(sendUpdates)
+	}
+	if event.Etag != "" {
+		call.Header().Set("If-Match", event.Etag)
+	}
+	first, err := call.Do()
+	if err != nil {
+		rollback()
+		return nil, c.conflictError(calendarID, event.Id, event.Etag, err)
+	}
+	return append([]*calendar.Event{first}, copies...), nil
+}
+
+func (ct *CalendarTools) handleSplitEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID := getStringOrDefault(arguments, "event_id", "")
+	if eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	parts := getIntOrDefault(arguments, "parts", 0)
+	partMinutes := getIntOrDefault(arguments, "part_minutes", 0)
+	if (parts > 0) == (partMinutes > 0) {
+		return nil, fmt.Errorf("give either parts or part_minutes")
+	}
+	breakMinutes := getIntOrDefault(arguments, "break_minutes", 0)
+	if breakMinutes < 0 {
+		return nil, fmt.Errorf("break_minutes cannot be negative")
+	}
+	dryRun := getBoolOrDefault(arguments, "dry_run", false)
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarID := ct.calendarID(arguments)
+	event, err := ct.client.GetEvent(calendarID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	title := eventTitle(event)
+	if event.RecurringEventId != "" || len(event.Recurrence) > 0 {
+		return nil, fmt.Errorf("cannot split '%s': it is part of a recurring series; split_event only splits single events", title)
+	}
+	if access := ct.client.EventAccess(calendarID, event); access.Level != accessOrganizer {
+		return nil, fmt.Errorf("cannot split '%s': it is organized by %s, who would have to split it", title, access.organizerName())
+	}
+	start, end, allDay, err := parseEventTimes(event)
+	if err != nil {
+		return nil, fmt.Errorf("cannot split '%s': %v", title, err)
+	}
+	if allDay {
+		return nil, fmt.Errorf("cannot split '%s': all-day events have no hours to split", title)
+	}
+
+	slots, err := splitSlots(start, end, parts, time.Duration(partMinutes)*time.Minute, time.Duration(breakMinutes)*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("cannot split '%s': %v", title, err)
+	}
+
+	split := make([]SplitPart, len(slots))
+	for i, slot := range slots {
+		split[i] = SplitPart{Start: slot.Start.Format(time.RFC3339), End: slot.End.Format(time.RFC3339)}
+	}
+	split[0].EventID = event.Id
+	if !dryRun {
+		pieces, err := ct.client.SplitEvent(calendarID, event, slots, sendUpdates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split '%s': %v", title, err)
+		}
+		for i, piece := range pieces {
+			split[i].EventID = piece.Id
+		}
+	}
+
+	tf := ct.client.TimeFormat()
+	var result strings.Builder
+	verb := "Split"
+	if dryRun {
+		verb = "Would split"
+	}
+	fmt.Fprintf(&result, "✂️ %s '%s' (%s, %s–%s) into %d events", verb, title, tf.ShortDate(start), tf.Clock(start), tf.Clock(end), len(slots))
+	if breakMinutes > 0 {
+		fmt.Fprintf(&result, " with %d-minute breaks", breakMinutes)
+	}
+	if dryRun {
+		result.WriteString(" — dry run, nothing changed")
+	} else if len(event.Attendees) > 0 {
+		fmt.Fprintf(&result, "; %s", describeSendUpdates(sendUpdates))
+	}
+	result.WriteString(":\n")
+	for i, slot := range slots {
+		fmt.Fprintf(&result, "• %s–%s", tf.Clock(slot.Start), tf.Clock(slot.End))
+		if split[i].EventID != "" {
+			fmt.Fprintf(&result, " (%s)", split[i].EventID)
+		}
+		if i == 0 {
+			result.WriteString(" — the original event")
+		}
+		result.WriteString("\n")
+	}
+
+	return structuredResult(result.String(), map[string]interface{}{
+		"calendar_id": calendarID,
+		"event_id":    event.Id,
+		"dry_run":     dryRun,
+		"parts":       split,
+	}), nil
+}
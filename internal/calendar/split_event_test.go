@@ -0,0 +1,62 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitSlots(t *testing.T) {
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+	tests := []struct {
+		name        string
+		parts       int
+		partMinutes int
+		breakMins   int
+		want        []string // "HH:MM-HH:MM"
+		wantErr     bool
+	}{
+		{"equal parts", 4, 0, 0, []string{"09:00-10:00", "10:00-11:00", "11:00-12:00", "12:00-13:00"}, false},
+		{"equal parts with breaks", 3, 0, 10, []string{"09:00-10:13", "10:23-11:36", "11:46-13:00"}, false},
+		{"fixed length", 0, 90, 15, []string{"09:00-10:30", "10:45-12:15", "12:30-13:00"}, false},
+		{"fixed length leaves a short remainder free", 0, 50, 10, []string{"09:00-09:50", "10:00-10:50", "11:00-11:50", "12:00-12:50"}, false},
+		{"one part", 1, 0, 0, nil, true},
+		{"parts too short", 60, 0, 0, nil, true},
+		{"part as long as the block", 0, 240, 0, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots, err := splitSlots(start, end, tt.parts, time.Duration(tt.partMinutes)*time.Minute, time.Duration(tt.breakMins)*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, s := range slots {
+				got = append(got, s.Start.Format("15:04")+"-"+s.End.Format("15:04"))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
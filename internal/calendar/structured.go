@@ -0,0 +1,194 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import "gcal-mcp-server/internal/mcp"
+
+// Schema fragments shared by the output schemas below.
+var (
+	stringProperty  = map[string]interface{}{"type": "string"}
+	integerProperty = map[string]interface{}{"type": "integer"}
+	booleanProperty = map[string]interface{}{"type": "boolean"}
+	objectProperty  = map[string]interface{}{"type": "object"}
+	// eventTimeProperty is an event's start or end: dateTime for timed
+	// events, date for all-day ones
+	eventTimeProperty = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"dateTime": stringProperty,
+			"date":     stringProperty,
+			"timeZone": stringProperty,
+		},
+	}
+)
+
+// outputSchemas describes the structuredContent returned by the tools whose
+// results are most often parsed: single events, listings and availability.
+// Schemas only list the fields clients rely on; results may carry more.
+var outputSchemas = map[string]*mcp.ToolSchema{
+	// The created event as the Calendar API returned it
+	"create_event": {
+		Type: "object",
+		Properties: map[string]interface{}{
+			"id":          stringProperty,
+			"summary":     stringProperty,
+			"status":      stringProperty,
+			"etag":        stringProperty,
+			"htmlLink":    stringProperty,
+			"hangoutLink": stringProperty,
+			"start":       eventTimeProperty,
+			"end":         eventTimeProperty,
+			"attendees":   map[string]interface{}{"type": "array", "items": objectProperty},
+		},
+		Required: []string{"id"},
+	},
+	// EventDiff
+	"edit_event": {
+		Type: "object",
+		Properties: map[string]interface{}{
+			"calendar_id": stringProperty,
+			"event_id":    stringProperty,
+			"etag":        stringProperty,
+			"html_link":   stringProperty,
+			"summary":     stringProperty,
+			"scope":       stringProperty,
+			"changes": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"field": stringProperty,
+						"old":   map[string]interface{}{},
+						"new":   map[string]interface{}{},
+					},
+					"required": []string{"field"},
+				},
+			},
+		},
+		Required: []string{"calendar_id", "event_id", "changes"},
+	},
+	// formatEventsJSON, whatever output_format was asked for
+	"list_events": {
+		Type: "object",
+		Properties: map[string]interface{}{
+			"time_filter":             stringProperty,
+			"include_past":            booleanProperty,
+			"days":                    integerProperty,
+			"week_mode":               stringProperty,
+			"total_count":             integerProperty,
+			"attendees_omitted_count": integerProperty,
+			"conflicts":               map[string]interface{}{"type": "array", "items": objectProperty},
+			"events": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"id":        stringProperty,
+						"handle":    stringProperty,
+						"summary":   stringProperty,
+						"status":    stringProperty,
+						"etag":      stringProperty,
+						"start":     eventTimeProperty,
+						"end":       eventTimeProperty,
+						"attendees": map[string]interface{}{"type": "array", "items": objectProperty},
+						"source":    objectProperty,
+					},
+					"required": []string{"id"},
+				},
+			},
+		},
+		Required: []string{"total_count", "events"},
+	},
+	// The Calendar API's free/busy response: busy periods per calendar,
+	// group expansions and per-calendar errors
+	"get_attendee_freebusy": {
+		Type: "object",
+		Properties: map[string]interface{}{
+			"timeMin":   stringProperty,
+			"timeMax":   stringProperty,
+			"calendars": objectProperty,
+			"groups":    objectProperty,
+		},
+	},
+	// Availability
+	"share_availability": {
+		Type: "object",
+		Properties: map[string]interface{}{
+			"time_zone": stringProperty,
+			"slots": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"start": stringProperty,
+						"end":   stringProperty,
+					},
+					"required": []string{"start", "end"},
+				},
+			},
+		},
+		Required: []string{"time_zone", "slots"},
+	},
+	// CandidateMatrix
+	"check_candidate_slots": {
+		Type: "object",
+		Properties: map[string]interface{}{
+			"time_zone": stringProperty,
+			"attendees": map[string]interface{}{"type": "array", "items": objectProperty},
+			"slots": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"number":            integerProperty,
+						"start":             stringProperty,
+						"end":               stringProperty,
+						"status":            objectProperty,
+						"busy":              map[string]interface{}{"type": "array", "items": stringProperty},
+						"works_for_all":     booleanProperty,
+						"policy_violations": map[string]interface{}{"type": "array", "items": objectProperty},
+						"blocked_by_policy": booleanProperty,
+					},
+					"required": []string{"number", "start", "end", "status"},
+				},
+			},
+			"best": map[string]interface{}{"type": "array", "items": integerProperty},
+		},
+		Required: []string{"time_zone", "slots", "best"},
+	},
+}
+
+// withOutputSchemas sets the output schema of every tool that has one.
+func withOutputSchemas(tools []mcp.Tool) []mcp.Tool {
+	for i := range tools {
+		tools[i].OutputSchema = outputSchemas[tools[i].Name]
+	}
+	return tools
+}
+
+// structuredResult returns text as the tool result, with data attached as
+// structured content for clients that parse it. data must marshal to a JSON
+// object matching the tool's output schema.
+func structuredResult(text string, data interface{}) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{
+			Type: "text",
+			Text: text,
+		}},
+		StructuredContent: data,
+	}
+}
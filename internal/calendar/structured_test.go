@@ -0,0 +1,93 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- outputSchemas -----
+
+func TestGetToolsOutputSchemas(t *testing.T) {
+	tools := NewCalendarTools(nil).GetTools()
+	names := make(map[string]bool)
+	for _, tool := range tools {
+		names[tool.Name] = true
+		if want := outputSchemas[tool.Name]; tool.OutputSchema != want {
+			t.Errorf("%s: output schema not attached", tool.Name)
+		}
+	}
+	for name := range outputSchemas {
+		if !names[name] {
+			t.Errorf("output schema for unknown tool %q", name)
+		}
+	}
+}
+
+// checkRequired fails unless data marshals to a JSON object with every field
+// the tool's output schema requires.
+func checkRequired(t *testing.T, tool string, data interface{}) {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("%s: marshal: %v", tool, err)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		t.Fatalf("%s: structured content is not a JSON object: %s", tool, raw)
+	}
+	for _, field := range outputSchemas[tool].Required {
+		if _, ok := object[field]; !ok {
+			t.Errorf("%s: structured content missing required %q: %s", tool, field, raw)
+		}
+	}
+}
+
+func TestStructuredContentMatchesSchemas(t *testing.T) {
+	event := &calendar.Event{
+		Id: "ev1", Summary: "Standup",
+		Start: &calendar.EventDateTime{DateTime: "2025-03-03T09:00:00Z"},
+		End:   &calendar.EventDateTime{DateTime: "2025-03-03T09:15:00Z"},
+	}
+	checkRequired(t, "create_event", event)
+	checkRequired(t, "edit_event", EventDiff{CalendarID: "primary", EventID: "ev1", Changes: []FieldChange{}})
+	checkRequired(t, "get_attendee_freebusy", &calendar.FreeBusyResponse{Calendars: map[string]calendar.FreeBusyCalendar{}})
+	checkRequired(t, "share_availability", newAvailability(nil, time.UTC))
+
+	ct := NewCalendarTools(nil)
+	listing := ct.formatEventsJSON(&calendar.Events{Items: []*calendar.Event{event}}, ListEventsParams{TimeFilter: "today"})
+	checkRequired(t, "list_events", listing)
+}
+
+// ----- newAvailability -----
+
+func TestNewAvailability(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	start := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+	got := newAvailability([]TimeSlot{{Start: start, End: start.Add(time.Hour)}}, loc)
+	if got.TimeZone != "Europe/Paris" || len(got.Slots) != 1 {
+		t.Fatalf("newAvailability() = %+v", got)
+	}
+	if s := got.Slots[0]; s.Start != "2025-03-03T09:00:00+01:00" || s.End != "2025-03-03T10:00:00+01:00" {
+		t.Errorf("slot = %+v, want 09:00-10:00 Paris time", s)
+	}
+}
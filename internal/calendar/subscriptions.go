@@ -0,0 +1,421 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// subscriptionFields is the field selector for calendar list entries returned
+// by subscribe_calendar
+const subscriptionFields = "id,summary,summaryOverride,timeZone,accessRole,primary,hidden,selected,colorId,backgroundColor"
+
+// SubscribeCalendarParams describes a calendar to add to the user's calendar
+// list and how it is shown.
+type SubscribeCalendarParams struct {
+	CalendarID      string
+	Hidden          bool   // keep the calendar out of the calendar list in the UI
+	Selected        bool   // show the calendar's events in the UI
+	ColorID         string // calendar color from get_calendar_colors; "" keeps the default
+	SummaryOverride string // name shown to the user instead of the calendar's own
+}
+
+// calendarSettingsFields is the field selector for calendar list entries
+// returned by configure_calendar
+const calendarSettingsFields = subscriptionFields + ",defaultReminders,notificationSettings"
+
+// calendarNotificationTypes are the changes Google Calendar can email the
+// user about for a calendar in their list.
+var calendarNotificationTypes = []string{"eventCreation", "eventChange", "eventCancellation", "eventResponse", "agenda"}
+
+// maxDefaultReminders and maxReminderMinutes are the API's limits on a
+// calendar's default reminders.
+const (
+	maxDefaultReminders = 5
+	maxReminderMinutes  = 40320 // four weeks
+)
+
+// Subscription is the calendar list entry reported after subscribing or
+// changing a calendar's settings.
+type Subscription struct {
+	CalendarID       string     `json:"calendar_id"`
+	Name             string     `json:"name"`
+	AccessRole       string     `json:"access_role"`
+	TimeZone         string     `json:"time_zone,omitempty"`
+	Hidden           bool       `json:"hidden"`
+	Selected         bool       `json:"selected"`
+	ColorID          string     `json:"color_id,omitempty"`
+	Color            string     `json:"color,omitempty"`
+	DefaultReminders []Reminder `json:"default_reminders,omitempty"`
+	Notifications    []string   `json:"notifications,omitempty"`
+}
+
+// CalendarSettingsParams changes how a calendar in the user's calendar list
+// is shown and what it notifies about. Nil fields are left unchanged.
+type CalendarSettingsParams struct {
+	CalendarID       string
+	Hidden           *bool
+	Selected         *bool
+	ColorID          *string    // "" returns to the calendar's default color
+	SummaryOverride  *string    // "" shows the calendar's own name again
+	DefaultReminders []Reminder // reminders added to events without their own
+	Notifications    []string   // email notification types from calendarNotificationTypes
+
+	// Track which lists have been explicitly provided; an empty list clears them
+	HasDefaultReminders bool
+	HasNotifications    bool
+}
+
+// newSubscription describes a calendar list entry for tool output.
+func newSubscription(entry *calendar.CalendarListEntry) Subscription {
+	sub := Subscription{
+		CalendarID: entry.Id,
+		Name:       calendarName(entry),
+		AccessRole: entry.AccessRole,
+		TimeZone:   entry.TimeZone,
+		Hidden:     entry.Hidden,
+		Selected:   entry.Selected,
+		ColorID:    entry.ColorId,
+		Color:      entry.BackgroundColor,
+	}
+	for _, r := range entry.DefaultReminders {
+		sub.DefaultReminders = append(sub.DefaultReminders, Reminder{Method: r.Method, Minutes: r.Minutes})
+	}
+	if entry.NotificationSettings != nil {
+		for _, n := range entry.NotificationSettings.Notifications {
+			sub.Notifications = append(sub.Notifications, n.Type)
+		}
+	}
+	return sub
+}
+
+// SubscribeCalendar adds an existing calendar, such as a public holiday
+// calendar or a colleague's shared calendar, to the user's calendar list.
+// Subscribing to a calendar already in the list updates how it is shown.
+func (c *Client) SubscribeCalendar(params SubscribeCalendarParams) (*calendar.CalendarListEntry, error) {
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, err
+	}
+
+	entry, err := c.service.CalendarList.Insert(&calendar.CalendarListEntry{
+		Id:              params.CalendarID,
+		Hidden:          params.Hidden,
+		Selected:        params.Selected,
+		ColorId:         params.ColorID,
+		SummaryOverride: params.SummaryOverride,
+	}).Fields(subscriptionFields).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("calendar %s does not exist or is not shared with you: %v", params.CalendarID, err)
+		}
+		return nil, fmt.Errorf("failed to subscribe to calendar %s: %v", params.CalendarID, err)
+	}
+	c.setAccessRole(entry.Id, entry.AccessRole)
+	return entry, nil
+}
+
+// UnsubscribeCalendar removes a calendar from the user's calendar list. The
+// calendar and its events are unchanged; only the user's subscription goes.
+func (c *Client) UnsubscribeCalendar(calendarID string) error {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return err
+	}
+	if calendarID == "primary" {
+		return fmt.Errorf("your primary calendar cannot be removed from your calendar list")
+	}
+
+	if err := c.service.CalendarList.Delete(calendarID).Do(); err != nil {
+		if isNotFound(err) {
+			return fmt.Errorf("calendar %s is not in your calendar list", calendarID)
+		}
+		return fmt.Errorf("failed to unsubscribe from calendar %s: %v", calendarID, err)
+	}
+	c.setAccessRole(calendarID, "")
+	return nil
+}
+
+// UpdateCalendarSettings changes the color, visibility, name, default
+// reminders or email notifications of a calendar in the user's calendar list.
+// Only the user's view of the calendar changes, so any calendar in the list
+// can be updated, whatever the user's access to its events.
+func (c *Client) UpdateCalendarSettings(params CalendarSettingsParams) (*calendar.CalendarListEntry, error) {
+	if err := c.checkCalendar(params.CalendarID); err != nil {
+		return nil, err
+	}
+
+	patch := &calendar.CalendarListEntry{}
+	if params.Hidden != nil {
+		patch.Hidden = *params.Hidden
+		patch.ForceSendFields = append(patch.ForceSendFields, "Hidden")
+	}
+	if params.Selected != nil {
+		patch.Selected = *params.Selected
+		patch.ForceSendFields = append(patch.ForceSendFields, "Selected")
+	}
+	if params.ColorID != nil {
+		patch.ColorId = *params.ColorID
+		patch.ForceSendFields = append(patch.ForceSendFields, "ColorId")
+	}
+	if params.SummaryOverride != nil {
+		patch.SummaryOverride = *params.SummaryOverride
+		patch.ForceSendFields = append(patch.ForceSendFields, "SummaryOverride")
+	}
+	if params.HasDefaultReminders {
+		for _, r := range params.DefaultReminders {
+			patch.DefaultReminders = append(patch.DefaultReminders, &calendar.EventReminder{Method: r.Method, Minutes: r.Minutes, ForceSendFields: []string{"Minutes"}})
+		}
+		patch.ForceSendFields = append(patch.ForceSendFields, "DefaultReminders")
+	}
+	if params.HasNotifications {
+		settings := &calendar.CalendarListEntryNotificationSettings{ForceSendFields: []string{"Notifications"}}
+		for _, t := range params.Notifications {
+			settings.Notifications = append(settings.Notifications, &calendar.CalendarNotification{Method: "email", Type: t})
+		}
+		patch.NotificationSettings = settings
+	}
+
+	entry, err := c.service.CalendarList.Patch(params.CalendarID, patch).Fields(calendarSettingsFields).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("calendar %s is not in your calendar list; subscribe_calendar adds it", params.CalendarID)
+		}
+		return nil, fmt.Errorf("failed to update calendar %s: %v", params.CalendarID, err)
+	}
+	c.setAccessRole(entry.Id, entry.AccessRole)
+	return entry, nil
+}
+
+func (ct *CalendarTools) handleSubscribeCalendar(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	params := SubscribeCalendarParams{
+		CalendarID:      strings.TrimSpace(getStringOrDefault(arguments, "calendar_id", "")),
+		Hidden:          getBoolOrDefault(arguments, "hidden", false),
+		Selected:        getBoolOrDefault(arguments, "selected", true),
+		ColorID:         getStringOrDefault(arguments, "color_id", ""),
+		SummaryOverride: getStringOrDefault(arguments, "summary_override", ""),
+	}
+	if params.CalendarID == "" {
+		return nil, fmt.Errorf("calendar_id is required")
+	}
+	if params.CalendarID == "primary" {
+		return nil, fmt.Errorf("your primary calendar is always in your calendar list")
+	}
+
+	entry, err := ct.client.SubscribeCalendar(params)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Primary {
+		return nil, fmt.Errorf("%s is your primary calendar, which is always in your calendar list", entry.Id)
+	}
+
+	sub := newSubscription(entry)
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "✅ Subscribed to '%s' (%s)\n\n", sub.Name, sub.CalendarID)
+	fmt.Fprintf(&result, "• Access: %s\n", sub.AccessRole)
+	switch {
+	case sub.Hidden:
+		result.WriteString("• Hidden from your calendar list\n")
+	case !sub.Selected:
+		result.WriteString("• In your calendar list, events not shown\n")
+	default:
+		result.WriteString("• Events shown in your calendar\n")
+	}
+	if sub.AccessRole == "freeBusyReader" {
+		result.WriteString("\n⚠️ Only free/busy information is shared, so event details will not be visible.\n")
+	}
+
+	subJSON, _ := json.MarshalIndent(sub, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(subJSON))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleUnsubscribeCalendar(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	calendarID := strings.TrimSpace(getStringOrDefault(arguments, "calendar_id", ""))
+	if calendarID == "" {
+		return nil, fmt.Errorf("calendar_id is required")
+	}
+
+	if err := ct.client.UnsubscribeCalendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	result := fmt.Sprintf("✅ Removed %s from your calendar list. The calendar itself is unchanged; subscribe_calendar adds it back.", calendarID)
+	// Tools called without calendar_id would otherwise keep using it
+	if ct.prefs != nil && strings.EqualFold(ct.prefs.Get().DefaultCalendar, calendarID) {
+		if err := ct.prefs.Update(func(p *Preferences) { p.DefaultCalendar = "" }); err != nil {
+			return nil, err
+		}
+		result += "\nIt was your default calendar; tools called without calendar_id now use your primary calendar."
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result}},
+	}, nil
+}
+
+func (ct *CalendarTools) handleConfigureCalendar(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	idOrName := strings.TrimSpace(getStringOrDefault(arguments, "calendar", ""))
+	if idOrName == "" {
+		return nil, fmt.Errorf("calendar is required")
+	}
+	params, err := parseCalendarSettingsParams(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := ct.client.ResolveCalendar(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	params.CalendarID = resolved.Id
+	if resolved.Primary && params.Hidden != nil && *params.Hidden {
+		return nil, fmt.Errorf("your primary calendar cannot be hidden from your calendar list; set selected to false to stop showing its events")
+	}
+
+	entry, err := ct.client.UpdateCalendarSettings(params)
+	if err != nil {
+		return nil, err
+	}
+	sub := newSubscription(entry)
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "✅ Updated '%s' (%s)\n\n", sub.Name, sub.CalendarID)
+	switch {
+	case sub.Hidden:
+		result.WriteString("• Hidden from your calendar list\n")
+	case !sub.Selected:
+		result.WriteString("• In your calendar list, events not shown\n")
+	default:
+		result.WriteString("• Events shown in your calendar\n")
+	}
+	if sub.ColorID != "" || sub.Color != "" {
+		fmt.Fprintf(&result, "• Color: %s\n", strings.TrimSpace(sub.ColorID+" "+sub.Color))
+	}
+	if params.HasDefaultReminders {
+		if len(sub.DefaultReminders) == 0 {
+			result.WriteString("• No default reminders\n")
+		}
+		for _, r := range sub.DefaultReminders {
+			fmt.Fprintf(&result, "• Default reminder: %s %d minutes before\n", r.Method, r.Minutes)
+		}
+	}
+	if params.HasNotifications {
+		if len(sub.Notifications) == 0 {
+			result.WriteString("• No email notifications\n")
+		} else {
+			fmt.Fprintf(&result, "• Email notifications: %s\n", strings.Join(sub.Notifications, ", "))
+		}
+	}
+
+	subJSON, _ := json.MarshalIndent(sub, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(subJSON))
+
+	return structuredResult(result.String(), sub), nil
+}
+
+// parseCalendarSettingsParams reads the settings to change; at least one is
+// required. The calendar itself is resolved by the caller.
+func parseCalendarSettingsParams(arguments map[string]interface{}) (CalendarSettingsParams, error) {
+	var params CalendarSettingsParams
+	if _, ok := arguments["hidden"]; ok {
+		hidden := getBoolOrDefault(arguments, "hidden", false)
+		params.Hidden = &hidden
+	}
+	if _, ok := arguments["selected"]; ok {
+		selected := getBoolOrDefault(arguments, "selected", true)
+		params.Selected = &selected
+	}
+	if v, ok := arguments["color_id"].(string); ok {
+		colorID := strings.TrimSpace(v)
+		params.ColorID = &colorID
+	}
+	if v, ok := arguments["summary_override"].(string); ok {
+		params.SummaryOverride = &v
+	}
+
+	if raw, ok := arguments["default_reminders"]; ok {
+		items, ok := raw.([]interface{})
+		if !ok {
+			return params, fmt.Errorf("default_reminders must be an array")
+		}
+		if len(items) > maxDefaultReminders {
+			return params, fmt.Errorf("a calendar can have at most %d default reminders", maxDefaultReminders)
+		}
+		params.HasDefaultReminders = true
+		for _, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return params, fmt.Errorf("each default reminder needs a method and minutes")
+			}
+			r := Reminder{
+				Method:  getStringOrDefault(m, "method", "popup"),
+				Minutes: int64(getIntOrDefault(m, "minutes", -1)),
+			}
+			if r.Method != "popup" && r.Method != "email" {
+				return params, fmt.Errorf("invalid reminder method %q: use popup or email", r.Method)
+			}
+			if r.Minutes < 0 || r.Minutes > maxReminderMinutes {
+				return params, fmt.Errorf("reminder minutes must be between 0 and %d", maxReminderMinutes)
+			}
+			params.DefaultReminders = append(params.DefaultReminders, r)
+		}
+	}
+
+	if raw, ok := arguments["notifications"]; ok {
+		items, ok := raw.([]interface{})
+		if !ok {
+			return params, fmt.Errorf("notifications must be an array")
+		}
+		params.HasNotifications = true
+		seen := map[string]bool{}
+		for _, item := range items {
+			t, _ := item.(string)
+			if !containsString(calendarNotificationTypes, t) {
+				return params, fmt.Errorf("invalid notification %q: use %s", t, strings.Join(calendarNotificationTypes, ", "))
+			}
+			if !seen[t] {
+				seen[t] = true
+				params.Notifications = append(params.Notifications, t)
+			}
+		}
+	}
+
+	if params.Hidden == nil && params.Selected == nil && params.ColorID == nil && params.SummaryOverride == nil &&
+		!params.HasDefaultReminders && !params.HasNotifications {
+		return params, fmt.Errorf("nothing to change: set hidden, selected, color_id, summary_override, default_reminders or notifications")
+	}
+	return params, nil
+}
+
+// isNotFound reports whether err is a 404 response from the API.
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
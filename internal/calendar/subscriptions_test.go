@@ -0,0 +1,148 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"gcal-mcp-server/internal/fake"
+)
+
+// ----- subscribe_calendar / unsubscribe_calendar -----
+
+func TestSubscribeCalendar_Validation(t *testing.T) {
+	ct := NewCalendarTools(&Client{})
+	tests := []struct {
+		name      string
+		tool      string
+		arguments map[string]interface{}
+		wantErr   string
+	}{
+		{"subscribe without id", "subscribe_calendar", map[string]interface{}{}, "calendar_id is required"},
+		{"subscribe to primary", "subscribe_calendar", map[string]interface{}{"calendar_id": "primary"}, "always in your calendar list"},
+		{"unsubscribe without id", "unsubscribe_calendar", map[string]interface{}{"calendar_id": "  "}, "calendar_id is required"},
+		{"unsubscribe from primary", "unsubscribe_calendar", map[string]interface{}{"calendar_id": "primary"}, "cannot be removed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ct.HandleTool(tt.tool, tt.arguments)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscribeCalendar_Policy(t *testing.T) {
+	// Denied calendars are refused before any API call
+	c := &Client{policy: CalendarPolicy{Denied: []string{"secret@group.calendar.google.com"}}}
+	if _, err := c.SubscribeCalendar(SubscribeCalendarParams{CalendarID: "secret@group.calendar.google.com"}); err == nil {
+		t.Error("expected policy error when subscribing to a denied calendar")
+	}
+	if err := c.UnsubscribeCalendar("secret@group.calendar.google.com"); err == nil {
+		t.Error("expected policy error when unsubscribing from a denied calendar")
+	}
+}
+
+// ----- configure_calendar -----
+
+func TestConfigureCalendar_Validation(t *testing.T) {
+	ct := NewCalendarTools(&Client{})
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		wantErr   string
+	}{
+		{"no calendar", map[string]interface{}{"hidden": true}, "calendar is required"},
+		{"nothing to change", map[string]interface{}{"calendar": "Team"}, "nothing to change"},
+		{"bad notification", map[string]interface{}{"calendar": "Team", "notifications": []interface{}{"eventMoved"}}, "invalid notification"},
+		{"bad reminder method", map[string]interface{}{"calendar": "Team", "default_reminders": []interface{}{map[string]interface{}{"method": "sms", "minutes": float64(10)}}}, "invalid reminder method"},
+		{"reminder too early", map[string]interface{}{"calendar": "Team", "default_reminders": []interface{}{map[string]interface{}{"method": "popup", "minutes": float64(maxReminderMinutes + 1)}}}, "between 0 and"},
+		{"reminders not a list", map[string]interface{}{"calendar": "Team", "default_reminders": "10m"}, "must be an array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ct.HandleTool("configure_calendar", tt.arguments)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigureCalendar_Fake(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	store.AddCalendar("holidays@group.v.calendar.google.com", "Holidays", "UTC")
+	store.AddCalendar("team@group.calendar.google.com", "Team", "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	// Calendars can be named; only the given fields change
+	result, err := ct.HandleTool("configure_calendar", map[string]interface{}{"calendar": "holidays", "hidden": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := result.StructuredContent.(Subscription)
+	if sub.CalendarID != "holidays@group.v.calendar.google.com" || !sub.Hidden || !sub.Selected {
+		t.Errorf("hiding holidays = %+v, want it hidden and still selected", sub)
+	}
+
+	result, err = ct.HandleTool("configure_calendar", map[string]interface{}{
+		"calendar":          "team@group.calendar.google.com",
+		"color_id":          "24",
+		"default_reminders": []interface{}{map[string]interface{}{"method": "popup", "minutes": float64(0)}},
+		"notifications":     []interface{}{"eventCreation", "eventCancellation", "eventCreation"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub = result.StructuredContent.(Subscription)
+	if sub.ColorID != "24" || sub.Hidden || !sub.Selected {
+		t.Errorf("team = %+v, want color 24 and visible", sub)
+	}
+	if len(sub.DefaultReminders) != 1 || sub.DefaultReminders[0] != (Reminder{Method: "popup", Minutes: 0}) {
+		t.Errorf("default reminders = %+v, want one popup at the start", sub.DefaultReminders)
+	}
+	if strings.Join(sub.Notifications, ",") != "eventCreation,eventCancellation" {
+		t.Errorf("notifications = %v, want eventCreation,eventCancellation", sub.Notifications)
+	}
+
+	// Empty lists clear the reminders and notifications, and an empty color
+	// returns to the default
+	result, err = ct.HandleTool("configure_calendar", map[string]interface{}{
+		"calendar":          "Team",
+		"color_id":          "",
+		"default_reminders": []interface{}{},
+		"notifications":     []interface{}{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub = result.StructuredContent.(Subscription)
+	if sub.ColorID != "" || len(sub.DefaultReminders) != 0 || len(sub.Notifications) != 0 {
+		t.Errorf("cleared team = %+v, want no color, reminders or notifications", sub)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "No email notifications") || !strings.Contains(text, "No default reminders") {
+		t.Errorf("result text = %q, want it to report the cleared settings", text)
+	}
+
+	if _, err := ct.HandleTool("configure_calendar", map[string]interface{}{"calendar": "primary", "hidden": true}); err == nil {
+		t.Error("expected an error hiding the primary calendar")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// timesheetHeader is the first row of an exported timesheet.
+var timesheetHeader = []string{"date", "start", "end", "duration", "title", "category"}
+
+// TimesheetRow is one event as a timesheet entry, in local time.
+type TimesheetRow struct {
+	Date     string // YYYY-MM-DD of the start
+	Start    string // HH:MM
+	End      string // HH:MM
+	Duration time.Duration
+	Title    string
+	Category string // the event color's meaning from the color legend
+}
+
+// timesheetRows turns events into timesheet rows in loc. Only time spent is
+// kept: all-day, cancelled, declined and working-location or out-of-office
+// events are skipped, as are unconfirmed holds.
+func timesheetRows(events []*calendar.Event, loc *time.Location, legend ColorLegend) []TimesheetRow {
+	var rows []TimesheetRow
+	for _, e := range events {
+		if e.Status == "cancelled" || e.Start == nil || e.End == nil || e.Start.DateTime == "" {
+			continue
+		}
+		if e.EventType == "workingLocation" || e.EventType == "outOfOffice" {
+			continue
+		}
+		if selfDeclined(e) || isHold(e) {
+			continue
+		}
+		start, err1 := time.Parse(time.RFC3339, e.Start.DateTime)
+		end, err2 := time.Parse(time.RFC3339, e.End.DateTime)
+		if err1 != nil || err2 != nil || !end.After(start) {
+			continue
+		}
+		start, end = start.In(loc), end.In(loc)
+		rows = append(rows, TimesheetRow{
+			Date:     start.Format("2006-01-02"),
+			Start:    start.Format("15:04"),
+			End:      end.Format("15:04"),
+			Duration: end.Sub(start),
+			Title:    eventTitle(e),
+			Category: legend.Category(e),
+		})
+	}
+	return rows
+}
+
+// formatTimesheetCSV renders rows as CSV with a header row. Durations are
+// decimal hours, which time-tracking and billing imports accept.
+func formatTimesheetCSV(rows []TimesheetRow) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(timesheetHeader); err != nil {
+		return "", err
+	}
+	for _, r := range rows {
+		record := []string{r.Date, r.Start, r.End, fmt.Sprintf("%.2f", r.Duration.Hours()), r.Title, r.Category}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// timesheetTotals sums the rows' durations, overall and per category.
+func timesheetTotals(rows []TimesheetRow) (time.Duration, map[string]time.Duration) {
+	var total time.Duration
+	byCategory := make(map[string]time.Duration)
+	for _, r := range rows {
+		total += r.Duration
+		byCategory[r.Category] += r.Duration
+	}
+	return total, byCategory
+}
+
+func (ct *CalendarTools) handleExportTimesheet(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	timeMinStr := getStringOrDefault(arguments, "time_min", "")
+	if timeMinStr == "" {
+		return nil, fmt.Errorf("time_min is required")
+	}
+	timeMaxStr := getStringOrDefault(arguments, "time_max", "")
+	if timeMaxStr == "" {
+		return nil, fmt.Errorf("time_max is required")
+	}
+	timeMin, err := time.Parse(time.RFC3339, timeMinStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_min format: %v", err)
+	}
+	timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_max format: %v", err)
+	}
+	if !timeMax.After(timeMin) {
+		return nil, fmt.Errorf("time_max must be after time_min")
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   ct.calendarID(arguments),
+		TimeFilter:   "custom",
+		TimeMin:      timeMin,
+		TimeMax:      timeMax,
+		TimeZone:     timezone,
+		SingleEvents: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	rows := timesheetRows(events.Items, loc, ct.colorLegend)
+	csvText, err := formatTimesheetCSV(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	total, byCategory := timesheetTotals(rows)
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "🧾 Timesheet %s to %s (%s): %d entries, %.2f hours\n", timeMin.In(loc).Format("2006-01-02"), timeMax.In(loc).Format("2006-01-02"), loc.String(), len(rows), total.Hours())
+	// Per-category totals only help once the color legend categorizes something
+	if _, onlyUncategorized := byCategory[""]; !(onlyUncategorized && len(byCategory) == 1) {
+		var categories []string
+		for category := range byCategory {
+			if category != "" {
+				categories = append(categories, category)
+			}
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Fprintf(&summary, "• %s: %.2f hours\n", category, byCategory[category].Hours())
+		}
+		if d, ok := byCategory[""]; ok {
+			fmt.Fprintf(&summary, "• Uncategorized: %.2f hours\n", d.Hours())
+		}
+	}
+	if events.NextPageToken != "" {
+		summary.WriteString("⚠️ Only the first page of events was exported; use a shorter range for a complete timesheet\n")
+	}
+	summary.WriteString("\nThe CSV follows as a separate content item.")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{
+			{Type: "text", Text: summary.String()},
+			{Type: "text", Text: csvText},
+		},
+	}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- timesheetRows -----
+
+func TestTimesheetRows(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	legend := ColorLegend{"11": "external", defaultColorKey: "internal"}
+
+	external := timedEvent("1", "Client call", "2025-03-03T14:00:00Z", "2025-03-03T15:30:00Z")
+	external.ColorId = "11"
+	declined := timedEvent("2", "Skipped", "2025-03-03T16:00:00Z", "2025-03-03T17:00:00Z")
+	declined.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+	cancelled := timedEvent("3", "Cancelled", "2025-03-03T16:00:00Z", "2025-03-03T17:00:00Z")
+	cancelled.Status = "cancelled"
+	location := timedEvent("4", "Office", "2025-03-03T13:00:00Z", "2025-03-03T22:00:00Z")
+	location.EventType = "workingLocation"
+	allDay := &calendar.Event{Id: "5", Summary: "Holiday", Start: &calendar.EventDateTime{Date: "2025-03-04"}, End: &calendar.EventDateTime{Date: "2025-03-05"}}
+	focus := timedEvent("6", "Deep work", "2025-03-04T04:30:00Z", "2025-03-04T05:00:00Z")
+	focus.EventType = "focusTime"
+
+	rows := timesheetRows([]*calendar.Event{external, declined, cancelled, location, allDay, focus}, loc, legend)
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+
+	want := TimesheetRow{Date: "2025-03-03", Start: "09:00", End: "10:30", Duration: 90 * time.Minute, Title: "Client call", Category: "external"}
+	if rows[0] != want {
+		t.Errorf("row 0 = %+v, want %+v", rows[0], want)
+	}
+	// Dates are local: 04:30 UTC is still the previous evening in New York
+	if rows[1].Date != "2025-03-03" || rows[1].Start != "23:30" || rows[1].Category != "internal" {
+		t.Errorf("row 1 = %+v, want 2025-03-03 23:30 internal", rows[1])
+	}
+}
+
+// ----- formatTimesheetCSV -----
+
+func TestFormatTimesheetCSV(t *testing.T) {
+	rows := []TimesheetRow{
+		{Date: "2025-03-03", Start: "09:00", End: "10:30", Duration: 90 * time.Minute, Title: "Client call, Acme", Category: "external"},
+		{Date: "2025-03-03", Start: "11:00", End: "11:20", Duration: 20 * time.Minute, Title: `Say "hi"`},
+	}
+	got, err := formatTimesheetCSV(rows)
+	if err != nil {
+		t.Fatalf("formatTimesheetCSV: %v", err)
+	}
+	want := strings.Join([]string{
+		"date,start,end,duration,title,category",
+		`2025-03-03,09:00,10:30,1.50,"Client call, Acme",external`,
+		`2025-03-03,11:00,11:20,0.33,"Say ""hi""",`,
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// ----- timesheetTotals -----
+
+func TestTimesheetTotals(t *testing.T) {
+	rows := []TimesheetRow{
+		{Duration: time.Hour, Category: "external"},
+		{Duration: 30 * time.Minute, Category: "external"},
+		{Duration: 15 * time.Minute},
+	}
+	total, byCategory := timesheetTotals(rows)
+	if total != 105*time.Minute {
+		t.Errorf("total = %v, want 1h45m", total)
+	}
+	if byCategory["external"] != 90*time.Minute || byCategory[""] != 15*time.Minute {
+		t.Errorf("byCategory = %v", byCategory)
+	}
+}
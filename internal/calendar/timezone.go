@@ -0,0 +1,138 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time zone arguments are IANA names ("Europe/Paris") or fixed offsets from
+// UTC ("UTC+05:30", "GMT-8", "+0100"). The server binary embeds the tz
+// database, so an IANA name that fails to load is genuinely unknown rather
+// than missing from the host.
+
+// fixedOffsetPattern matches a fixed offset, with an optional UTC or GMT
+// prefix and optional minutes
+var fixedOffsetPattern = regexp.MustCompile(`^(?i:UTC|GMT)?\s*([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// maxOffsetHours is the largest offset from UTC in use anywhere
+const maxOffsetHours = 14
+
+// errUnknownTimeZone explains which time zone values are accepted
+var errUnknownTimeZone = errors.New(`unknown time zone; use an IANA name such as "America/New_York" or a fixed offset such as "UTC+05:30"`)
+
+// loadLocation returns the location named by a time zone argument: an IANA
+// name or a fixed offset from UTC.
+func loadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if offset, ok := parseFixedOffset(name); ok {
+		return time.FixedZone(formatOffset(offset), offset), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errUnknownTimeZone
+	}
+	return loc, nil
+}
+
+// locationOrUTC returns the location named by a time zone argument, warning
+// and falling back to UTC when it is unknown. Handlers validate their
+// timezone argument first, so this only guards internal callers.
+func locationOrUTC(name string) *time.Location {
+	loc, err := loadLocation(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: time zone %q: %v; using UTC\n", name, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// validateTimeZone checks a timezone argument, naming it in the error.
+func validateTimeZone(name string) error {
+	if _, err := loadLocation(name); err != nil {
+		return fmt.Errorf("invalid timezone %q: %v", name, err)
+	}
+	return nil
+}
+
+// parseFixedOffset returns the offset in seconds of a fixed offset time
+// zone, and false when name is not one.
+func parseFixedOffset(name string) (int, bool) {
+	m := fixedOffsetPattern.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes := 0
+	if m[3] != "" {
+		minutes, _ = strconv.Atoi(m[3])
+	}
+	if minutes >= 60 || hours > maxOffsetHours || (hours == maxOffsetHours && minutes > 0) {
+		return 0, false
+	}
+	offset := hours*3600 + minutes*60
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return offset, true
+}
+
+// formatOffset names a fixed offset the way results show it, as UTC+05:30.
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("UTC%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// apiTimeZone returns the time zone to send to the Calendar API, which only
+// takes IANA names. Whole-hour offsets map to the tz database's Etc/GMT
+// zones, whose signs are inverted (UTC+05:00 is Etc/GMT-5). Other offsets
+// have no IANA name, so "" is returned and the offset in the RFC3339 time
+// carries the zone instead.
+func apiTimeZone(name string) string {
+	offset, ok := parseFixedOffset(strings.TrimSpace(name))
+	if !ok {
+		return name
+	}
+	switch {
+	case offset == 0:
+		return "UTC"
+	case offset%3600 != 0 || offset > maxOffsetHours*3600 || offset < -12*3600:
+		return ""
+	case offset > 0:
+		return fmt.Sprintf("Etc/GMT-%d", offset/3600)
+	default:
+		return fmt.Sprintf("Etc/GMT+%d", -offset/3600)
+	}
+}
+
+// checkRecurringTimeZone refuses a time zone with no IANA name for a
+// recurring event: the API expands recurrences in a named time zone only.
+func checkRecurringTimeZone(name string) error {
+	if name != "" && apiTimeZone(name) == "" {
+		return fmt.Errorf("recurring events need a time zone with an IANA name, which %q has not: use one such as \"Asia/Kolkata\"", name)
+	}
+	return nil
+}
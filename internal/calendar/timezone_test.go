@@ -0,0 +1,140 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- loadLocation -----
+
+func TestLoadLocation(t *testing.T) {
+	at := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		offset int // seconds east of UTC at noon on 1 July 2025
+		zone   string
+	}{
+		{"UTC", 0, "UTC"},
+		{"Europe/Paris", 2 * 3600, "CEST"},
+		{"Asia/Kolkata", 5*3600 + 1800, "IST"},
+		{"UTC+05:30", 5*3600 + 1800, "UTC+05:30"},
+		{"utc+5:30", 5*3600 + 1800, "UTC+05:30"},
+		{"GMT-8", -8 * 3600, "UTC-08:00"},
+		{"+0100", 3600, "UTC+01:00"},
+		{"-03:30", -(3*3600 + 1800), "UTC-03:30"},
+		{" UTC+14 ", 14 * 3600, "UTC+14:00"},
+	}
+	for _, tt := range tests {
+		loc, err := loadLocation(tt.name)
+		if err != nil {
+			t.Errorf("loadLocation(%q): %v", tt.name, err)
+			continue
+		}
+		zone, offset := at.In(loc).Zone()
+		if offset != tt.offset || zone != tt.zone {
+			t.Errorf("loadLocation(%q) = %s %+d, want %s %+d", tt.name, zone, offset, tt.zone, tt.offset)
+		}
+	}
+
+	for _, name := range []string{"Mars/Olympus_Mons", "UTC+15", "UTC+05:60", "UTC+14:30", "EST5EDT+1", "+"} {
+		_, err := loadLocation(name)
+		if err == nil || !strings.Contains(err.Error(), "IANA name") {
+			t.Errorf("loadLocation(%q) error = %v, want one explaining the accepted values", name, err)
+		}
+	}
+}
+
+// ----- apiTimeZone -----
+
+func TestAPITimeZone(t *testing.T) {
+	tests := map[string]string{
+		"America/New_York": "America/New_York",
+		"UTC":              "UTC",
+		"UTC+00:00":        "UTC",
+		"UTC+05:00":        "Etc/GMT-5",
+		"GMT-8":            "Etc/GMT+8",
+		"+14":              "Etc/GMT-14",
+		"-12:00":           "Etc/GMT+12",
+		"UTC-13":           "",
+		"UTC+05:30":        "",
+	}
+	for name, want := range tests {
+		if got := apiTimeZone(name); got != want {
+			t.Errorf("apiTimeZone(%q) = %q, want %q", name, got, want)
+		}
+		// Every name sent to the API must load
+		if got := apiTimeZone(name); got != "" {
+			if _, err := time.LoadLocation(got); err != nil {
+				t.Errorf("apiTimeZone(%q) = %q, which does not load: %v", name, got, err)
+			}
+		}
+	}
+}
+
+// ----- create_event with a fixed offset -----
+
+func TestCreateEvent_FixedOffset(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := NewCalendarTools(NewClient(svc, drv))
+
+	arguments := map[string]interface{}{
+		"summary":    "Sync with Bengaluru",
+		"start_time": "2025-03-03T10:00:00+05:30",
+		"end_time":   "2025-03-03T10:30:00+05:30",
+		"timezone":   "UTC+05:30",
+	}
+	result, err := ct.HandleTool("create_event", arguments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := result.StructuredContent.(*calendar.Event)
+	if event.Start.TimeZone != "" {
+		t.Errorf("start time zone = %q, want none for an offset without an IANA name", event.Start.TimeZone)
+	}
+	if start, _ := time.Parse(time.RFC3339, event.Start.DateTime); !start.Equal(time.Date(2025, 3, 3, 4, 30, 0, 0, time.UTC)) {
+		t.Errorf("start = %s, want 04:30 UTC", event.Start.DateTime)
+	}
+
+	arguments["timezone"] = "GMT-8"
+	result, err = ct.HandleTool("create_event", arguments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := result.StructuredContent.(*calendar.Event); event.Start.TimeZone != "Etc/GMT+8" {
+		t.Errorf("start time zone = %q, want Etc/GMT+8", event.Start.TimeZone)
+	}
+
+	arguments["timezone"] = "UTC+05:30"
+	arguments["recurrence"] = []interface{}{"RRULE:FREQ=WEEKLY;COUNT=4"}
+	if _, err := ct.HandleTool("create_event", arguments); err == nil || !strings.Contains(err.Error(), "recurring events need") {
+		t.Errorf("a recurring event at UTC+05:30 should be refused, got %v", err)
+	}
+
+	arguments["timezone"] = "Asia/Bangalore"
+	if _, err := ct.HandleTool("create_event", arguments); err == nil || !strings.Contains(err.Error(), `invalid timezone "Asia/Bangalore"`) {
+		t.Errorf("an unknown time zone should be refused, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+)
+
+// Scope sets tools need, from least to most privileged.
+var (
+	// readScopes read events, calendars and free/busy
+	readScopes = []string{calendar.CalendarReadonlyScope}
+	// freeBusyScopes only query free/busy
+	freeBusyScopes = []string{calendar.CalendarFreebusyScope}
+	// writeScopes change events; calendars are still read to check access
+	writeScopes = []string{calendar.CalendarEventsScope, calendar.CalendarReadonlyScope}
+	// manageScopes create calendars or change the calendar list
+	manageScopes = []string{calendar.CalendarScope}
+)
+
+// toolScopes maps each tool to the minimal OAuth scopes it needs. Tools not
+// listed only use local state, such as preferences, and need none.
+var toolScopes = map[string][]string{
+	"create_event":           writeScopes,
+	"edit_event":             writeScopes,
+	"approve_event":          writeScopes,
+	"delete_event":           writeScopes,
+	"set_working_location":   writeScopes,
+	"get_calendar_colors":    readScopes,
+	"search_attendees":       readScopes,
+	"get_attendee_freebusy":  freeBusyScopes,
+	"list_event_occurrences": readScopes,
+	"list_events":            readScopes,
+	"get_document":           {drive.DriveReadonlyScope},
+	"get_meeting_context":    {calendar.CalendarReadonlyScope, drive.DriveReadonlyScope},
+	"create_holds":           writeScopes,
+	"confirm_hold":           writeScopes,
+	"analyze_series":         readScopes,
+	"series_modify":          writeScopes,
+	"share_availability":     readScopes,
+	"find_duplicates":        readScopes,
+	"merge_duplicates":       writeScopes,
+	"find_recurring_slot":    readScopes,
+	"check_candidate_slots":  readScopes,
+	"availability_heatmap":   readScopes,
+	"render_week_image":      readScopes,
+	"rebalance_one_on_ones":  writeScopes,
+	"suggest_gap_fill":       readScopes,
+	"prepare_for_meeting":    readScopes,
+	"morning_digest":         readScopes,
+	"track_event":            readScopes,
+	"get_event_link":         readScopes,
+	"list_linked_events":     readScopes,
+	"list_policy_violations": readScopes,
+	"export_timesheet":       readScopes,
+	"backup_calendar":        readScopes,
+	"restore_calendar":       writeScopes,
+	"import_events":          writeScopes,
+	"list_shared_calendars":  readScopes,
+	"subscribe_calendar":     manageScopes,
+	"unsubscribe_calendar":   manageScopes,
+	"configure_calendar":     manageScopes,
+	"decline_all":            writeScopes,
+	"schedule_followup":      writeScopes,
+	"split_event":            writeScopes,
+	"schedule_before":        writeScopes,
+	"add_constraint":         readScopes,
+	"compare_agendas":        readScopes,
+	"infer_working_hours":    readScopes,
+	"find_series_conflicts":  readScopes,
+	"cleanup_holds":          writeScopes,
+	"log_note":               manageScopes,
+	"schedule_goals":         writeScopes,
+	"set_default_calendar":   readScopes,
+	"whoami":                 readScopes,
+}
+
+// impliedScopes lists the narrower scopes each scope grants as well.
+var impliedScopes = map[string][]string{
+	calendar.CalendarScope: {
+		calendar.CalendarReadonlyScope, calendar.CalendarEventsScope,
+		calendar.CalendarEventsReadonlyScope, calendar.CalendarFreebusyScope,
+	},
+	calendar.CalendarReadonlyScope: {calendar.CalendarEventsReadonlyScope, calendar.CalendarFreebusyScope},
+	calendar.CalendarEventsScope:   {calendar.CalendarEventsReadonlyScope},
+	drive.DriveScope:               {drive.DriveReadonlyScope, drive.DriveFileScope},
+}
+
+// onDemandScopes are asked for the first time a tool needs them (see
+// Client.SetScopeCheck), so tools needing only these stay available.
+var onDemandScopes = []string{drive.DriveReadonlyScope, drive.DriveFileScope}
+
+// ScopeReport compares the scopes the tools need with those granted.
+type ScopeReport struct {
+	Granted []string `json:"granted"`
+	// Unavailable are the tools hidden because the token lacks scopes they
+	// need, with the missing scopes
+	Unavailable map[string][]string `json:"unavailable_tools,omitempty"`
+	// OnDemand are the tools listed although scopes are missing, because
+	// their first call asks the user to grant them
+	OnDemand map[string][]string `json:"on_demand_tools,omitempty"`
+}
+
+// missingToolScopes returns the scopes tool needs that granted does not
+// cover, directly or through a broader scope.
+func missingToolScopes(tool string, granted []string) []string {
+	covered := make(map[string]bool)
+	for _, s := range granted {
+		covered[s] = true
+		for _, implied := range impliedScopes[s] {
+			covered[implied] = true
+		}
+	}
+	var missing []string
+	for _, s := range toolScopes[tool] {
+		if !covered[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// ToolsForScopes returns the tools usable with the granted scopes and a
+// report of the mismatch. Tools missing a scope that cannot be asked for on
+// demand are left out, so they fail to appear rather than fail when called.
+// With no granted scopes known (a backend without OAuth), every tool is returned.
+func (ct *CalendarTools) ToolsForScopes(granted []string) ([]mcp.Tool, ScopeReport) {
+	report := ScopeReport{Granted: granted}
+	all := ct.GetTools()
+	if granted == nil {
+		return all, report
+	}
+
+	tools := make([]mcp.Tool, 0, len(all))
+	for _, tool := range all {
+		missing := missingToolScopes(tool.Name, granted)
+		switch {
+		case len(missing) == 0:
+			tools = append(tools, tool)
+		case ct.client.scopeCheck != nil && allOnDemand(missing):
+			if report.OnDemand == nil {
+				report.OnDemand = make(map[string][]string)
+			}
+			report.OnDemand[tool.Name] = missing
+			tools = append(tools, tool)
+		default:
+			if report.Unavailable == nil {
+				report.Unavailable = make(map[string][]string)
+			}
+			report.Unavailable[tool.Name] = missing
+		}
+	}
+	return tools, report
+}
+
+func allOnDemand(scopes []string) bool {
+	for _, s := range scopes {
+		if !containsString(onDemandScopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe summarizes the report for the server log, or returns "" when
+// every tool has the scopes it needs.
+func (r ScopeReport) Describe() string {
+	if len(r.Unavailable) == 0 && len(r.OnDemand) == 0 {
+		return ""
+	}
+	var lines []string
+	if len(r.Unavailable) > 0 {
+		lines = append(lines, fmt.Sprintf("Tools hidden for missing OAuth scopes: %s", describeToolScopes(r.Unavailable)))
+	}
+	if len(r.OnDemand) > 0 {
+		lines = append(lines, fmt.Sprintf("Tools that will ask for more access on first use: %s", describeToolScopes(r.OnDemand)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeToolScopes lists tools with their missing scopes, in name order.
+func describeToolScopes(tools map[string][]string) string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%s)", name, strings.Join(tools[name], ", "))
+	}
+	return strings.Join(parts, "; ")
+}
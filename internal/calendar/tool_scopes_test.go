@@ -0,0 +1,95 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+)
+
+// localTools only use local state and need no OAuth scope
+var localTools = []string{"get_color_legend", "define_goal", "list_goals", "define_group", "list_groups", "set_default_send_updates", "get_usage", "list_constraints", "remove_constraint", "server_info"}
+
+func TestToolScopesCoverEveryTool(t *testing.T) {
+	for _, tool := range NewCalendarTools(&Client{}).GetTools() {
+		_, mapped := toolScopes[tool.Name]
+		if mapped == containsString(localTools, tool.Name) {
+			t.Errorf("%s: add it to toolScopes, or to localTools if it makes no API calls", tool.Name)
+		}
+	}
+}
+
+func TestMissingToolScopes(t *testing.T) {
+	if missing := missingToolScopes("create_event", []string{calendar.CalendarScope}); len(missing) != 0 {
+		t.Errorf("the calendar scope should cover create_event, missing %v", missing)
+	}
+	if missing := missingToolScopes("list_events", []string{calendar.CalendarReadonlyScope}); len(missing) != 0 {
+		t.Errorf("calendar.readonly should cover list_events, missing %v", missing)
+	}
+	missing := missingToolScopes("create_event", []string{calendar.CalendarReadonlyScope})
+	if len(missing) != 1 || missing[0] != calendar.CalendarEventsScope {
+		t.Errorf("create_event with calendar.readonly: missing %v, want calendar.events", missing)
+	}
+	if missing := missingToolScopes("get_document", []string{calendar.CalendarScope, drive.DriveScope}); len(missing) != 0 {
+		t.Errorf("the drive scope should cover get_document, missing %v", missing)
+	}
+	if missing := missingToolScopes("define_goal", nil); len(missing) != 0 {
+		t.Errorf("local tools need no scope, missing %v", missing)
+	}
+}
+
+func TestToolsForScopes(t *testing.T) {
+	listed := func(ct *CalendarTools, granted []string) (map[string]bool, ScopeReport) {
+		tools, report := ct.ToolsForScopes(granted)
+		names := make(map[string]bool)
+		for _, tool := range tools {
+			names[tool.Name] = true
+		}
+		return names, report
+	}
+
+	ct := NewCalendarTools(&Client{})
+	all := len(ct.GetTools())
+	if names, report := listed(ct, nil); len(names) != all || report.Describe() != "" {
+		t.Errorf("unknown scopes should list all %d tools, got %d and %q", all, len(names), report.Describe())
+	}
+
+	// Drive access is asked for on first use when a scope check is installed
+	ct.client.SetScopeCheck(func(...string) error { return nil })
+	names, report := listed(ct, []string{calendar.CalendarScope})
+	if len(names) != all || len(report.Unavailable) != 0 {
+		t.Errorf("with the calendar scope every tool should be listed, got %d of %d, hidden %v", len(names), all, report.Unavailable)
+	}
+	if _, ok := report.OnDemand["get_document"]; !ok {
+		t.Errorf("get_document should be reported as asking for Drive access, got %v", report.OnDemand)
+	}
+
+	// A read-only token hides the tools that change events
+	names, report = listed(ct, []string{calendar.CalendarReadonlyScope})
+	if names["create_event"] || !names["list_events"] || !names["define_goal"] {
+		t.Errorf("read-only token: unexpected tools %v", names)
+	}
+	if missing := report.Unavailable["create_event"]; len(missing) != 1 || missing[0] != calendar.CalendarEventsScope {
+		t.Errorf("create_event should be reported missing calendar.events, got %v", missing)
+	}
+
+	// Without a scope check nothing can be asked for, so Drive tools are hidden
+	names, report = listed(NewCalendarTools(&Client{}), []string{calendar.CalendarScope})
+	if names["get_document"] || report.Unavailable["get_document"] == nil {
+		t.Errorf("get_document should be hidden without Drive access, got %v", report)
+	}
+}
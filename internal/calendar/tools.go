@@ -23,24 +23,54 @@ import (
 	"time"
 
 	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+	"gcal-mcp-server/internal/telemetry"
 
 	"google.golang.org/api/calendar/v3"
 )
 
 type CalendarTools struct {
-	client *Client
+	client      *Client
+	budget      *quota.Budget
+	prefs       *PreferenceStore
+	colorLegend ColorLegend
+	fetchLimits FetchLimits
+	handles     eventHandles
+	agenda      agendaState
+	tracked     trackedState
+
+	schedulingPolicy *SchedulingPolicy
+
+	server ServerInfo   // see SetServerInfo
+	scopes *ScopeReport // see SetScopeReport
+}
+
+// SetBudget attaches the request budget that API calls are charged to, so
+// calls can be attributed to tools and reported by get_usage.
+func (ct *CalendarTools) SetBudget(budget *quota.Budget) {
+	ct.budget = budget
+}
+
+// SetPreferences attaches the profile preferences, such as the default
+// calendar, that tools read and set_default_calendar changes.
+func (ct *CalendarTools) SetPreferences(prefs *PreferenceStore) {
+	ct.prefs = prefs
 }
 
 // NewCalendarTools creates a new CalendarTools instance with the given Calendar client.
 func NewCalendarTools(client *Client) *CalendarTools {
 	return &CalendarTools{
-		client: client,
+		client:      client,
+		fetchLimits: DefaultFetchLimits,
 	}
 }
 
+// calendarIDDescription documents the calendar_id argument shared by most tools.
+const calendarIDDescription = "Calendar ID (defaults to the profile's default calendar, normally 'primary'; see set_default_calendar)"
+
 // GetTools returns a slice of MCP tools for calendar operations.
 func (ct *CalendarTools) GetTools() []mcp.Tool {
-	return []mcp.Tool{
+	return withOutputSchemas([]mcp.Tool{
 		{
 			Name:        "create_event",
 			Description: "Create a new calendar event with comprehensive options. Supports all-day events, recurring events, conference data, reminders, and guest permissions.",
@@ -49,8 +79,7 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 				Properties: map[string]interface{}{
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary' for user's main calendar)",
-						"default":     "primary",
+						"description": calendarIDDescription,
 					},
 					"summary": map[string]interface{}{
 						"type":        "string",
@@ -60,34 +89,72 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 						"type":        "string",
 						"description": "Event description/details (RECOMMENDED)",
 					},
+					"offload_description": map[string]interface{}{
+						"type":        "boolean",
+						"description": "When the description is longer than an event holds (8192 characters), save the full text as a Google Doc shared with the attendees, attach it, and keep the start of the text with a link in the description. Without it, an oversize description is rejected",
+						"default":     false,
+					},
+					"create_meeting_notes": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Create a Google Doc for the meeting notes, like Calendar's 'Take meeting notes': it is filled from notes_template (or a default with the title, date, attendees, notes and action items), attached to the event, and the attendees can edit it",
+						"default":     false,
+					},
+					"notes_template": map[string]interface{}{
+						"type":        "string",
+						"description": "Google Doc ID or URL to use as the template for create_meeting_notes; {{title}}, {{date}}, {{time}} and {{attendees}} in it are filled in",
+					},
 					"location": map[string]interface{}{
 						"type":        "string",
 						"description": "Event location (RECOMMENDED for in-person events)",
 					},
 					"start_time": map[string]interface{}{
 						"type":        "string",
-						"description": "Event start time in RFC3339 format (REQUIRED). Example: '2024-01-15T10:00:00-08:00'",
+						"description": "Event start time in RFC3339 format (REQUIRED). Example: '2024-01-15T10:00:00-08:00'. For all-day events, the first day as '2024-01-15'",
 					},
 					"end_time": map[string]interface{}{
 						"type":        "string",
-						"description": "Event end time in RFC3339 format (REQUIRED). Example: '2024-01-15T11:00:00-08:00'",
+						"description": "Event end time in RFC3339 format (REQUIRED). Example: '2024-01-15T11:00:00-08:00'. For all-day events, the last day, inclusive: '2024-01-15' for a one-day event",
 					},
 					"timezone": map[string]interface{}{
 						"type":        "string",
-						"description": "Time zone for the event (defaults to system timezone). Example: 'America/New_York'",
+						"description": "Time zone for the event (defaults to system timezone): an IANA name such as 'America/New_York', or a fixed offset such as 'UTC+05:30'",
 						"default":     "UTC",
 					},
 					"all_day": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Whether this is an all-day event (defaults to false)",
+						"description": "Whether this is an all-day event (defaults to false). start_time and end_time are then the first and last day; the API's exclusive end date is handled for you",
 						"default":     false,
 					},
 					"attendees": map[string]interface{}{
 						"type": "array",
 						"items": map[string]interface{}{
-							"type": "string",
+							"oneOf": []map[string]interface{}{
+								{
+									"type":        "string",
+									"description": "Attendee email address",
+								},
+								{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"email": map[string]interface{}{
+											"type":        "string",
+											"description": "Attendee email address",
+										},
+										"display_name": map[string]interface{}{
+											"type":        "string",
+											"description": "Name shown for the attendee in the invitation, e.g. for external guests not in contacts",
+										},
+										"optional": map[string]interface{}{
+											"type":        "boolean",
+											"description": "Whether attendance is optional (defaults to false)",
+											"default":     false,
+										},
+									},
+									"required": []string{"email"},
+								},
+							},
 						},
-						"description": "List of attendee email addresses (RECOMMENDED for meetings)",
+						"description": "List of attendees (RECOMMENDED for meetings). Can be email strings or objects with email, display_name and optional. An attendee group name (see list_groups) adds all its members",
 					},
 					"recurrence": map[string]interface{}{
 						"type": "array",
@@ -104,8 +171,12 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 					},
 					"send_notifications": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Whether to send email notifications to attendees (defaults to true)",
-						"default":     true,
+						"description": "Whether to send email notifications to attendees (defaults to the profile's default_send_updates, else true)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
 					},
 					"guest_can_modify": map[string]interface{}{
 						"type":        "boolean",
@@ -122,11 +193,14 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 						"description": "Whether guests can see other guests (defaults to true)",
 						"default":     true,
 					},
+					"anyone_can_add_self": anyoneCanAddSelfSchema,
+					"max_attendees":       maxAttendeesSchema,
 					"create_meet_link": map[string]interface{}{
 						"type":        "boolean",
 						"description": "Whether to create a Google Meet link for the event (defaults to false)",
 						"default":     false,
 					},
+					"meet_settings": meetSettingsSchema("How the Meet conference should be run: recording, transcripts, breakout rooms, guest access and notes for the host. The API cannot switch these on, so they are added to the description and the conference notes for the host to apply; requires create_meet_link"),
 					"reminders": map[string]interface{}{
 						"type": "object",
 						"properties": map[string]interface{}{
@@ -161,6 +235,21 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 						"type":        "string",
 						"description": "Event color ID (string). Use standard IDs like '1', '2', '3', etc. for different colors",
 					},
+					"followup_of": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of an earlier event this one follows up on (e.g. a review after a kickoff). The link is stored on this event; use list_linked_events to navigate the chain",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "How important the event is. list_events detect_overlaps suggests moving the lower-priority event of a double booking and never moves a high-priority one",
+						"enum":        []string{"high", "normal", "low"},
+					},
+					"source": eventSourceSchema("Link back to what the event was created from, e.g. the ticket, pull request or doc it is about. Shown on the event in Google Calendar and in list_events"),
+					"propose": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Propose the event instead of booking it, e.g. on a shared team calendar: it is created tentative and marked pending, attendees are not notified, and approve_event confirms it and notifies them",
+						"default":     false,
+					},
 					"eventType": map[string]interface{}{
 						"type":        "string",
 						"description": "Event type: 'default' (normal event), 'focusTime' (dedicated work blocks), 'workingLocation' (location indicators)",
@@ -216,12 +305,20 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 				Properties: map[string]interface{}{
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary')",
-						"default":     "primary",
+						"description": calendarIDDescription,
 					},
 					"event_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Event ID to edit (REQUIRED)",
+						"description": "Event ID to edit, or its handle from list_events such as 'e3' (REQUIRED)",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"instance", "series"},
+						"description": "For recurring events: 'instance' changes only the occurrence identified by event_id (an instance ID like baseId_20240115T100000Z); 'series' applies to every occurrence. Defaults to the kind of ID passed.",
+					},
+					"etag": map[string]interface{}{
+						"type":        "string",
+						"description": "Etag of the event as you last read it (from list_events JSON or a previous edit). If the event has changed since, nothing is modified and the current version is returned so you can re-plan.",
 					},
 					"summary": map[string]interface{}{
 						"type":        "string",
@@ -231,21 +328,26 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 						"type":        "string",
 						"description": "New event description/details",
 					},
+					"offload_description": map[string]interface{}{
+						"type":        "boolean",
+						"description": "When the description is longer than an event holds (8192 characters), save the full text as a Google Doc shared with the attendees, attach it, and keep the start of the text with a link in the description. Without it, an oversize description is rejected",
+						"default":     false,
+					},
 					"location": map[string]interface{}{
 						"type":        "string",
 						"description": "New event location",
 					},
 					"start_time": map[string]interface{}{
 						"type":        "string",
-						"description": "New start time in RFC3339 format",
+						"description": "New start time in RFC3339 format, or the first day ('2024-01-15') of an all-day event",
 					},
 					"end_time": map[string]interface{}{
 						"type":        "string",
-						"description": "New end time in RFC3339 format",
+						"description": "New end time in RFC3339 format, or the last day, inclusive ('2024-01-15'), of an all-day event",
 					},
 					"timezone": map[string]interface{}{
 						"type":        "string",
-						"description": "Time zone for the event",
+						"description": "Time zone for the event: an IANA name such as 'America/New_York', or a fixed offset such as 'UTC+05:30'",
 					},
 					"all_day": map[string]interface{}{
 						"type":        "boolean",
@@ -266,28 +368,95 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 											"type":        "string",
 											"description": "Attendee email address",
 										},
+										"display_name": map[string]interface{}{
+											"type":        "string",
+											"description": "Name shown for the attendee in the invitation",
+										},
+										"optional": map[string]interface{}{
+											"type":        "boolean",
+											"description": "Whether attendance is optional (defaults to false)",
+											"default":     false,
+										},
 										"response_status": map[string]interface{}{
 											"type":        "string",
 											"description": "RSVP response status: 'accepted', 'declined', 'tentative', 'needsAction'",
 											"enum":        []string{"accepted", "declined", "tentative", "needsAction"},
 											"default":     "needsAction",
 										},
+										"comment": map[string]interface{}{
+											"type":        "string",
+											"description": "Note to the organizer sent with the RSVP, e.g. why you declined or are tentative. Omit to keep the existing comment; empty string clears it",
+										},
 									},
 									"required": []string{"email"},
 								},
 							},
 						},
-						"description": "New list of attendees (replaces existing). Can be email strings or objects with email and response_status",
+						"description": "New list of attendees (replaces existing). Can be email strings or objects with email, display_name, optional, response_status and comment. An attendee group name (see list_groups) adds all its members",
+					},
+					"anyone_can_add_self": anyoneCanAddSelfSchema,
+					"max_attendees":       maxAttendeesSchema,
+					"update_attendees": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"email": map[string]interface{}{
+									"type":        "string",
+									"description": "Email address of a current attendee",
+								},
+								"optional": map[string]interface{}{
+									"type":        "boolean",
+									"description": "Mark the attendee optional (true) or required (false)",
+								},
+								"response_status": map[string]interface{}{
+									"type":        "string",
+									"description": "Set the attendee's RSVP, e.g. 'accepted' so they are no longer asked to respond, or 'needsAction' to ask them again",
+									"enum":        []string{"accepted", "declined", "tentative", "needsAction"},
+								},
+							},
+							"required": []string{"email"},
+						},
+						"description": "As organizer, change only these attendees and leave everyone else, including their RSVPs, as it is. Cannot be combined with attendees",
 					},
 					"send_notifications": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Whether to send email notifications to attendees",
-						"default":     true,
+						"description": "Whether to send email notifications to attendees (defaults to the profile's default_send_updates, else true)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
 					},
 					"colorId": map[string]interface{}{
 						"type":        "string",
 						"description": "Event color ID (string). Use standard IDs like '1', '2', '3', etc. for different colors",
 					},
+					"followup_of": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of an earlier event this one follows up on; an empty string removes the link",
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "Event priority: 'high', 'normal' or 'low'; an empty string removes it",
+						"enum":        []string{"high", "normal", "low", ""},
+					},
+					"source": eventSourceSchema("Link back to the ticket, pull request or doc the event is about; replaces the current source. An empty string or source: null removes it"),
+					"remove_conference": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Remove the event's conference (its Google Meet link). Passing conference_data: null does the same",
+						"default":     false,
+					},
+					"conference_data": map[string]interface{}{
+						"type":        "null",
+						"description": "Only null is accepted: removes the event's conference, like remove_conference",
+					},
+					"create_meet_link": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Add a new Google Meet link, replacing the event's current one if it has one (e.g. to regenerate a leaked link)",
+						"default":     false,
+					},
+					"meet_settings": meetSettingsSchema("Replace the Meet settings listed in the description (recording, transcripts, breakout rooms, guest access and notes for the host); an empty object removes them. The event must have a Meet link or get one with create_meet_link; conference notes are only set along with a new link"),
 					"eventType": map[string]interface{}{
 						"type":        "string",
 						"description": "Event type: 'default' (normal event), 'focusTime' (dedicated work blocks), 'workingLocation' (location indicators)",
@@ -312,25 +481,65 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 				Required: []string{"event_id"},
 			},
 		},
+		{
+			Name:        "approve_event",
+			Description: "Approve an event proposed with create_event's propose option, e.g. on a shared team calendar: it becomes confirmed and attendees are notified.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the proposed event",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": "Who is told about the approved event; defaults to the profile's default_send_updates, else 'all'",
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
 		{
 			Name:        "delete_event",
-			Description: "Delete a calendar event permanently.",
+			Description: "Delete a calendar event. What that does depends on who organizes it: deleting an event you organize cancels it for every guest (cancel_for_everyone); deleting an invite you are a guest of, or a private copy, only removes it from your calendar and leaves it unchanged for everyone else (remove_from_my_calendar). Pass mode to make sure the delete has the effect you intend.",
 			InputSchema: mcp.ToolSchema{
 				Type: "object",
 				Properties: map[string]interface{}{
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary')",
-						"default":     "primary",
+						"description": calendarIDDescription,
 					},
 					"event_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Event ID to delete (REQUIRED)",
+						"description": "Event ID to delete, or its handle from list_events such as 'e3' (REQUIRED)",
+					},
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"instance", "series"},
+						"description": "For recurring events: 'instance' deletes only the occurrence identified by event_id (an instance ID like baseId_20240115T100000Z); 'series' deletes every occurrence. Defaults to the kind of ID passed.",
+					},
+					"etag": map[string]interface{}{
+						"type":        "string",
+						"description": "Etag of the event as you last read it. If the event has changed since, it is not deleted and the current version is returned.",
 					},
 					"send_notifications": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Whether to send cancellation notifications to attendees",
-						"default":     true,
+						"description": "Whether to send cancellation notifications to attendees (defaults to the profile's default_send_updates, else true)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"remove_from_my_calendar", "cancel_for_everyone"},
+						"description": "The intended effect. 'remove_from_my_calendar' only removes your copy (invites you are a guest of, private copies); 'cancel_for_everyone' cancels the event for all guests (events you organize). If the event's organizer means the delete would have the other effect, nothing is deleted and the reason is returned. Defaults to whatever deleting does for this event.",
 					},
 				},
 				Required: []string{"event_id"},
@@ -349,8 +558,7 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 					},
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary')",
-						"default":     "primary",
+						"description": calendarIDDescription,
 					},
 					"event_id": map[string]interface{}{
 						"type":        "string",
@@ -403,7 +611,7 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 		},
 		{
 			Name:        "get_attendee_freebusy",
-			Description: "Check free/busy status for attendees during a specific time period.",
+			Description: "Check free/busy status for attendees or Google Groups during a time period: a time_filter such as 'next_week', as in list_events, or explicit time_min and time_max. Groups are expanded to their members, and members whose calendars are not visible are listed.",
 			InputSchema: mcp.ToolSchema{
 				Type: "object",
 				Properties: map[string]interface{}{
@@ -412,23 +620,47 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 						"items": map[string]interface{}{
 							"type": "string",
 						},
-						"description": "List of attendee email addresses to check (REQUIRED)",
+						"description": "List of attendee or Google Group email addresses, or attendee group names (see list_groups), to check; groups are expanded to their members (REQUIRED)",
+					},
+					"group_expansion_max": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of members to expand per Google Group (1-100, defaults to 100). Larger groups are reported as too big rather than partially expanded",
+						"default":     100,
+						"minimum":     1,
+						"maximum":     100,
+					},
+					"time_filter": map[string]interface{}{
+						"type":        "string",
+						"description": "Period to check, as in list_events: 'today', 'tomorrow', 'this_week' and 'next_week' (Mon-Fri, or the whole week with week_mode 'full'), 'this_weekend', 'this_month', 'next_month', 'next_n_days' or 'past_n_days' (see days), or 'custom' with time_min and time_max. Give this or time_min and time_max",
+						"enum":        timeFilters,
+					},
+					"week_mode": map[string]interface{}{
+						"type":        "string",
+						"description": "Days covered by time_filter 'this_week' and 'next_week': 'workweek' (Monday-Friday) or 'full' (all seven days)",
+						"enum":        []string{"workweek", "full"},
+						"default":     "workweek",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days covered by time_filter 'next_n_days' or 'past_n_days', today included (defaults to 7)",
+						"minimum":     1,
+						"default":     7,
 					},
 					"time_min": map[string]interface{}{
 						"type":        "string",
-						"description": "Start time for free/busy query in RFC3339 format (REQUIRED)",
+						"description": "Start time for free/busy query in RFC3339 format (required without time_filter)",
 					},
 					"time_max": map[string]interface{}{
 						"type":        "string",
-						"description": "End time for free/busy query in RFC3339 format (REQUIRED)",
+						"description": "End time for free/busy query in RFC3339 format (required without time_filter)",
 					},
 					"timezone": map[string]interface{}{
 						"type":        "string",
-						"description": "Time zone for the query (defaults to UTC)",
+						"description": "Time zone for the query and the days of time_filter (defaults to UTC)",
 						"default":     "UTC",
 					},
 				},
-				Required: []string{"attendee_emails", "time_min", "time_max"},
+				Required: []string{"attendee_emails"},
 			},
 		},
 		{
@@ -443,8 +675,7 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 					},
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary')",
-						"default":     "primary",
+						"description": calendarIDDescription,
 					},
 					"past_count": map[string]interface{}{
 						"type":        "integer",
@@ -462,21 +693,42 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 		},
 		{
 			Name:        "list_events",
-			Description: "List calendar events with comprehensive filtering options. Supports predefined time filters (today, this_week, next_week) and custom time ranges.",
+			Description: "List calendar events with comprehensive filtering options. Supports predefined time filters (today, this_week, next_week) and custom time ranges. Custom ranges and max_results are capped by configured limits (92 days and 2500 events by default); a request beyond them fails with suggested narrower windows to call instead. Each event comes with a short handle (e1, e2, ...) that other tools accept in place of its event ID for the rest of the session.",
 			InputSchema: mcp.ToolSchema{
 				Type: "object",
 				Properties: map[string]interface{}{
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary' for user's main calendar)",
-						"default":     "primary",
+						"description": calendarIDDescription,
+					},
+					"calendar_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "List several calendars at once instead of calendar_id. They are fetched in parallel; a calendar that cannot be read or does not answer in time is reported with its error while the others' events are still returned, each tagged with its calendar_id",
 					},
 					"time_filter": map[string]interface{}{
 						"type":        "string",
-						"description": "Time filter for events. Options: 'today', 'this_week' (Mon-Fri), 'next_week' (Mon-Fri), 'custom' (requires time_min and time_max)",
-						"enum":        []string{"today", "this_week", "next_week", "custom"},
+						"description": "Time filter for events. Options: 'today', 'tomorrow', 'this_week' and 'next_week' (Mon-Fri, or the whole week with week_mode 'full'), 'this_weekend' (Sat-Sun), 'this_month', 'next_month', 'next_n_days' (today and the following days, see days), 'past_n_days' (the days up to and including today, see days), 'custom' (requires time_min and time_max)",
+						"enum":        timeFilters,
 						"default":     "today",
 					},
+					"week_mode": map[string]interface{}{
+						"type":        "string",
+						"description": "Days covered by time_filter 'this_week' and 'next_week': 'workweek' (Monday-Friday) or 'full' (all seven days, starting on the week start day in the user's Calendar settings)",
+						"enum":        []string{"workweek", "full"},
+						"default":     "workweek",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days covered by time_filter 'next_n_days' or 'past_n_days', today included (defaults to 7)",
+						"minimum":     1,
+						"default":     7,
+					},
+					"include_past": map[string]interface{}{
+						"type":        "boolean",
+						"description": "With time_filter 'today', also list events that have already ended (defaults to false: only meetings in progress and still to come)",
+						"default":     false,
+					},
 					"time_min": map[string]interface{}{
 						"type":        "string",
 						"description": "Start time for custom time range in RFC3339 format (required if time_filter is 'custom')",
@@ -487,7 +739,7 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 					},
 					"timezone": map[string]interface{}{
 						"type":        "string",
-						"description": "Time zone for the query (defaults to UTC). Example: 'America/New_York'",
+						"description": "Time zone for the query (defaults to UTC): an IANA name such as 'America/New_York', or a fixed offset such as 'UTC+05:30'",
 						"default":     "UTC",
 					},
 					"max_results": map[string]interface{}{
@@ -508,14 +760,19 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 					},
 					"show_declined": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Whether to include events that you have declined (defaults to false)",
+						"description": "Whether to include events that you have declined (defaults to false). Declined events are also left out of overlap detection unless this is set",
 						"default":     false,
 					},
 					"detect_overlaps": map[string]interface{}{
 						"type":        "boolean",
-						"description": "Whether to detect and mark overlapping events with has_overlap field (defaults to true)",
+						"description": "Whether to detect and mark overlapping events with has_overlap field (defaults to true). Each conflict is also listed with ready-to-run resolutions (decline the less important event, shorten one, or move one to the nearest free slot), each a single edit_event call",
 						"default":     true,
 					},
+					"annotate_colors": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to label each event with the meaning of its color from the color legend (see get_color_legend)",
+						"default":     false,
+					},
 					"output_format": map[string]interface{}{
 						"type":        "string",
 						"description": "Output format: 'text' for formatted display, 'json' for raw JSON data (defaults to 'text')",
@@ -526,6 +783,29 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 						"type":        "string",
 						"description": "Free-text search query to filter events by title, description, location, or attendees (optional)",
 					},
+					"organizer": map[string]interface{}{
+						"type":        "string",
+						"description": "Only list events organized by this person: an email address (exact match), part of a name or email (case-insensitive), or 'me' for events you organize (optional). Applied after listing, so it narrows the max_results events returned by the API.",
+					},
+					"max_attendees": map[string]interface{}{
+						"type":        "integer",
+						"description": "Return at most this many attendees per event (you are always included) to keep large events small (optional). Truncated events are marked attendeesOmitted.",
+					},
+					"full_attendees": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Re-read events whose attendee list was truncated so every attendee and RSVP is included (up to 25 events per call). Use when you need complete RSVP data for large meetings.",
+						"default":     false,
+					},
+					"attendee_limit": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Attendees to show per event in text output; larger events list the first ones (you are always included) and count the rest by RSVP. 0 shows everyone (defaults to %d)", defaultAttendeeLimit),
+						"default":     defaultAttendeeLimit,
+					},
+					"split_multi_day": map[string]interface{}{
+						"type":        "boolean",
+						"description": "In text output, show events spanning several days, including timed events that cross midnight, under each day they cover in timezone, noting that they continue from the day before or into the next, instead of only under their start day",
+						"default":     false,
+					},
 				},
 				Required: []string{},
 			},
@@ -556,90 +836,1941 @@ func (ct *CalendarTools) GetTools() []mcp.Tool {
 					},
 					"calendar_id": map[string]interface{}{
 						"type":        "string",
-						"description": "Calendar ID (defaults to 'primary')",
+						"description": calendarIDDescription,
 					},
 				},
 				Required: []string{"event_id"},
 			},
 		},
-	}
-}
-
-// HandleTool dispatches tool calls to the appropriate handler based on the tool name.
-func (ct *CalendarTools) HandleTool(name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	switch name {
-	case "create_event":
-		return ct.handleCreateEvent(arguments)
-	case "edit_event":
-		return ct.handleEditEvent(arguments)
-	case "delete_event":
-		return ct.handleDeleteEvent(arguments)
-	case "set_working_location":
-		return ct.handleSetWorkingLocation(arguments)
-	case "get_calendar_colors":
-		return ct.handleGetCalendarColors(arguments)
-	case "search_attendees":
-		return ct.handleSearchAttendees(arguments)
-	case "get_attendee_freebusy":
-		return ct.handleGetAttendeeFreeBusy(arguments)
-	case "list_event_occurrences":
-		return ct.handleListEventOccurrences(arguments)
-	case "list_events":
-		return ct.handleListEvents(arguments)
-	case "get_document":
-		return ct.handleGetDocument(arguments)
-	case "get_meeting_context":
-		return ct.handleGetMeetingContext(arguments)
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
-	}
-}
+		{
+			Name:        "create_holds",
+			Description: "Place tentative \"HOLD:\" events on several candidate time slots while waiting for a meeting time to be confirmed. Attendees are not invited until one hold is confirmed with confirm_hold.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "Title of the meeting being scheduled (REQUIRED). Holds are titled 'HOLD: <summary>'",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Meeting description/details",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone for the holds. Example: 'America/New_York'",
+					},
+					"attendees": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+						"description": "Attendee email addresses or attendee group names (see list_groups) to invite once a hold is confirmed",
+					},
+					"slots": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"start_time": map[string]interface{}{
+									"type":        "string",
+									"description": "Slot start time in RFC3339 format",
+								},
+								"end_time": map[string]interface{}{
+									"type":        "string",
+									"description": "Slot end time in RFC3339 format",
+								},
+							},
+							"required": []string{"start_time", "end_time"},
+						},
+						"description": "Candidate time slots to hold (REQUIRED)",
+					},
+					"decide_by": map[string]interface{}{
+						"type":        "string",
+						"description": "When the meeting time must be decided, in RFC3339 format. Holds still pending then are released by cleanup_holds; without it, each hold is released once its slot has started",
+					},
+				},
+				Required: []string{"summary", "slots"},
+			},
+		},
+		{
+			Name:        "cleanup_holds",
+			Description: "Release holds left behind by create_holds: pending holds of a group whose meeting was already confirmed (e.g. when confirm_hold could not delete them) or cancelled, every hold of a group past its decide_by deadline, and holds whose slot has started. Use dry_run to list them first.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "List the holds that would be released without deleting them (defaults to false)",
+						"default":     false,
+					},
+				},
+			},
+		},
+		{
+			Name:        "confirm_hold",
+			Description: "Convert a hold created by create_holds into the real meeting (restores the title, confirms it and invites attendees) and delete the remaining holds from the same group.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID of the hold to keep (REQUIRED)",
+					},
+					"send_notifications": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to send invitations to attendees (defaults to the profile's default_send_updates, else true)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
+					},
+					"create_meet_link": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to add a Google Meet link to the confirmed meeting (defaults to false)",
+						"default":     false,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "analyze_series",
+			Description: "Analyze the history of a recurring meeting: attendance rate from attendee responses, how often occurrences were cancelled or rescheduled, and average attendee count, with suggestions such as meeting less often.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The recurring event series ID, or any instance ID from the series (REQUIRED)",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"lookback_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days of past occurrences to analyze (defaults to 90, max 730)",
+						"default":     90,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "series_modify",
+			Description: "Change how a recurring series continues: 'end' stops it after last_date (sets UNTIL), 'skip' removes upcoming occurrences by date or the next count of them (adds EXDATEs; occurrences modified on their own are cancelled instead), and 'split' switches to a new recurrence rule from from_date by ending the series there and starting a new one with the same details. Occurrences modified on their own that no longer belong to a series are reported so their changes can be re-applied.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "The recurring event series ID, or any instance ID from the series (REQUIRED)",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"action": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"end", "skip", "split"},
+						"description": "What to change (REQUIRED)",
+					},
+					"last_date": map[string]interface{}{
+						"type":        "string",
+						"description": "For 'end': the last date the series occurs, YYYY-MM-DD in the series' time zone",
+					},
+					"dates": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "For 'skip': original dates (YYYY-MM-DD) of the occurrences to remove",
+					},
+					"count": map[string]interface{}{
+						"type":        "integer",
+						"description": "For 'skip': remove the next N upcoming occurrences instead of naming dates (at most 52)",
+					},
+					"from_date": map[string]interface{}{
+						"type":        "string",
+						"description": "For 'split': the new rule applies from the first occurrence on or after this date (YYYY-MM-DD)",
+					},
+					"recurrence": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "For 'split': the new recurrence rules, e.g. ['RRULE:FREQ=WEEKLY;INTERVAL=2'] for every other week",
+					},
+					"etag": map[string]interface{}{
+						"type":        "string",
+						"description": "Etag of the series as you last read it. If the series has changed since, nothing is modified and the current version is returned.",
+					},
+					"send_notifications": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to send email notifications to attendees (defaults to the profile's default_send_updates, else true)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
+					},
+				},
+				Required: []string{"event_id", "action"},
+			},
+		},
+		{
+			Name:        "share_availability",
+			Description: "Render the user's free time over the next few days as a clean bullet list to paste into an email. Only working hours are offered and slots are rounded to 30-minute increments in the chosen time zone.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of days to cover, starting today (defaults to 5, max 14)",
+						"default":     5,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone for working hours and the rendered times, e.g. 'America/New_York' (defaults to UTC)",
+						"default":     "UTC",
+					},
+					"work_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the working day as HH:MM (defaults to your saved working hours, see infer_working_hours, else 09:00)",
+					},
+					"work_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the working day as HH:MM (defaults to your saved working hours, else 17:00)",
+					},
+					"min_duration_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Shortest free slot to include, in minutes (defaults to 30)",
+						"default":     30,
+					},
+					"include_weekends": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to include Saturdays and Sundays (defaults to false)",
+						"default":     false,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+			},
+		},
+		{
+			Name:        "find_duplicates",
+			Description: "Find events in a time range that look like copies of each other (similar titles and overlapping times, e.g. an invite imported twice). For each set, recommends which copy to keep: the one with conference data and the most attendees.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range to scan in RFC3339 format (defaults to now)",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range to scan in RFC3339 format (defaults to 30 days from now)",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+			},
+		},
+		{
+			Name:        "merge_duplicates",
+			Description: "Merge duplicate events into one: attendees, and a description or location the kept event lacks, are copied from the duplicates onto the kept event, then the duplicates are deleted. Use find_duplicates first to get the IDs.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"keep_event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the copy to keep",
+					},
+					"duplicate_event_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "IDs of the copies to merge into the kept event and delete",
+					},
+					"send_notifications": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to notify attendees of the changes and cancellations (defaults to the profile's default_send_updates, else false)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"keep_event_id", "duplicate_event_ids"},
+			},
+		},
+		{
+			Name:        "find_recurring_slot",
+			Description: "Find a weekly time that is free for all attendees for the next N weeks (e.g. a 30-minute weekly 1:1 for the next 8 weeks). Checks every occurrence with free/busy and returns the best weekly slots with a score; if none is free every week, the closest ones with their conflicting dates and, per attendee, which busy blocks ruled slots out and how many slots only they block. Optional attendees' conflicts lower a slot's score instead of ruling out the week.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"attendees": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"oneOf": []map[string]interface{}{
+								{
+									"type":        "string",
+									"description": "Required attendee's email address",
+								},
+								{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"email": map[string]interface{}{
+											"type":        "string",
+											"description": "Attendee email address",
+										},
+										"optional": map[string]interface{}{
+											"type":        "boolean",
+											"description": "Whether attendance is optional; an optional attendee's conflicts lower the slot's score instead of ruling out the week (defaults to false)",
+											"default":     false,
+										},
+									},
+									"required": []string{"email"},
+								},
+							},
+						},
+						"description": "Attendee email addresses or attendee group names (see list_groups); Google Groups are expanded to their members",
+					},
+					"duration_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Meeting length in minutes (defaults to 30)",
+						"default":     30,
+					},
+					"weeks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of weeks, starting tomorrow, the slot must be free for (defaults to 8, maximum 26)",
+						"default":     8,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone for working hours and results, e.g. 'America/New_York' (defaults to UTC)",
+						"default":     "UTC",
+					},
+					"work_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the working day as HH:MM (defaults to your saved working hours, see infer_working_hours, else 09:00)",
+					},
+					"work_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the working day as HH:MM (defaults to your saved working hours, else 17:00)",
+					},
+					"include_weekends": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to consider Saturdays and Sundays (defaults to false)",
+						"default":     false,
+					},
+					"include_self": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether your own calendar must also be free (defaults to true). Set to false when scheduling for others, e.g. an external partner's meeting you will not attend",
+						"default":     true,
+					},
+					"max_results": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of weekly slots to return (defaults to 5)",
+						"default":     5,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"attendees"},
+			},
+		},
+		{
+			Name:        "check_candidate_slots",
+			Description: "Check a fixed list of candidate times (e.g. the three options from an email thread) against every attendee's free/busy and return a matrix of who is free or busy for each option, with the options that work best. Use find_recurring_slot or share_availability to find new times instead.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"slots": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"start_time": map[string]interface{}{
+									"type":        "string",
+									"description": "Option start time in RFC3339 format",
+								},
+								"end_time": map[string]interface{}{
+									"type":        "string",
+									"description": "Option end time in RFC3339 format",
+								},
+							},
+							"required": []string{"start_time", "end_time"},
+						},
+						"description": fmt.Sprintf("The candidate times to compare, in order (at most %d)", maxCandidateSlots),
+					},
+					"attendees": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"oneOf": []map[string]interface{}{
+								{
+									"type":        "string",
+									"description": "Required attendee's email address",
+								},
+								{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"email": map[string]interface{}{
+											"type":        "string",
+											"description": "Attendee email address",
+										},
+										"optional": map[string]interface{}{
+											"type":        "boolean",
+											"description": "Whether attendance is optional; an optional attendee's conflicts are shown but do not rule an option out (defaults to false)",
+											"default":     false,
+										},
+									},
+									"required": []string{"email"},
+								},
+							},
+						},
+						"description": "Attendee email addresses or attendee group names (see list_groups); Google Groups are expanded to their members",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone for the times in the result, e.g. 'America/New_York' (defaults to UTC)",
+						"default":     "UTC",
+					},
+					"include_self": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to check your own calendar too (defaults to true)",
+						"default":     true,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"slots", "attendees"},
+			},
+		},
+		{
+			Name:        "availability_heatmap",
+			Description: "Build an availability heatmap for a working group: for each weekday and working hour, how many attendees are free on average over the next N days, and on how many days everyone is. Use it to pick standing meeting times for distributed teams.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"attendees": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Attendee email addresses or attendee group names (see list_groups); Google Groups are expanded to their members",
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Horizon in days, starting tomorrow (defaults to 14, maximum 56)",
+						"default":     14,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone for the hours in the heatmap, e.g. 'America/New_York' (defaults to UTC)",
+						"default":     "UTC",
+					},
+					"work_start": map[string]interface{}{
+						"type":        "string",
+						"description": "First hour to include as HH:MM (defaults to 08:00)",
+						"default":     "08:00",
+					},
+					"work_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the last hour to include as HH:MM (defaults to 18:00)",
+						"default":     "18:00",
+					},
+					"include_weekends": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to include Saturdays and Sundays (defaults to false)",
+						"default":     false,
+					},
+					"include_self": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to count your own calendar too (defaults to true)",
+						"default":     true,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"attendees"},
+			},
+		},
+		{
+			Name:        "render_week_image",
+			Description: "Draw a week of events as a PNG image (returned as MCP image content) for clients that show images inline: a column per day and a row per hour, with each event as a block in its event color, or its calendar's color if it has none. All-day events are bars above the hours. Titles do not fit in the image, so a text legend listing each day's events comes with it.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Calendars to draw together, each in its own color (defaults to calendar_id)",
+					},
+					"week_start": map[string]interface{}{
+						"type":        "string",
+						"description": "First day of the week to draw as YYYY-MM-DD (defaults to this week's Monday)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone to draw the week in (defaults to the first calendar's time zone)",
+					},
+					"day_start": map[string]interface{}{
+						"type":        "string",
+						"description": "First hour drawn as HH:MM (defaults to 08:00)",
+						"default":     "08:00",
+					},
+					"day_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the last hour drawn as HH:MM (defaults to 20:00)",
+						"default":     "20:00",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+			},
+		},
+		{
+			Name:        "rebalance_one_on_ones",
+			Description: "Find your weekly recurring 1:1s (series with exactly one other person), show how they are spread across the week, and propose moving 1:1s off overloaded days (e.g. five on Tuesday) to lighter days, at times free for both of you in each of the coming weeks. Proposes only by default; with apply, each moved series is split so earlier occurrences keep their time. Moved or edited occurrences after the split are not carried over.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"max_per_day": map[string]interface{}{
+						"type":        "integer",
+						"description": "Most 1:1s wanted on one day (defaults to an even spread over Monday to Friday)",
+					},
+					"weeks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of weeks, starting tomorrow, a new time must be free for both people (defaults to 4, maximum 26)",
+						"default":     4,
+					},
+					"work_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Earliest start time for a moved 1:1, HH:MM (default: your saved working hours, else 09:00)",
+					},
+					"work_end": map[string]interface{}{
+						"type":        "string",
+						"description": "Latest end time for a moved 1:1, HH:MM (default: your saved working hours, else 17:00)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone for weekdays and times (defaults to the calendar's time zone)",
+					},
+					"apply": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Move the 1:1s as proposed (default: false, only propose)",
+						"default":     false,
+					},
+					"series_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "With apply, move only these proposed 1:1s (their series_id)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": sendUpdatesDescription,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+			},
+		},
+		{
+			Name:        "suggest_gap_fill",
+			Description: "After an event is declined or cancelled, suggest how to use the freed time: extend adjacent focus time, move a pending (not yet answered) invite you are allowed to reschedule and whose attendees are free into the gap, or leave it free. Each option includes the tool and arguments for the single follow-up call that carries it out.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID of the declined or cancelled event",
+					},
+					"start_time": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the freed block in RFC3339 format, instead of event_id (e.g. when the event was deleted)",
+					},
+					"end_time": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the freed block in RFC3339 format, instead of event_id",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"lookahead_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many days after the event to look for pending invites to pull forward (defaults to 5, at most 14)",
+						"default":     5,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone for displaying times (defaults to UTC). Example: 'America/New_York'",
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "prepare_for_meeting",
+			Description: "Assemble a briefing for one meeting: time, location, organizer, description/agenda, attached Drive documents (titles, links and file IDs for get_document), attendees with their RSVP state, the conference link, and — for recurring meetings — the previous occurrence's description, documents and declines. A series ID prepares for its next occurrence.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID of the meeting (an occurrence ID for one meeting of a series, or the series ID for its next occurrence)",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "morning_digest",
+			Description: "Compile what needs attention on a day before it starts, in one response: the first meeting and meeting count, invites you have not answered, overlapping meetings, in-person meetings (with a warning when there is little free time to get there), and changes to events followed with track_event. Defaults to today in the calendar's time zone.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"date": map[string]interface{}{
+						"type":        "string",
+						"description": "Day to summarize: 'today', 'tomorrow' or YYYY-MM-DD (defaults to 'today')",
+						"default":     "today",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone of the day (defaults to the calendar's time zone). Example: 'America/New_York'",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{},
+			},
+		},
+		{
+			Name:        "track_event",
+			Description: fmt.Sprintf("Follow one event, such as an important meeting someone else organizes, and be told when its time or attendees (including their RSVPs) change or it is cancelled. Changes are reported by morning_digest and in the %s resource, which notifies subscribed clients. Tracking is saved in the profile; stop it with stop: true.", TrackedResourceURI),
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID (an occurrence ID follows that occurrence only)",
+					},
+					"stop": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Stop tracking the event instead (defaults to false)",
+						"default":     false,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "get_event_link",
+			Description: "Get the direct Google Calendar web link, the Google Meet link and the dial-in phone numbers with their PINs of an event, for quick sharing.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID (an occurrence ID returns that occurrence's link)",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "list_linked_events",
+			Description: "Navigate a chain of linked events (e.g. kickoff → review → retro): the events the given event follows up on, oldest first, and every follow-up linked to it with followup_of.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of any event in the chain",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "list_policy_violations",
+			Description: "Audit the calendar against the scheduling policy (rules such as no meetings after 17:00, no-meeting Fridays, a daily cap on meeting hours, or no meetings over focus time and out-of-office blocks) and list every meeting or day that breaks a rule.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range to audit in RFC3339 format (defaults to now)",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range to audit in RFC3339 format (defaults to 7 days from now)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone rules are evaluated in when the policy does not set one (defaults to UTC)",
+						"default":     "UTC",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+			},
+		},
+		{
+			Name:        "export_timesheet",
+			Description: "Export the events in a time range as CSV rows (date, start, end, duration in hours, title, category) for importing into time-tracking or billing systems. Categories come from the event color legend (see get_color_legend). All-day, cancelled, declined, working-location and out-of-office events are left out.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range in RFC3339 format (REQUIRED)",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range in RFC3339 format (REQUIRED)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "IANA time zone for the dates and times in the CSV (defaults to UTC)",
+						"default":     "UTC",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"time_min", "time_max"},
+			},
+		},
+		{
+			Name:        "backup_calendar",
+			Description: "Snapshot every event of a calendar in a time range, including recurrence rules, modified and cancelled instances and extended properties, as JSON. Take one before bulk changes; restore_calendar brings the calendar back to it. Writes the backup to file when given, otherwise returns it as a separate content item.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range in RFC3339 format (REQUIRED)",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range in RFC3339 format (REQUIRED)",
+					},
+					"file": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of a file to write the backup to instead of returning it",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+				},
+				Required: []string{"time_min", "time_max"},
+			},
+		},
+		{
+			Name:        "restore_calendar",
+			Description: "Restore a calendar from a backup_calendar snapshot: events deleted since the backup are recreated and cancelled instances cancelled again. Events changed since the backup are reported and only reverted with overwrite; events created after the backup are left alone. Use dry_run to preview.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"file": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of a backup file written by backup_calendar",
+					},
+					"backup": map[string]interface{}{
+						"type":        "string",
+						"description": "Backup JSON returned by backup_calendar, when not using file",
+					},
+					"overwrite": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Revert events changed since the backup to their backed-up version (default: false)",
+						"default":     false,
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Report what would be restored without changing the calendar (default: false)",
+						"default":     false,
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": "Who gets email notifications for restored events: 'all' guests, 'externalOnly', or 'none'. Defaults to the profile's default_send_updates, else none",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar to restore into (defaults to the calendar the backup was taken from)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "import_events",
+			Description: "Copy events into a calendar with the Calendar API's import, for migrations: unlike create_event, each copy keeps its iCalUID and original organizer, attendees' responses are kept and nobody is invited or emailed again. Events come from another calendar (source_calendar_id with time_min and time_max, including recurring series and their modified instances) or from an iCalendar (.ics) export given as ics text or a file. An event whose iCalUID is already in the calendar is updated instead of duplicated. Use dry_run to preview.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"source_calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar to copy events from",
+					},
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range to copy in RFC3339 format (required with source_calendar_id)",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range to copy in RFC3339 format (required with source_calendar_id)",
+					},
+					"ics": map[string]interface{}{
+						"type":        "string",
+						"description": "iCalendar (.ics) text whose VEVENTs to import",
+					},
+					"file": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of an iCalendar (.ics) file to import",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone for iCalendar times without one (floating times): an IANA name such as 'America/New_York', or a fixed offset such as 'UTC+05:30'. TZID parameters must be IANA names",
+						"default":     "UTC",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Report what would be imported without changing the calendar (default: false)",
+						"default":     false,
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar to import into (defaults to the profile's default calendar, normally 'primary')",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_color_legend",
+			Description: "Show what each event color means (e.g. red = external meeting, green = focus time), as configured for this server. Use list_events with annotate_colors to label events with these categories.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "list_shared_calendars",
+			Description: "List the calendars in your calendar list that other people own and have shared with you (e.g. a manager's or direct report's), with your access to each: event details, edit, or free/busy only. Pass their IDs as calendar_id to list_events, or to get_attendee_freebusy.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"people_only": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Only list people's calendars, leaving out group, holiday and resource calendars (defaults to false)",
+						"default":     false,
+					},
+				},
+			},
+		},
+		{
+			Name:        "subscribe_calendar",
+			Description: "Add an existing calendar to the user's calendar list by ID, such as a public holiday calendar (e.g. en.usa#holiday@group.v.calendar.google.com) or a team or colleague's calendar shared with the user, and choose how it is shown.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the calendar to add",
+					},
+					"selected": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether the calendar's events are shown in the Calendar UI",
+						"default":     true,
+					},
+					"hidden": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to hide the calendar from the calendar list in the Calendar UI",
+						"default":     false,
+					},
+					"color_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar color ID (see get_calendar_colors); omit for the default color",
+					},
+					"summary_override": map[string]interface{}{
+						"type":        "string",
+						"description": "Name to show for the calendar instead of its own",
+					},
+				},
+				Required: []string{"calendar_id"},
+			},
+		},
+		{
+			Name:        "configure_calendar",
+			Description: "Change how a calendar in the user's calendar list is shown and what it notifies about: its color, whether it is hidden from the list or its events are shown, the name shown for it, its default reminders and its email notifications. Only the user's own view of the calendar changes. Fields that are left out keep their current values.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar": map[string]interface{}{
+						"type":        "string",
+						"description": "ID or name of a calendar in your calendar list (e.g. 'primary', 'Holidays in United States')",
+					},
+					"hidden": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to hide the calendar from the calendar list in the Calendar UI",
+					},
+					"selected": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether the calendar's events are shown in the Calendar UI",
+					},
+					"color_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar color ID (see get_calendar_colors to pick one, e.g. a purple); empty returns to the default color",
+					},
+					"summary_override": map[string]interface{}{
+						"type":        "string",
+						"description": "Name to show for the calendar instead of its own; empty shows its own name again",
+					},
+					"default_reminders": map[string]interface{}{
+						"type":        "array",
+						"description": fmt.Sprintf("Reminders added to events on this calendar that don't set their own (at most %d); an empty array removes them", maxDefaultReminders),
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"method": map[string]interface{}{
+									"type":        "string",
+									"enum":        []string{"email", "popup"},
+									"description": "Reminder method",
+								},
+								"minutes": map[string]interface{}{
+									"type":        "integer",
+									"description": fmt.Sprintf("Minutes before the event (0-%d)", maxReminderMinutes),
+								},
+							},
+							"required": []string{"method", "minutes"},
+						},
+					},
+					"notifications": map[string]interface{}{
+						"type":        "array",
+						"description": "Changes to be emailed about: new events, changed events, cancelled events, guest responses and a daily agenda; replaces the current choice, and an empty array turns email notifications off",
+						"items": map[string]interface{}{
+							"type": "string",
+							"enum": calendarNotificationTypes,
+						},
+					},
+				},
+				Required: []string{"calendar"},
+			},
+		},
+		{
+			Name:        "unsubscribe_calendar",
+			Description: "Remove a calendar from the user's calendar list. The calendar and its events are not deleted; it can be added back with subscribe_calendar.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the calendar to remove",
+					},
+				},
+				Required: []string{"calendar_id"},
+			},
+		},
+		{
+			Name:        "decline_all",
+			Description: "Decline every meeting you were invited to in a date range, e.g. before a vacation, with an optional message to the organizers. Meetings you organize are left alone; 1:1s and meetings organized by specific people can be skipped too. Recurring meetings are declined only for the occurrences in the range. Returns the declined, skipped and failed meetings; use dry_run to review the list first.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range in RFC3339 format",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range in RFC3339 format",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "Note sent with each decline (e.g. 'Out on vacation until the 14th')",
+					},
+					"skip_one_on_ones": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Leave meetings with just you and one other person alone",
+						"default":     false,
+					},
+					"skip_organizers": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Leave meetings organized by these people alone: email addresses, or part of a name",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": "Who is told about the declines; defaults to the profile's default_send_updates, else 'all' so organizers see them",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Timezone for the listed times (e.g., 'America/New_York')",
+						"default":     "UTC",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "List the meetings that would be declined without answering them",
+						"default":     false,
+					},
+				},
+				Required: []string{"time_min", "time_max"},
+			},
+		},
+		{
+			Name:        "schedule_followup",
+			Description: "Schedule a follow-up to a meeting N days or weeks later (one week by default) at the same time and length, with the same attendees, location and Meet link setting. Availability is checked again: if you or a required attendee is busy then, the nearest time everyone is free on that day or the next weekdays is used. The follow-up is linked to the original (see list_linked_events) and its description names the original meeting and carries over the start of its description.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID of the meeting to follow up on (REQUIRED)",
+					},
+					"after_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Days after the meeting; added to after_weeks",
+					},
+					"after_weeks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Weeks after the meeting (1 when neither after_days nor after_weeks is given)",
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": "Who is sent the invitation; defaults to the profile's default_send_updates, else 'all'",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Show when the follow-up would be without creating it",
+						"default":     false,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "split_event",
+			Description: "Split a long block, such as a 4-hour focus block, into shorter events with optional breaks between them: either a number of equal parts or parts of a given length. The original event becomes the first part; the others are copies keeping its title, description, color, attendees and settings. Only single timed events you organize can be split.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"event_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Event ID of the block to split (REQUIRED)",
+					},
+					"parts": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of equal parts (at least 2); give this or part_minutes",
+					},
+					"part_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Length of each part in minutes, from the start of the block; the last part may be shorter. Give this or parts",
+					},
+					"break_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Free minutes left between parts",
+						"default":     0,
+					},
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        sendUpdatesValues,
+						"description": "Who is told about the change when the block has attendees; defaults to the profile's default_send_updates, else 'all'",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Show the parts without changing the calendar",
+						"default":     false,
+					},
+				},
+				Required: []string{"event_id"},
+			},
+		},
+		{
+			Name:        "schedule_before",
+			Description: "Fit a task that needs a number of hours of work before a deadline: find free working time on the calendar between now and the deadline and book it as one or more blocks (earliest first, with a short break between blocks on the same stretch of free time). Nothing is booked if the hours do not fit. Returns the plan with every event created.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "Title of the task, used for the blocks; numbered when there is more than one (REQUIRED)",
+					},
+					"hours": map[string]interface{}{
+						"type":        "number",
+						"description": "Hours of work the task needs, e.g. 4 or 2.5 (REQUIRED)",
+					},
+					"deadline": map[string]interface{}{
+						"type":        "string",
+						"description": "When the task is due, within the next 31 days: a date (YYYY-MM-DD), meaning the work is done before that day starts, or an RFC3339 time (REQUIRED)",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Description for the blocks",
+					},
+					"min_block_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Shortest block worth booking, a multiple of 30 (defaults to 60); the last block may be shorter when that is all the task still needs",
+						"default":     defaultDeadlineBlockMinutes,
+					},
+					"max_block_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Longest single block, a multiple of 30 (defaults to 120)",
+						"default":     defaultDeadlineMaxBlockMinutes,
+					},
+					"work_start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the working day as HH:MM (defaults to your saved working hours, see infer_working_hours, else 09:00)",
+					},
+					"work_end": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the working day as HH:MM (defaults to your saved working hours, else 17:00)",
+					},
+					"include_weekends": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether blocks may be booked on Saturdays and Sundays (defaults to false)",
+						"default":     false,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone of the working hours and a date deadline (defaults to the calendar's time zone)",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Show the blocks that would be booked without creating them",
+						"default":     false,
+					},
+				},
+				Required: []string{"summary", "hours", "deadline"},
+			},
+		},
+		{
+			Name:        "add_constraint",
+			Description: "Save a recurring time you are never available, e.g. 'Friday afternoons' every Friday 13:00–17:00, in the current profile. share_availability, find_recurring_slot, schedule_before, schedule_followup and schedule_goals then never offer or book that time. Adding an existing name replaces it.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the constraint, e.g. 'Friday afternoons' (REQUIRED)",
+					},
+					"days": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Weekdays it applies to, e.g. ['Friday'] (REQUIRED)",
+					},
+					"start": map[string]interface{}{
+						"type":        "string",
+						"description": "Start time of day as HH:MM (REQUIRED)",
+					},
+					"end": map[string]interface{}{
+						"type":        "string",
+						"description": "End time of day as HH:MM (REQUIRED)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Time zone of the days and times (defaults to the calendar's time zone)",
+					},
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar whose time zone is used when timezone is omitted (defaults to the default calendar)",
+					},
+				},
+				Required: []string{"name", "days", "start", "end"},
+			},
+		},
+		{
+			Name:        "list_constraints",
+			Description: "List the recurring availability constraints saved in the current profile with add_constraint.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "remove_constraint",
+			Description: "Remove a recurring availability constraint from the current profile, so its time can be offered and booked again.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the constraint (REQUIRED)",
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			Name:        "infer_working_hours",
+			Description: "Infer the user's working hours from the past month of events: the typical start and end of the day (medians of each day's first and last event), meetings per weekday and the busiest hours. With save: true the hours are saved as the default working day of share_availability, find_recurring_slot, rebalance_one_on_ones and schedule_before; otherwise nothing is changed, so show the result to the user and offer to save it.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of past days to analyze, ending yesterday (defaults to 30, at least 7)",
+						"default":     30,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Timezone of the working hours (defaults to the calendar's time zone)",
+					},
+					"save": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Save the inferred hours as your working hours preference (defaults to false)",
+						"default":     false,
+					},
+				},
+			},
+		},
+		{
+			Name:        "find_series_conflicts",
+			Description: "Find recurring series that collide with each other, e.g. a weekly staff meeting and a biweekly review. Each pair of series is reported once with the pattern of its collisions (e.g. every other Tuesday, 10:00 AM–10:30 AM) rather than one conflict per occurrence, plus fixes for the whole series: moving the less important series to the nearest time free of conflicts every week, skipping only the colliding occurrences, or declining the series. Each fix comes with the call that carries it out; nothing is changed.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"weeks": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of weeks ahead to check (defaults to 8, at most 13)",
+						"default":     8,
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Timezone for dates and times (defaults to the calendar's time zone)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "compare_agendas",
+			Description: "Compare two agendas and report the events added, removed or moved: a time range against an earlier one (by default the range of the same length just before, e.g. this week vs last week), or the same range on two calendars, e.g. after a reorganization or a sync from another system. Events are matched by recurring series or event UID, then by similar titles; an event is moved when it sits at a different point of its range or lasts a different time.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range in RFC3339 format",
+					},
+					"time_max": map[string]interface{}{
+						"type":        "string",
+						"description": "End of the range in RFC3339 format",
+					},
+					"compare_calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar to compare against (defaults to calendar_id); when it differs, the same range is compared unless compare_time_min is set",
+					},
+					"compare_time_min": map[string]interface{}{
+						"type":        "string",
+						"description": "Start of the range to compare against in RFC3339 format; it has the same length as time_min to time_max (defaults to the range just before)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Timezone for the listed times and for placing events within their range (e.g., 'America/New_York')",
+						"default":     "UTC",
+					},
+				},
+				Required: []string{"time_min", "time_max"},
+			},
+		},
+		{
+			Name:        "log_note",
+			Description: "Log a note in the user's private journal calendar (named 'Journal', created on first use), e.g. \"log that I spent 2 hours on incident response\". The note is an event covering the time just spent, ending at 'at' (now by default); a duration of 0 marks a moment. Notes are private and do not block the user's time.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"note": map[string]interface{}{
+						"type":        "string",
+						"description": "What to log; becomes the event title (e.g. 'Incident response')",
+					},
+					"details": map[string]interface{}{
+						"type":        "string",
+						"description": "Longer notes, stored as the event description",
+					},
+					"duration_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Minutes the note covers, ending at 'at' (e.g. 120 for 'spent 2 hours'); 0 logs a moment, shown as one minute",
+						"default":     defaultNoteMinutes,
+						"minimum":     0,
+						"maximum":     1440,
+					},
+					"at": map[string]interface{}{
+						"type":        "string",
+						"description": "When the logged time ended, in RFC3339 format (defaults to now)",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Timezone for the note (e.g., 'America/New_York')",
+						"default":     "UTC",
+					},
+				},
+				Required: []string{"note"},
+			},
+		},
+		{
+			Name:        "define_goal",
+			Description: "Save a weekly goal in the current profile, such as '3 hours of writing per week'. schedule_goals then books blocks of time for it on free parts of the calendar and moves them when they start to conflict with other events. Defining an existing name replaces the goal; hours_per_week 0 deletes it.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Goal name (e.g. 'Writing'); blocks are titled with it (case-insensitive)",
+					},
+					"hours_per_week": map[string]interface{}{
+						"type":        "number",
+						"description": "Hours to book each week (e.g. 3 or 1.5); 0 deletes the goal",
+						"minimum":     0,
+					},
+					"block_minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "Length of each block, a multiple of 30 minutes",
+						"default":     defaultGoalBlockMinutes,
+					},
+					"days": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Weekdays blocks may be booked on (e.g. ['Tuesday', 'Thursday']); Monday to Friday when omitted",
+					},
+					"earliest": map[string]interface{}{
+						"type":        "string",
+						"description": "Earliest time of day for a block (HH:MM)",
+						"default":     "09:00",
+					},
+					"latest": map[string]interface{}{
+						"type":        "string",
+						"description": "Latest time of day for a block to end (HH:MM)",
+						"default":     "17:00",
+					},
+				},
+				Required: []string{"name", "hours_per_week"},
+			},
+		},
+		{
+			Name:        "list_goals",
+			Description: "List the weekly goals saved in the current profile with define_goal.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "schedule_goals",
+			Description: "Book this week's (or next week's) blocks for every goal saved with define_goal. Blocks already booked are kept; ones that now overlap another event are moved to a free slot, or removed when none is left, and new blocks are booked until each goal's weekly hours are reached, one per day first. Safe to run again at any time.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar_id": map[string]interface{}{
+						"type":        "string",
+						"description": calendarIDDescription,
+					},
+					"week": map[string]interface{}{
+						"type":        "string",
+						"description": "Week to schedule",
+						"enum":        []string{"this", "next"},
+						"default":     "this",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "Timezone the goals' hours are in (defaults to the calendar's timezone)",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Show what would be booked, moved and removed without changing the calendar",
+						"default":     false,
+					},
+				},
+			},
+		},
+		{
+			Name:        "define_group",
+			Description: "Save a named attendee group (e.g. 'platform-team') in the current profile. The name can then be used in place of its members anywhere attendees are accepted: create_event, edit_event, get_attendee_freebusy, find_recurring_slot, availability_heatmap and create_holds. Defining an existing name replaces its members; an empty members list deletes the group.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Group name, without '@' or spaces (case-insensitive)",
+					},
+					"members": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Member email addresses; Google Group addresses are allowed. Empty to delete the group",
+					},
+				},
+				Required: []string{"name", "members"},
+			},
+		},
+		{
+			Name:        "list_groups",
+			Description: "List the attendee groups saved in the current profile with define_group, with their members.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "set_default_calendar",
+			Description: "Choose the calendar that tools use when called without calendar_id (e.g. a shared 'Team' calendar instead of your primary one). The choice is saved for the current profile and kept across sessions.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"calendar": map[string]interface{}{
+						"type":        "string",
+						"description": "Calendar ID or name as shown in your calendar list; 'primary' restores your own calendar",
+					},
+				},
+				Required: []string{"calendar"},
+			},
+		},
+		{
+			Name:        "set_default_send_updates",
+			Description: "Choose who is notified by default when tools create, change or delete events without send_updates or send_notifications: 'all' guests, 'externalOnly', or 'none' (e.g. to avoid notification spam while experimenting). Saved for the current profile; an explicit argument still overrides it per call.",
+			InputSchema: mcp.ToolSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"send_updates": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"all", "externalOnly", "none", "reset"},
+						"description": "Default notification behavior; 'reset' restores each tool's own default",
+					},
+				},
+				Required: []string{"send_updates"},
+			},
+		},
+		{
+			Name:        "whoami",
+			Description: "Show the signed-in Google account, its time zone, the active profile, and the default calendar used when calendar_id is omitted.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "server_info",
+			Description: "Show the server's version and build, the backend, the active profile, which optional subsystems (warm cache, background jobs, tracing, policies) are running, and the granted OAuth scopes with any tools they leave out. Use it to debug tools or features that seem to be missing.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "get_usage",
+			Description: "Report the Google API calls made this session, broken down by API and by tool, and how much of the per-minute request budget remains.",
+			InputSchema: mcp.ToolSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{},
+			},
+		},
+	})
+}
+
+// HandleTool dispatches tool calls to the appropriate handler based on the tool name.
+func (ct *CalendarTools) HandleTool(name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.budget != nil {
+		ct.budget.SetTool(name)
+	}
+	// API request spans carry the calendar from their path; the tool span
+	// records the calendar the caller asked for
+	if _, ok := arguments["calendar_id"]; ok {
+		telemetry.SetCalendarID(ct.calendarID(arguments))
+	}
+	if err := ct.expandGroupArguments(arguments); err != nil {
+		return nil, err
+	}
+	if err := ct.resolveEventHandles(arguments); err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "create_event":
+		return ct.handleCreateEvent(arguments)
+	case "edit_event":
+		return ct.handleEditEvent(arguments)
+	case "approve_event":
+		return ct.handleApproveEvent(arguments)
+	case "delete_event":
+		return ct.handleDeleteEvent(arguments)
+	case "set_working_location":
+		return ct.handleSetWorkingLocation(arguments)
+	case "get_calendar_colors":
+		return ct.handleGetCalendarColors(arguments)
+	case "search_attendees":
+		return ct.handleSearchAttendees(arguments)
+	case "get_attendee_freebusy":
+		return ct.handleGetAttendeeFreeBusy(arguments)
+	case "list_event_occurrences":
+		return ct.handleListEventOccurrences(arguments)
+	case "list_events":
+		return ct.handleListEvents(arguments)
+	case "get_document":
+		return ct.handleGetDocument(arguments)
+	case "get_meeting_context":
+		return ct.handleGetMeetingContext(arguments)
+	case "create_holds":
+		return ct.handleCreateHolds(arguments)
+	case "confirm_hold":
+		return ct.handleConfirmHold(arguments)
+	case "analyze_series":
+		return ct.handleAnalyzeSeries(arguments)
+	case "series_modify":
+		return ct.handleSeriesModify(arguments)
+	case "share_availability":
+		return ct.handleShareAvailability(arguments)
+	case "find_duplicates":
+		return ct.handleFindDuplicates(arguments)
+	case "merge_duplicates":
+		return ct.handleMergeDuplicates(arguments)
+	case "find_recurring_slot":
+		return ct.handleFindRecurringSlot(arguments)
+	case "check_candidate_slots":
+		return ct.handleCheckCandidateSlots(arguments)
+	case "availability_heatmap":
+		return ct.handleAvailabilityHeatmap(arguments)
+	case "render_week_image":
+		return ct.handleRenderWeekImage(arguments)
+	case "rebalance_one_on_ones":
+		return ct.handleRebalanceOneOnOnes(arguments)
+	case "suggest_gap_fill":
+		return ct.handleSuggestGapFill(arguments)
+	case "prepare_for_meeting":
+		return ct.handlePrepareForMeeting(arguments)
+	case "morning_digest":
+		return ct.handleMorningDigest(arguments)
+	case "track_event":
+		return ct.handleTrackEvent(arguments)
+	case "get_event_link":
+		return ct.handleGetEventLink(arguments)
+	case "list_linked_events":
+		return ct.handleListLinkedEvents(arguments)
+	case "list_policy_violations":
+		return ct.handleListPolicyViolations(arguments)
+	case "export_timesheet":
+		return ct.handleExportTimesheet(arguments)
+	case "backup_calendar":
+		return ct.handleBackupCalendar(arguments)
+	case "restore_calendar":
+		return ct.handleRestoreCalendar(arguments)
+	case "import_events":
+		return ct.handleImportEvents(arguments)
+	case "get_color_legend":
+		return ct.handleGetColorLegend(arguments)
+	case "list_shared_calendars":
+		return ct.handleListSharedCalendars(arguments)
+	case "subscribe_calendar":
+		return ct.handleSubscribeCalendar(arguments)
+	case "unsubscribe_calendar":
+		return ct.handleUnsubscribeCalendar(arguments)
+	case "configure_calendar":
+		return ct.handleConfigureCalendar(arguments)
+	case "decline_all":
+		return ct.handleDeclineAll(arguments)
+	case "schedule_followup":
+		return ct.handleScheduleFollowup(arguments)
+	case "split_event":
+		return ct.handleSplitEvent(arguments)
+	case "schedule_before":
+		return ct.handleScheduleBefore(arguments)
+	case "add_constraint":
+		return ct.handleAddConstraint(arguments)
+	case "list_constraints":
+		return ct.handleListConstraints(arguments)
+	case "remove_constraint":
+		return ct.handleRemoveConstraint(arguments)
+	case "cleanup_holds":
+		return ct.handleCleanupHolds(arguments)
+	case "find_series_conflicts":
+		return ct.handleFindSeriesConflicts(arguments)
+	case "infer_working_hours":
+		return ct.handleInferWorkingHours(arguments)
+	case "compare_agendas":
+		return ct.handleCompareAgendas(arguments)
+	case "log_note":
+		return ct.handleLogNote(arguments)
+	case "define_goal":
+		return ct.handleDefineGoal(arguments)
+	case "list_goals":
+		return ct.handleListGoals(arguments)
+	case "schedule_goals":
+		return ct.handleScheduleGoals(arguments)
+	case "define_group":
+		return ct.handleDefineGroup(arguments)
+	case "list_groups":
+		return ct.handleListGroups(arguments)
+	case "set_default_calendar":
+		return ct.handleSetDefaultCalendar(arguments)
+	case "set_default_send_updates":
+		return ct.handleSetDefaultSendUpdates(arguments)
+	case "whoami":
+		return ct.handleWhoami(arguments)
+	case "get_usage":
+		return ct.handleGetUsage(arguments)
+	case "server_info":
+		return ct.handleServerInfo(arguments)
+	default:
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+}
+
+func (ct *CalendarTools) handleCreateEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	params, err := ct.parseEventParams(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %v", err)
+	}
+	if err := checkAttendeeCap(params.Summary, len(params.Attendees), 0, getIntOrDefault(arguments, "max_attendees", defaultMaxAttendees)); err != nil {
+		return nil, err
+	}
+	createNotes := getBoolOrDefault(arguments, "create_meeting_notes", false)
+	if !createNotes && getStringOrDefault(arguments, "notes_template", "") != "" {
+		return nil, fmt.Errorf("notes_template needs create_meeting_notes: true")
+	}
+
+	// Handle conference data creation
+	if createMeet, ok := arguments["create_meet_link"].(bool); ok && createMeet {
+		params.ConferenceData = newMeetConference()
+	}
+	if value, ok := arguments["meet_settings"]; ok && value != nil {
+		settings, err := parseMeetSettings(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameters: %v", err)
+		}
+		if params.ConferenceData == nil {
+			return nil, fmt.Errorf("meet_settings needs a Meet link: set create_meet_link to true")
+		}
+		params.Description = withMeetSettings(params.Description, *settings)
+		params.ConferenceData.Notes = settings.conferenceNotes()
+		params.MeetSettings = settings
+	}
+
+	// Check the meeting against the scheduling policy before creating it
+	var warnings []PolicyViolation
+	if !params.AllDay && (params.EventType == "" || params.EventType == "default") {
+		violations, err := ct.checkSchedulingPolicy(params.CalendarID, meetingSpan{Summary: params.Summary, Start: params.StartTime, End: params.EndTime}, params.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		if blocking := blockingViolations(violations); len(blocking) > 0 {
+			return nil, fmt.Errorf("cannot create '%s': it breaks the scheduling policy:\n%s", params.Summary, describeViolations(blocking))
+		}
+		warnings = violations
+	}
+
+	if params.FollowupOf != "" {
+		if err := ct.client.checkFollowupLink(params.CalendarID, "", params.FollowupOf); err != nil {
+			return nil, err
+		}
+	}
+
+	var attendeeEmails []string
+	for _, a := range params.Attendees {
+		attendeeEmails = append(attendeeEmails, a.Email)
+	}
+	description, offload, err := ct.offloadDescription(arguments, params.Summary, params.Description, attendeeEmails)
+	if err != nil {
+		return nil, err
+	}
+	params.Description = description
+	if offload != nil {
+		params.Attachments = append(params.Attachments, offload.attachment())
+	}
+	var meetingNotes *MeetingNotes
+	if createNotes {
+		if meetingNotes, err = ct.createMeetingNotes(arguments, params); err != nil {
+			return nil, err
+		}
+		params.Attachments = append(params.Attachments, meetingNotes.attachment())
+	}
+
+	event, err := ct.client.CreateEvent(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event: %v", err)
+	}
+
+	tf := ct.client.TimeFormat()
+	var notes []string
+	if days := describeAllDay(event, tf); days != "" {
+		notes = append(notes, fmt.Sprintf("📅 All-day event: %s (the API stores end date %s, the day after the last day)", days, event.End.Date))
+	}
+	if len(warnings) > 0 {
+		notes = append(notes, "⚠️ Scheduling policy warnings:\n"+describeViolations(warnings))
+	}
+	notes = append(notes, ct.client.attendeeLocalTimesForEvent(event))
+	if params.ConferenceData != nil {
+		notes = append(notes, conferenceNote(event))
+	}
+	if offload != nil {
+		notes = append(notes, offload.note())
+	}
+	if meetingNotes != nil {
+		notes = append(notes, meetingNotes.note())
+	}
+
+	action := confirmCreated
+	if params.Proposed {
+		action = confirmProposed
+		notes = append(notes, "⏳ Pending approval: the event is tentative and attendees were not notified. Approve it with approve_event.")
+	}
+	confirmation := newConfirmation(action, params.CalendarID, event, tf)
+	if params.ConferenceData != nil {
+		confirmation.Conference = conferenceInfo(event)
+	}
+	return structuredResult(formatConfirmation(confirmation, confirmation, notes...), event), nil
+}
+
+func (ct *CalendarTools) handleEditEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	eventID, ok := arguments["event_id"].(string)
+	if !ok || eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+
+	calendarID := ct.calendarID(arguments)
+
+	// First, fetch the event to get its title for better error messages
+	existingEvent, err := ct.client.getEventForChange(calendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkETag(getStringOrDefault(arguments, "etag", ""), existingEvent); err != nil {
+		return nil, err
+	}
+
+	eventTitle := existingEvent.Summary
+	if eventTitle == "" {
+		eventTitle = "(No Title)"
+	}
+
+	target, err := resolveEventTarget(eventID, existingEvent, getStringOrDefault(arguments, "scope", ""))
+	if err != nil {
+		return nil, err
+	}
+	scopeNote := describeTarget(target, existingEvent, ct.client.TimeFormat())
+	if target.EventID != eventID {
+		// Editing the whole series from one of its occurrences: diff against the series
+		existingEvent, err = ct.client.GetEvent(calendarID, target.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recurring series details: %v", err)
+		}
+	}
+
+	params, err := ct.parsePatchEventParams(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters for event '%s': %v", eventTitle, err)
+	}
+	// New dates for an all-day event keep it all-day unless all_day says otherwise
+	if params.AllDay == nil && existingEvent.Start != nil && existingEvent.Start.Date != "" && (params.StartTime != nil || params.EndTime != nil) {
+		allDay := true
+		params.AllDay = &allDay
+	}
+	keepAttendeeComments(params.Attendees, existingEvent)
+	if params.HasAttendees {
+		if err := checkAttendeeCap(eventTitle, len(params.Attendees), len(existingEvent.Attendees), getIntOrDefault(arguments, "max_attendees", defaultMaxAttendees)); err != nil {
+			return nil, err
+		}
+	}
+	var settingsNote string
+	if value, ok := arguments["meet_settings"]; ok && value != nil {
+		settings, err := parseMeetSettings(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameters for event '%s': %v", eventTitle, err)
+		}
+		if params.RemoveConference || (params.ConferenceData == nil && meetLink(existingEvent) == "") {
+			return nil, fmt.Errorf("meet_settings needs a Meet link: '%s' has none, so set create_meet_link to true", eventTitle)
+		}
+		description := existingEvent.Description
+		if params.Description != nil {
+			description = *params.Description
+		}
+		description = withMeetSettings(description, *settings)
+		params.Description = &description
+		params.MeetSettings = settings
+		if params.ConferenceData != nil {
+			params.ConferenceData.Notes = settings.conferenceNotes()
+		} else {
+			settingsNote = "ℹ️ The Meet settings were written into the description; the conference notes next to the Meet link only change when a new link is created (create_meet_link)"
+		}
+	}
+
+	// Refuse edits the user has no right to make before calling the API
+	access := ct.client.EventAccess(calendarID, existingEvent)
+	if err := access.checkEdit(eventTitle, params, existingEvent); err != nil {
+		return nil, err
+	}
+	// Patch the version the access checks above were made against
+	params.ETag = existingEvent.Etag
+	if target.Kind == targetInstance && params.HasRecurrence {
+		return nil, fmt.Errorf("recurrence can only be changed on the whole series; retry with scope 'series'")
+	}
+	if params.FollowupOf != nil && *params.FollowupOf != "" {
+		if err := ct.client.checkFollowupLink(calendarID, target.EventID, *params.FollowupOf); err != nil {
+			return nil, err
+		}
+	}
+
+	var offload *DescriptionOffload
+	if params.Description != nil {
+		// Share the doc with the attendees the event will have
+		var attendeeEmails []string
+		if params.HasAttendees {
+			for _, a := range params.Attendees {
+				attendeeEmails = append(attendeeEmails, a.Email)
+			}
+		} else {
+			for _, a := range existingEvent.Attendees {
+				attendeeEmails = append(attendeeEmails, a.Email)
+			}
+		}
+		summary := existingEvent.Summary
+		if params.Summary != nil {
+			summary = *params.Summary
+		}
+		var description string
+		description, offload, err = ct.offloadDescription(arguments, summary, *params.Description, attendeeEmails)
+		if err != nil {
+			return nil, err
+		}
+		params.Description = &description
+		if offload != nil {
+			params.Attachments = append(attachmentParams(existingEvent), offload.attachment())
+		}
+	}
 
-func (ct *CalendarTools) handleCreateEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	params, err := ct.parseEventParams(arguments)
+	event, err := ct.client.PatchEventDirect(target.EventID, params)
 	if err != nil {
-		return nil, fmt.Errorf("invalid parameters: %v", err)
+		if isForbidden(err) {
+			return nil, access.explainForbidden(err, "change", eventTitle)
+		}
+		return nil, fmt.Errorf("failed to patch event '%s': %v", eventTitle, err)
 	}
 
-	// Handle conference data creation
-	if createMeet, ok := arguments["create_meet_link"].(bool); ok && createMeet {
-		params.ConferenceData = &ConferenceDataParams{
-			CreateRequest: &CreateConferenceRequest{
-				RequestID: fmt.Sprintf("meet-%d", time.Now().Unix()),
-				ConferenceSolution: &ConferenceSolution{
-					Type: "hangoutsMeet",
-				},
-			},
+	diff := EventDiff{
+		CalendarID: calendarID,
+		EventID:    event.Id,
+		ETag:       event.Etag,
+		HTMLLink:   event.HtmlLink,
+		Summary:    event.Summary,
+		When:       describeWhen(event, ct.client.TimeFormat()),
+		Attendees:  countAttendees(event),
+		Scope:      scopeNote,
+		Changes:    diffEvents(existingEvent, event),
+	}
+	if params.ConferenceData != nil || params.MeetSettings != nil {
+		diff.Conference = conferenceInfo(event)
+	}
+	result := formatEventDiff(diff)
+	if params.ConferenceData != nil {
+		if note := conferenceNote(event); note != "" {
+			result += "\n\n" + note
 		}
 	}
-
-	event, err := ct.client.CreateEvent(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create event: %v", err)
+	if settingsNote != "" {
+		result += "\n\n" + settingsNote
+	}
+	if offload != nil {
+		result += "\n\n" + offload.note()
 	}
 
-	result := ct.formatEventResult(event)
-
-	return &mcp.CallToolResult{
-		Content: []mcp.ToolResult{{
-			Type: "text",
-			Text: result,
-		}},
-	}, nil
+	return structuredResult(result, diff), nil
 }
 
-func (ct *CalendarTools) handleEditEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func (ct *CalendarTools) handleDeleteEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	eventID, ok := arguments["event_id"].(string)
 	if !ok || eventID == "" {
 		return nil, fmt.Errorf("event_id is required")
 	}
 
-	calendarID := getStringOrDefault(arguments, "calendar_id", "primary")
+	calendarID := ct.calendarID(arguments)
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return nil, err
+	}
 
-	// First, fetch the event to get its title for better error messages
-	existingEvent, err := ct.client.GetEvent(calendarID, eventID)
+	// First, fetch the event to get its title for better messages
+	existingEvent, err := ct.client.getEventForChange(calendarID, eventID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get event details: %v", err)
+		return nil, err
+	}
+	if err := checkETag(getStringOrDefault(arguments, "etag", ""), existingEvent); err != nil {
+		return nil, err
 	}
 
 	eventTitle := existingEvent.Summary
@@ -647,60 +2778,64 @@ func (ct *CalendarTools) handleEditEvent(arguments map[string]interface{}) (*mcp
 		eventTitle = "(No Title)"
 	}
 
-	params, err := ct.parsePatchEventParams(arguments)
+	target, err := resolveEventTarget(eventID, existingEvent, getStringOrDefault(arguments, "scope", ""))
 	if err != nil {
-		return nil, fmt.Errorf("invalid parameters for event '%s': %v", eventTitle, err)
+		return nil, err
 	}
 
-	event, err := ct.client.PatchEventDirect(eventID, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to patch event '%s': %v", eventTitle, err)
+	access := ct.client.EventAccess(calendarID, existingEvent)
+	if err := access.checkDelete(eventTitle); err != nil {
+		return nil, err
 	}
-
-	result := ct.formatEventResult(event)
-
-	return &mcp.CallToolResult{
-		Content: []mcp.ToolResult{{
-			Type: "text",
-			Text: result,
-		}},
-	}, nil
-}
-
-func (ct *CalendarTools) handleDeleteEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	eventID, ok := arguments["event_id"].(string)
-	if !ok || eventID == "" {
-		return nil, fmt.Errorf("event_id is required")
+	if err := access.checkDeleteMode(getStringOrDefault(arguments, "mode", ""), eventTitle, existingEvent); err != nil {
+		return nil, err
 	}
+	mode := access.deleteMode(existingEvent)
 
-	calendarID := getStringOrDefault(arguments, "calendar_id", "primary")
-	sendNotifications := getBoolOrDefault(arguments, "send_notifications", true)
-
-	// First, fetch the event to get its title for better messages
-	existingEvent, err := ct.client.GetEvent(calendarID, eventID)
+	// The etag read above only guards the event it belongs to, not a series
+	// deleted through one of its occurrences
+	etag := ""
+	if target.EventID == existingEvent.Id {
+		etag = existingEvent.Etag
+	}
+	err = ct.client.DeleteEventIfMatch(calendarID, target.EventID, etag, sendUpdates)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get event details: %v", err)
+		if isForbidden(err) {
+			return nil, access.explainForbidden(err, "delete", eventTitle)
+		}
+		return nil, fmt.Errorf("failed to delete event '%s': %v", eventTitle, err)
 	}
 
-	eventTitle := existingEvent.Summary
-	if eventTitle == "" {
-		eventTitle = "(No Title)"
+	tf := ct.client.TimeFormat()
+	confirmation := newConfirmation(confirmDeleted, calendarID, existingEvent, tf)
+	confirmation.ETag = "" // the event is gone, so its etag guards nothing
+	payload := struct {
+		Confirmation
+		Scope       string `json:"scope,omitempty"`
+		Mode        string `json:"mode"`
+		SendUpdates string `json:"send_updates,omitempty"`
+	}{
+		Confirmation: confirmation,
+		Scope:        describeTarget(target, existingEvent, tf),
+		Mode:         mode,
+		SendUpdates:  sendUpdates,
 	}
 
-	err = ct.client.DeleteEvent(calendarID, eventID, sendNotifications)
-	if err != nil {
-		return nil, fmt.Errorf("failed to delete event '%s': %v", eventTitle, err)
+	var details []string
+	if payload.Scope != "" {
+		details = append(details, "Removed "+payload.Scope)
 	}
-
-	result := fmt.Sprintf("✅ Event '%s' deleted successfully", eventTitle)
-	if sendNotifications {
-		result += " (cancellation notifications sent to attendees)"
+	if note := access.deleteNote(existingEvent); note != "" {
+		details = append(details, strings.ToUpper(note[:1])+note[1:])
+	} else {
+		details = append(details, fmt.Sprintf("Cancelled for %s; %s", describeGuests(existingEvent), describeSendUpdates(sendUpdates)))
 	}
+	details = append(details, "Mode: "+mode)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.ToolResult{{
 			Type: "text",
-			Text: result,
+			Text: formatConfirmation(confirmation, payload, strings.Join(details, "\n")),
 		}},
 	}, nil
 }
@@ -712,7 +2847,7 @@ func (ct *CalendarTools) handleSetWorkingLocation(arguments map[string]interface
 	}
 
 	params := SetWorkingLocationParams{
-		CalendarID:   getStringOrDefault(arguments, "calendar_id", "primary"),
+		CalendarID:   ct.calendarID(arguments),
 		Action:       action,
 		EventID:      getStringOrDefault(arguments, "event_id", ""),
 		Date:         getStringOrDefault(arguments, "date", ""),
@@ -835,31 +2970,43 @@ func (ct *CalendarTools) handleGetAttendeeFreeBusy(arguments map[string]interfac
 		}
 	}
 
-	timeMinStr, ok := arguments["time_min"].(string)
-	if !ok || timeMinStr == "" {
-		return nil, fmt.Errorf("time_min is required")
+	// The range is a time_filter, as in list_events, or time_min and time_max
+	timezone := getStringOrDefault(arguments, "timezone", "UTC")
+	if err := validateTimeZone(timezone); err != nil {
+		return nil, err
 	}
-
-	timeMaxStr, ok := arguments["time_max"].(string)
-	if !ok || timeMaxStr == "" {
-		return nil, fmt.Errorf("time_max is required")
+	filter := getStringOrDefault(arguments, "time_filter", "")
+	if filter == "" {
+		_, hasMin := arguments["time_min"]
+		_, hasMax := arguments["time_max"]
+		if !hasMin && !hasMax {
+			return nil, fmt.Errorf("time_filter, or time_min and time_max, is required")
+		}
+		filter = "custom"
 	}
-
-	timeMin, err := time.Parse(time.RFC3339, timeMinStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid time_min format: %v", err)
+	rangeParams := ListEventsParams{TimeFilter: filter, TimeZone: timezone}
+	if err := ct.parseTimeFilter(arguments, &rangeParams); err != nil {
+		return nil, err
 	}
+	timeMin, timeMax := calculateTimeRange(rangeParams)
 
-	timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid time_max format: %v", err)
+	groupExpansionMax := getIntOrDefault(arguments, "group_expansion_max", maxGroupExpansion)
+	if groupExpansionMax < 1 || groupExpansionMax > maxGroupExpansion {
+		return nil, fmt.Errorf("group_expansion_max must be between 1 and %d", maxGroupExpansion)
+	}
+	if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+		return nil, err
+	}
+	if err := ct.fetchLimits.checkCalendars(attendees); err != nil {
+		return nil, err
 	}
 
 	params := FreeBusyParams{
-		TimeMin:     timeMin,
-		TimeMax:     timeMax,
-		TimeZone:    getStringOrDefault(arguments, "timezone", "UTC"),
-		CalendarIDs: attendees,
+		TimeMin:           timeMin,
+		TimeMax:           timeMax,
+		TimeZone:          timezone,
+		CalendarIDs:       attendees,
+		GroupExpansionMax: groupExpansionMax,
 	}
 
 	response, err := ct.client.GetFreeBusy(params)
@@ -868,13 +3015,14 @@ func (ct *CalendarTools) handleGetAttendeeFreeBusy(arguments map[string]interfac
 	}
 
 	result := ct.formatFreeBusyResult(response, attendees, timeMin, timeMax)
+	if groups := groupAvailability(response, groupExpansionMax); len(groups) > 0 {
+		result += "\n\n" + formatGroupAvailability(groups)
+	}
+	if notes := freeBusyErrors(response); len(notes) > 0 {
+		result += "\n\n⚠️ Free/busy not visible: " + strings.Join(notes, "; ")
+	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.ToolResult{{
-			Type: "text",
-			Text: result,
-		}},
-	}, nil
+	return structuredResult(result, response), nil
 }
 
 func (ct *CalendarTools) parseEventParams(arguments map[string]interface{}) (EventParams, error) {
@@ -886,20 +3034,46 @@ func (ct *CalendarTools) parseEventParams(arguments map[string]interface{}) (Eve
 		visibility = "public"
 	}
 
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return EventParams{}, err
+	}
+
 	params := EventParams{
-		CalendarID:             getStringOrDefault(arguments, "calendar_id", "primary"),
+		CalendarID:             ct.calendarID(arguments),
 		Summary:                getStringOrDefault(arguments, "summary", ""),
 		Description:            getStringOrDefault(arguments, "description", ""),
 		Location:               getStringOrDefault(arguments, "location", ""),
 		TimeZone:               getStringOrDefault(arguments, "timezone", "UTC"),
 		AllDay:                 getBoolOrDefault(arguments, "all_day", false),
 		Visibility:             visibility,
-		SendNotifications:      getBoolOrDefault(arguments, "send_notifications", true),
+		SendUpdates:            sendUpdates,
 		GuestCanModify:         getBoolOrDefault(arguments, "guest_can_modify", false),
 		GuestCanInviteOthers:   getBoolOrDefault(arguments, "guest_can_invite_others", true),
 		GuestCanSeeOtherGuests: getBoolOrDefault(arguments, "guest_can_see_other_guests", true),
+		AnyoneCanAddSelf:       getBoolOrDefault(arguments, "anyone_can_add_self", false),
 		ColorID:                getStringOrDefault(arguments, "colorId", ""),
 		EventType:              eventType,
+		FollowupOf:             getStringOrDefault(arguments, "followup_of", ""),
+		Priority:               getStringOrDefault(arguments, "priority", ""),
+		Proposed:               getBoolOrDefault(arguments, "propose", false),
+	}
+	if err := validatePriority(params.Priority); err != nil {
+		return EventParams{}, err
+	}
+	if err := validateTimeZone(params.TimeZone); err != nil {
+		return EventParams{}, err
+	}
+	if value, ok := arguments["source"]; ok && value != nil {
+		source, err := parseEventSource(value, false)
+		if err != nil {
+			return EventParams{}, err
+		}
+		params.Source = source
+	}
+	// Attendees hear about a proposed event once it is approved
+	if params.Proposed {
+		params.SendUpdates = "none"
 	}
 
 	// Parse workingLocation if provided
@@ -935,7 +3109,7 @@ func (ct *CalendarTools) parseEventParams(arguments map[string]interface{}) (Eve
 
 	// Parse start and end times
 	if startTimeStr, ok := arguments["start_time"].(string); ok && startTimeStr != "" {
-		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		startTime, err := parseEventTime(startTimeStr, false)
 		if err != nil {
 			return params, fmt.Errorf("invalid start_time format: %v", err)
 		}
@@ -943,7 +3117,7 @@ func (ct *CalendarTools) parseEventParams(arguments map[string]interface{}) (Eve
 	}
 
 	if endTimeStr, ok := arguments["end_time"].(string); ok && endTimeStr != "" {
-		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		endTime, err := parseEventTime(endTimeStr, true)
 		if err != nil {
 			return params, fmt.Errorf("invalid end_time format: %v", err)
 		}
@@ -953,11 +3127,9 @@ func (ct *CalendarTools) parseEventParams(arguments map[string]interface{}) (Eve
 	// Parse attendees
 	if attendeesInterface, ok := arguments["attendees"]; ok {
 		if attendeesSlice, ok := attendeesInterface.([]interface{}); ok {
-			attendees := make([]string, len(attendeesSlice))
-			for i, v := range attendeesSlice {
-				if email, ok := v.(string); ok {
-					attendees[i] = email
-				}
+			attendees, err := parseAttendees(attendeesSlice, "")
+			if err != nil {
+				return params, err
 			}
 			params.Attendees = attendees
 		}
@@ -1006,9 +3178,13 @@ func (ct *CalendarTools) parseEventParams(arguments map[string]interface{}) (Eve
 }
 
 func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{}) (PatchEventParams, error) {
+	sendUpdates, err := ct.sendUpdates(arguments, true)
+	if err != nil {
+		return PatchEventParams{}, err
+	}
 	params := PatchEventParams{
-		CalendarID:        getStringOrDefault(arguments, "calendar_id", "primary"),
-		SendNotifications: getBoolOrDefault(arguments, "send_notifications", true),
+		CalendarID:  ct.calendarID(arguments),
+		SendUpdates: sendUpdates,
 	}
 
 	// Only set pointer fields if they are explicitly provided in the arguments
@@ -1022,6 +3198,9 @@ func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{})
 		params.Location = &location
 	}
 	if timezone, ok := arguments["timezone"].(string); ok {
+		if err := validateTimeZone(timezone); err != nil {
+			return PatchEventParams{}, err
+		}
 		params.TimeZone = &timezone
 	}
 	if visibility, ok := arguments["visibility"].(string); ok {
@@ -1033,6 +3212,42 @@ func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{})
 	if colorID, ok := arguments["colorId"].(string); ok {
 		params.ColorID = &colorID
 	}
+	if followupOf, ok := arguments["followup_of"].(string); ok {
+		params.FollowupOf = &followupOf
+	}
+	if priority, ok := arguments["priority"].(string); ok {
+		if err := validatePriority(priority); err != nil {
+			return PatchEventParams{}, err
+		}
+		params.Priority = &priority
+	}
+	// source: null removes the source, like an empty url
+	if value, exists := arguments["source"]; exists {
+		params.Source = &EventSourceParams{}
+		if value != nil {
+			source, err := parseEventSource(value, true)
+			if err != nil {
+				return PatchEventParams{}, err
+			}
+			params.Source = source
+		}
+	}
+	// conference_data: null is accepted as a synonym for remove_conference
+	if conference, exists := arguments["conference_data"]; exists {
+		if conference != nil {
+			return params, fmt.Errorf("conference_data only accepts null, to remove the conference; use create_meet_link to add a Meet link")
+		}
+		params.RemoveConference = true
+	}
+	if getBoolOrDefault(arguments, "remove_conference", false) {
+		params.RemoveConference = true
+	}
+	if getBoolOrDefault(arguments, "create_meet_link", false) {
+		if params.RemoveConference {
+			return params, fmt.Errorf("create_meet_link and remove_conference cannot be combined")
+		}
+		params.ConferenceData = newMeetConference()
+	}
 	if eventType, ok := arguments["eventType"].(string); ok {
 		params.EventType = &eventType
 
@@ -1064,10 +3279,13 @@ func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{})
 	if guestCanSeeOtherGuests, ok := arguments["guest_can_see_other_guests"].(bool); ok {
 		params.GuestCanSeeOtherGuests = &guestCanSeeOtherGuests
 	}
+	if anyoneCanAddSelf, ok := arguments["anyone_can_add_self"].(bool); ok {
+		params.AnyoneCanAddSelf = &anyoneCanAddSelf
+	}
 
 	// Parse start and end times
 	if startTimeStr, ok := arguments["start_time"].(string); ok && startTimeStr != "" {
-		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		startTime, err := parseEventTime(startTimeStr, false)
 		if err != nil {
 			return params, fmt.Errorf("invalid start_time format: %v", err)
 		}
@@ -1075,7 +3293,7 @@ func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{})
 	}
 
 	if endTimeStr, ok := arguments["end_time"].(string); ok && endTimeStr != "" {
-		endTime, err := time.Parse(time.RFC3339, endTimeStr)
+		endTime, err := parseEventTime(endTimeStr, true)
 		if err != nil {
 			return params, fmt.Errorf("invalid end_time format: %v", err)
 		}
@@ -1086,25 +3304,24 @@ func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{})
 	if attendeesInterface, exists := arguments["attendees"]; exists {
 		params.HasAttendees = true
 		if attendeesSlice, ok := attendeesInterface.([]interface{}); ok {
-			attendees := make([]AttendeeParams, len(attendeesSlice))
-			for i, v := range attendeesSlice {
-				if email, ok := v.(string); ok {
-					// Backward compatibility: simple email string
-					attendees[i] = AttendeeParams{
-						Email:          email,
-						ResponseStatus: "needsAction",
-					}
-				} else if attendeeMap, ok := v.(map[string]interface{}); ok {
-					// New format: attendee object with email and response_status
-					attendees[i] = AttendeeParams{
-						Email:          getStringOrDefault(attendeeMap, "email", ""),
-						ResponseStatus: getStringOrDefault(attendeeMap, "response_status", "needsAction"),
-					}
-				}
+			attendees, err := parseAttendees(attendeesSlice, "needsAction")
+			if err != nil {
+				return params, err
 			}
 			params.Attendees = attendees
 		}
 	}
+	if updatesInterface, exists := arguments["update_attendees"]; exists {
+		if params.HasAttendees {
+			return params, fmt.Errorf("update_attendees cannot be combined with attendees")
+		}
+		updatesSlice, _ := updatesInterface.([]interface{})
+		updates, err := parseAttendeeUpdates(updatesSlice)
+		if err != nil {
+			return params, err
+		}
+		params.AttendeeUpdates = updates
+	}
 
 	// Parse recurrence - set HasRecurrence flag if recurrence key exists (even if empty)
 	if recurrenceInterface, exists := arguments["recurrence"]; exists {
@@ -1149,11 +3366,6 @@ func (ct *CalendarTools) parsePatchEventParams(arguments map[string]interface{})
 	return params, nil
 }
 
-func (ct *CalendarTools) formatEventResult(event interface{}) string {
-	eventJSON, _ := json.MarshalIndent(event, "", "  ")
-	return fmt.Sprintf("✅ Event operation completed successfully:\n\n%s", string(eventJSON))
-}
-
 func (ct *CalendarTools) formatFreeBusyResult(response interface{}, attendees []string, timeMin, timeMax time.Time) string {
 	var result strings.Builder
 	fmt.Fprintf(&result, "📅 Free/Busy information from %s to %s:\n\n",
@@ -1203,6 +3415,61 @@ func getIntOrDefault(args map[string]interface{}, key string, defaultValue int)
 	return defaultValue
 }
 
+// parseAttendees parses attendees given as email strings or as objects with
+// email, display_name, optional, response_status and comment. defaultStatus is
+// used when no response_status is given.
+func parseAttendees(values []interface{}, defaultStatus string) ([]AttendeeParams, error) {
+	attendees := make([]AttendeeParams, 0, len(values))
+	for i, v := range values {
+		var attendee AttendeeParams
+		switch a := v.(type) {
+		case string:
+			// Backward compatibility: simple email string
+			attendee = AttendeeParams{Email: a, ResponseStatus: defaultStatus}
+		case map[string]interface{}:
+			attendee = AttendeeParams{
+				Email:          getStringOrDefault(a, "email", ""),
+				DisplayName:    getStringOrDefault(a, "display_name", ""),
+				Optional:       getBoolOrDefault(a, "optional", false),
+				ResponseStatus: getStringOrDefault(a, "response_status", defaultStatus),
+			}
+			if comment, ok := a["comment"].(string); ok {
+				attendee.Comment = &comment
+			}
+		default:
+			return nil, fmt.Errorf("attendee %d must be an email string or an object with an email", i+1)
+		}
+		if attendee.Email == "" {
+			return nil, fmt.Errorf("attendee %d: email is required", i+1)
+		}
+		email, err := normalizeEmail(attendee.Email)
+		if err != nil {
+			return nil, fmt.Errorf("attendee %d %q: %v", i+1, attendee.Email, err)
+		}
+		attendee.Email = email
+		attendees = append(attendees, attendee)
+	}
+	return attendees, nil
+}
+
+// keepAttendeeComments fills in the existing comment of every attendee in
+// attendees that was given without one, so replacing the attendee list (for
+// example to RSVP) does not erase other guests' comments.
+func keepAttendeeComments(attendees []AttendeeParams, event *calendar.Event) {
+	for i := range attendees {
+		if attendees[i].Comment != nil {
+			continue
+		}
+		for _, a := range event.Attendees {
+			if a.Comment != "" && strings.EqualFold(a.Email, attendees[i].Email) {
+				comment := a.Comment
+				attendees[i].Comment = &comment
+				break
+			}
+		}
+	}
+}
+
 func (ct *CalendarTools) handleListEventOccurrences(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	eventID, ok := arguments["event_id"].(string)
 	if !ok || eventID == "" {
@@ -1210,7 +3477,7 @@ func (ct *CalendarTools) handleListEventOccurrences(arguments map[string]interfa
 	}
 
 	params := GetRecurringOccurrencesParams{
-		CalendarID:  getStringOrDefault(arguments, "calendar_id", "primary"),
+		CalendarID:  ct.calendarID(arguments),
 		EventID:     eventID,
 		PastCount:   getIntOrDefault(arguments, "past_count", 5),
 		FutureCount: getIntOrDefault(arguments, "future_count", 3),
@@ -1257,46 +3524,115 @@ func (ct *CalendarTools) formatRecurringOccurrences(past, upcoming []*calendar.E
 	return string(b)
 }
 
-func (ct *CalendarTools) handleListEvents(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	params := ListEventsParams{
-		CalendarID:     getStringOrDefault(arguments, "calendar_id", "primary"),
-		TimeFilter:     getStringOrDefault(arguments, "time_filter", "today"),
-		TimeZone:       getStringOrDefault(arguments, "timezone", "UTC"),
-		MaxResults:     int64(getIntOrDefault(arguments, "max_results", 250)),
-		ShowDeleted:    getBoolOrDefault(arguments, "show_deleted", false),
-		SingleEvents:   true,
-		OrderBy:        getStringOrDefault(arguments, "order_by", "startTime"),
-		ShowDeclined:   getBoolOrDefault(arguments, "show_declined", false),
-		DetectOverlaps: getBoolOrDefault(arguments, "detect_overlaps", true),
-		Query:          getStringOrDefault(arguments, "query", ""),
-	}
+// timeFilters are the values of the time_filter argument; see calculateTimeRange.
+var timeFilters = []string{"today", "tomorrow", "this_week", "next_week", "this_weekend", "this_month", "next_month", "next_n_days", "past_n_days", "custom"}
 
-	outputFormat := getStringOrDefault(arguments, "output_format", "text")
+// parseTimeFilter reads the arguments that go with params.TimeFilter into
+// params: week_mode for the weeks, days for the n-day filters and
+// time_min/time_max for 'custom'. Ranges longer than the fetch limits allow
+// are refused.
+func (ct *CalendarTools) parseTimeFilter(arguments map[string]interface{}, params *ListEventsParams) error {
+	if !containsString(timeFilters, params.TimeFilter) {
+		return fmt.Errorf("invalid time_filter %q: use one of %s", params.TimeFilter, strings.Join(timeFilters, ", "))
+	}
+	if params.TimeFilter == "this_week" || params.TimeFilter == "next_week" {
+		params.WeekMode = getStringOrDefault(arguments, "week_mode", "workweek")
+		if params.WeekMode != "workweek" && params.WeekMode != "full" {
+			return fmt.Errorf("invalid week_mode %q: use 'workweek' or 'full'", params.WeekMode)
+		}
+		params.WeekStart = ct.client.WeekStart()
+	}
+	if params.TimeFilter == "next_n_days" || params.TimeFilter == "past_n_days" {
+		params.Days = getIntOrDefault(arguments, "days", 7)
+		if params.Days < 1 {
+			return fmt.Errorf("days must be at least 1")
+		}
+		timeMin, timeMax := calculateTimeRange(*params)
+		if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+			return err
+		}
+	}
 
 	// Parse custom time range if provided
 	if params.TimeFilter == "custom" {
 		timeMinStr, ok := arguments["time_min"].(string)
 		if !ok || timeMinStr == "" {
-			return nil, fmt.Errorf("time_min is required when time_filter is 'custom'")
+			return fmt.Errorf("time_min is required when time_filter is 'custom'")
 		}
 
 		timeMaxStr, ok := arguments["time_max"].(string)
 		if !ok || timeMaxStr == "" {
-			return nil, fmt.Errorf("time_max is required when time_filter is 'custom'")
+			return fmt.Errorf("time_max is required when time_filter is 'custom'")
 		}
 
 		timeMin, err := time.Parse(time.RFC3339, timeMinStr)
 		if err != nil {
-			return nil, fmt.Errorf("invalid time_min format: %v", err)
+			return fmt.Errorf("invalid time_min format: %v", err)
 		}
 
 		timeMax, err := time.Parse(time.RFC3339, timeMaxStr)
 		if err != nil {
-			return nil, fmt.Errorf("invalid time_max format: %v", err)
+			return fmt.Errorf("invalid time_max format: %v", err)
 		}
 
 		params.TimeMin = timeMin
 		params.TimeMax = timeMax
+		if err := ct.fetchLimits.checkRange(timeMin, timeMax); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ct *CalendarTools) handleListEvents(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	params := ListEventsParams{
+		CalendarID:     ct.calendarID(arguments),
+		TimeFilter:     getStringOrDefault(arguments, "time_filter", "today"),
+		TimeZone:       getStringOrDefault(arguments, "timezone", "UTC"),
+		MaxResults:     int64(getIntOrDefault(arguments, "max_results", 250)),
+		ShowDeleted:    getBoolOrDefault(arguments, "show_deleted", false),
+		SingleEvents:   true,
+		OrderBy:        getStringOrDefault(arguments, "order_by", "startTime"),
+		ShowDeclined:   getBoolOrDefault(arguments, "show_declined", false),
+		DetectOverlaps: getBoolOrDefault(arguments, "detect_overlaps", true),
+		Query:          getStringOrDefault(arguments, "query", ""),
+		AnnotateColors: getBoolOrDefault(arguments, "annotate_colors", false),
+		Organizer:      getStringOrDefault(arguments, "organizer", ""),
+		MaxAttendees:   int64(getIntOrDefault(arguments, "max_attendees", 0)),
+		FullAttendees:  getBoolOrDefault(arguments, "full_attendees", false),
+		AttendeeLimit:  getIntOrDefault(arguments, "attendee_limit", defaultAttendeeLimit),
+		SplitMultiDay:  getBoolOrDefault(arguments, "split_multi_day", false),
+	}
+	if params.AttendeeLimit < 0 {
+		return nil, fmt.Errorf("attendee_limit must be 0 (show everyone) or more")
+	}
+	if err := validateTimeZone(params.TimeZone); err != nil {
+		return nil, err
+	}
+	if err := ct.parseTimeFilter(arguments, &params); err != nil {
+		return nil, err
+	}
+	// Today's agenda starts from now unless the whole day is asked for
+	params.ExcludePast = params.TimeFilter == "today" && !getBoolOrDefault(arguments, "include_past", false)
+
+	outputFormat := getStringOrDefault(arguments, "output_format", "text")
+
+	if err := ct.fetchLimits.checkResults(int(params.MaxResults), params.TimeMin, params.TimeMax); err != nil {
+		return nil, err
+	}
+
+	calendarIDs, err := stringArguments(arguments, "calendar_ids")
+	if err != nil {
+		return nil, err
+	}
+	if len(calendarIDs) > 1 {
+		if err := ct.fetchLimits.checkCalendars(calendarIDs); err != nil {
+			return nil, err
+		}
+		return ct.listEventsOnCalendars(calendarIDs, params, outputFormat)
+	}
+	if len(calendarIDs) == 1 {
+		params.CalendarID = calendarIDs[0]
 	}
 
 	events, err := ct.client.ListEvents(params)
@@ -1306,9 +3642,17 @@ func (ct *CalendarTools) handleListEvents(arguments map[string]interface{}) (*mc
 
 	var result string
 
+	// The JSON form, with overlap detection, is always the structured content
+	jsonResult := ct.formatEventsJSON(events, params)
+	// Each conflict comes with the calls that would resolve it
+	var conflicts []OverlapConflict
+	if params.DetectOverlaps {
+		conflicts = ct.overlapConflicts(events.Items, params)
+		if len(conflicts) > 0 {
+			jsonResult["conflicts"] = conflicts
+		}
+	}
 	if outputFormat == "json" {
-		// Return JSON format with overlap detection
-		jsonResult := ct.formatEventsJSON(events, params)
 		jsonBytes, err := json.Marshal(jsonResult)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal events to JSON: %v", err)
@@ -1317,14 +3661,12 @@ func (ct *CalendarTools) handleListEvents(arguments map[string]interface{}) (*mc
 	} else {
 		// Return formatted text
 		result = ct.formatEventsResult(events, params)
+		if len(conflicts) > 0 {
+			result += "\n\n" + formatOverlapConflicts(conflicts, locationOrUTC(params.TimeZone), ct.client.TimeFormat())
+		}
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.ToolResult{{
-			Type: "text",
-			Text: result,
-		}},
-	}, nil
+	return structuredResult(result, jsonResult), nil
 }
 
 func (ct *CalendarTools) formatEventsJSON(events *calendar.Events, params ListEventsParams) map[string]interface{} {
@@ -1370,18 +3712,40 @@ func (ct *CalendarTools) formatEventsJSON(events *calendar.Events, params ListEv
 	// Build JSON result
 	result := make(map[string]interface{})
 	result["time_filter"] = params.TimeFilter
+	if params.TimeFilter == "today" {
+		result["include_past"] = !params.ExcludePast
+	}
+	if params.Days > 0 {
+		result["days"] = params.Days
+	}
+	if params.WeekMode != "" {
+		result["week_mode"] = params.WeekMode
+	}
 	result["total_count"] = len(events.Items)
+	if n := omittedCount(events.Items); n > 0 {
+		result["attendees_omitted_count"] = n
+	}
 
 	// Convert events to JSON-friendly format
 	eventsJSON := make([]map[string]interface{}, 0, len(events.Items))
 	for _, event := range events.Items {
 		eventJSON := make(map[string]interface{})
 		eventJSON["id"] = event.Id
+		eventJSON["handle"] = ct.handles.handle(event.Id)
 		eventJSON["summary"] = event.Summary
 		eventJSON["description"] = event.Description
 		eventJSON["location"] = event.Location
 		eventJSON["status"] = event.Status
 		eventJSON["eventType"] = event.EventType
+		eventJSON["etag"] = event.Etag
+		if event.PrivateCopy {
+			eventJSON["privateCopy"] = true
+		}
+		// Private events on someone else's calendar only show as busy time
+		if isHiddenPrivate(event) {
+			eventJSON["summary"] = privateBusyTitle
+			eventJSON["detailsHidden"] = true
+		}
 
 		// Start/End times
 		eventJSON["start"] = map[string]interface{}{
@@ -1394,6 +3758,18 @@ func (ct *CalendarTools) formatEventsJSON(events *calendar.Events, params ListEv
 			"date":     event.End.Date,
 			"timeZone": event.End.TimeZone,
 		}
+		// The API's all-day end date is exclusive; lastDate is the event's last day
+		if last := allDayLastDate(event); last != "" {
+			eventJSON["end"].(map[string]interface{})["lastDate"] = last
+		}
+
+		// Organizer and creator
+		if event.Organizer != nil {
+			eventJSON["organizer"] = personJSON(event.Organizer.Email, event.Organizer.DisplayName, event.Organizer.Self)
+		}
+		if event.Creator != nil {
+			eventJSON["creator"] = personJSON(event.Creator.Email, event.Creator.DisplayName, event.Creator.Self)
+		}
 
 		// Attendees
 		if len(event.Attendees) > 0 {
@@ -1403,12 +3779,24 @@ func (ct *CalendarTools) formatEventsJSON(events *calendar.Events, params ListEv
 				attendeeJSON["email"] = attendee.Email
 				attendeeJSON["displayName"] = attendee.DisplayName
 				attendeeJSON["responseStatus"] = attendee.ResponseStatus
+				if attendee.Comment != "" {
+					attendeeJSON["comment"] = attendee.Comment
+				}
 				attendeeJSON["self"] = attendee.Self
 				attendeeJSON["organizer"] = attendee.Organizer
 				attendeesJSON = append(attendeesJSON, attendeeJSON)
 			}
 			eventJSON["attendees"] = attendeesJSON
 		}
+		if event.AttendeesOmitted {
+			eventJSON["attendeesOmitted"] = true
+		}
+		if priority := eventPriorityLevel(event); priority != "" {
+			eventJSON["priority"] = priority
+		}
+		if approval := eventApproval(event); approval != "" {
+			eventJSON["approval"] = approval
+		}
 
 		// Overlap information
 		if overlaps != nil {
@@ -1422,10 +3810,33 @@ func (ct *CalendarTools) formatEventsJSON(events *calendar.Events, params ListEv
 		if event.ColorId != "" {
 			eventJSON["colorId"] = event.ColorId
 		}
+		if params.AnnotateColors {
+			if category := ct.colorLegend.Category(event); category != "" {
+				eventJSON["category"] = category
+			}
+		}
 
-		// Hangout/Meet link
+		// Hangout/Meet link, or another conference's video link
 		if event.HangoutLink != "" {
 			eventJSON["hangoutLink"] = event.HangoutLink
+		} else if link := meetLink(event); link != "" {
+			eventJSON["conferenceLink"] = link
+		}
+		// Phone numbers, PINs and other ways to join
+		if entries := dialIns(event); len(entries) > 0 {
+			eventJSON["dialIn"] = entries
+		}
+
+		// Calendar web link
+		if event.HtmlLink != "" {
+			eventJSON["htmlLink"] = event.HtmlLink
+		}
+		// The ticket, PR or doc the event was created from
+		if event.Source != nil && event.Source.Url != "" {
+			eventJSON["source"] = map[string]interface{}{
+				"title": event.Source.Title,
+				"url":   event.Source.Url,
+			}
 		}
 
 		// Recurring event ID (identifies which series this instance belongs to)
@@ -1479,17 +3890,42 @@ func (ct *CalendarTools) formatEventsJSON(events *calendar.Events, params ListEv
 	return result
 }
 
+// weekDays names the days a week filter covers, e.g. "Monday-Friday" or
+// "Sunday-Saturday".
+func weekDays(params ListEventsParams) string {
+	if params.WeekMode != "full" {
+		return "Monday-Friday"
+	}
+	return fmt.Sprintf("%s-%s", params.WeekStart, (params.WeekStart+6)%7)
+}
+
 func (ct *CalendarTools) formatEventsResult(events *calendar.Events, params ListEventsParams) string {
 	var result strings.Builder
 
 	// Create a descriptive header based on the time filter
 	switch params.TimeFilter {
 	case "today":
-		result.WriteString("📅 Events for Today:\n\n")
+		if params.ExcludePast {
+			result.WriteString("📅 Events for the Rest of Today (include_past: true shows the whole day):\n\n")
+		} else {
+			result.WriteString("📅 Events for Today:\n\n")
+		}
+	case "tomorrow":
+		result.WriteString("📅 Events for Tomorrow:\n\n")
 	case "this_week":
-		result.WriteString("📅 Events for This Week (Monday-Friday):\n\n")
+		fmt.Fprintf(&result, "📅 Events for This Week (%s):\n\n", weekDays(params))
 	case "next_week":
-		result.WriteString("📅 Events for Next Week (Monday-Friday):\n\n")
+		fmt.Fprintf(&result, "📅 Events for Next Week (%s):\n\n", weekDays(params))
+	case "this_weekend":
+		result.WriteString("📅 Events for This Weekend (Saturday-Sunday):\n\n")
+	case "this_month":
+		result.WriteString("📅 Events for This Month:\n\n")
+	case "next_month":
+		result.WriteString("📅 Events for Next Month:\n\n")
+	case "next_n_days":
+		fmt.Fprintf(&result, "📅 Events for the Next %d Day(s), Today Included:\n\n", params.Days)
+	case "past_n_days":
+		fmt.Fprintf(&result, "📅 Events for the Past %d Day(s), Today Included:\n\n", params.Days)
 	case "custom":
 		fmt.Fprintf(&result, "📅 Events from %s to %s:\n\n",
 			params.TimeMin.Format("2006-01-02 15:04"),
@@ -1509,9 +3945,20 @@ func (ct *CalendarTools) formatEventsResult(events *calendar.Events, params List
 		overlaps = ct.client.DetectOverlaps(events.Items, params.ShowDeclined)
 	}
 
-	// Group events by date
-	eventsByDate := make(map[string][]*calendar.Event)
+	// Group events by date; with split_multi_day, by the days in the list's
+	// time zone, under each day they cover
+	eventsByDate := make(map[string][]dayEntry)
+	var loc *time.Location
+	var from, to time.Time
+	if params.SplitMultiDay {
+		loc = locationOrUTC(params.TimeZone)
+		from, to = listWindow(params, time.Now())
+	}
 	for _, event := range events.Items {
+		if params.SplitMultiDay && addEventDays(eventsByDate, event, loc, from, to) {
+			continue
+		}
+
 		var eventDate string
 		if event.Start.Date != "" {
 			// All-day event
@@ -1528,7 +3975,7 @@ func (ct *CalendarTools) formatEventsResult(events *calendar.Events, params List
 			eventDate = "Unknown"
 		}
 
-		eventsByDate[eventDate] = append(eventsByDate[eventDate], event)
+		eventsByDate[eventDate] = append(eventsByDate[eventDate], dayEntry{event: event})
 	}
 
 	// Sort dates
@@ -1546,6 +3993,7 @@ func (ct *CalendarTools) formatEventsResult(events *calendar.Events, params List
 	}
 
 	// Display events grouped by date
+	tf := ct.client.TimeFormat()
 	for i, date := range dates {
 		if i > 0 {
 			result.WriteString("\n")
@@ -1553,37 +4001,48 @@ func (ct *CalendarTools) formatEventsResult(events *calendar.Events, params List
 
 		// Format date header
 		if parsedDate, err := time.Parse("2006-01-02", date); err == nil {
-			fmt.Fprintf(&result, "## %s\n", parsedDate.Format("Monday, January 2, 2006"))
+			fmt.Fprintf(&result, "## %s\n", tf.LongDate(parsedDate))
 		} else {
 			fmt.Fprintf(&result, "## %s\n", date)
 		}
 
-		for _, event := range eventsByDate[date] {
+		for _, entry := range eventsByDate[date] {
+			event := entry.event
 			hasOverlap := false
 			if overlaps != nil {
 				hasOverlap = overlaps[event.Id]
 			}
-			ct.formatSingleEvent(&result, event, hasOverlap)
+			category := ""
+			if params.AnnotateColors {
+				category = ct.colorLegend.Category(event)
+			}
+			ct.formatSingleEvent(&result, event, hasOverlap, tf, category, params.AttendeeLimit, entry.span)
 		}
 	}
 
 	fmt.Fprintf(&result, "\n📊 Total: %d events", len(events.Items))
+	if n := omittedCount(events.Items); n > 0 {
+		fmt.Fprintf(&result, "\n⚠️ %d event(s) show a partial attendee list; list again with full_attendees: true for complete RSVP data", n)
+	}
+	if n := truncatedCount(events.Items, params.AttendeeLimit); n > 0 {
+		fmt.Fprintf(&result, "\n👥 %d event(s) list only their first %d attendees; list again with attendee_limit: 0 to show everyone", n, params.AttendeeLimit)
+	}
 
 	return result.String()
 }
 
-func (ct *CalendarTools) formatSingleEvent(result *strings.Builder, event *calendar.Event, hasOverlap bool) {
+func (ct *CalendarTools) formatSingleEvent(result *strings.Builder, event *calendar.Event, hasOverlap bool, tf TimeFormat, category string, attendeeLimit int, span string) {
 	// Event title
-	title := event.Summary
-	if title == "" {
-		title = "(No Title)"
-	}
-	fmt.Fprintf(result, "### %s\n", title)
+	fmt.Fprintf(result, "### %s\n", eventTitle(event))
 
 	// Time information
 	if event.Start.Date != "" {
-		// All-day event
-		result.WriteString("🕐 **All Day**\n")
+		// All-day event; show the days covered when there are several
+		if allDayLastDate(event) != event.Start.Date {
+			fmt.Fprintf(result, "🕐 **All Day** (%s)\n", describeAllDay(event, tf))
+		} else {
+			result.WriteString("🕐 **All Day**\n")
+		}
 	} else if event.Start.DateTime != "" {
 		// Regular event with time
 		startTime, err := time.Parse(time.RFC3339, event.Start.DateTime)
@@ -1593,30 +4052,53 @@ func (ct *CalendarTools) formatSingleEvent(result *strings.Builder, event *calen
 				// Same day event
 				if startTime.Format("2006-01-02") == endTime.Format("2006-01-02") {
 					fmt.Fprintf(result, "🕐 **%s - %s**\n",
-						startTime.Format("3:04 PM"),
-						endTime.Format("3:04 PM"))
+						tf.Clock(startTime),
+						tf.Clock(endTime))
 				} else {
 					// Multi-day event
 					fmt.Fprintf(result, "🕐 **%s - %s**\n",
-						startTime.Format("Jan 2, 3:04 PM"),
-						endTime.Format("Jan 2, 3:04 PM"))
+						tf.MonthDay(startTime)+", "+tf.Clock(startTime),
+						tf.MonthDay(endTime)+", "+tf.Clock(endTime))
 				}
 			} else {
-				fmt.Fprintf(result, "🕐 **%s**\n", startTime.Format("3:04 PM"))
+				fmt.Fprintf(result, "🕐 **%s**\n", tf.Clock(startTime))
 			}
 		}
 	}
+	if span != "" {
+		fmt.Fprintf(result, "%s\n", span)
+	}
 
 	// Location
 	if event.Location != "" {
 		fmt.Fprintf(result, "📍 **Location:** %s\n", event.Location)
 	}
 
+	// Organizer, and the creator when someone else created it on their behalf
+	if event.Organizer != nil {
+		fmt.Fprintf(result, "👤 **Organizer:** %s\n", formatPerson(event.Organizer.Email, event.Organizer.DisplayName, event.Organizer.Self))
+		if event.Creator != nil && !strings.EqualFold(event.Creator.Email, event.Organizer.Email) {
+			fmt.Fprintf(result, "✍️ **Created by:** %s\n", formatPerson(event.Creator.Email, event.Creator.DisplayName, event.Creator.Self))
+		}
+	}
+
 	// Attendees
 	if len(event.Attendees) > 0 {
-		result.WriteString("👥 **Attendees:** ")
-		attendeeStrings := make([]string, 0, len(event.Attendees))
-		for _, attendee := range event.Attendees {
+		shown, hidden := shownAttendees(event.Attendees, attendeeLimit)
+		var notes []string
+		if hidden > 0 {
+			notes = append(notes, fmt.Sprintf("%d: %s", len(event.Attendees), rsvpSummary(event.Attendees)))
+		}
+		if event.AttendeesOmitted {
+			notes = append(notes, "partial list")
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(result, "👥 **Attendees (%s):** ", strings.Join(notes, "; "))
+		} else {
+			result.WriteString("👥 **Attendees:** ")
+		}
+		attendeeStrings := make([]string, 0, len(shown)+1)
+		for _, attendee := range shown {
 			name := attendee.DisplayName
 			if name == "" {
 				name = attendee.Email
@@ -1637,12 +4119,27 @@ func (ct *CalendarTools) formatSingleEvent(result *strings.Builder, event *calen
 				statusIcon = ""
 			}
 
+			// Show the note the attendee sent with their RSVP
+			if attendee.Comment != "" {
+				statusIcon += fmt.Sprintf(" (%q)", attendee.Comment)
+			}
+
 			attendeeStrings = append(attendeeStrings, name+statusIcon)
 		}
+		if hidden > 0 {
+			attendeeStrings = append(attendeeStrings, fmt.Sprintf("and %d more", hidden))
+		}
 		result.WriteString(strings.Join(attendeeStrings, ", "))
 		result.WriteString("\n")
 	}
 
+	if priority := eventPriorityLevel(event); priority != "" {
+		fmt.Fprintf(result, "🔺 **Priority:** %s\n", priority)
+	}
+	if eventApproval(event) == approvalPending {
+		result.WriteString("⏳ **Pending approval** (approve with approve_event)\n")
+	}
+
 	// Description (truncated)
 	if event.Description != "" {
 		description := event.Description
@@ -1653,13 +4150,19 @@ func (ct *CalendarTools) formatSingleEvent(result *strings.Builder, event *calen
 	}
 
 	// Conference/meeting link
-	if event.ConferenceData != nil && len(event.ConferenceData.EntryPoints) > 0 {
-		for _, entry := range event.ConferenceData.EntryPoints {
-			if entry.EntryPointType == "video" {
-				fmt.Fprintf(result, "🔗 **Meeting Link:** %s\n", entry.Uri)
-				break
-			}
-		}
+	if link := meetLink(event); link != "" {
+		fmt.Fprintf(result, "🔗 **Meeting Link:** %s\n", link)
+	}
+	for _, d := range dialIns(event) {
+		fmt.Fprintf(result, "📞 **Dial-in:** %s\n", describeDialIn(d))
+	}
+
+	// Calendar web link
+	if event.HtmlLink != "" {
+		fmt.Fprintf(result, "🌐 **Calendar Link:** %s\n", event.HtmlLink)
+	}
+	if source := describeSource(event); source != "" {
+		fmt.Fprintf(result, "📎 **Source:** %s\n", source)
 	}
 
 	// Attachments (e.g. Gemini Notes)
@@ -1730,11 +4233,16 @@ func (ct *CalendarTools) formatSingleEvent(result *strings.Builder, event *calen
 		}
 	}
 
+	// Meaning of the color from the color legend
+	if category != "" {
+		fmt.Fprintf(result, "🏷️ **Category:** %s\n", category)
+	}
+
 	// Color information - always show to debug what's being returned
 	fmt.Fprintf(result, "🎨 **Color ID:** '%s' (length: %d)\n", event.ColorId, len(event.ColorId))
 
 	// Event ID for reference
-	fmt.Fprintf(result, "🆔 **Event ID:** %s\n", event.Id)
+	fmt.Fprintf(result, "🆔 **Event ID:** %s (handle: %s)\n", event.Id, ct.handles.handle(event.Id))
 
 	// Overlap status
 	overlapIcon := "✅"
@@ -1765,7 +4273,7 @@ func (ct *CalendarTools) handleGetMeetingContext(arguments map[string]interface{
 	if eventID == "" {
 		return nil, fmt.Errorf("event_id is required")
 	}
-	calendarID := getStringOrDefault(arguments, "calendar_id", "primary")
+	calendarID := ct.calendarID(arguments)
 
 	result, err := ct.client.GetMeetingContext(GetMeetingContextParams{
 		CalendarID: calendarID,
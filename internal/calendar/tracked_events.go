@@ -0,0 +1,347 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+	"gcal-mcp-server/internal/quota"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// TrackedResourceURI is the resource listing unreported changes to the
+	// events track_event follows
+	TrackedResourceURI = "calendar://tracked/changes"
+	// maxTrackedEvents bounds how many events are tracked; every check reads
+	// each of them
+	maxTrackedEvents = 20
+)
+
+// TrackedEvent is an event followed with track_event: its state when last
+// checked, and the changes found since they were last reported.
+type TrackedEvent struct {
+	CalendarID string        `json:"calendar_id"`
+	EventID    string        `json:"event_id"`
+	Summary    string        `json:"summary"`
+	Snapshot   EventSnapshot `json:"snapshot"`
+	Changes    []string      `json:"changes,omitempty"`
+}
+
+// EventSnapshot is the part of an event whose changes are reported: its
+// time, attendees and whether it is cancelled.
+type EventSnapshot struct {
+	Start     string            `json:"start"` // RFC3339, or a date for all-day events
+	End       string            `json:"end"`
+	Status    string            `json:"status"`
+	Attendees map[string]string `json:"attendees,omitempty"` // email -> response status
+}
+
+// TrackedChange is a tracked event that changed, as reported.
+type TrackedChange struct {
+	CalendarID string   `json:"calendar_id"`
+	EventID    string   `json:"event_id"`
+	Summary    string   `json:"summary"`
+	Changes    []string `json:"changes"`
+}
+
+// trackedState serializes checks of tracked events with track_event, so
+// neither loses the other's update of the preferences.
+type trackedState struct {
+	mu sync.Mutex
+}
+
+// snapshotEvent records the reported parts of event.
+func snapshotEvent(event *calendar.Event) EventSnapshot {
+	snapshot := EventSnapshot{Status: event.Status}
+	if event.Start != nil {
+		snapshot.Start = event.Start.DateTime + event.Start.Date
+	}
+	if event.End != nil {
+		snapshot.End = event.End.DateTime + event.End.Date
+	}
+	if len(event.Attendees) > 0 {
+		snapshot.Attendees = make(map[string]string, len(event.Attendees))
+		for _, a := range event.Attendees {
+			snapshot.Attendees[strings.ToLower(a.Email)] = a.ResponseStatus
+		}
+	}
+	return snapshot
+}
+
+// diffSnapshots describes how an event changed from before to after: a
+// cancellation, a new time, and attendees added, removed or answering
+// differently. Times are compared as instants, so the same time reported in
+// another zone is no change.
+func diffSnapshots(before, after EventSnapshot, tf TimeFormat) []string {
+	if after.Status == "cancelled" {
+		if before.Status == "cancelled" {
+			return nil
+		}
+		return []string{"cancelled"}
+	}
+	var changes []string
+	if before.Status == "cancelled" {
+		changes = append(changes, "restored")
+	}
+	if !sameInstant(before.Start, after.Start) || !sameInstant(before.End, after.End) {
+		changes = append(changes, fmt.Sprintf("moved from %s to %s", describeSnapshotTime(before, tf), describeSnapshotTime(after, tf)))
+	}
+
+	var emails []string
+	for email := range before.Attendees {
+		emails = append(emails, email)
+	}
+	for email := range after.Attendees {
+		if _, ok := before.Attendees[email]; !ok {
+			emails = append(emails, email)
+		}
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		old, had := before.Attendees[email]
+		status, has := after.Attendees[email]
+		switch {
+		case !had:
+			changes = append(changes, "added "+email)
+		case !has:
+			changes = append(changes, "removed "+email)
+		case old != status:
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", email, old, status))
+		}
+	}
+	return changes
+}
+
+// sameInstant compares two snapshot times: RFC3339 times by instant, dates
+// as they are.
+func sameInstant(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ta.Equal(tb)
+}
+
+// describeSnapshotTime renders a snapshot's time, e.g. "Tue, Mar 4, 10:00 – 11:00".
+func describeSnapshotTime(s EventSnapshot, tf TimeFormat) string {
+	start, err := time.Parse(time.RFC3339, s.Start)
+	if err != nil {
+		if day, err := time.Parse(dateLayout, s.Start); err == nil {
+			return tf.ShortDate(day) + " (all day)"
+		}
+		return s.Start
+	}
+	end, err := time.Parse(time.RFC3339, s.End)
+	if err != nil {
+		return tf.ShortDate(start) + ", " + tf.Clock(start)
+	}
+	return fmt.Sprintf("%s, %s – %s", tf.ShortDate(start), tf.Clock(start), describeEnd(start, end, tf))
+}
+
+// checkTrackedEvents re-reads every tracked event, records how each changed
+// since it was last checked, and returns the events with unreported changes
+// and whether any were found by this check. With report, the changes
+// returned are marked reported. A deleted event counts as cancelled; one that
+// cannot be read is skipped until the next check.
+func (ct *CalendarTools) checkTrackedEvents(report bool) ([]TrackedChange, bool, error) {
+	if ct.prefs == nil {
+		return nil, false, nil
+	}
+	ct.tracked.mu.Lock()
+	defer ct.tracked.mu.Unlock()
+
+	tracked := append([]TrackedEvent{}, ct.prefs.Get().TrackedEvents...)
+	if len(tracked) == 0 {
+		return nil, false, nil
+	}
+	tf := ct.client.TimeFormat()
+	found, renamed := false, false
+	for i := range tracked {
+		t := &tracked[i]
+		snapshot := t.Snapshot
+		event, err := ct.client.GetEvent(t.CalendarID, t.EventID)
+		switch {
+		case isNotFound(err):
+			snapshot.Status = "cancelled"
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Checking tracked event %s: %v\n", t.EventID, err)
+			continue
+		default:
+			snapshot = snapshotEvent(event)
+			if event.Summary != "" && event.Summary != t.Summary {
+				t.Summary, renamed = event.Summary, true
+			}
+		}
+		if changes := diffSnapshots(t.Snapshot, snapshot, tf); len(changes) > 0 {
+			t.Changes = append(append([]string{}, t.Changes...), changes...)
+			found = true
+		}
+		t.Snapshot = snapshot
+	}
+
+	var pending []TrackedChange
+	for i := range tracked {
+		if len(tracked[i].Changes) == 0 {
+			continue
+		}
+		t := tracked[i]
+		pending = append(pending, TrackedChange{CalendarID: t.CalendarID, EventID: t.EventID, Summary: t.Summary, Changes: t.Changes})
+		if report {
+			tracked[i].Changes = nil
+		}
+	}
+	if found || renamed || (report && len(pending) > 0) {
+		if err := ct.prefs.Update(func(p *Preferences) { p.TrackedEvents = tracked }); err != nil {
+			return nil, false, err
+		}
+	}
+	return pending, found, nil
+}
+
+// formatTrackedChanges renders changes to tracked events as one bullet per event.
+func formatTrackedChanges(changes []TrackedChange) string {
+	var result strings.Builder
+	for _, c := range changes {
+		fmt.Fprintf(&result, "• %s: %s\n", c.Summary, strings.Join(c.Changes, "; "))
+	}
+	return result.String()
+}
+
+// readTrackedChanges serves TrackedResourceURI, reporting the changes it lists.
+func (ct *CalendarTools) readTrackedChanges() (*mcp.ReadResourceResult, error) {
+	changes, _, err := ct.checkTrackedEvents(true)
+	if err != nil {
+		return nil, err
+	}
+	var text string
+	switch {
+	case ct.prefs == nil || len(ct.prefs.Get().TrackedEvents) == 0:
+		text = "No events are tracked; follow one with track_event.\n"
+	case len(changes) == 0:
+		text = fmt.Sprintf("No changes to the %d tracked event(s) since they were last reported.\n", len(ct.prefs.Get().TrackedEvents))
+	default:
+		text = fmt.Sprintf("🔔 Tracked events that changed (%d):\n\n%s", len(changes), formatTrackedChanges(changes))
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{{
+			URI:      TrackedResourceURI,
+			MimeType: "text/markdown",
+			Text:     text,
+		}},
+	}, nil
+}
+
+// StartTrackedWatch checks tracked events every interval while the client is
+// subscribed to TrackedResourceURI, and sends notifications/resources/updated
+// when one of them changed. Without a subscriber, changes are found when
+// morning_digest runs or the resource is read.
+func (ct *CalendarTools) StartTrackedWatch(notifier ResourceNotifier, interval time.Duration, budget *quota.Budget) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if !notifier.Subscribed(TrackedResourceURI) || budgetLow(budget) {
+				continue
+			}
+			_, found, err := ct.checkTrackedEvents(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Event watch: %v\n", err)
+				continue
+			}
+			if found {
+				notifier.NotifyResourceUpdated(TrackedResourceURI)
+			}
+		}
+	}()
+}
+
+func (ct *CalendarTools) handleTrackEvent(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+	eventID := getStringOrDefault(arguments, "event_id", "")
+	if eventID == "" {
+		return nil, fmt.Errorf("event_id is required")
+	}
+	calendarID := ct.calendarID(arguments)
+
+	ct.tracked.mu.Lock()
+	defer ct.tracked.mu.Unlock()
+	tracked := ct.prefs.Get().TrackedEvents
+	index := -1
+	for i, t := range tracked {
+		if t.CalendarID == calendarID && t.EventID == eventID {
+			index = i
+		}
+	}
+
+	if getBoolOrDefault(arguments, "stop", false) {
+		if index < 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.ToolResult{{Type: "text", Text: fmt.Sprintf("ℹ️ Event %s is not tracked; nothing to stop.", eventID)}},
+			}, nil
+		}
+		stopped := tracked[index]
+		if err := ct.prefs.Update(func(p *Preferences) {
+			p.TrackedEvents = append(append([]TrackedEvent{}, tracked[:index]...), tracked[index+1:]...)
+		}); err != nil {
+			return nil, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{Type: "text", Text: fmt.Sprintf("🛑 Stopped tracking '%s'.", stopped.Summary)}},
+		}, nil
+	}
+
+	if index < 0 && len(tracked) >= maxTrackedEvents {
+		return nil, fmt.Errorf("already tracking %d events, the most allowed; stop tracking one with stop: true first", maxTrackedEvents)
+	}
+	event, err := ct.client.GetEvent(calendarID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+	if event.Status == "cancelled" {
+		return nil, fmt.Errorf("event '%s' is cancelled", event.Summary)
+	}
+	entry := TrackedEvent{CalendarID: calendarID, EventID: eventID, Summary: event.Summary, Snapshot: snapshotEvent(event)}
+	updated := append([]TrackedEvent{}, tracked...)
+	verb := "Tracking"
+	if index >= 0 {
+		// Tracking again starts over from the event as it is now
+		updated[index] = entry
+		verb = "Still tracking"
+	} else {
+		updated = append(updated, entry)
+	}
+	if err := ct.prefs.Update(func(p *Preferences) { p.TrackedEvents = updated }); err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("👁️ %s '%s' (%s). Changes to its time or attendees, or its cancellation, are reported by morning_digest and in the %s resource, which notifies subscribed clients. %d event(s) tracked in profile '%s'.",
+		verb, event.Summary, describeSnapshotTime(entry.Snapshot, ct.client.TimeFormat()), TrackedResourceURI, len(updated), ct.prefs.Profile())
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: text}},
+	}, nil
+}
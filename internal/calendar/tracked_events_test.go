@@ -0,0 +1,142 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+
+	"gcal-mcp-server/internal/fake"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- diffSnapshots -----
+
+func TestDiffSnapshots(t *testing.T) {
+	tf := TimeFormat{Locale: "en", Clock24: true}
+	before := EventSnapshot{
+		Start:     "2025-03-04T10:00:00Z",
+		End:       "2025-03-04T11:00:00Z",
+		Status:    "confirmed",
+		Attendees: map[string]string{"ada@example.com": "accepted", "bob@example.com": "needsAction"},
+	}
+
+	// The same time in another zone is no change
+	same := before
+	same.Start, same.End = "2025-03-04T11:00:00+01:00", "2025-03-04T12:00:00+01:00"
+	if changes := diffSnapshots(before, same, tf); len(changes) != 0 {
+		t.Errorf("changes = %v, want none", changes)
+	}
+
+	after := EventSnapshot{
+		Start:     "2025-03-05T14:00:00Z",
+		End:       "2025-03-05T15:00:00Z",
+		Status:    "confirmed",
+		Attendees: map[string]string{"bob@example.com": "declined", "carol@example.com": "needsAction"},
+	}
+	want := []string{
+		"moved from Tue, Mar 4, 10:00 – 11:00 to Wed, Mar 5, 14:00 – 15:00",
+		"removed ada@example.com",
+		"bob@example.com: needsAction → declined",
+		"added carol@example.com",
+	}
+	if got := diffSnapshots(before, after, tf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+
+	after.Status = "cancelled"
+	if got := diffSnapshots(before, after, tf); len(got) != 1 || got[0] != "cancelled" {
+		t.Errorf("changes = %q, want only the cancellation", got)
+	}
+	if got := diffSnapshots(after, after, tf); len(got) != 0 {
+		t.Errorf("a cancellation should only be reported once, got %q", got)
+	}
+}
+
+// ----- track_event -----
+
+func TestTrackEvent(t *testing.T) {
+	store := fake.NewStore(fake.DemoOwner, "UTC")
+	svc, drv, err := fake.NewServices(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(svc, drv)
+	client.SetDisplaySettings(DisplaySettings{Locale: "en", Clock: "24h"})
+	ct := NewCalendarTools(client)
+	prefs, _ := LoadPreferenceStore("", "default")
+	ct.SetPreferences(prefs)
+
+	result, err := ct.HandleTool("create_event", map[string]interface{}{
+		"summary":    "Board meeting",
+		"start_time": "2030-03-04T10:00:00Z",
+		"end_time":   "2030-03-04T11:00:00Z",
+		"attendees":  []interface{}{"ada@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventID := result.StructuredContent.(*calendar.Event).Id
+
+	result, err = ct.HandleTool("track_event", map[string]interface{}{"event_id": eventID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "Tracking 'Board meeting' (Mon, Mar 4, 10:00 – 11:00)") {
+		t.Errorf("confirmation = %s", text)
+	}
+	if changes, _, err := ct.checkTrackedEvents(true); err != nil || len(changes) != 0 {
+		t.Fatalf("an unchanged event should report nothing, got %v, %v", changes, err)
+	}
+
+	if _, err := ct.HandleTool("edit_event", map[string]interface{}{
+		"event_id":   eventID,
+		"start_time": "2030-03-04T13:00:00Z",
+		"end_time":   "2030-03-04T14:00:00Z",
+		"attendees":  []interface{}{"ada@example.com", "bob@example.com"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// A check without reporting keeps the changes for the digest or the resource
+	if changes, found, err := ct.checkTrackedEvents(false); err != nil || !found || len(changes) != 1 {
+		t.Fatalf("checkTrackedEvents = %v, %v, %v; want the edit found", changes, found, err)
+	}
+	read, err := ct.ReadResource(TrackedResourceURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := read.Contents[0].Text
+	if !strings.Contains(text, "• Board meeting: moved from Mon, Mar 4, 10:00 – 11:00 to Mon, Mar 4, 13:00 – 14:00; added bob@example.com") {
+		t.Errorf("resource = %s", text)
+	}
+	if read, _ := ct.ReadResource(TrackedResourceURI); !strings.Contains(read.Contents[0].Text, "No changes to the 1 tracked event(s)") {
+		t.Errorf("changes should be reported once, got %s", read.Contents[0].Text)
+	}
+
+	if _, err := ct.HandleTool("delete_event", map[string]interface{}{"event_id": eventID}); err != nil {
+		t.Fatal(err)
+	}
+	if changes, _, err := ct.checkTrackedEvents(true); err != nil || len(changes) != 1 || strings.Join(changes[0].Changes, ";") != "cancelled" {
+		t.Errorf("checkTrackedEvents = %v, %v; want the cancellation", changes, err)
+	}
+
+	if result, err := ct.HandleTool("track_event", map[string]interface{}{"event_id": eventID, "stop": true}); err != nil || !strings.Contains(result.Content[0].Text, "Stopped tracking 'Board meeting'") {
+		t.Errorf("stop: %v, %v", result, err)
+	}
+	if len(prefs.Get().TrackedEvents) != 0 {
+		t.Errorf("tracked = %+v, want none", prefs.Get().TrackedEvents)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gcal-mcp-server/internal/mcp"
+)
+
+func (ct *CalendarTools) handleGetUsage(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if ct.budget == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.ToolResult{{Type: "text", Text: "API usage tracking is not enabled for this server."}},
+		}, nil
+	}
+
+	usage := ct.budget.Usage()
+
+	var result strings.Builder
+	result.WriteString("📊 API usage this session:\n\n")
+	fmt.Fprintf(&result, "• Calls made: %d\n", usage.TotalCalls)
+	if usage.PerMinuteLimit > 0 {
+		fmt.Fprintf(&result, "• Budget: %d of %d requests/minute remaining\n", usage.Remaining, usage.PerMinuteLimit)
+	} else {
+		result.WriteString("• Budget: unlimited\n")
+	}
+	if usage.Rejected > 0 {
+		fmt.Fprintf(&result, "• ⚠️ Rejected by budget: %d\n", usage.Rejected)
+	}
+	if usage.Throttled > 0 {
+		fmt.Fprintf(&result, "• ⚠️ Throttled by Google (429): %d\n", usage.Throttled)
+	}
+
+	usageJSON, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode usage: %v", err)
+	}
+	fmt.Fprintf(&result, "\n%s", string(usageJSON))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{{Type: "text", Text: result.String()}},
+	}, nil
+}
@@ -0,0 +1,347 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gcal-mcp-server/internal/quota"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// warmCacheEnv lists the calendars to prefetch, comma separated; unset
+	// disables the warm cache
+	warmCacheEnv = "GCAL_MCP_WARM_CACHE"
+	// warmCacheIntervalEnv overrides how often cached calendars are checked for changes
+	warmCacheIntervalEnv = "GCAL_MCP_WARM_CACHE_INTERVAL"
+	// DefaultWarmCacheInterval is how often cached calendars are checked for changes
+	DefaultWarmCacheInterval = 2 * time.Minute
+	// minWarmCacheInterval is the shortest interval between checks; each one
+	// makes a request per cached calendar (see backgroundInterval)
+	minWarmCacheInterval = 30 * time.Second
+	// warmCacheReserve is the share of the per-minute request budget left to
+	// tools: background checks are skipped while less than this remains
+	warmCacheReserve = 0.25
+	// warmCacheMaxResults bounds the events kept per calendar
+	warmCacheMaxResults = 2500
+)
+
+// WarmCache keeps today's and tomorrow's events of selected calendars in
+// memory so list_events can answer from it without calling the API. It is
+// loaded at startup and checked for changes in the background every interval
+// with an updatedMin query, which costs one request when nothing changed.
+// Writes made through the server mark it stale, so the next read reloads first.
+type WarmCache struct {
+	calendars []string
+	interval  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*warmEntry
+	writes  int // changes seen by Middleware, so a load racing a write is not trusted
+}
+
+// warmEntry is one calendar's cached events over [start, end).
+type warmEntry struct {
+	start, end time.Time
+	loc        *time.Location // calendar time zone, for all-day events
+	events     []*calendar.Event
+	synced     time.Time // when the events were last known to be current
+	stale      bool
+}
+
+// NewWarmCache creates a warm cache for calendars, checked every interval.
+func NewWarmCache(calendars []string, interval time.Duration) *WarmCache {
+	interval = backgroundInterval(interval, minWarmCacheInterval)
+	return &WarmCache{calendars: calendars, interval: interval, entries: make(map[string]*warmEntry)}
+}
+
+// WarmCacheFromEnv creates the warm cache configured by GCAL_MCP_WARM_CACHE
+// and GCAL_MCP_WARM_CACHE_INTERVAL, or returns nil when it is not enabled.
+func WarmCacheFromEnv() *WarmCache {
+	var calendars []string
+	for _, id := range strings.Split(os.Getenv(warmCacheEnv), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			calendars = append(calendars, id)
+		}
+	}
+	if len(calendars) == 0 {
+		return nil
+	}
+
+	interval := DefaultWarmCacheInterval
+	if value := os.Getenv(warmCacheIntervalEnv); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			interval = d
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", warmCacheIntervalEnv, value)
+		}
+	}
+	return NewWarmCache(calendars, interval)
+}
+
+// warmWindow returns the range cached on now's UTC date, yesterday through
+// the day after tomorrow in UTC, which holds today and tomorrow in every time
+// zone.
+func warmWindow(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return midnight.AddDate(0, 0, -1), midnight.AddDate(0, 0, 3)
+}
+
+// Middleware wraps an HTTP transport so that any change to events made by the
+// server marks the cache stale. A nil cache returns next unchanged.
+func (w *WarmCache) Middleware(next http.RoundTripper) http.RoundTripper {
+	if w == nil {
+		return next
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet && strings.Contains(req.URL.Path, "/events") {
+			w.markStale()
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func (w *WarmCache) markStale() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	for _, entry := range w.entries {
+		entry.stale = true
+	}
+}
+
+// StartWarmCache attaches w to the client and loads it, then keeps it fresh
+// in the background. The first load runs before returning, together with the
+// per-session lookups a listing needs (the user's email and date format), so
+// it never races tool calls. Background checks are skipped while budget
+// (which may be nil) is running low, so they never crowd out tool calls.
+func (c *Client) StartWarmCache(w *WarmCache, budget *quota.Budget) {
+	if w == nil {
+		return
+	}
+	c.warmCache = w
+	if _, err := c.getUserEmail(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warm cache: %v\n", err)
+	}
+	c.TimeFormat()
+	for _, id := range w.calendars {
+		if _, err := c.calendarAccessRole(id); err != nil {
+			fmt.Fprintf(os.Stderr, "Warm cache: %v\n", err)
+		}
+		if err := w.load(c, id); err != nil {
+			fmt.Fprintf(os.Stderr, "Warm cache: %v\n", err)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if budgetLow(budget) {
+				continue
+			}
+			for _, id := range w.calendars {
+				if err := w.refresh(c, id); err != nil {
+					fmt.Fprintf(os.Stderr, "Warm cache: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// backgroundInterval raises the interval of a background loop to its floor.
+// The warm cache, the agenda and event watches, goal sync and hold cleanup
+// all make requests on every tick that are charged to the same per-minute
+// budget as tool calls, so each has a minimum interval that keeps the
+// background checks from using up the request budget.
+func backgroundInterval(interval, floor time.Duration) time.Duration {
+	if interval < floor {
+		return floor
+	}
+	return interval
+}
+
+// budgetLow reports whether less than warmCacheReserve of the per-minute
+// request budget remains.
+func budgetLow(budget *quota.Budget) bool {
+	if budget == nil {
+		return false
+	}
+	usage := budget.Usage()
+	return usage.PerMinuteLimit > 0 && float64(usage.Remaining) < warmCacheReserve*float64(usage.PerMinuteLimit)
+}
+
+// load reads every event of calendarID in the current window.
+func (w *WarmCache) load(c *Client, calendarID string) error {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	writes := w.writes
+	w.mu.Unlock()
+	started := time.Now()
+	start, end := warmWindow(started)
+
+	entry := &warmEntry{start: start, end: end, loc: time.UTC, synced: started}
+	call := c.service.Events.List(calendarID).
+		TimeMin(start.Format(time.RFC3339)).
+		TimeMax(end.Format(time.RFC3339)).
+		SingleEvents(true).
+		OrderBy("startTime").
+		AlwaysIncludeEmail(true).
+		MaxResults(250)
+	for {
+		page, err := call.Do()
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", calendarID, err)
+		}
+		if loc, err := loadLocation(page.TimeZone); err == nil && page.TimeZone != "" {
+			entry.loc = loc
+		}
+		entry.events = append(entry.events, page.Items...)
+		if page.NextPageToken == "" || len(entry.events) >= warmCacheMaxResults {
+			break
+		}
+		call = call.PageToken(page.NextPageToken)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entry.stale = w.writes != writes
+	w.entries[calendarID] = entry
+	return nil
+}
+
+// refresh brings calendarID up to date: the window is reloaded when the day
+// has changed, the cache is stale or any event changed since the last sync.
+func (w *WarmCache) refresh(c *Client, calendarID string) error {
+	w.mu.Lock()
+	entry, ok := w.entries[calendarID]
+	var synced time.Time
+	reload := !ok || entry.stale
+	if ok {
+		synced = entry.synced
+		start, _ := warmWindow(time.Now())
+		reload = reload || !start.Equal(entry.start)
+	}
+	w.mu.Unlock()
+	if reload {
+		return w.load(c, calendarID)
+	}
+
+	// Changes are looked for across the whole calendar, so events moved into
+	// or out of the window are noticed too
+	checked := time.Now()
+	changed, err := c.changedSince(calendarID, synced)
+	if err != nil {
+		return err
+	}
+	if changed {
+		return w.load(c, calendarID)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if current, ok := w.entries[calendarID]; ok && current == entry {
+		entry.synced = checked
+	}
+	return nil
+}
+
+// changedSince reports whether any event of calendarID was created, changed
+// or deleted since t, in one request. A minute of overlap allows for clock skew.
+func (c *Client) changedSince(calendarID string, t time.Time) (bool, error) {
+	if err := c.checkCalendar(calendarID); err != nil {
+		return false, err
+	}
+	changes, err := c.service.Events.List(calendarID).
+		UpdatedMin(t.Add(-time.Minute).Format(time.RFC3339)).
+		ShowDeleted(true).
+		MaxResults(1).
+		Fields("items(id)").
+		Do()
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s for changes: %v", calendarID, err)
+	}
+	return len(changes.Items) > 0, nil
+}
+
+// lookup returns the cached events of calendarID overlapping [timeMin,
+// timeMax), in the API's start order, if the cache can answer the request. A stale
+// entry is reloaded first, which costs one request rather than the request it
+// replaces.
+func (w *WarmCache) lookup(c *Client, calendarID string, timeMin, timeMax time.Time) ([]*calendar.Event, bool) {
+	if w == nil {
+		return nil, false
+	}
+	w.mu.Lock()
+	entry, ok := w.entries[calendarID]
+	stale := ok && entry.stale
+	w.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	if stale {
+		if err := w.load(c, calendarID); err != nil {
+			return nil, false
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entry = w.entries[calendarID]
+	if entry.stale || timeMin.Before(entry.start) || timeMax.After(entry.end) || len(entry.events) >= warmCacheMaxResults {
+		return nil, false
+	}
+
+	var events []*calendar.Event
+	for _, event := range entry.events {
+		start, end, allDay, err := parseEventTimes(event)
+		if err != nil {
+			continue
+		}
+		if allDay {
+			start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, entry.loc)
+			end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, entry.loc)
+		}
+		if start.Before(timeMax) && end.After(timeMin) {
+			// Callers may annotate the events they are given
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, true
+}
+
+// cacheable reports whether a listing can be answered from the warm cache:
+// only plain listings in start order, which the cache holds in full.
+func (params ListEventsParams) cacheable() bool {
+	return !params.ShowDeleted && params.Query == "" && params.MaxAttendees == 0 &&
+		(params.OrderBy == "" || params.OrderBy == "startTime")
+}
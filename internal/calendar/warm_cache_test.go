@@ -0,0 +1,167 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- warmWindow -----
+
+func TestWarmWindowCoversTodayAndTomorrowEverywhere(t *testing.T) {
+	for _, now := range []time.Time{
+		time.Date(2025, 3, 10, 0, 30, 0, 0, time.UTC),
+		time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC),
+	} {
+		start, end := warmWindow(now)
+		for _, zone := range []string{"Pacific/Kiritimati", "Etc/GMT+12", "America/New_York", "Asia/Kolkata"} {
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				t.Fatalf("LoadLocation: %v", err)
+			}
+			local := now.In(loc)
+			today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+			if today.Before(start) || today.AddDate(0, 0, 2).After(end) {
+				t.Errorf("%s at %v: today and tomorrow [%v, %v) outside window [%v, %v)", zone, now, today, today.AddDate(0, 0, 2), start, end)
+			}
+		}
+	}
+}
+
+// ----- WarmCacheFromEnv -----
+
+func TestWarmCacheFromEnv(t *testing.T) {
+	t.Setenv(warmCacheEnv, "")
+	if w := WarmCacheFromEnv(); w != nil {
+		t.Errorf("expected no warm cache when unset, got %+v", w)
+	}
+
+	t.Setenv(warmCacheEnv, " primary, team@example.com ,")
+	t.Setenv(warmCacheIntervalEnv, "5s")
+	w := WarmCacheFromEnv()
+	if w == nil || len(w.calendars) != 2 || w.calendars[1] != "team@example.com" {
+		t.Fatalf("unexpected calendars: %+v", w)
+	}
+	if w.interval != minWarmCacheInterval {
+		t.Errorf("interval = %v, want it raised to %v", w.interval, minWarmCacheInterval)
+	}
+
+	t.Setenv(warmCacheIntervalEnv, "soon")
+	if w := WarmCacheFromEnv(); w.interval != DefaultWarmCacheInterval {
+		t.Errorf("invalid interval should fall back to %v, got %v", DefaultWarmCacheInterval, w.interval)
+	}
+}
+
+// ----- lookup -----
+
+func TestWarmCacheLookup(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	start, end := warmWindow(now)
+	w := NewWarmCache([]string{"primary"}, time.Minute)
+	w.entries["primary"] = &warmEntry{
+		start: start,
+		end:   end,
+		loc:   time.UTC,
+		events: []*calendar.Event{
+			{Id: "holiday", Start: &calendar.EventDateTime{Date: "2025-03-10"}, End: &calendar.EventDateTime{Date: "2025-03-11"}},
+			{Id: "standup", Start: &calendar.EventDateTime{DateTime: "2025-03-10T09:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-10T09:15:00Z"}},
+			{Id: "late", Start: &calendar.EventDateTime{DateTime: "2025-03-10T23:30:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-11T00:30:00Z"}},
+			{Id: "tomorrow", Start: &calendar.EventDateTime{DateTime: "2025-03-11T10:00:00Z"}, End: &calendar.EventDateTime{DateTime: "2025-03-11T11:00:00Z"}},
+		},
+	}
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	events, ok := w.lookup(nil, "primary", day, day.AddDate(0, 0, 1))
+	if !ok {
+		t.Fatal("expected a cache hit for today")
+	}
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.Id)
+	}
+	if len(ids) != 3 || ids[0] != "holiday" || ids[2] != "late" {
+		t.Errorf("today = %v, want holiday, standup and late", ids)
+	}
+
+	if _, ok := w.lookup(nil, "primary", day, day.AddDate(0, 0, 7)); ok {
+		t.Error("a week extends past the cached window and should miss")
+	}
+	if _, ok := w.lookup(nil, "other@example.com", day, day.AddDate(0, 0, 1)); ok {
+		t.Error("an uncached calendar should miss")
+	}
+	var nilCache *WarmCache
+	if _, ok := nilCache.lookup(nil, "primary", day, day.AddDate(0, 0, 1)); ok {
+		t.Error("a disabled cache should miss")
+	}
+}
+
+// ----- Middleware -----
+
+func TestWarmCacheMiddlewareMarksWritesStale(t *testing.T) {
+	w := NewWarmCache([]string{"primary"}, time.Minute)
+	w.entries["primary"] = &warmEntry{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: w.Middleware(http.DefaultTransport)}
+
+	get, _ := http.NewRequest(http.MethodGet, server.URL+"/calendars/primary/events", nil)
+	resp, err := client.Do(get)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if w.entries["primary"].stale {
+		t.Error("a read should not mark the cache stale")
+	}
+
+	patch, _ := http.NewRequest(http.MethodPatch, server.URL+"/calendars/primary/events/e1", nil)
+	resp, err = client.Do(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !w.entries["primary"].stale {
+		t.Error("an event write should mark the cache stale")
+	}
+}
+
+// ----- cacheable -----
+
+func TestListEventsParamsCacheable(t *testing.T) {
+	tests := []struct {
+		name   string
+		params ListEventsParams
+		want   bool
+	}{
+		{"plain listing", ListEventsParams{TimeFilter: "today"}, true},
+		{"start order", ListEventsParams{OrderBy: "startTime"}, true},
+		{"search", ListEventsParams{Query: "review"}, false},
+		{"deleted events", ListEventsParams{ShowDeleted: true}, false},
+		{"truncated attendees", ListEventsParams{MaxAttendees: 5}, false},
+		{"updated order", ListEventsParams{OrderBy: "updated"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.cacheable(); got != tt.want {
+				t.Errorf("cacheable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
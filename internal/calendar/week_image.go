@@ -0,0 +1,487 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Layout of the week image, in pixels.
+const (
+	weekImageMargin       = 28 // left of the grid, for the hour labels
+	weekImageHeader       = 20 // above the grid, for the day-of-month labels
+	weekImageColumnWidth  = 110
+	weekImageHourHeight   = 32
+	weekImageAllDayHeight = 6 // per all-day bar, drawn above the hours
+	weekImageMaxAllDay    = 3 // all-day bars shown per day
+)
+
+// eventColorHex are the Calendar UI's colors for the event color IDs.
+var eventColorHex = map[string]string{
+	"1":  "#a4bdfc",
+	"2":  "#7ae7bf",
+	"3":  "#dbadff",
+	"4":  "#ff887c",
+	"5":  "#fbd75b",
+	"6":  "#ffb878",
+	"7":  "#46d6db",
+	"8":  "#e1e1e1",
+	"9":  "#5484ed",
+	"10": "#51b749",
+	"11": "#dc2127",
+}
+
+// fallbackCalendarColors color calendars whose list entry has no color, in
+// the order the calendars were given.
+var fallbackCalendarColors = []string{"#039be5", "#33b679", "#8e24aa", "#e67c73", "#f6bf26", "#616161"}
+
+var (
+	weekImageBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	weekImageHourLine   = color.RGBA{0xe8, 0xe8, 0xe8, 0xff}
+	weekImageDayLine    = color.RGBA{0xb0, 0xb0, 0xb0, 0xff}
+	weekImageLabel      = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	weekImageWeekend    = color.RGBA{0xf6, 0xf6, 0xf6, 0xff}
+)
+
+// WeekImageEvent is one event as drawn on the week image: a timed event on
+// one day (events crossing midnight are drawn once per day), or an all-day
+// event on one of its days.
+type WeekImageEvent struct {
+	CalendarID string
+	Title      string
+	Day        int       // column, 0 for the first day of the week
+	Start, End time.Time // local times, clipped to the day; zero for all-day events
+	AllDay     bool
+	Color      string // hex
+	ColorName  string // the event color's name, or the calendar for its default color
+}
+
+// weekImageEvents lays out the events of each calendar over the week starting
+// at firstDay (local midnight). Cancelled and declined events are left out;
+// events with their own color keep it, others take their calendar's color.
+func weekImageEvents(calendarIDs []string, events map[string][]*calendar.Event, calendarColors map[string]string, firstDay time.Time) []WeekImageEvent {
+	loc := firstDay.Location()
+	var laid []WeekImageEvent
+	for _, calendarID := range calendarIDs {
+		for _, e := range events[calendarID] {
+			if e.Status == "cancelled" || selfDeclined(e) {
+				continue
+			}
+			if e.Start == nil || e.End == nil {
+				continue
+			}
+			base := WeekImageEvent{CalendarID: calendarID, Title: eventTitle(e), Color: calendarColors[calendarID], ColorName: calendarID}
+			if hex, ok := eventColorHex[e.ColorId]; ok {
+				base.Color, base.ColorName = hex, eventColorNames[e.ColorId]
+			}
+
+			if e.Start.Date != "" {
+				for day := 0; day < 7; day++ {
+					date := firstDay.AddDate(0, 0, day).Format(dateLayout)
+					if date >= e.Start.Date && date < e.End.Date {
+						segment := base
+						segment.Day, segment.AllDay = day, true
+						laid = append(laid, segment)
+					}
+				}
+				continue
+			}
+
+			start, end, _, err := parseEventTimes(e)
+			if err != nil {
+				continue
+			}
+			start, end = start.In(loc), end.In(loc)
+			for day := 0; day < 7; day++ {
+				dayStart := firstDay.AddDate(0, 0, day)
+				dayEnd := firstDay.AddDate(0, 0, day+1)
+				if !start.Before(dayEnd) || !end.After(dayStart) {
+					continue
+				}
+				segment := base
+				segment.Day, segment.Start, segment.End = day, start, end
+				if segment.Start.Before(dayStart) {
+					segment.Start = dayStart
+				}
+				if segment.End.After(dayEnd) {
+					segment.End = dayEnd
+				}
+				laid = append(laid, segment)
+			}
+		}
+	}
+	return laid
+}
+
+// assignLanes places each day's overlapping timed events side by side. It
+// returns each event's lane and the number of lanes in its group of
+// overlapping events, indexed like events; all-day events get lane 0 of 1.
+func assignLanes(events []WeekImageEvent) (lanes, widths []int) {
+	lanes = make([]int, len(events))
+	widths = make([]int, len(events))
+	byDay := make(map[int][]int)
+	for i, e := range events {
+		widths[i] = 1
+		if !e.AllDay {
+			byDay[e.Day] = append(byDay[e.Day], i)
+		}
+	}
+	for _, indexes := range byDay {
+		sort.SliceStable(indexes, func(a, b int) bool { return events[indexes[a]].Start.Before(events[indexes[b]].Start) })
+
+		var group []int          // the current group of overlapping events
+		var laneEnds []time.Time // when each lane of the group frees up
+		var groupEnd time.Time
+		flush := func() {
+			for _, i := range group {
+				widths[i] = len(laneEnds)
+			}
+			group, laneEnds = nil, nil
+		}
+		for _, i := range indexes {
+			e := events[i]
+			if len(group) > 0 && !e.Start.Before(groupEnd) {
+				flush()
+			}
+			lane := -1
+			for l, free := range laneEnds {
+				if !e.Start.Before(free) {
+					lane = l
+					break
+				}
+			}
+			if lane < 0 {
+				lane = len(laneEnds)
+				laneEnds = append(laneEnds, time.Time{})
+			}
+			laneEnds[lane] = e.End
+			lanes[i] = lane
+			group = append(group, i)
+			if e.End.After(groupEnd) || len(group) == 1 {
+				groupEnd = e.End
+			}
+		}
+		flush()
+	}
+	return lanes, widths
+}
+
+// renderWeekImage draws the week starting at firstDay as a PNG grid: a
+// column per day, a row per hour from dayStart to dayEnd, all-day events as
+// bars above the hours and timed events as blocks in their color.
+func renderWeekImage(events []WeekImageEvent, firstDay time.Time, dayStart, dayEnd time.Duration) ([]byte, error) {
+	hours := int((dayEnd - dayStart + time.Hour - 1) / time.Hour)
+	allDayTop := weekImageHeader
+	gridTop := allDayTop + weekImageMaxAllDay*weekImageAllDayHeight + 2
+	width := weekImageMargin + 7*weekImageColumnWidth + 1
+	height := gridTop + hours*weekImageHourHeight + 1
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{weekImageBackground}, image.Point{}, draw.Src)
+
+	columnX := func(day int) int { return weekImageMargin + day*weekImageColumnWidth }
+	// offsetY maps a time of day to its row position, clamped to the grid
+	offsetY := func(offset time.Duration) int {
+		if offset < dayStart {
+			offset = dayStart
+		}
+		if offset > dayStart+time.Duration(hours)*time.Hour {
+			offset = dayStart + time.Duration(hours)*time.Hour
+		}
+		return gridTop + int((offset-dayStart)*weekImageHourHeight/time.Hour)
+	}
+
+	// Weekends shaded, hour lines, day lines and labels
+	for day := 0; day < 7; day++ {
+		date := firstDay.AddDate(0, 0, day)
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			fillRect(img, columnX(day), allDayTop, columnX(day+1), height-1, weekImageWeekend)
+		}
+		drawDigits(img, columnX(day)+4, 4, fmt.Sprintf("%02d", date.Day()), 2, weekImageLabel)
+	}
+	for h := 0; h <= hours; h++ {
+		y := gridTop + h*weekImageHourHeight
+		fillRect(img, weekImageMargin, y, width-1, y+1, weekImageHourLine)
+		if h < hours {
+			hour := int((dayStart + time.Duration(h)*time.Hour) / time.Hour)
+			drawDigits(img, 4, y+2, fmt.Sprintf("%02d", hour%24), 2, weekImageLabel)
+		}
+	}
+	for day := 0; day <= 7; day++ {
+		fillRect(img, columnX(day), allDayTop, columnX(day)+1, height, weekImageDayLine)
+	}
+
+	lanes, widths := assignLanes(events)
+	allDayRows := make(map[int]int)
+	for i, e := range events {
+		fill, ok := parseHexColor(e.Color)
+		if !ok {
+			fill, _ = parseHexColor(fallbackCalendarColors[0])
+		}
+		x0, x1 := columnX(e.Day)+2, columnX(e.Day+1)-1
+
+		if e.AllDay {
+			row := allDayRows[e.Day]
+			allDayRows[e.Day]++
+			if row >= weekImageMaxAllDay {
+				continue
+			}
+			y := allDayTop + row*weekImageAllDayHeight
+			fillRect(img, x0, y, x1, y+weekImageAllDayHeight-1, fill)
+			continue
+		}
+
+		day := firstDay.AddDate(0, 0, e.Day)
+		y0 := offsetY(e.Start.Sub(day))
+		y1 := offsetY(e.End.Sub(day))
+		if y1-y0 < 3 {
+			if y0 >= height-3 {
+				continue // entirely after the drawn hours
+			}
+			y1 = y0 + 3 // keep short or clipped events visible
+		}
+		laneWidth := (x1 - x0) / widths[i]
+		lx0 := x0 + lanes[i]*laneWidth
+		fillRect(img, lx0, y0+1, lx0+laneWidth-1, y1, fill)
+		fillRect(img, lx0, y0+1, lx0+2, y1, darken(fill))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// darken returns c at two thirds of its brightness, for event edges.
+func darken(c color.RGBA) color.RGBA {
+	return color.RGBA{c.R / 3 * 2, c.G / 3 * 2, c.B / 3 * 2, c.A}
+}
+
+// parseHexColor parses a "#rrggbb" color.
+func parseHexColor(hex string) (color.RGBA, bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, true
+}
+
+// digitGlyphs is a 3x5 pixel font for the digits, one row per string.
+var digitGlyphs = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// drawDigits writes a string of digits at (x, y), each pixel scale wide.
+func drawDigits(img *image.RGBA, x, y int, digits string, scale int, c color.RGBA) {
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			continue
+		}
+		for row, line := range digitGlyphs[r-'0'] {
+			for col, p := range line {
+				if p == '#' {
+					fillRect(img, x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale, c)
+				}
+			}
+		}
+		x += 4 * scale
+	}
+}
+
+// formatWeekImageLegend lists the drawn events by day, since the image has no
+// room for titles.
+func formatWeekImageLegend(events []WeekImageEvent, firstDay time.Time, tf TimeFormat, dayStart, dayEnd time.Duration) string {
+	var result strings.Builder
+	loc := firstDay.Location()
+	fmt.Fprintf(&result, "🗓️ Week of %s (%s), drawn %s–%s:\n", tf.LongDate(firstDay), loc.String(), formatClock(dayStart), formatClock(dayEnd))
+	if len(events) == 0 {
+		result.WriteString("\n• No events this week\n")
+		return result.String()
+	}
+
+	sorted := append([]WeekImageEvent{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Day != sorted[j].Day {
+			return sorted[i].Day < sorted[j].Day
+		}
+		if sorted[i].AllDay != sorted[j].AllDay {
+			return sorted[i].AllDay
+		}
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	day := -1
+	for _, e := range sorted {
+		if e.Day != day {
+			day = e.Day
+			fmt.Fprintf(&result, "\n%s:\n", tf.ShortDate(firstDay.AddDate(0, 0, day)))
+		}
+		when := "all day"
+		if !e.AllDay {
+			when = tf.Clock(e.Start) + "–" + tf.Clock(e.End)
+			midnight := firstDay.AddDate(0, 0, e.Day)
+			if e.End.Sub(midnight) <= dayStart || e.Start.Sub(midnight) >= dayEnd {
+				when += " (outside the drawn hours)"
+			}
+		}
+		fmt.Fprintf(&result, "• %s %s [%s]\n", when, e.Title, e.ColorName)
+	}
+	return result.String()
+}
+
+// weekImageFirstDay resolves the week_start argument of render_week_image:
+// YYYY-MM-DD, or by default the Monday of the current week, as local
+// midnight in loc.
+func weekImageFirstDay(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if value != "" {
+		day, err := time.ParseInLocation(dateLayout, value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid week_start %q: expected YYYY-MM-DD", value)
+		}
+		return day, nil
+	}
+	now = now.In(loc)
+	daysFromMonday := (int(now.Weekday()) + 6) % 7
+	return time.Date(now.Year(), now.Month(), now.Day()-daysFromMonday, 0, 0, 0, 0, loc), nil
+}
+
+func (ct *CalendarTools) handleRenderWeekImage(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	var calendarIDs []string
+	if values, ok := arguments["calendar_ids"].([]interface{}); ok {
+		for _, v := range values {
+			id, ok := v.(string)
+			if !ok || id == "" {
+				return nil, fmt.Errorf("all calendar_ids must be non-empty strings")
+			}
+			calendarIDs = append(calendarIDs, id)
+		}
+	}
+	if len(calendarIDs) == 0 {
+		calendarIDs = []string{ct.calendarID(arguments)}
+	}
+	if err := ct.fetchLimits.checkCalendars(calendarIDs); err != nil {
+		return nil, err
+	}
+
+	// Calendar colors come from the calendar list; the first calendar's
+	// time zone is the default
+	calendarColors := make(map[string]string)
+	timezone := getStringOrDefault(arguments, "timezone", "")
+	for i, id := range calendarIDs {
+		calendarColors[id] = fallbackCalendarColors[i%len(fallbackCalendarColors)]
+		entry, err := ct.client.CalendarListEntry(id)
+		if err != nil {
+			continue
+		}
+		if _, ok := parseHexColor(entry.BackgroundColor); ok {
+			calendarColors[id] = entry.BackgroundColor
+		}
+		if timezone == "" && entry.TimeZone != "" {
+			timezone = entry.TimeZone
+		}
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	firstDay, err := weekImageFirstDay(getStringOrDefault(arguments, "week_start", ""), time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+	dayStart, err := parseClock(getStringOrDefault(arguments, "day_start", "08:00"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid day_start: %v", err)
+	}
+	dayEnd, err := parseClock(getStringOrDefault(arguments, "day_end", "20:00"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid day_end: %v", err)
+	}
+	if dayEnd < dayStart+time.Hour {
+		return nil, fmt.Errorf("day_end must be at least an hour after day_start")
+	}
+
+	// A calendar that cannot be listed is left out of the image and noted
+	listings := ct.client.listCalendars(calendarIDs, ListEventsParams{
+		TimeFilter:   "custom",
+		TimeMin:      firstDay,
+		TimeMax:      firstDay.AddDate(0, 0, 7),
+		TimeZone:     timezone,
+		SingleEvents: true,
+		OrderBy:      "startTime",
+	}, calendarListTimeout)
+	errs := calendarListErrors(listings)
+	if len(errs) == len(listings) {
+		return nil, calendarListFailure(errs)
+	}
+	events := make(map[string][]*calendar.Event)
+	for _, l := range listings {
+		if l.err == nil {
+			events[l.calendarID] = l.events.Items
+		}
+	}
+
+	laid := weekImageEvents(calendarIDs, events, calendarColors, firstDay)
+	pngData, err := renderWeekImage(laid, firstDay, dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render week image: %v", err)
+	}
+
+	legend := formatWeekImageLegend(laid, firstDay, ct.client.TimeFormat(), dayStart, dayEnd)
+	if len(errs) > 0 {
+		legend += "\n" + formatCalendarListErrors(errs)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.ToolResult{
+			{Type: "text", Text: legend},
+			{Type: "image", Data: base64.StdEncoding.EncodeToString(pngData), MimeType: "image/png"},
+		},
+	}, nil
+}
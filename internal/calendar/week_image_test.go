@@ -0,0 +1,164 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// ----- weekImageEvents -----
+
+func TestWeekImageEvents(t *testing.T) {
+	firstDay := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC) // a Monday
+	timed := func(id, start, end string) *calendar.Event {
+		return &calendar.Event{Id: id, Summary: id, Start: &calendar.EventDateTime{DateTime: start}, End: &calendar.EventDateTime{DateTime: end}}
+	}
+	overnight := timed("overnight", "2025-03-04T22:00:00Z", "2025-03-05T02:00:00Z")
+	colored := timed("colored", "2025-03-03T09:00:00Z", "2025-03-03T10:00:00Z")
+	colored.ColorId = "11"
+	declined := timed("declined", "2025-03-03T11:00:00Z", "2025-03-03T12:00:00Z")
+	declined.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+	offsite := &calendar.Event{Id: "offsite", Summary: "Offsite", Start: &calendar.EventDateTime{Date: "2025-03-06"}, End: &calendar.EventDateTime{Date: "2025-03-08"}}
+
+	events := map[string][]*calendar.Event{
+		"me@example.com":   {overnight, colored, declined},
+		"team@example.com": {offsite},
+	}
+	colors := map[string]string{"me@example.com": "#039be5", "team@example.com": "#33b679"}
+	got := weekImageEvents([]string{"me@example.com", "team@example.com"}, events, colors, firstDay)
+
+	var summary []string
+	for _, e := range got {
+		summary = append(summary, e.Title+"@"+string(rune('0'+e.Day))+"/"+e.ColorName)
+	}
+	want := []string{
+		"overnight@1/me@example.com", "overnight@2/me@example.com",
+		"colored@0/Tomato",
+		"Offsite@3/team@example.com", "Offsite@4/team@example.com",
+	}
+	if strings.Join(summary, ",") != strings.Join(want, ",") {
+		t.Fatalf("laid out %v, want %v", summary, want)
+	}
+	if got[0].End != firstDay.AddDate(0, 0, 2) || got[1].Start != firstDay.AddDate(0, 0, 2) {
+		t.Errorf("overnight event not split at midnight: %v–%v and %v–%v", got[0].Start, got[0].End, got[1].Start, got[1].End)
+	}
+	if got[2].Color != "#dc2127" || got[3].Color != "#33b679" || !got[3].AllDay {
+		t.Errorf("colors = %q, %q (all-day %v)", got[2].Color, got[3].Color, got[3].AllDay)
+	}
+}
+
+// ----- assignLanes -----
+
+func TestAssignLanes(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2025, 3, 3, hour, minute, 0, 0, time.UTC) }
+	events := []WeekImageEvent{
+		{Day: 0, Start: at(9, 0), End: at(10, 0)},
+		{Day: 0, Start: at(9, 30), End: at(10, 30)},
+		{Day: 0, Start: at(10, 0), End: at(11, 0)}, // reuses the first lane
+		{Day: 0, Start: at(12, 0), End: at(13, 0)}, // a group of its own
+		{Day: 1, Start: at(9, 0), End: at(10, 0)},
+		{Day: 0, AllDay: true},
+	}
+	lanes, widths := assignLanes(events)
+	wantLanes := []int{0, 1, 0, 0, 0, 0}
+	wantWidths := []int{2, 2, 2, 1, 1, 1}
+	for i := range events {
+		if lanes[i] != wantLanes[i] || widths[i] != wantWidths[i] {
+			t.Errorf("event %d: lane %d of %d, want %d of %d", i, lanes[i], widths[i], wantLanes[i], wantWidths[i])
+		}
+	}
+}
+
+// ----- renderWeekImage -----
+
+func TestRenderWeekImage(t *testing.T) {
+	firstDay := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	events := []WeekImageEvent{
+		{Day: 2, Start: firstDay.Add(2*24*time.Hour + 9*time.Hour), End: firstDay.Add(2*24*time.Hour + 11*time.Hour), Color: "#dc2127"},
+	}
+	data, err := renderWeekImage(events, firstDay, 8*time.Hour, 18*time.Hour)
+	if err != nil {
+		t.Fatalf("renderWeekImage: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+	gridTop := weekImageHeader + weekImageMaxAllDay*weekImageAllDayHeight + 2
+	if b := img.Bounds(); b.Dx() != weekImageMargin+7*weekImageColumnWidth+1 || b.Dy() != gridTop+10*weekImageHourHeight+1 {
+		t.Errorf("image is %dx%d", b.Dx(), b.Dy())
+	}
+
+	// Wednesday 10:30 is inside the event and red; the same time on Tuesday is not
+	y := gridTop + 2*weekImageHourHeight + weekImageHourHeight/2
+	x := weekImageMargin + 2*weekImageColumnWidth + weekImageColumnWidth/2
+	if got := color.RGBAModel.Convert(img.At(x, y)); got != (color.RGBA{0xdc, 0x21, 0x27, 0xff}) {
+		t.Errorf("event pixel = %v, want the Tomato color", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(x-weekImageColumnWidth, y)); got != weekImageBackground {
+		t.Errorf("empty pixel = %v, want the background", got)
+	}
+}
+
+// ----- weekImageFirstDay -----
+
+func TestWeekImageFirstDay(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	// Sunday evening in New York, already Monday in UTC
+	now := time.Date(2025, 3, 10, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Date(2025, 3, 3, 0, 0, 0, 0, loc), false},
+		{"2025-03-12", time.Date(2025, 3, 12, 0, 0, 0, 0, loc), false},
+		{"next week", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := weekImageFirstDay(tt.value, now, loc)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("weekImageFirstDay(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("weekImageFirstDay(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// ----- parseHexColor -----
+
+func TestParseHexColor(t *testing.T) {
+	if c, ok := parseHexColor("#039be5"); !ok || c != (color.RGBA{0x03, 0x9b, 0xe5, 0xff}) {
+		t.Errorf("parseHexColor(#039be5) = %v, %v", c, ok)
+	}
+	for _, bad := range []string{"", "039be5", "#039be", "#zzzzzz"} {
+		if _, ok := parseHexColor(bad); ok {
+			t.Errorf("parseHexColor(%q) accepted", bad)
+		}
+	}
+}
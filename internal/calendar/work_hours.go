@@ -0,0 +1,329 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gcal-mcp-server/internal/mcp"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// defaultWorkStart and defaultWorkEnd bound the working day of the slot
+	// finders until working hours are saved
+	defaultWorkStart = 9 * time.Hour
+	defaultWorkEnd   = 17 * time.Hour
+	// workHoursDays is how many past days infer_working_hours looks at by default
+	workHoursDays = 30
+	// minWorkHoursDays is how many days with events inference needs
+	minWorkHoursDays = 5
+	// workHoursRounding rounds inferred hours out to quarter hours
+	workHoursRounding = 15 * time.Minute
+	// busiestHoursShown is how many of the busiest hours are reported
+	busiestHoursShown = 3
+)
+
+// WorkingHours are the user's usual working hours as HH:MM, saved in the
+// preferences and used as the default work_start and work_end of the slot
+// finders.
+type WorkingHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// workHours returns the working day from the work_start and work_end
+// arguments, defaulting to the saved working hours, else 09:00-17:00.
+func (ct *CalendarTools) workHours(arguments map[string]interface{}) (time.Duration, time.Duration, error) {
+	start, end := formatClock(defaultWorkStart), formatClock(defaultWorkEnd)
+	if ct.prefs != nil {
+		if saved := ct.prefs.Get().WorkingHours; saved != nil {
+			start, end = saved.Start, saved.End
+		}
+	}
+
+	workStart, err := parseClock(getStringOrDefault(arguments, "work_start", start))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid work_start: %v", err)
+	}
+	workEnd, err := parseClock(getStringOrDefault(arguments, "work_end", end))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid work_end: %v", err)
+	}
+	if workEnd <= workStart {
+		return 0, 0, fmt.Errorf("work_end must be after work_start")
+	}
+	return workStart, workEnd, nil
+}
+
+// WeekdayDensity is how busy one weekday was on average.
+type WeekdayDensity struct {
+	Weekday      string  `json:"weekday"`
+	Days         int     `json:"days"`          // occurrences of the weekday in the range
+	Meetings     float64 `json:"meetings"`      // average meetings per day
+	MeetingHours float64 `json:"meeting_hours"` // average hours in meetings per day
+}
+
+// HourDensity is how many meetings overlapped one hour of the day.
+type HourDensity struct {
+	Hour     string `json:"hour"` // HH:00
+	Meetings int    `json:"meetings"`
+}
+
+// WorkHoursInference is the working pattern found in past events.
+type WorkHoursInference struct {
+	From           string           `json:"from"` // first day analyzed, YYYY-MM-DD
+	To             string           `json:"to"`   // last day analyzed
+	TimeZone       string           `json:"time_zone"`
+	ActiveDays     int              `json:"active_days"` // days with at least one event
+	Start          string           `json:"start"`       // typical start of the day, HH:MM
+	End            string           `json:"end"`         // typical end of the day, HH:MM
+	EarliestStart  string           `json:"earliest_start"`
+	LatestEnd      string           `json:"latest_end"`
+	MeetingsPerDay float64          `json:"meetings_per_day"` // over active days
+	Weekdays       []WeekdayDensity `json:"weekdays"`
+	BusiestHours   []HourDensity    `json:"busiest_hours,omitempty"`
+	Saved          bool             `json:"saved"`
+}
+
+// workDay is the span of one day's events, as offsets from midnight.
+type workDay struct {
+	first, last time.Duration
+}
+
+// inferWorkHours finds the typical working day in events over the days
+// [from, to) in loc. The day starts at the median of each day's first event
+// and ends at the median of each day's last, rounded out to quarter hours,
+// so the odd early call or late dinner does not stretch it. Every timed event
+// that makes the user busy counts towards the span; meetings, as opposed to
+// focus time or working locations, make up the density figures.
+func inferWorkHours(events []*calendar.Event, from, to time.Time, loc *time.Location) (WorkHoursInference, error) {
+	inference := WorkHoursInference{
+		From:     from.Format("2006-01-02"),
+		To:       to.AddDate(0, 0, -1).Format("2006-01-02"),
+		TimeZone: loc.String(),
+	}
+
+	days := make(map[string]*workDay)
+	meetings := make(map[time.Weekday]int)
+	meetingTime := make(map[time.Weekday]time.Duration)
+	hours := make([]int, 24)
+	totalMeetings := 0
+	for _, e := range events {
+		if !blocksTime(e) || e.EventType == "workingLocation" || e.EventType == "outOfOffice" {
+			continue
+		}
+		start, end, _, err := parseEventTimes(e)
+		if err != nil || !end.After(start) {
+			continue
+		}
+		start, end = start.In(loc), end.In(loc)
+		midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		// Events running past midnight, or for a day or more, say nothing
+		// about the working day
+		if end.Sub(midnight) > 24*time.Hour {
+			continue
+		}
+
+		date := start.Format("2006-01-02")
+		first, last := start.Sub(midnight), end.Sub(midnight)
+		if d, ok := days[date]; ok {
+			d.first = min(d.first, first)
+			d.last = max(d.last, last)
+		} else {
+			days[date] = &workDay{first: first, last: last}
+		}
+
+		if !isMeeting(e) {
+			continue
+		}
+		totalMeetings++
+		meetings[start.Weekday()]++
+		meetingTime[start.Weekday()] += end.Sub(start)
+		for h := start.Hour(); h < 24 && midnight.Add(time.Duration(h)*time.Hour).Before(end); h++ {
+			hours[h]++
+		}
+	}
+
+	inference.ActiveDays = len(days)
+	if len(days) < minWorkHoursDays {
+		return inference, fmt.Errorf("only %d day(s) between %s and %s have events; at least %d are needed to infer working hours", len(days), inference.From, inference.To, minWorkHoursDays)
+	}
+
+	firsts := make([]time.Duration, 0, len(days))
+	lasts := make([]time.Duration, 0, len(days))
+	for _, d := range days {
+		firsts = append(firsts, d.first)
+		lasts = append(lasts, d.last)
+	}
+	start := medianDuration(firsts).Truncate(workHoursRounding)
+	end := medianDuration(lasts)
+	if rem := end % workHoursRounding; rem != 0 {
+		end += workHoursRounding - rem
+	}
+	if end <= start {
+		return inference, fmt.Errorf("the events between %s and %s show no regular working day", inference.From, inference.To)
+	}
+	inference.Start, inference.End = formatClock(start), formatClock(end)
+	inference.EarliestStart = formatClock(minDuration(firsts))
+	inference.LatestEnd = formatClock(maxDuration(lasts))
+	inference.MeetingsPerDay = roundTenth(float64(totalMeetings) / float64(len(days)))
+
+	// Averages are over every occurrence of the weekday, so a quiet Friday
+	// counts as quiet rather than being left out
+	occurrences := make(map[time.Weekday]int)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		occurrences[day.Weekday()]++
+	}
+	for _, wd := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		n := occurrences[wd]
+		// Weekends only show up when something happened on them
+		if n == 0 || ((wd == time.Saturday || wd == time.Sunday) && meetings[wd] == 0) {
+			continue
+		}
+		inference.Weekdays = append(inference.Weekdays, WeekdayDensity{
+			Weekday:      wd.String(),
+			Days:         n,
+			Meetings:     roundTenth(float64(meetings[wd]) / float64(n)),
+			MeetingHours: roundTenth(meetingTime[wd].Hours() / float64(n)),
+		})
+	}
+
+	for h, n := range hours {
+		if n > 0 {
+			inference.BusiestHours = append(inference.BusiestHours, HourDensity{Hour: fmt.Sprintf("%02d:00", h), Meetings: n})
+		}
+	}
+	sort.SliceStable(inference.BusiestHours, func(i, j int) bool {
+		return inference.BusiestHours[i].Meetings > inference.BusiestHours[j].Meetings
+	})
+	if len(inference.BusiestHours) > busiestHoursShown {
+		inference.BusiestHours = inference.BusiestHours[:busiestHoursShown]
+	}
+	return inference, nil
+}
+
+// medianDuration returns the median of values, the lower middle one for an
+// even count. values must not be empty; it is sorted in place.
+func medianDuration(values []time.Duration) time.Duration {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values[(len(values)-1)/2]
+}
+
+func minDuration(values []time.Duration) time.Duration {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = min(m, v)
+	}
+	return m
+}
+
+func maxDuration(values []time.Duration) time.Duration {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = max(m, v)
+	}
+	return m
+}
+
+func roundTenth(v float64) float64 {
+	return float64(int(v*10+0.5)) / 10
+}
+
+func (ct *CalendarTools) handleInferWorkingHours(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	days := getIntOrDefault(arguments, "days", workHoursDays)
+	if days < 7 {
+		return nil, fmt.Errorf("days must be at least 7")
+	}
+	save := getBoolOrDefault(arguments, "save", false)
+	if save && ct.prefs == nil {
+		return nil, fmt.Errorf("preferences are not enabled for this server")
+	}
+
+	calendarID := ct.calendarID(arguments)
+	loc, err := ct.goalLocation(calendarID, getStringOrDefault(arguments, "timezone", ""))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -days)
+	if err := ct.fetchLimits.checkRange(from, to); err != nil {
+		return nil, err
+	}
+
+	events, err := ct.client.ListEvents(ListEventsParams{
+		CalendarID:   calendarID,
+		TimeFilter:   "custom",
+		TimeMin:      from,
+		TimeMax:      to,
+		TimeZone:     loc.String(),
+		MaxResults:   2500,
+		SingleEvents: true,
+		OrderBy:      "startTime",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	inference, err := inferWorkHours(events.Items, from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	if save {
+		hours := &WorkingHours{Start: inference.Start, End: inference.End}
+		if err := ct.prefs.Update(func(p *Preferences) { p.WorkingHours = hours }); err != nil {
+			return nil, err
+		}
+		inference.Saved = true
+	}
+	return structuredResult(formatWorkHoursInference(inference), inference), nil
+}
+
+// formatWorkHoursInference renders the inferred working pattern, followed
+// by the structured data.
+func formatWorkHoursInference(inference WorkHoursInference) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "🕘 Working hours from %s to %s (%s), %d day(s) with events:\n\n", inference.From, inference.To, inference.TimeZone, inference.ActiveDays)
+	fmt.Fprintf(&result, "Typical day: %s–%s (earliest start %s, latest end %s)\n", inference.Start, inference.End, inference.EarliestStart, inference.LatestEnd)
+	fmt.Fprintf(&result, "Meetings: %.1f per day with events\n", inference.MeetingsPerDay)
+	for _, wd := range inference.Weekdays {
+		fmt.Fprintf(&result, "• %s: %.1f meeting(s), %.1f hour(s) on average\n", wd.Weekday, wd.Meetings, wd.MeetingHours)
+	}
+	if len(inference.BusiestHours) > 0 {
+		busiest := make([]string, len(inference.BusiestHours))
+		for i, h := range inference.BusiestHours {
+			busiest[i] = fmt.Sprintf("%s (%d)", h.Hour, h.Meetings)
+		}
+		fmt.Fprintf(&result, "Busiest hours: %s\n", strings.Join(busiest, ", "))
+	}
+
+	if inference.Saved {
+		fmt.Fprintf(&result, "\n✅ Saved %s–%s as your working hours: share_availability, find_recurring_slot and rebalance_one_on_ones use them when work_start and work_end are not given.\n", inference.Start, inference.End)
+	} else {
+		fmt.Fprintf(&result, "\n💡 Call infer_working_hours again with save: true to use %s–%s as the default working day of share_availability, find_recurring_slot and rebalance_one_on_ones.\n", inference.Start, inference.End)
+	}
+
+	data, _ := json.MarshalIndent(inference, "", "  ")
+	fmt.Fprintf(&result, "\n%s", string(data))
+	return result.String()
+}
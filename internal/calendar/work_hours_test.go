@@ -0,0 +1,109 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func workHoursEvent(start time.Time, length time.Duration) *calendar.Event {
+	return &calendar.Event{
+		Id:    start.Format("0102T1504"),
+		Start: &calendar.EventDateTime{DateTime: start.Format(time.RFC3339)},
+		End:   &calendar.EventDateTime{DateTime: start.Add(length).Format(time.RFC3339)},
+	}
+}
+
+func TestInferWorkHours(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, loc) // a Monday
+	to := from.AddDate(0, 0, 14)
+
+	var events []*calendar.Event
+	for d := 0; d < 14; d++ {
+		day := from.AddDate(0, 0, d)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		events = append(events,
+			workHoursEvent(day.Add(8*time.Hour+50*time.Minute), 30*time.Minute),
+			workHoursEvent(day.Add(16*time.Hour+20*time.Minute), 30*time.Minute),
+		)
+	}
+	// One early call and one late dinner do not move the typical day
+	events = append(events,
+		workHoursEvent(from.Add(6*time.Hour), time.Hour),
+		workHoursEvent(from.AddDate(0, 0, 1).Add(20*time.Hour), 2*time.Hour),
+	)
+	// Declined events and events past midnight are ignored
+	declined := workHoursEvent(from.Add(5*time.Hour), time.Hour)
+	declined.Attendees = []*calendar.EventAttendee{{Email: "me@example.com", Self: true, ResponseStatus: "declined"}}
+	events = append(events, declined, workHoursEvent(from.AddDate(0, 0, 2).Add(23*time.Hour), 2*time.Hour))
+
+	inference, err := inferWorkHours(events, from, to, loc)
+	if err != nil {
+		t.Fatalf("inferWorkHours: %v", err)
+	}
+	if inference.Start != "08:45" || inference.End != "17:00" {
+		t.Errorf("typical day = %s-%s, want 08:45-17:00", inference.Start, inference.End)
+	}
+	if inference.EarliestStart != "06:00" || inference.LatestEnd != "22:00" {
+		t.Errorf("extremes = %s, %s, want 06:00, 22:00", inference.EarliestStart, inference.LatestEnd)
+	}
+	if inference.ActiveDays != 10 {
+		t.Errorf("active days = %d, want 10", inference.ActiveDays)
+	}
+	if len(inference.Weekdays) != 5 || inference.Weekdays[0].Weekday != "Monday" || inference.Weekdays[0].Meetings != 2.5 {
+		t.Errorf("weekdays = %+v, want Monday first with 2.5 meetings a day", inference.Weekdays)
+	}
+	if len(inference.BusiestHours) != busiestHoursShown || inference.BusiestHours[0].Meetings != 10 {
+		t.Errorf("busiest hours = %+v", inference.BusiestHours)
+	}
+
+	if _, err := inferWorkHours(events[:4], from, to, loc); err == nil || !strings.Contains(err.Error(), "at least") {
+		t.Errorf("expected too few days to fail, got %v", err)
+	}
+}
+
+func TestWorkHours(t *testing.T) {
+	ct := NewCalendarTools(&Client{})
+	start, end, err := ct.workHours(map[string]interface{}{})
+	if err != nil || start != defaultWorkStart || end != defaultWorkEnd {
+		t.Errorf("without preferences: got %v-%v, %v", start, end, err)
+	}
+
+	prefs, _ := LoadPreferenceStore("", "default")
+	ct.SetPreferences(prefs)
+	if err := prefs.Update(func(p *Preferences) { p.WorkingHours = &WorkingHours{Start: "08:15", End: "17:45"} }); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	start, end, err = ct.workHours(map[string]interface{}{})
+	if err != nil || formatClock(start) != "08:15" || formatClock(end) != "17:45" {
+		t.Errorf("saved hours: got %s-%s, %v", formatClock(start), formatClock(end), err)
+	}
+
+	// Arguments override the saved hours
+	start, end, err = ct.workHours(map[string]interface{}{"work_start": "10:00"})
+	if err != nil || formatClock(start) != "10:00" || formatClock(end) != "17:45" {
+		t.Errorf("work_start argument: got %s-%s, %v", formatClock(start), formatClock(end), err)
+	}
+	if _, _, err := ct.workHours(map[string]interface{}{"work_start": "18:00"}); err == nil {
+		t.Error("expected work_end before work_start to fail")
+	}
+}
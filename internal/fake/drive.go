@@ -0,0 +1,89 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package fake
+
+import (
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// fakeFile is a Drive file created through the API, with the text it was
+// uploaded with.
+type fakeFile struct {
+	file    *drive.File
+	content string
+}
+
+// CreateFile stores a new Drive file owned by the signed-in user. Google Docs
+// get a docs.google.com link, other files a drive.google.com one.
+func (s *Store) CreateFile(meta *drive.File, content string) (*drive.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := *meta
+	if file.Name == "" {
+		file.Name = "Untitled"
+	}
+	file.Kind = "drive#file"
+	file.Id = randomString("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_-", 44)
+	file.CreatedTime = time.Now().UTC().Format(time.RFC3339)
+	file.Owners = []*drive.User{{EmailAddress: s.owner, Me: true}}
+	file.WebViewLink = "https://drive.google.com/file/d/" + file.Id + "/view"
+	if file.MimeType == "application/vnd.google-apps.document" {
+		file.WebViewLink = "https://docs.google.com/document/d/" + file.Id + "/edit"
+	}
+	if s.files == nil {
+		s.files = make(map[string]*fakeFile)
+	}
+	s.files[file.Id] = &fakeFile{file: &file, content: content}
+	copied := file
+	return &copied, nil
+}
+
+// ShareFile adds a permission to a file created with CreateFile.
+func (s *Store) ShareFile(fileID string, permission *drive.Permission) (*drive.Permission, error) {
+	if permission.Role == "" || permission.Type == "" {
+		return nil, badRequest("permission role and type are required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[fileID]
+	if !ok {
+		return nil, notFound("file " + fileID)
+	}
+	p := *permission
+	p.Kind = "drive#permission"
+	p.Id = randomString("0123456789", 20)
+	f.file.Permissions = append(f.file.Permissions, &p)
+	copied := p
+	return &copied, nil
+}
+
+// File returns a file created with CreateFile and its content.
+func (s *Store) File(fileID string) (*drive.File, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[fileID]
+	if !ok {
+		return nil, "", notFound("file " + fileID)
+	}
+	copied := *f.file
+	return &copied, f.content, nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxOccurrences bounds how many instances one expansion returns
+	maxOccurrences = 1000
+	// maxIterations bounds how many occurrences are walked to reach a window
+	maxIterations = 100000
+)
+
+// rrule is the subset of RFC 5545 recurrence rules the fake backend understands:
+// FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL and BYDAY for
+// weekly rules, plus the EXDATE lines that accompany the rule.
+type rrule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+	exdates  []time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRecurrence extracts the RRULE and EXDATEs from an event's recurrence
+// lines. It returns nil with no error if the event does not recur.
+func parseRecurrence(lines []string) (*rrule, error) {
+	var rule *rrule
+	var exdates []time.Time
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "RRULE:"):
+			if rule != nil {
+				continue
+			}
+			var err error
+			if rule, err = parseRRule(strings.TrimPrefix(line, "RRULE:")); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "EXDATE"):
+			dates, err := parseExdate(line)
+			if err != nil {
+				return nil, err
+			}
+			exdates = append(exdates, dates...)
+		}
+	}
+	if rule != nil {
+		rule.exdates = exdates
+	}
+	return rule, nil
+}
+
+// parseExdate reads an EXDATE line such as "EXDATE:20250106T090000Z",
+// "EXDATE;TZID=Europe/Paris:20250106T100000" or "EXDATE;VALUE=DATE:20250106".
+// Dates are taken as UTC midnight, like all-day event starts.
+func parseExdate(line string) ([]time.Time, error) {
+	head, values, ok := strings.Cut(line, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid EXDATE %q", line)
+	}
+	loc := time.UTC
+	for _, param := range strings.Split(head, ";")[1:] {
+		if tz, ok := strings.CutPrefix(param, "TZID="); ok {
+			var err error
+			if loc, err = time.LoadLocation(tz); err != nil {
+				return nil, fmt.Errorf("invalid EXDATE TZID %q", tz)
+			}
+		}
+	}
+
+	var dates []time.Time
+	for _, value := range strings.Split(values, ",") {
+		var t time.Time
+		var err error
+		switch {
+		case strings.HasSuffix(value, "Z"):
+			t, err = time.Parse("20060102T150405Z", value)
+		case strings.Contains(value, "T"):
+			t, err = time.ParseInLocation("20060102T150405", value, loc)
+		default:
+			t, err = time.Parse("20060102", value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid EXDATE value %q", value)
+		}
+		dates = append(dates, t)
+	}
+	return dates, nil
+}
+
+func parseRRule(spec string) (*rrule, error) {
+	rule := &rrule{interval: 1}
+	for _, part := range strings.Split(spec, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid RRULE INTERVAL %q", value)
+			}
+			rule.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid RRULE COUNT %q", value)
+			}
+			rule.count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[strings.ToUpper(code)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported RRULE BYDAY value %q", code)
+				}
+				rule.byDay = append(rule.byDay, day)
+			}
+		}
+	}
+
+	switch rule.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported RRULE FREQ %q", rule.freq)
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid RRULE UNTIL %q", value)
+}
+
+// occurrences returns the start times of the series beginning at start that
+// fall in [windowStart, windowEnd), in order. Zero window bounds are open. The
+// rule's COUNT and UNTIL are honoured from the first occurrence, and at most
+// maxOccurrences starts are returned. As in RFC 5545, excluded dates still
+// count toward COUNT.
+func (r *rrule) occurrences(start, windowStart, windowEnd time.Time) []time.Time {
+	var starts []time.Time
+	generated := 0
+	emit := func(t time.Time) bool {
+		if !r.until.IsZero() && t.After(r.until) {
+			return false
+		}
+		if !windowEnd.IsZero() && !t.Before(windowEnd) {
+			return false
+		}
+		// Guard against open-ended rules walking forever toward a distant window
+		if generated >= maxIterations {
+			return false
+		}
+		generated++
+		if (windowStart.IsZero() || !t.Before(windowStart)) && !r.excluded(t) {
+			starts = append(starts, t)
+		}
+		return !(r.count > 0 && generated >= r.count) && len(starts) < maxOccurrences
+	}
+
+	if r.freq == "WEEKLY" && len(r.byDay) > 0 {
+		// Walk day by day, keeping days that fall on a BYDAY weekday in an
+		// active week. Weeks are counted from the Monday on or before start.
+		offset := (int(start.Weekday()) + 6) % 7
+		for d := 0; ; d++ {
+			t := start.AddDate(0, 0, d)
+			week := (d + offset) / 7
+			if week%r.interval != 0 || !containsWeekday(r.byDay, t.Weekday()) {
+				continue
+			}
+			if !emit(t) {
+				return starts
+			}
+		}
+	}
+
+	for i := 0; ; i++ {
+		var t time.Time
+		switch r.freq {
+		case "DAILY":
+			t = start.AddDate(0, 0, i*r.interval)
+		case "WEEKLY":
+			t = start.AddDate(0, 0, 7*i*r.interval)
+		case "MONTHLY":
+			t = start.AddDate(0, i*r.interval, 0)
+		case "YEARLY":
+			t = start.AddDate(i*r.interval, 0, 0)
+		}
+		if !emit(t) {
+			return starts
+		}
+	}
+}
+
+// excluded reports whether t is one of the rule's EXDATEs.
+func (r *rrule) excluded(t time.Time) bool {
+	for _, ex := range r.exdates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
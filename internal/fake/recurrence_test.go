@@ -0,0 +1,112 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"testing"
+	"time"
+)
+
+// ----- parseRRule -----
+
+func TestParseRRule_Invalid(t *testing.T) {
+	for _, spec := range []string{"FREQ=HOURLY", "FREQ=DAILY;INTERVAL=0", "FREQ=WEEKLY;BYDAY=XX", "FREQ=DAILY;COUNT", "FREQ=DAILY;UNTIL=tomorrow"} {
+		if _, err := parseRRule(spec); err == nil {
+			t.Errorf("parseRRule(%q) expected error", spec)
+		}
+	}
+}
+
+// ----- occurrences -----
+
+func TestOccurrences(t *testing.T) {
+	// Monday 2025-01-06 09:00 UTC
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return start.AddDate(0, 0, d) }
+
+	cases := []struct {
+		name        string
+		spec        string
+		windowStart time.Time
+		windowEnd   time.Time
+		want        []time.Time
+	}{
+		{"daily count", "FREQ=DAILY;COUNT=3", time.Time{}, time.Time{}, []time.Time{day(0), day(1), day(2)}},
+		{"daily interval", "FREQ=DAILY;INTERVAL=2;COUNT=3", time.Time{}, time.Time{}, []time.Time{day(0), day(2), day(4)}},
+		{"weekly until", "FREQ=WEEKLY;UNTIL=20250120T090000Z", time.Time{}, time.Time{}, []time.Time{day(0), day(7), day(14)}},
+		{"weekly byday", "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", time.Time{}, time.Time{}, []time.Time{day(0), day(2), day(7), day(9)}},
+		{"biweekly byday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=2", time.Time{}, time.Time{}, []time.Time{day(1), day(15)}},
+		{"monthly count", "FREQ=MONTHLY;COUNT=2", time.Time{}, time.Time{}, []time.Time{day(0), start.AddDate(0, 1, 0)}},
+		{"window", "FREQ=DAILY", day(10), day(12), []time.Time{day(10), day(11)}},
+		{"count before window", "FREQ=DAILY;COUNT=5", day(3), time.Time{}, []time.Time{day(3), day(4)}},
+	}
+	for _, tc := range cases {
+		rule, err := parseRRule(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: parseRRule error: %v", tc.name, err)
+		}
+		got := rule.occurrences(start, tc.windowStart, tc.windowEnd)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %d occurrences %v, want %v", tc.name, len(got), got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%s: occurrence %d = %v, want %v", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestOccurrences_Exdates(t *testing.T) {
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return start.AddDate(0, 0, d) }
+
+	rule, err := parseRecurrence([]string{
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"EXDATE:20250107T090000Z",
+		"EXDATE;TZID=Europe/Paris:20250109T100000,20250120T100000",
+	})
+	if err != nil {
+		t.Fatalf("parseRecurrence error: %v", err)
+	}
+	// Excluded dates still count toward COUNT
+	want := []time.Time{day(0), day(2), day(4)}
+	got := rule.occurrences(start, time.Time{}, time.Time{})
+	if len(got) != len(want) {
+		t.Fatalf("got occurrences %v, want %v", got, want)
+	}
+	for i := range got {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseExdate_Invalid(t *testing.T) {
+	for _, line := range []string{"EXDATE", "EXDATE:tomorrow", "EXDATE;TZID=Nowhere/Else:20250107T090000"} {
+		if _, err := parseExdate(line); err == nil {
+			t.Errorf("parseExdate(%q) expected error", line)
+		}
+	}
+}
+
+func TestOccurrences_OpenEndedIsBounded(t *testing.T) {
+	rule, _ := parseRRule("FREQ=DAILY")
+	got := rule.occurrences(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}, time.Time{})
+	if len(got) != maxOccurrences {
+		t.Errorf("expected %d occurrences, got %d", maxOccurrences, len(got))
+	}
+}
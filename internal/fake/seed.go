@@ -0,0 +1,102 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package fake
+
+import (
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const (
+	// DemoOwner is the signed-in user of the demo store
+	DemoOwner = "demo@example.com"
+	// demoColleague has a calendar of their own for free/busy demos
+	demoColleague = "alex@example.com"
+	// demoTeam is a group for group free/busy demos
+	demoTeam = "team@example.com"
+)
+
+// NewDemoStore returns a store seeded with a realistic week of meetings around
+// now, in the local time zone, for offline demos.
+func NewDemoStore(now time.Time) *Store {
+	loc := now.Location()
+	tz := loc.String()
+	if tz == "Local" {
+		tz = "UTC"
+	}
+
+	s := NewStore(DemoOwner, tz)
+	s.AddCalendar(demoColleague, "Alex", tz)
+	// sam@example.com has no calendar shared with the owner
+	s.AddGroup(demoTeam, DemoOwner, demoColleague, "sam@example.com")
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	at := func(dayOffset, hour, minute int) time.Time {
+		return day.AddDate(0, 0, dayOffset).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	timed := func(summary string, start time.Time, d time.Duration, attendees ...string) *calendar.Event {
+		ev := &calendar.Event{
+			Summary: summary,
+			Start:   &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: tz},
+			End:     &calendar.EventDateTime{DateTime: start.Add(d).Format(time.RFC3339), TimeZone: tz},
+		}
+		for _, email := range attendees {
+			ev.Attendees = append(ev.Attendees, &calendar.EventAttendee{Email: email})
+		}
+		return ev
+	}
+
+	standup := timed("Team Standup", at(0, 9, 30), 15*time.Minute, DemoOwner, demoColleague)
+	standup.Recurrence = []string{"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}
+	standup.Attendees[0].ResponseStatus = "accepted"
+	standup.Attendees[1].ResponseStatus = "accepted"
+
+	review := timed("Design Review", at(0, 14, 0), time.Hour, DemoOwner, demoColleague, "sam@example.com")
+	review.Description = "Review the draft: https://docs.google.com/document/d/demo-design-doc/edit"
+	review.Attendees[1].ResponseStatus = "accepted"
+	review.Attendees[2].ResponseStatus = "tentative"
+
+	oneOnOne := timed("1:1 with Alex", at(1, 11, 0), 30*time.Minute, DemoOwner, demoColleague)
+	oneOnOne.Recurrence = []string{"RRULE:FREQ=WEEKLY"}
+
+	focus := timed("Focus Time", at(1, 13, 0), 2*time.Hour)
+	focus.EventType = "focusTime"
+
+	lunch := timed("Lunch", at(2, 12, 0), time.Hour)
+	lunch.Transparency = "transparent"
+
+	offsite := &calendar.Event{
+		Summary: "Team Offsite",
+		Start:   &calendar.EventDateTime{Date: day.AddDate(0, 0, 4).Format("2006-01-02")},
+		End:     &calendar.EventDateTime{Date: day.AddDate(0, 0, 5).Format("2006-01-02")},
+	}
+
+	for _, ev := range []*calendar.Event{standup, review, oneOnOne, focus, lunch, offsite} {
+		_, _ = s.InsertEvent(DemoOwner, ev, 0)
+	}
+
+	// The colleague's own commitments show up only through free/busy
+	for _, ev := range []*calendar.Event{
+		timed("Customer Call", at(0, 10, 0), time.Hour),
+		timed("Planning", at(1, 15, 0), 90*time.Minute),
+	} {
+		_, _ = s.InsertEvent(demoColleague, ev, 0)
+	}
+
+	return s
+}
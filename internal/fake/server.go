@@ -0,0 +1,468 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// Handler serves the Calendar v3 and Drive v3 REST endpoints used by the
+// server from a Store.
+type Handler struct {
+	store *Store
+}
+
+// NewHandler creates an HTTP handler backed by store.
+func NewHandler(store *Store) *Handler {
+	return &Handler{store: store}
+}
+
+// NewHTTPClient returns an HTTP client whose requests are answered in-process by
+// the handler, regardless of the host they are addressed to.
+func NewHTTPClient(store *Store) *http.Client {
+	return &http.Client{Transport: &handlerTransport{handler: NewHandler(store)}}
+}
+
+// NewServices returns Calendar and Drive services wired to store. If
+// middleware is non-nil it wraps the in-process transport, as it would the
+// real one.
+func NewServices(store *Store, middleware func(http.RoundTripper) http.RoundTripper) (*calendar.Service, *drive.Service, error) {
+	client := NewHTTPClient(store)
+	if middleware != nil {
+		client.Transport = middleware(client.Transport)
+	}
+
+	calendarService, err := calendar.NewService(context.Background(), option.WithHTTPClient(client))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create fake Calendar service: %v", err)
+	}
+	driveService, err := drive.NewService(context.Background(), option.WithHTTPClient(client))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create fake Drive service: %v", err)
+	}
+	return calendarService, driveService, nil
+}
+
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// ServeHTTP routes a request to the matching Calendar or Drive operation.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := pathSegments(r.URL)
+
+	switch {
+	case hasPrefix(segments, "calendar", "v3"):
+		h.serveCalendar(w, r, segments[2:])
+	case hasPrefix(segments, "drive", "v3", "files") && len(segments) == 5 && segments[4] == "export":
+		h.serveExport(w, r, segments[3])
+	case hasPrefix(segments, "upload", "drive", "v3", "files") && len(segments) == 4 && r.Method == http.MethodPost:
+		h.serveUpload(w, r)
+	case hasPrefix(segments, "drive", "v3", "files") && len(segments) == 5 && segments[4] == "permissions" && r.Method == http.MethodPost:
+		permission := &drive.Permission{}
+		if err := json.NewDecoder(r.Body).Decode(permission); err != nil {
+			writeError(w, badRequest("invalid permission: %v", err))
+			return
+		}
+		shared, err := h.store.ShareFile(segments[3], permission)
+		writeResult(w, shared, err)
+	default:
+		writeError(w, notFound("path "+r.URL.Path))
+	}
+}
+
+func (h *Handler) serveCalendar(w http.ResponseWriter, r *http.Request, seg []string) {
+	switch {
+	case len(seg) == 1 && seg[0] == "freeBusy" && r.Method == http.MethodPost:
+		req := &calendar.FreeBusyRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, badRequest("invalid free/busy request: %v", err))
+			return
+		}
+		resp, err := h.store.FreeBusy(req)
+		writeResult(w, resp, err)
+
+	case len(seg) == 1 && seg[0] == "colors":
+		writeJSON(w, demoColors())
+
+	case len(seg) == 3 && seg[0] == "users" && seg[1] == "me" && seg[2] == "settings":
+		writeJSON(w, demoSettings())
+
+	case len(seg) == 3 && seg[0] == "users" && seg[1] == "me" && seg[2] == "calendarList":
+		h.serveCalendarList(w, r)
+
+	case len(seg) == 4 && seg[0] == "users" && seg[1] == "me" && seg[2] == "calendarList":
+		h.serveCalendarListEntry(w, r, seg[3])
+
+	case len(seg) == 1 && seg[0] == "calendars" && r.Method == http.MethodPost:
+		cal := &calendar.Calendar{}
+		if err := json.NewDecoder(r.Body).Decode(cal); err != nil {
+			writeError(w, badRequest("invalid calendar: %v", err))
+			return
+		}
+		inserted, err := h.store.InsertCalendar(cal)
+		writeResult(w, inserted, err)
+
+	case len(seg) == 2 && seg[0] == "calendars":
+		cal, err := h.store.Calendar(seg[1])
+		writeResult(w, cal, err)
+
+	case len(seg) == 3 && seg[0] == "calendars" && seg[2] == "events":
+		h.serveEvents(w, r, seg[1])
+
+	case len(seg) == 4 && seg[0] == "calendars" && seg[2] == "events" && seg[3] == "import" && r.Method == http.MethodPost:
+		ev := &calendar.Event{}
+		if err := json.NewDecoder(r.Body).Decode(ev); err != nil {
+			writeError(w, badRequest("invalid event: %v", err))
+			return
+		}
+		imported, err := h.store.ImportEvent(seg[1], ev)
+		writeResult(w, imported, err)
+
+	case len(seg) == 4 && seg[0] == "calendars" && seg[2] == "events":
+		h.serveEvent(w, r, seg[1], seg[3])
+
+	case len(seg) == 5 && seg[0] == "calendars" && seg[2] == "events" && seg[4] == "instances":
+		q := r.URL.Query()
+		events, err := h.store.Instances(seg[1], seg[3], queryTime(q, "timeMin"), queryTime(q, "timeMax"), q.Get("showDeleted") == "true")
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeEventPage(w, r, events)
+
+	default:
+		writeError(w, notFound("path "+r.URL.Path))
+	}
+}
+
+func (h *Handler) serveCalendarList(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, &calendar.CalendarList{Kind: "calendar#calendarList", Items: h.store.CalendarList()})
+
+	case http.MethodPost:
+		entry := &calendar.CalendarListEntry{}
+		if err := json.NewDecoder(r.Body).Decode(entry); err != nil {
+			writeError(w, badRequest("invalid calendar list entry: %v", err))
+			return
+		}
+		inserted, err := h.store.InsertListEntry(entry)
+		writeResult(w, inserted, err)
+
+	default:
+		writeError(w, &apiError{code: http.StatusMethodNotAllowed, reason: "methodNotAllowed", message: "method not allowed"})
+	}
+}
+
+func (h *Handler) serveCalendarListEntry(w http.ResponseWriter, r *http.Request, calendarID string) {
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := h.store.CalendarListEntry(calendarID)
+		writeResult(w, entry, err)
+
+	case http.MethodPatch:
+		patch := map[string]json.RawMessage{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeError(w, badRequest("invalid calendar list entry: %v", err))
+			return
+		}
+		entry, err := h.store.PatchListEntry(calendarID, patch)
+		writeResult(w, entry, err)
+
+	case http.MethodDelete:
+		if err := h.store.DeleteListEntry(calendarID); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, &apiError{code: http.StatusMethodNotAllowed, reason: "methodNotAllowed", message: "method not allowed"})
+	}
+}
+
+func (h *Handler) serveEvents(w http.ResponseWriter, r *http.Request, calendarID string) {
+	q := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		query := EventQuery{
+			TimeMin:      queryTime(q, "timeMin"),
+			TimeMax:      queryTime(q, "timeMax"),
+			UpdatedMin:   queryTime(q, "updatedMin"),
+			SingleEvents: q.Get("singleEvents") == "true",
+			ShowDeleted:  q.Get("showDeleted") == "true",
+			Query:        q.Get("q"),
+			ICalUID:      q.Get("iCalUID"),
+			EventTypes:   q["eventTypes"],
+			PrivateProps: queryProps(q["privateExtendedProperty"]),
+			SharedProps:  queryProps(q["sharedExtendedProperty"]),
+		}
+		query.MaxAttendees, _ = strconv.Atoi(q.Get("maxAttendees"))
+		events, err := h.store.ListEvents(calendarID, query)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeEventPage(w, r, events)
+
+	case http.MethodPost:
+		ev := &calendar.Event{}
+		if err := json.NewDecoder(r.Body).Decode(ev); err != nil {
+			writeError(w, badRequest("invalid event: %v", err))
+			return
+		}
+		version, _ := strconv.Atoi(q.Get("conferenceDataVersion"))
+		created, err := h.store.InsertEvent(calendarID, ev, version)
+		writeResult(w, created, err)
+
+	default:
+		writeError(w, &apiError{code: http.StatusMethodNotAllowed, reason: "methodNotAllowed", message: "method not allowed"})
+	}
+}
+
+func (h *Handler) serveEvent(w http.ResponseWriter, r *http.Request, calendarID, eventID string) {
+	switch r.Method {
+	case http.MethodGet:
+		ev, err := h.store.GetEvent(calendarID, eventID)
+		writeResult(w, ev, err)
+
+	case http.MethodPatch:
+		patch := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeError(w, badRequest("invalid event patch: %v", err))
+			return
+		}
+		version, _ := strconv.Atoi(r.URL.Query().Get("conferenceDataVersion"))
+		ev, err := h.store.PatchEvent(calendarID, eventID, r.Header.Get("If-Match"), patch, version)
+		writeResult(w, ev, err)
+
+	case http.MethodPut:
+		ev := &calendar.Event{}
+		if err := json.NewDecoder(r.Body).Decode(ev); err != nil {
+			writeError(w, badRequest("invalid event: %v", err))
+			return
+		}
+		updated, err := h.store.UpdateEvent(calendarID, eventID, r.Header.Get("If-Match"), ev)
+		writeResult(w, updated, err)
+
+	case http.MethodDelete:
+		if err := h.store.DeleteEvent(calendarID, eventID, r.Header.Get("If-Match")); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, &apiError{code: http.StatusMethodNotAllowed, reason: "methodNotAllowed", message: "method not allowed"})
+	}
+}
+
+// serveExport answers Drive file exports with the content of a file created
+// through the API, or a placeholder document so get_document and
+// get_meeting_context work offline.
+func (h *Handler) serveExport(w http.ResponseWriter, r *http.Request, fileID string) {
+	w.Header().Set("Content-Type", "text/markdown")
+	if _, content, err := h.store.File(fileID); err == nil {
+		_, _ = io.WriteString(w, content)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "# Demo document %s\n\nThis document is served by the fake backend.\n", fileID)
+}
+
+// serveUpload creates a Drive file from a multipart upload (metadata, then
+// content) or a plain media upload.
+func (h *Handler) serveUpload(w http.ResponseWriter, r *http.Request) {
+	meta := &drive.File{}
+	var content []byte
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(r.Body, params["boundary"])
+		part, err := parts.NextPart()
+		if err != nil {
+			writeError(w, badRequest("invalid upload: %v", err))
+			return
+		}
+		if err := json.NewDecoder(part).Decode(meta); err != nil {
+			writeError(w, badRequest("invalid file metadata: %v", err))
+			return
+		}
+		if part, err = parts.NextPart(); err == nil {
+			content, err = io.ReadAll(part)
+		}
+		if err != nil && err != io.EOF {
+			writeError(w, badRequest("invalid upload: %v", err))
+			return
+		}
+	} else if content, err = io.ReadAll(r.Body); err != nil {
+		writeError(w, badRequest("invalid upload: %v", err))
+		return
+	}
+	created, err := h.store.CreateFile(meta, string(content))
+	writeResult(w, created, err)
+}
+
+// writeEventPage writes events as a calendar#events page. pageToken is the
+// offset of the first event and maxResults the page size.
+func writeEventPage(w http.ResponseWriter, r *http.Request, events []*calendar.Event) {
+	q := r.URL.Query()
+	offset, _ := strconv.Atoi(q.Get("pageToken"))
+	if offset > len(events) {
+		offset = len(events)
+	}
+	page := &calendar.Events{Kind: "calendar#events", Items: events[offset:]}
+	if size, err := strconv.Atoi(q.Get("maxResults")); err == nil && size > 0 && len(page.Items) > size {
+		page.Items = page.Items[:size]
+		page.NextPageToken = strconv.Itoa(offset + size)
+	}
+	writeJSON(w, page)
+}
+
+func writeResult(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, v)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err in the Google API error format so googleapi.CheckResponse
+// turns it back into a *googleapi.Error.
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		apiErr = &apiError{code: http.StatusInternalServerError, reason: "backendError", message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    apiErr.code,
+			"message": apiErr.message,
+			"errors": []map[string]string{
+				{"domain": "global", "reason": apiErr.reason, "message": apiErr.message},
+			},
+		},
+	})
+}
+
+// pathSegments splits the request path into unescaped segments, so calendar
+// IDs containing '@' or '#' survive intact.
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for _, part := range strings.Split(strings.Trim(u.EscapedPath(), "/"), "/") {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		segments = append(segments, part)
+	}
+	return segments
+}
+
+func hasPrefix(segments []string, prefix ...string) bool {
+	if len(segments) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if segments[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+func queryTime(q url.Values, key string) time.Time {
+	t, _ := time.Parse(time.RFC3339, q.Get(key))
+	return t
+}
+
+// queryProps parses repeated "key=value" extended property filters.
+func queryProps(values []string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	props := make(map[string]string, len(values))
+	for _, v := range values {
+		if key, value, ok := strings.Cut(v, "="); ok {
+			props[key] = value
+		}
+	}
+	return props
+}
+
+// demoSettings are the user settings read for date formatting and week
+// filters: US English with a 12-hour clock, weeks starting on Sunday.
+func demoSettings() *calendar.Settings {
+	return &calendar.Settings{
+		Kind: "calendar#settings",
+		Items: []*calendar.Setting{
+			{Kind: "calendar#setting", Id: "locale", Value: "en"},
+			{Kind: "calendar#setting", Id: "format24HourTime", Value: "false"},
+			{Kind: "calendar#setting", Id: "timezone", Value: "UTC"},
+			{Kind: "calendar#setting", Id: "weekStart", Value: "0"},
+		},
+	}
+}
+
+func demoColors() *calendar.Colors {
+	return &calendar.Colors{
+		Kind: "calendar#colors",
+		Event: map[string]calendar.ColorDefinition{
+			"1":  {Background: "#a4bdfc", Foreground: "#1d1d1d"},
+			"2":  {Background: "#7ae7bf", Foreground: "#1d1d1d"},
+			"3":  {Background: "#dbadff", Foreground: "#1d1d1d"},
+			"4":  {Background: "#ff887c", Foreground: "#1d1d1d"},
+			"5":  {Background: "#fbd75b", Foreground: "#1d1d1d"},
+			"6":  {Background: "#ffb878", Foreground: "#1d1d1d"},
+			"7":  {Background: "#46d6db", Foreground: "#1d1d1d"},
+			"8":  {Background: "#e1e1e1", Foreground: "#1d1d1d"},
+			"9":  {Background: "#5484ed", Foreground: "#1d1d1d"},
+			"10": {Background: "#51b749", Foreground: "#1d1d1d"},
+			"11": {Background: "#dc2127", Foreground: "#1d1d1d"},
+		},
+	}
+}
@@ -0,0 +1,606 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+const baseURL = "https://www.googleapis.com/calendar/v3"
+
+// do sends a request through the in-process client and decodes the JSON reply.
+func do(t *testing.T, client *http.Client, method, path, body string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, baseURL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func window(start, end time.Time) string {
+	return "timeMin=" + url.QueryEscape(start.Format(time.RFC3339)) + "&timeMax=" + url.QueryEscape(end.Format(time.RFC3339))
+}
+
+// ----- event lifecycle -----
+
+func TestEventLifecycle(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	var created calendar.Event
+	body := `{"summary":"Sync","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T10:30:00Z"},"attendees":[{"email":"me@example.com"},{"email":"bob@example.com"}]}`
+	if code := do(t, client, "POST", "/calendars/primary/events", body, &created); code != 200 {
+		t.Fatalf("insert returned %d", code)
+	}
+	if created.Id == "" || created.Etag == "" || created.HtmlLink == "" {
+		t.Errorf("server-assigned fields missing: %+v", created)
+	}
+	if created.Attendees[1].ResponseStatus != "needsAction" {
+		t.Errorf("expected needsAction for invitee, got %q", created.Attendees[1].ResponseStatus)
+	}
+
+	var patched calendar.Event
+	if code := do(t, client, "PATCH", "/calendars/primary/events/"+created.Id, `{"summary":"Renamed","location":null}`, &patched); code != 200 {
+		t.Fatalf("patch returned %d", code)
+	}
+	if patched.Summary != "Renamed" || patched.Start.DateTime != "2025-03-03T10:00:00Z" {
+		t.Errorf("patch should change only the summary, got %+v", patched)
+	}
+
+	var got calendar.Event
+	do(t, client, "GET", "/calendars/me%40example.com/events/"+created.Id, "", &got)
+	if got.Summary != "Renamed" {
+		t.Errorf("expected persisted summary, got %q", got.Summary)
+	}
+
+	if code := do(t, client, "DELETE", "/calendars/primary/events/"+created.Id, "", nil); code != http.StatusNoContent {
+		t.Errorf("delete returned %d", code)
+	}
+	if code := do(t, client, "GET", "/calendars/primary/events/"+created.Id, "", nil); code != http.StatusNotFound {
+		t.Errorf("get after delete returned %d, want 404", code)
+	}
+}
+
+func TestInsertEvent_Meet(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	var created calendar.Event
+	body := `{"summary":"Call","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T11:00:00Z"},"conferenceData":{"createRequest":{"requestId":"r1","conferenceSolutionKey":{"type":"hangoutsMeet"}}}}`
+	do(t, client, "POST", "/calendars/primary/events?conferenceDataVersion=1", body, &created)
+	if !strings.HasPrefix(created.HangoutLink, "https://meet.google.com/") {
+		t.Errorf("expected Meet link, got %q", created.HangoutLink)
+	}
+}
+
+func TestPatchEvent_Conference(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	var created calendar.Event
+	do(t, client, "POST", "/calendars/primary/events",
+		`{"summary":"Call","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T11:00:00Z"}}`, &created)
+	path := "/calendars/primary/events/" + created.Id
+	addMeet := `{"conferenceData":{"createRequest":{"requestId":"r1","conferenceSolutionKey":{"type":"hangoutsMeet"}}}}`
+
+	// Without conferenceDataVersion=1 conference changes are ignored
+	var patched calendar.Event
+	do(t, client, "PATCH", path, addMeet, &patched)
+	if patched.HangoutLink != "" || patched.ConferenceData != nil {
+		t.Errorf("conference added without conferenceDataVersion: %+v", patched.ConferenceData)
+	}
+
+	patched = calendar.Event{}
+	do(t, client, "PATCH", path+"?conferenceDataVersion=1", addMeet, &patched)
+	if !strings.HasPrefix(patched.HangoutLink, "https://meet.google.com/") {
+		t.Fatalf("expected Meet link, got %q", patched.HangoutLink)
+	}
+	link := patched.HangoutLink
+
+	// A repeated request ID is ignored; a new one replaces the conference
+	patched = calendar.Event{}
+	do(t, client, "PATCH", path+"?conferenceDataVersion=1", addMeet, &patched)
+	if patched.HangoutLink != link {
+		t.Errorf("repeated request changed the link: %q, want %q", patched.HangoutLink, link)
+	}
+	patched = calendar.Event{}
+	do(t, client, "PATCH", path+"?conferenceDataVersion=1", strings.Replace(addMeet, "r1", "r2", 1), &patched)
+	if patched.HangoutLink == "" || patched.HangoutLink == link {
+		t.Errorf("new request did not regenerate the link: %q", patched.HangoutLink)
+	}
+
+	patched = calendar.Event{}
+	do(t, client, "PATCH", path+"?conferenceDataVersion=1", `{"conferenceData":null}`, &patched)
+	if patched.HangoutLink != "" || patched.ConferenceData != nil {
+		t.Errorf("conference not removed: link %q, data %+v", patched.HangoutLink, patched.ConferenceData)
+	}
+}
+
+func TestPatchEvent_ExtendedProperties(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	var created calendar.Event
+	do(t, client, "POST", "/calendars/primary/events",
+		`{"summary":"Review","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T11:00:00Z"},"extendedProperties":{"private":{"tag":"a"},"shared":{"team":"x"}}}`, &created)
+
+	var patched calendar.Event
+	do(t, client, "PATCH", "/calendars/primary/events/"+created.Id, `{"extendedProperties":{"private":{"followupOf":"k1"}}}`, &patched)
+	props := patched.ExtendedProperties
+	if props == nil || props.Private["tag"] != "a" || props.Private["followupOf"] != "k1" || props.Shared["team"] != "x" {
+		t.Errorf("extended properties not merged by key: %+v", props)
+	}
+}
+
+func TestInsertEvent_EmptyRange(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+	body := `{"summary":"Bad","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}}`
+	if code := do(t, client, "POST", "/calendars/primary/events", body, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty time range, got %d", code)
+	}
+}
+
+func TestImportEvent(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	body := `{"iCalUID":"weekly@example.org","summary":"Weekly","organizer":{"email":"alice@example.org"},"start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T11:00:00Z"},"recurrence":["RRULE:FREQ=WEEKLY;COUNT=3"],"attendees":[{"email":"alice@example.org","responseStatus":"accepted"},{"email":"me@example.com","responseStatus":"accepted"}]}`
+	var imported calendar.Event
+	if code := do(t, client, "POST", "/calendars/primary/events/import", body, &imported); code != 200 {
+		t.Fatalf("import returned %d", code)
+	}
+	if imported.ICalUID != "weekly@example.org" || imported.Organizer.Email != "alice@example.org" || imported.Organizer.Self {
+		t.Errorf("iCalUID or organizer not kept: %q / %+v", imported.ICalUID, imported.Organizer)
+	}
+	if imported.Attendees[1].ResponseStatus != "accepted" || !imported.Attendees[1].Self {
+		t.Errorf("attendee response not kept: %+v", imported.Attendees[1])
+	}
+
+	// Importing the same iCalUID again replaces the event
+	var again calendar.Event
+	do(t, client, "POST", "/calendars/primary/events/import", strings.Replace(body, `"Weekly"`, `"Weekly sync"`, 1), &again)
+	if again.Id != imported.Id || again.Summary != "Weekly sync" {
+		t.Errorf("re-import created %q (%q), want an update of %q", again.Id, again.Summary, imported.Id)
+	}
+
+	exception := `{"iCalUID":"weekly@example.org","summary":"Weekly (moved)","originalStartTime":{"dateTime":"2025-03-10T10:00:00Z"},"start":{"dateTime":"2025-03-11T10:00:00Z"},"end":{"dateTime":"2025-03-11T11:00:00Z"}}`
+	var instance calendar.Event
+	if code := do(t, client, "POST", "/calendars/primary/events/import", exception, &instance); code != 200 {
+		t.Fatalf("exception import returned %d", code)
+	}
+	if instance.Id != imported.Id+"_20250310T100000Z" || instance.RecurringEventId != imported.Id {
+		t.Errorf("exception not attached to the series: %q / %q", instance.Id, instance.RecurringEventId)
+	}
+
+	if code := do(t, client, "POST", "/calendars/primary/events/import", `{"summary":"No UID","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T11:00:00Z"}}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 without iCalUID, got %d", code)
+	}
+}
+
+// ----- conditional writes -----
+
+func TestIfMatch(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	var created calendar.Event
+	body := `{"summary":"Sync","start":{"dateTime":"2025-03-03T10:00:00Z"},"end":{"dateTime":"2025-03-03T10:30:00Z"}}`
+	do(t, client, "POST", "/calendars/primary/events", body, &created)
+
+	conditional := func(method, etag, body string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, baseURL+"/calendars/primary/events/"+created.Id, strings.NewReader(body))
+		req.Header.Set("If-Match", etag)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := conditional("PATCH", created.Etag, `{"summary":"Renamed"}`); code != 200 {
+		t.Fatalf("patch with current etag returned %d", code)
+	}
+	// The first patch changed the etag, so the one read at creation is stale
+	if code := conditional("PATCH", created.Etag, `{"summary":"Again"}`); code != http.StatusPreconditionFailed {
+		t.Errorf("patch with stale etag returned %d, want 412", code)
+	}
+	if code := conditional("DELETE", created.Etag, ""); code != http.StatusPreconditionFailed {
+		t.Errorf("delete with stale etag returned %d, want 412", code)
+	}
+
+	var got calendar.Event
+	do(t, client, "GET", "/calendars/primary/events/"+created.Id, "", &got)
+	if got.Summary != "Renamed" {
+		t.Errorf("summary = %q, want Renamed", got.Summary)
+	}
+	if code := conditional("DELETE", got.Etag, ""); code != http.StatusNoContent {
+		t.Errorf("delete with current etag returned %d", code)
+	}
+}
+
+// ----- recurring events -----
+
+func TestRecurringExpansionAndExceptions(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	var series calendar.Event
+	body := `{"id":"standup","summary":"Standup","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T09:15:00Z"},"recurrence":["RRULE:FREQ=DAILY;COUNT=5"]}`
+	do(t, client, "POST", "/calendars/primary/events", body, &series)
+
+	var page calendar.Events
+	w := window(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC))
+	do(t, client, "GET", "/calendars/primary/events?singleEvents=true&"+w, "", &page)
+	if len(page.Items) != 5 {
+		t.Fatalf("expected 5 instances, got %d", len(page.Items))
+	}
+	second := page.Items[1]
+	if second.Id != "standup_20250304T090000Z" || second.RecurringEventId != "standup" {
+		t.Errorf("unexpected instance identity %q / %q", second.Id, second.RecurringEventId)
+	}
+
+	// Modify one instance and cancel another
+	do(t, client, "PATCH", "/calendars/primary/events/"+second.Id, `{"summary":"Standup (moved)"}`, nil)
+	do(t, client, "DELETE", "/calendars/primary/events/standup_20250305T090000Z", "", nil)
+
+	page = calendar.Events{}
+	do(t, client, "GET", "/calendars/primary/events/standup/instances?"+w, "", &page)
+	if len(page.Items) != 4 {
+		t.Fatalf("expected 4 instances after cancelling one, got %d", len(page.Items))
+	}
+	if page.Items[1].Summary != "Standup (moved)" {
+		t.Errorf("expected modified instance, got %q", page.Items[1].Summary)
+	}
+
+	page = calendar.Events{}
+	do(t, client, "GET", "/calendars/primary/events?"+w, "", &page)
+	// Like Google, unexpanded listings return the series master plus its modified instances
+	if len(page.Items) != 2 || page.Items[0].Id != "standup" || page.Items[1].Id != second.Id {
+		t.Errorf("without singleEvents expected the master and one exception, got %d items", len(page.Items))
+	}
+}
+
+// ----- listing -----
+
+func TestListEvents_FiltersAndPaging(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+	for i, summary := range []string{"Alpha", "Beta", "Gamma"} {
+		start := time.Date(2025, 3, 3, 9+i, 0, 0, 0, time.UTC)
+		body := `{"summary":"` + summary + `","start":{"dateTime":"` + start.Format(time.RFC3339) + `"},"end":{"dateTime":"` + start.Add(time.Hour).Format(time.RFC3339) + `"},"extendedProperties":{"private":{"tag":"` + summary + `"}}}`
+		do(t, client, "POST", "/calendars/primary/events", body, nil)
+	}
+	w := window(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	var page calendar.Events
+	do(t, client, "GET", "/calendars/primary/events?q=beta&"+w, "", &page)
+	if len(page.Items) != 1 || page.Items[0].Summary != "Beta" {
+		t.Errorf("q filter: unexpected items %v", page.Items)
+	}
+
+	page = calendar.Events{}
+	do(t, client, "GET", "/calendars/primary/events?privateExtendedProperty=tag%3DGamma&"+w, "", &page)
+	if len(page.Items) != 1 || page.Items[0].Summary != "Gamma" {
+		t.Errorf("extended property filter: unexpected items %v", page.Items)
+	}
+
+	page = calendar.Events{}
+	do(t, client, "GET", "/calendars/primary/events?maxResults=2&"+w, "", &page)
+	if len(page.Items) != 2 || page.NextPageToken == "" {
+		t.Fatalf("expected first page of 2 with a token, got %d (%q)", len(page.Items), page.NextPageToken)
+	}
+	next := calendar.Events{}
+	do(t, client, "GET", "/calendars/primary/events?maxResults=2&pageToken="+page.NextPageToken+"&"+w, "", &next)
+	if len(next.Items) != 1 || next.Items[0].Summary != "Gamma" || next.NextPageToken != "" {
+		t.Errorf("unexpected second page %v (%q)", next.Items, next.NextPageToken)
+	}
+}
+
+func TestListEvents_MaxAttendees(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+	body := `{"summary":"All hands","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"},` +
+		`"attendees":[{"email":"a@example.com"},{"email":"b@example.com"},{"email":"me@example.com"},{"email":"c@example.com"}]}`
+	var created calendar.Event
+	do(t, client, "POST", "/calendars/primary/events", body, &created)
+	w := window(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	var page calendar.Events
+	do(t, client, "GET", "/calendars/primary/events?maxAttendees=2&"+w, "", &page)
+	if len(page.Items) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(page.Items))
+	}
+	ev := page.Items[0]
+	if !ev.AttendeesOmitted || len(ev.Attendees) != 2 {
+		t.Fatalf("expected 2 attendees and attendeesOmitted, got %d (omitted %v)", len(ev.Attendees), ev.AttendeesOmitted)
+	}
+	if ev.Attendees[0].Email != "a@example.com" || !ev.Attendees[1].Self {
+		t.Errorf("expected the first guest and the user, got %s and %s", ev.Attendees[0].Email, ev.Attendees[1].Email)
+	}
+
+	var full calendar.Event
+	do(t, client, "GET", "/calendars/primary/events/"+created.Id, "", &full)
+	if full.AttendeesOmitted || len(full.Attendees) != 4 {
+		t.Errorf("get should return all 4 attendees, got %d (omitted %v)", len(full.Attendees), full.AttendeesOmitted)
+	}
+}
+
+func TestListEvents_FreeBusyReader(t *testing.T) {
+	store := NewStore("me@example.com", "UTC")
+	store.AddCalendar("boss@example.com", "Boss", "UTC")
+	if err := store.SetAccessRole("boss@example.com", "freeBusyReader"); err != nil {
+		t.Fatal(err)
+	}
+	client := NewHTTPClient(store)
+	do(t, client, "POST", "/calendars/boss@example.com/events", `{"summary":"Board meeting","location":"HQ","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}}`, nil)
+
+	var page calendar.Events
+	do(t, client, "GET", "/calendars/boss@example.com/events?"+window(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)), "", &page)
+	if len(page.Items) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(page.Items))
+	}
+	if ev := page.Items[0]; ev.Summary != "" || ev.Location != "" || ev.Start == nil {
+		t.Errorf("expected only the event's times, got %+v", ev)
+	}
+
+	entry := &calendar.CalendarListEntry{}
+	do(t, client, "GET", "/users/me/calendarList/boss@example.com", "", entry)
+	if entry.AccessRole != "freeBusyReader" {
+		t.Errorf("access role = %q, want freeBusyReader", entry.AccessRole)
+	}
+}
+
+func TestListEvents_PrivateEventsForReader(t *testing.T) {
+	store := NewStore("me@example.com", "UTC")
+	store.AddCalendar("boss@example.com", "Boss", "UTC")
+	client := NewHTTPClient(store)
+	var private calendar.Event
+	do(t, client, "POST", "/calendars/boss@example.com/events", `{"summary":"Doctor","visibility":"private","attendees":[{"email":"boss@example.com"}],"start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}}`, &private)
+	do(t, client, "POST", "/calendars/boss@example.com/events", `{"summary":"Staff meeting","start":{"dateTime":"2025-03-03T11:00:00Z"},"end":{"dateTime":"2025-03-03T12:00:00Z"}}`, nil)
+
+	var page calendar.Events
+	do(t, client, "GET", "/calendars/boss@example.com/events?"+window(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)), "", &page)
+	if len(page.Items) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(page.Items))
+	}
+	if ev := page.Items[0]; ev.Summary != "" || len(ev.Attendees) != 0 || ev.Visibility != "private" || ev.Start == nil {
+		t.Errorf("expected only the private event's times, got %+v", ev)
+	}
+	if ev := page.Items[1]; ev.Summary != "Staff meeting" {
+		t.Errorf("public event should keep its details, got %+v", ev)
+	}
+
+	var got calendar.Event
+	do(t, client, "GET", "/calendars/boss@example.com/events/"+private.Id, "", &got)
+	if got.Summary != "" {
+		t.Errorf("Get should hide the private event's details too, got %+v", got)
+	}
+
+	if err := store.SetAccessRole("boss@example.com", "writer"); err != nil {
+		t.Fatal(err)
+	}
+	got = calendar.Event{}
+	do(t, client, "GET", "/calendars/boss@example.com/events/"+private.Id, "", &got)
+	if got.Summary != "Doctor" {
+		t.Errorf("a writer should see the private event's details, got %+v", got)
+	}
+}
+
+// ----- free/busy -----
+
+func TestFreeBusy(t *testing.T) {
+	store := NewStore("me@example.com", "UTC")
+	client := NewHTTPClient(store)
+	for _, body := range []string{
+		`{"summary":"A","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}}`,
+		`{"summary":"B","start":{"dateTime":"2025-03-03T09:30:00Z"},"end":{"dateTime":"2025-03-03T11:00:00Z"}}`,
+		`{"summary":"Free","transparency":"transparent","start":{"dateTime":"2025-03-03T13:00:00Z"},"end":{"dateTime":"2025-03-03T14:00:00Z"}}`,
+	} {
+		do(t, client, "POST", "/calendars/primary/events", body, nil)
+	}
+
+	var resp calendar.FreeBusyResponse
+	req := `{"timeMin":"2025-03-03T00:00:00Z","timeMax":"2025-03-04T00:00:00Z","items":[{"id":"primary"},{"id":"nobody@example.com"}]}`
+	if code := do(t, client, "POST", "/freeBusy", req, &resp); code != 200 {
+		t.Fatalf("freeBusy returned %d", code)
+	}
+	busy := resp.Calendars["primary"].Busy
+	if len(busy) != 1 || busy[0].Start != "2025-03-03T09:00:00Z" || busy[0].End != "2025-03-03T11:00:00Z" {
+		t.Errorf("expected one merged busy block 09:00-11:00, got %+v", busy)
+	}
+	if errs := resp.Calendars["nobody@example.com"].Errors; len(errs) != 1 || errs[0].Reason != "notFound" {
+		t.Errorf("expected notFound error for unknown calendar, got %+v", errs)
+	}
+}
+
+func TestFreeBusyGroups(t *testing.T) {
+	store := NewStore("me@example.com", "UTC")
+	store.AddCalendar("alex@example.com", "Alex", "UTC")
+	store.AddGroup("team@example.com", "me@example.com", "alex@example.com", "hidden@example.com")
+	client := NewHTTPClient(store)
+	do(t, client, "POST", "/calendars/alex@example.com/events",
+		`{"summary":"A","start":{"dateTime":"2025-03-03T09:00:00Z"},"end":{"dateTime":"2025-03-03T10:00:00Z"}}`, nil)
+
+	var resp calendar.FreeBusyResponse
+	req := `{"timeMin":"2025-03-03T00:00:00Z","timeMax":"2025-03-04T00:00:00Z","items":[{"id":"team@example.com"}]}`
+	if code := do(t, client, "POST", "/freeBusy", req, &resp); code != 200 {
+		t.Fatalf("freeBusy returned %d", code)
+	}
+	if got := resp.Groups["team@example.com"].Calendars; len(got) != 3 {
+		t.Errorf("expected 3 expanded members, got %v", got)
+	}
+	if busy := resp.Calendars["alex@example.com"].Busy; len(busy) != 1 {
+		t.Errorf("expected alex to be busy once, got %+v", busy)
+	}
+	if errs := resp.Calendars["hidden@example.com"].Errors; len(errs) != 1 || errs[0].Reason != "notFound" {
+		t.Errorf("expected notFound for hidden member, got %+v", errs)
+	}
+
+	resp = calendar.FreeBusyResponse{}
+	req = `{"timeMin":"2025-03-03T00:00:00Z","timeMax":"2025-03-04T00:00:00Z","groupExpansionMax":2,"items":[{"id":"team@example.com"}]}`
+	do(t, client, "POST", "/freeBusy", req, &resp)
+	if errs := resp.Groups["team@example.com"].Errors; len(errs) != 1 || errs[0].Reason != "groupTooBig" {
+		t.Errorf("expected groupTooBig, got %+v", resp.Groups)
+	}
+}
+
+func TestFreeBusyTooManyCalendars(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	items := make([]string, 51)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id":"user%d@example.com"}`, i)
+	}
+	req := `{"timeMin":"2025-03-03T00:00:00Z","timeMax":"2025-03-04T00:00:00Z","items":[` + strings.Join(items, ",") + `]}`
+	if code := do(t, client, "POST", "/freeBusy", req, nil); code != http.StatusBadRequest {
+		t.Errorf("freeBusy with 51 calendars returned %d, want 400", code)
+	}
+
+	req = `{"timeMin":"2025-03-03T00:00:00Z","timeMax":"2025-03-04T00:00:00Z","calendarExpansionMax":2,"items":[` + strings.Join(items[:3], ",") + `]}`
+	if code := do(t, client, "POST", "/freeBusy", req, nil); code != http.StatusBadRequest {
+		t.Errorf("freeBusy with 3 calendars and calendarExpansionMax 2 returned %d, want 400", code)
+	}
+}
+
+// ----- calendar list -----
+
+func TestCalendarListSubscriptions(t *testing.T) {
+	store := NewStore("me@example.com", "UTC")
+	store.AddCalendar("holidays@example.com", "Holidays", "UTC")
+	client := NewHTTPClient(store)
+
+	if code := do(t, client, "DELETE", "/users/me/calendarList/holidays@example.com", "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete returned %d", code)
+	}
+	list := &calendar.CalendarList{}
+	do(t, client, "GET", "/users/me/calendarList", "", list)
+	if len(list.Items) != 1 || list.Items[0].Id != "me@example.com" {
+		t.Fatalf("unsubscribed calendar still listed: %+v", list.Items)
+	}
+	if code := do(t, client, "GET", "/users/me/calendarList/holidays@example.com", "", nil); code != http.StatusNotFound {
+		t.Errorf("get of unlisted calendar returned %d, want 404", code)
+	}
+
+	entry := &calendar.CalendarListEntry{}
+	if code := do(t, client, "POST", "/users/me/calendarList", `{"id":"holidays@example.com","colorId":"7","hidden":true}`, entry); code != http.StatusOK {
+		t.Fatalf("insert returned %d", code)
+	}
+	if entry.ColorId != "7" || !entry.Hidden || entry.Selected {
+		t.Errorf("unexpected entry settings %+v", entry)
+	}
+
+	if code := do(t, client, "POST", "/users/me/calendarList", `{"id":"missing@example.com"}`, nil); code != http.StatusNotFound {
+		t.Errorf("insert of unknown calendar returned %d, want 404", code)
+	}
+	if code := do(t, client, "DELETE", "/users/me/calendarList/primary", "", nil); code != http.StatusForbidden {
+		t.Errorf("delete of primary calendar returned %d, want 403", code)
+	}
+}
+
+func TestInsertCalendar(t *testing.T) {
+	client := NewHTTPClient(NewStore("me@example.com", "UTC"))
+
+	cal := &calendar.Calendar{}
+	if code := do(t, client, "POST", "/calendars", `{"summary":"Journal","timeZone":"Europe/Berlin"}`, cal); code != http.StatusOK {
+		t.Fatalf("insert returned %d", code)
+	}
+	if !strings.HasSuffix(cal.Id, "@group.calendar.google.com") || cal.TimeZone != "Europe/Berlin" {
+		t.Errorf("unexpected calendar %+v", cal)
+	}
+	entry := &calendar.CalendarListEntry{}
+	if code := do(t, client, "GET", "/users/me/calendarList/"+url.PathEscape(cal.Id), "", entry); code != http.StatusOK {
+		t.Fatalf("new calendar not listed: %d", code)
+	}
+	if entry.AccessRole != "owner" || entry.Summary != "Journal" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if code := do(t, client, "POST", "/calendars", `{}`, nil); code != http.StatusBadRequest {
+		t.Errorf("insert without a title returned %d, want 400", code)
+	}
+}
+
+// ----- Drive files -----
+
+func TestCreateAndShareFile(t *testing.T) {
+	store := NewStore("me@example.com", "UTC")
+	_, driveService, err := NewServices(store, nil)
+	if err != nil {
+		t.Fatalf("NewServices: %v", err)
+	}
+
+	doc, err := driveService.Files.Create(&drive.File{Name: "Agenda", MimeType: "application/vnd.google-apps.document"}).
+		Media(strings.NewReader("1. Intro\n2. Plan"), googleapi.ContentType("text/plain")).
+		Do()
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if doc.Name != "Agenda" || !strings.HasPrefix(doc.WebViewLink, "https://docs.google.com/document/d/"+doc.Id) {
+		t.Errorf("unexpected file %+v", doc)
+	}
+
+	if _, err := driveService.Permissions.Create(doc.Id, &drive.Permission{Type: "user", Role: "reader", EmailAddress: "bob@example.com"}).Do(); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+	if _, err := driveService.Permissions.Create("missing", &drive.Permission{Type: "user", Role: "reader"}).Do(); err == nil {
+		t.Error("sharing a missing file succeeded")
+	}
+	file, _, _ := store.File(doc.Id)
+	if len(file.Permissions) != 1 || file.Permissions[0].EmailAddress != "bob@example.com" {
+		t.Errorf("permissions = %+v", file.Permissions)
+	}
+
+	resp, err := driveService.Files.Export(doc.Id, "text/markdown").Download()
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	defer resp.Body.Close()
+	if content, _ := io.ReadAll(resp.Body); string(content) != "1. Intro\n2. Plan" {
+		t.Errorf("exported %q, want the uploaded text", content)
+	}
+}
+
+// ----- demo data -----
+
+func TestNewDemoStore(t *testing.T) {
+	now := time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+	store := NewDemoStore(now)
+
+	events, err := store.ListEvents("primary", EventQuery{TimeMin: now, TimeMax: now.AddDate(0, 0, 7), SingleEvents: true})
+	if err != nil {
+		t.Fatalf("ListEvents() error: %v", err)
+	}
+	if len(events) == 0 {
+		t.Error("demo store should have upcoming events")
+	}
+	if _, err := store.Calendar(demoColleague); err != nil {
+		t.Errorf("demo colleague calendar missing: %v", err)
+	}
+}
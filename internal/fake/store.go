@@ -0,0 +1,1216 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+// Package fake provides an in-memory Google Calendar backend. It serves the
+// subset of the Calendar v3 and Drive v3 REST APIs used by the server, so the
+// real API client libraries can run against it without credentials.
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// apiError is an error carrying the HTTP status and reason the Google API
+// would return for it.
+type apiError struct {
+	code    int
+	reason  string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func notFound(what string) error {
+	return &apiError{code: http.StatusNotFound, reason: "notFound", message: what + " not found"}
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{code: http.StatusBadRequest, reason: "invalid", message: fmt.Sprintf(format, args...)}
+}
+
+// fakeCalendar holds one calendar's events keyed by event ID. Modified or
+// cancelled instances of recurring events are stored under their instance ID
+// with RecurringEventId set.
+type fakeCalendar struct {
+	id       string
+	summary  string
+	timeZone string
+	events   map[string]*calendar.Event
+
+	// The owner's calendar list entry; calendars start out listed
+	listed          bool
+	hidden          bool
+	selected        bool
+	colorID         string
+	summaryOverride string
+	accessRole      string // the owner's role on another user's calendar; "" means reader
+
+	defaultReminders []*calendar.EventReminder
+	notifications    []*calendar.CalendarNotification
+}
+
+// Store is an in-memory set of calendars. It is safe for concurrent use.
+type Store struct {
+	mu        sync.Mutex
+	owner     string
+	calendars map[string]*fakeCalendar
+	order     []string
+	groups    map[string][]string  // group email -> member emails
+	files     map[string]*fakeFile // Drive files created through the API, by ID
+}
+
+// NewStore creates a store whose primary calendar belongs to owner.
+func NewStore(owner, timeZone string) *Store {
+	s := &Store{
+		owner:     owner,
+		calendars: make(map[string]*fakeCalendar),
+	}
+	s.AddCalendar(owner, owner, timeZone)
+	return s
+}
+
+// Owner returns the email address of the signed-in user.
+func (s *Store) Owner() string {
+	return s.owner
+}
+
+// AddCalendar adds an empty calendar, such as another user's calendar for
+// free/busy lookups. Adding an existing calendar is a no-op.
+func (s *Store) AddCalendar(id, summary, timeZone string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.calendars[id]; ok {
+		return
+	}
+	s.calendars[id] = &fakeCalendar{
+		id:       id,
+		summary:  summary,
+		timeZone: timeZone,
+		events:   make(map[string]*calendar.Event),
+		listed:   true,
+		selected: true,
+	}
+	s.order = append(s.order, id)
+}
+
+// SetAccessRole sets the owner's access role ("owner", "writer", "reader" or
+// "freeBusyReader") on another user's calendar. With freeBusyReader, listing
+// the calendar's events returns only their times, as Google does; a reader
+// sees private events the same way.
+func (s *Store) SetAccessRole(calendarID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return err
+	}
+	cal.accessRole = role
+	return nil
+}
+
+// AddGroup registers a Google Group whose members are expanded by FreeBusy.
+// Members without a calendar in the store are reported as not found.
+func (s *Store) AddGroup(email string, members ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.groups == nil {
+		s.groups = make(map[string][]string)
+	}
+	s.groups[email] = append([]string{}, members...)
+}
+
+// resolveLocked looks up a calendar, treating "primary" as the owner's calendar.
+func (s *Store) resolveLocked(calendarID string) (*fakeCalendar, error) {
+	if calendarID == "primary" {
+		calendarID = s.owner
+	}
+	cal, ok := s.calendars[calendarID]
+	if !ok {
+		return nil, notFound("calendar " + calendarID)
+	}
+	return cal, nil
+}
+
+// Calendar returns the metadata of a calendar.
+func (s *Store) Calendar(calendarID string) (*calendar.Calendar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	return &calendar.Calendar{
+		Kind:     "calendar#calendar",
+		Id:       cal.id,
+		Summary:  cal.summary,
+		TimeZone: cal.timeZone,
+	}, nil
+}
+
+// InsertCalendar creates a secondary calendar owned by the owner and adds it
+// to their calendar list, as the API does.
+func (s *Store) InsertCalendar(cal *calendar.Calendar) (*calendar.Calendar, error) {
+	if cal.Summary == "" {
+		return nil, badRequest("Missing title.")
+	}
+	timeZone := cal.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	id := randomString("0123456789abcdefghijklmnopqrstuv", 26) + "@group.calendar.google.com"
+	s.AddCalendar(id, cal.Summary, timeZone)
+	if err := s.SetAccessRole(id, "owner"); err != nil {
+		return nil, err
+	}
+	return s.Calendar(id)
+}
+
+// CalendarList returns every calendar in the owner's calendar list, primary first.
+func (s *Store) CalendarList() []*calendar.CalendarListEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]*calendar.CalendarListEntry, 0, len(s.order))
+	for _, id := range s.order {
+		if cal := s.calendars[id]; cal.listed {
+			entries = append(entries, s.listEntryLocked(cal))
+		}
+	}
+	return entries
+}
+
+// CalendarListEntry returns the calendar list entry of one calendar, including
+// the owner's access role.
+func (s *Store) CalendarListEntry(calendarID string) (*calendar.CalendarListEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	if !cal.listed {
+		return nil, notFound("calendar list entry " + calendarID)
+	}
+	return s.listEntryLocked(cal), nil
+}
+
+// InsertListEntry adds an existing calendar to the owner's calendar list with
+// the entry's display settings. Inserting a listed calendar updates them.
+func (s *Store) InsertListEntry(entry *calendar.CalendarListEntry) (*calendar.CalendarListEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(entry.Id)
+	if err != nil {
+		return nil, err
+	}
+	cal.listed = true
+	cal.hidden = entry.Hidden
+	cal.selected = entry.Selected
+	cal.colorID = entry.ColorId
+	cal.summaryOverride = entry.SummaryOverride
+	return s.listEntryLocked(cal), nil
+}
+
+// PatchListEntry changes the fields present in patch on the owner's calendar
+// list entry for calendarID. The calendar must be in the list.
+func (s *Store) PatchListEntry(calendarID string, patch map[string]json.RawMessage) (*calendar.CalendarListEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	if !cal.listed {
+		return nil, notFound("calendar list entry " + calendarID)
+	}
+
+	raw, _ := json.Marshal(patch)
+	entry := &calendar.CalendarListEntry{}
+	if err := json.Unmarshal(raw, entry); err != nil {
+		return nil, badRequest("invalid calendar list entry: %v", err)
+	}
+	for _, n := range notificationsOf(entry) {
+		if n.Method != "email" {
+			return nil, badRequest("invalid notification method %q", n.Method)
+		}
+	}
+	if _, ok := patch["hidden"]; ok {
+		cal.hidden = entry.Hidden
+	}
+	if _, ok := patch["selected"]; ok {
+		cal.selected = entry.Selected
+	}
+	if _, ok := patch["colorId"]; ok {
+		cal.colorID = entry.ColorId
+	}
+	if _, ok := patch["summaryOverride"]; ok {
+		cal.summaryOverride = entry.SummaryOverride
+	}
+	if _, ok := patch["defaultReminders"]; ok {
+		cal.defaultReminders = entry.DefaultReminders
+	}
+	if _, ok := patch["notificationSettings"]; ok {
+		cal.notifications = notificationsOf(entry)
+	}
+	return s.listEntryLocked(cal), nil
+}
+
+func notificationsOf(entry *calendar.CalendarListEntry) []*calendar.CalendarNotification {
+	if entry.NotificationSettings == nil {
+		return nil
+	}
+	return entry.NotificationSettings.Notifications
+}
+
+// DeleteListEntry removes a calendar from the owner's calendar list. Its
+// events are kept. The primary calendar cannot be removed.
+func (s *Store) DeleteListEntry(calendarID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return err
+	}
+	if !cal.listed {
+		return notFound("calendar list entry " + calendarID)
+	}
+	if cal.id == s.owner {
+		return &apiError{code: http.StatusForbidden, reason: "cannotRemovePrimaryCalendar", message: "the primary calendar cannot be removed from the calendar list"}
+	}
+	cal.listed = false
+	return nil
+}
+
+// listEntryLocked describes cal as seen by the owner: their own calendar is
+// owned, every other calendar is read-only unless SetAccessRole says otherwise.
+func (s *Store) listEntryLocked(cal *fakeCalendar) *calendar.CalendarListEntry {
+	role := "reader"
+	if cal.accessRole != "" {
+		role = cal.accessRole
+	}
+	if cal.id == s.owner {
+		role = "owner"
+	}
+	entry := &calendar.CalendarListEntry{
+		Kind:       "calendar#calendarListEntry",
+		Id:         cal.id,
+		Summary:    cal.summary,
+		TimeZone:   cal.timeZone,
+		AccessRole: role,
+		Primary:    cal.id == s.owner,
+
+		Hidden:          cal.hidden,
+		Selected:        cal.selected,
+		ColorId:         cal.colorID,
+		SummaryOverride: cal.summaryOverride,
+
+		DefaultReminders: cal.defaultReminders,
+	}
+	if len(cal.notifications) > 0 {
+		entry.NotificationSettings = &calendar.CalendarListEntryNotificationSettings{Notifications: cal.notifications}
+	}
+	return entry
+}
+
+// InsertEvent adds an event, filling in the server-assigned fields. A Meet link
+// is generated when conferenceDataVersion is 1 and a create request is present.
+func (s *Store) InsertEvent(calendarID string, ev *calendar.Event, conferenceDataVersion int) (*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := eventTimes(ev); err != nil {
+		return nil, err
+	}
+	if ev.Recurrence != nil {
+		if _, err := parseRecurrence(ev.Recurrence); err != nil {
+			return nil, badRequest("%v", err)
+		}
+	}
+
+	if ev.Id == "" {
+		ev.Id = newEventID()
+	}
+	if _, exists := cal.events[ev.Id]; exists {
+		return nil, &apiError{code: http.StatusConflict, reason: "duplicate", message: "The requested identifier already exists."}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	ev.Kind = "calendar#event"
+	ev.Created = now
+	ev.ICalUID = ev.Id + "@google.com"
+	ev.HtmlLink = "https://calendar.google.com/calendar/event?eid=" + ev.Id
+	if ev.Status == "" {
+		ev.Status = "confirmed"
+	}
+	if ev.EventType == "" {
+		ev.EventType = "default"
+	}
+	if ev.Organizer == nil {
+		ev.Organizer = &calendar.EventOrganizer{Email: cal.id, Self: cal.id == s.owner}
+	}
+	ev.Creator = &calendar.EventCreator{Email: s.owner, Self: true}
+	for _, a := range ev.Attendees {
+		a.Self = strings.EqualFold(a.Email, s.owner)
+		a.Organizer = strings.EqualFold(a.Email, ev.Organizer.Email)
+		if a.ResponseStatus == "" {
+			a.ResponseStatus = "needsAction"
+			if a.Organizer {
+				a.ResponseStatus = "accepted"
+			}
+		}
+	}
+	if conferenceDataVersion >= 1 && ev.ConferenceData != nil && ev.ConferenceData.CreateRequest != nil {
+		addMeetConference(ev)
+	}
+	touch(ev)
+
+	cal.events[ev.Id] = clone(ev)
+	return ev, nil
+}
+
+// ImportEvent adds a private copy of an event under its iCalUID, as Google's
+// events.import does: the organizer, attendees' responses and sequence are
+// kept as given. An event already imported with the same iCalUID is replaced,
+// and an event with originalStartTime becomes an exception of the recurring
+// event with that iCalUID.
+func (s *Store) ImportEvent(calendarID string, ev *calendar.Event) (*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	if ev.ICalUID == "" {
+		return nil, badRequest("Missing iCalUID.")
+	}
+	if _, _, _, err := eventTimes(ev); err != nil {
+		return nil, err
+	}
+	if ev.Recurrence != nil {
+		if _, err := parseRecurrence(ev.Recurrence); err != nil {
+			return nil, badRequest("%v", err)
+		}
+	}
+
+	var master *calendar.Event
+	for _, other := range cal.events {
+		if other.ICalUID == ev.ICalUID && other.RecurringEventId == "" {
+			master = other
+			break
+		}
+	}
+	ev.RecurringEventId = ""
+	switch {
+	case ev.OriginalStartTime != nil:
+		if master == nil || master.Recurrence == nil {
+			return nil, notFound("recurring event " + ev.ICalUID)
+		}
+		start, allDay, err := parseEventTime(ev.OriginalStartTime)
+		if err != nil {
+			return nil, err
+		}
+		ev.Id = instanceID(master.Id, start, allDay)
+		ev.RecurringEventId = master.Id
+		ev.Created = master.Created
+	case master != nil:
+		ev.Id = master.Id
+		ev.Created = master.Created
+	default:
+		ev.Id = newEventID()
+		ev.Created = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	ev.Kind = "calendar#event"
+	ev.HtmlLink = "https://calendar.google.com/calendar/event?eid=" + ev.Id
+	if ev.Status == "" {
+		ev.Status = "confirmed"
+	}
+	if ev.EventType == "" {
+		ev.EventType = "default"
+	}
+	if ev.Organizer == nil {
+		ev.Organizer = &calendar.EventOrganizer{Email: cal.id, Self: cal.id == s.owner}
+	}
+	ev.Organizer.Self = strings.EqualFold(ev.Organizer.Email, s.owner)
+	ev.Creator = &calendar.EventCreator{Email: s.owner, Self: true}
+	for _, a := range ev.Attendees {
+		a.Self = strings.EqualFold(a.Email, s.owner)
+		a.Organizer = strings.EqualFold(a.Email, ev.Organizer.Email)
+		if a.ResponseStatus == "" {
+			a.ResponseStatus = "needsAction"
+		}
+	}
+	touch(ev)
+
+	cal.events[ev.Id] = clone(ev)
+	return ev, nil
+}
+
+// GetEvent returns an event or a single instance of a recurring event.
+func (s *Store) GetEvent(calendarID, eventID string) (*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	ev, err := s.lookupLocked(cal, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return s.hidePrivateLocked(cal, clone(ev)), nil
+}
+
+// lookupLocked finds a stored event, or synthesizes an unmodified instance of a
+// recurring event from its instance ID.
+func (s *Store) lookupLocked(cal *fakeCalendar, eventID string) (*calendar.Event, error) {
+	if ev, ok := cal.events[eventID]; ok {
+		return ev, nil
+	}
+	master, start, ok := splitInstanceID(cal, eventID)
+	if !ok {
+		return nil, notFound("event " + eventID)
+	}
+	return newInstance(master, start), nil
+}
+
+// PatchEvent applies a JSON merge patch to an event. Fields set to null in the
+// patch are cleared. Patching an unmodified instance stores it as an exception.
+// A non-empty ifMatch must equal the event's etag. As with Google, conference
+// data is only changed when conferenceDataVersion is 1: a null value removes
+// the conference and its Meet link, and a create request adds a Meet link.
+func (s *Store) PatchEvent(calendarID, eventID, ifMatch string, patch map[string]interface{}, conferenceDataVersion int) (*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.lookupLocked(cal, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(existing, ifMatch); err != nil {
+		return nil, err
+	}
+
+	conference, changesConference := patch["conferenceData"]
+	if conferenceDataVersion < 1 {
+		delete(patch, "conferenceData")
+		changesConference = false
+	} else if changesConference && conference == nil {
+		patch["hangoutLink"] = nil
+	}
+
+	merged := map[string]interface{}{}
+	raw, _ := json.Marshal(existing)
+	_ = json.Unmarshal(raw, &merged)
+	for k, v := range patch {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		if k == "extendedProperties" {
+			v = mergeExtendedProperties(merged[k], v)
+		}
+		merged[k] = v
+	}
+
+	updated := &calendar.Event{}
+	raw, _ = json.Marshal(merged)
+	if err := json.Unmarshal(raw, updated); err != nil {
+		return nil, badRequest("invalid event patch: %v", err)
+	}
+	if changesConference && updated.ConferenceData != nil && updated.ConferenceData.CreateRequest != nil && len(updated.ConferenceData.EntryPoints) == 0 {
+		if sameConferenceRequest(existing, updated) {
+			// The API ignores a create request it has already seen
+			updated.ConferenceData = existing.ConferenceData
+			updated.HangoutLink = existing.HangoutLink
+		} else {
+			addMeetConference(updated)
+		}
+	}
+	return s.storeUpdateLocked(cal, existing, updated)
+}
+
+// UpdateEvent replaces an event with ev, keeping its server-assigned fields.
+// A non-empty ifMatch must equal the event's etag.
+func (s *Store) UpdateEvent(calendarID, eventID, ifMatch string, ev *calendar.Event) (*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.lookupLocked(cal, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(existing, ifMatch); err != nil {
+		return nil, err
+	}
+	return s.storeUpdateLocked(cal, existing, ev)
+}
+
+func (s *Store) storeUpdateLocked(cal *fakeCalendar, existing, updated *calendar.Event) (*calendar.Event, error) {
+	if _, _, _, err := eventTimes(updated); err != nil {
+		return nil, err
+	}
+	updated.Id = existing.Id
+	updated.Kind = existing.Kind
+	updated.Created = existing.Created
+	updated.ICalUID = existing.ICalUID
+	updated.HtmlLink = existing.HtmlLink
+	updated.Creator = existing.Creator
+	updated.RecurringEventId = existing.RecurringEventId
+	updated.OriginalStartTime = existing.OriginalStartTime
+	updated.Sequence = existing.Sequence + 1
+	if updated.Organizer == nil {
+		updated.Organizer = existing.Organizer
+	}
+	if updated.Status == "" {
+		updated.Status = "confirmed"
+	}
+	touch(updated)
+
+	cal.events[updated.Id] = clone(updated)
+	return updated, nil
+}
+
+// DeleteEvent removes an event. Deleting a recurring series removes all of its
+// exceptions; deleting a single instance records it as cancelled. A non-empty
+// ifMatch must equal the event's etag.
+func (s *Store) DeleteEvent(calendarID, eventID, ifMatch string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return err
+	}
+	ev, err := s.lookupLocked(cal, eventID)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(ev, ifMatch); err != nil {
+		return err
+	}
+	if ev.Status == "cancelled" {
+		return &apiError{code: http.StatusGone, reason: "deleted", message: "Resource has been deleted"}
+	}
+
+	if ev.RecurringEventId != "" {
+		cancelled := clone(ev)
+		cancelled.Status = "cancelled"
+		touch(cancelled)
+		cal.events[cancelled.Id] = cancelled
+		return nil
+	}
+
+	delete(cal.events, eventID)
+	for id, other := range cal.events {
+		if other.RecurringEventId == eventID {
+			delete(cal.events, id)
+		}
+	}
+	return nil
+}
+
+// EventQuery holds the filters supported by ListEvents.
+type EventQuery struct {
+	TimeMin      time.Time
+	TimeMax      time.Time
+	SingleEvents bool
+	ShowDeleted  bool
+	Query        string
+	PrivateProps map[string]string
+	SharedProps  map[string]string
+	EventTypes   []string
+	ICalUID      string
+	UpdatedMin   time.Time
+	MaxAttendees int
+}
+
+// ListEvents returns the events of a calendar matching q, ordered by start time.
+func (s *Store) ListEvents(calendarID string, q EventQuery) ([]*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	events := s.listLocked(cal, q)
+	for i, ev := range events {
+		if cal.accessRole == "freeBusyReader" {
+			events[i] = &calendar.Event{Kind: ev.Kind, Id: ev.Id, Status: ev.Status, Start: ev.Start, End: ev.End, Transparency: ev.Transparency}
+			continue
+		}
+		events[i] = s.hidePrivateLocked(cal, ev)
+	}
+	return events, nil
+}
+
+// hidePrivateLocked strips a private event on another user's calendar down to
+// its times when the owner only has reader access, as Google does.
+func (s *Store) hidePrivateLocked(cal *fakeCalendar, ev *calendar.Event) *calendar.Event {
+	if cal.id == s.owner || (cal.accessRole != "" && cal.accessRole != "reader") {
+		return ev
+	}
+	if ev.Visibility != "private" && ev.Visibility != "confidential" {
+		return ev
+	}
+	return &calendar.Event{Kind: ev.Kind, Id: ev.Id, Etag: ev.Etag, Status: ev.Status, Start: ev.Start, End: ev.End, Transparency: ev.Transparency, Visibility: ev.Visibility, RecurringEventId: ev.RecurringEventId, OriginalStartTime: ev.OriginalStartTime}
+}
+
+func (s *Store) listLocked(cal *fakeCalendar, q EventQuery) []*calendar.Event {
+	var candidates []*calendar.Event
+	for _, ev := range cal.events {
+		if ev.Recurrence == nil {
+			// Exceptions are emitted by their series when expanding
+			if q.SingleEvents && ev.RecurringEventId != "" {
+				continue
+			}
+			candidates = append(candidates, ev)
+			continue
+		}
+		if !q.SingleEvents {
+			candidates = append(candidates, ev)
+			continue
+		}
+		candidates = append(candidates, expand(cal, ev, q.TimeMin, q.TimeMax)...)
+	}
+
+	var result []*calendar.Event
+	for _, ev := range candidates {
+		if matchesQuery(ev, q) {
+			ev = clone(ev)
+			limitAttendees(ev, q.MaxAttendees)
+			result = append(result, ev)
+		}
+	}
+	sortByStart(result)
+	return result
+}
+
+// limitAttendees keeps at most max attendees, always including the user, and
+// marks the list as truncated, as Google does for maxAttendees.
+func limitAttendees(ev *calendar.Event, max int) {
+	if max <= 0 || len(ev.Attendees) <= max {
+		return
+	}
+	room := max
+	for _, a := range ev.Attendees {
+		if a.Self {
+			room--
+		}
+	}
+	var kept []*calendar.EventAttendee
+	for _, a := range ev.Attendees {
+		if a.Self {
+			kept = append(kept, a)
+		} else if room > 0 {
+			kept = append(kept, a)
+			room--
+		}
+	}
+	ev.Attendees = kept
+	ev.AttendeesOmitted = true
+}
+
+// Instances returns the instances of a recurring event within the window.
+func (s *Store) Instances(calendarID, eventID string, timeMin, timeMax time.Time, showDeleted bool) ([]*calendar.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	master, ok := cal.events[eventID]
+	if !ok {
+		return nil, notFound("event " + eventID)
+	}
+	if master.Recurrence == nil {
+		return []*calendar.Event{clone(master)}, nil
+	}
+
+	q := EventQuery{TimeMin: timeMin, TimeMax: timeMax, ShowDeleted: showDeleted, SingleEvents: true}
+	var result []*calendar.Event
+	for _, inst := range expand(cal, master, timeMin, timeMax) {
+		if matchesQuery(inst, q) {
+			result = append(result, clone(inst))
+		}
+	}
+	sortByStart(result)
+	return result, nil
+}
+
+// maxFreeBusyCalendars is the most calendars a free/busy query may name.
+const maxFreeBusyCalendars = 50
+
+// FreeBusy returns the merged busy periods of each requested calendar.
+// Transparent, cancelled and declined events do not count as busy.
+func (s *Store) FreeBusy(req *calendar.FreeBusyRequest) (*calendar.FreeBusyResponse, error) {
+	timeMin, err := time.Parse(time.RFC3339, req.TimeMin)
+	if err != nil {
+		return nil, badRequest("invalid timeMin %q", req.TimeMin)
+	}
+	timeMax, err := time.Parse(time.RFC3339, req.TimeMax)
+	if err != nil {
+		return nil, badRequest("invalid timeMax %q", req.TimeMax)
+	}
+	// Like Google, refuse queries naming more calendars than the limit
+	limit := int64(maxFreeBusyCalendars)
+	if req.CalendarExpansionMax > 0 && req.CalendarExpansionMax < limit {
+		limit = req.CalendarExpansionMax
+	}
+	if int64(len(req.Items)) > limit {
+		return nil, badRequest("too many calendars requested: %d (at most %d)", len(req.Items), limit)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &calendar.FreeBusyResponse{
+		Kind:      "calendar#freeBusy",
+		TimeMin:   req.TimeMin,
+		TimeMax:   req.TimeMax,
+		Calendars: make(map[string]calendar.FreeBusyCalendar),
+	}
+	for _, item := range req.Items {
+		members, isGroup := s.groups[item.Id]
+		if !isGroup {
+			resp.Calendars[item.Id] = s.busyLocked(item.Id, timeMin, timeMax)
+			continue
+		}
+
+		// Like Google, refuse to partially expand a group larger than the limit
+		if resp.Groups == nil {
+			resp.Groups = make(map[string]calendar.FreeBusyGroup)
+		}
+		if req.GroupExpansionMax > 0 && int64(len(members)) > req.GroupExpansionMax {
+			resp.Groups[item.Id] = calendar.FreeBusyGroup{
+				Errors: []*calendar.Error{{Domain: "global", Reason: "groupTooBig"}},
+			}
+			continue
+		}
+		resp.Groups[item.Id] = calendar.FreeBusyGroup{Calendars: append([]string{}, members...)}
+		for _, member := range members {
+			resp.Calendars[member] = s.busyLocked(member, timeMin, timeMax)
+		}
+	}
+	return resp, nil
+}
+
+// busyLocked returns the merged busy periods of one calendar, or a notFound
+// error entry if the calendar does not exist.
+func (s *Store) busyLocked(calendarID string, timeMin, timeMax time.Time) calendar.FreeBusyCalendar {
+	cal, err := s.resolveLocked(calendarID)
+	if err != nil {
+		return calendar.FreeBusyCalendar{
+			Errors: []*calendar.Error{{Domain: "global", Reason: "notFound"}},
+		}
+	}
+
+	var periods [][2]time.Time
+	for _, ev := range s.listLocked(cal, EventQuery{TimeMin: timeMin, TimeMax: timeMax, SingleEvents: true}) {
+		if ev.Transparency == "transparent" || ev.EventType == "workingLocation" || declinedBy(ev, cal.id) {
+			continue
+		}
+		start, end, _, err := eventTimes(ev)
+		if err != nil {
+			continue
+		}
+		if start.Before(timeMin) {
+			start = timeMin
+		}
+		if end.After(timeMax) {
+			end = timeMax
+		}
+		periods = append(periods, [2]time.Time{start, end})
+	}
+
+	busy := []*calendar.TimePeriod{}
+	for _, p := range mergePeriods(periods) {
+		busy = append(busy, &calendar.TimePeriod{
+			Start: p[0].UTC().Format(time.RFC3339),
+			End:   p[1].UTC().Format(time.RFC3339),
+		})
+	}
+	return calendar.FreeBusyCalendar{Busy: busy}
+}
+
+// expand returns the instances of a recurring event overlapping the window,
+// substituting stored exceptions for the generated instances they modify.
+func expand(cal *fakeCalendar, master *calendar.Event, windowStart, windowEnd time.Time) []*calendar.Event {
+	rule, err := parseRecurrence(master.Recurrence)
+	if err != nil || rule == nil {
+		return nil
+	}
+	start, end, _, err := eventTimes(master)
+	if err != nil {
+		return nil
+	}
+
+	// Include instances that started before the window but are still running
+	if !windowStart.IsZero() {
+		windowStart = windowStart.Add(-end.Sub(start))
+	}
+
+	var instances []*calendar.Event
+	for _, t := range rule.occurrences(start, windowStart, windowEnd) {
+		inst := newInstance(master, t)
+		if exception, ok := cal.events[inst.Id]; ok {
+			inst = exception
+		}
+		instances = append(instances, inst)
+	}
+	return instances
+}
+
+// newInstance builds the unmodified instance of master starting at start.
+func newInstance(master *calendar.Event, start time.Time) *calendar.Event {
+	masterStart, masterEnd, allDay, _ := eventTimes(master)
+	inst := clone(master)
+	inst.Id = instanceID(master.Id, start, allDay)
+	inst.Recurrence = nil
+	inst.RecurringEventId = master.Id
+	inst.Start = formatEventTime(start, allDay, master.Start.TimeZone)
+	inst.End = formatEventTime(start.Add(masterEnd.Sub(masterStart)), allDay, master.End.TimeZone)
+	inst.OriginalStartTime = formatEventTime(start, allDay, master.Start.TimeZone)
+	return inst
+}
+
+// instanceID formats a recurring instance ID the way Google does: the series
+// ID, an underscore and the original start (UTC time, or date for all-day).
+func instanceID(seriesID string, start time.Time, allDay bool) string {
+	if allDay {
+		return seriesID + "_" + start.Format("20060102")
+	}
+	return seriesID + "_" + start.UTC().Format("20060102T150405Z")
+}
+
+// splitInstanceID resolves an instance ID to its recurring master and start time.
+func splitInstanceID(cal *fakeCalendar, eventID string) (*calendar.Event, time.Time, bool) {
+	i := strings.LastIndex(eventID, "_")
+	if i < 0 {
+		return nil, time.Time{}, false
+	}
+	master, ok := cal.events[eventID[:i]]
+	if !ok || master.Recurrence == nil {
+		return nil, time.Time{}, false
+	}
+
+	masterStart, _, allDay, err := eventTimes(master)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var start time.Time
+	if allDay {
+		start, err = time.Parse("20060102", eventID[i+1:])
+	} else {
+		start, err = time.Parse("20060102T150405Z", eventID[i+1:])
+		start = start.In(masterStart.Location())
+	}
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return master, start, true
+}
+
+// eventTimes parses an event's start and end. All-day dates are taken as UTC
+// midnight; timed events use their time zone when one is set.
+func eventTimes(ev *calendar.Event) (time.Time, time.Time, bool, error) {
+	if ev.Start == nil || ev.End == nil {
+		return time.Time{}, time.Time{}, false, badRequest("Missing start or end time.")
+	}
+	start, allDay, err := parseEventTime(ev.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	end, _, err := parseEventTime(ev.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, false, badRequest("The specified time range is empty.")
+	}
+	return start, end, allDay, nil
+}
+
+func parseEventTime(dt *calendar.EventDateTime) (time.Time, bool, error) {
+	if dt.Date != "" {
+		t, err := time.Parse("2006-01-02", dt.Date)
+		if err != nil {
+			return time.Time{}, false, badRequest("invalid date %q", dt.Date)
+		}
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, dt.DateTime)
+	if err != nil {
+		return time.Time{}, false, badRequest("invalid dateTime %q", dt.DateTime)
+	}
+	if dt.TimeZone != "" {
+		if loc, err := time.LoadLocation(dt.TimeZone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	return t, false, nil
+}
+
+func formatEventTime(t time.Time, allDay bool, timeZone string) *calendar.EventDateTime {
+	if allDay {
+		return &calendar.EventDateTime{Date: t.Format("2006-01-02")}
+	}
+	return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339), TimeZone: timeZone}
+}
+
+func matchesQuery(ev *calendar.Event, q EventQuery) bool {
+	if ev.Status == "cancelled" && !q.ShowDeleted {
+		return false
+	}
+	start, end, _, err := eventTimes(ev)
+	if err != nil {
+		return false
+	}
+	// Unexpanded series are listed if they start before the window ends
+	if !q.TimeMin.IsZero() && !end.After(q.TimeMin) && ev.Recurrence == nil {
+		return false
+	}
+	if !q.TimeMax.IsZero() && !start.Before(q.TimeMax) {
+		return false
+	}
+	if q.ICalUID != "" && ev.ICalUID != q.ICalUID {
+		return false
+	}
+	if !q.UpdatedMin.IsZero() {
+		if updated, err := time.Parse(time.RFC3339Nano, ev.Updated); err == nil && updated.Before(q.UpdatedMin) {
+			return false
+		}
+	}
+	if len(q.EventTypes) > 0 && !containsString(q.EventTypes, ev.EventType) {
+		return false
+	}
+	if !matchesProps(ev, q.PrivateProps, false) || !matchesProps(ev, q.SharedProps, true) {
+		return false
+	}
+	if q.Query != "" && !matchesText(ev, q.Query) {
+		return false
+	}
+	return true
+}
+
+func matchesProps(ev *calendar.Event, want map[string]string, shared bool) bool {
+	if len(want) == 0 {
+		return true
+	}
+	if ev.ExtendedProperties == nil {
+		return false
+	}
+	props := ev.ExtendedProperties.Private
+	if shared {
+		props = ev.ExtendedProperties.Shared
+	}
+	for k, v := range want {
+		if props[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesText(ev *calendar.Event, query string) bool {
+	query = strings.ToLower(query)
+	fields := []string{ev.Summary, ev.Description, ev.Location}
+	for _, a := range ev.Attendees {
+		fields = append(fields, a.Email, a.DisplayName)
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func declinedBy(ev *calendar.Event, email string) bool {
+	for _, a := range ev.Attendees {
+		if strings.EqualFold(a.Email, email) {
+			return a.ResponseStatus == "declined"
+		}
+	}
+	return false
+}
+
+// mergePeriods sorts periods and merges the ones that overlap or touch.
+func mergePeriods(periods [][2]time.Time) [][2]time.Time {
+	sort.Slice(periods, func(i, j int) bool { return periods[i][0].Before(periods[j][0]) })
+	var merged [][2]time.Time
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p[0].After(merged[n-1][1]) {
+			if p[1].After(merged[n-1][1]) {
+				merged[n-1][1] = p[1]
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+func sortByStart(events []*calendar.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		si, _, _, _ := eventTimes(events[i])
+		sj, _, _, _ := eventTimes(events[j])
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return events[i].Id < events[j].Id
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// addMeetConference fills in the conference data Google returns for a
+// successful hangoutsMeet create request.
+func addMeetConference(ev *calendar.Event) {
+	code := meetCode()
+	link := "https://meet.google.com/" + code
+	ev.HangoutLink = link
+	ev.ConferenceData.ConferenceId = code
+	ev.ConferenceData.CreateRequest.Status = &calendar.ConferenceRequestStatus{StatusCode: "success"}
+	ev.ConferenceData.ConferenceSolution = &calendar.ConferenceSolution{
+		Key:  &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"},
+		Name: "Google Meet",
+	}
+	ev.ConferenceData.EntryPoints = []*calendar.EntryPoint{
+		{EntryPointType: "video", Uri: link, Label: "meet.google.com/" + code},
+	}
+}
+
+// sameConferenceRequest reports whether updated repeats the create request
+// that made existing's conference.
+func sameConferenceRequest(existing, updated *calendar.Event) bool {
+	if existing.ConferenceData == nil || existing.ConferenceData.CreateRequest == nil {
+		return false
+	}
+	return existing.ConferenceData.CreateRequest.RequestId == updated.ConferenceData.CreateRequest.RequestId
+}
+
+// checkIfMatch fails with 412 Precondition Failed, as the API does, when an
+// If-Match etag is given and the event has since changed.
+func checkIfMatch(ev *calendar.Event, ifMatch string) error {
+	if ifMatch == "" || ifMatch == "*" || ifMatch == ev.Etag {
+		return nil
+	}
+	return &apiError{code: http.StatusPreconditionFailed, reason: "conditionNotMet", message: "Precondition Failed"}
+}
+
+// touch bumps an event's updated timestamp and etag after a write.
+func touch(ev *calendar.Event) {
+	now := time.Now().UTC()
+	ev.Updated = now.Format(time.RFC3339Nano)
+	ev.Etag = fmt.Sprintf("\"%d\"", now.UnixNano())
+}
+
+// clone deep-copies an event so callers cannot mutate stored state.
+func clone(ev *calendar.Event) *calendar.Event {
+	raw, _ := json.Marshal(ev)
+	out := &calendar.Event{}
+	_ = json.Unmarshal(raw, out)
+	return out
+}
+
+// newEventID returns a random ID in the base32hex alphabet Google requires.
+func newEventID() string {
+	return randomString("0123456789abcdefghijklmnopqrstuv", 26)
+}
+
+// meetCode returns a random Meet code of the form abc-defg-hij.
+func meetCode() string {
+	letters := "abcdefghijklmnopqrstuvwxyz"
+	return randomString(letters, 3) + "-" + randomString(letters, 4) + "-" + randomString(letters, 3)
+}
+
+func randomString(alphabet string, n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b)
+}
+
+// mergeExtendedProperties applies a patch of extended properties the way the
+// API does: the private and shared maps are merged key by key, so a patch
+// setting one property keeps the others.
+func mergeExtendedProperties(existing, patch interface{}) interface{} {
+	current, ok := existing.(map[string]interface{})
+	changes, ok2 := patch.(map[string]interface{})
+	if !ok || !ok2 {
+		return patch
+	}
+	for _, scope := range []string{"private", "shared"} {
+		props, ok := changes[scope].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		merged, _ := current[scope].(map[string]interface{})
+		if merged == nil {
+			merged = map[string]interface{}{}
+		}
+		for k, v := range props {
+			merged[k] = v
+		}
+		current[scope] = merged
+	}
+	return current
+}
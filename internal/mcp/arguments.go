@@ -0,0 +1,145 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// strictArgumentsEnv makes every tool reject arguments its schema does not list
+const strictArgumentsEnv = "GCAL_MCP_STRICT_ARGUMENTS"
+
+// StrictArgumentsFromEnv reports whether GCAL_MCP_STRICT_ARGUMENTS turns on
+// strict argument checking (see Server.SetStrictArguments).
+func StrictArgumentsFromEnv() bool {
+	value := os.Getenv(strictArgumentsEnv)
+	if value == "" {
+		return false
+	}
+	strict, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", strictArgumentsEnv, value)
+		return false
+	}
+	return strict
+}
+
+// SetStrictArguments makes every tool reject arguments its input schema does
+// not list, as if the schema set additionalProperties to false, and says so
+// in tools/list. Tools whose schema sets it are checked either way.
+func (s *Server) SetStrictArguments(strict bool) {
+	s.strict = strict
+}
+
+// strictSchema reports whether calls to tool are checked for unknown arguments.
+func (s *Server) strictSchema(tool Tool) bool {
+	if additional := tool.InputSchema.AdditionalProperties; additional != nil {
+		return !*additional
+	}
+	return s.strict
+}
+
+// checkArguments rejects the arguments a strict tool's schema does not list,
+// suggesting the property each was probably meant to be. Misspelled
+// arguments would otherwise be ignored, and the call run without them.
+func checkArguments(tool Tool, arguments map[string]interface{}) error {
+	var unknown []string
+	for name := range arguments {
+		if _, ok := tool.InputSchema.Properties[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	var problems []string
+	guessed := true
+	for _, name := range unknown {
+		if suggestion := SuggestArgument(tool.InputSchema, name); suggestion != "" {
+			problems = append(problems, fmt.Sprintf("unknown argument %q (did you mean %q?)", name, suggestion))
+		} else {
+			problems = append(problems, fmt.Sprintf("unknown argument %q", name))
+			guessed = false
+		}
+	}
+	message := strings.Join(problems, "; ")
+	if !guessed {
+		message += fmt.Sprintf("; %s accepts: %s", tool.Name, strings.Join(propertyNames(tool.InputSchema), ", "))
+	}
+	return fmt.Errorf("%s", message)
+}
+
+// SuggestArgument returns the property of schema closest to name, or "" when
+// none is close enough to be a likely misspelling. Case, dashes and
+// underscores are ignored, so startTime and start-time suggest start_time.
+func SuggestArgument(schema ToolSchema, name string) string {
+	target := normalizeArgument(name)
+	best, bestDistance := "", 0
+	for _, property := range propertyNames(schema) {
+		distance := editDistance(target, normalizeArgument(property))
+		if best == "" || distance < bestDistance {
+			best, bestDistance = property, distance
+		}
+	}
+	// Allow about one typo per three characters, and at least one
+	if limit := max(1, len(target)/3); best == "" || bestDistance > limit {
+		return ""
+	}
+	return best
+}
+
+// propertyNames returns the properties of schema in name order.
+func propertyNames(schema ToolSchema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeArgument(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
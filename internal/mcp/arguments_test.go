@@ -0,0 +1,124 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func strictTestServer(h ToolHandler, additional *bool) *Server {
+	s := NewServer(h)
+	s.RegisterTool(Tool{
+		Name:        "create_event",
+		Description: "A test tool",
+		InputSchema: ToolSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"summary":    map[string]interface{}{"type": "string"},
+				"attendees":  map[string]interface{}{"type": "array"},
+				"start_time": map[string]interface{}{"type": "string"},
+			},
+			AdditionalProperties: additional,
+		},
+	})
+	return s
+}
+
+func callTool(s *Server, arguments map[string]interface{}) *CallToolResult {
+	params, _ := json.Marshal(CallToolParams{Name: "create_event", Arguments: arguments})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	result, _ := resp.Result.(*CallToolResult)
+	return result
+}
+
+func TestStrictArguments(t *testing.T) {
+	ok := &CallToolResult{Content: []ToolResult{{Type: "text", Text: "done"}}}
+	misspelled := map[string]interface{}{"summary": "Sync", "attendee": []interface{}{"a@example.com"}}
+
+	// Unknown arguments are passed through unless the server or schema is strict
+	handler := &mockHandler{result: ok}
+	if result := callTool(strictTestServer(handler, nil), misspelled); result.IsError != nil || handler.called == "" {
+		t.Errorf("lenient server should run the tool, got %+v", result)
+	}
+
+	handler = &mockHandler{result: ok}
+	s := strictTestServer(handler, nil)
+	s.SetStrictArguments(true)
+	result := callTool(s, misspelled)
+	if result == nil || result.IsError == nil || !*result.IsError {
+		t.Fatalf("strict server should reject the call, got %+v", result)
+	}
+	if handler.called != "" {
+		t.Error("the tool should not run with unknown arguments")
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, `unknown argument "attendee" (did you mean "attendees"?)`) {
+		t.Errorf("error = %q, want a suggestion", text)
+	}
+
+	// A schema that sets additionalProperties overrides the server
+	handler = &mockHandler{result: ok}
+	if result := callTool(strictTestServer(handler, boolPtr(false)), misspelled); result.IsError == nil {
+		t.Error("a schema with additionalProperties false should be checked on a lenient server")
+	}
+	handler = &mockHandler{result: ok}
+	s = strictTestServer(handler, boolPtr(true))
+	s.SetStrictArguments(true)
+	if result := callTool(s, misspelled); result.IsError != nil {
+		t.Error("a schema with additionalProperties true should accept extra arguments")
+	}
+}
+
+func TestStrictArguments_ListTools(t *testing.T) {
+	s := strictTestServer(&mockHandler{}, nil)
+	s.SetStrictArguments(true)
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	data, _ := json.Marshal(resp.Result)
+	if !strings.Contains(string(data), `"additionalProperties":false`) {
+		t.Errorf("strict tools/list should declare additionalProperties false: %s", data)
+	}
+	if s.tools["create_event"].InputSchema.AdditionalProperties != nil {
+		t.Error("listing tools must not change the registered schema")
+	}
+}
+
+func TestCheckArguments_NoSuggestion(t *testing.T) {
+	tool := strictTestServer(&mockHandler{}, nil).tools["create_event"]
+	err := checkArguments(tool, map[string]interface{}{"summary": "Sync", "color": "red"})
+	if err == nil || !strings.Contains(err.Error(), "create_event accepts: attendees, start_time, summary") {
+		t.Errorf("error = %v, want the accepted arguments listed", err)
+	}
+}
+
+func TestSuggestArgument(t *testing.T) {
+	schema := strictTestServer(&mockHandler{}, nil).tools["create_event"].InputSchema
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"attendee", "attendees"},
+		{"startTime", "start_time"},
+		{"start-time", "start_time"},
+		{"sumary", "summary"},
+		{"title", ""},
+		{"x", ""},
+	}
+	for _, tt := range tests {
+		if got := SuggestArgument(schema, tt.name); got != tt.want {
+			t.Errorf("SuggestArgument(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -19,20 +19,48 @@ package mcp
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sync"
+
+	"gcal-mcp-server/internal/telemetry"
 )
 
 type Server struct {
-	tools   map[string]Tool
-	handler ToolHandler
+	tools       map[string]Tool
+	handler     ToolHandler
+	healthCheck func() error
+	resources   ResourceHandler
+	initMeta    map[string]interface{}
+	version     string
+	strict      bool // see SetStrictArguments
+
+	// Notifications may be sent from background goroutines, so writes to
+	// stdout and the subscriptions they depend on are guarded
+	writeMu       sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]bool
 }
 
 type ToolHandler interface {
 	HandleTool(name string, arguments map[string]interface{}) (*CallToolResult, error)
 }
 
+// ResourceHandler serves the server's resources. ReadResource returns
+// ErrResourceNotFound for a URI it does not know.
+type ResourceHandler interface {
+	ListResources() []Resource
+	ReadResource(uri string) (*ReadResourceResult, error)
+}
+
+// ErrResourceNotFound is returned by a ResourceHandler for an unknown URI.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// resourceNotFoundCode is the JSON-RPC error code MCP uses for unknown resources
+const resourceNotFoundCode = -32002
+
 // NewServer creates a new MCP server instance with the given tool handler.
 func NewServer(handler ToolHandler) *Server {
 	return &Server{
@@ -41,11 +69,73 @@ func NewServer(handler ToolHandler) *Server {
 	}
 }
 
+// SetHealthCheck installs a function reporting whether the server's backing
+// services are healthy. A nil check (the default) always reports healthy.
+func (s *Server) SetHealthCheck(check func() error) {
+	s.healthCheck = check
+}
+
+// SetInitializeMeta adds value under key to the _meta of the initialize
+// result, for server details clients may show, such as missing permissions.
+// Keys should be prefixed with the server name.
+func (s *Server) SetInitializeMeta(key string, value interface{}) {
+	if s.initMeta == nil {
+		s.initMeta = make(map[string]interface{})
+	}
+	s.initMeta[key] = value
+}
+
+// SetVersion sets the version reported in the initialize result's
+// serverInfo. Without it the server reports defaultVersion.
+func (s *Server) SetVersion(version string) {
+	s.version = version
+}
+
+// SetResources installs the handler serving resources/list and
+// resources/read. Until it is called the server offers no resources.
+func (s *Server) SetResources(handler ResourceHandler) {
+	s.resources = handler
+}
+
+// Subscribed reports whether the client asked to be notified when the
+// resource at uri changes.
+func (s *Server) Subscribed(uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscriptions[uri]
+}
+
+// NotifyResourceUpdated tells the client that the resource at uri changed, if
+// it subscribed to it. It is safe to call from any goroutine.
+func (s *Server) NotifyResourceUpdated(uri string) {
+	if !s.Subscribed(uri) {
+		return
+	}
+	notification := &Notification{JSONRPC: "2.0", Method: "notifications/resources/updated", Params: ResourceParams{URI: uri}}
+	if err := s.sendNotification(notification); err != nil {
+		s.LogToStderr("failed to send resource update: %v", err)
+	}
+}
+
 // RegisterTool registers a tool with the server.
 func (s *Server) RegisterTool(tool Tool) {
 	s.tools[tool.Name] = tool
 }
 
+// SetTools replaces the tool handler and every registered tool, and notifies
+// the client that the tool list changed. It is meant to be called from a tool
+// call, e.g. when setup completes and the full set of tools becomes available.
+func (s *Server) SetTools(handler ToolHandler, tools []Tool) {
+	s.handler = handler
+	s.tools = make(map[string]Tool, len(tools))
+	for _, tool := range tools {
+		s.tools[tool.Name] = tool
+	}
+	if err := s.sendNotification(&Notification{JSONRPC: "2.0", Method: "notifications/tools/list_changed"}); err != nil {
+		s.LogToStderr("failed to send tool list change: %v", err)
+	}
+}
+
 // Run starts the MCP server and listens for incoming JSON-RPC requests on stdin.
 func (s *Server) Run() error {
 	scanner := bufio.NewScanner(os.Stdin)
@@ -85,24 +175,36 @@ func (s *Server) handleRequest(req *Request) *Response {
 		return s.handleListTools(req)
 	case "tools/call":
 		return s.handleCallTool(req)
-	case "shutdown":
+	case "resources/list", "resources/read", "resources/subscribe", "resources/unsubscribe":
+		if s.resources == nil {
+			break
+		}
+		return s.handleResourceRequest(req)
+	case "ping":
 		return &Response{
 			JSONRPC: "2.0",
 			ID:      req.ID,
 			Result:  map[string]interface{}{},
 		}
-	case "exit":
-		os.Exit(0)
-		return nil
-	default:
+	case "health":
+		return s.handleHealth(req)
+	case "shutdown":
 		return &Response{
 			JSONRPC: "2.0",
 			ID:      req.ID,
-			Error: &Error{
-				Code:    -32601,
-				Message: "Method not found",
-			},
+			Result:  map[string]interface{}{},
 		}
+	case "exit":
+		os.Exit(0)
+		return nil
+	}
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Error: &Error{
+			Code:    -32601,
+			Message: "Method not found",
+		},
 	}
 }
 
@@ -123,16 +225,60 @@ func (s *Server) handleInitialize(req *Request) *Response {
 	}
 
 	result := InitializeResult{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: negotiateProtocolVersion(params.ProtocolVersion),
 		Capabilities: ServerCapabilities{
 			Tools: &ToolsCapability{
-				ListChanged: boolPtr(false),
+				ListChanged: boolPtr(true),
 			},
 		},
 		ServerInfo: ServerInfo{
 			Name:    "gcal-mcp-server",
-			Version: "1.0.0",
+			Title:   "Google Calendar",
+			Version: defaultVersion,
 		},
+		Meta: s.initMeta,
+	}
+	if s.version != "" {
+		result.ServerInfo.Version = s.version
+	}
+	if s.resources != nil {
+		result.Capabilities.Resources = &ResourcesCapability{
+			Subscribe: boolPtr(true),
+		}
+	}
+
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+// defaultVersion is reported when SetVersion was not called
+const defaultVersion = "1.0.0"
+
+// supportedProtocolVersions lists the MCP revisions the server speaks, newest
+// first. Structured tool results and output schemas need 2025-06-18.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// negotiateProtocolVersion answers with the client's protocol version when the
+// server supports it, and otherwise with the newest one it does.
+func negotiateProtocolVersion(requested string) string {
+	for _, v := range supportedProtocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return supportedProtocolVersions[0]
+}
+
+func (s *Server) handleHealth(req *Request) *Response {
+	result := HealthResult{Healthy: true}
+	if s.healthCheck != nil {
+		if err := s.healthCheck(); err != nil {
+			result.Healthy = false
+			result.Error = err.Error()
+		}
 	}
 
 	return &Response{
@@ -145,6 +291,9 @@ func (s *Server) handleInitialize(req *Request) *Response {
 func (s *Server) handleListTools(req *Request) *Response {
 	tools := make([]Tool, 0, len(s.tools))
 	for _, tool := range s.tools {
+		if s.strict && tool.InputSchema.AdditionalProperties == nil {
+			tool.InputSchema.AdditionalProperties = boolPtr(false)
+		}
 		tools = append(tools, tool)
 	}
 
@@ -173,7 +322,8 @@ func (s *Server) handleCallTool(req *Request) *Response {
 		}
 	}
 
-	if _, exists := s.tools[params.Name]; !exists {
+	tool, exists := s.tools[params.Name]
+	if !exists {
 		return &Response{
 			JSONRPC: "2.0",
 			ID:      req.ID,
@@ -183,8 +333,26 @@ func (s *Server) handleCallTool(req *Request) *Response {
 			},
 		}
 	}
+	// Reported as a tool error so the model can correct the call
+	if s.strictSchema(tool) {
+		if err := checkArguments(tool, params.Arguments); err != nil {
+			isError := true
+			return &Response{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Result: &CallToolResult{
+					Content: []ToolResult{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+					IsError: &isError,
+				},
+			}
+		}
+	}
 
+	// The tool call span joins the client's trace when it sent one and is
+	// the parent of the Google API requests the tool makes
+	endSpan := telemetry.StartToolCall(telemetry.Extract(params.Meta), params.Name, req.ID)
 	result, err := s.handler.HandleTool(params.Name, params.Arguments)
+	endSpan(err)
 	if err != nil {
 		isError := true
 		result = &CallToolResult{
@@ -203,12 +371,111 @@ func (s *Server) handleCallTool(req *Request) *Response {
 	}
 }
 
+// handleResourceRequest serves resources/list, resources/read and
+// subscriptions to resource updates.
+func (s *Server) handleResourceRequest(req *Request) *Response {
+	if req.Method == "resources/list" {
+		return &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result:  ListResourcesResult{Resources: s.resources.ListResources()},
+		}
+	}
+
+	var params ResourceParams
+	if req.Params != nil {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return &Response{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error: &Error{
+					Code:    -32602,
+					Message: "Invalid params",
+					Data:    err.Error(),
+				},
+			}
+		}
+	}
+	if !s.hasResource(params.URI) {
+		return &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &Error{
+				Code:    resourceNotFoundCode,
+				Message: "Resource not found",
+				Data:    map[string]string{"uri": params.URI},
+			},
+		}
+	}
+
+	switch req.Method {
+	case "resources/subscribe", "resources/unsubscribe":
+		s.mu.Lock()
+		if s.subscriptions == nil {
+			s.subscriptions = make(map[string]bool)
+		}
+		if req.Method == "resources/subscribe" {
+			s.subscriptions[params.URI] = true
+		} else {
+			delete(s.subscriptions, params.URI)
+		}
+		s.mu.Unlock()
+		return &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result:  map[string]interface{}{},
+		}
+	}
+
+	result, err := s.resources.ReadResource(params.URI)
+	if err != nil {
+		code := -32603
+		if errors.Is(err, ErrResourceNotFound) {
+			code = resourceNotFoundCode
+		}
+		return &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &Error{
+				Code:    code,
+				Message: err.Error(),
+			},
+		}
+	}
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+// hasResource reports whether the resource handler lists uri.
+func (s *Server) hasResource(uri string) bool {
+	for _, r := range s.resources.ListResources() {
+		if r.URI == uri {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Server) sendResponse(response *Response) error {
-	data, err := json.Marshal(response)
+	return s.writeMessage(response)
+}
+
+func (s *Server) sendNotification(notification *Notification) error {
+	return s.writeMessage(notification)
+}
+
+// writeMessage writes one JSON-RPC message as a line on stdout.
+func (s *Server) writeMessage(message interface{}) error {
+	data, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	_, err = fmt.Fprintln(os.Stdout, string(data))
 	return err
 }
@@ -67,6 +67,30 @@ func TestHandleInitialize(t *testing.T) {
 	}
 }
 
+func TestHandleInitialize_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"2024-11-05", "2024-11-05"},
+		{"2025-06-18", "2025-06-18"},
+		{"2099-01-01", "2025-06-18"},
+		{"", "2025-06-18"},
+	}
+	for _, tt := range tests {
+		s := newTestServer(&mockHandler{})
+		params, _ := json.Marshal(InitializeParams{ProtocolVersion: tt.requested})
+		resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: params})
+		result, ok := resp.Result.(InitializeResult)
+		if !ok {
+			t.Fatalf("expected InitializeResult, got %T", resp.Result)
+		}
+		if result.ProtocolVersion != tt.want {
+			t.Errorf("requested %q: got protocol version %q, want %q", tt.requested, result.ProtocolVersion, tt.want)
+		}
+	}
+}
+
 func TestHandleInitialized(t *testing.T) {
 	s := newTestServer(&mockHandler{})
 	req := &Request{JSONRPC: "2.0", ID: 2, Method: "initialized"}
@@ -116,6 +140,32 @@ func TestHandleCallTool_KnownTool(t *testing.T) {
 	}
 }
 
+func TestHandleCallTool_StructuredContent(t *testing.T) {
+	handler := &mockHandler{
+		result: &CallToolResult{
+			Content:           []ToolResult{{Type: "text", Text: `{"count":1}`}},
+			StructuredContent: map[string]interface{}{"count": 1},
+		},
+	}
+	s := newTestServer(handler)
+
+	params, _ := json.Marshal(CallToolParams{Name: "test_tool"})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 4, Method: "tools/call", Params: params})
+	data, err := json.Marshal(resp.Result)
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	var decoded struct {
+		StructuredContent map[string]interface{} `json:"structuredContent"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if decoded.StructuredContent["count"] != float64(1) {
+		t.Errorf("structuredContent = %v, want count 1", decoded.StructuredContent)
+	}
+}
+
 func TestHandleCallTool_UnknownTool(t *testing.T) {
 	s := newTestServer(&mockHandler{})
 
@@ -174,6 +224,36 @@ func TestHandleShutdown(t *testing.T) {
 	}
 }
 
+func TestHandlePing(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 9, Method: "ping"})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error on ping: %v", resp.Error)
+	}
+}
+
+func TestHandleHealth(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 10, Method: "health"})
+	result, ok := resp.Result.(HealthResult)
+	if !ok {
+		t.Fatalf("expected HealthResult, got %T", resp.Result)
+	}
+	if !result.Healthy {
+		t.Error("server without a health check should report healthy")
+	}
+
+	s.SetHealthCheck(func() error { return fmt.Errorf("token refresh failing") })
+	resp = s.handleRequest(&Request{JSONRPC: "2.0", ID: 11, Method: "health"})
+	result = resp.Result.(HealthResult)
+	if result.Healthy {
+		t.Error("expected unhealthy when the health check fails")
+	}
+	if result.Error != "token refresh failing" {
+		t.Errorf("unexpected health error %q", result.Error)
+	}
+}
+
 // ----- sendResponse / sendError / LogToStderr -----
 
 func captureStdout(t *testing.T, fn func()) string {
@@ -314,6 +394,34 @@ func TestRegisterTool(t *testing.T) {
 	}
 }
 
+func TestSetTools(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	replacement := &mockHandler{result: &CallToolResult{}}
+
+	out := captureStdout(t, func() {
+		s.SetTools(replacement, []Tool{{Name: "new_tool"}, {Name: "other_tool"}})
+	})
+	var notification Notification
+	if err := json.Unmarshal([]byte(out), &notification); err != nil {
+		t.Fatalf("SetTools should write a notification: %v (%q)", err, out)
+	}
+	if notification.Method != "notifications/tools/list_changed" {
+		t.Errorf("notification method = %q", notification.Method)
+	}
+
+	if _, ok := s.tools["test_tool"]; ok {
+		t.Error("old tools should be removed")
+	}
+	params, _ := json.Marshal(CallToolParams{Name: "new_tool"})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 9, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if replacement.called != "new_tool" {
+		t.Errorf("replacement handler called with %q, want new_tool", replacement.called)
+	}
+}
+
 func TestBoolPtr(t *testing.T) {
 	p := boolPtr(true)
 	if p == nil || !*p {
@@ -324,3 +432,167 @@ func TestBoolPtr(t *testing.T) {
 		t.Error("boolPtr(false) should return pointer to false")
 	}
 }
+
+func TestToolResultMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ToolResult
+		want   string
+	}{
+		{"text", ToolResult{Type: "text", Text: "hello"}, `{"type":"text","text":"hello"}`},
+		{"empty text", ToolResult{Type: "text"}, `{"type":"text","text":""}`},
+		{"image", ToolResult{Type: "image", Data: "iVBORw0KGgo=", MimeType: "image/png"}, `{"type":"image","data":"iVBORw0KGgo=","mimeType":"image/png"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.result)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+// mockResources is a test double for ResourceHandler serving one resource.
+type mockResources struct {
+	err error
+}
+
+func (m *mockResources) ListResources() []Resource {
+	return []Resource{{URI: "test://agenda", Name: "Agenda", MimeType: "text/plain"}}
+}
+
+func (m *mockResources) ReadResource(uri string) (*ReadResourceResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &ReadResourceResult{Contents: []ResourceContents{{URI: uri, MimeType: "text/plain", Text: "nothing today"}}}, nil
+}
+
+func resourceRequest(method, uri string) *Request {
+	params, _ := json.Marshal(ResourceParams{URI: uri})
+	return &Request{JSONRPC: "2.0", ID: 1, Method: method, Params: params}
+}
+
+func TestResources_NotOffered(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	if resp.Result.(InitializeResult).Capabilities.Resources != nil {
+		t.Error("resources capability advertised without resources")
+	}
+	resp = s.handleRequest(&Request{JSONRPC: "2.0", ID: 2, Method: "resources/list"})
+	if resp.Error == nil || resp.Error.Code != -32601 {
+		t.Errorf("expected method not found, got %+v", resp.Error)
+	}
+}
+
+func TestResources_ListAndRead(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	s.SetResources(&mockResources{})
+
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	caps := resp.Result.(InitializeResult).Capabilities
+	if caps.Resources == nil || caps.Resources.Subscribe == nil || !*caps.Resources.Subscribe {
+		t.Errorf("expected a resources capability with subscribe, got %+v", caps.Resources)
+	}
+
+	resp = s.handleRequest(&Request{JSONRPC: "2.0", ID: 2, Method: "resources/list"})
+	list, ok := resp.Result.(ListResourcesResult)
+	if !ok || len(list.Resources) != 1 || list.Resources[0].URI != "test://agenda" {
+		t.Fatalf("unexpected list: %+v", resp.Result)
+	}
+
+	resp = s.handleRequest(resourceRequest("resources/read", "test://agenda"))
+	read, ok := resp.Result.(*ReadResourceResult)
+	if !ok || len(read.Contents) != 1 || read.Contents[0].Text != "nothing today" {
+		t.Fatalf("unexpected read: %+v", resp.Result)
+	}
+
+	resp = s.handleRequest(resourceRequest("resources/read", "test://other"))
+	if resp.Error == nil || resp.Error.Code != resourceNotFoundCode {
+		t.Errorf("expected resource not found, got %+v", resp.Error)
+	}
+
+	s.SetResources(&mockResources{err: fmt.Errorf("calendar unavailable")})
+	resp = s.handleRequest(resourceRequest("resources/read", "test://agenda"))
+	if resp.Error == nil || resp.Error.Code != -32603 {
+		t.Errorf("expected an internal error, got %+v", resp.Error)
+	}
+}
+
+func TestResources_Subscribe(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	s.SetResources(&mockResources{})
+
+	// Updates are only sent for subscribed resources
+	if out := captureStdout(t, func() { s.NotifyResourceUpdated("test://agenda") }); out != "" {
+		t.Errorf("update sent without a subscription: %q", out)
+	}
+
+	resp := s.handleRequest(resourceRequest("resources/subscribe", "test://agenda"))
+	if resp.Error != nil {
+		t.Fatalf("subscribe: %v", resp.Error)
+	}
+	if !s.Subscribed("test://agenda") {
+		t.Fatal("expected a subscription")
+	}
+	out := captureStdout(t, func() { s.NotifyResourceUpdated("test://agenda") })
+	var notification struct {
+		Method string         `json:"method"`
+		Params ResourceParams `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(out), &notification); err != nil {
+		t.Fatalf("expected a notification: %v (%q)", err, out)
+	}
+	if notification.Method != "notifications/resources/updated" || notification.Params.URI != "test://agenda" {
+		t.Errorf("unexpected notification: %+v", notification)
+	}
+
+	resp = s.handleRequest(resourceRequest("resources/unsubscribe", "test://agenda"))
+	if resp.Error != nil || s.Subscribed("test://agenda") {
+		t.Errorf("unsubscribe failed: %+v", resp.Error)
+	}
+
+	resp = s.handleRequest(resourceRequest("resources/subscribe", "test://other"))
+	if resp.Error == nil || resp.Error.Code != resourceNotFoundCode {
+		t.Errorf("subscribing to an unknown resource: got %+v", resp.Error)
+	}
+}
+
+func TestHandleInitialize_Meta(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	if meta := resp.Result.(InitializeResult).Meta; meta != nil {
+		t.Errorf("expected no _meta by default, got %v", meta)
+	}
+
+	s.SetInitializeMeta("gcal-mcp-server/scopes", map[string]interface{}{"granted": []string{"calendar"}})
+	resp = s.handleRequest(&Request{JSONRPC: "2.0", ID: 2, Method: "initialize"})
+	data, _ := json.Marshal(resp.Result)
+	var result struct {
+		Meta map[string]json.RawMessage `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if _, ok := result.Meta["gcal-mcp-server/scopes"]; !ok {
+		t.Errorf("expected the scopes in _meta, got %s", data)
+	}
+}
+
+func TestHandleInitialize_Version(t *testing.T) {
+	s := newTestServer(&mockHandler{})
+	resp := s.handleRequest(&Request{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	if v := resp.Result.(InitializeResult).ServerInfo.Version; v != defaultVersion {
+		t.Errorf("default version = %q, want %q", v, defaultVersion)
+	}
+
+	s.SetVersion("1.4.2")
+	resp = s.handleRequest(&Request{JSONRPC: "2.0", ID: 2, Method: "initialize"})
+	if v := resp.Result.(InitializeResult).ServerInfo.Version; v != "1.4.2" {
+		t.Errorf("version = %q, want 1.4.2", v)
+	}
+}
@@ -32,6 +32,14 @@ type Response struct {
 	Error   *Error      `json:"error,omitempty"`
 }
 
+// Notification is a JSON-RPC message sent by the server without a request,
+// such as notifications/tools/list_changed.
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 type Error struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
@@ -56,52 +64,122 @@ type ClientInfo struct {
 }
 
 type InitializeResult struct {
-	ProtocolVersion string             `json:"protocolVersion"`
-	Capabilities    ServerCapabilities `json:"capabilities"`
-	ServerInfo      ServerInfo         `json:"serverInfo"`
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    ServerCapabilities     `json:"capabilities"`
+	ServerInfo      ServerInfo             `json:"serverInfo"`
+	Meta            map[string]interface{} `json:"_meta,omitempty"` // see Server.SetInitializeMeta
 }
 
 type ServerCapabilities struct {
-	Tools *ToolsCapability `json:"tools,omitempty"`
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
 }
 
 type ToolsCapability struct {
 	ListChanged *bool `json:"listChanged,omitempty"`
 }
 
+// ResourcesCapability is advertised when the server has resources; Subscribe
+// means clients may ask for notifications/resources/updated.
+type ResourcesCapability struct {
+	Subscribe   *bool `json:"subscribe,omitempty"`
+	ListChanged *bool `json:"listChanged,omitempty"`
+}
+
 type ServerInfo struct {
 	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"` // display name, since protocol 2025-06-18
 	Version string `json:"version"`
 }
 
 type Tool struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	InputSchema ToolSchema `json:"inputSchema"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	InputSchema  ToolSchema  `json:"inputSchema"`
+	OutputSchema *ToolSchema `json:"outputSchema,omitempty"` // shape of StructuredContent, for tools that return it
 }
 
 type ToolSchema struct {
 	Type                 string                 `json:"type"`
 	Properties           map[string]interface{} `json:"properties"`
 	Required             []string               `json:"required,omitempty"`
-	AdditionalProperties bool                   `json:"additionalProperties,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"` // false rejects arguments not in Properties
 }
 
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      map[string]interface{} `json:"_meta,omitempty"` // may carry W3C traceparent/tracestate
 }
 
 type CallToolResult struct {
 	Content []ToolResult `json:"content"`
-	IsError *bool        `json:"isError,omitempty"`
+	// StructuredContent is the result as a JSON object matching the tool's
+	// OutputSchema. Content still carries it as text for older clients.
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           *bool       `json:"isError,omitempty"`
 }
 
+// ToolResult is one content item of a tool result: text, or an image when
+// Type is "image".
 type ToolResult struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Data     string `json:"data,omitempty"`     // base64-encoded image
+	MimeType string `json:"mimeType,omitempty"` // e.g. "image/png"
+}
+
+// MarshalJSON writes only the fields of the item's content type, so image
+// content carries no "text" field.
+func (r ToolResult) MarshalJSON() ([]byte, error) {
+	if r.Type == "image" {
+		return json.Marshal(struct {
+			Type     string `json:"type"`
+			Data     string `json:"data"`
+			MimeType string `json:"mimeType"`
+		}{r.Type, r.Data, r.MimeType})
+	}
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}{r.Type, r.Text})
 }
 
 type ListToolsResult struct {
 	Tools []Tool `json:"tools"`
 }
+
+// Resource is a piece of context the client can read, and subscribe to, by URI.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ResourceParams are the params of resources/read, resources/subscribe and
+// resources/unsubscribe, and of notifications/resources/updated.
+type ResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceContents is the text of a resource as read.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// HealthResult is returned by the non-standard "health" method.
+type HealthResult struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
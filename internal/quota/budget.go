@@ -0,0 +1,235 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+// Package quota limits and counts Google API requests made by the server. A
+// single Budget is shared by every tool through an http.RoundTripper, so an
+// agent stuck in a loop cannot exhaust the user's API quota.
+package quota
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// requestsPerMinuteEnv overrides DefaultRequestsPerMinute; 0 disables the limit
+	requestsPerMinuteEnv = "GCAL_MCP_REQUESTS_PER_MINUTE"
+	// DefaultRequestsPerMinute is the request budget when none is configured
+	DefaultRequestsPerMinute = 120
+)
+
+// ExhaustedError is returned instead of making a request when the budget is spent.
+type ExhaustedError struct {
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("API request budget exhausted (%d requests/minute); retry in %s", e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+// Usage is a snapshot of the API calls made this session.
+type Usage struct {
+	Since          time.Time      `json:"since"`
+	TotalCalls     int            `json:"total_calls"`
+	Rejected       int            `json:"rejected_by_budget"`
+	Throttled      int            `json:"throttled_by_google"`
+	PerMinuteLimit int            `json:"per_minute_limit"` // 0 means unlimited
+	Remaining      int            `json:"remaining_this_minute"`
+	ByAPI          map[string]int `json:"by_api"`
+	ByTool         map[string]int `json:"by_tool"`
+}
+
+// Budget is a token bucket refilled continuously at perMinute tokens per
+// minute, holding at most perMinute tokens. It also counts calls per API and
+// per tool. It is safe for concurrent use.
+type Budget struct {
+	mu        sync.Mutex
+	perMinute int
+	tokens    float64
+	last      time.Time
+	now       func() time.Time
+
+	since     time.Time
+	total     int
+	rejected  int
+	throttled int
+	byAPI     map[string]int
+	byTool    map[string]int
+	tool      string
+}
+
+// NewBudget creates a full budget of perMinute requests per minute. A
+// perMinute of 0 or less counts requests without limiting them.
+func NewBudget(perMinute int) *Budget {
+	return newBudgetAt(perMinute, time.Now)
+}
+
+func newBudgetAt(perMinute int, now func() time.Time) *Budget {
+	if perMinute < 0 {
+		perMinute = 0
+	}
+	t := now()
+	return &Budget{
+		perMinute: perMinute,
+		tokens:    float64(perMinute),
+		last:      t,
+		now:       now,
+		since:     t,
+		byAPI:     make(map[string]int),
+		byTool:    make(map[string]int),
+	}
+}
+
+// BudgetFromEnv creates a budget sized by GCAL_MCP_REQUESTS_PER_MINUTE, or
+// DefaultRequestsPerMinute when it is unset or invalid.
+func BudgetFromEnv() *Budget {
+	perMinute := DefaultRequestsPerMinute
+	if value := os.Getenv(requestsPerMinuteEnv); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			perMinute = n
+		} else {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q\n", requestsPerMinuteEnv, value)
+		}
+	}
+	return NewBudget(perMinute)
+}
+
+// SetTool attributes subsequent requests to the named tool.
+func (b *Budget) SetTool(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tool = name
+}
+
+// Take spends one request from the budget for the given API, or returns an
+// *ExhaustedError if none is left.
+func (b *Budget) Take(api string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.perMinute > 0 {
+		b.refillLocked()
+		if b.tokens < 1 {
+			b.rejected++
+			perToken := time.Minute / time.Duration(b.perMinute)
+			return &ExhaustedError{
+				Limit:      b.perMinute,
+				RetryAfter: time.Duration((1 - b.tokens) * float64(perToken)),
+			}
+		}
+		b.tokens--
+	}
+
+	b.total++
+	b.byAPI[api]++
+	if b.tool != "" {
+		b.byTool[b.tool]++
+	}
+	return nil
+}
+
+// RecordStatus notes a response status, counting 429 responses as Google-side
+// throttling.
+func (b *Budget) RecordStatus(status int) {
+	if status != http.StatusTooManyRequests {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.throttled++
+}
+
+func (b *Budget) refillLocked() {
+	now := b.now()
+	elapsed := now.Sub(b.last)
+	b.last = now
+	b.tokens += elapsed.Minutes() * float64(b.perMinute)
+	if b.tokens > float64(b.perMinute) {
+		b.tokens = float64(b.perMinute)
+	}
+}
+
+// Usage returns a snapshot of the session's API usage.
+func (b *Budget) Usage() Usage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := 0
+	if b.perMinute > 0 {
+		b.refillLocked()
+		remaining = int(b.tokens)
+	}
+	u := Usage{
+		Since:          b.since,
+		TotalCalls:     b.total,
+		Rejected:       b.rejected,
+		Throttled:      b.throttled,
+		PerMinuteLimit: b.perMinute,
+		Remaining:      remaining,
+		ByAPI:          make(map[string]int, len(b.byAPI)),
+		ByTool:         make(map[string]int, len(b.byTool)),
+	}
+	for k, v := range b.byAPI {
+		u.ByAPI[k] = v
+	}
+	for k, v := range b.byTool {
+		u.ByTool[k] = v
+	}
+	return u
+}
+
+// Middleware wraps an HTTP transport so every request is charged to the budget.
+func (b *Budget) Middleware(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{budget: b, next: next}
+}
+
+type transport struct {
+	budget *Budget
+	next   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.budget.Take(apiName(req)); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.budget.RecordStatus(resp.StatusCode)
+	}
+	return resp, err
+}
+
+// apiName returns the API a request targets, taken from the first path segment
+// of Google API URLs (e.g. /calendar/v3/... → "calendar").
+func apiName(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, "/")
+	if i := strings.Index(path, "/"); i > 0 {
+		return path[:i]
+	}
+	if path == "" {
+		return req.URL.Host
+	}
+	return path
+}
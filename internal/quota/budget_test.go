@@ -0,0 +1,162 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+type stubTransport struct {
+	status int
+	calls  int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls++
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(s.status)
+	return rec.Result(), nil
+}
+
+// ----- Take -----
+
+func TestBudgetExhaustsAndRefills(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	b := newBudgetAt(60, clock.now)
+
+	for i := 0; i < 60; i++ {
+		if err := b.Take("calendar"); err != nil {
+			t.Fatalf("request %d rejected: %v", i, err)
+		}
+	}
+
+	err := b.Take("calendar")
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("expected ExhaustedError, got %v", err)
+	}
+	if exhausted.Limit != 60 || exhausted.RetryAfter != time.Second {
+		t.Errorf("unexpected error details: %+v", exhausted)
+	}
+
+	// One request per second is refilled at 60/minute
+	clock.advance(time.Second)
+	if err := b.Take("calendar"); err != nil {
+		t.Errorf("expected refilled token, got %v", err)
+	}
+
+	// The bucket never holds more than one minute's worth
+	clock.advance(time.Hour)
+	if got := b.Usage().Remaining; got != 60 {
+		t.Errorf("remaining after idle hour = %d, want 60", got)
+	}
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	b := NewBudget(0)
+	for i := 0; i < 1000; i++ {
+		if err := b.Take("calendar"); err != nil {
+			t.Fatalf("unlimited budget rejected request %d: %v", i, err)
+		}
+	}
+	usage := b.Usage()
+	if usage.TotalCalls != 1000 || usage.PerMinuteLimit != 0 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+// ----- Usage -----
+
+func TestBudgetCountsByAPIAndTool(t *testing.T) {
+	b := NewBudget(100)
+	b.Take("calendar")
+	b.SetTool("list_events")
+	b.Take("calendar")
+	b.Take("calendar")
+	b.SetTool("get_document")
+	b.Take("drive")
+
+	usage := b.Usage()
+	if usage.TotalCalls != 4 {
+		t.Errorf("total = %d, want 4", usage.TotalCalls)
+	}
+	if usage.ByAPI["calendar"] != 3 || usage.ByAPI["drive"] != 1 {
+		t.Errorf("unexpected per-API counts: %v", usage.ByAPI)
+	}
+	if usage.ByTool["list_events"] != 2 || usage.ByTool["get_document"] != 1 || len(usage.ByTool) != 2 {
+		t.Errorf("unexpected per-tool counts: %v", usage.ByTool)
+	}
+	if usage.Remaining != 96 {
+		t.Errorf("remaining = %d, want 96", usage.Remaining)
+	}
+}
+
+// ----- Middleware -----
+
+func TestMiddleware(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	b := newBudgetAt(2, clock.now)
+	stub := &stubTransport{status: http.StatusTooManyRequests}
+	client := &http.Client{Transport: b.Middleware(stub)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("https://www.googleapis.com/calendar/v3/users/me/calendarList")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get("https://www.googleapis.com/calendar/v3/colors"); err == nil {
+		t.Fatal("expected request beyond the budget to fail")
+	}
+	if stub.calls != 2 {
+		t.Errorf("transport called %d times, want 2", stub.calls)
+	}
+
+	usage := b.Usage()
+	if usage.Throttled != 2 || usage.Rejected != 1 || usage.ByAPI["calendar"] != 2 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestAPIName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.googleapis.com/calendar/v3/freeBusy", "calendar"},
+		{"https://www.googleapis.com/drive/v3/files/abc/export", "drive"},
+		{"https://oauth2.googleapis.com/token", "token"},
+		{"https://example.com/", "example.com"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		if got := apiName(req); got != tt.want {
+			t.Errorf("apiName(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// This code was developed with AI assistance.
+
+// Package telemetry traces MCP tool calls and the Google API requests they
+// make with OpenTelemetry. Spans are exported over OTLP/HTTP when an OTLP
+// endpoint is configured through the standard OTEL_EXPORTER_OTLP_* variables;
+// otherwise tracing costs nothing and no spans are recorded.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// serviceName is reported unless OTEL_SERVICE_NAME overrides it
+	serviceName = "gcal-mcp-server"
+	// tracerName identifies the spans created by this server
+	tracerName = "gcal-mcp-server"
+)
+
+// Span attributes. Tool and request attributes follow the OpenTelemetry
+// conventions for MCP and HTTP clients; calendar attributes are our own.
+const (
+	mcpMethodKey      = attribute.Key("mcp.method.name")
+	toolNameKey       = attribute.Key("gen_ai.tool.name")
+	requestIDKey      = attribute.Key("jsonrpc.request.id")
+	calendarIDKey     = attribute.Key("gcal.calendar_id")
+	apiMethodKey      = attribute.Key("gcal.api.method")
+	httpMethodKey     = attribute.Key("http.request.method")
+	httpStatusKey     = attribute.Key("http.response.status_code")
+	serverAddressKey  = attribute.Key("server.address")
+	errorTypeKey      = attribute.Key("error.type")
+	toolCallOperation = "tools/call"
+)
+
+// customMethods are path segments naming an API method rather than a
+// resource or ID, e.g. /calendars/{id}/events/{eventId}/move.
+var customMethods = map[string]bool{
+	"instances": true,
+	"move":      true,
+	"quickAdd":  true,
+	"import":    true,
+	"watch":     true,
+	"export":    true,
+}
+
+var (
+	// current holds the span of the tool call being handled. The generated
+	// Google clients make requests without the caller's context, so API
+	// spans find their parent here. The MCP server handles one request at a
+	// time, so a single current span is enough.
+	currentMu sync.Mutex
+	current   = context.Background()
+)
+
+// Enabled reports whether an OTLP endpoint is configured and the SDK is not
+// disabled with OTEL_SDK_DISABLED or OTEL_TRACES_EXPORTER=none.
+func Enabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") || os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs a tracer provider exporting spans over OTLP/HTTP when
+// tracing is enabled. The exporter reads the standard OTEL_EXPORTER_OTLP_*
+// variables (endpoint, headers, timeout, TLS). The returned function flushes
+// pending spans and must be called before the process exits; it does nothing
+// when tracing is disabled.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create OTLP trace exporter: %v", err)
+	}
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES are
+	// detected last, so they override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe the traced service: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Extract returns a context carrying the trace context an MCP client sent in
+// a request's _meta (the W3C traceparent and tracestate keys), so tool call
+// spans join the client's trace. Without one it returns context.Background().
+func Extract(meta map[string]interface{}) context.Context {
+	carrier := propagation.MapCarrier{}
+	for key, value := range meta {
+		if s, ok := value.(string); ok {
+			carrier[strings.ToLower(key)] = s
+		}
+	}
+	return otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+}
+
+// StartToolCall starts the span of one MCP tool call as a child of parent.
+// Until the returned function is called with the call's error, the span is
+// also the parent of every Google API request.
+func StartToolCall(parent context.Context, tool string, requestID interface{}) func(error) {
+	ctx, span := tracer().Start(parent, toolCallOperation+" "+tool,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			mcpMethodKey.String(toolCallOperation),
+			toolNameKey.String(tool),
+		),
+	)
+	if requestID != nil {
+		span.SetAttributes(requestIDKey.String(fmt.Sprint(requestID)))
+	}
+	setCurrent(ctx)
+
+	return func(err error) {
+		setCurrent(context.Background())
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.SetAttributes(errorTypeKey.String("tool_error"))
+		}
+		span.End()
+	}
+}
+
+// SetCalendarID records the calendar the current tool call works on.
+func SetCalendarID(calendarID string) {
+	trace.SpanFromContext(currentContext()).SetAttributes(calendarIDKey.String(calendarID))
+}
+
+func setCurrent(ctx context.Context) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = ctx
+}
+
+func currentContext() context.Context {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current
+}
+
+// Middleware wraps an http.RoundTripper so every Google API request is traced
+// as a client span with its API method, calendar and response status.
+func Middleware(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(currentContext()))
+	}
+
+	call := describeRequest(req)
+	ctx, span := tracer().Start(ctx, call.api+" "+call.method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			apiMethodKey.String(call.method),
+			httpMethodKey.String(req.Method),
+			serverAddressKey.String(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+	if call.calendarID != "" {
+		span.SetAttributes(calendarIDKey.String(call.calendarID))
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(errorTypeKey.String(fmt.Sprintf("%T", err)))
+		return resp, err
+	}
+	span.SetAttributes(httpStatusKey.Int(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		span.SetAttributes(errorTypeKey.String(fmt.Sprint(resp.StatusCode)))
+	}
+	return resp, nil
+}
+
+// apiCall identifies a Google API request.
+type apiCall struct {
+	api        string // e.g. "calendar" or "drive"
+	method     string // e.g. "events.list" or "freebusy.query"
+	calendarID string // calendar in the path, if any
+}
+
+// describeRequest derives the API method from a Google API request path.
+// Paths alternate between collections and IDs after the version, e.g.
+// /calendar/v3/calendars/{calendarId}/events/{eventId}; the last collection
+// names the resource and whether an ID follows picks the method, unless the
+// path ends in a custom method such as move or instances.
+func describeRequest(req *http.Request) apiCall {
+	// Split the escaped path: collection and method names never need
+	// unescaping, and an ID with an escaped '/' stays one segment
+	segments := strings.Split(strings.Trim(req.URL.EscapedPath(), "/"), "/")
+	if len(segments) < 3 {
+		return apiCall{api: req.URL.Hostname(), method: req.Method}
+	}
+	call := apiCall{api: segments[0]}
+	segments = segments[2:]
+	if len(segments) >= 2 && segments[0] == "users" && segments[1] == "me" {
+		segments = segments[2:]
+	}
+	if len(segments) == 0 {
+		call.method = req.Method
+		return call
+	}
+
+	for i := 0; i+1 < len(segments); i += 2 {
+		if segments[i] == "calendars" || segments[i] == "calendarList" {
+			call.calendarID = unescapeSegment(segments[i+1])
+		}
+	}
+
+	custom := ""
+	if last := segments[len(segments)-1]; len(segments) > 1 && customMethods[last] {
+		custom = last
+		segments = segments[:len(segments)-1]
+	}
+	hasID := len(segments)%2 == 0
+	resource := segments[len(segments)-1]
+	if hasID {
+		resource = segments[len(segments)-2]
+	}
+
+	switch {
+	case custom != "":
+		call.method = custom
+	case resource == "freeBusy":
+		resource, call.method = "freebusy", "query"
+	case req.Method == http.MethodGet && hasID:
+		call.method = "get"
+	case req.Method == http.MethodGet:
+		call.method = "list"
+	case req.Method == http.MethodPost:
+		call.method = "insert"
+	case req.Method == http.MethodPut:
+		call.method = "update"
+	case req.Method == http.MethodPatch:
+		call.method = "patch"
+	case req.Method == http.MethodDelete:
+		call.method = "delete"
+	default:
+		call.method = strings.ToLower(req.Method)
+	}
+	call.method = resource + "." + call.method
+	return call
+}
+
+// unescapeSegment returns a path segment as the API reads it, or unchanged
+// when it is not validly escaped.
+func unescapeSegment(segment string) string {
+	if unescaped, err := url.PathUnescape(segment); err == nil {
+		return unescaped
+	}
+	return segment
+}
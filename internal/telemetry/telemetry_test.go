@@ -0,0 +1,241 @@
+// Copyright 2024 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type stubTransport struct {
+	status int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(s.status)
+	return rec.Result(), nil
+}
+
+// recordSpans installs a tracer provider recording every ended span for the
+// duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func attributeValue(attrs []attribute.KeyValue, key attribute.Key) string {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+// ----- describeRequest -----
+
+func TestDescribeRequest(t *testing.T) {
+	tests := []struct {
+		method     string
+		url        string
+		api        string
+		apiMethod  string
+		calendarID string
+	}{
+		{"GET", "https://www.googleapis.com/calendar/v3/calendars/primary/events?timeMin=x", "calendar", "events.list", "primary"},
+		{"POST", "https://www.googleapis.com/calendar/v3/calendars/team%40example.com/events", "calendar", "events.insert", "team@example.com"},
+		{"GET", "https://www.googleapis.com/calendar/v3/calendars/odd%2Fid%40example.com/events", "calendar", "events.list", "odd/id@example.com"},
+		{"GET", "https://www.googleapis.com/calendar/v3/calendars/primary/events/abc123", "calendar", "events.get", "primary"},
+		{"PATCH", "https://www.googleapis.com/calendar/v3/calendars/primary/events/abc123", "calendar", "events.patch", "primary"},
+		{"DELETE", "https://www.googleapis.com/calendar/v3/calendars/primary/events/abc123", "calendar", "events.delete", "primary"},
+		{"GET", "https://www.googleapis.com/calendar/v3/calendars/primary/events/abc123/instances", "calendar", "events.instances", "primary"},
+		{"POST", "https://www.googleapis.com/calendar/v3/calendars/primary/events/abc123/move?destination=x", "calendar", "events.move", "primary"},
+		{"POST", "https://www.googleapis.com/calendar/v3/calendars/primary/events/quickAdd", "calendar", "events.quickAdd", "primary"},
+		{"POST", "https://www.googleapis.com/calendar/v3/freeBusy", "calendar", "freebusy.query", ""},
+		{"GET", "https://www.googleapis.com/calendar/v3/users/me/calendarList", "calendar", "calendarList.list", ""},
+		{"GET", "https://www.googleapis.com/calendar/v3/users/me/calendarList/en.usa%23holiday%40group.v.calendar.google.com", "calendar", "calendarList.get", "en.usa#holiday@group.v.calendar.google.com"},
+		{"GET", "https://www.googleapis.com/drive/v3/files/doc1/export?mimeType=text/plain", "drive", "files.export", ""},
+		{"GET", "https://www.googleapis.com/drive/v3/files/doc1", "drive", "files.get", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			got := describeRequest(req)
+			if got.api != tt.api || got.method != tt.apiMethod || got.calendarID != tt.calendarID {
+				t.Errorf("describeRequest() = %+v, want api %q method %q calendar %q", got, tt.api, tt.apiMethod, tt.calendarID)
+			}
+		})
+	}
+}
+
+// ----- Middleware -----
+
+func TestMiddleware_NestsUnderToolCall(t *testing.T) {
+	recorder := recordSpans(t)
+	client := &http.Client{Transport: Middleware(&stubTransport{status: http.StatusOK})}
+
+	end := StartToolCall(Extract(nil), "list_events", 7)
+	SetCalendarID("team@example.com")
+	resp, err := client.Get("https://www.googleapis.com/calendar/v3/calendars/team%40example.com/events")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	end(nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	apiSpan, toolSpan := spans[0], spans[1]
+
+	if toolSpan.Name() != "tools/call list_events" {
+		t.Errorf("tool span name = %q", toolSpan.Name())
+	}
+	if got := attributeValue(toolSpan.Attributes(), toolNameKey); got != "list_events" {
+		t.Errorf("tool name = %q", got)
+	}
+	if got := attributeValue(toolSpan.Attributes(), requestIDKey); got != "7" {
+		t.Errorf("request id = %q", got)
+	}
+	if got := attributeValue(toolSpan.Attributes(), calendarIDKey); got != "team@example.com" {
+		t.Errorf("tool calendar id = %q", got)
+	}
+
+	if apiSpan.Name() != "calendar events.list" {
+		t.Errorf("API span name = %q", apiSpan.Name())
+	}
+	if apiSpan.Parent().SpanID() != toolSpan.SpanContext().SpanID() {
+		t.Errorf("API span is not a child of the tool call span")
+	}
+	if got := attributeValue(apiSpan.Attributes(), httpStatusKey); got != "200" {
+		t.Errorf("status = %q", got)
+	}
+	if got := attributeValue(apiSpan.Attributes(), calendarIDKey); got != "team@example.com" {
+		t.Errorf("API calendar id = %q", got)
+	}
+	if apiSpan.Status().Code != codes.Unset {
+		t.Errorf("API span status = %v, want unset", apiSpan.Status().Code)
+	}
+}
+
+func TestMiddleware_ErrorStatus(t *testing.T) {
+	recorder := recordSpans(t)
+	client := &http.Client{Transport: Middleware(&stubTransport{status: http.StatusNotFound})}
+
+	end := StartToolCall(Extract(nil), "get_event", nil)
+	resp, err := client.Get("https://www.googleapis.com/calendar/v3/calendars/primary/events/missing")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	end(errors.New("event not found"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("API span status = %v, want error", spans[0].Status().Code)
+	}
+	if spans[1].Status().Code != codes.Error || spans[1].Status().Description != "event not found" {
+		t.Errorf("tool span status = %+v, want error", spans[1].Status())
+	}
+}
+
+func TestMiddleware_WithoutToolCall(t *testing.T) {
+	recorder := recordSpans(t)
+	client := &http.Client{Transport: Middleware(&stubTransport{status: http.StatusOK})}
+
+	resp, err := client.Get("https://www.googleapis.com/calendar/v3/users/me/calendarList")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Parent().IsValid() {
+		t.Fatalf("want one root span, got %d spans", len(spans))
+	}
+}
+
+// ----- Extract -----
+
+func TestExtract_JoinsClientTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	meta := map[string]interface{}{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	StartToolCall(Extract(meta), "whoami", 1)(nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := spans[0].SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the client's", got)
+	}
+	if got := spans[0].Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want the client's", got)
+	}
+}
+
+// ----- Enabled -----
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected bool
+	}{
+		{"no endpoint", map[string]string{}, false},
+		{"endpoint", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"}, true},
+		{"traces endpoint", map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://localhost:4318/v1/traces"}, true},
+		{"sdk disabled", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318", "OTEL_SDK_DISABLED": "true"}, false},
+		{"exporter none", map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318", "OTEL_TRACES_EXPORTER": "none"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_SDK_DISABLED", "OTEL_TRACES_EXPORTER"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := Enabled(); got != tt.expected {
+				t.Errorf("Enabled() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}